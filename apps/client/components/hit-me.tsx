@@ -16,7 +16,7 @@ export function HitMe() {
   const { getToken } = useAuth();
   async function handleHitMe() {
     const token = await getToken();
-    const response = await fetch("http://localhost:8000/api/me", {
+    const response = await fetch("http://localhost:8000/api/v1/me", {
       method: "GET",
       headers: {
         Authorization: `Bearer ${token}`,
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/logging"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/servicetokens"
+)
+
+// servicetoken mints a service token, for jobs that can't mint their own
+// and for trying out the service routes by hand.
+func main() {
+	service := flag.String("service", "", "name of the calling service")
+	org := flag.String("org", "", "Clerk organization id the token acts in")
+	scopes := flag.String("scopes", models.TokenScopeRead, "comma-separated scopes: read, write")
+	ttl := flag.Duration("ttl", time.Hour, "how long the token is valid, at most 24h")
+	config.RegisterFlags(flag.CommandLine)
+	flag.Parse()
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logging.Fatal("Failed to load configuration", "error", err)
+	}
+	logging.Setup(os.Stderr, cfg.ENV, cfg.LOG_LEVEL)
+	if cfg.SERVICE_TOKEN_SECRET == "" {
+		logging.Fatal("SERVICE_TOKEN_SECRET is not set")
+	}
+
+	list := strings.Split(*scopes, ",")
+	for _, s := range list {
+		if !models.ValidTokenScope(s) {
+			fmt.Fprintln(os.Stderr, "unknown scope:", s)
+			os.Exit(2)
+		}
+	}
+
+	issuer, err := servicetokens.NewIssuer(cfg.SERVICE_TOKEN_SECRET)
+	if err != nil {
+		logging.Fatal("Invalid SERVICE_TOKEN_SECRET", "error", err)
+	}
+	token, err := issuer.Issue(*service, *org, list, *ttl, time.Now())
+	if err != nil {
+		logging.Fatal("Failed to issue service token", "error", err)
+	}
+	fmt.Println(token)
+}
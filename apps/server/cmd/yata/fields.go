@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/base64"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/crypto"
+	"yata/apps/server/internal/logging"
+	"yata/apps/server/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// encryptedFields is what the store encrypts fields with, or nil without
+// ENCRYPTION_MASTER_KEY. Every command writing through the store uses it,
+// so none of them stores in the clear what the others encrypt.
+func encryptedFields(cfg *config.Config, pool *pgxpool.Pool) *crypto.Fields {
+	if cfg.ENCRYPTION_MASTER_KEY == "" {
+		return nil
+	}
+	masterKey, err := base64.StdEncoding.DecodeString(cfg.ENCRYPTION_MASTER_KEY)
+	if err != nil {
+		logging.Fatal("Invalid ENCRYPTION_MASTER_KEY", "error", err)
+	}
+	kms, err := crypto.NewLocalKMS(masterKey)
+	if err != nil {
+		logging.Fatal("Invalid ENCRYPTION_MASTER_KEY", "error", err)
+	}
+	return crypto.NewFields(kms, repository.NewDataKeyRepository(pool))
+}
@@ -0,0 +1,82 @@
+// Command yata is the server's one binary: the API, the background worker
+// and the schema migrations are subcommands of it, and all of them load
+// their config the same way.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/logging"
+)
+
+type command struct {
+	run func(cfg *config.Config, args []string)
+	// flags adds the command's own flags, if it has any, next to the
+	// config ones.
+	flags func(fs *flag.FlagSet)
+	args  string
+	help  string
+}
+
+// commands is filled in by init, since usage refers back to it.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"serve":   {run: serve, help: "serve the API, and run background jobs unless JOB_WORKER_ENABLED is off"},
+		"worker":  {run: worker, help: "run background jobs and schedulers without serving HTTP"},
+		"migrate": {run: migrate, args: "up | down [steps] | status", help: "apply, roll back or list schema migrations"},
+		"seed":    {run: seedDatabase, flags: seedFlags, args: "[-orgs n] [-users n] [-projects n] [-tasks n]", help: "fill the database with demo data"},
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage("")
+	}
+	name := os.Args[1]
+	cmd, ok := commands[name]
+	if !ok {
+		usage("")
+	}
+
+	fs := flag.NewFlagSet("yata "+name, flag.ExitOnError)
+	config.RegisterFlags(fs)
+	if cmd.flags != nil {
+		cmd.flags(fs)
+	}
+	fs.Usage = func() { usage(name) }
+	fs.Parse(os.Args[2:])
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logging.Fatal("Failed to load configuration", "error", err)
+	}
+	logging.Setup(os.Stderr, cfg.ENV, cfg.LOG_LEVEL)
+
+	cmd.run(cfg, fs.Args())
+}
+
+// usage prints how to call name, or every command when name is empty, and
+// exits.
+func usage(name string) {
+	if cmd, ok := commands[name]; ok {
+		fmt.Fprintf(os.Stderr, "usage: yata %s [-set KEY=value] [-env-file path] %s\n", name, cmd.args)
+		os.Exit(2)
+	}
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "usage: yata <command> [-set KEY=value] [-env-file path] [args]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].help)
+	}
+	os.Exit(2)
+}
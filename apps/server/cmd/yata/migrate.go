@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/database/migrations"
+	"yata/apps/server/internal/logging"
+)
+
+// migrate applies, rolls back or lists the schema migrations.
+func migrate(cfg *config.Config, args []string) {
+	if len(args) < 1 {
+		usage("migrate")
+	}
+
+	pool, err := database.Connect(cfg.DATABASE_URL)
+	if err != nil {
+		logging.Fatal("Failed to connect to the database", "error", err)
+	}
+	defer pool.Close()
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(ctx, pool)
+		if err != nil {
+			logging.Fatal("Migration failed", "error", err)
+		}
+		slog.Info("Applied migrations", "count", len(applied))
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				usage("migrate")
+			}
+		}
+		reverted, err := migrations.Down(ctx, pool, steps)
+		if err != nil {
+			logging.Fatal("Rollback failed", "error", err)
+		}
+		slog.Info("Rolled back migrations", "count", len(reverted))
+
+	case "status":
+		statuses, err := migrations.List(ctx, pool)
+		if err != nil {
+			logging.Fatal("Failed to read migration status", "error", err)
+		}
+		for _, s := range statuses {
+			state := "pending"
+			if s.Applied {
+				state = "applied"
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+
+	default:
+		usage("migrate")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/logging"
+	"yata/apps/server/internal/seed"
+	"yata/apps/server/internal/store"
+)
+
+var seedSize = seed.DefaultSize
+
+func seedFlags(fs *flag.FlagSet) {
+	fs.IntVar(&seedSize.Orgs, "orgs", seedSize.Orgs, "how many orgs to seed")
+	fs.IntVar(&seedSize.Users, "users", seedSize.Users, "how many users to seed, members of every org")
+	fs.IntVar(&seedSize.Projects, "projects", seedSize.Projects, "how many projects to seed in each org")
+	fs.IntVar(&seedSize.Tasks, "tasks", seedSize.Tasks, "how many tasks to seed in each project")
+}
+
+// seedDatabase fills the database with demo data; running it again only adds what
+// a bigger size asks for.
+func seedDatabase(cfg *config.Config, _ []string) {
+	if cfg.ENV == "production" {
+		logging.Fatal("Refusing to seed a production database")
+	}
+
+	pool, err := database.Connect(cfg.DATABASE_URL)
+	if err != nil {
+		logging.Fatal("Failed to connect to the database", "error", err)
+	}
+	defer pool.Close()
+
+	db := store.WithActivity(store.NewPostgres(pool, encryptedFields(cfg, pool)))
+	result, err := seed.Run(context.Background(), seed.Stores{
+		Users:       db.Users(),
+		Projects:    db.Projects(),
+		Labels:      db.Labels(),
+		Comments:    db.Comments(),
+		Workflows:   db.Workflows(),
+		OrgSettings: db.OrgSettings(),
+	}, seedSize)
+	if err != nil {
+		logging.Fatal("Seeding failed", "error", err)
+	}
+	slog.Info("Seeded the database", "projects", result.Projects, "tasks", result.Tasks, "comments", result.Comments)
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os/signal"
+	"slices"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/background"
+	"yata/apps/server/internal/cache"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/database/migrations"
+	"yata/apps/server/internal/errorreport"
+	"yata/apps/server/internal/events"
+	"yata/apps/server/internal/flags"
+	"yata/apps/server/internal/handlers"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/logging"
+	"yata/apps/server/internal/metrics"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/ratelimit"
+	"yata/apps/server/internal/rpc"
+	"yata/apps/server/internal/server"
+	"yata/apps/server/internal/servicetokens"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/tracing"
+	"yata/apps/server/internal/validation"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"google.golang.org/grpc"
+)
+
+// serve runs the HTTP API, the gRPC API when GRPC_PORT is set, and the
+// background workers unless JOB_WORKER_ENABLED is off.
+func serve(cfg *config.Config, _ []string) {
+	shutdownTracing, err := tracing.Setup(context.Background(), "yata-api", cfg.ENV)
+	if err != nil {
+		logging.Fatal("Failed to set up tracing", "error", err)
+		return
+	}
+	defer shutdownTracing(context.Background())
+
+	clerk.SetKey(cfg.CLERK_SECRET_KEY)
+	api.SetCursorSecret(cfg.CURSOR_SECRET)
+	validation.Setup()
+
+	dbOptions := []database.Option{}
+
+	if cfg.DB_APPLICATION_NAME != "" {
+		dbOptions = append(dbOptions, database.WithApplicationName(cfg.DB_APPLICATION_NAME, true))
+	} else {
+		dbOptions = append(dbOptions, database.WithApplicationName("yata-"+cfg.ENV+"-"+cfg.INSTANCE_ID, false))
+	}
+
+	dbOptions = append(dbOptions, database.WithStatementTimeout(cfg.DB_STATEMENT_TIMEOUT), database.WithTracing())
+
+	if cfg.DB_SLOW_LOG_ENABLED {
+		dbOptions = append(dbOptions, database.WithQueryStats())
+	}
+
+	// Replicas keep their own URLs' credentials and aren't shrunk under
+	// memory pressure; the options below are the primary's.
+	replicaOptions := slices.Clone(dbOptions)
+
+	var databaseURL atomic.Value
+	databaseURL.Store(cfg.DATABASE_URL)
+	dbOptions = append(dbOptions, database.WithRotatingCredentials(func() string { return databaseURL.Load().(string) }))
+
+	var pressureLimiter *database.MemoryPressureLimiter
+	if cfg.DB_MEMORY_PRESSURE_THRESHOLD > 0 {
+		pressureLimiter = database.NewMemoryPressureLimiter(
+			cfg.DB_MEMORY_PRESSURE_THRESHOLD,
+			int32(cfg.DB_MEMORY_PRESSURE_MAX_CONNS),
+			cfg.DB_MEMORY_PRESSURE_INTERVAL,
+		)
+		dbOptions = append(dbOptions, database.WithMemoryPressureLimit(pressureLimiter))
+	}
+
+	pool, err := database.Connect(cfg.DATABASE_URL, dbOptions...)
+
+	if err != nil {
+		logging.Fatal("Failed to connect to the database", "error", err)
+		return
+	}
+
+	defer pool.Close()
+
+	replicas := database.NewReplicas(pool, cfg.DB_REPLICA_MAX_LAG)
+	for _, url := range cfg.DATABASE_REPLICA_URLS {
+		if err := replicas.Add(url, replicaOptions...); err != nil {
+			logging.Fatal("Invalid DATABASE_REPLICA_URLS", "error", err)
+			return
+		}
+	}
+	defer replicas.Close()
+
+	if cfg.AUTO_MIGRATE {
+		if _, err := migrations.Up(context.Background(), pool); err != nil {
+			logging.Fatal("Failed to run migrations", "error", err)
+			return
+		}
+	}
+
+	// Background work outlives request draining on shutdown, since requests
+	// still enqueue jobs; it's stopped after the server.
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	if pressureLimiter != nil {
+		pressureLimiter.Start(backgroundCtx, pool)
+	}
+	replicas.Start(backgroundCtx, cfg.DB_REPLICA_CHECK_INTERVAL)
+
+	// clerk.SetKey is a plain assignment in the SDK; rotations are rare
+	// enough that a request racing one just fails and is retried.
+	cfg.WatchSecrets(backgroundCtx, cfg.SECRET_REFRESH_INTERVAL, map[string]func(string){
+		"DATABASE_URL":     func(v string) { databaseURL.Store(v) },
+		"CLERK_SECRET_KEY": clerk.SetKey,
+	})
+
+	sessionKeys := middlewares.NewSessionKeys()
+	sessionKeys.Start(backgroundCtx, cfg.CLERK_JWKS_REFRESH_INTERVAL)
+
+	var redisClient *redis.Client
+	var readCache cache.Cache = cache.Nop{}
+	if cfg.REDIS_URL != "" {
+		redisOptions, err := redis.ParseURL(cfg.REDIS_URL)
+		if err != nil {
+			logging.Fatal("Invalid REDIS_URL", "error", err)
+			return
+		}
+		redisClient = redis.NewClient(redisOptions)
+		defer redisClient.Close()
+		readCache = cache.Redis{Client: redisClient}
+	}
+
+	broker := events.NewBroker()
+	base := store.NewPostgresWithReads(pool, replicas, encryptedFields(cfg, pool))
+	quotas := quota.New(base.Usage(), quota.LimitsFrom(cfg))
+	db := store.WithCache(store.WithActivity(store.WithEvents(quota.WithQuotas(base, quotas), broker)), readCache, cfg.CACHE_TTL)
+
+	waitBackground := func() {}
+	if cfg.JOB_WORKER_ENABLED {
+		waitBackground, err = background.Start(backgroundCtx, cfg, pool, db)
+		if err != nil {
+			logging.Fatal("Failed to start background workers", "error", err)
+			return
+		}
+	}
+
+	// Requests enqueue jobs, like notifications, for the job worker to run.
+	queue := jobs.NewPostgresQueue(pool)
+
+	featureFlags := flags.New(flags.NewPostgresStore(pool))
+
+	// Attachments and data exports are only offered when there's a bucket to
+	// put them in.
+	files, err := background.NewFiles(cfg)
+	if err != nil {
+		logging.Fatal("Failed to configure file storage", "error", err)
+		return
+	}
+
+	var limiter ratelimit.Limiter = ratelimit.NewMemory()
+	if redisClient != nil {
+		limiter = ratelimit.Fallback{Primary: ratelimit.Redis{Client: redisClient}, Secondary: limiter}
+	}
+
+	var serviceTokens *servicetokens.Issuer
+	if cfg.SERVICE_TOKEN_SECRET != "" {
+		serviceTokens, err = servicetokens.NewIssuer(cfg.SERVICE_TOKEN_SECRET)
+		if err != nil {
+			logging.Fatal("Failed to configure service tokens", "error", err)
+			return
+		}
+	}
+
+	var reporter errorreport.Reporter = errorreport.Nop{}
+	if cfg.SENTRY_DSN != "" {
+		sentry, err := errorreport.NewSentry(cfg.SENTRY_DSN, cfg.ENV, cfg.INSTANCE_ID)
+		if err != nil {
+			logging.Fatal("Invalid SENTRY_DSN", "error", err)
+			return
+		}
+		sentry.Start(backgroundCtx)
+		reporter = sentry
+	}
+
+	readiness := map[string]handlers.ReadinessCheck{
+		"database": pool.Ping,
+		"migrations": func(ctx context.Context) error {
+			pending, err := migrations.Pending(ctx, pool)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				return fmt.Errorf("%d pending, next is %04d", len(pending), pending[0])
+			}
+			return nil
+		},
+	}
+	if redisClient != nil {
+		readiness["redis"] = func(ctx context.Context) error {
+			return redisClient.Ping(ctx).Err()
+		}
+	}
+
+	handler, err := server.New(cfg, db, server.Deps{
+		Broker:        broker,
+		Queue:         queue,
+		Flags:         featureFlags,
+		Files:         files,
+		Limiter:       limiter,
+		Reporter:      reporter,
+		ServiceTokens: serviceTokens,
+		SessionKeys:   sessionKeys,
+		Readiness:     readiness,
+		Collectors:    []prometheus.Collector{metrics.PoolCollector{Targets: replicas.Targets()}, metrics.QueueCollector{Queue: queue}},
+	})
+	if err != nil {
+		logging.Fatal("Failed to set up the API", "error", err)
+		return
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + cfg.PORT,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Shutdown doesn't wait on hijacked sockets and would wait out the
+	// timeout on event streams; closing the broker ends both.
+	srv.RegisterOnShutdown(broker.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	redirectSrv := listen(cfg, srv)
+
+	// The gRPC API has its own port; it's for the same services as the
+	// /api/v1/service routes and takes the same tokens.
+	var grpcSrv *grpc.Server
+	if cfg.GRPC_PORT != "" {
+		lis, err := net.Listen("tcp", ":"+cfg.GRPC_PORT)
+		if err != nil {
+			logging.Fatal("Failed to listen for gRPC", "port", cfg.GRPC_PORT, "error", err)
+			return
+		}
+		grpcSrv = rpc.NewServer(serviceTokens, rpc.Stores{
+			Tasks:       db.Tasks(),
+			Projects:    db.Projects(),
+			Workflows:   db.Workflows(),
+			OrgSettings: db.OrgSettings(),
+		})
+		go func() {
+			if err := grpcSrv.Serve(lis); err != nil {
+				logging.Fatal("gRPC server failed", "port", cfg.GRPC_PORT, "error", err)
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Shutting down", "timeout", cfg.SHUTDOWN_TIMEOUT)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.SHUTDOWN_TIMEOUT)
+	defer cancel()
+
+	if redirectSrv != nil {
+		redirectSrv.Shutdown(shutdownCtx)
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Requests still running at shutdown", "error", err)
+	}
+	if grpcSrv != nil && !waitFor(shutdownCtx, grpcSrv.GracefulStop) {
+		grpcSrv.Stop()
+	}
+
+	stopBackground()
+	if !waitFor(shutdownCtx, waitBackground) {
+		slog.Warn("Jobs still running at shutdown; they'll be retried once their lease expires")
+	}
+
+	// Deferred: close Redis and the pools, then flush traces.
+	slog.Info("Server stopped")
+}
+
+// waitFor runs wait and reports whether it returned before ctx was done.
+func waitFor(ctx context.Context, wait func()) bool {
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
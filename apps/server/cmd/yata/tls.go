@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/logging"
+
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// listen serves srv in the background: over HTTPS with the configured
+// certificate files or Let's Encrypt certificates, otherwise plain HTTP.
+// With TLS_REDIRECT_HTTP it also returns the HTTP server that redirects to
+// HTTPS (and answers ACME challenges), to be shut down alongside srv.
+func listen(cfg *config.Config, srv *http.Server) *http.Server {
+	var redirect http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host, _, err := net.SplitHostPort(r.Host)
+		if err != nil {
+			host = r.Host
+		}
+		if cfg.PORT != "443" {
+			host = net.JoinHostPort(host, cfg.PORT)
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+
+	serve := func(run func() error) {
+		go func() {
+			if err := run(); !errors.Is(err, http.ErrServerClosed) {
+				logging.Fatal("Server failed", "addr", srv.Addr, "error", err)
+			}
+		}()
+	}
+
+	switch {
+	case len(cfg.TLS_AUTOCERT_DOMAINS) > 0:
+		manager := &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.TLS_AUTOCERT_DOMAINS...),
+			Cache:      autocert.DirCache(cfg.TLS_AUTOCERT_CACHE_DIR),
+			Email:      cfg.TLS_AUTOCERT_EMAIL,
+		}
+		// The TLS config answers TLS-ALPN-01 challenges itself; the HTTP
+		// listener adds HTTP-01 for when port 443 isn't reachable directly.
+		srv.TLSConfig = manager.TLSConfig()
+		srv.TLSConfig.MinVersion = tls.VersionTLS12
+		redirect = manager.HTTPHandler(redirect)
+		serve(func() error { return srv.ListenAndServeTLS("", "") })
+		slog.Info("Server listening with Let's Encrypt certificates", "addr", srv.Addr, "domains", cfg.TLS_AUTOCERT_DOMAINS)
+	case cfg.TLS_CERT_FILE != "":
+		srv.TLSConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		serve(func() error { return srv.ListenAndServeTLS(cfg.TLS_CERT_FILE, cfg.TLS_KEY_FILE) })
+		slog.Info("Server listening with TLS", "addr", srv.Addr)
+	default:
+		serve(srv.ListenAndServe)
+		slog.Info("Server listening", "addr", srv.Addr)
+		return nil
+	}
+
+	if !cfg.TLS_REDIRECT_HTTP {
+		return nil
+	}
+	redirectSrv := &http.Server{
+		Addr:              ":" + cfg.HTTP_PORT,
+		Handler:           redirect,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		if err := redirectSrv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			logging.Fatal("HTTP redirect server failed", "addr", redirectSrv.Addr, "error", err)
+		}
+	}()
+	slog.Info("Redirecting HTTP to HTTPS", "addr", redirectSrv.Addr)
+	return redirectSrv
+}
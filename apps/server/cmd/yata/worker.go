@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+	"yata/apps/server/internal/background"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/logging"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/tracing"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+)
+
+// worker runs background jobs and schedulers without serving HTTP, for
+// deployments that set JOB_WORKER_ENABLED=false on the API.
+func worker(cfg *config.Config, _ []string) {
+	shutdownTracing, err := tracing.Setup(context.Background(), "yata-worker", cfg.ENV)
+	if err != nil {
+		logging.Fatal("Failed to set up tracing", "error", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	var databaseURL atomic.Value
+	databaseURL.Store(cfg.DATABASE_URL)
+
+	pool, err := database.Connect(cfg.DATABASE_URL,
+		database.WithApplicationName("yata-worker-"+cfg.ENV+"-"+cfg.INSTANCE_ID, false),
+		database.WithStatementTimeout(cfg.DB_STATEMENT_TIMEOUT),
+		database.WithTracing(),
+		database.WithRotatingCredentials(func() string { return databaseURL.Load().(string) }),
+	)
+	if err != nil {
+		logging.Fatal("Failed to connect to the database", "error", err)
+	}
+	defer pool.Close()
+
+	clerk.SetKey(cfg.CLERK_SECRET_KEY)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	cfg.WatchSecrets(ctx, cfg.SECRET_REFRESH_INTERVAL, map[string]func(string){
+		"DATABASE_URL":     func(v string) { databaseURL.Store(v) },
+		"CLERK_SECRET_KEY": clerk.SetKey,
+	})
+
+	db := store.NewPostgres(pool, encryptedFields(cfg, pool))
+	wait, err := background.Start(ctx, cfg, pool, quota.WithQuotas(db, quota.New(db.Usage(), quota.LimitsFrom(cfg))))
+	if err != nil {
+		logging.Fatal("Failed to start background workers", "error", err)
+	}
+	slog.Info("Worker started")
+
+	<-ctx.Done()
+	stop()
+	slog.Info("Worker shutting down", "timeout", cfg.SHUTDOWN_TIMEOUT)
+
+	done := make(chan struct{})
+	go func() {
+		wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		slog.Info("Worker stopped")
+	case <-time.After(cfg.SHUTDOWN_TIMEOUT):
+		slog.Warn("Jobs still running at shutdown; they'll be retried once their lease expires")
+	}
+}
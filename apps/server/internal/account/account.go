@@ -0,0 +1,60 @@
+// Package account exports and erases everything kept about a user, for
+// users exercising their rights over their data.
+package account
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/user"
+)
+
+// Deleter deletes the user's sign-in, ending every session they have.
+type Deleter interface {
+	Delete(ctx context.Context, userID string) error
+}
+
+// ClerkDeleter deletes the Clerk user. Clerk's webhook then erases their
+// data here too, if it's still there.
+type ClerkDeleter struct{}
+
+// Delete treats a user Clerk no longer has as deleted.
+func (ClerkDeleter) Delete(ctx context.Context, userID string) error {
+	_, err := user.Delete(ctx, userID)
+	var apiErr *clerk.APIErrorResponse
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
+
+// Eraser erases a user's data, and the archives of it they exported. Files
+// is nil when there's no object storage, and so no archives.
+type Eraser struct {
+	Accounts store.AccountStore
+	Files    storage.Storage
+}
+
+// Erase can be repeated; it's run when the user asks, and again when Clerk
+// says they're gone. Archives that can't be deleted are logged and left to
+// the bucket's lifecycle rules rather than failing the erase.
+func (e Eraser) Erase(ctx context.Context, userID string) error {
+	erased, err := e.Accounts.Erase(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if e.Files == nil {
+		return nil
+	}
+	for _, key := range erased.ExportKeys {
+		if err := e.Files.Delete(ctx, key); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete data export", "key", key, "error", err)
+		}
+	}
+	return nil
+}
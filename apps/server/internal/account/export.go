@@ -0,0 +1,123 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+)
+
+// JobKind is the job that builds a data export.
+const JobKind = "data_export"
+
+// ArchiveName is the file name exports download as.
+const ArchiveName = "yata-export.zip"
+
+type jobPayload struct {
+	ExportID string `json:"exportId"`
+}
+
+// Enqueue schedules the export. It runs once, like imports; a failed one
+// says so on the export and the user can ask again.
+func Enqueue(ctx context.Context, queue jobs.Queue, exportID string) error {
+	return queue.Enqueue(ctx, JobKind, jobPayload{ExportID: exportID}, jobs.MaxAttempts(1))
+}
+
+// JobHandler collects the user's data into a zip of JSON files and puts it
+// in object storage, where it can be downloaded for models.DataExportTTL.
+func JobHandler(accounts store.AccountStore, files storage.Storage) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var p jobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+
+		export, err := accounts.StartExport(ctx, p.ExportID)
+		if errors.Is(err, store.ErrNotFound) {
+			slog.WarnContext(ctx, "Data export already started", "export", p.ExportID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		key, size, err := build(ctx, accounts, files, export.UserID)
+		// Recorded even if ctx was cancelled by a shutdown.
+		ctx = context.WithoutCancel(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Data export failed", "export", export.ID, "error", err)
+			return accounts.FailExport(ctx, export.ID, "The export couldn't be built; please try again")
+		}
+
+		err = accounts.FinishExport(ctx, export.ID, key, size, time.Now().Add(models.DataExportTTL))
+		if errors.Is(err, store.ErrNotFound) {
+			// The user was erased while it was being built.
+			return files.Delete(ctx, key)
+		}
+		return err
+	}
+}
+
+func build(ctx context.Context, accounts store.AccountStore, files storage.Storage, userID string) (string, int64, error) {
+	data, err := accounts.Collect(ctx, userID)
+	if err != nil {
+		return "", 0, err
+	}
+	archive, err := Archive(data)
+	if err != nil {
+		return "", 0, err
+	}
+	key := storage.NewKey("exports", ArchiveName)
+	size := int64(len(archive))
+	if err := files.Put(ctx, key, "application/zip", bytes.NewReader(archive), size); err != nil {
+		return "", 0, err
+	}
+	return key, size, nil
+}
+
+// Archive lays the data out as one JSON file per kind of thing.
+func Archive(data *models.AccountData) ([]byte, error) {
+	sections := []struct {
+		name  string
+		value any
+	}{
+		{"profile.json", map[string]any{
+			"user":             data.User,
+			"memberships":      data.Memberships,
+			"settings":         data.Settings,
+			"emailPreferences": data.EmailPreferences,
+		}},
+		{"tasks.json", data.Tasks},
+		{"comments.json", data.Comments},
+		{"time_entries.json", data.TimeEntries},
+		{"pomodoros.json", data.Pomodoros},
+		{"activity.json", data.Activity},
+		{"notifications.json", data.Notifications},
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	now := time.Now()
+	for _, s := range sections {
+		w, err := zw.CreateHeader(&zip.FileHeader{Name: s.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return nil, err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(s.value); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
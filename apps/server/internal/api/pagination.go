@@ -0,0 +1,135 @@
+package api
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	DefaultLimit = 50
+	MaxLimit     = 200
+
+	maxCursorLength = 1024
+	signatureLength = 16
+)
+
+var (
+	ErrInvalidCursor = errors.New("invalid cursor")
+	ErrInvalidLimit  = errors.New("invalid limit")
+)
+
+var cursorKey = randomKey()
+
+func randomKey() []byte {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return b
+}
+
+// SetCursorSecret sets the HMAC key cursors are signed with. Without it a
+// random per-process key is used, so cursors don't survive restarts.
+func SetCursorSecret(secret string) {
+	if secret != "" {
+		cursorKey = []byte(secret)
+	}
+}
+
+type PageInfo struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	HasMore    bool   `json:"hasMore"`
+}
+
+func sign(kind string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, cursorKey)
+	mac.Write([]byte(kind))
+	mac.Write([]byte{0})
+	mac.Write(payload)
+	return mac.Sum(nil)[:signatureLength]
+}
+
+// EncodeCursor returns an opaque, signed cursor for the given sort key. kind
+// ties the cursor to one listing so it can't be replayed against another.
+func EncodeCursor(kind string, values ...string) string {
+	payload, _ := json.Marshal(values)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString(payload) + "." + enc.EncodeToString(sign(kind, payload))
+}
+
+func DecodeCursor(kind, cursor string) ([]string, error) {
+	if len(cursor) > maxCursorLength {
+		return nil, ErrInvalidCursor
+	}
+
+	rawPayload, rawSig, ok := strings.Cut(cursor, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	sig, err := enc.DecodeString(rawSig)
+	if err != nil || !hmac.Equal(sig, sign(kind, payload)) {
+		return nil, ErrInvalidCursor
+	}
+
+	var values []string
+	if err := json.Unmarshal(payload, &values); err != nil || len(values) == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return values, nil
+}
+
+// ParsePage reads ?limit= and ?cursor= for the listing identified by kind.
+func ParsePage(c *gin.Context, kind string) (models.Page, error) {
+	page := models.Page{Limit: DefaultLimit}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			return page, ErrInvalidLimit
+		}
+		page.Limit = min(limit, MaxLimit)
+	}
+
+	if raw := c.Query("cursor"); raw != "" {
+		after, err := DecodeCursor(kind, raw)
+		if err != nil {
+			return page, err
+		}
+		page.After = after
+	}
+
+	return page, nil
+}
+
+// PageError writes the 400 response for an error returned by ParsePage.
+func PageError(c *gin.Context, err error) {
+	if errors.Is(err, ErrInvalidLimit) {
+		apierror.Respond(c, &apierror.Error{Status: http.StatusBadRequest, Code: "INVALID_LIMIT", Message: "Invalid limit"})
+		return
+	}
+	apierror.Respond(c, &apierror.Error{Status: http.StatusBadRequest, Code: "INVALID_CURSOR", Message: "Invalid cursor"})
+}
+
+// Trim cuts a result fetched with limit+1 rows down to the page size and
+// reports whether there are more rows.
+func Trim[T any](items []T, limit int) ([]T, bool) {
+	if len(items) > limit {
+		return items[:limit], true
+	}
+	return items, false
+}
@@ -0,0 +1,255 @@
+// Package apierror is the errors handlers can pass to gin with c.Error and
+// leave middlewares.Errors to turn into a response, so the same failure gets
+// the same status, code and body everywhere.
+package apierror
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// Codes clients can branch on; the message is for people and is translated.
+const (
+	CodeUnauthorized = "UNAUTHORIZED"
+	CodeNotFound     = "NOT_FOUND"
+	CodeValidation   = "VALIDATION_FAILED"
+	CodeConflict     = "CONFLICT"
+	CodeForbidden    = "FORBIDDEN"
+	CodeTimeout      = "TIMEOUT"
+	CodeInternal     = "INTERNAL"
+
+	CodeBadRequest           = "BAD_REQUEST"
+	CodePaymentRequired      = "PAYMENT_REQUIRED"
+	CodeMethodNotAllowed     = "METHOD_NOT_ALLOWED"
+	CodeGone                 = "GONE"
+	CodePreconditionFailed   = "PRECONDITION_FAILED"
+	CodeTooLarge             = "TOO_LARGE"
+	CodeUnsupportedMedia     = "UNSUPPORTED_MEDIA_TYPE"
+	CodeRateLimited          = "RATE_LIMITED"
+	CodePreconditionRequired = "PRECONDITION_REQUIRED"
+	CodeUnavailable          = "UNAVAILABLE"
+	CodeBadGateway           = "BAD_GATEWAY"
+)
+
+// statusCodes are the codes New gives errors with nothing more specific
+// than a status.
+var statusCodes = map[int]string{
+	http.StatusBadRequest:            CodeBadRequest,
+	http.StatusUnauthorized:          CodeUnauthorized,
+	http.StatusPaymentRequired:       CodePaymentRequired,
+	http.StatusForbidden:             CodeForbidden,
+	http.StatusNotFound:              CodeNotFound,
+	http.StatusMethodNotAllowed:      CodeMethodNotAllowed,
+	http.StatusConflict:              CodeConflict,
+	http.StatusGone:                  CodeGone,
+	http.StatusPreconditionFailed:    CodePreconditionFailed,
+	http.StatusRequestEntityTooLarge: CodeTooLarge,
+	http.StatusUnsupportedMediaType:  CodeUnsupportedMedia,
+	http.StatusUnprocessableEntity:   CodeValidation,
+	http.StatusPreconditionRequired:  CodePreconditionRequired,
+	http.StatusTooManyRequests:       CodeRateLimited,
+	http.StatusBadGateway:            CodeBadGateway,
+	http.StatusServiceUnavailable:    CodeUnavailable,
+	http.StatusGatewayTimeout:        CodeTimeout,
+}
+
+// CodeFor is the code for an error that has only a status; anything not
+// listed is BAD_REQUEST or INTERNAL by its class.
+func CodeFor(status int) string {
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+// Error is an API error. Cause is logged for 5xx errors and never sent.
+type Error struct {
+	Status  int
+	Code    string
+	Message string
+	Details any
+	Cause   error
+	// RetryAfter is the least a client should wait before retrying. Errors
+	// with a retryable status are sent with a Retry-After of at least the
+	// request's RetryPolicy.Base whether it's set or not.
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Cause != nil {
+		return e.Message + ": " + e.Cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Cause
+}
+
+// Body is the JSON the error is sent as, the envelope every error response
+// has: {code, message, details, request_id}. The request ID is added on the
+// way out by middlewares.RequestID.
+func (e *Error) Body() gin.H {
+	body := gin.H{"code": e.Code, "message": e.Message}
+	if e.Details != nil {
+		body["details"] = e.Details
+	}
+	return body
+}
+
+// WithDetails returns a copy of e carrying details, such as which fields
+// failed validation.
+func (e *Error) WithDetails(details any) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// WithRetryAfter returns a copy of e telling clients to wait at least d
+// before retrying.
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	copied := *e
+	copied.RetryAfter = d
+	return &copied
+}
+
+// retryable are the statuses that always go out with Retry-After: the
+// request may well succeed later as it is.
+func (e *Error) retryable() bool {
+	switch e.Status {
+	case http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return e.RetryAfter > 0
+}
+
+// New is an error with the code status maps to, for failures with nothing
+// more specific to say.
+func New(status int, message string) *Error {
+	return &Error{Status: status, Code: CodeFor(status), Message: message}
+}
+
+func Unauthorized(message string) *Error {
+	return &Error{Status: http.StatusUnauthorized, Code: CodeUnauthorized, Message: message}
+}
+
+func NotFound(message string) *Error {
+	return &Error{Status: http.StatusNotFound, Code: CodeNotFound, Message: message}
+}
+
+func Validation(message string) *Error {
+	return &Error{Status: http.StatusBadRequest, Code: CodeValidation, Message: message}
+}
+
+func Conflict(message string) *Error {
+	return &Error{Status: http.StatusConflict, Code: CodeConflict, Message: message}
+}
+
+func Forbidden(message string) *Error {
+	return &Error{Status: http.StatusForbidden, Code: CodeForbidden, Message: message}
+}
+
+// Internal is for failures the caller can only retry; message says what
+// failed ("Failed to create task") and cause is why. When the cause is the
+// database being too busy to answer, it's a 503 to retry later instead.
+func Internal(message string, cause error) *Error {
+	if busy(cause) {
+		return &Error{Status: http.StatusServiceUnavailable, Code: CodeUnavailable, Message: message, Cause: cause}
+	}
+	return &Error{Status: http.StatusInternalServerError, Code: CodeInternal, Message: message, Cause: cause}
+}
+
+// busyStates are the SQLSTATEs of a database turning work away that would
+// go through once it's less loaded.
+var busyStates = map[string]bool{
+	"53300": true, // too_many_connections
+	"55P03": true, // lock_not_available
+	"40001": true, // serialization_failure
+	"40P01": true, // deadlock_detected
+}
+
+func busy(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && busyStates[pgErr.Code]
+}
+
+// From maps err onto an API error: API errors as they are, missing rows as
+// not found, duplicates, including a unique violation reaching here
+// straight from pgx, as conflicts, and the rest as Internal(message, err).
+func From(err error, message string) *Error {
+	var apiErr *Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, store.ErrNotFound):
+		return NotFound("Not found")
+	case errors.Is(err, store.ErrConflict):
+		return Conflict("Already exists")
+	case errors.As(err, &pgErr) && pgErr.Code == "23505":
+		return Conflict("Already exists")
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Status: http.StatusGatewayTimeout, Code: CodeTimeout, Message: "Request timed out", Cause: err}
+	}
+	return Internal(message, err)
+}
+
+// CodeKey is where the code of the error a request failed with is kept on
+// the gin context, for middlewares.RecordRecentErrors.
+const CodeKey = "errorCode"
+
+// Abort records err for middlewares.Errors to respond with and stops the
+// handler chain.
+func Abort(c *gin.Context, err *Error) {
+	c.Error(err)
+	c.Abort()
+}
+
+// RetryPolicyKey is where middlewares.Errors keeps the RetryPolicy on the
+// gin context for Respond.
+const RetryPolicyKey = "retryPolicy"
+
+// RetryPolicy decides the Retry-After clients are sent, so that the ones
+// turned away together don't all come back at the same moment.
+type RetryPolicy struct {
+	// Base is the least any client is told to wait.
+	Base time.Duration
+	// Jitter is the most added on top, picked at random per response.
+	Jitter time.Duration
+}
+
+// Seconds is the Retry-After for a client that has to wait at least wait:
+// the longer of wait and Base, plus jitter, in whole seconds and at least
+// one.
+func (p RetryPolicy) Seconds(wait time.Duration) int {
+	d := max(wait, p.Base)
+	if p.Jitter > 0 {
+		d += rand.N(p.Jitter + 1)
+	}
+	return max(int(math.Ceil(d.Seconds())), 1)
+}
+
+// Respond sends err as the response straight away, with Retry-After if it's
+// retryable. Causes of 5xx errors are logged by the caller; Respond never
+// sends them.
+func Respond(c *gin.Context, err *Error) {
+	if err.retryable() {
+		v, _ := c.Get(RetryPolicyKey)
+		policy, _ := v.(RetryPolicy)
+		c.Header("Retry-After", strconv.Itoa(policy.Seconds(err.RetryAfter)))
+	}
+	c.Set(CodeKey, err.Code)
+	c.JSON(err.Status, err.Body())
+}
@@ -0,0 +1,55 @@
+// Package archive moves long-finished tasks out of the default listings.
+package archive
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/store"
+)
+
+const batchSize = 1000
+
+// Archiver archives done tasks that haven't changed in After, checking every
+// Interval. A task's last update stands in for when it was completed.
+type Archiver struct {
+	Tasks    store.TaskStore
+	After    time.Duration
+	Interval time.Duration
+}
+
+func NewArchiver(tasks store.TaskStore, after, interval time.Duration) *Archiver {
+	return &Archiver{Tasks: tasks, After: after, Interval: interval}
+}
+
+// Start runs the archiver until ctx is done.
+func (a *Archiver) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(a.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				a.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run archives everything currently eligible, a batch at a time.
+func (a *Archiver) Run(ctx context.Context) {
+	before := time.Now().Add(-a.After)
+	for {
+		n, err := a.Tasks.ArchiveCompleted(ctx, before, batchSize)
+		if err != nil {
+			slog.Error("Failed to archive completed tasks", "error", err)
+			return
+		}
+		if n < batchSize {
+			return
+		}
+	}
+}
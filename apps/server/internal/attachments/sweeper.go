@@ -0,0 +1,61 @@
+// Package attachments cleans up after uploads that were presigned but never
+// confirmed.
+package attachments
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+)
+
+// Sweeper deletes attachments left pending for more than TTL, checking
+// every Interval. Deleting the rows releases the bytes they held of the
+// org's quota; their objects are deleted after, as far as they can be, and
+// a missed one is only left behind in the bucket. Confirmed attachments are
+// never touched.
+type Sweeper struct {
+	Attachments store.AttachmentStore
+	Files       storage.Storage
+	TTL         time.Duration
+	Interval    time.Duration
+}
+
+func NewSweeper(attachments store.AttachmentStore, files storage.Storage, ttl, interval time.Duration) *Sweeper {
+	return &Sweeper{Attachments: attachments, Files: files, TTL: ttl, Interval: interval}
+}
+
+// Start runs the sweeper until ctx is done.
+func (s *Sweeper) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run sweeps once.
+func (s *Sweeper) Run(ctx context.Context) {
+	keys, err := s.Attachments.DeletePending(ctx, time.Now().Add(-s.TTL))
+	if err != nil {
+		slog.Error("Failed to delete pending attachments", "error", err)
+		return
+	}
+	for _, key := range keys {
+		if err := s.Files.Delete(ctx, key); err != nil {
+			slog.Warn("Failed to delete a pending attachment's object", "key", key, "error", err)
+		}
+	}
+	if len(keys) > 0 {
+		slog.Info("Swept pending attachments", "attachments", len(keys))
+	}
+}
@@ -0,0 +1,91 @@
+package attachments
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+)
+
+// files records the keys deleted from it, and fails to delete "missing".
+type files struct {
+	storage.Storage
+	deleted []string
+}
+
+func (f *files) Delete(_ context.Context, key string) error {
+	if key == "missing" {
+		return errors.New("no such key")
+	}
+	f.deleted = append(f.deleted, key)
+	return nil
+}
+
+func TestSweeper(t *testing.T) {
+	ctx := context.Background()
+	base := store.NewMemory()
+	db := quota.WithQuotas(base, quota.New(base.Usage(), quota.Limits{AttachmentBytes: 100}))
+	org := models.Scope{UserID: "user_1", OrgID: "org_1"}
+
+	task, err := db.Tasks().Create(ctx, org, models.CreateTaskInput{Title: "upload"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	attach := func(key string, size int64) *models.Attachment {
+		t.Helper()
+		a, err := db.Attachments().Create(ctx, org, task.ID, models.CreateAttachmentInput{Filename: key, ContentType: "text/plain", Size: size, Key: key})
+		if err != nil {
+			t.Fatalf("attach %s: %v", key, err)
+		}
+		return a
+	}
+	confirmed := attach("confirmed", 20)
+	if _, err := db.Attachments().Confirm(ctx, org, task.ID, confirmed.ID); err != nil {
+		t.Fatal(err)
+	}
+	attach("pending", 60)
+	attach("missing", 10)
+
+	// The pending uploads hold the rest of the quota.
+	var exceeded *quota.ExceededError
+	if _, err := db.Attachments().Create(ctx, org, task.ID, models.CreateAttachmentInput{Filename: "more", ContentType: "text/plain", Size: 30, Key: "more"}); !errors.As(err, &exceeded) {
+		t.Fatalf("attach past the quota: err = %v, want it exceeded", err)
+	}
+
+	f := &files{}
+	sweeper := NewSweeper(db.Attachments(), f, time.Hour, time.Hour)
+	sweeper.Run(ctx)
+	if len(f.deleted) != 0 {
+		t.Fatalf("deleted %v before the TTL, want nothing", f.deleted)
+	}
+
+	sweeper.TTL = 0
+	sweeper.Run(ctx)
+	if !slices.Equal(f.deleted, []string{"pending"}) {
+		t.Errorf("deleted objects %v, want [pending]", f.deleted)
+	}
+
+	usage, err := base.Usage().Get(ctx, org.OrgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The row of "missing" goes too, though its object couldn't be deleted.
+	if usage.AttachmentBytes != 20 {
+		t.Errorf("attachment bytes = %d, want 20 left for the confirmed one", usage.AttachmentBytes)
+	}
+	list, err := db.Attachments().List(ctx, org, task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].ID != confirmed.ID {
+		t.Errorf("attachments = %+v, want the confirmed one kept", list)
+	}
+	if _, err := db.Attachments().Create(ctx, org, task.ID, models.CreateAttachmentInput{Filename: "more", ContentType: "text/plain", Size: 30, Key: "more"}); err != nil {
+		t.Errorf("attach after the sweep: %v", err)
+	}
+}
@@ -0,0 +1,130 @@
+// Package audit streams the org audit trail, the activity entries of org
+// tasks and projects, to sinks outside the database for SIEMs and
+// compliance archives. Each sink reads the trail from a cursor of its own
+// kept in Postgres, so a sink that's slow or down falls behind without
+// holding up writes or the other sinks, and picks up where it left off.
+// Delivery is at least once: a batch whose cursor fails to move is sent
+// again, with the same event ids.
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+// Schema names the shape of Event, for consumers to check; it changes if a
+// field is ever removed or changes meaning.
+const Schema = "yata.audit.v1"
+
+// Event is an audit entry as sinks get it.
+type Event struct {
+	Schema string    `json:"schema"`
+	ID     string    `json:"id"`
+	Time   time.Time `json:"time"`
+	OrgID  string    `json:"orgId"`
+	// ActorID is the Clerk user who made the change.
+	ActorID string `json:"actorId"`
+	// Action is one of the models.Activity* actions, like "task.updated".
+	Action    string                        `json:"action"`
+	Target    Target                        `json:"target"`
+	ProjectID *string                       `json:"projectId,omitempty"`
+	Changes   map[string]models.FieldChange `json:"changes"`
+}
+
+// Target is what the action was taken on.
+type Target struct {
+	// Type is "task" or "project".
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+func newEvent(a models.Activity) Event {
+	e := Event{
+		Schema:    Schema,
+		ID:        a.ID,
+		Time:      a.CreatedAt.UTC(),
+		ActorID:   a.ActorID,
+		Action:    a.Action,
+		ProjectID: a.ProjectID,
+		Changes:   a.Changes,
+	}
+	if a.OrgID != nil {
+		e.OrgID = *a.OrgID
+	}
+	if a.TaskID != nil {
+		e.Target = Target{Type: "task", ID: *a.TaskID}
+	} else if a.ProjectID != nil {
+		e.Target = Target{Type: "project", ID: *a.ProjectID}
+	}
+	return e
+}
+
+// Sink is somewhere events are streamed to. Write delivers a batch, in
+// order, and only returns nil once the sink has taken all of it.
+type Sink interface {
+	// Name identifies the sink in metrics, logs and its cursor. It's the
+	// sink's URL without credentials, so it stays the same across restarts.
+	Name() string
+	Write(ctx context.Context, events []Event) error
+	Close() error
+}
+
+// BusyError is a sink asking to be left alone for a while, as with an HTTP
+// 429; the streamer waits at least After before sending it more.
+type BusyError struct {
+	After time.Duration
+	Err   error
+}
+
+func (e *BusyError) Error() string { return e.Err.Error() }
+func (e *BusyError) Unwrap() error { return e.Err }
+
+// NewSink makes the sink a URL describes:
+//
+//	file:///var/log/yata/audit.jsonl    JSON lines appended to a file
+//	syslog:                             the local syslog daemon
+//	syslog+udp://host:514               a remote one, or syslog+tcp://
+//	https://siem.example.com/ingest     batches POSTed as JSON lines
+//	kafka+https://proxy:8082/topics/t   records produced through a Kafka REST proxy
+//
+// token, when set, is sent as a bearer token to the HTTPS and Kafka sinks.
+// Nothing is opened or dialed until the first Write.
+func NewSink(raw, token string) (Sink, error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	if u.User != nil {
+		return nil, errors.New("credentials don't go in the sink URL")
+	}
+	switch u.Scheme {
+	case "file":
+		if u.Host != "" || u.Path == "" {
+			return nil, fmt.Errorf("%q is not file:// and an absolute path", raw)
+		}
+		return newFileSink(u.Path), nil
+	case "syslog", "syslog+udp", "syslog+tcp":
+		network := strings.TrimPrefix(strings.TrimPrefix(u.Scheme, "syslog"), "+")
+		if (network == "") != (u.Host == "") {
+			return nil, fmt.Errorf("%q needs a host with syslog+udp or syslog+tcp, and only then", raw)
+		}
+		return newSyslogSink(network, u.Host), nil
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("%q has no host", raw)
+		}
+		return newHTTPSink(u, token), nil
+	case "kafka+http", "kafka+https":
+		topic, ok := strings.CutPrefix(u.Path, "/topics/")
+		if u.Host == "" || !ok || topic == "" || strings.Contains(topic, "/") {
+			return nil, fmt.Errorf("%q is not a REST proxy /topics/<topic> URL", raw)
+		}
+		return newKafkaSink(u, token), nil
+	}
+	return nil, fmt.Errorf("%q: unknown sink %q", raw, u.Scheme)
+}
@@ -0,0 +1,239 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// defaultRetryAfter is how long a busy HTTP sink is left alone when it
+// doesn't say.
+const defaultRetryAfter = 30 * time.Second
+
+// jsonLines is events one JSON object per line.
+func jsonLines(events []Event) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+// fileSink appends to a file it syncs after every batch. Each instance
+// streaming to it writes its own copy of the file, so it wants the API on
+// one instance, a shared volume, or a log shipper on every host.
+type fileSink struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileSink(path string) *fileSink {
+	return &fileSink{path: path}
+}
+
+func (s *fileSink) Name() string { return "file://" + s.path }
+
+func (s *fileSink) Write(_ context.Context, events []Event) error {
+	body, err := jsonLines(events)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		if s.file, err = os.OpenFile(s.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600); err != nil {
+			return err
+		}
+	}
+	if _, err := s.file.Write(body); err != nil {
+		return err
+	}
+	return s.file.Sync()
+}
+
+func (s *fileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// syslogSink sends each event as one message, tagged yata, from the auth
+// facility. A failed write drops the connection for the next to redial.
+type syslogSink struct {
+	network, addr string
+
+	mu     sync.Mutex
+	writer *syslog.Writer
+}
+
+func newSyslogSink(network, addr string) *syslogSink {
+	return &syslogSink{network: network, addr: addr}
+}
+
+func (s *syslogSink) Name() string {
+	if s.network == "" {
+		return "syslog:"
+	}
+	return "syslog+" + s.network + "://" + s.addr
+}
+
+func (s *syslogSink) Write(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writer == nil {
+		w, err := syslog.Dial(s.network, s.addr, syslog.LOG_INFO|syslog.LOG_AUTH, "yata")
+		if err != nil {
+			return err
+		}
+		s.writer = w
+	}
+	for _, e := range events {
+		msg, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		if err := s.writer.Info(string(msg)); err != nil {
+			s.writer.Close()
+			s.writer = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.writer == nil {
+		return nil
+	}
+	err := s.writer.Close()
+	s.writer = nil
+	return err
+}
+
+// poster POSTs batches for the HTTP and Kafka sinks. A 429 or 503 is the
+// endpoint asking for less, and comes back as a BusyError.
+type poster struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+func newPoster(u *url.URL, token string) poster {
+	return poster{url: u.String(), token: token, client: &http.Client{Timeout: 30 * time.Second}}
+}
+
+func (p poster) post(ctx context.Context, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", "yata-audit/1")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable:
+		after := defaultRetryAfter
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+			after = time.Duration(seconds) * time.Second
+		}
+		return &BusyError{After: after, Err: fmt.Errorf("sink answered %d", resp.StatusCode)}
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return fmt.Errorf("sink answered %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// httpSink POSTs each batch as JSON lines, which most SIEM collectors take
+// as they are.
+type httpSink struct {
+	name string
+	poster
+}
+
+func newHTTPSink(u *url.URL, token string) *httpSink {
+	return &httpSink{name: nameOf(u), poster: newPoster(u, token)}
+}
+
+func (s *httpSink) Name() string { return s.name }
+
+func (s *httpSink) Write(ctx context.Context, events []Event) error {
+	body, err := jsonLines(events)
+	if err != nil {
+		return err
+	}
+	return s.post(ctx, "application/x-ndjson", body)
+}
+
+func (s *httpSink) Close() error { return nil }
+
+// kafkaSink produces to a topic through a Confluent-compatible REST proxy,
+// keyed by org so each org's events stay in order on one partition.
+type kafkaSink struct {
+	name string
+	poster
+}
+
+func newKafkaSink(u *url.URL, token string) *kafkaSink {
+	proxy := *u
+	proxy.Scheme = u.Scheme[len("kafka+"):]
+	return &kafkaSink{name: nameOf(u), poster: newPoster(&proxy, token)}
+}
+
+func (s *kafkaSink) Name() string { return s.name }
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+func (s *kafkaSink) Write(ctx context.Context, events []Event) error {
+	records := make([]kafkaRecord, len(events))
+	for i, e := range events {
+		records[i] = kafkaRecord{Key: e.OrgID, Value: e}
+	}
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return err
+	}
+	return s.post(ctx, "application/vnd.kafka.json.v2+json", body)
+}
+
+func (s *kafkaSink) Close() error { return nil }
+
+// nameOf is u without its query, which may carry a collector's token.
+func nameOf(u *url.URL) string {
+	named := *u
+	named.RawQuery, named.Fragment = "", ""
+	return named.String()
+}
@@ -0,0 +1,175 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/metrics"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	batchSize = 100
+	// settle is how old an entry has to be to be streamed. Entries are
+	// recorded in transactions of their own that commit within moments of
+	// their created_at; waiting that long keeps one from committing behind
+	// a cursor that has already moved past it.
+	settle = 5 * time.Second
+	// maxBackoff caps the wait after failures, which doubles from the poll
+	// interval with each one in a row.
+	maxBackoff = 5 * time.Minute
+	// writeTimeout bounds one batch's delivery, which holds the cursor.
+	writeTimeout = time.Minute
+)
+
+// Streamer delivers the trail to its sinks, each in a loop of its own. A
+// sink's cursor row is locked with FOR UPDATE SKIP LOCKED while a batch is
+// out, so the streamer can run on every instance and each batch still goes
+// out from one.
+type Streamer struct {
+	pool     *pgxpool.Pool
+	interval time.Duration
+	sinks    []Sink
+}
+
+func NewStreamer(pool *pgxpool.Pool, interval time.Duration, sinks []Sink) *Streamer {
+	return &Streamer{pool: pool, interval: interval, sinks: sinks}
+}
+
+// Start streams until ctx is done. A sink added to the configuration starts
+// from the entries recorded after it first runs.
+func (s *Streamer) Start(ctx context.Context) {
+	for _, sink := range s.sinks {
+		go s.stream(ctx, sink)
+	}
+}
+
+func (s *Streamer) stream(ctx context.Context, sink Sink) {
+	defer sink.Close()
+	wait := s.interval
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+
+		err := s.drain(ctx, sink)
+		if err == nil {
+			wait = s.interval
+			continue
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		metrics.AuditFailures.WithLabelValues(sink.Name()).Inc()
+		slog.Warn("Failed to stream audit events", "sink", sink.Name(), "error", err)
+		wait = backoff(wait, err)
+	}
+}
+
+// drain delivers batches until the sink has caught up.
+func (s *Streamer) drain(ctx context.Context, sink Sink) error {
+	for {
+		n, err := s.Run(ctx, sink)
+		if err != nil || n < batchSize {
+			return err
+		}
+	}
+}
+
+// backoff is how long to wait after err, with last the wait before it.
+func backoff(last time.Duration, err error) time.Duration {
+	next := min(2*last, maxBackoff)
+	var busy *BusyError
+	if errors.As(err, &busy) {
+		next = max(next, busy.After)
+	}
+	return next
+}
+
+// Run delivers one batch to sink and returns how many events were in it.
+func (s *Streamer) Run(ctx context.Context, sink Sink) (int, error) {
+	var delivered int
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `INSERT INTO audit_cursors (sink) VALUES ($1) ON CONFLICT DO NOTHING`, sink.Name()); err != nil {
+			return err
+		}
+		var afterTime time.Time
+		var afterID string
+		err := tx.QueryRow(ctx,
+			`SELECT created_at, activity_id FROM audit_cursors WHERE sink = $1 FOR UPDATE SKIP LOCKED`,
+			sink.Name(),
+		).Scan(&afterTime, &afterID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// Another instance has a batch out.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		events, err := pending(ctx, tx, afterTime, afterID)
+		if err != nil || len(events) == 0 {
+			metrics.AuditLag.WithLabelValues(sink.Name()).Set(0)
+			return err
+		}
+
+		writeCtx, cancel := context.WithTimeout(ctx, writeTimeout)
+		start := time.Now()
+		err = sink.Write(writeCtx, events)
+		cancel()
+		metrics.AuditDeliveryDuration.WithLabelValues(sink.Name()).Observe(time.Since(start).Seconds())
+		if err != nil {
+			metrics.AuditLag.WithLabelValues(sink.Name()).Set(time.Since(events[0].Time).Seconds())
+			return err
+		}
+
+		last := events[len(events)-1]
+		if _, err := tx.Exec(ctx,
+			`UPDATE audit_cursors SET created_at = $2, activity_id = $3, delivered_at = NOW() WHERE sink = $1`,
+			sink.Name(), last.Time, last.ID,
+		); err != nil {
+			return err
+		}
+		delivered = len(events)
+		metrics.AuditEvents.WithLabelValues(sink.Name()).Add(float64(delivered))
+		// A full batch leaves more behind it, at least as old as its last.
+		lag := 0.0
+		if delivered == batchSize {
+			lag = time.Since(last.Time).Seconds()
+		}
+		metrics.AuditLag.WithLabelValues(sink.Name()).Set(lag)
+		return nil
+	})
+	return delivered, err
+}
+
+// pending is the next batch of org entries after the cursor, oldest first.
+func pending(ctx context.Context, tx pgx.Tx, afterTime time.Time, afterID string) ([]Event, error) {
+	rows, err := tx.Query(ctx,
+		`SELECT id, org_id, actor_id, task_id, project_id, action, changes, created_at FROM activity
+		 WHERE org_id IS NOT NULL AND (created_at, id) > ($1::timestamptz, $2::uuid) AND created_at < NOW() - make_interval(secs => $3)
+		 ORDER BY created_at, id
+		 LIMIT $4`,
+		afterTime, afterID, settle.Seconds(), batchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var a models.Activity
+		if err := rows.Scan(&a.ID, &a.OrgID, &a.ActorID, &a.TaskID, &a.ProjectID, &a.Action, &a.Changes, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, newEvent(a))
+	}
+	return events, rows.Err()
+}
@@ -0,0 +1,55 @@
+// Package auth is who a request is from, as the auth middleware found
+// them: a Clerk session, a personal access token or a service token all
+// end up as the same User, so handlers don't read claims themselves.
+package auth
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrUnauthenticated is CurrentUser on a request no user was set on.
+var ErrUnauthenticated = errors.New("request is not authenticated")
+
+// User is the caller. OrgID is empty outside an org, and Role with it;
+// Email is empty for services and until Clerk's user event has arrived.
+type User struct {
+	ID    string
+	OrgID string
+	Role  string
+	Email string
+	// Guest is set for external collaborators in the org.
+	Guest bool
+}
+
+// Scope is what the user's requests can see.
+func (u User) Scope() models.Scope {
+	return models.Scope{UserID: u.ID, OrgID: u.OrgID, Guest: u.Guest}
+}
+
+type userKey struct{}
+
+// WithUser is how the middleware sets the caller, and how tests can sign a
+// request in without it.
+func WithUser(ctx context.Context, u User) context.Context {
+	return context.WithValue(ctx, userKey{}, u)
+}
+
+// FromContext is the user WithUser set on ctx, for middleware that runs on
+// a context rather than a gin.Context.
+func FromContext(ctx context.Context) (User, bool) {
+	u, ok := ctx.Value(userKey{}).(User)
+	return u, ok
+}
+
+// CurrentUser is the caller of c's request, or ErrUnauthenticated.
+func CurrentUser(c *gin.Context) (User, error) {
+	u, ok := FromContext(c.Request.Context())
+	if !ok {
+		return User{}, ErrUnauthenticated
+	}
+	return u, nil
+}
@@ -0,0 +1,207 @@
+// Package background wires up the job worker and the schedulers that feed
+// it. Both yata serve (unless disabled) and yata worker start it.
+package background
+
+import (
+	"context"
+	"yata/apps/server/internal/account"
+	"yata/apps/server/internal/archive"
+	"yata/apps/server/internal/attachments"
+	"yata/apps/server/internal/audit"
+	"yata/apps/server/internal/changelog"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/digest"
+	"yata/apps/server/internal/gcal"
+	"yata/apps/server/internal/importers"
+	"yata/apps/server/internal/inbox"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/mailer"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/outbox"
+	"yata/apps/server/internal/push"
+	"yata/apps/server/internal/reminders"
+	"yata/apps/server/internal/slack"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/telegram"
+	"yata/apps/server/internal/trash"
+	"yata/apps/server/internal/webhooks"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Start launches the worker and schedulers; they stop when ctx is done.
+// The returned wait blocks until the jobs running at that point finish.
+func Start(ctx context.Context, cfg *config.Config, pool *pgxpool.Pool, db store.Store) (wait func(), err error) {
+	m, templates, err := newMailer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	delivery := deliveryNotifier(cfg, db, m, templates)
+
+	queue := jobs.NewPostgresQueue(pool)
+
+	worker := jobs.NewWorker(pool, cfg.JOB_WORKER_CONCURRENCY, cfg.JOB_POLL_INTERVAL)
+	worker.Handle(notify.JobKind, notify.JobHandler(delivery))
+	worker.Handle(importers.JobKind, importers.JobHandler(importers.Stores{
+		Imports:     db.Imports(),
+		Tasks:       db.Tasks(),
+		Projects:    db.Projects(),
+		Labels:      db.Labels(),
+		Workflows:   db.Workflows(),
+		OrgSettings: db.OrgSettings(),
+	}))
+	deliverer := webhooks.NewDeliverer(db.Webhooks())
+	deliverer.OrgConcurrency = cfg.WEBHOOK_ORG_CONCURRENCY
+	deliverer.Breaker = models.WebhookBreakerPolicy{
+		Threshold:   cfg.WEBHOOK_BREAKER_THRESHOLD,
+		Cooldown:    cfg.WEBHOOK_BREAKER_COOLDOWN,
+		MaxCooldown: cfg.WEBHOOK_BREAKER_MAX_COOLDOWN,
+	}
+	worker.Handle(webhooks.JobKind, deliverer.Handle)
+	files, err := NewFiles(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if files != nil {
+		worker.Handle(account.JobKind, account.JobHandler(db.Accounts(), files))
+	}
+	worker.Start(ctx)
+
+	if cfg.REMINDER_POLL_INTERVAL > 0 {
+		notifier := notify.QueuedNotifier{Queue: queue}
+		reminders.NewScheduler(db.Reminders(), notifier, cfg.REMINDER_POLL_INTERVAL).Start(ctx)
+	}
+	if cfg.DIGEST_POLL_INTERVAL > 0 {
+		(&digest.Sender{
+			Digests:   db.Digests(),
+			Mailer:    m,
+			Templates: templates,
+			Directory: mailer.ClerkDirectory{},
+			AppURL:    cfg.APP_URL,
+			Interval:  cfg.DIGEST_POLL_INTERVAL,
+		}).Start(ctx)
+	}
+	if cfg.OUTBOX_POLL_INTERVAL > 0 {
+		relay := outbox.NewRelay(pool, cfg.OUTBOX_POLL_INTERVAL)
+		relay.Add(webhooks.Dispatcher{Endpoints: db.Webhooks()})
+		relay.Start(ctx)
+	}
+	if len(cfg.AUDIT_SINKS) > 0 {
+		sinks := make([]audit.Sink, len(cfg.AUDIT_SINKS))
+		for i, raw := range cfg.AUDIT_SINKS {
+			if sinks[i], err = audit.NewSink(raw, cfg.AUDIT_SINK_TOKEN); err != nil {
+				return nil, err
+			}
+		}
+		audit.NewStreamer(pool, cfg.AUDIT_POLL_INTERVAL, sinks).Start(ctx)
+	}
+	if cfg.TRASH_PURGE_INTERVAL > 0 {
+		trash.NewPurger(db.Trash(), cfg.TRASH_RETENTION, cfg.TRASH_PURGE_INTERVAL).Start(ctx)
+	}
+	if cfg.CHANGE_LOG_PRUNE_INTERVAL > 0 {
+		changelog.NewPruner(db.Changes(), cfg.CHANGE_LOG_RETENTION, cfg.CHANGE_LOG_PRUNE_INTERVAL).Start(ctx)
+	}
+	if files != nil && cfg.ATTACHMENT_SWEEP_INTERVAL > 0 {
+		attachments.NewSweeper(db.Attachments(), files, cfg.ATTACHMENT_PENDING_TTL, cfg.ATTACHMENT_SWEEP_INTERVAL).Start(ctx)
+	}
+	if cfg.TASK_ARCHIVE_AFTER > 0 && cfg.TASK_ARCHIVE_INTERVAL > 0 {
+		archive.NewArchiver(db.Tasks(), cfg.TASK_ARCHIVE_AFTER, cfg.TASK_ARCHIVE_INTERVAL).Start(ctx)
+	}
+	if cfg.GOOGLE_CLIENT_ID != "" {
+		client := gcal.NewClient(gcal.Config{
+			ClientID:     cfg.GOOGLE_CLIENT_ID,
+			ClientSecret: cfg.GOOGLE_CLIENT_SECRET,
+			RedirectURL:  cfg.GOOGLE_REDIRECT_URL,
+		})
+		gcal.NewSyncer(client, gcal.Stores{
+			Connections: db.GoogleCalendar(),
+			Users:       db.Users(),
+			Tasks:       db.Tasks(),
+			Workflows:   db.Workflows(),
+		}, cfg.GOOGLE_CALENDAR_SYNC_INTERVAL, cfg.APP_URL).Start(ctx)
+	}
+	return worker.Wait, nil
+}
+
+// NewFiles returns the configured object storage, or nil when there's no
+// bucket to keep files in.
+func NewFiles(cfg *config.Config) (storage.Storage, error) {
+	if cfg.S3_BUCKET == "" {
+		return nil, nil
+	}
+	return storage.NewS3(storage.S3Config{
+		Endpoint:        cfg.S3_ENDPOINT,
+		Region:          cfg.S3_REGION,
+		Bucket:          cfg.S3_BUCKET,
+		AccessKeyID:     cfg.S3_ACCESS_KEY_ID,
+		SecretAccessKey: cfg.S3_SECRET_ACCESS_KEY,
+		PathStyle:       cfg.S3_USE_PATH_STYLE,
+	})
+}
+
+func newMailer(cfg *config.Config) (mailer.Mailer, *mailer.Templates, error) {
+	m, err := mailer.New(mailer.Config{
+		Provider:     cfg.MAIL_PROVIDER,
+		From:         cfg.MAIL_FROM,
+		SMTPHost:     cfg.SMTP_HOST,
+		SMTPPort:     cfg.SMTP_PORT,
+		SMTPUsername: cfg.SMTP_USERNAME,
+		SMTPPassword: cfg.SMTP_PASSWORD,
+		SESRegion:    cfg.SES_REGION,
+		ResendAPIKey: cfg.RESEND_API_KEY,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	templates, err := mailer.LoadTemplates()
+	if err != nil {
+		return nil, nil, err
+	}
+	return m, templates, nil
+}
+
+// deliveryNotifier is what queued notifications are finally sent through.
+func deliveryNotifier(cfg *config.Config, db store.Store, m mailer.Mailer, templates *mailer.Templates) notify.Notifier {
+	notifiers := notify.Multi{
+		notify.LogNotifier{},
+		&inbox.Notifier{Notifications: db.Notifications()},
+		&mailer.Notifier{
+			Mailer:      m,
+			Templates:   templates,
+			Directory:   mailer.ClerkDirectory{},
+			Preferences: db.EmailPreferences(),
+			Settings:    db.UserSettings(),
+			AppURL:      cfg.APP_URL,
+		},
+	}
+
+	notifiers = append(notifiers, &slack.Notifier{
+		Client:       slack.NewClient(),
+		Integrations: db.Slack(),
+		Users:        db.Users(),
+		AppURL:       cfg.APP_URL,
+	})
+
+	if cfg.TELEGRAM_BOT_TOKEN != "" {
+		notifiers = append(notifiers, &telegram.Notifier{
+			Client:   telegram.NewClient(cfg.TELEGRAM_BOT_TOKEN),
+			Links:    db.Telegram(),
+			Settings: db.UserSettings(),
+			AppURL:   cfg.APP_URL,
+		})
+	}
+	if cfg.VAPID_PUBLIC_KEY != "" && cfg.VAPID_PRIVATE_KEY != "" {
+		notifiers = append(notifiers, &push.Notifier{
+			Config: push.Config{
+				PublicKey:  cfg.VAPID_PUBLIC_KEY,
+				PrivateKey: cfg.VAPID_PRIVATE_KEY,
+				Subject:    cfg.VAPID_SUBJECT,
+			},
+			Subscriptions: db.PushSubscriptions(),
+			Settings:      db.UserSettings(),
+		})
+	}
+	return notifiers
+}
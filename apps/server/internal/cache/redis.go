@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// keyPrefix keeps cache keys apart from the rate limiter's and anything
+// else sharing the Redis database.
+const keyPrefix = "cache:"
+
+// generationTTL outlives any cached value, so a namespace's generation
+// can't expire and come back as an old number while its values are live.
+const generationTTL = 7 * 24 * time.Hour
+
+type Redis struct {
+	Client redis.UniversalClient
+}
+
+func (r Redis) Get(ctx context.Context, key string) ([]byte, error) {
+	raw, err := r.Client.Get(ctx, keyPrefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrMiss
+	}
+	return raw, err
+}
+
+func (r Redis) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return r.Client.Set(ctx, keyPrefix+key, value, ttl).Err()
+}
+
+func (r Redis) Delete(ctx context.Context, keys ...string) error {
+	prefixed := make([]string, len(keys))
+	for i, k := range keys {
+		prefixed[i] = keyPrefix + k
+	}
+	return r.Client.Del(ctx, prefixed...).Err()
+}
+
+func (r Redis) Incr(ctx context.Context, key string) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := r.Client.TxPipelined(ctx, func(p redis.Pipeliner) error {
+		incr = p.Incr(ctx, keyPrefix+key)
+		p.Expire(ctx, keyPrefix+key, generationTTL)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
@@ -0,0 +1,51 @@
+// Package changelog drops change log entries older than delta sync cursors
+// are accepted for.
+package changelog
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/store"
+)
+
+// Pruner deletes entries logged more than Retention ago, checking every
+// Interval.
+type Pruner struct {
+	Changes   store.ChangeStore
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func NewPruner(changes store.ChangeStore, retention, interval time.Duration) *Pruner {
+	return &Pruner{Changes: changes, Retention: retention, Interval: interval}
+}
+
+// Start runs the pruner until ctx is done.
+func (p *Pruner) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run prunes once.
+func (p *Pruner) Run(ctx context.Context) {
+	n, err := p.Changes.Prune(ctx, time.Now().Add(-p.Retention))
+	if err != nil {
+		slog.Error("Failed to prune the change log", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("Pruned the change log", "entries", n)
+	}
+}
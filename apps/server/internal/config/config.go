@@ -1,42 +1,490 @@
 package config
 
 import (
-	"log"
-	"os"
-	"strings"
-
-	"github.com/joho/godotenv"
+	"maps"
+	"slices"
+	"time"
 )
 
 type Config struct {
-	DATABASE_URL     string
-	PORT             string
-	CLERK_SECRET_KEY string
-	ALLOWED_ORIGINS  []string
+	DATABASE_URL string
+	PORT         string
+	// TLS_CERT_FILE and TLS_KEY_FILE serve HTTPS on PORT, for deployments
+	// without a proxy in front; TLS_AUTOCERT_DOMAINS gets certificates for
+	// those domains from Let's Encrypt instead, cached in
+	// TLS_AUTOCERT_CACHE_DIR. TLS_REDIRECT_HTTP also listens on HTTP_PORT
+	// and redirects to HTTPS.
+	TLS_CERT_FILE          string
+	TLS_KEY_FILE           string
+	TLS_AUTOCERT_DOMAINS   []string
+	TLS_AUTOCERT_EMAIL     string
+	TLS_AUTOCERT_CACHE_DIR string
+	TLS_REDIRECT_HTTP      bool
+	HTTP_PORT              string
+	CLERK_SECRET_KEY       string
+
+	// Session tokens are verified against Clerk's signing keys, reloaded
+	// every CLERK_JWKS_REFRESH_INTERVAL. They're accepted CLERK_CLOCK_SKEW
+	// past expiry or before they're valid, and when CLERK_AUTHORIZED_PARTIES
+	// is set only if issued to one of those origins.
+	CLERK_JWKS_REFRESH_INTERVAL time.Duration
+	CLERK_CLOCK_SKEW            time.Duration
+	CLERK_AUTHORIZED_PARTIES    []string
+
+	// ALLOWED_ORIGINS may call the API from a browser: exact origins,
+	// https://*.yata.app for any subdomain, or "*" for anyone.
+	// ALLOWED_ORIGIN_PATTERNS are regular expressions an origin must match
+	// in full, for hosts like Vercel preview URLs. Either can be given per
+	// ENV, as ALLOWED_ORIGINS_STAGING, which then replaces the plain one.
+	ALLOWED_ORIGINS         []string
+	ALLOWED_ORIGIN_PATTERNS []string
+
+	// CLERK_WEBHOOK_SECRET is the Svix signing secret of the Clerk webhook
+	// endpoint; without it users and orgs aren't mirrored locally.
+	CLERK_WEBHOOK_SECRET string
+
+	ENV                 string
+	LOG_LEVEL           string
+	INSTANCE_ID         string
+	DB_APPLICATION_NAME string
+
+	DB_STATEMENT_TIMEOUT time.Duration
+	AUTO_MIGRATE         bool
+
+	ADMIN_ALLOWED_IPS  []string
+	RECENT_ERRORS_SIZE int
+
+	// SENTRY_DSN, if set, gets panics and 5xx responses reported to Sentry.
+	SENTRY_DSN string
+
+	// ENABLE_DEBUG serves pprof and goroutine/heap dumps under /debug, to
+	// ADMIN_ALLOWED_IPS only.
+	ENABLE_DEBUG bool
+
+	// METRICS_ALLOWED_IPS can scrape /metrics without credentials; anyone
+	// else needs METRICS_USERNAME and METRICS_PASSWORD.
+	METRICS_ALLOWED_IPS []string
+	METRICS_USERNAME    string
+	METRICS_PASSWORD    string
+
+	REQUEST_TIMEOUT time.Duration
+	ROUTE_TIMEOUTS  map[string]time.Duration
+
+	// MAINTENANCE_MODE pins the maintenance mode to off, read_only or full;
+	// left empty, admins switch it at /admin/maintenance. Requests turned
+	// away are told to retry after MAINTENANCE_RETRY_AFTER.
+	MAINTENANCE_MODE        string
+	MAINTENANCE_RETRY_AFTER time.Duration
+
+	// SUSPENDED_ORGS_BLOCK_READS turns suspended orgs away from reads as
+	// well as writes; exports stay open so they can take their data out.
+	SUSPENDED_ORGS_BLOCK_READS bool
+
+	// RETRY_AFTER_BASE is the least a client turned away with a 429, 503 or
+	// 504 is told to wait; up to RETRY_AFTER_JITTER more is added at random,
+	// so they don't all come back at once.
+	RETRY_AFTER_BASE   time.Duration
+	RETRY_AFTER_JITTER time.Duration
+
+	// API_ALIAS_SUNSET is announced in the Sunset header on the
+	// unversioned /api paths, as the date they stop working.
+	API_ALIAS_SUNSET time.Time
+
+	// SHUTDOWN_TIMEOUT is how long SIGTERM waits for in-flight requests and
+	// jobs before closing up anyway; keep it under the orchestrator's grace
+	// period.
+	SHUTDOWN_TIMEOUT time.Duration
+
+	REQUEST_ID_FORMAT string
+	CURSOR_SECRET     string
+
+	DB_MEMORY_PRESSURE_THRESHOLD uint64
+	DB_MEMORY_PRESSURE_MAX_CONNS int
+	DB_MEMORY_PRESSURE_INTERVAL  time.Duration
+
+	DB_SLOW_LOG_ENABLED   bool
+	DB_SLOW_LOG_THRESHOLD time.Duration
+
+	// DATABASE_REPLICA_URLS are streaming replicas of DATABASE_URL for reads
+	// that can be a little stale. One more than DB_REPLICA_MAX_LAG behind,
+	// or unreachable, is skipped until a check every
+	// DB_REPLICA_CHECK_INTERVAL finds it caught up.
+	DATABASE_REPLICA_URLS     []string
+	DB_REPLICA_MAX_LAG        time.Duration
+	DB_REPLICA_CHECK_INTERVAL time.Duration
+
+	REMINDER_POLL_INTERVAL time.Duration
+	// DIGEST_POLL_INTERVAL is how often due digest emails are looked for;
+	// zero turns digests off.
+	DIGEST_POLL_INTERVAL time.Duration
+	// OUTBOX_POLL_INTERVAL is how often recorded events are relayed to
+	// webhooks; zero leaves them in the outbox.
+	OUTBOX_POLL_INTERVAL time.Duration
+
+	// AUDIT_SINKS are URLs, of the kinds audit.NewSink takes, the org audit
+	// trail is streamed to; AUDIT_SINK_TOKEN is the bearer token the HTTPS
+	// and Kafka sinks send. AUDIT_POLL_INTERVAL is how often each sink is
+	// sent what's new.
+	AUDIT_SINKS         []string
+	AUDIT_SINK_TOKEN    string
+	AUDIT_POLL_INTERVAL time.Duration
+
+	// TRASH_RETENTION is how long deleted tasks and projects can be restored.
+	TRASH_RETENTION      time.Duration
+	TRASH_PURGE_INTERVAL time.Duration
+
+	// CHANGE_LOG_RETENTION is how far back GET /sync can go; older cursors
+	// get 410 and the client loads everything again.
+	CHANGE_LOG_RETENTION      time.Duration
+	CHANGE_LOG_PRUNE_INTERVAL time.Duration
+
+	// TASK_ARCHIVE_AFTER is how long a done task sits untouched before it's
+	// archived; zero turns automatic archival off.
+	TASK_ARCHIVE_AFTER    time.Duration
+	TASK_ARCHIVE_INTERVAL time.Duration
+
+	// JOB_WORKER_ENABLED runs the job worker inside the API process; turn it
+	// off when yata worker runs separately.
+	JOB_WORKER_ENABLED     bool
+	JOB_WORKER_CONCURRENCY int
+	JOB_POLL_INTERVAL      time.Duration
+
+	// WEBHOOK_ORG_CONCURRENCY caps how many of one org's webhook deliveries
+	// a worker makes at once, so one org's slow endpoints can't take every
+	// job slot.
+	WEBHOOK_ORG_CONCURRENCY int
+	// WEBHOOK_BREAKER_THRESHOLD failed deliveries in a row to one of an
+	// org's URLs open its breaker for WEBHOOK_BREAKER_COOLDOWN, doubling
+	// each time it opens again up to WEBHOOK_BREAKER_MAX_COOLDOWN. Zero
+	// turns the breakers off.
+	WEBHOOK_BREAKER_THRESHOLD    int
+	WEBHOOK_BREAKER_COOLDOWN     time.Duration
+	WEBHOOK_BREAKER_MAX_COOLDOWN time.Duration
+
+	IDEMPOTENCY_TTL time.Duration
+
+	APP_URL        string
+	MAIL_PROVIDER  string
+	MAIL_FROM      string
+	SMTP_HOST      string
+	SMTP_PORT      int
+	SMTP_USERNAME  string
+	SMTP_PASSWORD  string
+	SES_REGION     string
+	RESEND_API_KEY string
+
+	VAPID_PUBLIC_KEY  string
+	VAPID_PRIVATE_KEY string
+	VAPID_SUBJECT     string
+
+	// S3_ENDPOINT and friends point attachments at S3 or an S3-compatible
+	// service; with no S3_BUCKET the attachment routes aren't registered.
+	S3_ENDPOINT          string
+	S3_REGION            string
+	S3_BUCKET            string
+	S3_ACCESS_KEY_ID     string
+	S3_SECRET_ACCESS_KEY string
+	S3_USE_PATH_STYLE    bool
+
+	ATTACHMENT_MAX_SIZE      int64
+	ATTACHMENT_ALLOWED_TYPES []string
+	// ATTACHMENT_PENDING_TTL is how long an upload has to be confirmed
+	// before its attachment is swept, every ATTACHMENT_SWEEP_INTERVAL.
+	ATTACHMENT_PENDING_TTL    time.Duration
+	ATTACHMENT_SWEEP_INTERVAL time.Duration
+
+	// QUOTA_MAX_* are the limits every org is held to; zero is no limit.
+	// Attachment bytes count pending uploads too.
+	QUOTA_MAX_TASKS             int64
+	QUOTA_MAX_ATTACHMENT_BYTES  int64
+	QUOTA_MAX_WEBHOOK_ENDPOINTS int64
+
+	// SHARE_LINK_SECRET signs public share link tokens; without it share
+	// links are turned off. SHARE_LINK_BASE_URL is the API's public URL the
+	// links, and calendar feed URLs, point at.
+	SHARE_LINK_SECRET   string
+	SHARE_LINK_BASE_URL string
+
+	// SERVICE_TOKEN_SECRET signs the tokens internal services call
+	// /api/v1/service with; without it those routes are turned off.
+	SERVICE_TOKEN_SECRET string
+	// GRPC_PORT serves the gRPC API to the same services, with the same
+	// tokens; empty leaves it off.
+	GRPC_PORT string
+
+	// ENCRYPTION_MASTER_KEY, 32 bytes in base64, wraps the per-org keys
+	// attachment filenames and content types are encrypted with at rest.
+	// Without it they're stored in the clear; ones already encrypted can't
+	// be read.
+	ENCRYPTION_MASTER_KEY string
+
+	// GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET are the OAuth client for
+	// Google Calendar sync, which is off without them. GOOGLE_REDIRECT_URL
+	// is the API's /integrations/google-calendar/callback as registered with
+	// Google. Each connection syncs every GOOGLE_CALENDAR_SYNC_INTERVAL.
+	GOOGLE_CLIENT_ID              string
+	GOOGLE_CLIENT_SECRET          string
+	GOOGLE_REDIRECT_URL           string
+	GOOGLE_CALENDAR_SYNC_INTERVAL time.Duration
+
+	// SLACK_SIGNING_SECRET is the Slack app's, checked on /yata slash
+	// commands; without it they're turned off. Notifications only need the
+	// tokens each org configures.
+	SLACK_SIGNING_SECRET string
+
+	// INBOUND_EMAIL_DOMAIN is where inbound addresses live; its mail should
+	// reach a Mailgun route forwarding to the API's
+	// /integrations/inbound-email/mailgun. MAILGUN_WEBHOOK_SIGNING_KEY checks
+	// those requests. Without the domain, email-to-task is off.
+	INBOUND_EMAIL_DOMAIN        string
+	MAILGUN_WEBHOOK_SIGNING_KEY string
+
+	// TELEGRAM_BOT_TOKEN turns on the Telegram bot, TELEGRAM_BOT_USERNAME
+	// being its @name without the @. Register the API's
+	// /integrations/telegram/webhook with the bot's setWebhook, passing
+	// TELEGRAM_WEBHOOK_SECRET as secret_token.
+	TELEGRAM_BOT_TOKEN      string
+	TELEGRAM_BOT_USERNAME   string
+	TELEGRAM_WEBHOOK_SECRET string
+
+	// REDIS_URL is shared state for running more than one instance; without
+	// it everything that would live there stays in process memory.
+	REDIS_URL string
+	// CACHE_TTL bounds how stale a cached read can get if an invalidation
+	// is lost.
+	CACHE_TTL time.Duration
+
+	// RATE_LIMITS maps route groups to "limit/window" rules.
+	RATE_LIMITS map[string]string
+	// COMPRESSION_MIN_SIZES maps the same route groups to the size in bytes
+	// a response needs to be gzipped; zero turns compression off.
+	COMPRESSION_MIN_SIZES map[string]int
+
+	// SECRET_REFRESH_INTERVAL is how often secrets given as secret://
+	// references are looked up again to pick up rotations; zero reads them
+	// once at startup.
+	SECRET_REFRESH_INTERVAL time.Duration
+
+	// secretRefs are the settings that came from secret:// references.
+	secretRefs map[string]secretRef
 }
 
-func LoadConfig() (*Config, error) {
-	err := godotenv.Load()
+type secretRef struct {
+	ref   string
+	value string
+}
 
-	if err != nil {
-		log.Println("Unable to load .env file", err)
-		return nil, err
-	}
+// defaultRateLimits apply to groups RATE_LIMITS leaves out: "api" is the
+// signed-in API, "public" the unauthenticated share links and "service"
+// the service routes.
+var defaultRateLimits = map[string]string{
+	"api":     "300/1m",
+	"public":  "60/1m",
+	"service": "1200/1m",
+}
+
+// RateLimits are the RATE_LIMITS rules over the defaults, so a config built
+// by hand instead of loaded still limits every route group.
+func (c *Config) RateLimits() map[string]string {
+	rules := maps.Clone(defaultRateLimits)
+	maps.Copy(rules, c.RATE_LIMITS)
+	return rules
+}
 
-	origins := []string{}
-	for _, o := range strings.Split(os.Getenv("ALLOWED_ORIGINS"), ",") {
-		o = strings.TrimSpace(o)
-		if o != "" {
-			origins = append(origins, o)
-		}
+// defaultCompressionMinSizes put a response under about a kilobyte, a
+// handful of tasks, below what's worth compressing.
+var defaultCompressionMinSizes = map[string]int{
+	"api":     1024,
+	"public":  1024,
+	"service": 1024,
+}
+
+// defaultOrigins are allowed when an environment sets no origins: the
+// client's dev server, in development only.
+var defaultOrigins = map[string][]string{
+	"development": {"http://localhost:3000", "http://127.0.0.1:3000"},
+}
+
+// defaultMetricsIPs are loopback and the private ranges, where a scraper
+// inside the cluster or VPC would be.
+var defaultMetricsIPs = []string{
+	"127.0.0.1", "::1", "10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16", "fc00::/7",
+}
+
+// defaultAttachmentTypes are accepted when ATTACHMENT_ALLOWED_TYPES is unset.
+var defaultAttachmentTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp",
+	"application/pdf", "text/plain", "text/csv", "application/zip",
+}
+
+// LoadConfig reads every setting from the sources described in sources.go.
+func LoadConfig() (*Config, error) {
+	if err := loadEnvFile(); err != nil {
+		return nil, err
 	}
 
+	e := &env{overrides: flagOverrides, seen: map[string]bool{}, refs: map[string]secretRef{}}
 	config := &Config{
-		DATABASE_URL:     os.Getenv("DATABASE_URL"),
-		PORT:             os.Getenv("PORT"),
-		CLERK_SECRET_KEY: os.Getenv("CLERK_SECRET_KEY"),
-		ALLOWED_ORIGINS:  origins,
+		DATABASE_URL: e.secret("DATABASE_URL"),
+		PORT:         e.string("PORT", "8080"),
+
+		TLS_CERT_FILE:          e.string("TLS_CERT_FILE", ""),
+		TLS_KEY_FILE:           e.string("TLS_KEY_FILE", ""),
+		TLS_AUTOCERT_DOMAINS:   e.list("TLS_AUTOCERT_DOMAINS"),
+		TLS_AUTOCERT_EMAIL:     e.string("TLS_AUTOCERT_EMAIL", ""),
+		TLS_AUTOCERT_CACHE_DIR: e.string("TLS_AUTOCERT_CACHE_DIR", "certs"),
+		TLS_REDIRECT_HTTP:      e.bool("TLS_REDIRECT_HTTP", false),
+		HTTP_PORT:              e.string("HTTP_PORT", "80"),
+
+		CLERK_SECRET_KEY:            e.secret("CLERK_SECRET_KEY"),
+		CLERK_JWKS_REFRESH_INTERVAL: e.duration("CLERK_JWKS_REFRESH_INTERVAL", time.Hour),
+		CLERK_CLOCK_SKEW:            e.duration("CLERK_CLOCK_SKEW", 5*time.Second),
+		CLERK_AUTHORIZED_PARTIES:    e.list("CLERK_AUTHORIZED_PARTIES"),
+
+		CLERK_WEBHOOK_SECRET: e.secret("CLERK_WEBHOOK_SECRET"),
+
+		ENV:                 e.string("ENV", "development"),
+		LOG_LEVEL:           e.string("LOG_LEVEL", "info"),
+		INSTANCE_ID:         e.string("INSTANCE_ID", hostname()),
+		DB_APPLICATION_NAME: e.string("DB_APPLICATION_NAME", ""),
+
+		DB_STATEMENT_TIMEOUT: e.duration("DB_STATEMENT_TIMEOUT", 30*time.Second),
+		AUTO_MIGRATE:         e.bool("AUTO_MIGRATE", false),
+
+		ADMIN_ALLOWED_IPS:  e.list("ADMIN_ALLOWED_IPS"),
+		RECENT_ERRORS_SIZE: e.int("RECENT_ERRORS_SIZE", 50),
+
+		SENTRY_DSN: e.secret("SENTRY_DSN"),
+
+		ENABLE_DEBUG: e.bool("ENABLE_DEBUG", false),
+
+		METRICS_ALLOWED_IPS: e.list("METRICS_ALLOWED_IPS", defaultMetricsIPs...),
+		METRICS_USERNAME:    e.string("METRICS_USERNAME", ""),
+		METRICS_PASSWORD:    e.secret("METRICS_PASSWORD"),
+
+		REQUEST_TIMEOUT: e.duration("REQUEST_TIMEOUT", 15*time.Second),
+		ROUTE_TIMEOUTS:  e.durationMap("ROUTE_TIMEOUTS"),
+
+		MAINTENANCE_MODE:           e.string("MAINTENANCE_MODE", ""),
+		MAINTENANCE_RETRY_AFTER:    e.duration("MAINTENANCE_RETRY_AFTER", 5*time.Minute),
+		SUSPENDED_ORGS_BLOCK_READS: e.bool("SUSPENDED_ORGS_BLOCK_READS", false),
+		RETRY_AFTER_BASE:           e.duration("RETRY_AFTER_BASE", time.Second),
+		RETRY_AFTER_JITTER:         e.duration("RETRY_AFTER_JITTER", 5*time.Second),
+
+		API_ALIAS_SUNSET: e.date("API_ALIAS_SUNSET"),
+
+		SHUTDOWN_TIMEOUT: e.duration("SHUTDOWN_TIMEOUT", 25*time.Second),
+
+		REQUEST_ID_FORMAT: e.string("REQUEST_ID_FORMAT", "uuid"),
+		CURSOR_SECRET:     e.string("CURSOR_SECRET", ""),
+
+		DB_MEMORY_PRESSURE_THRESHOLD: uint64(e.int("DB_MEMORY_PRESSURE_THRESHOLD", 0)),
+		DB_MEMORY_PRESSURE_MAX_CONNS: e.int("DB_MEMORY_PRESSURE_MAX_CONNS", 2),
+		DB_MEMORY_PRESSURE_INTERVAL:  e.duration("DB_MEMORY_PRESSURE_INTERVAL", 15*time.Second),
+
+		DB_SLOW_LOG_ENABLED:   e.bool("DB_SLOW_LOG_ENABLED", false),
+		DB_SLOW_LOG_THRESHOLD: e.duration("DB_SLOW_LOG_THRESHOLD", 500*time.Millisecond),
+
+		DATABASE_REPLICA_URLS:     e.list("DATABASE_REPLICA_URLS"),
+		DB_REPLICA_MAX_LAG:        e.duration("DB_REPLICA_MAX_LAG", 10*time.Second),
+		DB_REPLICA_CHECK_INTERVAL: e.duration("DB_REPLICA_CHECK_INTERVAL", 5*time.Second),
+
+		REMINDER_POLL_INTERVAL: e.duration("REMINDER_POLL_INTERVAL", 30*time.Second),
+		DIGEST_POLL_INTERVAL:   e.duration("DIGEST_POLL_INTERVAL", 5*time.Minute),
+		OUTBOX_POLL_INTERVAL:   e.duration("OUTBOX_POLL_INTERVAL", time.Second),
+
+		AUDIT_SINKS:         e.list("AUDIT_SINKS"),
+		AUDIT_SINK_TOKEN:    e.secret("AUDIT_SINK_TOKEN"),
+		AUDIT_POLL_INTERVAL: e.duration("AUDIT_POLL_INTERVAL", 5*time.Second),
+
+		TRASH_RETENTION:      e.duration("TRASH_RETENTION", 30*24*time.Hour),
+		TRASH_PURGE_INTERVAL: e.duration("TRASH_PURGE_INTERVAL", time.Hour),
+
+		CHANGE_LOG_RETENTION:      e.duration("CHANGE_LOG_RETENTION", 30*24*time.Hour),
+		CHANGE_LOG_PRUNE_INTERVAL: e.duration("CHANGE_LOG_PRUNE_INTERVAL", time.Hour),
+
+		TASK_ARCHIVE_AFTER:    e.duration("TASK_ARCHIVE_AFTER", 90*24*time.Hour),
+		TASK_ARCHIVE_INTERVAL: e.duration("TASK_ARCHIVE_INTERVAL", time.Hour),
+
+		JOB_WORKER_ENABLED:     e.bool("JOB_WORKER_ENABLED", true),
+		JOB_WORKER_CONCURRENCY: e.int("JOB_WORKER_CONCURRENCY", 4),
+		JOB_POLL_INTERVAL:      e.duration("JOB_POLL_INTERVAL", time.Second),
+
+		WEBHOOK_ORG_CONCURRENCY:      e.int("WEBHOOK_ORG_CONCURRENCY", 2),
+		WEBHOOK_BREAKER_THRESHOLD:    e.int("WEBHOOK_BREAKER_THRESHOLD", 5),
+		WEBHOOK_BREAKER_COOLDOWN:     e.duration("WEBHOOK_BREAKER_COOLDOWN", time.Minute),
+		WEBHOOK_BREAKER_MAX_COOLDOWN: e.duration("WEBHOOK_BREAKER_MAX_COOLDOWN", time.Hour),
+
+		IDEMPOTENCY_TTL: e.duration("IDEMPOTENCY_TTL", 24*time.Hour),
+
+		APP_URL:        e.string("APP_URL", ""),
+		MAIL_PROVIDER:  e.string("MAIL_PROVIDER", "log"),
+		MAIL_FROM:      e.string("MAIL_FROM", "YATA <no-reply@localhost>"),
+		SMTP_HOST:      e.string("SMTP_HOST", ""),
+		SMTP_PORT:      e.int("SMTP_PORT", 587),
+		SMTP_USERNAME:  e.string("SMTP_USERNAME", ""),
+		SMTP_PASSWORD:  e.secret("SMTP_PASSWORD"),
+		SES_REGION:     e.string("SES_REGION", ""),
+		RESEND_API_KEY: e.secret("RESEND_API_KEY"),
+
+		VAPID_PUBLIC_KEY:  e.string("VAPID_PUBLIC_KEY", ""),
+		VAPID_PRIVATE_KEY: e.secret("VAPID_PRIVATE_KEY"),
+		VAPID_SUBJECT:     e.string("VAPID_SUBJECT", ""),
+
+		S3_ENDPOINT:          e.string("S3_ENDPOINT", "https://s3.amazonaws.com"),
+		S3_REGION:            e.string("S3_REGION", "us-east-1"),
+		S3_BUCKET:            e.string("S3_BUCKET", ""),
+		S3_ACCESS_KEY_ID:     e.string("S3_ACCESS_KEY_ID", ""),
+		S3_SECRET_ACCESS_KEY: e.secret("S3_SECRET_ACCESS_KEY"),
+		S3_USE_PATH_STYLE:    e.bool("S3_USE_PATH_STYLE", false),
+
+		ATTACHMENT_MAX_SIZE:       int64(e.int("ATTACHMENT_MAX_SIZE", 25<<20)),
+		ATTACHMENT_ALLOWED_TYPES:  e.list("ATTACHMENT_ALLOWED_TYPES", defaultAttachmentTypes...),
+		ATTACHMENT_PENDING_TTL:    e.duration("ATTACHMENT_PENDING_TTL", 24*time.Hour),
+		ATTACHMENT_SWEEP_INTERVAL: e.duration("ATTACHMENT_SWEEP_INTERVAL", time.Hour),
+
+		QUOTA_MAX_TASKS:             int64(e.int("QUOTA_MAX_TASKS", 0)),
+		QUOTA_MAX_ATTACHMENT_BYTES:  int64(e.int("QUOTA_MAX_ATTACHMENT_BYTES", 0)),
+		QUOTA_MAX_WEBHOOK_ENDPOINTS: int64(e.int("QUOTA_MAX_WEBHOOK_ENDPOINTS", 0)),
+
+		SHARE_LINK_SECRET:   e.secret("SHARE_LINK_SECRET"),
+		SHARE_LINK_BASE_URL: e.string("SHARE_LINK_BASE_URL", ""),
+
+		SERVICE_TOKEN_SECRET: e.secret("SERVICE_TOKEN_SECRET"),
+		GRPC_PORT:            e.string("GRPC_PORT", ""),
+
+		ENCRYPTION_MASTER_KEY: e.secret("ENCRYPTION_MASTER_KEY"),
+
+		GOOGLE_CLIENT_ID:              e.string("GOOGLE_CLIENT_ID", ""),
+		GOOGLE_CLIENT_SECRET:          e.secret("GOOGLE_CLIENT_SECRET"),
+		GOOGLE_REDIRECT_URL:           e.string("GOOGLE_REDIRECT_URL", ""),
+		GOOGLE_CALENDAR_SYNC_INTERVAL: e.duration("GOOGLE_CALENDAR_SYNC_INTERVAL", 5*time.Minute),
+
+		SLACK_SIGNING_SECRET: e.secret("SLACK_SIGNING_SECRET"),
+
+		INBOUND_EMAIL_DOMAIN:        e.string("INBOUND_EMAIL_DOMAIN", ""),
+		MAILGUN_WEBHOOK_SIGNING_KEY: e.secret("MAILGUN_WEBHOOK_SIGNING_KEY"),
+
+		TELEGRAM_BOT_TOKEN:      e.secret("TELEGRAM_BOT_TOKEN"),
+		TELEGRAM_BOT_USERNAME:   e.string("TELEGRAM_BOT_USERNAME", ""),
+		TELEGRAM_WEBHOOK_SECRET: e.secret("TELEGRAM_WEBHOOK_SECRET"),
+
+		REDIS_URL:             e.secret("REDIS_URL"),
+		CACHE_TTL:             e.duration("CACHE_TTL", 5*time.Minute),
+		RATE_LIMITS:           e.stringMap("RATE_LIMITS", defaultRateLimits),
+		COMPRESSION_MIN_SIZES: e.intMap("COMPRESSION_MIN_SIZES", defaultCompressionMinSizes),
+
+		SECRET_REFRESH_INTERVAL: e.duration("SECRET_REFRESH_INTERVAL", 0),
 	}
+	config.ALLOWED_ORIGINS = e.environmentList("ALLOWED_ORIGINS", config.ENV, defaultOrigins[config.ENV]...)
+	config.ALLOWED_ORIGIN_PATTERNS = e.environmentList("ALLOWED_ORIGIN_PATTERNS", config.ENV)
+	config.secretRefs = e.refs
 
+	config.validate(e)
+	e.checkOverrides()
+	if len(e.problems) > 0 {
+		slices.Sort(e.problems)
+		return nil, &Error{Problems: e.problems}
+	}
 	return config, nil
 }
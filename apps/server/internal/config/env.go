@@ -0,0 +1,206 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// env reads typed settings and collects every problem instead of stopping
+// at the first, so a bad deploy reports all of them at once.
+type env struct {
+	overrides overrides
+	seen      map[string]bool
+	refs      map[string]secretRef
+	problems  []string
+}
+
+func (e *env) problem(key, format string, args ...any) {
+	e.problems = append(e.problems, key+": "+fmt.Sprintf(format, args...))
+}
+
+func (e *env) raw(key string) string {
+	e.seen[key] = true
+	if value, ok := e.overrides[key]; ok {
+		return strings.TrimSpace(value)
+	}
+	return strings.TrimSpace(os.Getenv(key))
+}
+
+// checkOverrides reports -set flags that don't name a setting, which are
+// most likely typos.
+func (e *env) checkOverrides() {
+	for key := range e.overrides {
+		if !e.seen[key] {
+			e.problem(key, "is not a setting (from -set)")
+		}
+	}
+}
+
+func (e *env) string(key, fallback string) string {
+	if value := e.raw(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// secret reads key through ResolveSecret.
+func (e *env) secret(key string) string {
+	raw := e.raw(key)
+	value, err := ResolveSecret(raw)
+	if err != nil {
+		e.problem(key, "%v", err)
+		return ""
+	}
+	if strings.HasPrefix(raw, secretScheme) {
+		e.refs[key] = secretRef{ref: raw, value: value}
+	}
+	return value
+}
+
+// list splits a comma-separated value, or returns fallback when it's empty.
+func (e *env) list(key string, fallback ...string) []string {
+	values := []string{}
+	for _, v := range strings.Split(e.raw(key), ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 && fallback != nil {
+		return append(values, fallback...)
+	}
+	return values
+}
+
+// environmentList reads key_<ENV>, such as ALLOWED_ORIGINS_STAGING, and
+// falls back to key and then fallback, so one env file can hold the values
+// of every environment.
+func (e *env) environmentList(key, environment string, fallback ...string) []string {
+	return e.list(key+"_"+settingName(environment), e.list(key, fallback...)...)
+}
+
+func (e *env) int(key string, fallback int) int {
+	raw := e.raw(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.Atoi(raw)
+	if err != nil {
+		e.problem(key, "%q is not an integer", raw)
+		return fallback
+	}
+	return value
+}
+
+func (e *env) duration(key string, fallback time.Duration) time.Duration {
+	raw := e.raw(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := time.ParseDuration(raw)
+	if err != nil {
+		e.problem(key, "%q is not a duration like 30s or 5m", raw)
+		return fallback
+	}
+	return value
+}
+
+func (e *env) bool(key string, fallback bool) bool {
+	raw := e.raw(key)
+	if raw == "" {
+		return fallback
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		e.problem(key, "%q is not true or false", raw)
+		return fallback
+	}
+	return value
+}
+
+// date parses a YYYY-MM-DD date or an RFC 3339 time; unset is the zero time.
+func (e *env) date(key string) time.Time {
+	raw := e.raw(key)
+	if raw == "" {
+		return time.Time{}
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if value, err := time.Parse(layout, raw); err == nil {
+			return value
+		}
+	}
+	e.problem(key, "%q is not a date like 2026-12-31", raw)
+	return time.Time{}
+}
+
+// durationMap parses "key=duration" pairs separated by commas,
+// e.g. "GET /api/export=2m,GET /api/tasks/:id=2s".
+func (e *env) durationMap(key string) map[string]time.Duration {
+	values := map[string]time.Duration{}
+	for _, pair := range e.list(key) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			e.problem(key, "%q is not key=duration", pair)
+			continue
+		}
+
+		d, err := time.ParseDuration(strings.TrimSpace(v))
+		if err != nil {
+			e.problem(key, "%q has an invalid duration", pair)
+			continue
+		}
+		values[strings.TrimSpace(k)] = d
+	}
+	return values
+}
+
+// stringMap parses "key=value" pairs separated by commas over the
+// defaults, e.g. "api=300/1m,public=60/1m".
+func (e *env) stringMap(key string, defaults map[string]string) map[string]string {
+	values := map[string]string{}
+	for k, v := range defaults {
+		values[k] = v
+	}
+	for _, pair := range e.list(key) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			e.problem(key, "%q is not key=value", pair)
+			continue
+		}
+		values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return values
+}
+
+// intMap parses "key=number" pairs separated by commas over the defaults,
+// e.g. "api=2048,service=0".
+func (e *env) intMap(key string, defaults map[string]int) map[string]int {
+	values := map[string]int{}
+	for k, v := range defaults {
+		values[k] = v
+	}
+	for k, raw := range e.stringMap(key, nil) {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			e.problem(key, "%s=%q is not a whole number", k, raw)
+			continue
+		}
+		values[k] = n
+	}
+	return values
+}
+
+func hostname() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return "unknown"
+	}
+	return name
+}
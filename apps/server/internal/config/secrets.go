@@ -0,0 +1,125 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const secretScheme = "secret://"
+
+// SecretSource resolves the path part of a secret:// reference to the secret value.
+type SecretSource interface {
+	Resolve(path string) (string, error)
+}
+
+// FileSecretSource reads secrets from files, e.g. secret://file/run/secrets/clerk_key.
+type FileSecretSource struct{}
+
+func (FileSecretSource) Resolve(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// EnvSecretSource reads secrets from another environment variable, e.g. secret://env/VAULT_DB_URL.
+type EnvSecretSource struct{}
+
+func (EnvSecretSource) Resolve(path string) (string, error) {
+	name := strings.TrimPrefix(path, "/")
+	value, ok := os.LookupEnv(name)
+	if !ok || value == "" {
+		return "", fmt.Errorf("environment variable %q is not set", name)
+	}
+	return value, nil
+}
+
+var secretSources = map[string]SecretSource{
+	"file":  FileSecretSource{},
+	"env":   EnvSecretSource{},
+	"aws":   AWSSecretSource{},
+	"gcp":   GCPSecretSource{},
+	"vault": VaultSecretSource{},
+}
+
+// RegisterSecretSource makes an additional provider available as secret://<name>/...
+func RegisterSecretSource(name string, source SecretSource) {
+	secretSources[name] = source
+}
+
+// ResolveSecret returns value unchanged unless it is a secret:// reference,
+// in which case the matching SecretSource is asked for the real value. A
+// #fragment is passed on, for sources that pick a field out of a secret.
+func ResolveSecret(value string) (string, error) {
+	if !strings.HasPrefix(value, secretScheme) {
+		return value, nil
+	}
+
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret reference: %w", err)
+	}
+
+	source, ok := secretSources[u.Host]
+	if !ok {
+		return "", fmt.Errorf("unknown secret source %q", u.Host)
+	}
+
+	if u.Path == "" || u.Path == "/" {
+		return "", fmt.Errorf("secret reference %q has no path", value)
+	}
+
+	path := u.Path
+	if u.Fragment != "" {
+		path += "#" + u.Fragment
+	}
+	return source.Resolve(path)
+}
+
+// WatchSecrets re-resolves the settings in onChange that came from a
+// secret:// reference every interval until ctx is done, and calls their
+// callback with the new value whenever it has rotated. Failed lookups are
+// logged and the old value kept.
+func (c *Config) WatchSecrets(ctx context.Context, interval time.Duration, onChange map[string]func(string)) {
+	current := map[string]string{}
+	for key := range onChange {
+		if ref, ok := c.secretRefs[key]; ok {
+			current[key] = ref.value
+		}
+	}
+	if len(current) == 0 || interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			for key, old := range current {
+				value, err := ResolveSecret(c.secretRefs[key].ref)
+				if err != nil {
+					slog.Error("Failed to refresh secret", "key", key, "error", err)
+					continue
+				}
+				if value != old && value != "" {
+					slog.Info("Secret rotated", "key", key)
+					current[key] = value
+					onChange[key](value)
+				}
+			}
+		}
+	}()
+}
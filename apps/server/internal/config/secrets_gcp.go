@@ -0,0 +1,66 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const gcpMetadataToken = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// GCPSecretSource reads Google Secret Manager, e.g.
+// secret://gcp/projects/acme/secrets/yata/versions/latest#database_url.
+// It authenticates with GCP_ACCESS_TOKEN when set and otherwise asks the
+// metadata server for the instance's service account token.
+type GCPSecretSource struct{}
+
+func (GCPSecretSource) Resolve(path string) (string, error) {
+	name, field := splitField(strings.TrimPrefix(path, "/"))
+
+	token, err := gcpToken()
+	if err != nil {
+		return "", fmt.Errorf("gcp access token: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, "https://secretmanager.googleapis.com/v1/"+name+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var out struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := doSecretRequest(req, &out); err != nil {
+		return "", fmt.Errorf("gcp secret %s: %w", name, err)
+	}
+	data, err := base64.StdEncoding.DecodeString(out.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret %s: %w", name, err)
+	}
+	return pickField(strings.TrimSpace(string(data)), field)
+}
+
+func gcpToken() (string, error) {
+	if token := os.Getenv("GCP_ACCESS_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, gcpMetadataToken, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	var out struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := doSecretRequest(req, &out); err != nil {
+		return "", err
+	}
+	return out.AccessToken, nil
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveSecret(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "clerk_key")
+	if err := os.WriteFile(path, []byte("sk_test_from_file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("YATA_TEST_DB_URL", "postgres://from-env")
+
+	tests := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{"plain value", "sk_test_plain", "sk_test_plain", false},
+		{"empty value", "", "", false},
+		{"file reference", "secret://file" + path, "sk_test_from_file", false},
+		{"env reference", "secret://env/YATA_TEST_DB_URL", "postgres://from-env", false},
+		{"missing file", "secret://file" + filepath.Join(dir, "missing"), "", true},
+		{"unset env", "secret://env/YATA_TEST_UNSET", "", true},
+		{"unknown source", "secret://nope/key", "", true},
+		{"no path", "secret://file", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSecret(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSecret(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolveSecret(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvSecretRecordsReferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db_url")
+	if err := os.WriteFile(path, []byte("postgres://secret"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DATABASE_URL", "secret://file"+path)
+	t.Setenv("CLERK_SECRET_KEY", "sk_test_direct")
+
+	e := &env{seen: map[string]bool{}, refs: map[string]secretRef{}}
+	if got := e.secret("DATABASE_URL"); got != "postgres://secret" {
+		t.Errorf("DATABASE_URL = %q, want the file contents", got)
+	}
+	if got := e.secret("CLERK_SECRET_KEY"); got != "sk_test_direct" {
+		t.Errorf("CLERK_SECRET_KEY = %q, want the direct value", got)
+	}
+	if _, ok := e.refs["DATABASE_URL"]; !ok {
+		t.Error("DATABASE_URL reference not recorded for rotation")
+	}
+	if _, ok := e.refs["CLERK_SECRET_KEY"]; ok {
+		t.Error("direct CLERK_SECRET_KEY recorded as a reference")
+	}
+	if len(e.problems) != 0 {
+		t.Errorf("problems = %v, want none", e.problems)
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// VaultSecretSource reads HashiCorp Vault over its HTTP API with
+// VAULT_ADDR and VAULT_TOKEN, e.g. secret://vault/secret/data/yata#database_url.
+// KV version 1 and 2 responses both work; field picks a key from the
+// secret's data and defaults to "value".
+type VaultSecretSource struct{}
+
+func (VaultSecretSource) Resolve(path string) (string, error) {
+	secretPath, field := splitField(strings.TrimPrefix(path, "/"))
+	if field == "" {
+		field = "value"
+	}
+
+	addr, token := os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN")
+	if addr == "" || token == "" {
+		return "", fmt.Errorf("vault secrets need VAULT_ADDR and VAULT_TOKEN")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimRight(addr, "/")+"/v1/"+secretPath, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if ns := os.Getenv("VAULT_NAMESPACE"); ns != "" {
+		req.Header.Set("X-Vault-Namespace", ns)
+	}
+
+	var out struct {
+		Data map[string]json.RawMessage `json:"data"`
+	}
+	if err := doSecretRequest(req, &out); err != nil {
+		return "", fmt.Errorf("vault secret %s: %w", secretPath, err)
+	}
+
+	// KV v2 nests the secret under data.data next to data.metadata.
+	data := out.Data
+	if nested, ok := data["data"]; ok {
+		if _, v2 := data["metadata"]; v2 {
+			data = nil
+			if err := json.Unmarshal(nested, &data); err != nil {
+				return "", fmt.Errorf("vault secret %s: %w", secretPath, err)
+			}
+		}
+	}
+
+	var value string
+	if err := json.Unmarshal(data[field], &value); err != nil {
+		return "", fmt.Errorf("vault secret %s has no string field %q", secretPath, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// Settings are read from, highest precedence first:
+//
+//  1. -set KEY=value flags
+//  2. the process environment
+//  3. the env file: -env-file, else $ENV_FILE, else ./.env if there is one
+//  4. the defaults in LoadConfig
+//
+// The env file only fills in variables the environment doesn't already
+// have, so containers can leave it out entirely.
+
+// overrides holds -set flags by setting name.
+type overrides map[string]string
+
+func (o overrides) String() string {
+	pairs := make([]string, 0, len(o))
+	for k, v := range o {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (o overrides) Set(pair string) error {
+	key, value, ok := strings.Cut(pair, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("%q is not KEY=value", pair)
+	}
+	o[settingName(key)] = value
+	return nil
+}
+
+// settingName lets flags spell settings as port or db-statement-timeout.
+func settingName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(key), "-", "_"))
+}
+
+var (
+	flagOverrides = overrides{}
+	envFileFlag   string
+)
+
+// RegisterFlags adds -set and -env-file to fs. Call it before fs.Parse and
+// LoadConfig after.
+func RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(flagOverrides, "set", "override a setting as KEY=value, e.g. -set PORT=9000; repeatable")
+	fs.StringVar(&envFileFlag, "env-file", "", "read settings from this file instead of ./.env")
+}
+
+// loadEnvFile copies the env file into the environment without replacing
+// anything already set. A missing ./.env is fine; a missing file that was
+// asked for by name isn't.
+func loadEnvFile() error {
+	path, named := envFileFlag, envFileFlag != ""
+	if !named {
+		path = os.Getenv("ENV_FILE")
+		named = path != ""
+	}
+	if !named {
+		path = ".env"
+	}
+
+	err := godotenv.Load(path)
+	if errors.Is(err, fs.ErrNotExist) && !named {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("env file %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/audit"
+	"yata/apps/server/internal/maintenance"
+	"yata/apps/server/internal/origins"
+	"yata/apps/server/internal/ratelimit"
+)
+
+// Error lists everything wrong with the configuration.
+type Error struct {
+	Problems []string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid configuration (%d problems): %s", len(e.Problems), strings.Join(e.Problems, "; "))
+}
+
+// minSigningSecret matches what sharelinks.NewSigner and
+// servicetokens.NewIssuer accept.
+const minSigningSecret = 32
+
+// validate checks what parsing alone can't: required settings, ranges and
+// settings that only make sense together.
+func (c *Config) validate(e *env) {
+	if c.DATABASE_URL == "" {
+		e.problem("DATABASE_URL", "is required")
+	}
+	if c.CLERK_SECRET_KEY == "" {
+		e.problem("CLERK_SECRET_KEY", "is required")
+	}
+
+	if port, err := strconv.Atoi(c.PORT); err != nil || port < 1 || port > 65535 {
+		e.problem("PORT", "%q is not a port number", c.PORT)
+	}
+	if (c.TLS_CERT_FILE == "") != (c.TLS_KEY_FILE == "") {
+		e.problem("TLS_KEY_FILE", "TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+	if c.TLS_CERT_FILE != "" && len(c.TLS_AUTOCERT_DOMAINS) > 0 {
+		e.problem("TLS_AUTOCERT_DOMAINS", "can't be used together with TLS_CERT_FILE")
+	}
+	tlsOn := c.TLS_CERT_FILE != "" || len(c.TLS_AUTOCERT_DOMAINS) > 0
+	if c.TLS_REDIRECT_HTTP && !tlsOn {
+		e.problem("TLS_REDIRECT_HTTP", "needs TLS_CERT_FILE or TLS_AUTOCERT_DOMAINS")
+	}
+	if port, err := strconv.Atoi(c.HTTP_PORT); c.TLS_REDIRECT_HTTP && (err != nil || port < 1 || port > 65535 || c.HTTP_PORT == c.PORT) {
+		e.problem("HTTP_PORT", "%q is not a port number other than PORT", c.HTTP_PORT)
+	}
+
+	if !slices.Contains([]string{"debug", "info", "warn", "error"}, strings.ToLower(c.LOG_LEVEL)) {
+		e.problem("LOG_LEVEL", "%q is not debug, info, warn or error", c.LOG_LEVEL)
+	}
+	if c.MAINTENANCE_MODE != "" && !maintenance.Valid(c.MAINTENANCE_MODE) {
+		e.problem("MAINTENANCE_MODE", "%q is not off, read_only or full", c.MAINTENANCE_MODE)
+	}
+	if c.REQUEST_ID_FORMAT != "uuid" && c.REQUEST_ID_FORMAT != "nanoid" {
+		e.problem("REQUEST_ID_FORMAT", "%q is not uuid or nanoid", c.REQUEST_ID_FORMAT)
+	}
+
+	if _, err := origins.New(c.ALLOWED_ORIGINS, nil); err != nil {
+		e.problem("ALLOWED_ORIGINS", "%v", err)
+	}
+	if _, err := origins.New(nil, c.ALLOWED_ORIGIN_PATTERNS); err != nil {
+		e.problem("ALLOWED_ORIGIN_PATTERNS", "%v", err)
+	}
+
+	positive := map[string]int64{
+		"REQUEST_TIMEOUT":             int64(c.REQUEST_TIMEOUT),
+		"MAINTENANCE_RETRY_AFTER":     int64(c.MAINTENANCE_RETRY_AFTER),
+		"SHUTDOWN_TIMEOUT":            int64(c.SHUTDOWN_TIMEOUT),
+		"JOB_POLL_INTERVAL":           int64(c.JOB_POLL_INTERVAL),
+		"JOB_WORKER_CONCURRENCY":      int64(c.JOB_WORKER_CONCURRENCY),
+		"WEBHOOK_ORG_CONCURRENCY":     int64(c.WEBHOOK_ORG_CONCURRENCY),
+		"CACHE_TTL":                   int64(c.CACHE_TTL),
+		"IDEMPOTENCY_TTL":             int64(c.IDEMPOTENCY_TTL),
+		"CHANGE_LOG_RETENTION":        int64(c.CHANGE_LOG_RETENTION),
+		"ATTACHMENT_MAX_SIZE":         c.ATTACHMENT_MAX_SIZE,
+		"CLERK_JWKS_REFRESH_INTERVAL": int64(c.CLERK_JWKS_REFRESH_INTERVAL),
+	}
+	if len(c.DATABASE_REPLICA_URLS) > 0 {
+		positive["DB_REPLICA_CHECK_INTERVAL"] = int64(c.DB_REPLICA_CHECK_INTERVAL)
+	}
+	if len(c.AUDIT_SINKS) > 0 {
+		positive["AUDIT_POLL_INTERVAL"] = int64(c.AUDIT_POLL_INTERVAL)
+	}
+	// Upload URLs last 15 minutes, so a shorter TTL could sweep an upload
+	// still in flight.
+	if c.ATTACHMENT_SWEEP_INTERVAL > 0 && c.ATTACHMENT_PENDING_TTL < 15*time.Minute {
+		e.problem("ATTACHMENT_PENDING_TTL", "must be at least 15m, how long upload URLs last")
+	}
+	if c.WEBHOOK_BREAKER_THRESHOLD > 0 {
+		positive["WEBHOOK_BREAKER_COOLDOWN"] = int64(c.WEBHOOK_BREAKER_COOLDOWN)
+		if c.WEBHOOK_BREAKER_MAX_COOLDOWN < c.WEBHOOK_BREAKER_COOLDOWN {
+			e.problem("WEBHOOK_BREAKER_MAX_COOLDOWN", "must not be shorter than WEBHOOK_BREAKER_COOLDOWN")
+		}
+	}
+	for key, value := range positive {
+		if value <= 0 {
+			e.problem(key, "must be greater than zero")
+		}
+	}
+	nonNegative := map[string]int64{
+		"RECENT_ERRORS_SIZE":          int64(c.RECENT_ERRORS_SIZE),
+		"CLERK_CLOCK_SKEW":            int64(c.CLERK_CLOCK_SKEW),
+		"QUOTA_MAX_TASKS":             c.QUOTA_MAX_TASKS,
+		"QUOTA_MAX_ATTACHMENT_BYTES":  c.QUOTA_MAX_ATTACHMENT_BYTES,
+		"QUOTA_MAX_WEBHOOK_ENDPOINTS": c.QUOTA_MAX_WEBHOOK_ENDPOINTS,
+		"WEBHOOK_BREAKER_THRESHOLD":   int64(c.WEBHOOK_BREAKER_THRESHOLD),
+		"RETRY_AFTER_BASE":            int64(c.RETRY_AFTER_BASE),
+		"RETRY_AFTER_JITTER":          int64(c.RETRY_AFTER_JITTER),
+	}
+	for key, value := range nonNegative {
+		if value < 0 {
+			e.problem(key, "must not be negative")
+		}
+	}
+
+	switch c.MAIL_PROVIDER {
+	case "log":
+	case "smtp":
+		if c.SMTP_HOST == "" {
+			e.problem("SMTP_HOST", "is required when MAIL_PROVIDER is smtp")
+		}
+	case "ses":
+		if c.SES_REGION == "" {
+			e.problem("SES_REGION", "is required when MAIL_PROVIDER is ses")
+		}
+	case "resend":
+		if c.RESEND_API_KEY == "" {
+			e.problem("RESEND_API_KEY", "is required when MAIL_PROVIDER is resend")
+		}
+	default:
+		e.problem("MAIL_PROVIDER", "%q is not log, smtp, ses or resend", c.MAIL_PROVIDER)
+	}
+
+	if (c.VAPID_PUBLIC_KEY == "") != (c.VAPID_PRIVATE_KEY == "") {
+		e.problem("VAPID_PRIVATE_KEY", "VAPID_PUBLIC_KEY and VAPID_PRIVATE_KEY must be set together")
+	}
+	if c.SHARE_LINK_SECRET != "" && len(c.SHARE_LINK_SECRET) < minSigningSecret {
+		e.problem("SHARE_LINK_SECRET", "must be at least %d bytes", minSigningSecret)
+	}
+	if c.SERVICE_TOKEN_SECRET != "" && len(c.SERVICE_TOKEN_SECRET) < minSigningSecret {
+		e.problem("SERVICE_TOKEN_SECRET", "must be at least %d bytes", minSigningSecret)
+	}
+	if c.GRPC_PORT != "" {
+		if port, err := strconv.Atoi(c.GRPC_PORT); err != nil || port < 1 || port > 65535 || c.GRPC_PORT == c.PORT {
+			e.problem("GRPC_PORT", "%q is not a port number other than PORT", c.GRPC_PORT)
+		}
+		if c.SERVICE_TOKEN_SECRET == "" {
+			e.problem("SERVICE_TOKEN_SECRET", "is required when GRPC_PORT is set")
+		}
+	}
+	if c.ENCRYPTION_MASTER_KEY != "" {
+		if key, err := base64.StdEncoding.DecodeString(c.ENCRYPTION_MASTER_KEY); err != nil || len(key) != 32 {
+			e.problem("ENCRYPTION_MASTER_KEY", "must be 32 bytes in base64")
+		}
+	}
+	if c.GOOGLE_CLIENT_ID != "" || c.GOOGLE_CLIENT_SECRET != "" {
+		if c.GOOGLE_CLIENT_ID == "" || c.GOOGLE_CLIENT_SECRET == "" {
+			e.problem("GOOGLE_CLIENT_SECRET", "GOOGLE_CLIENT_ID and GOOGLE_CLIENT_SECRET must be set together")
+		}
+		if c.GOOGLE_REDIRECT_URL == "" {
+			e.problem("GOOGLE_REDIRECT_URL", "is required when GOOGLE_CLIENT_ID is set")
+		}
+		if c.GOOGLE_CALENDAR_SYNC_INTERVAL <= 0 {
+			e.problem("GOOGLE_CALENDAR_SYNC_INTERVAL", "must be greater than zero")
+		}
+	}
+	if c.INBOUND_EMAIL_DOMAIN != "" {
+		if strings.ContainsAny(c.INBOUND_EMAIL_DOMAIN, "@/ ") || !strings.Contains(c.INBOUND_EMAIL_DOMAIN, ".") {
+			e.problem("INBOUND_EMAIL_DOMAIN", "%q is not a domain name", c.INBOUND_EMAIL_DOMAIN)
+		}
+		if c.MAILGUN_WEBHOOK_SIGNING_KEY == "" {
+			e.problem("MAILGUN_WEBHOOK_SIGNING_KEY", "is required when INBOUND_EMAIL_DOMAIN is set")
+		}
+	}
+	if c.TELEGRAM_BOT_TOKEN != "" {
+		if c.TELEGRAM_BOT_USERNAME == "" {
+			e.problem("TELEGRAM_BOT_USERNAME", "is required when TELEGRAM_BOT_TOKEN is set")
+		}
+		if len(c.TELEGRAM_WEBHOOK_SECRET) < 16 {
+			e.problem("TELEGRAM_WEBHOOK_SECRET", "must be at least 16 characters when TELEGRAM_BOT_TOKEN is set")
+		}
+	}
+	names := map[string]bool{}
+	for _, raw := range c.AUDIT_SINKS {
+		sink, err := audit.NewSink(raw, c.AUDIT_SINK_TOKEN)
+		if err != nil {
+			e.problem("AUDIT_SINKS", "%v", err)
+			continue
+		}
+		if names[sink.Name()] {
+			e.problem("AUDIT_SINKS", "%s is listed twice", sink.Name())
+		}
+		names[sink.Name()] = true
+	}
+	if c.METRICS_USERNAME != "" && c.METRICS_PASSWORD == "" {
+		e.problem("METRICS_PASSWORD", "is required when METRICS_USERNAME is set")
+	}
+
+	for group, rule := range c.RATE_LIMITS {
+		if _, err := ratelimit.ParseRule(rule); err != nil {
+			e.problem("RATE_LIMITS", "%s: %v", group, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package crdt holds the logical clock offline clients stamp their edits
+// with. Every task field is a last-writer-wins register ordered by
+// Timestamp, so replicas that see the same edits in any order agree.
+package crdt
+
+import (
+	"cmp"
+	"errors"
+	"time"
+)
+
+// MaxSkew is how far ahead of the server's clock a timestamp may be. A
+// client with a clock far in the future would otherwise win every conflict.
+const MaxSkew = 5 * time.Minute
+
+// Timestamp is a hybrid logical clock reading: wall time in milliseconds, a
+// counter for events within the same millisecond, and the id of the client
+// that made it as the final tie-breaker.
+type Timestamp struct {
+	Wall    int64  `json:"wall"`
+	Counter int    `json:"counter"`
+	Node    string `json:"node"`
+}
+
+// Compare orders timestamps by wall time, then counter, then node.
+func (t Timestamp) Compare(o Timestamp) int {
+	if c := cmp.Compare(t.Wall, o.Wall); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(t.Counter, o.Counter); c != 0 {
+		return c
+	}
+	return cmp.Compare(t.Node, o.Node)
+}
+
+func (t Timestamp) After(o Timestamp) bool {
+	return t.Compare(o) > 0
+}
+
+// Validate rejects timestamps without a node or too far ahead of now.
+func (t Timestamp) Validate(now time.Time) error {
+	if t.Node == "" {
+		return errors.New("timestamp node is required")
+	}
+	if t.Wall <= 0 || t.Counter < 0 {
+		return errors.New("timestamp is out of range")
+	}
+	if time.UnixMilli(t.Wall).After(now.Add(MaxSkew)) {
+		return errors.New("timestamp is too far in the future")
+	}
+	return nil
+}
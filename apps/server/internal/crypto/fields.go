@@ -0,0 +1,140 @@
+package crypto
+
+import (
+	"context"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// prefix marks an encrypted value. Values without it were written before
+// encryption was turned on, and are read as they are.
+const prefix = "enc1:"
+
+// ErrNoDataKey is a KeyStore's Get for an owner without a data key.
+var ErrNoDataKey = errors.New("no data key")
+
+// KeyStore keeps the wrapped data keys, by owner: an org id, or a user id
+// for personal tasks.
+type KeyStore interface {
+	Get(ctx context.Context, ownerID string) ([]byte, error)
+	// Create stores wrapped as the owner's key unless it has one already,
+	// and returns whichever key it ends up with.
+	Create(ctx context.Context, ownerID string, wrapped []byte) ([]byte, error)
+}
+
+// Fields encrypts and decrypts field values with their owner's data key,
+// creating the key the first time the owner writes one. Unwrapped keys are
+// kept in memory, so the KMS is asked once per owner and process.
+//
+// An encrypted value is bound to its owner, whose id is the additional data
+// it's sealed with: Decrypt is told which owner the row belongs to, and a
+// value copied from another owner's row fails rather than decrypting with
+// the key it names. A nil *Fields stores values in the clear.
+type Fields struct {
+	kms  KMS
+	keys KeyStore
+
+	mu    sync.RWMutex
+	aeads map[string]cipher.AEAD
+}
+
+func NewFields(kms KMS, keys KeyStore) *Fields {
+	return &Fields{kms: kms, keys: keys, aeads: map[string]cipher.AEAD{}}
+}
+
+// Encrypt seals value with ownerID's key. The empty string stays empty.
+func (f *Fields) Encrypt(ctx context.Context, ownerID, value string) (string, error) {
+	if f == nil || value == "" {
+		return value, nil
+	}
+	if ownerID == "" || strings.Contains(ownerID, ":") {
+		return "", fmt.Errorf("invalid data key owner %q", ownerID)
+	}
+	aead, err := f.aead(ctx, ownerID, true)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(aead, []byte(value), []byte(ownerID))
+	if err != nil {
+		return "", err
+	}
+	return prefix + ownerID + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt reverses Encrypt for a value read from a row of ownerID's; values
+// that aren't encrypted come back as they are.
+func (f *Fields) Decrypt(ctx context.Context, ownerID, value string) (string, error) {
+	rest, ok := strings.CutPrefix(value, prefix)
+	if f == nil || !ok {
+		return value, nil
+	}
+	sealedFor, encoded, ok := strings.Cut(rest, ":")
+	if !ok {
+		return "", errors.New("malformed encrypted value")
+	}
+	if sealedFor != ownerID {
+		return "", fmt.Errorf("a field of %s is encrypted for %s", ownerID, sealedFor)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("malformed encrypted value: %w", err)
+	}
+	aead, err := f.aead(ctx, ownerID, false)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, sealed, []byte(ownerID))
+	if err != nil {
+		return "", fmt.Errorf("decrypting a field of %s: %w", ownerID, err)
+	}
+	return string(plaintext), nil
+}
+
+// aead is ownerID's unwrapped data key, made first if create is set.
+func (f *Fields) aead(ctx context.Context, ownerID string, create bool) (cipher.AEAD, error) {
+	f.mu.RLock()
+	aead, ok := f.aeads[ownerID]
+	f.mu.RUnlock()
+	if ok {
+		return aead, nil
+	}
+
+	wrapped, err := f.keys.Get(ctx, ownerID)
+	if errors.Is(err, ErrNoDataKey) && create {
+		wrapped, err = f.createKey(ctx, ownerID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("data key of %s: %w", ownerID, err)
+	}
+	key, err := f.kms.Unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrapping the data key of %s: %w", ownerID, err)
+	}
+	if aead, err = newAEAD(key); err != nil {
+		return nil, fmt.Errorf("data key of %s: %w", ownerID, err)
+	}
+
+	f.mu.Lock()
+	f.aeads[ownerID] = aead
+	f.mu.Unlock()
+	return aead, nil
+}
+
+// createKey stores a new data key for ownerID, or returns the one another
+// request stored first.
+func (f *Fields) createKey(ctx context.Context, ownerID string) ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	wrapped, err := f.kms.Wrap(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	return f.keys.Create(ctx, ownerID, wrapped)
+}
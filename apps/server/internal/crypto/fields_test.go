@@ -0,0 +1,96 @@
+package crypto
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type memoryKeys struct {
+	mu   sync.Mutex
+	keys map[string][]byte
+}
+
+func (k *memoryKeys) Get(_ context.Context, ownerID string) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	wrapped, ok := k.keys[ownerID]
+	if !ok {
+		return nil, ErrNoDataKey
+	}
+	return wrapped, nil
+}
+
+func (k *memoryKeys) Create(_ context.Context, ownerID string, wrapped []byte) ([]byte, error) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if existing, ok := k.keys[ownerID]; ok {
+		return existing, nil
+	}
+	k.keys[ownerID] = wrapped
+	return wrapped, nil
+}
+
+func newTestFields(t *testing.T) *Fields {
+	t.Helper()
+	kms, err := NewLocalKMS(bytes.Repeat([]byte{7}, KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewFields(kms, &memoryKeys{keys: map[string][]byte{}})
+}
+
+func TestFieldsRoundTrip(t *testing.T) {
+	f := newTestFields(t)
+	ctx := context.Background()
+
+	sealed, err := f.Encrypt(ctx, "org_a", "payroll.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "payroll") {
+		t.Fatalf("encrypted value %q contains the plaintext", sealed)
+	}
+	got, err := f.Decrypt(ctx, "org_a", sealed)
+	if err != nil || got != "payroll.xlsx" {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+}
+
+func TestFieldsRejectOtherOwnersValues(t *testing.T) {
+	f := newTestFields(t)
+	ctx := context.Background()
+
+	sealed, err := f.Encrypt(ctx, "org_a", "payroll.xlsx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.Encrypt(ctx, "org_b", "other"); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := f.Decrypt(ctx, "org_b", sealed); err == nil {
+		t.Fatalf("org_a's value read as org_b's: %q", got)
+	}
+
+	// Renaming the owner in the value doesn't get past the additional data.
+	forged := strings.Replace(sealed, "org_a", "org_b", 1)
+	if got, err := f.Decrypt(ctx, "org_b", forged); err == nil {
+		t.Fatalf("forged value decrypted: %q", got)
+	}
+}
+
+func TestFieldsPassPlaintextThrough(t *testing.T) {
+	f := newTestFields(t)
+	got, err := f.Decrypt(context.Background(), "org_a", "written before encryption")
+	if err != nil || got != "written before encryption" {
+		t.Fatalf("Decrypt = %q, %v", got, err)
+	}
+
+	var off *Fields
+	sealed, err := off.Encrypt(context.Background(), "org_a", "clear")
+	if err != nil || sealed != "clear" {
+		t.Fatalf("nil Fields Encrypt = %q, %v", sealed, err)
+	}
+}
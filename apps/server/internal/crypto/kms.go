@@ -0,0 +1,73 @@
+// Package crypto encrypts designated fields at rest. Each org, and each
+// user for their personal tasks, gets a data key of its own; data keys are
+// stored wrapped by a master key that never leaves its KMS, so a copy of
+// the database alone reads nothing.
+package crypto
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the length of master and data keys: AES-256.
+const KeySize = 32
+
+// KMS holds the master key. Wrap encrypts a data key with it and Unwrap
+// reverses that; a hosted KMS can stand in for LocalKMS behind this.
+type KMS interface {
+	Wrap(ctx context.Context, key []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// LocalKMS is a master key held by the process, from configuration.
+type LocalKMS struct {
+	aead cipher.AEAD
+}
+
+func NewLocalKMS(masterKey []byte) (*LocalKMS, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("master key: %w", err)
+	}
+	return &LocalKMS{aead: aead}, nil
+}
+
+func (k *LocalKMS) Wrap(_ context.Context, key []byte) ([]byte, error) {
+	return seal(k.aead, key, nil)
+}
+
+func (k *LocalKMS) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	return open(k.aead, wrapped, nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	if len(key) != KeySize {
+		return nil, fmt.Errorf("key is %d bytes, not %d", len(key), KeySize)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal is a random nonce followed by the ciphertext.
+func seal(aead cipher.AEAD, plaintext, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(aead cipher.AEAD, sealed, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additional)
+}
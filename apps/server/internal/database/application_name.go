@@ -0,0 +1,23 @@
+package database
+
+import "github.com/jackc/pgx/v5/pgxpool"
+
+// Postgres truncates application_name to NAMEDATALEN-1 bytes.
+const maxApplicationNameLength = 63
+
+// WithApplicationName sets application_name on every connection so DBAs can
+// attribute queries. A name already present in the DSN wins unless override is set.
+func WithApplicationName(name string, override bool) Option {
+	return func(cfg *pgxpool.Config) {
+		if name == "" {
+			return
+		}
+		if _, ok := cfg.ConnConfig.RuntimeParams["application_name"]; ok && !override {
+			return
+		}
+		if len(name) > maxApplicationNameLength {
+			name = name[:maxApplicationNameLength]
+		}
+		cfg.ConnConfig.RuntimeParams["application_name"] = name
+	}
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithApplicationName(t *testing.T) {
+	const base = "postgres://yata@localhost:5432/yata"
+
+	tests := []struct {
+		name     string
+		dsn      string
+		appName  string
+		override bool
+		want     string
+	}{
+		{"set when absent", base, "yata-production-web-1", false, "yata-production-web-1"},
+		{"dsn wins", base + "?application_name=psql-debug", "yata-production-web-1", false, "psql-debug"},
+		{"override replaces dsn", base + "?application_name=psql-debug", "yata-custom", true, "yata-custom"},
+		{"empty name leaves dsn", base + "?application_name=psql-debug", "", true, "psql-debug"},
+		{"truncated", base, strings.Repeat("y", 80), false, strings.Repeat("y", maxApplicationNameLength)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig(tt.dsn, WithApplicationName(tt.appName, tt.override))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := cfg.ConnConfig.RuntimeParams["application_name"]; got != tt.want {
+				t.Errorf("application_name = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
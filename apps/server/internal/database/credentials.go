@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WithRotatingCredentials takes the user and password for each new
+// connection from current, so a rotated DATABASE_URL applies without a
+// restart. Open connections keep the credentials they were opened with.
+func WithRotatingCredentials(current func() string) Option {
+	return func(cfg *pgxpool.Config) {
+		next := cfg.BeforeConnect
+		cfg.BeforeConnect = func(ctx context.Context, conn *pgx.ConnConfig) error {
+			if next != nil {
+				if err := next(ctx, conn); err != nil {
+					return err
+				}
+			}
+
+			latest, err := pgx.ParseConfig(current())
+			if err != nil {
+				// Keep connecting with what worked before.
+				slog.Error("Failed to parse rotated DATABASE_URL", "error", err)
+				return nil
+			}
+			conn.User, conn.Password = latest.User, latest.Password
+			return nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Package dbtest gives tests a migrated Postgres to run against.
+package dbtest
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/database/migrations"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+)
+
+const image = "postgres:17-alpine"
+
+var (
+	once     sync.Once
+	url      string
+	setupErr error
+)
+
+// URL returns the connection string of a migrated database. It is
+// TEST_DATABASE_URL when set; otherwise a Postgres container is started the
+// first time it's asked for and shared by the rest of the test binary.
+// Tests are skipped when there is neither a database nor Docker.
+func URL(t *testing.T) string {
+	t.Helper()
+
+	if testing.Short() {
+		t.Skip("needs Postgres; skipped in -short mode")
+	}
+	if os.Getenv("TEST_DATABASE_URL") == "" {
+		testcontainers.SkipIfProviderIsNotHealthy(t)
+	}
+
+	once.Do(func() { url, setupErr = setup(context.Background()) })
+	if setupErr != nil {
+		t.Fatalf("Failed to set up the test database: %v", setupErr)
+	}
+	return url
+}
+
+// Pool connects to the database from URL and closes the pool when the test ends.
+func Pool(t *testing.T, opts ...database.Option) *pgxpool.Pool {
+	t.Helper()
+
+	pool, err := database.Connect(URL(t), opts...)
+	if err != nil {
+		t.Fatalf("Failed to connect to the test database: %v", err)
+	}
+	t.Cleanup(pool.Close)
+	return pool
+}
+
+func setup(ctx context.Context) (string, error) {
+	connString := os.Getenv("TEST_DATABASE_URL")
+	if connString == "" {
+		// The container is left to the testcontainers reaper, which removes
+		// it when the test binary exits.
+		container, err := postgres.Run(ctx, image,
+			postgres.WithDatabase("yata"),
+			postgres.WithUsername("yata"),
+			postgres.WithPassword("yata"),
+			postgres.BasicWaitStrategies(),
+		)
+		if err != nil {
+			return "", err
+		}
+		connString, err = container.ConnectionString(ctx, "sslmode=disable")
+		if err != nil {
+			return "", err
+		}
+	}
+
+	pool, err := database.Connect(connString)
+	if err != nil {
+		return "", err
+	}
+	defer pool.Close()
+
+	if _, err := migrations.Up(ctx, pool); err != nil {
+		return "", err
+	}
+	return connString, nil
+}
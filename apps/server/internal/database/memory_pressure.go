@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// MemoryPressureLimiter shrinks the pool while the process heap is above a
+// threshold and lifts the limit again once it drops back under it.
+type MemoryPressureLimiter struct {
+	Threshold  uint64
+	ReducedMax int32
+	Interval   time.Duration
+
+	// ReadHeap reports current heap usage in bytes. Defaults to runtime.ReadMemStats.
+	ReadHeap func() uint64
+
+	limit atomic.Int32
+	pool  *pgxpool.Pool
+}
+
+func NewMemoryPressureLimiter(threshold uint64, reducedMax int32, interval time.Duration) *MemoryPressureLimiter {
+	if reducedMax < 1 {
+		reducedMax = 1
+	}
+	return &MemoryPressureLimiter{
+		Threshold:  threshold,
+		ReducedMax: reducedMax,
+		Interval:   interval,
+		ReadHeap: func() uint64 {
+			var m runtime.MemStats
+			runtime.ReadMemStats(&m)
+			return m.HeapAlloc
+		},
+	}
+}
+
+// WithMemoryPressureLimit hooks the limiter into the pool so connections
+// released while over the reduced limit are closed instead of kept idle.
+func WithMemoryPressureLimit(l *MemoryPressureLimiter) Option {
+	return func(cfg *pgxpool.Config) {
+		next := cfg.AfterRelease
+		cfg.AfterRelease = func(conn *pgx.Conn) bool {
+			if next != nil && !next(conn) {
+				return false
+			}
+			return !l.overLimit()
+		}
+	}
+}
+
+// EffectiveMaxConns is the reduced max while under pressure, otherwise the pool's configured max.
+func (l *MemoryPressureLimiter) EffectiveMaxConns() int32 {
+	if limit := l.limit.Load(); limit > 0 {
+		return limit
+	}
+	if l.pool != nil {
+		return l.pool.Config().MaxConns
+	}
+	return 0
+}
+
+func (l *MemoryPressureLimiter) UnderPressure() bool {
+	return l.limit.Load() > 0
+}
+
+// Start checks memory every Interval until ctx is done.
+func (l *MemoryPressureLimiter) Start(ctx context.Context, pool *pgxpool.Pool) {
+	l.pool = pool
+
+	go func() {
+		ticker := time.NewTicker(l.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				l.Check(ctx)
+			}
+		}
+	}()
+}
+
+// Check samples the heap once and tightens or restores the limit.
+func (l *MemoryPressureLimiter) Check(ctx context.Context) {
+	heap := l.ReadHeap()
+
+	if heap >= l.Threshold {
+		if !l.UnderPressure() {
+			slog.Warn("Memory pressure detected, limiting pool", "max_conns", l.ReducedMax)
+		}
+		l.limit.Store(l.ReducedMax)
+		l.trimIdle(ctx)
+		return
+	}
+
+	// Small hysteresis so we don't flap around the threshold.
+	if l.UnderPressure() && heap < l.Threshold-l.Threshold/10 {
+		slog.Info("Memory pressure cleared, restoring pool limits")
+		l.limit.Store(0)
+	}
+}
+
+func (l *MemoryPressureLimiter) overLimit() bool {
+	limit := l.limit.Load()
+	return limit > 0 && l.pool != nil && l.pool.Stat().TotalConns() > limit
+}
+
+// trimIdle cycles idle connections through Release so AfterRelease can
+// drop the ones above the limit.
+func (l *MemoryPressureLimiter) trimIdle(ctx context.Context) {
+	if l.pool == nil {
+		return
+	}
+	for _, conn := range l.pool.AcquireAllIdle(ctx) {
+		conn.Release()
+	}
+}
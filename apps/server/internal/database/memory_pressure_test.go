@@ -0,0 +1,57 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func TestMemoryPressureLimiter(t *testing.T) {
+	cfg, err := pgxpool.ParseConfig("postgres://yata@127.0.0.1:1/yata?pool_max_conns=10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := NewMemoryPressureLimiter(1000, 2, 0)
+	WithMemoryPressureLimit(l)(cfg)
+
+	// The pool connects lazily, so nothing dials the bogus address.
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Close()
+	l.pool = pool
+
+	var heap uint64
+	l.ReadHeap = func() uint64 { return heap }
+
+	steps := []struct {
+		name     string
+		heap     uint64
+		pressure bool
+		max      int32
+	}{
+		{"below threshold", 500, false, 10},
+		{"at threshold", 1000, true, 2},
+		{"inside hysteresis band", 950, true, 2},
+		{"cleared", 850, false, 10},
+		{"pressure again", 5000, true, 2},
+	}
+	for _, s := range steps {
+		heap = s.heap
+		l.Check(context.Background())
+		if got := l.UnderPressure(); got != s.pressure {
+			t.Errorf("%s: UnderPressure = %v, want %v", s.name, got, s.pressure)
+		}
+		if got := l.EffectiveMaxConns(); got != s.max {
+			t.Errorf("%s: EffectiveMaxConns = %d, want %d", s.name, got, s.max)
+		}
+	}
+}
+
+func TestNewMemoryPressureLimiterFloorsReducedMax(t *testing.T) {
+	if got := NewMemoryPressureLimiter(1, 0, 0).ReducedMax; got != 1 {
+		t.Errorf("ReducedMax = %d, want 1", got)
+	}
+}
@@ -0,0 +1,28 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT NOT NULL,          -- Clerk org id
+    name        TEXT NOT NULL,
+    created_by  TEXT NOT NULL,          -- Clerk user id
+    archived_at TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_projects_org_id ON projects(org_id);
+
+CREATE TABLE IF NOT EXISTS tasks (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id    TEXT NOT NULL,          -- Clerk user id of the creator
+    org_id      TEXT,                   -- Clerk org id, NULL for personal tasks
+    project_id  UUID REFERENCES projects(id) ON DELETE SET NULL,
+    title       TEXT NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    status      TEXT NOT NULL DEFAULT 'todo',
+    due_date    TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_org_id ON tasks(org_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_owner_personal ON tasks(owner_id) WHERE org_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS labels (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT NOT NULL,          -- Clerk org id
+    name        TEXT NOT NULL,
+    color       TEXT NOT NULL,          -- #rrggbb
+    created_by  TEXT NOT NULL,          -- Clerk user id
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_labels_org_name ON labels(org_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS task_labels (
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    label_id    UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_labels_label_id ON task_labels(label_id);
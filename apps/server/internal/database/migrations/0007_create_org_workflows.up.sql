@@ -0,0 +1,8 @@
+-- Per-org status and priority configuration. A NULL column means the org
+-- uses the built-in defaults for it.
+CREATE TABLE IF NOT EXISTS org_workflows (
+    org_id      TEXT PRIMARY KEY,       -- Clerk org id
+    statuses    JSONB,
+    priorities  JSONB,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,2 @@
+-- IANA zone the due date was set in, e.g. Europe/Berlin.
+ALTER TABLE tasks ADD COLUMN due_timezone TEXT;
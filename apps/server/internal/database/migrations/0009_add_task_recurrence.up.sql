@@ -0,0 +1,2 @@
+-- RFC 5545 RRULE, anchored at due_date.
+ALTER TABLE tasks ADD COLUMN recurrence TEXT;
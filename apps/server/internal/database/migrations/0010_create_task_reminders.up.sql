@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS task_reminders (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id         UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id         TEXT NOT NULL,          -- Clerk user id to remind
+    remind_at       TIMESTAMPTZ,            -- absolute reminder
+    minutes_before  INTEGER,                -- or relative to the task's due date
+    sent_at         TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((remind_at IS NULL) <> (minutes_before IS NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_task_reminders_task_id ON task_reminders(task_id);
+CREATE INDEX IF NOT EXISTS idx_task_reminders_pending ON task_reminders(remind_at) WHERE sent_at IS NULL;
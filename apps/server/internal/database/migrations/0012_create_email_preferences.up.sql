@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS email_preferences (
+    user_id          TEXT PRIMARY KEY,      -- Clerk user id
+    task_assigned    BOOLEAN NOT NULL DEFAULT TRUE,
+    due_soon         BOOLEAN NOT NULL DEFAULT TRUE,
+    comment_mention  BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
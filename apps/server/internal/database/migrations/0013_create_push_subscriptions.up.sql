@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS push_subscriptions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     TEXT NOT NULL,          -- Clerk user id
+    endpoint    TEXT NOT NULL UNIQUE,   -- the push service URL, one per browser
+    p256dh      TEXT NOT NULL,
+    auth        TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_push_subscriptions_user_id ON push_subscriptions (user_id);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS notifications (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     TEXT NOT NULL,          -- Clerk user id of the recipient
+    org_id      TEXT,
+    kind        TEXT NOT NULL,
+    task_id     UUID REFERENCES tasks(id) ON DELETE CASCADE,
+    actor_id    TEXT,
+    title       TEXT NOT NULL,
+    body        TEXT NOT NULL DEFAULT '',
+    read_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_unread ON notifications (user_id) WHERE read_at IS NULL;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS task_field_clocks;
+DROP TABLE IF EXISTS sync_ops;
@@ -0,0 +1,29 @@
+-- Log of applied offline edits; seq is the checkpoint clients pull from.
+CREATE TABLE IF NOT EXISTS sync_ops (
+    seq         BIGSERIAL PRIMARY KEY,
+    id          UUID NOT NULL UNIQUE,   -- client-generated op id
+    owner_id    TEXT NOT NULL,
+    org_id      TEXT,
+    task_id     UUID NOT NULL,
+    type        TEXT NOT NULL,
+    field       TEXT,
+    value       JSONB,
+    ts_wall     BIGINT NOT NULL,
+    ts_counter  INTEGER NOT NULL,
+    ts_node     TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sync_ops_org_seq ON sync_ops (org_id, seq);
+CREATE INDEX IF NOT EXISTS idx_sync_ops_personal_seq ON sync_ops (owner_id, seq) WHERE org_id IS NULL;
+
+-- Latest timestamp per task field. Kept after a task is deleted so the
+-- '_deleted' tombstone keeps late edits from recreating it.
+CREATE TABLE IF NOT EXISTS task_field_clocks (
+    task_id     UUID NOT NULL,
+    field       TEXT NOT NULL,
+    ts_wall     BIGINT NOT NULL,
+    ts_counter  INTEGER NOT NULL,
+    ts_node     TEXT NOT NULL,
+    PRIMARY KEY (task_id, field)
+);
@@ -0,0 +1,2 @@
+-- Bumped on every write; served as the task's ETag.
+ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id       TEXT NOT NULL,        -- Clerk user id
+    key           TEXT NOT NULL,        -- the client's Idempotency-Key header
+    fingerprint   TEXT NOT NULL,        -- hash of method, path and body
+    status_code   INTEGER,              -- NULL while the first request is running
+    content_type  TEXT,
+    body          BYTEA,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_created_at ON idempotency_keys (created_at);
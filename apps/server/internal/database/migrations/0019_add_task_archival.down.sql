@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_active_owner_created;
+DROP INDEX IF EXISTS idx_tasks_active_org_created;
+ALTER TABLE tasks DROP COLUMN IF EXISTS archived_at;
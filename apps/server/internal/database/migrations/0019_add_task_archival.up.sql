@@ -0,0 +1,9 @@
+-- Archived tasks stay in the tasks table so labels, dependencies and
+-- reminders keep pointing at them; the partial index keeps listing the
+-- working set fast no matter how much history an org has built up.
+ALTER TABLE tasks ADD COLUMN archived_at TIMESTAMPTZ;
+
+CREATE INDEX idx_tasks_active_org_created ON tasks(org_id, created_at DESC)
+    WHERE archived_at IS NULL AND deleted_at IS NULL;
+CREATE INDEX idx_tasks_active_owner_created ON tasks(owner_id, created_at DESC)
+    WHERE archived_at IS NULL AND deleted_at IS NULL AND org_id IS NULL;
@@ -0,0 +1,15 @@
+-- No foreign keys: the trail outlives tasks and projects purged from the
+-- trash.
+CREATE TABLE activity (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT,                   -- Clerk org id, NULL for personal tasks
+    actor_id    TEXT NOT NULL,          -- Clerk user id
+    task_id     UUID,
+    project_id  UUID,
+    action      TEXT NOT NULL,
+    changes     JSONB NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_activity_task ON activity(task_id, created_at DESC, id DESC) WHERE task_id IS NOT NULL;
+CREATE INDEX idx_activity_org ON activity(org_id, created_at DESC, id DESC) WHERE org_id IS NOT NULL;
@@ -0,0 +1,21 @@
+CREATE TABLE comments (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    author_id   TEXT NOT NULL,          -- Clerk user id
+    body        TEXT NOT NULL,
+    mentions    TEXT[] NOT NULL DEFAULT '{}', -- Clerk user ids
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_comments_task ON comments(task_id, created_at, id);
+
+-- Each edit keeps the body it replaced.
+CREATE TABLE comment_revisions (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    comment_id  UUID NOT NULL REFERENCES comments(id) ON DELETE CASCADE,
+    body        TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_comment_revisions_comment ON comment_revisions(comment_id, created_at);
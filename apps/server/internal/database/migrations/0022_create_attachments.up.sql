@@ -0,0 +1,17 @@
+-- File contents live in object storage under key; this is only metadata.
+-- Rows start out pending when an upload URL is handed out and become ready
+-- once the server has checked the uploaded object. Deleting a task through
+-- the cascade leaves its objects behind for the bucket's lifecycle rules.
+CREATE TABLE attachments (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id       UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    uploader_id   TEXT NOT NULL,          -- Clerk user id
+    key           TEXT NOT NULL UNIQUE,
+    filename      TEXT NOT NULL,
+    content_type  TEXT NOT NULL,
+    size          BIGINT NOT NULL,
+    confirmed_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_attachments_task ON attachments(task_id, created_at, id) WHERE confirmed_at IS NOT NULL;
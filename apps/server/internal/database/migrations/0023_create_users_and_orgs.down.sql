@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS org_memberships;
+DROP TABLE IF EXISTS organizations;
+DROP TABLE IF EXISTS users;
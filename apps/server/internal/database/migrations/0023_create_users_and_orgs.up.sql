@@ -0,0 +1,36 @@
+-- Local copies of Clerk's users, organizations and memberships, kept up to
+-- date by the Clerk webhook. Ids are Clerk's. updated_at is Clerk's own
+-- timestamp, so a delivery that arrives late can't overwrite a newer one.
+-- Users and organizations are tombstoned rather than deleted, so a late
+-- update can't bring them back and old tasks can still show who made them.
+CREATE TABLE users (
+    id          TEXT PRIMARY KEY,
+    email       TEXT,
+    first_name  TEXT,
+    last_name   TEXT,
+    username    TEXT,
+    image_url   TEXT,
+    updated_at  TIMESTAMPTZ NOT NULL,
+    deleted_at  TIMESTAMPTZ
+);
+
+CREATE TABLE organizations (
+    id          TEXT PRIMARY KEY,
+    name        TEXT NOT NULL,
+    slug        TEXT,
+    image_url   TEXT,
+    updated_at  TIMESTAMPTZ NOT NULL,
+    deleted_at  TIMESTAMPTZ
+);
+
+-- No foreign keys: Clerk doesn't promise to deliver a user's or an org's
+-- event before the membership that mentions them.
+CREATE TABLE org_memberships (
+    org_id      TEXT NOT NULL,
+    user_id     TEXT NOT NULL,
+    role        TEXT NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (org_id, user_id)
+);
+
+CREATE INDEX idx_org_memberships_user ON org_memberships(user_id);
@@ -0,0 +1,14 @@
+-- One row per user who changed anything; everyone else gets the defaults in
+-- models.DefaultUserSettings.
+CREATE TABLE user_settings (
+    user_id             TEXT PRIMARY KEY,   -- Clerk user id
+    timezone            TEXT,               -- IANA zone
+    week_start          TEXT NOT NULL DEFAULT 'monday',
+    -- No foreign key: the project may be trashed or purged, and clients
+    -- ignore a default they can't find.
+    default_project_id  UUID,
+    email_enabled       BOOLEAN NOT NULL DEFAULT TRUE,
+    push_enabled        BOOLEAN NOT NULL DEFAULT TRUE,
+    digest_frequency    TEXT NOT NULL DEFAULT 'off',
+    updated_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,12 @@
+-- Org-wide defaults. Orgs without a row get models.DefaultOrgSettings.
+CREATE TABLE org_settings (
+    org_id                   TEXT PRIMARY KEY,
+    -- Weekdays as in Go's time.Weekday: 0 is Sunday.
+    working_days             SMALLINT[] NOT NULL DEFAULT '{1,2,3,4,5}',
+    default_task_visibility  TEXT NOT NULL DEFAULT 'org',
+    -- Empty allows any color.
+    allowed_label_colors     TEXT[] NOT NULL DEFAULT '{}',
+    -- NULL keeps the server's TRASH_RETENTION.
+    trash_retention_days     INT,
+    updated_at               TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS project_shares;
+DROP TABLE IF EXISTS task_shares;
+ALTER TABLE tasks DROP COLUMN IF EXISTS visibility;
@@ -0,0 +1,27 @@
+-- Tasks default to being visible to the whole org, as they always were.
+-- Private ones are visible to their owner and to whoever they, or their
+-- project, are shared with.
+ALTER TABLE tasks ADD COLUMN visibility TEXT NOT NULL DEFAULT 'org';
+
+-- Sharing a project shares every private task in it.
+CREATE TABLE task_shares (
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id     TEXT NOT NULL,          -- Clerk user id
+    role        TEXT NOT NULL,          -- viewer | editor
+    created_by  TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE TABLE project_shares (
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id     TEXT NOT NULL,
+    role        TEXT NOT NULL,
+    created_by  TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+-- The access check looks shares up by user.
+CREATE INDEX idx_task_shares_user ON task_shares(user_id, task_id);
+CREATE INDEX idx_project_shares_user ON project_shares(user_id, project_id);
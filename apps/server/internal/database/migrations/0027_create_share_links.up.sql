@@ -0,0 +1,28 @@
+-- A share link exposes one task or project, read-only, to anyone holding
+-- its URL. The URL's token is signed and carries the expiry; the row is what
+-- lets a link be revoked before then. Links see what their creator sees, so
+-- personal tasks keep org_id NULL like the tasks themselves.
+CREATE TABLE share_links (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT,
+    task_id     UUID REFERENCES tasks(id) ON DELETE CASCADE,
+    project_id  UUID REFERENCES projects(id) ON DELETE CASCADE,
+    created_by  TEXT NOT NULL,          -- Clerk user id
+    expires_at  TIMESTAMPTZ NOT NULL,
+    revoked_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((task_id IS NULL) <> (project_id IS NULL))
+);
+
+CREATE INDEX idx_share_links_task ON share_links(task_id, created_at) WHERE task_id IS NOT NULL;
+CREATE INDEX idx_share_links_project ON share_links(project_id, created_at) WHERE project_id IS NOT NULL;
+
+CREATE TABLE share_link_accesses (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    link_id      UUID NOT NULL REFERENCES share_links(id) ON DELETE CASCADE,
+    ip           TEXT NOT NULL,
+    user_agent   TEXT NOT NULL,
+    accessed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_share_link_accesses_link ON share_link_accesses(link_id, accessed_at DESC, id DESC);
@@ -0,0 +1,20 @@
+-- Guests are invited to a single project. Clerk sends the email and adds
+-- them to the org with the guest role; accepting the invitation here is what
+-- shares the project with them. Only the token's hash is kept.
+CREATE TABLE project_invitations (
+    id                   UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id               TEXT NOT NULL,
+    project_id           UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    email                TEXT NOT NULL,
+    role                 TEXT NOT NULL,          -- viewer | editor, as in project_shares
+    token_hash           BYTEA NOT NULL UNIQUE,
+    clerk_invitation_id  TEXT NOT NULL,
+    invited_by           TEXT NOT NULL,
+    expires_at           TIMESTAMPTZ NOT NULL,
+    accepted_by          TEXT,
+    accepted_at          TIMESTAMPTZ,
+    revoked_at           TIMESTAMPTZ,
+    created_at           TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_invitations_project ON project_invitations(project_id, created_at);
@@ -0,0 +1,18 @@
+-- Personal access tokens let scripts call the API as a user without a
+-- Clerk session. A token is bound to the org that was active when it was
+-- minted (NULL for personal scope), and to the user's role there at request
+-- time. Only the token's hash is kept.
+CREATE TABLE api_tokens (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       TEXT NOT NULL,          -- Clerk user id
+    org_id        TEXT,
+    name          TEXT NOT NULL,
+    prefix        TEXT NOT NULL,          -- start of the token, to tell them apart
+    token_hash    BYTEA NOT NULL UNIQUE,
+    scopes        TEXT[] NOT NULL,        -- read | write
+    expires_at    TIMESTAMPTZ,
+    last_used_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_api_tokens_user ON api_tokens(user_id, created_at);
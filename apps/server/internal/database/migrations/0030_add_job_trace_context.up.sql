@@ -0,0 +1,3 @@
+-- The W3C trace context of whatever enqueued the job, so the job's span
+-- joins the request's trace.
+ALTER TABLE jobs ADD COLUMN IF NOT EXISTS trace_context JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,21 @@
+-- Imports turn an export from another task manager into tasks. The upload
+-- is kept in data until the job worker has gone through it.
+CREATE TABLE imports (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      TEXT NOT NULL,          -- Clerk user id
+    org_id       TEXT,
+    source       TEXT NOT NULL,          -- todoist | trello | asana
+    file_name    TEXT NOT NULL,
+    timezone     TEXT,
+    status       TEXT NOT NULL DEFAULT 'pending',  -- pending | running | done | failed
+    total        INTEGER NOT NULL DEFAULT 0,
+    imported     INTEGER NOT NULL DEFAULT 0,
+    skipped      INTEGER NOT NULL DEFAULT 0,
+    error        TEXT,
+    data         BYTEA,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_imports_user ON imports(user_id, created_at);
@@ -0,0 +1,14 @@
+-- Calendar feeds are secret URLs serving a user's due dates as iCalendar,
+-- for calendar apps that can't authenticate. There's one per user and org
+-- (or personal scope); rotating the URL replaces the row. Only the token's
+-- hash is kept.
+CREATE TABLE calendar_feeds (
+    id            UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id       TEXT NOT NULL,          -- Clerk user id
+    org_id        TEXT,
+    token_hash    BYTEA NOT NULL UNIQUE,
+    last_used_at  TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_calendar_feeds_scope ON calendar_feeds(user_id, COALESCE(org_id, ''));
@@ -0,0 +1,31 @@
+-- Google Calendar connections sync a user's tasks with due dates to their
+-- calendar, one connection per user and org (or personal scope). The sync
+-- worker claims a connection by pushing next_sync_at forward.
+CREATE TABLE google_calendar_connections (
+    id                UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id           TEXT NOT NULL,          -- Clerk user id
+    org_id            TEXT,
+    email             TEXT NOT NULL,
+    calendar_id       TEXT NOT NULL DEFAULT 'primary',
+    access_token      TEXT NOT NULL,
+    refresh_token     TEXT NOT NULL,
+    token_expires_at  TIMESTAMPTZ NOT NULL,
+    next_sync_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_synced_at    TIMESTAMPTZ,
+    last_error        TEXT,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_google_calendar_connections_scope ON google_calendar_connections(user_id, COALESCE(org_id, ''));
+CREATE INDEX idx_google_calendar_connections_next_sync ON google_calendar_connections(next_sync_at);
+
+-- The event made for each task. task_id deliberately has no foreign key:
+-- when a task is purged the sync still needs the event id to delete it.
+CREATE TABLE google_calendar_events (
+    connection_id    UUID NOT NULL REFERENCES google_calendar_connections(id) ON DELETE CASCADE,
+    task_id          UUID NOT NULL,
+    event_id         TEXT NOT NULL,
+    due              TIMESTAMPTZ NOT NULL,   -- as of the last sync
+    task_updated_at  TIMESTAMPTZ NOT NULL,   -- as of the last sync
+    PRIMARY KEY (connection_id, task_id)
+);
@@ -0,0 +1,12 @@
+-- An org's Slack workspace: where task notifications are posted, and which
+-- workspace's /yata commands act in the org. A kind of notification is on
+-- while its channel is set.
+CREATE TABLE slack_integrations (
+    org_id                 TEXT PRIMARY KEY,
+    team_id                TEXT UNIQUE,       -- Slack workspace id
+    bot_token              TEXT,
+    webhook_url            TEXT,
+    task_assigned_channel  TEXT,
+    due_soon_channel       TEXT,
+    updated_at             TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
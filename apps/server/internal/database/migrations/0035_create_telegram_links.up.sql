@@ -0,0 +1,23 @@
+-- A user's Telegram chat with the bot: due-date reminders are sent there,
+-- and messages to the bot become tasks in the scope the user linked from.
+-- There's one chat per user and one user per chat.
+CREATE TABLE telegram_links (
+    user_id     TEXT PRIMARY KEY,         -- Clerk user id
+    org_id      TEXT,
+    chat_id     BIGINT NOT NULL UNIQUE,
+    username    TEXT,                     -- Telegram @username, if any
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Codes the user sends the bot (through a t.me deep link) to prove which
+-- account a chat belongs to. Only the code's hash is kept.
+CREATE TABLE telegram_link_codes (
+    code_hash   BYTEA PRIMARY KEY,
+    user_id     TEXT NOT NULL,
+    org_id      TEXT,
+    expires_at  TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_telegram_link_codes_user ON telegram_link_codes(user_id);
+
+ALTER TABLE user_settings ADD COLUMN IF NOT EXISTS telegram_enabled BOOLEAN NOT NULL DEFAULT TRUE;
@@ -0,0 +1,15 @@
+-- Inbound addresses turn forwarded email into tasks. Each user has at most
+-- one per scope and one per project; the random local part is what the
+-- mail provider's webhook is matched on, so rotating it retires the old
+-- address.
+CREATE TABLE inbound_addresses (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id     TEXT NOT NULL,          -- Clerk user id
+    org_id      TEXT,
+    project_id  UUID REFERENCES projects(id) ON DELETE CASCADE,
+    token       TEXT NOT NULL UNIQUE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_inbound_addresses_target
+    ON inbound_addresses(user_id, COALESCE(org_id, ''), COALESCE(project_id::text, ''));
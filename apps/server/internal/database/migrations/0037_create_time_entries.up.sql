@@ -0,0 +1,17 @@
+-- Time entries are the time users log against tasks, by timer or by hand.
+-- A running timer has no ended_at; each user has at most one.
+CREATE TABLE time_entries (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id     TEXT NOT NULL,          -- Clerk user id
+    started_at  TIMESTAMPTZ NOT NULL,
+    ended_at    TIMESTAMPTZ,
+    note        TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX idx_time_entries_task ON time_entries(task_id, started_at);
+CREATE INDEX idx_time_entries_started ON time_entries(started_at, id);
+CREATE UNIQUE INDEX idx_time_entries_running ON time_entries(user_id) WHERE ended_at IS NULL;
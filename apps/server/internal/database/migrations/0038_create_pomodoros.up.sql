@@ -0,0 +1,16 @@
+-- Pomodoros are a user's focus sessions in a scope. A session runs until
+-- it's completed or interrupted; each user runs at most one at a time.
+CREATE TABLE pomodoros (
+    id               UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id          TEXT NOT NULL,          -- Clerk user id
+    org_id           TEXT,
+    task_id          UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    status           TEXT NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'interrupted')),
+    planned_seconds  INTEGER NOT NULL CHECK (planned_seconds > 0),
+    started_at       TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    ended_at         TIMESTAMPTZ,
+    CHECK ((status = 'running') = (ended_at IS NULL))
+);
+
+CREATE INDEX idx_pomodoros_user ON pomodoros(user_id, started_at);
+CREATE UNIQUE INDEX idx_pomodoros_running ON pomodoros(user_id) WHERE status = 'running';
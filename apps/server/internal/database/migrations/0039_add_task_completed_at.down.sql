@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS tasks_completed_at ON tasks;
+DROP FUNCTION IF EXISTS set_task_completed_at();
+DROP FUNCTION IF EXISTS task_status_done(TEXT, TEXT);
+ALTER TABLE tasks DROP COLUMN IF EXISTS completed_at;
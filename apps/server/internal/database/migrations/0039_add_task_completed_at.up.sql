@@ -0,0 +1,38 @@
+-- completed_at is when a task last moved into a status its workflow counts
+-- as done, and is cleared when it moves out. A trigger keeps it in step so
+-- every path that writes the status, sync pushes included, agrees.
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMPTZ;
+
+-- task_status_done reports whether status is done in the org's workflow,
+-- or in the built-in one for personal tasks and orgs that kept it.
+CREATE FUNCTION task_status_done(task_org_id TEXT, task_status TEXT) RETURNS BOOLEAN AS $$
+    SELECT COALESCE(
+        (SELECT (s->>'done')::boolean
+         FROM org_workflows w, jsonb_array_elements(w.statuses) s
+         WHERE w.org_id = task_org_id AND s->>'key' = task_status
+         LIMIT 1),
+        task_status = 'done')
+$$ LANGUAGE SQL STABLE;
+
+-- Tasks that were already done get the best guess there is.
+UPDATE tasks SET completed_at = updated_at WHERE task_status_done(org_id, status);
+
+CREATE FUNCTION set_task_completed_at() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.status = OLD.status THEN
+        RETURN NEW;
+    END IF;
+    IF task_status_done(NEW.org_id, NEW.status) THEN
+        NEW.completed_at := COALESCE(NEW.completed_at, NOW());
+    ELSE
+        NEW.completed_at := NULL;
+    END IF;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_completed_at
+    BEFORE INSERT OR UPDATE OF status ON tasks
+    FOR EACH ROW EXECUTE FUNCTION set_task_completed_at();
+
+CREATE INDEX idx_tasks_completed ON tasks(org_id, completed_at) WHERE completed_at IS NOT NULL;
@@ -0,0 +1,11 @@
+-- Feature flags let endpoints ship dark. A flag is set globally, for an org
+-- or for a user; global rows have an empty target_id so the key stays unique.
+CREATE TABLE feature_flags (
+    key         TEXT NOT NULL,
+    scope       TEXT NOT NULL CHECK (scope IN ('global', 'org', 'user')),
+    target_id   TEXT NOT NULL DEFAULT '',   -- Clerk org or user id
+    enabled     BOOLEAN NOT NULL,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (key, scope, target_id),
+    CHECK ((scope = 'global') = (target_id = ''))
+);
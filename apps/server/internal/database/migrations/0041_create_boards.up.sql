@@ -0,0 +1,32 @@
+-- Boards lay a project's tasks out in columns. Each column shows the tasks
+-- in one workflow status, and board_cards orders them within it; tasks
+-- without a card go after the ordered ones.
+CREATE TABLE boards (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT NOT NULL,          -- Clerk org id
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    created_by  TEXT NOT NULL,          -- Clerk user id
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_boards_project ON boards(project_id);
+
+CREATE TABLE board_columns (
+    id        UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    board_id  UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    name      TEXT NOT NULL,
+    status    TEXT NOT NULL,
+    position  INTEGER NOT NULL,
+    UNIQUE (board_id, status)
+);
+
+CREATE TABLE board_cards (
+    board_id  UUID NOT NULL REFERENCES boards(id) ON DELETE CASCADE,
+    task_id   UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    PRIMARY KEY (board_id, task_id)
+);
+
+CREATE INDEX idx_board_cards_task ON board_cards(task_id);
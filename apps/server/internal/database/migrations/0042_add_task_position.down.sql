@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_tasks_personal_position;
+DROP INDEX IF EXISTS idx_tasks_org_position;
+ALTER TABLE tasks DROP COLUMN IF EXISTS position;
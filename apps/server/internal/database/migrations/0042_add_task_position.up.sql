@@ -0,0 +1,18 @@
+-- position is a task's place in the manual order of its org, or of its
+-- owner's personal tasks. Keys come from the ordering package and compare
+-- byte-wise, hence the C collation; moving a task only rewrites its own key.
+ALTER TABLE tasks ADD COLUMN position TEXT COLLATE "C";
+
+-- Existing tasks keep their creation order. Eight decimal digits behind an
+-- 'h' make valid keys for up to a hundred million tasks per list.
+UPDATE tasks t SET position = 'h' || lpad(o.n::text, 8, '0')
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY COALESCE(org_id, 'user:' || owner_id) ORDER BY created_at, id) AS n
+    FROM tasks
+) o
+WHERE o.id = t.id;
+
+ALTER TABLE tasks ALTER COLUMN position SET NOT NULL;
+
+CREATE INDEX idx_tasks_org_position ON tasks(org_id, position) WHERE org_id IS NOT NULL;
+CREATE INDEX idx_tasks_personal_position ON tasks(owner_id, position) WHERE org_id IS NULL;
@@ -0,0 +1,18 @@
+-- Views are saved task filters: a query in the view language and a sort,
+-- kept by name. An org view can be shared, making it visible to everyone in
+-- the org; personal views (org_id NULL) are only ever their owner's.
+CREATE TABLE views (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id    TEXT NOT NULL,          -- Clerk user id
+    org_id      TEXT,
+    name        TEXT NOT NULL,
+    query       TEXT NOT NULL DEFAULT '',
+    sort        TEXT NOT NULL DEFAULT '',
+    shared      BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (org_id IS NOT NULL OR NOT shared)
+);
+
+CREATE INDEX idx_views_owner ON views(owner_id, org_id);
+CREATE INDEX idx_views_shared ON views(org_id) WHERE shared;
@@ -0,0 +1,16 @@
+-- Task templates are reusable task trees. The tree is kept whole as JSON
+-- (see models.TemplateTask) since it is only ever read and written at
+-- once. Org templates are visible to the whole org; personal ones (org_id
+-- NULL) only to their owner.
+CREATE TABLE task_templates (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    owner_id    TEXT NOT NULL,          -- Clerk user id
+    org_id      TEXT,
+    name        TEXT NOT NULL,
+    task        JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_task_templates_org ON task_templates(org_id);
+CREATE INDEX idx_task_templates_owner ON task_templates(owner_id) WHERE org_id IS NULL;
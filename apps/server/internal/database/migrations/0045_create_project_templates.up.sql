@@ -0,0 +1,14 @@
+-- Project templates are saved projects: a flat list of tasks as JSON (see
+-- models.ProjectTemplateTask), whose subtasks and dependencies point at
+-- each other by index. They belong to an org.
+CREATE TABLE project_templates (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    created_by  TEXT NOT NULL,          -- Clerk user id
+    tasks       JSONB NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_project_templates_org ON project_templates(org_id);
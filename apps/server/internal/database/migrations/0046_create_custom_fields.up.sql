@@ -0,0 +1,19 @@
+-- Custom fields are typed task attributes an org defines for itself. Each
+-- task keeps its values in one JSON object keyed by the field's key, which
+-- never changes once the field exists.
+CREATE TABLE custom_fields (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT NOT NULL,          -- Clerk org id
+    key         TEXT NOT NULL,
+    name        TEXT NOT NULL,
+    type        TEXT NOT NULL CHECK (type IN ('text', 'number', 'select', 'date', 'user')),
+    options     TEXT[] NOT NULL DEFAULT '{}', -- choices of a select field
+    created_by  TEXT NOT NULL,          -- Clerk user id
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (org_id, key)
+);
+
+ALTER TABLE tasks ADD COLUMN custom_fields JSONB NOT NULL DEFAULT '{}';
+
+CREATE INDEX idx_tasks_custom_fields ON tasks USING GIN (custom_fields jsonb_path_ops);
@@ -0,0 +1,3 @@
+-- The language a user reads emails, digests and API errors in. NULL follows
+-- the request's Accept-Language.
+ALTER TABLE user_settings ADD COLUMN locale TEXT;
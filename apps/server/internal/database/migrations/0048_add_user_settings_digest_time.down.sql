@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_user_settings_digest;
+ALTER TABLE user_settings DROP COLUMN IF EXISTS digest_sent_at, DROP COLUMN IF EXISTS digest_time;
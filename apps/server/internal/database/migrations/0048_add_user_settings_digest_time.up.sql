@@ -0,0 +1,8 @@
+-- When digests go out, as HH:MM in the user's timezone, and when the last
+-- one was sent, so instances can tell whether the current one still has to
+-- go out.
+ALTER TABLE user_settings
+    ADD COLUMN digest_time TEXT NOT NULL DEFAULT '08:00',
+    ADD COLUMN digest_sent_at TIMESTAMPTZ;
+
+CREATE INDEX idx_user_settings_digest ON user_settings (user_id) WHERE digest_frequency <> 'off';
@@ -0,0 +1,3 @@
+-- A snoozed task is left out of today's lists, and its reminders wait,
+-- until snoozed_until.
+ALTER TABLE tasks ADD COLUMN snoozed_until TIMESTAMPTZ;
@@ -0,0 +1,31 @@
+-- Webhook endpoints are URLs an org has asked to be POSTed its events,
+-- signed with the endpoint's secret.
+CREATE TABLE webhook_endpoints (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    org_id      TEXT NOT NULL,          -- Clerk org id
+    url         TEXT NOT NULL,
+    secret      TEXT NOT NULL,
+    events      TEXT[] NOT NULL,
+    active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by  TEXT NOT NULL,          -- Clerk user id
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_endpoints_org ON webhook_endpoints (org_id);
+
+-- One row per delivery attempt, for orgs debugging their endpoints.
+CREATE TABLE webhook_deliveries (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    endpoint_id  UUID NOT NULL REFERENCES webhook_endpoints(id) ON DELETE CASCADE,
+    event_id     UUID NOT NULL,
+    event        TEXT NOT NULL,
+    payload      JSONB NOT NULL,
+    attempt      INT NOT NULL,
+    status_code  INT,                   -- NULL when no response came back
+    error        TEXT,
+    duration_ms  INT NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_deliveries_endpoint ON webhook_deliveries (endpoint_id, created_at DESC, id DESC);
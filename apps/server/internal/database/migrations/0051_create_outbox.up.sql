@@ -0,0 +1,19 @@
+-- The outbox holds events written in the same transaction as the change
+-- they describe until the relay hands them on. Relayed rows are kept for a
+-- while to debug with, then purged.
+CREATE TABLE outbox (
+    seq         BIGSERIAL PRIMARY KEY,
+    id          UUID NOT NULL DEFAULT gen_random_uuid(),
+    event       TEXT NOT NULL,
+    org_id      TEXT,                   -- Clerk org id; NULL for personal tasks
+    actor_id    TEXT NOT NULL,          -- Clerk user id
+    task_id     UUID,
+    payload     JSONB NOT NULL,
+    attempts    INT NOT NULL DEFAULT 0,
+    last_error  TEXT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    relayed_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbox_pending ON outbox (seq) WHERE relayed_at IS NULL;
+CREATE INDEX idx_outbox_relayed ON outbox (relayed_at) WHERE relayed_at IS NOT NULL;
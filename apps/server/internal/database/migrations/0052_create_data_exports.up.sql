@@ -0,0 +1,17 @@
+-- Data exports are archives of everything kept about a user, built by the
+-- job worker and put in object storage under key. A user has at most one
+-- export being built at a time.
+CREATE TABLE data_exports (
+    id           UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id      TEXT NOT NULL,          -- Clerk user id
+    status       TEXT NOT NULL DEFAULT 'pending',  -- pending | running | done | failed
+    key          TEXT,
+    size         BIGINT,
+    error        TEXT,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at  TIMESTAMPTZ,
+    expires_at   TIMESTAMPTZ
+);
+
+CREATE INDEX idx_data_exports_user ON data_exports(user_id, created_at);
+CREATE UNIQUE INDEX idx_data_exports_in_flight ON data_exports(user_id) WHERE status IN ('pending', 'running');
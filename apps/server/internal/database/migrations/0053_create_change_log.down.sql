@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS task_labels_change_log ON task_labels;
+DROP TRIGGER IF EXISTS labels_change_log ON labels;
+DROP TRIGGER IF EXISTS projects_change_log ON projects;
+DROP TRIGGER IF EXISTS tasks_change_log ON tasks;
+DROP FUNCTION IF EXISTS log_task_label_change();
+DROP FUNCTION IF EXISTS log_change();
+DROP TABLE IF EXISTS change_log;
@@ -0,0 +1,66 @@
+-- change_log gets an entry for every write to a task, project or label, so
+-- clients can ask what changed since they last synced. Triggers write it,
+-- so every path, sync pushes and purges included, is covered. An entry
+-- only names what changed; readers load it as it is now.
+CREATE TABLE change_log (
+    seq         BIGSERIAL PRIMARY KEY,
+    -- tx is the writing transaction. Readers stop at the oldest one still
+    -- running, so an entry that commits after one with a higher seq isn't
+    -- skipped.
+    tx          XID8 NOT NULL DEFAULT pg_current_xact_id(),
+    entity      TEXT NOT NULL,          -- task, project or label
+    entity_id   UUID NOT NULL,
+    org_id      TEXT,                   -- Clerk org id; NULL for personal tasks
+    owner_id    TEXT,                   -- the task's owner; NULL otherwise
+    changed_at  TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_change_log_org ON change_log (org_id, tx, seq);
+CREATE INDEX idx_change_log_personal ON change_log (owner_id, tx, seq) WHERE org_id IS NULL;
+CREATE INDEX idx_change_log_changed_at ON change_log (changed_at);
+
+-- log_change takes the entity as its argument; owner_id is only read for
+-- tasks, through jsonb since projects and labels don't have one.
+CREATE FUNCTION log_change() RETURNS TRIGGER AS $$
+DECLARE
+    r RECORD;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        r := OLD;
+    ELSE
+        r := NEW;
+    END IF;
+    INSERT INTO change_log (entity, entity_id, org_id, owner_id)
+    VALUES (TG_ARGV[0], r.id, r.org_id, to_jsonb(r) ->> 'owner_id');
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- Attaching or detaching a label changes the task.
+CREATE FUNCTION log_task_label_change() RETURNS TRIGGER AS $$
+DECLARE
+    changed_task_id UUID;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_task_id := OLD.task_id;
+    ELSE
+        changed_task_id := NEW.task_id;
+    END IF;
+    INSERT INTO change_log (entity, entity_id, org_id, owner_id)
+    SELECT 'task', t.id, t.org_id, t.owner_id FROM tasks t WHERE t.id = changed_task_id;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_change_log
+    AFTER INSERT OR UPDATE OR DELETE ON tasks
+    FOR EACH ROW EXECUTE FUNCTION log_change('task');
+CREATE TRIGGER projects_change_log
+    AFTER INSERT OR UPDATE OR DELETE ON projects
+    FOR EACH ROW EXECUTE FUNCTION log_change('project');
+CREATE TRIGGER labels_change_log
+    AFTER INSERT OR UPDATE OR DELETE ON labels
+    FOR EACH ROW EXECUTE FUNCTION log_change('label');
+CREATE TRIGGER task_labels_change_log
+    AFTER INSERT OR DELETE ON task_labels
+    FOR EACH ROW EXECUTE FUNCTION log_task_label_change();
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_activity_org_actor;
+DROP INDEX IF EXISTS idx_task_labels_label_recent;
+DROP INDEX IF EXISTS idx_users_search_trgm;
+DROP INDEX IF EXISTS idx_projects_name_trgm;
+DROP INDEX IF EXISTS idx_labels_name_trgm;
@@ -0,0 +1,14 @@
+-- Trigram indexes for the suggest endpoint's prefix matches, which look for
+-- the query at the start of any word of a name with ILIKE.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_labels_name_trgm ON labels USING gin (name gin_trgm_ops);
+CREATE INDEX idx_projects_name_trgm ON projects USING gin (name gin_trgm_ops) WHERE deleted_at IS NULL;
+-- Must stay the same expression as memberSearchText in the repository.
+CREATE INDEX idx_users_search_trgm ON users USING gin ((
+    coalesce(first_name, '') || ' ' || coalesce(last_name, '') || ' ' || coalesce(username, '') || ' ' || coalesce(email, '')
+) gin_trgm_ops) WHERE deleted_at IS NULL;
+
+-- Recency: when a label was last attached and a member last did anything.
+CREATE INDEX idx_task_labels_label_recent ON task_labels(label_id, created_at DESC);
+CREATE INDEX idx_activity_org_actor ON activity(org_id, actor_id, created_at DESC) WHERE org_id IS NOT NULL;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_tasks_title_trgm;
@@ -0,0 +1,3 @@
+-- For duplicate detection, which compares a new task's title with those of
+-- the open tasks in its project by trigram similarity.
+CREATE INDEX idx_tasks_title_trgm ON tasks USING gin (title gin_trgm_ops) WHERE deleted_at IS NULL;
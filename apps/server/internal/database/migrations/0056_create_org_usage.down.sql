@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS webhook_endpoints_org_usage ON webhook_endpoints;
+DROP TRIGGER IF EXISTS attachments_org_usage ON attachments;
+DROP TRIGGER IF EXISTS attachments_org ON attachments;
+DROP TRIGGER IF EXISTS tasks_org_usage ON tasks;
+DROP FUNCTION IF EXISTS count_webhook_usage();
+DROP FUNCTION IF EXISTS count_attachment_usage();
+DROP FUNCTION IF EXISTS set_attachment_org();
+DROP FUNCTION IF EXISTS count_task_usage();
+DROP FUNCTION IF EXISTS add_org_usage(TEXT, BIGINT, BIGINT, BIGINT);
+ALTER TABLE attachments DROP COLUMN IF EXISTS org_id;
+DROP TABLE IF EXISTS org_usage;
@@ -0,0 +1,95 @@
+-- org_usage counts what each org holds against its quotas. Triggers keep
+-- it, in the same transaction as the write, so it can't drift from the rows
+-- it counts whichever path wrote them. Trashed tasks stop counting.
+CREATE TABLE org_usage (
+    org_id             TEXT PRIMARY KEY,    -- Clerk org id
+    tasks              BIGINT NOT NULL DEFAULT 0,
+    attachment_bytes   BIGINT NOT NULL DEFAULT 0,
+    webhook_endpoints  BIGINT NOT NULL DEFAULT 0
+);
+
+-- Attachments only name their task, which is gone by the time a cascade
+-- deletes them, so they keep its org.
+ALTER TABLE attachments ADD COLUMN org_id TEXT;
+UPDATE attachments a SET org_id = t.org_id FROM tasks t WHERE t.id = a.task_id;
+
+CREATE FUNCTION add_org_usage(org TEXT, task_delta BIGINT, byte_delta BIGINT, webhook_delta BIGINT) RETURNS VOID AS $$
+BEGIN
+    IF org IS NULL OR (task_delta = 0 AND byte_delta = 0 AND webhook_delta = 0) THEN
+        RETURN;
+    END IF;
+    INSERT INTO org_usage (org_id, tasks, attachment_bytes, webhook_endpoints)
+    VALUES (org, task_delta, byte_delta, webhook_delta)
+    ON CONFLICT (org_id) DO UPDATE SET
+        tasks = org_usage.tasks + EXCLUDED.tasks,
+        attachment_bytes = org_usage.attachment_bytes + EXCLUDED.attachment_bytes,
+        webhook_endpoints = org_usage.webhook_endpoints + EXCLUDED.webhook_endpoints;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION count_task_usage() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') AND OLD.deleted_at IS NULL THEN
+        PERFORM add_org_usage(OLD.org_id, -1, 0, 0);
+    END IF;
+    IF TG_OP IN ('INSERT', 'UPDATE') AND NEW.deleted_at IS NULL THEN
+        PERFORM add_org_usage(NEW.org_id, 1, 0, 0);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION set_attachment_org() RETURNS TRIGGER AS $$
+BEGIN
+    SELECT org_id INTO NEW.org_id FROM tasks WHERE id = NEW.task_id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION count_attachment_usage() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM add_org_usage(OLD.org_id, 0, -OLD.size, 0);
+    ELSE
+        PERFORM add_org_usage(NEW.org_id, 0, NEW.size, 0);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION count_webhook_usage() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        PERFORM add_org_usage(OLD.org_id, 0, 0, -1);
+    ELSE
+        PERFORM add_org_usage(NEW.org_id, 0, 0, 1);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+-- Only a change of trash state or org moves the task count.
+CREATE TRIGGER tasks_org_usage
+    AFTER INSERT OR DELETE OR UPDATE OF deleted_at, org_id ON tasks
+    FOR EACH ROW EXECUTE FUNCTION count_task_usage();
+CREATE TRIGGER attachments_org
+    BEFORE INSERT ON attachments
+    FOR EACH ROW EXECUTE FUNCTION set_attachment_org();
+CREATE TRIGGER attachments_org_usage
+    AFTER INSERT OR DELETE ON attachments
+    FOR EACH ROW EXECUTE FUNCTION count_attachment_usage();
+CREATE TRIGGER webhook_endpoints_org_usage
+    AFTER INSERT OR DELETE ON webhook_endpoints
+    FOR EACH ROW EXECUTE FUNCTION count_webhook_usage();
+
+INSERT INTO org_usage (org_id, tasks, attachment_bytes, webhook_endpoints)
+SELECT org_id, sum(tasks), sum(attachment_bytes), sum(webhook_endpoints)
+FROM (
+    SELECT org_id, count(*) AS tasks, 0 AS attachment_bytes, 0 AS webhook_endpoints
+    FROM tasks WHERE org_id IS NOT NULL AND deleted_at IS NULL GROUP BY org_id
+    UNION ALL
+    SELECT org_id, 0, sum(size), 0 FROM attachments WHERE org_id IS NOT NULL GROUP BY org_id
+    UNION ALL
+    SELECT org_id, 0, 0, count(*) FROM webhook_endpoints GROUP BY org_id
+) counts
+GROUP BY org_id;
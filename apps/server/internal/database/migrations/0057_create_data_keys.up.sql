@@ -0,0 +1,7 @@
+-- data_keys are the keys encrypted fields are sealed with, one per org, or
+-- per user for their personal tasks, each wrapped by the master key.
+CREATE TABLE data_keys (
+    owner_id     TEXT PRIMARY KEY,    -- Clerk org or user id
+    wrapped_key  BYTEA NOT NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
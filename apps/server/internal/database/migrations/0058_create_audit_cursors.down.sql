@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_activity_stream;
+DROP TABLE IF EXISTS audit_cursors;
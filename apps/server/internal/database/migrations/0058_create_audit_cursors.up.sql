@@ -0,0 +1,11 @@
+-- audit_cursors is how far each audit sink has got through the org
+-- activity trail. A sink's row is made the first time it runs, pointing at
+-- that moment, so a new sink doesn't replay the whole history.
+CREATE TABLE audit_cursors (
+    sink          TEXT PRIMARY KEY,    -- the sink's URL, without credentials
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    activity_id   UUID NOT NULL DEFAULT '00000000-0000-0000-0000-000000000000',
+    delivered_at  TIMESTAMPTZ
+);
+
+CREATE INDEX idx_activity_stream ON activity(created_at, id) WHERE org_id IS NOT NULL;
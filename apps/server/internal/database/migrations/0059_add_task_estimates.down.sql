@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_open_by_owner;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimate_minutes;
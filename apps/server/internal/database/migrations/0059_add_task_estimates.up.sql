@@ -0,0 +1,4 @@
+ALTER TABLE tasks ADD COLUMN estimate_minutes INTEGER CHECK (estimate_minutes >= 0);
+
+-- Workload sums up each member's open tasks by due date.
+CREATE INDEX idx_tasks_open_by_owner ON tasks(org_id, owner_id, due_date) WHERE completed_at IS NULL AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+DELETE FROM idempotency_keys WHERE org_id <> '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (user_id, key);
+ALTER TABLE idempotency_keys DROP COLUMN IF EXISTS org_id;
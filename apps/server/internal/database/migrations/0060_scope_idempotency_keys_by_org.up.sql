@@ -0,0 +1,4 @@
+-- The same Idempotency-Key sent from two orgs is two different requests.
+ALTER TABLE idempotency_keys ADD COLUMN org_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE idempotency_keys DROP CONSTRAINT idempotency_keys_pkey;
+ALTER TABLE idempotency_keys ADD PRIMARY KEY (user_id, org_id, key);
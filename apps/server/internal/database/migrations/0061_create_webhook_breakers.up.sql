@@ -0,0 +1,14 @@
+-- Circuit breakers on each org's deliveries to a URL, kept only while
+-- deliveries to it are failing. open_until is NULL while the breaker is
+-- closed; once it has passed, the breaker is half open and one delivery
+-- probes the URL, with probing set meanwhile.
+CREATE TABLE webhook_breakers (
+    org_id      TEXT NOT NULL,          -- Clerk org id
+    url         TEXT NOT NULL,
+    failures    INT NOT NULL DEFAULT 0,
+    trips       INT NOT NULL DEFAULT 0,
+    open_until  TIMESTAMPTZ,
+    probing     BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (org_id, url)
+);
@@ -0,0 +1 @@
+ALTER TABLE org_settings DROP COLUMN IF EXISTS rate_limit;
@@ -0,0 +1,3 @@
+-- A "limit/window" rule for the org's API requests, set by the operator.
+-- NULL keeps the server's RATE_LIMITS.
+ALTER TABLE org_settings ADD COLUMN rate_limit TEXT;
@@ -0,0 +1,2 @@
+-- The sweeper looks for uploads that were never confirmed.
+CREATE INDEX idx_attachments_pending ON attachments(created_at) WHERE confirmed_at IS NULL;
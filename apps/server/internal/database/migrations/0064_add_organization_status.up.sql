@@ -0,0 +1,4 @@
+-- Suspended orgs can read and export their data but not change it. Clerk
+-- sets it through the org's public metadata, or an operator through
+-- /admin/orgs/:orgId/status.
+ALTER TABLE organizations ADD COLUMN status TEXT NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'suspended'));
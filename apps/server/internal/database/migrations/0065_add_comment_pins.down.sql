@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_comments_pinned;
+ALTER TABLE comments DROP COLUMN IF EXISTS pinned_by;
+ALTER TABLE comments DROP COLUMN IF EXISTS pinned_at;
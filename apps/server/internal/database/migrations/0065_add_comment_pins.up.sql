@@ -0,0 +1,6 @@
+-- Pinned comments are listed ahead of the thread, for everyone who can see
+-- the task.
+ALTER TABLE comments ADD COLUMN pinned_at TIMESTAMPTZ;
+ALTER TABLE comments ADD COLUMN pinned_by TEXT; -- Clerk user id
+
+CREATE INDEX idx_comments_pinned ON comments(task_id, pinned_at) WHERE pinned_at IS NOT NULL;
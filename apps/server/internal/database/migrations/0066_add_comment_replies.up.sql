@@ -0,0 +1,5 @@
+-- Replies are one level deep: parent_id is always a top-level comment.
+-- Deleting it leaves its replies in the thread as top-level comments.
+ALTER TABLE comments ADD COLUMN parent_id UUID REFERENCES comments(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_comments_parent ON comments(parent_id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_sync_ops_owner_op;
+ALTER TABLE sync_ops ADD CONSTRAINT sync_ops_id_key UNIQUE (id);
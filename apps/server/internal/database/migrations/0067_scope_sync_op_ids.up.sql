@@ -0,0 +1,4 @@
+-- Op ids are generated by clients, so they're only unique per user: one
+-- user's retry can't be mistaken for, or blocked by, another's op.
+ALTER TABLE sync_ops DROP CONSTRAINT IF EXISTS sync_ops_id_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sync_ops_owner_op ON sync_ops (owner_id, id);
@@ -2,26 +2,46 @@ package database
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
-func Connect(connString string) (*pgxpool.Pool, error) {
-	ctx := context.Background()
+// Option adjusts the parsed pool config before the pool is created.
+type Option func(*pgxpool.Config)
 
+func ParseConfig(connString string, opts ...Option) (*pgxpool.Config, error) {
 	cfg, err := pgxpool.ParseConfig(connString)
 	if err != nil {
-		log.Println("Failed to load config", err)
+		return nil, err
+	}
+
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	return cfg, nil
+}
+
+func Connect(connString string, opts ...Option) (*pgxpool.Pool, error) {
+	ctx := context.Background()
+
+	cfg, err := ParseConfig(connString, opts...)
+	if err != nil {
+		slog.Error("Failed to load config", "error", err)
 		return nil, err
 	}
 
 	pool, err := pgxpool.NewWithConfig(ctx, cfg)
+	if err != nil {
+		slog.Error("Failed to create the pool", "error", err)
+		return nil, err
+	}
 
 	err = pool.Ping(ctx)
 
 	if err != nil {
-		log.Println("Failed to connect to the database", err)
+		slog.Error("Failed to connect to the database", "error", err)
 		pool.Close()
 		return nil, err
 	}
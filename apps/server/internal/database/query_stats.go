@@ -0,0 +1,124 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type QueryStat struct {
+	Name     string        `json:"name"`
+	Count    int           `json:"count"`
+	Duration time.Duration `json:"-"`
+}
+
+// QueryStats accumulates the queries run on behalf of a single request.
+type QueryStats struct {
+	mu      sync.Mutex
+	total   time.Duration
+	queries map[string]*QueryStat
+	order   []string
+}
+
+func NewQueryStats() *QueryStats {
+	return &QueryStats{queries: map[string]*QueryStat{}}
+}
+
+func (s *QueryStats) add(name string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.total += d
+	stat, ok := s.queries[name]
+	if !ok {
+		stat = &QueryStat{Name: name}
+		s.queries[name] = stat
+		s.order = append(s.order, name)
+	}
+	stat.Count++
+	stat.Duration += d
+}
+
+func (s *QueryStats) Total() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+// Breakdown returns one entry per distinct query in the order first seen.
+func (s *QueryStats) Breakdown() []QueryStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := make([]QueryStat, 0, len(s.order))
+	for _, name := range s.order {
+		out = append(out, *s.queries[name])
+	}
+	return out
+}
+
+type queryStatsKey struct{}
+
+func ContextWithQueryStats(ctx context.Context, stats *QueryStats) context.Context {
+	return context.WithValue(ctx, queryStatsKey{}, stats)
+}
+
+func QueryStatsFromContext(ctx context.Context) (*QueryStats, bool) {
+	stats, ok := ctx.Value(queryStatsKey{}).(*QueryStats)
+	return stats, ok
+}
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	name string
+	at   time.Time
+}
+
+type statsTracer struct{}
+
+func (statsTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	if _, ok := QueryStatsFromContext(ctx); !ok {
+		return ctx
+	}
+	return context.WithValue(ctx, queryStartKey{}, queryStart{name: QueryName(data.SQL), at: time.Now()})
+}
+
+func (statsTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	stats, ok := QueryStatsFromContext(ctx)
+	if !ok {
+		return
+	}
+	if start, ok := ctx.Value(queryStartKey{}).(queryStart); ok {
+		stats.add(start.name, time.Since(start.at))
+	}
+}
+
+// WithQueryStats records query timings into any QueryStats found on the query context.
+func WithQueryStats() Option {
+	return func(cfg *pgxpool.Config) {
+		addTracer(cfg, statsTracer{})
+	}
+}
+
+// QueryName identifies a query without its arguments: the "-- name: X"
+// annotation if there is one, otherwise the collapsed start of the SQL.
+func QueryName(sql string) string {
+	sql = strings.TrimSpace(sql)
+	if rest, ok := strings.CutPrefix(sql, "-- name:"); ok {
+		line, _, _ := strings.Cut(rest, "\n")
+		if fields := strings.Fields(line); len(fields) > 0 {
+			return fields[0]
+		}
+	}
+
+	name := strings.Join(strings.Fields(sql), " ")
+	if len(name) > 80 {
+		name = name[:80]
+	}
+	return name
+}
@@ -0,0 +1,217 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// replicaCheckTimeout keeps a hung replica from stalling the other checks.
+const replicaCheckTimeout = 5 * time.Second
+
+// Replay lag in seconds; zero when the replica has replayed all it received,
+// since pg_last_xact_replay_timestamp only moves when there are writes.
+const replicaLagQuery = `SELECT CASE
+	WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+	ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+END::float8`
+
+// Replicas spreads reads that can tolerate some staleness across streaming
+// replicas of the primary, round-robin. A replica that can't be reached or
+// is more than MaxLag behind is skipped until a check finds it caught up;
+// with none healthy, reads go to the primary.
+type Replicas struct {
+	MaxLag time.Duration
+
+	primary  *pgxpool.Pool
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+type replica struct {
+	name    string
+	pool    *pgxpool.Pool
+	healthy atomic.Bool
+}
+
+// Target is one pool reads can go to, named for the pool metrics.
+type Target struct {
+	Name string
+	Pool *pgxpool.Pool
+}
+
+// NewReplicas reads from primary until replicas are added. A zero maxLag
+// only takes unreachable replicas out of rotation.
+func NewReplicas(primary *pgxpool.Pool, maxLag time.Duration) *Replicas {
+	return &Replicas{MaxLag: maxLag, primary: primary}
+}
+
+// Add opens a pool to a replica and checks it once. Unlike Connect, a
+// replica that's down doesn't fail startup; it's just left out until it's up.
+func (r *Replicas) Add(connString string, opts ...Option) error {
+	cfg, err := ParseConfig(connString, opts...)
+	if err != nil {
+		return err
+	}
+	pool, err := pgxpool.NewWithConfig(context.Background(), cfg)
+	if err != nil {
+		return err
+	}
+
+	rep := &replica{name: fmt.Sprintf("replica-%d", len(r.replicas)+1), pool: pool}
+	if err := r.check(context.Background(), rep); err != nil {
+		slog.Warn("Replica unavailable at startup", "replica", rep.name, "error", err)
+	}
+	r.replicas = append(r.replicas, rep)
+	return nil
+}
+
+// Start re-checks every replica each interval until ctx is done.
+func (r *Replicas) Start(ctx context.Context, interval time.Duration) {
+	if len(r.replicas) == 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for _, rep := range r.replicas {
+					r.check(ctx, rep)
+				}
+			}
+		}
+	}()
+}
+
+// check measures the replica's lag and puts it in or out of rotation,
+// logging when that changes.
+func (r *Replicas) check(ctx context.Context, rep *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	var seconds float64
+	err := rep.pool.QueryRow(ctx, replicaLagQuery).Scan(&seconds)
+	lag := time.Duration(seconds * float64(time.Second))
+	if err == nil && r.MaxLag > 0 && lag > r.MaxLag {
+		err = fmt.Errorf("replication lag %s is over %s", lag.Round(time.Millisecond), r.MaxLag)
+	}
+
+	healthy := err == nil
+	if was := rep.healthy.Swap(healthy); was != healthy {
+		if healthy {
+			slog.Info("Replica back in rotation", "replica", rep.name, "lag", lag)
+		} else {
+			slog.Warn("Replica out of rotation", "replica", rep.name, "error", err)
+		}
+	}
+	return err
+}
+
+// pick is the next healthy replica, or nil to use the primary.
+func (r *Replicas) pick() *replica {
+	n := uint64(len(r.replicas))
+	if n == 0 {
+		return nil
+	}
+	start := r.next.Add(1)
+	for i := range n {
+		if rep := r.replicas[(start+i)%n]; rep.healthy.Load() {
+			return rep
+		}
+	}
+	return nil
+}
+
+// failed reports whether err means the replica itself is out of action, so
+// the query should go to the primary instead, and takes it out of rotation
+// until the next check if so.
+func (r *Replicas) failed(ctx context.Context, rep *replica, err error) bool {
+	if err == nil || ctx.Err() != nil || !unavailable(err) {
+		return false
+	}
+	if rep.healthy.Swap(false) {
+		slog.WarnContext(ctx, "Replica out of rotation", "replica", rep.name, "error", err)
+	}
+	return true
+}
+
+// unavailable is for errors the same query wouldn't get from the primary:
+// connection failures, shutdowns, and queries cancelled by a conflict with
+// recovery, which is a hot standby's 40001.
+func unavailable(err error) bool {
+	var connectErr *pgconn.ConnectError
+	if errors.As(err, &connectErr) {
+		return true
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || strings.HasPrefix(pgErr.Code, "57P") || pgErr.Code == "40001"
+	}
+	return pgconn.SafeToRetry(err)
+}
+
+func (r *Replicas) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	if rep := r.pick(); rep != nil {
+		rows, err := rep.pool.Query(ctx, sql, args...)
+		if !r.failed(ctx, rep, err) {
+			return rows, err
+		}
+	}
+	return r.primary.Query(ctx, sql, args...)
+}
+
+func (r *Replicas) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	rep := r.pick()
+	if rep == nil {
+		return r.primary.QueryRow(ctx, sql, args...)
+	}
+	return replicaRow{r: r, rep: rep, ctx: ctx, sql: sql, args: args}
+}
+
+// replicaRow runs the query when scanned, since a row's error only shows
+// up then, so it can still fall back to the primary.
+type replicaRow struct {
+	r    *Replicas
+	rep  *replica
+	ctx  context.Context
+	sql  string
+	args []any
+}
+
+func (row replicaRow) Scan(dest ...any) error {
+	err := row.rep.pool.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+	if !row.r.failed(row.ctx, row.rep, err) {
+		return err
+	}
+	return row.r.primary.QueryRow(row.ctx, row.sql, row.args...).Scan(dest...)
+}
+
+// Targets lists the primary and each replica, for pool metrics.
+func (r *Replicas) Targets() []Target {
+	targets := []Target{{Name: "primary", Pool: r.primary}}
+	for _, rep := range r.replicas {
+		targets = append(targets, Target{Name: rep.name, Pool: rep.pool})
+	}
+	return targets
+}
+
+// Close closes the replica pools; the primary is its owner's to close.
+func (r *Replicas) Close() {
+	for _, rep := range r.replicas {
+		rep.pool.Close()
+	}
+}
@@ -0,0 +1,30 @@
+package database
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WithStatementTimeout makes Postgres abort any statement running longer than
+// d, even if the client context never fires. Zero leaves the server default.
+func WithStatementTimeout(d time.Duration) Option {
+	return func(cfg *pgxpool.Config) {
+		if d <= 0 {
+			return
+		}
+		cfg.ConnConfig.RuntimeParams["statement_timeout"] = strconv.FormatInt(d.Milliseconds(), 10)
+	}
+}
+
+// SetLocalStatementTimeout overrides the statement timeout for the rest of tx,
+// for operations known to be slow, like migrations. Zero disables it.
+// Exports and reports don't need it: exports read a page per statement, and
+// a report that outgrows the timeout should get an index, not more time.
+func SetLocalStatementTimeout(ctx context.Context, tx pgx.Tx, d time.Duration) error {
+	_, err := tx.Exec(ctx, "SELECT set_config('statement_timeout', $1, true)", strconv.FormatInt(d.Milliseconds(), 10))
+	return err
+}
@@ -0,0 +1,68 @@
+package database_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/database/dbtest"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// queryCanceled is the SQLSTATE Postgres reports when statement_timeout fires.
+const queryCanceled = "57014"
+
+func TestWithStatementTimeout(t *testing.T) {
+	pool := dbtest.Pool(t, database.WithStatementTimeout(100*time.Millisecond))
+	ctx := context.Background()
+
+	_, err := pool.Exec(ctx, "SELECT pg_sleep(1)")
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != queryCanceled {
+		t.Fatalf("pg_sleep(1) error = %v, want the server to cancel it", err)
+	}
+
+	if _, err := pool.Exec(ctx, "SELECT pg_sleep(0.01)"); err != nil {
+		t.Errorf("query under the timeout failed: %v", err)
+	}
+}
+
+func TestSetLocalStatementTimeout(t *testing.T) {
+	pool := dbtest.Pool(t, database.WithStatementTimeout(100*time.Millisecond))
+	ctx := context.Background()
+
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := database.SetLocalStatementTimeout(ctx, tx, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(ctx, "SELECT pg_sleep(0.3)"); err != nil {
+		t.Errorf("query within the raised timeout failed: %v", err)
+	}
+}
+
+func TestParseConfigStatementTimeout(t *testing.T) {
+	tests := []struct {
+		timeout time.Duration
+		want    string
+	}{
+		{30 * time.Second, "30000"},
+		{1500 * time.Millisecond, "1500"},
+		{0, ""},
+	}
+	for _, tt := range tests {
+		cfg, err := database.ParseConfig("postgres://yata@localhost/yata", database.WithStatementTimeout(tt.timeout))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := cfg.ConnConfig.RuntimeParams["statement_timeout"]; got != tt.want {
+			t.Errorf("statement_timeout for %v = %q, want %q", tt.timeout, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// queryTracers lets more than one tracer watch the pool, since pgx only
+// takes one.
+type queryTracers []pgx.QueryTracer
+
+func (ts queryTracers) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, t := range ts {
+		ctx = t.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (ts queryTracers) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for i := len(ts) - 1; i >= 0; i-- {
+		ts[i].TraceQueryEnd(ctx, conn, data)
+	}
+}
+
+// addTracer attaches t alongside whatever tracer is already set.
+func addTracer(cfg *pgxpool.Config, t pgx.QueryTracer) {
+	switch existing := cfg.ConnConfig.Tracer.(type) {
+	case nil:
+		cfg.ConnConfig.Tracer = t
+	case queryTracers:
+		cfg.ConnConfig.Tracer = append(existing, t)
+	default:
+		cfg.ConnConfig.Tracer = queryTracers{existing, t}
+	}
+}
+
+type spanTracer struct {
+	tracer trace.Tracer
+	dbName string
+}
+
+func (t spanTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	// Queries outside of a traced request or job would each start a new
+	// trace of one span; leave them out.
+	if !trace.SpanContextFromContext(ctx).IsValid() {
+		return ctx
+	}
+	ctx, _ = t.tracer.Start(ctx, QueryName(data.SQL),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBNamespace(t.dbName),
+			semconv.DBQueryText(data.SQL),
+		),
+	)
+	return ctx
+}
+
+func (t spanTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	if data.Err != nil && !errors.Is(data.Err, pgx.ErrNoRows) {
+		span.RecordError(data.Err)
+		span.SetStatus(codes.Error, data.Err.Error())
+	}
+	span.SetAttributes(attribute.Int64("db.rows_affected", data.CommandTag.RowsAffected()))
+	span.End()
+}
+
+// WithTracing records a span for every query run under a traced context.
+// Arguments are left out of the span; only the SQL text is kept.
+func WithTracing() Option {
+	return func(cfg *pgxpool.Config) {
+		addTracer(cfg, spanTracer{
+			tracer: otel.Tracer(tracing.Name),
+			dbName: cfg.ConnConfig.Database,
+		})
+	}
+}
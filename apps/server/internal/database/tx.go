@@ -0,0 +1,34 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// txAttempts bounds how often WithTx runs fn when Postgres keeps aborting it.
+const txAttempts = 3
+
+// WithTx runs fn in a transaction, committing if it returns nil and rolling
+// back otherwise. A transaction Postgres aborts for a deadlock or a
+// serialization failure is run again, up to txAttempts times, so fn must do
+// everything through tx and set, not accumulate, what it hands back.
+func WithTx(ctx context.Context, pool *pgxpool.Pool, fn func(pgx.Tx) error) error {
+	var err error
+	for range txAttempts {
+		err = pgx.BeginFunc(ctx, pool, fn)
+		if !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// retryable is 40001 serialization_failure or 40P01 deadlock_detected.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == "40001" || pgErr.Code == "40P01")
+}
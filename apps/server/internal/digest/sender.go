@@ -0,0 +1,141 @@
+// Package digest emails users a summary of their open tasks once a day or a
+// week, at the time they picked in their own zone.
+package digest
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"yata/apps/server/internal/mailer"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+const (
+	// sectionLimit caps how many tasks each section of a digest lists.
+	sectionLimit = 20
+	// maxDelay is how late a digest can still go out. After downtime longer
+	// than that, the digest is skipped rather than sent at an odd hour.
+	maxDelay = 2 * time.Hour
+)
+
+// Sender checks every Interval whose digest is due and emails it. Digests
+// are claimed before they're sent, so a failed send is logged rather than
+// retried, and one with nothing to list isn't sent at all.
+type Sender struct {
+	Digests   store.DigestStore
+	Mailer    mailer.Mailer
+	Templates *mailer.Templates
+	Directory mailer.Directory
+	// AppURL is the web app's base URL, used to link to tasks.
+	AppURL   string
+	Interval time.Duration
+}
+
+// Start runs the sender until ctx is done.
+func (s *Sender) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run sends every digest that is currently due.
+func (s *Sender) Run(ctx context.Context) {
+	subscribers, err := s.Digests.Subscribers(ctx)
+	if err != nil {
+		slog.Error("Failed to list digest subscribers", "error", err)
+		return
+	}
+
+	now := time.Now()
+	for _, sub := range subscribers {
+		if err := s.send(ctx, sub, now); err != nil {
+			slog.Error("Failed to send digest", "user_id", sub.Settings.UserID, "error", err)
+		}
+	}
+}
+
+func (s *Sender) send(ctx context.Context, sub models.DigestSubscriber, now time.Time) error {
+	settings := sub.Settings
+	slot, ok := settings.LastDigestSlot(now)
+	if !ok || now.Sub(slot) > maxDelay || (sub.LastSentAt != nil && !sub.LastSentAt.Before(slot)) {
+		return nil
+	}
+	claimed, err := s.Digests.Claim(ctx, settings.UserID, slot, now)
+	if err != nil || !claimed {
+		return err
+	}
+
+	days := 1
+	if settings.DigestFrequency == "weekly" {
+		days = 7
+	}
+	day := time.Date(slot.Year(), slot.Month(), slot.Day(), 0, 0, 0, 0, slot.Location())
+	// Assignments count from the last digest, but no further back than one
+	// period for users who just turned digests (back) on.
+	since := slot.AddDate(0, 0, -days)
+	if sub.LastSentAt != nil && sub.LastSentAt.After(since) {
+		since = *sub.LastSentAt
+	}
+
+	digest, err := s.Digests.Digest(ctx, settings.UserID, models.DigestQuery{
+		DueFrom:       day,
+		DueTo:         day.AddDate(0, 0, days),
+		AssignedSince: since,
+		Limit:         sectionLimit,
+	})
+	if err != nil {
+		return err
+	}
+	if digest.Empty() {
+		return nil
+	}
+
+	to, err := s.Directory.Email(ctx, settings.UserID)
+	if err != nil {
+		return err
+	}
+	if to == "" {
+		slog.Warn("No email address, skipping digest", "user_id", settings.UserID)
+		return nil
+	}
+
+	loc := settings.Location()
+	msg, err := s.Templates.Render(mailer.TemplateDigest, to, mailer.TemplateData{
+		Locale:    settings.PreferredLocale(),
+		Frequency: settings.DigestFrequency,
+		Overdue:   s.items(digest.Overdue, loc),
+		Due:       s.items(digest.Due, loc),
+		Assigned:  s.items(digest.Assigned, loc),
+	})
+	if err != nil {
+		return err
+	}
+	return s.Mailer.Send(ctx, msg)
+}
+
+func (s *Sender) items(tasks []models.Task, loc *time.Location) []mailer.DigestItem {
+	items := make([]mailer.DigestItem, 0, len(tasks))
+	for _, t := range tasks {
+		item := mailer.DigestItem{Title: t.Title}
+		if t.DueDate != nil {
+			item.DueDate = t.DueDate.In(loc).Format("Mon, 02 Jan 2006 15:04")
+		}
+		if s.AppURL != "" {
+			item.URL = strings.TrimRight(s.AppURL, "/") + "/tasks/" + t.ID
+		}
+		items = append(items, item)
+	}
+	return items
+}
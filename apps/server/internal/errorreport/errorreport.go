@@ -0,0 +1,65 @@
+// Package errorreport sends panics and server errors on to an error tracker,
+// Sentry when a DSN is configured, with the request they came from.
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Report is one panic or 5xx response.
+type Report struct {
+	// Message is the panic value or the error, as text.
+	Message string
+	Panic   bool
+	// Stack is where it happened, innermost call first; only panics have one.
+	Stack []runtime.Frame
+
+	RequestID string
+	Method    string
+	Route     string
+	Status    int
+	UserID    string
+	OrgID     string
+	Time      time.Time
+}
+
+// Reporter takes reports without blocking the request they came from.
+type Reporter interface {
+	Report(ctx context.Context, r Report)
+}
+
+// Callers is the stack of its caller's caller and up, skipping skip more
+// frames, for a report made from a deferred recover.
+func Callers(skip int) []runtime.Frame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var stack []runtime.Frame
+	for {
+		frame, more := frames.Next()
+		stack = append(stack, frame)
+		if !more {
+			return stack
+		}
+	}
+}
+
+// FormatStack writes the stack the way a panic prints it.
+func FormatStack(stack []runtime.Frame) string {
+	var b strings.Builder
+	for _, f := range stack {
+		fmt.Fprintf(&b, "%s\n\t%s:%d\n", f.Function, f.File, f.Line)
+	}
+	return b.String()
+}
+
+// Nop is the Reporter when there's nowhere to send reports; the panic and
+// the 5xx response are in the log regardless.
+type Nop struct{}
+
+func (Nop) Report(context.Context, Report) {}
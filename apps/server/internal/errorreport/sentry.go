@@ -0,0 +1,240 @@
+package errorreport
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/version"
+)
+
+const (
+	// sentryQueueSize bounds the reports waiting to be sent; more are
+	// dropped rather than held up behind a slow Sentry.
+	sentryQueueSize = 100
+	// sentryDrainTimeout is how long shutdown keeps sending what's queued.
+	sentryDrainTimeout = 5 * time.Second
+)
+
+// Sentry sends reports to a Sentry project as envelopes, from a queue so
+// reporting never slows a request down.
+type Sentry struct {
+	endpoint    string
+	auth        string
+	environment string
+	serverName  string
+	client      *http.Client
+	queue       chan Report
+}
+
+// NewSentry parses dsn, https://<key>@<host>/<project id>, as shown in the
+// project's client keys settings.
+func NewSentry(dsn, environment, serverName string) (*Sentry, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, err
+	}
+	key := u.User.Username()
+	dir, projectID := path.Split(strings.TrimSuffix(u.Path, "/"))
+	if key == "" || projectID == "" || u.Host == "" {
+		return nil, errors.New("SENTRY_DSN needs a key, host and project id")
+	}
+
+	endpoint := url.URL{Scheme: u.Scheme, Host: u.Host, Path: path.Join(dir, "api", projectID, "envelope") + "/"}
+	return &Sentry{
+		endpoint:    endpoint.String(),
+		auth:        "Sentry sentry_version=7, sentry_client=yata/" + version.Get().Commit + ", sentry_key=" + key,
+		environment: environment,
+		serverName:  serverName,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan Report, sentryQueueSize),
+	}, nil
+}
+
+func (s *Sentry) Report(ctx context.Context, r Report) {
+	select {
+	case s.queue <- r:
+	default:
+		slog.WarnContext(ctx, "Error report dropped; the Sentry queue is full")
+	}
+}
+
+// Start sends queued reports until ctx is done, then for a few seconds more
+// to get out what was reported during shutdown.
+func (s *Sentry) Start(ctx context.Context) {
+	go func() {
+		for {
+			select {
+			case r := <-s.queue:
+				s.sendLogged(ctx, r)
+			case <-ctx.Done():
+				s.drain()
+				return
+			}
+		}
+	}()
+}
+
+func (s *Sentry) drain() {
+	ctx, cancel := context.WithTimeout(context.Background(), sentryDrainTimeout)
+	defer cancel()
+	for ctx.Err() == nil {
+		select {
+		case r := <-s.queue:
+			s.sendLogged(ctx, r)
+		default:
+			return
+		}
+	}
+}
+
+func (s *Sentry) sendLogged(ctx context.Context, r Report) {
+	if err := s.send(ctx, r); err != nil {
+		slog.WarnContext(ctx, "Failed to send error report to Sentry", "error", err)
+	}
+}
+
+type sentryEvent struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Message     *sentryMessage    `json:"message,omitempty"`
+	Exception   *sentryExceptions `json:"exception,omitempty"`
+	Tags        map[string]string `json:"tags"`
+	User        *sentryUser       `json:"user,omitempty"`
+}
+
+type sentryMessage struct {
+	Formatted string `json:"formatted"`
+}
+
+type sentryExceptions struct {
+	Values []sentryException `json:"values"`
+}
+
+type sentryException struct {
+	Type       string           `json:"type"`
+	Value      string           `json:"value"`
+	Stacktrace sentryStacktrace `json:"stacktrace"`
+}
+
+type sentryStacktrace struct {
+	Frames []sentryFrame `json:"frames"`
+}
+
+type sentryFrame struct {
+	Function string `json:"function"`
+	AbsPath  string `json:"abs_path"`
+	Lineno   int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type sentryUser struct {
+	ID string `json:"id"`
+}
+
+func (s *Sentry) event(r Report) (sentryEvent, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return sentryEvent{}, err
+	}
+
+	e := sentryEvent{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   r.Time.UTC(),
+		Level:       "error",
+		Platform:    "go",
+		Environment: s.environment,
+		Release:     version.Get().Commit,
+		ServerName:  s.serverName,
+		Transaction: r.Method + " " + r.Route,
+		Tags: map[string]string{
+			"method":     r.Method,
+			"route":      r.Route,
+			"status":     strconv.Itoa(r.Status),
+			"request_id": r.RequestID,
+		},
+	}
+	if r.OrgID != "" {
+		e.Tags["org_id"] = r.OrgID
+	}
+	if r.UserID != "" {
+		e.User = &sentryUser{ID: r.UserID}
+	}
+
+	if !r.Panic {
+		e.Message = &sentryMessage{Formatted: r.Message}
+		return e, nil
+	}
+	// Sentry wants the outermost call first.
+	frames := make([]sentryFrame, len(r.Stack))
+	for i, f := range r.Stack {
+		frames[len(frames)-1-i] = sentryFrame{
+			Function: f.Function,
+			AbsPath:  f.File,
+			Lineno:   f.Line,
+			InApp:    strings.HasPrefix(f.Function, "yata/"),
+		}
+	}
+	e.Exception = &sentryExceptions{Values: []sentryException{{
+		Type:       "panic",
+		Value:      r.Message,
+		Stacktrace: sentryStacktrace{Frames: frames},
+	}}}
+	return e, nil
+}
+
+// send posts one event as an envelope: a header line, an item header line
+// and the event.
+func (s *Sentry) send(ctx context.Context, r Report) error {
+	e, err := s.event(r)
+	if err != nil {
+		return err
+	}
+	body := &bytes.Buffer{}
+	enc := json.NewEncoder(body)
+	for _, line := range []any{
+		map[string]any{"event_id": e.EventID, "sent_at": time.Now().UTC()},
+		map[string]string{"type": "event"},
+		e,
+	} {
+		if err := enc.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", s.auth)
+
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("sentry returned %d: %s", res.StatusCode, detail)
+	}
+	return nil
+}
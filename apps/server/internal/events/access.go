@@ -0,0 +1,38 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"yata/apps/server/internal/models"
+)
+
+// Access tells which tasks a scope can see, by the rule the REST endpoints
+// apply; store.TaskStore is one.
+type Access interface {
+	Visible(ctx context.Context, scope models.Scope, ids []string) ([]string, error)
+}
+
+// Allowed reports whether e may go to a listener in scope. A personal topic
+// only has its owner listening. On an org topic the task's body settles it
+// when it shows the task open to the whole org, or the listener's own;
+// otherwise access is asked, as it is for events that carry no body. An
+// event whose check fails is withheld.
+func Allowed(ctx context.Context, access Access, scope models.Scope, e Event) bool {
+	if !scope.IsOrg() {
+		return true
+	}
+	task := e.Task
+	if task == nil && e.Move != nil {
+		task = &e.Move.Task
+	}
+	if task != nil && (task.OwnerID == scope.UserID || (task.Visibility == models.TaskVisibilityOrg && !scope.Guest)) {
+		return true
+	}
+
+	visible, err := access.Visible(ctx, scope, []string{e.TaskID})
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to check access to a task event", "task_id", e.TaskID, "error", err)
+		return false
+	}
+	return len(visible) > 0
+}
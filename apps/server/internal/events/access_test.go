@@ -0,0 +1,72 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"yata/apps/server/internal/models"
+)
+
+type fakeAccess struct {
+	visible []string
+	err     error
+	asked   int
+}
+
+func (a *fakeAccess) Visible(_ context.Context, _ models.Scope, ids []string) ([]string, error) {
+	a.asked++
+	var out []string
+	for _, id := range ids {
+		if slices.Contains(a.visible, id) {
+			out = append(out, id)
+		}
+	}
+	return out, a.err
+}
+
+func TestAllowed(t *testing.T) {
+	member := models.Scope{UserID: "user_member", OrgID: "org_1"}
+	guest := models.Scope{UserID: "user_guest", OrgID: "org_1", Guest: true}
+	orgTask := &models.Task{ID: "t-org", OwnerID: "user_owner", Visibility: models.TaskVisibilityOrg}
+	privateTask := &models.Task{ID: "t-private", OwnerID: "user_owner", Visibility: models.TaskVisibilityPrivate}
+
+	tests := []struct {
+		name    string
+		scope   models.Scope
+		event   Event
+		visible []string
+		want    bool
+		asks    bool
+	}{
+		{"personal topic", models.Scope{UserID: "user_me"}, Event{Type: TaskDeleted, TaskID: "t"}, nil, true, false},
+		{"org task to member", member, Event{Type: TaskUpdated, TaskID: orgTask.ID, Task: orgTask}, nil, true, false},
+		{"org task to guest", guest, Event{Type: TaskUpdated, TaskID: orgTask.ID, Task: orgTask}, nil, false, true},
+		{"org task shared with guest", guest, Event{Type: TaskUpdated, TaskID: orgTask.ID, Task: orgTask}, []string{orgTask.ID}, true, true},
+		{"private task to member", member, Event{Type: TaskCreated, TaskID: privateTask.ID, Task: privateTask}, nil, false, true},
+		{"private task to its owner", models.Scope{UserID: "user_owner", OrgID: "org_1"}, Event{Type: TaskCreated, TaskID: privateTask.ID, Task: privateTask}, nil, true, false},
+		{"private task shared with member", member, Event{Type: TaskUpdated, TaskID: privateTask.ID, Task: privateTask}, []string{privateTask.ID}, true, true},
+		{"private card moved", member, Event{Type: BoardCardMoved, TaskID: privateTask.ID, Move: &models.BoardMove{Task: *privateTask}}, nil, false, true},
+		{"delete without body", member, Event{Type: TaskDeleted, TaskID: "t-gone"}, nil, false, true},
+		{"delete of a visible task", member, Event{Type: TaskDeleted, TaskID: "t-gone"}, []string{"t-gone"}, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			access := &fakeAccess{visible: tt.visible}
+			if got := Allowed(context.Background(), access, tt.scope, tt.event); got != tt.want {
+				t.Errorf("Allowed = %v, want %v", got, tt.want)
+			}
+			if asked := access.asked > 0; asked != tt.asks {
+				t.Errorf("asked access = %v, want %v", asked, tt.asks)
+			}
+		})
+	}
+}
+
+func TestAllowedWithholdsOnError(t *testing.T) {
+	access := &fakeAccess{visible: []string{"t"}, err: errors.New("db down")}
+	scope := models.Scope{UserID: "user_member", OrgID: "org_1"}
+	if Allowed(context.Background(), access, scope, Event{Type: TaskDeleted, TaskID: "t"}) {
+		t.Error("event allowed after a failed access check")
+	}
+}
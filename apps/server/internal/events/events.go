@@ -0,0 +1,105 @@
+// Package events fans out task changes to live listeners such as the SSE
+// stream. The broker is in-process, so a listener only sees changes made
+// through the same server instance.
+package events
+
+import (
+	"sync"
+	"yata/apps/server/internal/models"
+)
+
+const (
+	TaskCreated = "task.created"
+	TaskUpdated = "task.updated"
+	TaskDeleted = "task.deleted"
+	// BoardCardMoved is a task moved on a board; a move that changes its
+	// status is published as TaskUpdated too.
+	BoardCardMoved = "board.card_moved"
+)
+
+type Event struct {
+	Type    string            `json:"type"`
+	TaskID  string            `json:"taskId"`
+	Task    *models.Task      `json:"task,omitempty"`
+	BoardID string            `json:"boardId,omitempty"`
+	Move    *models.BoardMove `json:"move,omitempty"`
+}
+
+// Topic is where events for scope are published: the org for org tasks,
+// the user for personal ones. Everyone in the org listens on its topic, so
+// listeners pass each event through Allowed before sending it on.
+func Topic(scope models.Scope) string {
+	if scope.IsOrg() {
+		return "org:" + scope.OrgID
+	}
+	return "user:" + scope.UserID
+}
+
+// subscriberBuffer is how many events a slow listener may fall behind
+// before it starts missing them.
+const subscriberBuffer = 64
+
+type Broker struct {
+	mu     sync.RWMutex
+	topics map[string]map[chan Event]struct{}
+	closed bool
+}
+
+func NewBroker() *Broker {
+	return &Broker{topics: map[string]map[chan Event]struct{}{}}
+}
+
+// Subscribe returns a channel of events on topic and a function that must be
+// called to stop listening.
+func (b *Broker) Subscribe(topic string) (<-chan Event, func()) {
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if b.topics[topic] == nil {
+		b.topics[topic] = map[chan Event]struct{}{}
+	}
+	b.topics[topic][ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.topics[topic], ch)
+		if len(b.topics[topic]) == 0 {
+			delete(b.topics, topic)
+		}
+		b.mu.Unlock()
+	}
+}
+
+// Publish never blocks; listeners whose buffer is full drop the event.
+func (b *Broker) Publish(topic string, e Event) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for ch := range b.topics[topic] {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Close ends every subscription by closing its channel, so streams return
+// and let the server shut down. Later subscriptions get a closed channel.
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subs := range b.topics {
+		for ch := range subs {
+			close(ch)
+		}
+	}
+	b.topics = map[string]map[chan Event]struct{}{}
+}
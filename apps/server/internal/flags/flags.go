@@ -0,0 +1,203 @@
+// Package flags turns features on and off without a deploy, so experimental
+// endpoints can ship dark. A flag can be set globally, for an org and for a
+// user; the most specific setting wins, and a flag nobody set is off.
+package flags
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	ScopeGlobal = "global"
+	ScopeOrg    = "org"
+	ScopeUser   = "user"
+)
+
+// cacheTTL is how long an instance goes on using the flags it read. Changes
+// made through it show up at once; changes made elsewhere within cacheTTL.
+const cacheTTL = 30 * time.Second
+
+var keyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_.-]{0,63}$`)
+
+// ValidKey reports whether key can name a flag: lowercase letters, digits,
+// dots, dashes and underscores.
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// ValidScope reports whether scope is one of the Scope constants.
+func ValidScope(scope string) bool {
+	return scope == ScopeGlobal || scope == ScopeOrg || scope == ScopeUser
+}
+
+// Flag is one setting of a flag. TargetID is the org or user it applies to,
+// and empty for a global setting.
+type Flag struct {
+	Key       string    `json:"key"`
+	Scope     string    `json:"scope"`
+	TargetID  string    `json:"targetId"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SetInput turns a flag on or off at one scope.
+type SetInput struct {
+	Scope    string `json:"scope" binding:"required"`
+	TargetID string `json:"targetId"`
+	Enabled  *bool  `json:"enabled" binding:"required"`
+}
+
+var ErrNotFound = errors.New("flag not found")
+
+type Store interface {
+	// List returns every setting by key.
+	List(ctx context.Context) ([]Flag, error)
+	Set(ctx context.Context, flag Flag) (*Flag, error)
+	Delete(ctx context.Context, key, scope, targetID string) error
+}
+
+type target struct{ key, scope, id string }
+
+// Flags answers flag checks from a copy of every setting, reloaded once it's
+// older than cacheTTL.
+type Flags struct {
+	store Store
+
+	mu       sync.Mutex
+	settings map[target]bool
+	loadedAt time.Time
+}
+
+func New(store Store) *Flags {
+	return &Flags{store: store}
+}
+
+// Enabled reports whether key is on for the user in the org; orgID is empty
+// outside one. A flag that can't be read counts as off.
+func (f *Flags) Enabled(ctx context.Context, key, userID, orgID string) bool {
+	settings, err := f.load(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to load feature flags", "error", err)
+		return false
+	}
+
+	if userID != "" {
+		if on, ok := settings[target{key, ScopeUser, userID}]; ok {
+			return on
+		}
+	}
+	if orgID != "" {
+		if on, ok := settings[target{key, ScopeOrg, orgID}]; ok {
+			return on
+		}
+	}
+	return settings[target{key, ScopeGlobal, ""}]
+}
+
+func (f *Flags) load(ctx context.Context) (map[target]bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.settings != nil && time.Since(f.loadedAt) < cacheTTL {
+		return f.settings, nil
+	}
+	list, err := f.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	settings := make(map[target]bool, len(list))
+	for _, flag := range list {
+		settings[target{flag.Key, flag.Scope, flag.TargetID}] = flag.Enabled
+	}
+	f.settings, f.loadedAt = settings, time.Now()
+	return settings, nil
+}
+
+func (f *Flags) invalidate() {
+	f.mu.Lock()
+	f.settings = nil
+	f.mu.Unlock()
+}
+
+func (f *Flags) List(ctx context.Context) ([]Flag, error) {
+	return f.store.List(ctx)
+}
+
+func (f *Flags) Set(ctx context.Context, flag Flag) (*Flag, error) {
+	set, err := f.store.Set(ctx, flag)
+	if err == nil {
+		f.invalidate()
+	}
+	return set, err
+}
+
+// Delete removes a setting, so the next less specific one applies.
+func (f *Flags) Delete(ctx context.Context, key, scope, targetID string) error {
+	err := f.store.Delete(ctx, key, scope, targetID)
+	if err == nil {
+		f.invalidate()
+	}
+	return err
+}
+
+type PostgresStore struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresStore(pool *pgxpool.Pool) *PostgresStore {
+	return &PostgresStore{pool: pool}
+}
+
+func (s *PostgresStore) List(ctx context.Context) ([]Flag, error) {
+	rows, err := s.pool.Query(ctx,
+		`SELECT key, scope, target_id, enabled, updated_at FROM feature_flags ORDER BY key, scope, target_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Flag{}
+	for rows.Next() {
+		var flag Flag
+		if err := rows.Scan(&flag.Key, &flag.Scope, &flag.TargetID, &flag.Enabled, &flag.UpdatedAt); err != nil {
+			return nil, err
+		}
+		list = append(list, flag)
+	}
+	return list, rows.Err()
+}
+
+func (s *PostgresStore) Set(ctx context.Context, flag Flag) (*Flag, error) {
+	err := s.pool.QueryRow(ctx,
+		`INSERT INTO feature_flags (key, scope, target_id, enabled) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (key, scope, target_id) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = NOW()
+		 RETURNING updated_at`,
+		flag.Key, flag.Scope, flag.TargetID, flag.Enabled,
+	).Scan(&flag.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, key, scope, targetID string) error {
+	tag, err := s.pool.Exec(ctx,
+		`DELETE FROM feature_flags WHERE key = $1 AND scope = $2 AND target_id = $3`,
+		key, scope, targetID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
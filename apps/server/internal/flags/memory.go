@@ -0,0 +1,55 @@
+package flags
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps flags in memory, for tests and a server without a
+// database.
+type MemoryStore struct {
+	mu    sync.Mutex
+	flags map[target]Flag
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{flags: map[target]Flag{}}
+}
+
+func (s *MemoryStore) List(_ context.Context) ([]Flag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make([]Flag, 0, len(s.flags))
+	for _, flag := range s.flags {
+		list = append(list, flag)
+	}
+	slices.SortFunc(list, func(a, b Flag) int {
+		return cmp.Or(cmp.Compare(a.Key, b.Key), cmp.Compare(a.Scope, b.Scope), cmp.Compare(a.TargetID, b.TargetID))
+	})
+	return list, nil
+}
+
+func (s *MemoryStore) Set(_ context.Context, flag Flag) (*Flag, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	flag.UpdatedAt = time.Now()
+	s.flags[target{flag.Key, flag.Scope, flag.TargetID}] = flag
+	return &flag, nil
+}
+
+func (s *MemoryStore) Delete(_ context.Context, key, scope, targetID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t := target{key, scope, targetID}
+	if _, ok := s.flags[t]; !ok {
+		return ErrNotFound
+	}
+	delete(s.flags, t)
+	return nil
+}
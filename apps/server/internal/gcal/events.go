@@ -0,0 +1,186 @@
+package gcal
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// eventLength is how long the event for a due time runs; tasks only have
+// the one point in time.
+const eventLength = 30 * time.Minute
+
+// The private extended properties tying an event to the task and the
+// connection it was made for.
+const (
+	taskProperty       = "yataTaskId"
+	connectionProperty = "yataConnection"
+)
+
+// ErrEventGone is returned when the event was deleted on Google's side.
+var ErrEventGone = errors.New("google calendar event is gone")
+
+// Event is the part of a Calendar event the sync cares about.
+type Event struct {
+	ID          string
+	TaskID      string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	// AllDay events have a date but no time; Start is midnight UTC.
+	AllDay bool
+	// TimeZone, when set, keeps the event's wall time across DST changes.
+	TimeZone  string
+	Cancelled bool
+	Updated   time.Time
+}
+
+type eventTime struct {
+	DateTime string `json:"dateTime,omitempty"`
+	Date     string `json:"date,omitempty"`
+	TimeZone string `json:"timeZone,omitempty"`
+}
+
+type eventSource struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type apiEvent struct {
+	ID                 string              `json:"id,omitempty"`
+	Status             string              `json:"status,omitempty"`
+	Summary            string              `json:"summary"`
+	Description        string              `json:"description"`
+	Source             *eventSource        `json:"source,omitempty"`
+	Start              *eventTime          `json:"start,omitempty"`
+	End                *eventTime          `json:"end,omitempty"`
+	Updated            string              `json:"updated,omitempty"`
+	ExtendedProperties *extendedProperties `json:"extendedProperties,omitempty"`
+}
+
+type extendedProperties struct {
+	Private map[string]string `json:"private"`
+}
+
+func toAPI(connectionID string, e Event) apiEvent {
+	a := apiEvent{
+		Summary:     e.Summary,
+		Description: e.Description,
+		Start:       &eventTime{DateTime: e.Start.Format(time.RFC3339), TimeZone: e.TimeZone},
+		End:         &eventTime{DateTime: e.Start.Add(eventLength).Format(time.RFC3339), TimeZone: e.TimeZone},
+	}
+	if e.URL != "" {
+		a.Source = &eventSource{Title: "yata", URL: e.URL}
+	}
+	a.ExtendedProperties = &extendedProperties{Private: map[string]string{taskProperty: e.TaskID, connectionProperty: connectionID}}
+	return a
+}
+
+func fromAPI(a apiEvent) Event {
+	e := Event{
+		ID:          a.ID,
+		Summary:     a.Summary,
+		Description: a.Description,
+		Cancelled:   a.Status == "cancelled",
+	}
+	if a.ExtendedProperties != nil {
+		e.TaskID = a.ExtendedProperties.Private[taskProperty]
+	}
+	if a.Start != nil {
+		e.TimeZone = a.Start.TimeZone
+		if t, err := time.Parse(time.RFC3339, a.Start.DateTime); err == nil {
+			e.Start = t
+		} else if t, err := time.Parse(time.DateOnly, a.Start.Date); err == nil {
+			e.Start = t
+			e.AllDay = true
+		}
+	}
+	e.Updated, _ = time.Parse(time.RFC3339, a.Updated)
+	return e
+}
+
+func (c *Client) eventsURL(calendarID string) string {
+	return c.apiBase + "/calendars/" + url.PathEscape(calendarID) + "/events"
+}
+
+func (c *Client) send(ctx context.Context, method, target, accessToken string, body any, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return c.do(req, out)
+}
+
+// InsertEvent creates e on the calendar and returns its id.
+func (c *Client) InsertEvent(ctx context.Context, accessToken, calendarID, connectionID string, e Event) (string, error) {
+	var res apiEvent
+	if err := c.send(ctx, http.MethodPost, c.eventsURL(calendarID), accessToken, toAPI(connectionID, e), &res); err != nil {
+		return "", err
+	}
+	return res.ID, nil
+}
+
+// UpdateEvent overwrites the event's time and text with e's. It returns
+// ErrEventGone if the user deleted the event.
+func (c *Client) UpdateEvent(ctx context.Context, accessToken, calendarID, connectionID string, e Event) error {
+	err := c.send(ctx, http.MethodPatch, c.eventsURL(calendarID)+"/"+url.PathEscape(e.ID), accessToken, toAPI(connectionID, e), nil)
+	if s := statusCode(err); s == http.StatusNotFound || s == http.StatusGone {
+		return ErrEventGone
+	}
+	return err
+}
+
+// DeleteEvent deletes the event; one that's already gone isn't an error.
+func (c *Client) DeleteEvent(ctx context.Context, accessToken, calendarID, eventID string) error {
+	err := c.send(ctx, http.MethodDelete, c.eventsURL(calendarID)+"/"+url.PathEscape(eventID), accessToken, nil, nil)
+	if s := statusCode(err); s == http.StatusNotFound || s == http.StatusGone {
+		return nil
+	}
+	return err
+}
+
+// ChangedEvents lists the events made for connectionID that changed since
+// since, deleted ones included.
+func (c *Client) ChangedEvents(ctx context.Context, accessToken, calendarID, connectionID string, since time.Time) ([]Event, error) {
+	q := url.Values{
+		"privateExtendedProperty": {connectionProperty + "=" + connectionID},
+		"updatedMin":              {since.UTC().Format(time.RFC3339)},
+		"showDeleted":             {"true"},
+		"maxResults":              {"250"},
+	}
+	var events []Event
+	for {
+		var res struct {
+			Items         []apiEvent `json:"items"`
+			NextPageToken string     `json:"nextPageToken"`
+		}
+		if err := c.send(ctx, http.MethodGet, c.eventsURL(calendarID)+"?"+q.Encode(), accessToken, nil, &res); err != nil {
+			return nil, err
+		}
+		for _, a := range res.Items {
+			events = append(events, fromAPI(a))
+		}
+		if res.NextPageToken == "" {
+			return events, nil
+		}
+		q.Set("pageToken", res.NextPageToken)
+	}
+}
@@ -0,0 +1,226 @@
+// Package gcal syncs tasks with due dates to Google Calendar. Each open task
+// gets an event at its due time; moving the event in Google moves the task's
+// due date, and changing the task updates the event.
+package gcal
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+const (
+	authEndpoint     = "https://accounts.google.com/o/oauth2/v2/auth"
+	tokenEndpoint    = "https://oauth2.googleapis.com/token"
+	revokeEndpoint   = "https://oauth2.googleapis.com/revoke"
+	userinfoEndpoint = "https://openidconnect.googleapis.com/v1/userinfo"
+
+	// oauthScopes covers events on the user's calendars, plus the address
+	// shown on the connection.
+	oauthScopes = "https://www.googleapis.com/auth/calendar.events email"
+
+	// stateTTL is how long the user has to finish the consent screen.
+	stateTTL = 10 * time.Minute
+)
+
+var ErrInvalidState = errors.New("google calendar oauth state is invalid")
+
+// Config is the OAuth client registered with Google. RedirectURL must point
+// at the API's callback route.
+type Config struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+}
+
+// Client talks OAuth and the Calendar API for every connection.
+type Client struct {
+	cfg    Config
+	client *http.Client
+	// apiBase is the Calendar API root.
+	apiBase string
+}
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:     cfg,
+		client:  &http.Client{Timeout: 15 * time.Second},
+		apiBase: "https://www.googleapis.com/calendar/v3",
+	}
+}
+
+// AuthURL is the consent screen to send the user to. prompt=consent makes
+// Google hand out a refresh token even if the user connected before.
+func (c *Client) AuthURL(state string) string {
+	q := url.Values{
+		"client_id":     {c.cfg.ClientID},
+		"redirect_uri":  {c.cfg.RedirectURL},
+		"response_type": {"code"},
+		"scope":         {oauthScopes},
+		"access_type":   {"offline"},
+		"prompt":        {"consent"},
+		"state":         {state},
+	}
+	return authEndpoint + "?" + q.Encode()
+}
+
+// State signs scope into the OAuth state parameter, so the callback knows
+// whose connection it's finishing without a session.
+func (c *Client) State(scope models.Scope, now time.Time) string {
+	payload := scope.UserID + "\n" + scope.OrgID + "\n" + strconv.FormatInt(now.Add(stateTTL).Unix(), 10)
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(payload)) + "." + enc.EncodeToString(c.sign(payload))
+}
+
+// VerifyState returns the scope a state was signed for.
+func (c *Client) VerifyState(state string, now time.Time) (models.Scope, error) {
+	rawPayload, rawSig, ok := strings.Cut(state, ".")
+	if !ok {
+		return models.Scope{}, ErrInvalidState
+	}
+	enc := base64.RawURLEncoding
+	payload, err := enc.DecodeString(rawPayload)
+	if err != nil {
+		return models.Scope{}, ErrInvalidState
+	}
+	sig, err := enc.DecodeString(rawSig)
+	if err != nil || !hmac.Equal(sig, c.sign(string(payload))) {
+		return models.Scope{}, ErrInvalidState
+	}
+
+	parts := strings.Split(string(payload), "\n")
+	if len(parts) != 3 || parts[0] == "" {
+		return models.Scope{}, ErrInvalidState
+	}
+	expiry, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil || !now.Before(time.Unix(expiry, 0)) {
+		return models.Scope{}, ErrInvalidState
+	}
+	return models.Scope{UserID: parts[0], OrgID: parts[1]}, nil
+}
+
+func (c *Client) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, []byte(c.cfg.ClientSecret))
+	mac.Write([]byte("gcal-state\n" + payload))
+	return mac.Sum(nil)
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// Exchange trades the callback's code for tokens.
+func (c *Client) Exchange(ctx context.Context, code string) (models.GoogleCalendarToken, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":   {"authorization_code"},
+		"code":         {code},
+		"redirect_uri": {c.cfg.RedirectURL},
+	})
+}
+
+// Refresh gets a new access token. Google usually doesn't send a new
+// refresh token, which leaves RefreshToken empty.
+func (c *Client) Refresh(ctx context.Context, refreshToken string) (models.GoogleCalendarToken, error) {
+	return c.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
+
+func (c *Client) token(ctx context.Context, form url.Values) (models.GoogleCalendarToken, error) {
+	form.Set("client_id", c.cfg.ClientID)
+	form.Set("client_secret", c.cfg.ClientSecret)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return models.GoogleCalendarToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var res tokenResponse
+	if err := c.do(req, &res); err != nil {
+		return models.GoogleCalendarToken{}, err
+	}
+	return models.GoogleCalendarToken{
+		AccessToken:  res.AccessToken,
+		RefreshToken: res.RefreshToken,
+		ExpiresAt:    time.Now().Add(time.Duration(res.ExpiresIn) * time.Second),
+	}, nil
+}
+
+// Revoke withdraws the grant, so the app drops off the user's Google
+// account when they disconnect.
+func (c *Client) Revoke(ctx context.Context, token string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeEndpoint, strings.NewReader(url.Values{"token": {token}}.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req, nil)
+}
+
+// Email returns the address of the Google account the token is for.
+func (c *Client) Email(ctx context.Context, accessToken string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, userinfoEndpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	var res struct {
+		Email string `json:"email"`
+	}
+	if err := c.do(req, &res); err != nil {
+		return "", err
+	}
+	return res.Email, nil
+}
+
+// StatusError is a non-2xx response from Google.
+type StatusError struct {
+	Status int
+	Body   string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("google returned %d: %s", e.Status, e.Body)
+}
+
+// statusCode is err's HTTP status, or 0 if it isn't a StatusError.
+func statusCode(err error) int {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Status
+	}
+	return 0
+}
+
+// do sends req and decodes a JSON response into out, if it's not nil.
+func (c *Client) do(req *http.Request, out any) error {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return &StatusError{Status: res.StatusCode, Body: string(detail)}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(res.Body).Decode(out)
+}
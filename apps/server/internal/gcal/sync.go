@@ -0,0 +1,384 @@
+package gcal
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+const (
+	batchSize = 20
+	// maxSyncedTasks bounds how many events one connection keeps.
+	maxSyncedTasks = 2000
+	// syncLookback is how long overdue tasks keep their event.
+	syncLookback = 30 * 24 * time.Hour
+	// tokenLeeway refreshes access tokens this long before they expire.
+	tokenLeeway = time.Minute
+	// pullOverlap re-reads events from a little before the last sync, so an
+	// edit made while it ran isn't missed.
+	pullOverlap = 5 * time.Minute
+	// pollEvery is how often the syncer looks for connections that are due.
+	pollEvery = 30 * time.Second
+)
+
+var (
+	errRevoked   = errors.New("google calendar access was revoked")
+	errNotMember = errors.New("user is no longer a member of the organization")
+)
+
+// Stores is what the syncer reads and writes.
+type Stores struct {
+	Connections store.GoogleCalendarStore
+	Users       store.UserStore
+	Tasks       store.TaskStore
+	Workflows   store.WorkflowStore
+}
+
+// Syncer syncs every connection once per Interval. Connections are claimed
+// before syncing, so several instances can run one each.
+type Syncer struct {
+	Client   *Client
+	Stores   Stores
+	Interval time.Duration
+	// AppURL, when set, links each event back to its task.
+	AppURL string
+}
+
+func NewSyncer(client *Client, stores Stores, interval time.Duration, appURL string) *Syncer {
+	return &Syncer{Client: client, Stores: stores, Interval: interval, AppURL: appURL}
+}
+
+// Start runs the syncer until ctx is done.
+func (s *Syncer) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(min(s.Interval, pollEvery))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run syncs every connection that is currently due, a batch at a time.
+func (s *Syncer) Run(ctx context.Context) {
+	for {
+		due, err := s.Stores.Connections.ClaimDue(ctx, s.Interval, batchSize)
+		if err != nil {
+			slog.Error("Failed to claim Google Calendar connections", "error", err)
+			return
+		}
+
+		for i := range due {
+			conn := &due[i]
+			started := time.Now()
+			var syncErr *string
+			if err := s.Sync(ctx, conn); err != nil {
+				slog.Error("Failed to sync Google Calendar", "connection", conn.ID, "error", err)
+				msg := errorMessage(err)
+				syncErr = &msg
+			}
+			if err := s.Stores.Connections.Synced(ctx, conn.ID, started, syncErr); err != nil && !errors.Is(err, store.ErrNotFound) {
+				slog.Error("Failed to record Google Calendar sync", "connection", conn.ID, "error", err)
+			}
+		}
+		if len(due) < batchSize {
+			return
+		}
+	}
+}
+
+// errorMessage is what the user sees on the connection for a failed sync.
+func errorMessage(err error) string {
+	switch {
+	case errors.Is(err, errRevoked):
+		return "Google Calendar access was revoked; reconnect to resume syncing"
+	case errors.Is(err, errNotMember):
+		return "You're no longer a member of this organization"
+	default:
+		return "The last sync failed; it will be retried"
+	}
+}
+
+// Sync pulls reschedules from the calendar into due dates, then brings the
+// events in line with the tasks. Pulling first keeps a move made in Google
+// from being undone by the push.
+func (s *Syncer) Sync(ctx context.Context, conn *models.GoogleCalendarConnection) error {
+	run, err := s.prepare(ctx, conn)
+	if err != nil {
+		return err
+	}
+	if err := run.pull(ctx); err != nil {
+		return err
+	}
+	return run.push(ctx)
+}
+
+type syncRun struct {
+	*Syncer
+	conn     *models.GoogleCalendarConnection
+	scope    models.Scope
+	workflow models.Workflow
+	token    string
+	// events is keyed by task id.
+	events map[string]models.GoogleCalendarEvent
+}
+
+func (s *Syncer) prepare(ctx context.Context, conn *models.GoogleCalendarConnection) (*syncRun, error) {
+	run := &syncRun{Syncer: s, conn: conn, scope: conn.Scope(), workflow: models.DefaultWorkflow()}
+	if run.scope.IsOrg() {
+		member, err := s.Stores.Users.GetMember(ctx, run.scope.OrgID, run.scope.UserID)
+		if errors.Is(err, store.ErrNotFound) {
+			return nil, errNotMember
+		}
+		if err != nil {
+			return nil, err
+		}
+		run.scope.Guest = member.Role == middlewares.OrgGuestRole
+
+		run.workflow, err = s.Stores.Workflows.Get(ctx, run.scope.OrgID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var err error
+	run.token, err = s.accessToken(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.Stores.Connections.Events(ctx, conn.ID)
+	if err != nil {
+		return nil, err
+	}
+	run.events = make(map[string]models.GoogleCalendarEvent, len(events))
+	for _, e := range events {
+		run.events[e.TaskID] = e
+	}
+	return run, nil
+}
+
+// accessToken returns a token good for the rest of the sync, refreshing it
+// if it's about to expire.
+func (s *Syncer) accessToken(ctx context.Context, conn *models.GoogleCalendarConnection) (string, error) {
+	if time.Until(conn.Token.ExpiresAt) > tokenLeeway {
+		return conn.Token.AccessToken, nil
+	}
+	token, err := s.Client.Refresh(ctx, conn.Token.RefreshToken)
+	if code := statusCode(err); code == http.StatusBadRequest || code == http.StatusUnauthorized {
+		return "", errRevoked
+	}
+	if err != nil {
+		return "", err
+	}
+	if err := s.Stores.Connections.SaveToken(ctx, conn.ID, token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// pull moves tasks whose event was moved in Google since the last sync. An
+// event the user deleted is let go of: the task keeps its due date, and only
+// gets a new event if it changes.
+func (r *syncRun) pull(ctx context.Context) error {
+	// The first sync only has events to create.
+	if r.conn.LastSyncedAt == nil {
+		return nil
+	}
+	changed, err := r.Client.ChangedEvents(ctx, r.token, r.conn.CalendarID, r.conn.ID, r.conn.LastSyncedAt.Add(-pullOverlap))
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range changed {
+		e, ok := r.events[ev.TaskID]
+		if !ok || e.EventID == "" || e.EventID != ev.ID {
+			continue
+		}
+		if ev.Cancelled {
+			e.EventID = ""
+			if err := r.save(ctx, e); err != nil {
+				return err
+			}
+			continue
+		}
+
+		task, err := r.Stores.Tasks.Get(ctx, r.scope, e.TaskID)
+		if errors.Is(err, store.ErrNotFound) {
+			// The push deletes its event.
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		due := ev.Start
+		if ev.AllDay {
+			due = onDate(ev.Start, task)
+		}
+		if due.Equal(e.Due) || task.DueDate == nil {
+			continue
+		}
+		// When both sides changed, the later edit wins.
+		if task.UpdatedAt.After(e.TaskUpdatedAt) && task.UpdatedAt.After(ev.Updated) {
+			continue
+		}
+
+		updated, err := r.Stores.Tasks.Update(ctx, r.scope, task.ID, models.UpdateTaskInput{
+			DueDate: models.Nullable[time.Time]{Set: true, Value: due},
+		})
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		e.Due = due
+		e.TaskUpdatedAt = updated.UpdatedAt
+		if err := r.save(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// onDate is the task's due time moved to the date of an all-day event.
+func onDate(date time.Time, task *models.Task) time.Time {
+	loc := time.UTC
+	if task.DueTimezone != nil {
+		if l, err := time.LoadLocation(*task.DueTimezone); err == nil {
+			loc = l
+		}
+	}
+	due := task.DueDate.In(loc)
+	return time.Date(date.Year(), date.Month(), date.Day(), due.Hour(), due.Minute(), due.Second(), 0, loc)
+}
+
+// push creates events for open tasks that have none, updates those whose
+// task changed since the last sync, and deletes the rest.
+func (r *syncRun) push(ctx context.Context) error {
+	tasks, err := r.openTasks(ctx)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		task := &tasks[i]
+		seen[task.ID] = true
+		e, ok := r.events[task.ID]
+		if ok && !task.UpdatedAt.After(e.TaskUpdatedAt) {
+			continue
+		}
+
+		ev := r.taskEvent(task)
+		if ok && e.EventID != "" {
+			ev.ID = e.EventID
+			err := r.Client.UpdateEvent(ctx, r.token, r.conn.CalendarID, r.conn.ID, ev)
+			if errors.Is(err, ErrEventGone) {
+				ev.ID = ""
+			} else if err != nil {
+				return err
+			}
+		}
+		if ev.ID == "" {
+			ev.ID, err = r.Client.InsertEvent(ctx, r.token, r.conn.CalendarID, r.conn.ID, ev)
+			if err != nil {
+				return err
+			}
+		}
+
+		err := r.save(ctx, models.GoogleCalendarEvent{
+			ConnectionID:  r.conn.ID,
+			TaskID:        task.ID,
+			EventID:       ev.ID,
+			Due:           *task.DueDate,
+			TaskUpdatedAt: task.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	for taskID, e := range r.events {
+		if seen[taskID] {
+			continue
+		}
+		if e.EventID != "" {
+			if err := r.Client.DeleteEvent(ctx, r.token, r.conn.CalendarID, e.EventID); err != nil {
+				return err
+			}
+		}
+		if err := r.Stores.Connections.DeleteEvent(ctx, r.conn.ID, taskID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *syncRun) save(ctx context.Context, e models.GoogleCalendarEvent) error {
+	if err := r.Stores.Connections.SaveEvent(ctx, e); err != nil {
+		return err
+	}
+	r.events[e.TaskID] = e
+	return nil
+}
+
+// openTasks lists the open tasks with a due date that should have an
+// event, soonest first.
+func (r *syncRun) openTasks(ctx context.Context) ([]models.Task, error) {
+	since := time.Now().Add(-syncLookback)
+	filter := models.TaskFilter{
+		OpenOnly:     true,
+		DoneStatuses: r.workflow.DoneStatuses(),
+		DueAfter:     &since,
+		Sort:         []models.SortField{{Field: "due_date"}},
+	}
+	var tasks []models.Task
+	page := models.Page{Limit: api.MaxLimit}
+	for len(tasks) < maxSyncedTasks {
+		list, err := r.Stores.Tasks.List(ctx, r.scope, filter, page)
+		if err != nil {
+			return nil, err
+		}
+		list, hasMore := api.Trim(list, page.Limit)
+		tasks = append(tasks, list...)
+		if !hasMore {
+			break
+		}
+		last := &list[len(list)-1]
+		page.After = []string{models.TaskSortValue(last, filter.Sort[0]), last.ID}
+	}
+	if len(tasks) > maxSyncedTasks {
+		tasks = tasks[:maxSyncedTasks]
+	}
+	return tasks, nil
+}
+
+func (r *syncRun) taskEvent(t *models.Task) Event {
+	e := Event{
+		TaskID:      t.ID,
+		Summary:     t.Title,
+		Description: t.Description,
+		Start:       *t.DueDate,
+	}
+	if t.DueTimezone != nil {
+		e.TimeZone = *t.DueTimezone
+	}
+	if r.AppURL != "" {
+		e.URL = strings.TrimRight(r.AppURL, "/") + "/tasks/" + t.ID
+	}
+	return e
+}
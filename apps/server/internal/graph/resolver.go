@@ -0,0 +1,139 @@
+// Package graph serves the GraphQL API over the same stores as the REST
+// handlers. The executable schema is generated by gqlgen from
+// schema.graphqls; resolvers live in schema.resolvers.go.
+package graph
+
+//go:generate go tool gqlgen generate --config gqlgen.yml
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+var (
+	errUnauthorized  = errors.New("Unauthorized")
+	errNoOrg         = errors.New("No organization selected")
+	errGuest         = errors.New("Not available to guests")
+	errInvalidLimit  = errors.New("Invalid limit")
+	errInvalidCursor = errors.New("Invalid cursor")
+	errInvalidID     = errors.New("Invalid id")
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type Resolver struct {
+	Tasks    store.TaskStore
+	Projects store.ProjectStore
+	Labels   store.LabelStore
+	Comments store.CommentStore
+	Users    store.UserStore
+}
+
+// request is what one GraphQL request resolves with: who's asking, and
+// loaders that live as long as the request.
+type request struct {
+	scope    models.Scope
+	labels   *loader[string, []models.Label]
+	projects *loader[string, *models.Project]
+	tasks    *loader[string, *models.Task]
+	users    *loader[string, *models.User]
+}
+
+type requestKey struct{}
+
+// WithScope prepares ctx for executing a request as scope.
+func (r *Resolver) WithScope(ctx context.Context, scope models.Scope) context.Context {
+	req := &request{scope: scope}
+	req.labels = newLoader(func(ctx context.Context, taskIDs []string) (map[string][]models.Label, error) {
+		if !scope.IsOrg() {
+			return nil, nil
+		}
+		return r.Labels.ForTasks(ctx, scope.OrgID, taskIDs)
+	})
+	req.projects = newLoader(func(ctx context.Context, ids []string) (map[string]*models.Project, error) {
+		return each(ids, func(id string) (*models.Project, error) { return r.Projects.Get(ctx, scope.OrgID, id) })
+	})
+	req.tasks = newLoader(func(ctx context.Context, ids []string) (map[string]*models.Task, error) {
+		return each(ids, func(id string) (*models.Task, error) { return r.Tasks.Get(ctx, scope, id) })
+	})
+	req.users = newLoader(func(ctx context.Context, ids []string) (map[string]*models.User, error) {
+		return each(ids, func(id string) (*models.User, error) { return r.Users.GetUser(ctx, id) })
+	})
+	return context.WithValue(ctx, requestKey{}, req)
+}
+
+func requestFrom(ctx context.Context) (*request, error) {
+	req, ok := ctx.Value(requestKey{}).(*request)
+	if !ok {
+		return nil, errUnauthorized
+	}
+	return req, nil
+}
+
+// orgRequest is for the fields that, like /projects and /labels, need an
+// active org and are closed to guests.
+func orgRequest(ctx context.Context) (*request, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !req.scope.IsOrg() {
+		return nil, errNoOrg
+	}
+	if req.scope.Guest {
+		return nil, errGuest
+	}
+	return req, nil
+}
+
+// each fetches keys one at a time for stores without a batch lookup; the
+// loader still saves the repeats. Missing rows are left out.
+func each[V any](keys []string, get func(string) (V, error)) (map[string]V, error) {
+	values := make(map[string]V, len(keys))
+	for _, key := range keys {
+		v, err := get(key)
+		if errors.Is(err, store.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		values[key] = v
+	}
+	return values, nil
+}
+
+// pageOf turns first/after into a page for the listing kind.
+func pageOf(kind string, first *int, after *string) (models.Page, error) {
+	page := models.Page{Limit: api.DefaultLimit}
+	if first != nil {
+		if *first < 1 {
+			return page, errInvalidLimit
+		}
+		page.Limit = min(*first, api.MaxLimit)
+	}
+	if after != nil && *after != "" {
+		values, err := api.DecodeCursor(kind, *after)
+		if err != nil {
+			return page, errInvalidCursor
+		}
+		page.After = values
+	}
+	return page, nil
+}
+
+// internalError logs err and returns what the client sees instead, like
+// the REST handlers' 500 responses.
+func internalError(ctx context.Context, msg string, err error) error {
+	slog.ErrorContext(ctx, msg, "error", err)
+	return errors.New(msg)
+}
+
+func isValidID(id string) bool {
+	return uuidPattern.MatchString(id)
+}
@@ -0,0 +1,246 @@
+package graph
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.78
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+// Author is the resolver for the author field.
+func (r *commentResolver) Author(ctx context.Context, obj *models.Comment) (*models.User, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, _, err := req.users.Load(ctx, obj.AuthorID)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get user", err)
+	}
+	return user, nil
+}
+
+// Tasks is the resolver for the tasks field.
+func (r *projectResolver) Tasks(ctx context.Context, obj *models.Project, filter *TaskFilter, first *int, after *string) (*TaskConnection, error) {
+	if filter == nil {
+		filter = &TaskFilter{}
+	}
+	filter.ProjectID = &obj.ID
+	return r.listTasks(ctx, filter, first, after)
+}
+
+// Me is the resolver for the me field.
+func (r *queryResolver) Me(ctx context.Context) (*models.User, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, _, err := req.users.Load(ctx, req.scope.UserID)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get user", err)
+	}
+	return user, nil
+}
+
+// Task is the resolver for the task field.
+func (r *queryResolver) Task(ctx context.Context, id string) (*models.Task, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidID(id) {
+		return nil, nil
+	}
+	task, _, err := req.tasks.Load(ctx, id)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get task", err)
+	}
+	return task, nil
+}
+
+// Tasks is the resolver for the tasks field.
+func (r *queryResolver) Tasks(ctx context.Context, filter *TaskFilter, first *int, after *string) (*TaskConnection, error) {
+	return r.listTasks(ctx, filter, first, after)
+}
+
+// Project is the resolver for the project field.
+func (r *queryResolver) Project(ctx context.Context, id string) (*models.Project, error) {
+	req, err := orgRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !isValidID(id) {
+		return nil, nil
+	}
+	project, _, err := req.projects.Load(ctx, id)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get project", err)
+	}
+	return project, nil
+}
+
+// Projects is the resolver for the projects field.
+func (r *queryResolver) Projects(ctx context.Context, includeArchived *bool, first *int, after *string) (*ProjectConnection, error) {
+	req, err := orgRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := pageOf("projects", first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := r.Resolver.Projects.List(ctx, req.scope.OrgID, includeArchived != nil && *includeArchived, page)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list projects", err)
+	}
+
+	list, hasMore := api.Trim(list, page.Limit)
+	pageInfo := &api.PageInfo{HasMore: hasMore}
+	if hasMore {
+		last := list[len(list)-1]
+		pageInfo.NextCursor = api.EncodeCursor("projects", last.Name, last.ID)
+	}
+	return &ProjectConnection{Nodes: list, PageInfo: pageInfo}, nil
+}
+
+// Labels is the resolver for the labels field.
+func (r *queryResolver) Labels(ctx context.Context) ([]models.Label, error) {
+	req, err := orgRequest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := r.Resolver.Labels.List(ctx, req.scope.OrgID)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list labels", err)
+	}
+	return list, nil
+}
+
+// Owner is the resolver for the owner field.
+func (r *taskResolver) Owner(ctx context.Context, obj *models.Task) (*models.User, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	user, _, err := req.users.Load(ctx, obj.OwnerID)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get user", err)
+	}
+	return user, nil
+}
+
+// Project is the resolver for the project field.
+func (r *taskResolver) Project(ctx context.Context, obj *models.Task) (*models.Project, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// Guests see the task but not the project around it.
+	if obj.ProjectID == nil || !req.scope.IsOrg() || req.scope.Guest {
+		return nil, nil
+	}
+	project, _, err := req.projects.Load(ctx, *obj.ProjectID)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get project", err)
+	}
+	return project, nil
+}
+
+// Parent is the resolver for the parent field.
+func (r *taskResolver) Parent(ctx context.Context, obj *models.Task) (*models.Task, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if obj.ParentID == nil {
+		return nil, nil
+	}
+	parent, _, err := req.tasks.Load(ctx, *obj.ParentID)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to get task", err)
+	}
+	return parent, nil
+}
+
+// Subtasks is the resolver for the subtasks field.
+func (r *taskResolver) Subtasks(ctx context.Context, obj *models.Task) ([]models.Task, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	filter := models.TaskFilter{ParentID: obj.ID, Sort: models.DefaultTaskSort}
+	list, err := r.Resolver.Tasks.List(ctx, req.scope, filter, models.Page{Limit: api.MaxLimit})
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list subtasks", err)
+	}
+	list, _ = api.Trim(list, api.MaxLimit)
+	return list, nil
+}
+
+// Labels is the resolver for the labels field.
+func (r *taskResolver) Labels(ctx context.Context, obj *models.Task) ([]models.Label, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	labels, _, err := req.labels.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to load task labels", err)
+	}
+	if labels == nil {
+		labels = []models.Label{}
+	}
+	return labels, nil
+}
+
+// Comments is the resolver for the comments field.
+func (r *taskResolver) Comments(ctx context.Context, obj *models.Task, first *int, after *string) (*CommentConnection, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+	page, err := pageOf("comments", first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := r.Resolver.Comments.List(ctx, req.scope, obj.ID, page)
+	if err != nil && !errors.Is(err, store.ErrNotFound) {
+		return nil, internalError(ctx, "Failed to list comments", err)
+	}
+
+	list, hasMore := api.Trim(list, page.Limit)
+	pageInfo := &api.PageInfo{HasMore: hasMore}
+	if hasMore {
+		last := list[len(list)-1]
+		pageInfo.NextCursor = api.EncodeCursor("comments", last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	if list == nil {
+		list = []models.Comment{}
+	}
+	return &CommentConnection{Nodes: list, PageInfo: pageInfo}, nil
+}
+
+// Comment returns CommentResolver implementation.
+func (r *Resolver) Comment() CommentResolver { return &commentResolver{r} }
+
+// Project returns ProjectResolver implementation.
+func (r *Resolver) Project() ProjectResolver { return &projectResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+// Task returns TaskResolver implementation.
+func (r *Resolver) Task() TaskResolver { return &taskResolver{r} }
+
+type commentResolver struct{ *Resolver }
+type projectResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
+type taskResolver struct{ *Resolver }
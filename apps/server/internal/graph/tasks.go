@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/models"
+)
+
+// listTasks pages tasks in the default sort, issuing cursors the REST
+// listing also accepts.
+func (r *Resolver) listTasks(ctx context.Context, in *TaskFilter, first *int, after *string) (*TaskConnection, error) {
+	req, err := requestFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := models.TaskFilter{Sort: models.DefaultTaskSort}
+	if in != nil {
+		for _, id := range []*string{in.ProjectID, in.LabelID, in.ParentID} {
+			if id != nil && !isValidID(*id) {
+				return nil, errInvalidID
+			}
+		}
+		filter.ProjectID = deref(in.ProjectID)
+		filter.LabelID = deref(in.LabelID)
+		filter.ParentID = deref(in.ParentID)
+		filter.TopLevel = deref(in.TopLevel)
+		filter.Statuses = in.Status
+		filter.IncludeArchived = deref(in.IncludeArchived)
+	}
+
+	kind := "tasks:" + models.SortString(filter.Sort)
+	page, err := pageOf(kind, first, after)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := r.Tasks.List(ctx, req.scope, filter, page)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list tasks", err)
+	}
+
+	list, hasMore := api.Trim(list, page.Limit)
+	pageInfo := &api.PageInfo{HasMore: hasMore}
+	if hasMore {
+		last := &list[len(list)-1]
+		values := make([]string, 0, len(filter.Sort)+1)
+		for _, s := range filter.Sort {
+			values = append(values, models.TaskSortValue(last, s))
+		}
+		pageInfo.NextCursor = api.EncodeCursor(kind, append(values, last.ID)...)
+	}
+	return &TaskConnection{Nodes: list, PageInfo: pageInfo}, nil
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/account"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateDataExportHandler starts building an archive of everything kept
+// about the caller, across all their orgs. Asking again while one is being
+// built returns that one; GET /me/export/:id follows along.
+func CreateDataExportHandler(accounts store.AccountStore, queue jobs.Queue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		export, err := accounts.CreateExport(ctx, scope.UserID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create data export", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to start export", err))
+			return
+		}
+		if export.Status == models.DataExportPending {
+			// A job queued for it already only finds it started.
+			if err := account.Enqueue(ctx, queue, export.ID); err != nil {
+				slog.ErrorContext(ctx, "Failed to queue data export", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to start export", err))
+				return
+			}
+		}
+
+		c.JSON(http.StatusAccepted, export)
+	}
+}
+
+// GetDataExportHandler reports on an export, with a short-lived download
+// URL once it's done and until it expires.
+func GetDataExportHandler(accounts store.AccountStore, files storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Export not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		export, err := accounts.GetExport(ctx, scope.UserID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Export not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get data export", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get export", err))
+			return
+		}
+
+		if export.Downloadable(time.Now()) {
+			url, err := files.PresignGet(ctx, *export.Key, account.ArchiveName, downloadURLExpiry)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to presign data export download", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to get export", err))
+				return
+			}
+			export.DownloadURL = &url
+		}
+
+		c.JSON(http.StatusOK, export)
+	}
+}
+
+// DeleteAccountHandler erases the caller's data and then deletes them from
+// Clerk. Both steps can be repeated, so a failure partway is retried by
+// calling again; Clerk's user.deleted webhook erases once more regardless.
+func DeleteAccountHandler(eraser account.Eraser, deleter account.Deleter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		if err := eraser.Erase(ctx, scope.UserID); err != nil {
+			slog.ErrorContext(ctx, "Failed to erase account", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete account", err))
+			return
+		}
+		if err := deleter.Delete(ctx, scope.UserID); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete Clerk user", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete account", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListTaskActivityHandler(activity store.ActivityStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "activity")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		list, err := activity.ListForTask(c.Request.Context(), scope, id, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list task activity", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list activity", err))
+			return
+		}
+
+		respondActivity(c, list, page)
+	}
+}
+
+// AssigneeHistoryHandler lists the changes of a task's owner, oldest first,
+// with names for the users involved.
+func AssigneeHistoryHandler(tasks store.TaskStore, activity store.ActivityStore, users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		if _, err := tasks.Get(ctx, scope, id); errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		} else if err != nil {
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+
+		entries, err := activity.ListOwnerChanges(ctx, scope, id)
+		if err != nil {
+			apierror.Respond(c, apierror.Internal("Failed to list assignee history", err))
+			return
+		}
+
+		names := map[string]string{}
+		person := func(userID string) models.Person {
+			name, ok := names[userID]
+			if !ok {
+				// The history still reads without a name, so don't fail on it.
+				if u, err := users.GetUser(ctx, userID); err == nil {
+					name = strings.TrimSpace(deref(u.FirstName) + " " + deref(u.LastName))
+					if name == "" {
+						name = deref(u.Email)
+					}
+				}
+				names[userID] = name
+			}
+			return models.Person{ID: userID, Name: name}
+		}
+		personOf := func(v any) *models.Person {
+			userID, ok := v.(string)
+			if !ok || userID == "" {
+				return nil
+			}
+			p := person(userID)
+			return &p
+		}
+
+		history := make([]models.AssigneeChange, 0, len(entries))
+		for _, a := range entries {
+			change := a.Changes["ownerId"]
+			history = append(history, models.AssigneeChange{
+				ActivityID: a.ID,
+				From:       personOf(change.Old),
+				To:         personOf(change.New),
+				Actor:      person(a.ActorID),
+				ChangedAt:  a.CreatedAt,
+			})
+		}
+		c.JSON(http.StatusOK, gin.H{"history": history})
+	}
+}
+
+// ListOrgActivityHandler is the org-wide audit feed, for admins.
+func ListOrgActivityHandler(activity store.ActivityStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "activity")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		list, err := activity.ListForOrg(c.Request.Context(), scope.OrgID, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list org activity", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list activity", err))
+			return
+		}
+
+		respondActivity(c, list, page)
+	}
+}
+
+func respondActivity(c *gin.Context, list []models.Activity, page models.Page) {
+	list, hasMore := api.Trim(list, page.Limit)
+	pageInfo := api.PageInfo{HasMore: hasMore}
+	if hasMore {
+		last := list[len(list)-1]
+		pageInfo.NextCursor = api.EncodeCursor("activity", last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"activity": list, "pageInfo": pageInfo})
+}
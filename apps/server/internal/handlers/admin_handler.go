@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+	"yata/apps/server/internal/middlewares"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetRecentErrorsHandler(buf *middlewares.RecentErrors) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"errors": buf.List(),
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/apitokens"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CreateAPITokenHandler mints a personal access token for the active scope.
+// The token itself is in this response only.
+func CreateAPITokenHandler(tokens store.APITokenStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateAPITokenInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		for _, s := range input.Scopes {
+			if !models.ValidTokenScope(s) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid scope").WithDetails(gin.H{"scope": s}))
+				return
+			}
+		}
+		slices.Sort(input.Scopes)
+		input.Scopes = slices.Compact(input.Scopes)
+
+		now := time.Now()
+		if input.ExpiresAt != nil && (!input.ExpiresAt.After(now) || input.ExpiresAt.After(now.Add(models.MaxAPITokenTTL))) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid expiry").WithDetails(gin.H{"maxExpiresAt": now.Add(models.MaxAPITokenTTL).UTC()}))
+			return
+		}
+
+		secret, prefix, hash := apitokens.NewToken()
+		input.Prefix, input.TokenHash = prefix, hash
+
+		token, err := tokens.Create(c.Request.Context(), scope, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create API token", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create token", err))
+			return
+		}
+
+		token.Token = secret
+		c.JSON(http.StatusCreated, token)
+	}
+}
+
+func ListAPITokensHandler(tokens store.APITokenStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := tokens.ListForUser(c.Request.Context(), scope.UserID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list API tokens", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list tokens", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"tokens": list})
+	}
+}
+
+func DeleteAPITokenHandler(tokens store.APITokenStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Token not found"))
+			return
+		}
+
+		err := tokens.Delete(c.Request.Context(), scope.UserID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Token not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete API token", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete token", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
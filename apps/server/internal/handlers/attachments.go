@@ -0,0 +1,304 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	uploadURLExpiry   = 15 * time.Minute
+	downloadURLExpiry = 5 * time.Minute
+
+	// attachmentObjectName names every attachment object in storage. Keys
+	// are stored in the clear, so the filename, which isn't, stays out of
+	// them; downloads are named from the attachment.
+	attachmentObjectName = "file"
+)
+
+// AttachmentLimits are checked both when an upload is requested and again
+// against what actually arrived.
+type AttachmentLimits struct {
+	MaxSize      int64
+	AllowedTypes []string
+}
+
+func (l AttachmentLimits) allows(contentType string, size int64) bool {
+	return size > 0 && size <= l.MaxSize && slices.Contains(l.AllowedTypes, contentType)
+}
+
+// mediaType drops parameters such as charset and lowercases the rest, so
+// "Text/Plain; charset=utf-8" matches "text/plain".
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mt
+}
+
+// RequestUploadHandler records a pending attachment and returns a presigned
+// URL the client PUTs the file to, with the headers it has to send.
+func RequestUploadHandler(attachments store.AttachmentStore, files storage.Storage, limits AttachmentLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.CreateAttachmentInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		input.Filename = strings.TrimSpace(input.Filename)
+		input.ContentType = mediaType(input.ContentType)
+		if input.Filename == "" {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid filename"))
+			return
+		}
+		if input.Size > limits.MaxSize {
+			apierror.Respond(c, apierror.New(http.StatusRequestEntityTooLarge, "File is too large").WithDetails(gin.H{"maxSize": limits.MaxSize}))
+			return
+		}
+		if !limits.allows(input.ContentType, input.Size) {
+			apierror.Respond(c, apierror.New(http.StatusUnsupportedMediaType, "File type is not allowed"))
+			return
+		}
+		input.Key = storage.NewKey("attachments/"+taskID, attachmentObjectName)
+
+		attachment, err := attachments.Create(c.Request.Context(), scope, taskID, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create attachment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create attachment", err))
+			return
+		}
+
+		url, err := files.PresignPut(c.Request.Context(), attachment.Key, attachment.ContentType, attachment.Size, uploadURLExpiry)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to presign upload", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create attachment", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"attachment": attachment,
+			"upload": gin.H{
+				"method":    http.MethodPut,
+				"url":       url,
+				"headers":   gin.H{"Content-Type": attachment.ContentType},
+				"expiresAt": time.Now().Add(uploadURLExpiry).UTC(),
+			},
+		})
+	}
+}
+
+// ConfirmUploadHandler checks the uploaded object against what was requested
+// and the limits before the attachment shows up on the task. An object that
+// doesn't match is thrown away along with the attachment.
+func ConfirmUploadHandler(attachments store.AttachmentStore, files storage.Storage, limits AttachmentLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("attachmentId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+
+		attachment, err := attachments.Get(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get attachment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to confirm attachment", err))
+			return
+		}
+		if attachment.ConfirmedAt != nil {
+			c.JSON(http.StatusOK, attachment)
+			return
+		}
+		if attachment.UploaderID != scope.UserID {
+			apierror.Respond(c, apierror.Forbidden("Only the uploader can confirm this attachment"))
+			return
+		}
+
+		info, err := files.Stat(c.Request.Context(), attachment.Key)
+		if errors.Is(err, storage.ErrObjectNotFound) {
+			apierror.Respond(c, apierror.Conflict("File has not been uploaded"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to stat attachment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to confirm attachment", err))
+			return
+		}
+
+		contentType := mediaType(info.ContentType)
+		if info.Size != attachment.Size || contentType != attachment.ContentType || !limits.allows(contentType, info.Size) {
+			if err := files.Delete(c.Request.Context(), attachment.Key); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to delete rejected upload", "error", err)
+			}
+			if err := attachments.Delete(c.Request.Context(), scope, taskID, id); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to delete rejected attachment", "error", err)
+			}
+			apierror.Respond(c, apierror.New(http.StatusUnprocessableEntity, "Uploaded file does not match the request"))
+			return
+		}
+
+		attachment, err = attachments.Confirm(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to confirm attachment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to confirm attachment", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, attachment)
+	}
+}
+
+func ListAttachmentsHandler(attachments store.AttachmentStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		list, err := attachments.List(c.Request.Context(), scope, taskID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list attachments", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list attachments", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"attachments": list})
+	}
+}
+
+// DownloadAttachmentHandler redirects to a short-lived presigned URL for the
+// file.
+func DownloadAttachmentHandler(attachments store.AttachmentStore, files storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("attachmentId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+
+		attachment, err := attachments.Get(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) || (err == nil && attachment.ConfirmedAt == nil) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get attachment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get attachment", err))
+			return
+		}
+
+		url, err := files.PresignGet(c.Request.Context(), attachment.Key, attachment.Filename, downloadURLExpiry)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to presign download", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get attachment", err))
+			return
+		}
+
+		c.Redirect(http.StatusFound, url)
+	}
+}
+
+// DeleteAttachmentHandler lets the uploader remove an attachment. The row
+// goes first so a storage failure can only leave an orphaned object behind,
+// never an attachment whose file is gone.
+func DeleteAttachmentHandler(attachments store.AttachmentStore, files storage.Storage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("attachmentId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+
+		attachment, err := attachments.Get(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get attachment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete attachment", err))
+			return
+		}
+		if attachment.UploaderID != scope.UserID {
+			apierror.Respond(c, apierror.Forbidden("Only the uploader can delete this attachment"))
+			return
+		}
+
+		err = attachments.Delete(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Attachment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete attachment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete attachment", err))
+			return
+		}
+		if err := files.Delete(c.Request.Context(), attachment.Key); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete attachment file", "error", err)
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"sync"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// batchConcurrency caps how many reads of one batch run at once, so a
+// batch of 50 can't take 50 pool connections by itself.
+const batchConcurrency = 8
+
+// batchExcludedPaths can't be read in a batch: the batch itself, and the
+// streaming endpoints, which flush or hijack the connection and so can't
+// be answered into a recorder.
+var batchExcludedPaths = []string{"/batch", "/events", "/ws", "/export", "/time-entries/export"}
+
+const batchPathError = "Path must be a GET path under the API, other than /batch and the streaming endpoints"
+
+// batchDropHeaders are the caller's headers that describe the batch itself
+// rather than the reads in it.
+var batchDropHeaders = []string{"Content-Type", "Content-Length", "Accept-Encoding", "Idempotency-Key", "If-None-Match", "If-Match"}
+
+// BatchHandler runs up to 50 GET requests under root, the API group's base
+// path, through api and answers with all their responses at once. Each read
+// goes through the same middleware as if it were sent on its own, with the
+// caller's credentials, so it's authorized, rate limited and logged the
+// same way.
+func BatchHandler(api http.Handler, root string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := scopeFromContext(c); !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.BatchInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		header := c.Request.Header.Clone()
+		for _, name := range batchDropHeaders {
+			header.Del(name)
+		}
+
+		responses := make([]models.BatchResponse, len(input.Requests))
+		sem := make(chan struct{}, batchConcurrency)
+		var wg sync.WaitGroup
+		for i, r := range input.Requests {
+			target, ok := batchTarget(root, r.Path)
+			if !ok {
+				responses[i] = batchError(r.ID, http.StatusBadRequest, batchPathError)
+				continue
+			}
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				responses[i] = batchRead(c, api, header, r.ID, target)
+			}()
+		}
+		wg.Wait()
+
+		c.JSON(http.StatusOK, gin.H{"responses": responses})
+	}
+}
+
+// batchTarget is the request URI for p under root, or false when p isn't a
+// plain path: one with a host, dot segments or naming one of
+// batchExcludedPaths.
+func batchTarget(root, p string) (string, bool) {
+	u, err := url.Parse(p)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Fragment != "" || path.Clean(u.Path) != u.Path {
+		return "", false
+	}
+	if slices.Contains(batchExcludedPaths, u.Path) {
+		return "", false
+	}
+	target := root + u.EscapedPath()
+	if u.RawQuery != "" {
+		target += "?" + u.RawQuery
+	}
+	return target, true
+}
+
+func batchRead(c *gin.Context, api http.Handler, header http.Header, id, target string) models.BatchResponse {
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, target, nil)
+	if err != nil {
+		return batchError(id, http.StatusBadRequest, batchPathError)
+	}
+	req.Header = header.Clone()
+	req.RemoteAddr = c.Request.RemoteAddr
+	req.Host = c.Request.Host
+
+	w := &batchRecorder{header: http.Header{}}
+	api.ServeHTTP(w, req)
+
+	resp := models.BatchResponse{ID: id, Status: w.status}
+	if resp.Status == 0 {
+		resp.Status = http.StatusOK
+	}
+	if body := w.body.Bytes(); len(body) > 0 {
+		if json.Valid(body) {
+			resp.Body = body
+		} else {
+			// Text bodies, like exports, are sent as a JSON string.
+			resp.Body, _ = json.Marshal(string(body))
+		}
+	}
+	return resp
+}
+
+func batchError(id string, status int, message string) models.BatchResponse {
+	body, _ := json.Marshal(apierror.New(status, message).Body())
+	return models.BatchResponse{ID: id, Status: status, Body: body}
+}
+
+// batchRecorder keeps one read's response in memory.
+type batchRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *batchRecorder) Header() http.Header {
+	return w.header
+}
+
+func (w *batchRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+}
+
+func (w *batchRecorder) Write(b []byte) (int, error) {
+	w.WriteHeader(http.StatusOK)
+	return w.body.Write(b)
+}
@@ -0,0 +1,87 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBatchHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(gin.Recovery(), func(c *gin.Context) {
+		u := auth.User{ID: "user_1", OrgID: "org_1"}
+		c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), u))
+	})
+	// Stand-ins for the streaming routes, which need a writer that can
+	// flush or hijack.
+	stream := func(c *gin.Context) {
+		c.Writer.WriteString("retry: 3000\n\n")
+		c.Writer.Flush()
+	}
+	router.GET("/api/v1/events", stream)
+	router.GET("/api/v1/ws", func(c *gin.Context) {
+		if _, _, err := c.Writer.Hijack(); err != nil {
+			panic(err)
+		}
+	})
+	api := router.Group("/api/v1")
+	api.GET("/export", stream)
+	api.GET("/time-entries/export", stream)
+	api.GET("/tasks/:id", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"id": c.Param("id")})
+	})
+	api.POST("/batch", BatchHandler(router, api.BasePath()))
+
+	paths := []string{
+		"/tasks/abc",
+		"/events",
+		"/ws",
+		"/export",
+		"/export?format=csv",
+		"/time-entries/export",
+		"/batch",
+		"/tasks/../events",
+		"//evil.example/tasks",
+	}
+	var input models.BatchInput
+	for _, p := range paths {
+		input.Requests = append(input.Requests, models.BatchRequest{ID: p, Path: p})
+	}
+	body, _ := json.Marshal(input)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/batch", strings.NewReader(string(body))))
+	if w.Code != http.StatusOK {
+		t.Fatalf("batch = %d %s", w.Code, w.Body)
+	}
+
+	var out struct {
+		Responses []models.BatchResponse `json:"responses"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out.Responses) != len(paths) {
+		t.Fatalf("got %d responses, want %d", len(out.Responses), len(paths))
+	}
+	for i, resp := range out.Responses {
+		want := http.StatusBadRequest
+		if paths[i] == "/tasks/abc" {
+			want = http.StatusOK
+		}
+		if resp.ID != paths[i] || resp.Status != want {
+			t.Errorf("%s: status %d, want %d (body %s)", paths[i], resp.Status, want, resp.Body)
+		}
+	}
+	if got := string(out.Responses[0].Body); got != `{"id":"abc"}` {
+		t.Errorf("/tasks/abc body = %s", got)
+	}
+}
@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkBoardColumns writes the error response and returns false unless every
+// column maps to a different status of the workflow.
+func checkBoardColumns(c *gin.Context, workflow models.Workflow, columns []models.BoardColumnInput) bool {
+	seen := map[string]bool{}
+	for _, col := range columns {
+		if !workflow.IsValidStatus(col.Status) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid status: "+col.Status))
+			return false
+		}
+		if seen[col.Status] {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Each status can only have one column"))
+			return false
+		}
+		seen[col.Status] = true
+	}
+	return true
+}
+
+// workflowColumns gives a board one column per workflow status, as many as
+// fit.
+func workflowColumns(workflow models.Workflow) []models.BoardColumnInput {
+	columns := []models.BoardColumnInput{}
+	for _, s := range workflow.Statuses {
+		columns = append(columns, models.BoardColumnInput{Name: s.Name, Status: s.Key})
+	}
+	if len(columns) > models.MaxBoardColumns {
+		columns = columns[:models.MaxBoardColumns]
+	}
+	return columns
+}
+
+// CreateBoardHandler adds a board to the project. Without columns it gets
+// one per workflow status, in workflow order.
+func CreateBoardHandler(boards store.BoardStore, workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		projectID := c.Param("id")
+		if !isValidID(projectID) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		var input models.CreateBoardInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+		if len(input.Columns) == 0 {
+			input.Columns = workflowColumns(workflow)
+		}
+		if !checkBoardColumns(c, workflow, input.Columns) {
+			return
+		}
+
+		board, err := boards.Create(c.Request.Context(), scope, projectID, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create board", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create board", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, board)
+	}
+}
+
+func ListProjectBoardsHandler(boards store.BoardStore, projects store.ProjectStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		projectID := c.Param("id")
+		if !isValidID(projectID) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		if _, err := projects.Get(ctx, scope.OrgID, projectID); errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		} else if err != nil {
+			slog.ErrorContext(ctx, "Failed to get project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list boards", err))
+			return
+		}
+
+		list, err := boards.ListForProject(ctx, scope, projectID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list boards", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list boards", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"boards": list})
+	}
+}
+
+// GetBoardHandler returns the board with the tasks in each column.
+func GetBoardHandler(boards store.BoardStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+
+		board, err := boards.Cards(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get board", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get board", err))
+			return
+		}
+
+		now := time.Now()
+		for i := range board.Columns {
+			for j := range board.Columns[i].Tasks {
+				setDueToday(&board.Columns[i].Tasks[j], loc, now)
+			}
+		}
+
+		c.JSON(http.StatusOK, board)
+	}
+}
+
+// UpdateBoardHandler renames a board or replaces its columns. Cards follow
+// their task's status, so dropping a column only hides its tasks.
+func UpdateBoardHandler(boards store.BoardStore, workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+
+		var input models.UpdateBoardInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		if input.Columns != nil {
+			workflow, ok := loadWorkflow(c, workflows, scope)
+			if !ok {
+				return
+			}
+			if !checkBoardColumns(c, workflow, input.Columns) {
+				return
+			}
+		}
+
+		board, err := boards.Update(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update board", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update board", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, board)
+	}
+}
+
+func DeleteBoardHandler(boards store.BoardStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+
+		err := boards.Delete(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete board", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete board", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// MoveCardHandler moves a task to a column and position on the board in one
+// step. Moving it into a done column completes it the way an update would,
+// including rolling a recurring task over.
+func MoveCardHandler(boards store.BoardStore, tasks store.TaskStore, workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+
+		var input models.MoveCardInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if !isValidID(input.TaskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found on this board"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		board, err := boards.Get(ctx, scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Board not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get board", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to move card", err))
+			return
+		}
+		hasColumn := false
+		for _, col := range board.Columns {
+			hasColumn = hasColumn || col.ID == input.ColumnID
+		}
+		if !hasColumn {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Column not found on this board"))
+			return
+		}
+
+		move, err := boards.Move(ctx, scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found on this board"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Task is read-only"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to move card", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to move card", err))
+			return
+		}
+
+		task := &move.Task
+		if workflow.IsDone(task.Status) && !workflow.IsDone(move.PreviousStatus) {
+			next, err := MaterializeNextOccurrence(ctx, tasks, scope, workflow, task)
+			if quotaExceeded(c, err) {
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to create next occurrence", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create next occurrence", err))
+				return
+			}
+			if next != nil {
+				setDueToday(next, loc, time.Now())
+				task.NextOccurrence = next
+			}
+		}
+		setDueToday(task, loc, time.Now())
+
+		c.JSON(http.StatusOK, move)
+	}
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BulkTasksHandler applies up to 500 ops in one transaction. Ops that fail
+// validation or name a missing task are reported individually; the rest
+// still apply.
+func BulkTasksHandler(tasks store.TaskStore, projects store.ProjectStore, labels store.LabelStore, workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.BulkTaskInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		check := bulkChecker{c: c, projects: projects, labels: labels, scope: scope, workflow: workflow, projectErrs: map[string]string{}}
+		valid := []models.BulkTaskOp{}
+		rejected := map[int]string{}
+		for i, op := range input.Ops {
+			reason, err := check.op(op)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to validate bulk op", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to apply bulk operations", err))
+				return
+			}
+			if reason != "" {
+				rejected[i] = reason
+				continue
+			}
+			valid = append(valid, op)
+		}
+
+		applied, err := tasks.Bulk(c.Request.Context(), scope, valid, workflow.CompletedStatus(), workflow.DoneStatuses())
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to apply bulk operations", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to apply bulk operations", err))
+			return
+		}
+
+		results := make([]models.BulkTaskResult, 0, len(input.Ops))
+		for i, op := range input.Ops {
+			if reason, ok := rejected[i]; ok {
+				results = append(results, models.BulkTaskResult{TaskID: op.TaskID, Action: op.Action, Error: reason})
+				continue
+			}
+			results = append(results, applied[0])
+			applied = applied[1:]
+		}
+
+		// Completing in bulk spawns next occurrences just like a PATCH does.
+		for _, r := range results {
+			if r.Completed == nil {
+				continue
+			}
+			if _, err := MaterializeNextOccurrence(c.Request.Context(), tasks, scope, workflow, r.Completed); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to create next occurrence", "error", err)
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"results": results})
+	}
+}
+
+// bulkChecker validates ops up front, looking up each project and the
+// org's labels at most once per request.
+type bulkChecker struct {
+	c           *gin.Context
+	projects    store.ProjectStore
+	labels      store.LabelStore
+	scope       models.Scope
+	workflow    models.Workflow
+	projectErrs map[string]string
+	orgLabels   map[string]bool
+}
+
+// op returns why op can't be applied, or "" when it can.
+func (b *bulkChecker) op(op models.BulkTaskOp) (string, error) {
+	if !isValidID(op.TaskID) {
+		return "Task not found", nil
+	}
+
+	switch op.Action {
+	case models.BulkComplete:
+		if b.workflow.CompletedStatus() == "" {
+			return "Workflow has no done status", nil
+		}
+	case models.BulkMove:
+		if op.ProjectID != nil {
+			return b.project(*op.ProjectID)
+		}
+	case models.BulkRelabel:
+		if !b.scope.IsOrg() {
+			return "Labels require an active organization", nil
+		}
+		if b.orgLabels == nil {
+			list, err := b.labels.List(b.c.Request.Context(), b.scope.OrgID)
+			if err != nil {
+				return "", err
+			}
+			b.orgLabels = map[string]bool{}
+			for _, l := range list {
+				b.orgLabels[l.ID] = true
+			}
+		}
+		for _, id := range op.LabelIDs {
+			if !b.orgLabels[id] {
+				return "Label not found", nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (b *bulkChecker) project(id string) (string, error) {
+	if reason, ok := b.projectErrs[id]; ok {
+		return reason, nil
+	}
+
+	reason := ""
+	switch {
+	case !b.scope.IsOrg():
+		reason = "Projects require an active organization"
+	case !isValidID(id):
+		reason = "Project not found"
+	default:
+		project, err := b.projects.Get(b.c.Request.Context(), b.scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			reason = "Project not found"
+		} else if err != nil {
+			return "", err
+		} else if project.ArchivedAt != nil {
+			reason = "Project is archived"
+		}
+	}
+	b.projectErrs[id] = reason
+	return reason, nil
+}
@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/ical"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxFeedEvents bounds the feed for users with a great many due dates.
+	maxFeedEvents = 2000
+	// feedLookback is how long overdue tasks stay in the feed.
+	feedLookback = 365 * 24 * time.Hour
+)
+
+// CalendarFeedURLs builds the links a feed hands out.
+type CalendarFeedURLs struct {
+	// BaseURL is where the API is reachable from outside; without it URLs
+	// are relative to the API's own host.
+	BaseURL string
+	// AppURL, when set, links each event back to its task.
+	AppURL string
+}
+
+func (u CalendarFeedURLs) feed(token string) string {
+	return strings.TrimRight(u.BaseURL, "/") + "/feeds/" + token + "/tasks.ics"
+}
+
+func hashFeedToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func GetCalendarFeedHandler(feeds store.CalendarFeedStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		feed, err := feeds.Get(c.Request.Context(), scope)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Calendar feed not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get calendar feed", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get calendar feed", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, feed)
+	}
+}
+
+// RotateCalendarFeedHandler creates the feed for the current scope, or gives
+// it a new URL if there already is one. The URL is only shown here.
+func RotateCalendarFeedHandler(feeds store.CalendarFeedStore, urls CalendarFeedURLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		b := make([]byte, 32)
+		rand.Read(b)
+		token := base64.RawURLEncoding.EncodeToString(b)
+
+		feed, err := feeds.Rotate(c.Request.Context(), scope, hashFeedToken(token))
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create calendar feed", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create calendar feed", err))
+			return
+		}
+		feed.URL = urls.feed(token)
+
+		c.JSON(http.StatusCreated, feed)
+	}
+}
+
+func DeleteCalendarFeedHandler(feeds store.CalendarFeedStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		err := feeds.Delete(c.Request.Context(), scope)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Calendar feed not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete calendar feed", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete calendar feed", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// CalendarFeedStores is what the public feed reads from.
+type CalendarFeedStores struct {
+	Feeds     store.CalendarFeedStore
+	Users     store.UserStore
+	Tasks     store.TaskStore
+	Workflows store.WorkflowStore
+}
+
+// CalendarFeedHandler serves /feeds/:token/tasks.ics: the open tasks with a
+// due date that the feed's owner can see, as events at their due time. The
+// token in the path is the only credential, so it reads with the role the
+// owner holds in the org now, and stops working if they've left it.
+func CalendarFeedHandler(stores CalendarFeedStores, urls CalendarFeedURLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		feed, err := stores.Feeds.Lookup(ctx, hashFeedToken(c.Param("token")))
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Calendar feed not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to look up calendar feed", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to load calendar feed", err))
+			return
+		}
+
+		scope := feed.Scope()
+		if scope.IsOrg() {
+			member, err := stores.Users.GetMember(ctx, scope.OrgID, scope.UserID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Calendar feed not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get member", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to load calendar feed", err))
+				return
+			}
+			scope.Guest = member.Role == middlewares.OrgGuestRole
+		}
+
+		workflow, ok := loadWorkflow(c, stores.Workflows, scope)
+		if !ok {
+			return
+		}
+
+		since := time.Now().Add(-feedLookback)
+		filter := models.TaskFilter{
+			OpenOnly:     true,
+			DoneStatuses: workflow.DoneStatuses(),
+			DueAfter:     &since,
+			Sort:         []models.SortField{{Field: "due_date"}},
+		}
+		cal := ical.Calendar{Name: "yata"}
+		page := models.Page{Limit: api.MaxLimit}
+		for len(cal.Events) < maxFeedEvents {
+			list, err := stores.Tasks.List(ctx, scope, filter, page)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to list tasks", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to load calendar feed", err))
+				return
+			}
+			list, hasMore := api.Trim(list, page.Limit)
+			for i := range list {
+				cal.Events = append(cal.Events, taskEvent(&list[i], urls))
+			}
+			if !hasMore {
+				break
+			}
+			last := &list[len(list)-1]
+			page.After = []string{models.TaskSortValue(last, filter.Sort[0]), last.ID}
+		}
+		if len(cal.Events) > maxFeedEvents {
+			cal.Events = cal.Events[:maxFeedEvents]
+		}
+
+		var body bytes.Buffer
+		if err := cal.Write(&body); err != nil {
+			slog.ErrorContext(ctx, "Failed to write calendar feed", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to load calendar feed", err))
+			return
+		}
+		// Calendar apps poll; a few minutes' staleness is expected of them.
+		c.Header("Cache-Control", "private, max-age=300")
+		c.Data(http.StatusOK, "text/calendar; charset=utf-8", body.Bytes())
+	}
+}
+
+func taskEvent(t *models.Task, urls CalendarFeedURLs) ical.Event {
+	e := ical.Event{
+		UID:         t.ID + "@yata",
+		Summary:     t.Title,
+		Description: t.Description,
+		Start:       *t.DueDate,
+		Modified:    t.UpdatedAt,
+	}
+	if t.DueTimezone != nil {
+		e.TZID = *t.DueTimezone
+	}
+	if t.Recurrence != nil {
+		e.RRule = *t.Recurrence
+	}
+	if urls.AppURL != "" {
+		e.URL = strings.TrimRight(urls.AppURL, "/") + "/tasks/" + t.ID
+	}
+	return e
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/account"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/metrics"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBody is well above the size of any Clerk event.
+const maxWebhookBody = 1 << 20
+
+type clerkEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type clerkUser struct {
+	ID             string  `json:"id"`
+	FirstName      *string `json:"first_name"`
+	LastName       *string `json:"last_name"`
+	Username       *string `json:"username"`
+	ImageURL       *string `json:"image_url"`
+	PrimaryEmailID *string `json:"primary_email_address_id"`
+	EmailAddresses []struct {
+		ID           string `json:"id"`
+		EmailAddress string `json:"email_address"`
+	} `json:"email_addresses"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+func (u clerkUser) primaryEmail() *string {
+	for _, e := range u.EmailAddresses {
+		if u.PrimaryEmailID != nil && e.ID == *u.PrimaryEmailID {
+			return &e.EmailAddress
+		}
+	}
+	return nil
+}
+
+type clerkOrganization struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Slug     *string `json:"slug"`
+	ImageURL *string `json:"image_url"`
+	// PublicMetadata.Status suspends or reactivates the org; orgs without
+	// one keep whatever status they have.
+	PublicMetadata struct {
+		Status string `json:"status"`
+	} `json:"public_metadata"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// status is the org's status in its metadata, or empty if it doesn't have
+// a valid one.
+func (o clerkOrganization) status() string {
+	if models.ValidOrgStatus(o.PublicMetadata.Status) {
+		return o.PublicMetadata.Status
+	}
+	return ""
+}
+
+type clerkMembership struct {
+	Role         string `json:"role"`
+	Organization struct {
+		ID string `json:"id"`
+	} `json:"organization"`
+	PublicUserData struct {
+		UserID string `json:"user_id"`
+	} `json:"public_user_data"`
+	UpdatedAt int64 `json:"updated_at"`
+}
+
+// clerkDeleted is all a user.deleted or organization.deleted event carries.
+type clerkDeleted struct {
+	ID string `json:"id"`
+}
+
+// ClerkWebhookHandler mirrors Clerk's users, organizations and memberships
+// into the UserStore. A deleted user's data is erased, however they were
+// deleted. Failures return 500 so Svix redelivers; events we don't care
+// about are acknowledged and dropped.
+func ClerkWebhookHandler(users store.UserStore, eraser account.Eraser, verifier *webhooks.SvixVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBody))
+		if err != nil {
+			metrics.WebhookFailures.WithLabelValues("clerk", "invalid_body").Inc()
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+		if err := verifier.Verify(c.Request.Header, body); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Rejected Clerk webhook", "error", err)
+			metrics.WebhookFailures.WithLabelValues("clerk", "invalid_signature").Inc()
+			apierror.Respond(c, apierror.Unauthorized("Invalid signature"))
+			return
+		}
+
+		var event clerkEvent
+		if err := json.Unmarshal(body, &event); err != nil {
+			metrics.WebhookFailures.WithLabelValues("clerk", "invalid_body").Inc()
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		switch event.Type {
+		case "user.created", "user.updated":
+			var u clerkUser
+			if err = json.Unmarshal(event.Data, &u); err == nil {
+				err = users.UpsertUser(ctx, models.User{
+					ID:        u.ID,
+					Email:     u.primaryEmail(),
+					FirstName: u.FirstName,
+					LastName:  u.LastName,
+					Username:  u.Username,
+					ImageURL:  u.ImageURL,
+					UpdatedAt: time.UnixMilli(u.UpdatedAt).UTC(),
+				})
+			}
+		case "user.deleted":
+			var d clerkDeleted
+			if err = json.Unmarshal(event.Data, &d); err == nil {
+				err = eraser.Erase(ctx, d.ID)
+			}
+			if err == nil {
+				err = users.DeleteUser(ctx, d.ID)
+			}
+		case "organization.created", "organization.updated":
+			var o clerkOrganization
+			if err = json.Unmarshal(event.Data, &o); err == nil {
+				err = users.UpsertOrganization(ctx, models.Organization{
+					ID:        o.ID,
+					Name:      o.Name,
+					Slug:      o.Slug,
+					ImageURL:  o.ImageURL,
+					Status:    o.status(),
+					UpdatedAt: time.UnixMilli(o.UpdatedAt).UTC(),
+				})
+			}
+		case "organization.deleted":
+			var d clerkDeleted
+			if err = json.Unmarshal(event.Data, &d); err == nil {
+				err = users.DeleteOrganization(ctx, d.ID)
+			}
+		case "organizationMembership.created", "organizationMembership.updated":
+			var m clerkMembership
+			if err = json.Unmarshal(event.Data, &m); err == nil {
+				err = users.UpsertMembership(ctx, models.OrgMembership{
+					OrgID:     m.Organization.ID,
+					UserID:    m.PublicUserData.UserID,
+					Role:      m.Role,
+					UpdatedAt: time.UnixMilli(m.UpdatedAt).UTC(),
+				})
+			}
+		case "organizationMembership.deleted":
+			var m clerkMembership
+			if err = json.Unmarshal(event.Data, &m); err == nil {
+				err = users.DeleteMembership(ctx, m.Organization.ID, m.PublicUserData.UserID)
+			}
+		}
+
+		var syntaxErr *json.SyntaxError
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+			metrics.WebhookFailures.WithLabelValues("clerk", "invalid_event").Inc()
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid event data"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to apply Clerk webhook", "type", event.Type, "error", err)
+			metrics.WebhookFailures.WithLabelValues("clerk", "apply_failed").Inc()
+			apierror.Respond(c, apierror.Internal("Failed to apply event", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
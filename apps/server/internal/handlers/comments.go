@@ -0,0 +1,454 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/mentions"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindComment reads and validates a comment body and resolves its mentions,
+// writing the error response and returning false if it can't.
+func bindComment(c *gin.Context, directory mentions.Directory, scope models.Scope) (models.CommentInput, bool) {
+	var input models.CommentInput
+	if !bindJSON(c, &input) {
+		return input, false
+	}
+	input.Body = strings.TrimSpace(input.Body)
+	if input.Body == "" || utf8.RuneCountInString(input.Body) > models.MaxCommentLength {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid body"))
+		return input, false
+	}
+
+	// Only org members can be mentioned, so personal tasks have none.
+	input.Mentions = []string{}
+	if scope.IsOrg() {
+		ids, err := directory.Members(c.Request.Context(), scope.OrgID, mentions.Parse(input.Body))
+		if err != nil {
+			// The comment is still worth posting without its notifications.
+			slog.ErrorContext(c.Request.Context(), "Failed to resolve mentions", "error", err)
+		} else {
+			input.Mentions = ids
+		}
+	}
+	return input, true
+}
+
+// notifyMentions tells everyone mentioned in comment, other than its author
+// and anyone in already, about it.
+func notifyMentions(ctx context.Context, notifier notify.Notifier, tasks store.TaskStore, scope models.Scope, comment *models.Comment, already []string) {
+	recipients := []string{}
+	for _, id := range comment.Mentions {
+		if id != comment.AuthorID && !slices.Contains(already, id) {
+			recipients = append(recipients, id)
+		}
+	}
+	if len(recipients) == 0 {
+		return
+	}
+
+	task, err := tasks.Get(ctx, scope, comment.TaskID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get task for mention notifications", "error", err)
+		return
+	}
+	for _, id := range recipients {
+		err := notifier.Notify(ctx, notify.Notification{
+			Kind:    notify.KindCommentMention,
+			UserID:  id,
+			OrgID:   task.OrgID,
+			TaskID:  task.ID,
+			Title:   task.Title,
+			Body:    comment.Body,
+			ActorID: comment.AuthorID,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to notify mentioned user", "user_id", id, "error", err)
+		}
+	}
+}
+
+func CreateCommentHandler(comments store.CommentStore, tasks store.TaskStore, directory mentions.Directory, notifier notify.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		html, ok := renderHTML(c)
+		if !ok {
+			return
+		}
+		input, ok := bindComment(c, directory, scope)
+		if !ok {
+			return
+		}
+		if input.ParentID != nil {
+			if !isValidID(*input.ParentID) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Parent comment not found"))
+				return
+			}
+			parent, err := comments.Get(c.Request.Context(), scope, taskID, *input.ParentID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Parent comment not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get parent comment", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create comment", err))
+				return
+			}
+			// Threads are one level deep.
+			if parent.ParentID != nil {
+				input.ParentID = parent.ParentID
+			}
+		}
+
+		comment, err := comments.Create(c.Request.Context(), scope, taskID, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create comment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create comment", err))
+			return
+		}
+
+		notifyMentions(c.Request.Context(), notifier, tasks, scope, comment, nil)
+		if html {
+			setBodyHTML(comment)
+		}
+		c.JSON(http.StatusCreated, comment)
+	}
+}
+
+func ListCommentsHandler(comments store.CommentStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		html, ok := renderHTML(c)
+		if !ok {
+			return
+		}
+		page, err := api.ParsePage(c, "comments")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		list, err := comments.List(c.Request.Context(), scope, taskID, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list comments", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list comments", err))
+			return
+		}
+
+		// Pinned comments lead the first page, and stay in the thread too.
+		pinned := []models.Comment{}
+		if len(page.After) == 0 {
+			if pinned, err = comments.Pinned(c.Request.Context(), scope, taskID); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to list pinned comments", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to list comments", err))
+				return
+			}
+		}
+
+		list, hasMore := api.Trim(list, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			last := list[len(list)-1]
+			pageInfo.NextCursor = api.EncodeCursor("comments", last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+		}
+		if html {
+			for i := range list {
+				setBodyHTML(&list[i])
+			}
+			for i := range pinned {
+				setBodyHTML(&pinned[i])
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"pinned": pinned, "comments": list, "pageInfo": pageInfo})
+	}
+}
+
+// maxThreadComments caps how many comments the thread tree holds, replies
+// included; the rest is left to the paged listing.
+const maxThreadComments = 500
+
+// CommentTreeHandler returns the task's top-level comments with their
+// replies nested, all oldest first, in one go for small threads.
+func CommentTreeHandler(comments store.CommentStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		html, ok := renderHTML(c)
+		if !ok {
+			return
+		}
+
+		list, err := comments.List(c.Request.Context(), scope, taskID, models.Page{Limit: maxThreadComments})
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list comments", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list comments", err))
+			return
+		}
+		list, truncated := api.Trim(list, maxThreadComments)
+		if html {
+			for i := range list {
+				setBodyHTML(&list[i])
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"threads": commentThreads(list), "truncated": truncated})
+	}
+}
+
+// commentThreads nests replies under their top-level comments. list is
+// oldest first, so a parent comes before its replies; a reply whose parent
+// isn't in list is left out with it.
+func commentThreads(list []models.Comment) []models.CommentThread {
+	threads := []models.CommentThread{}
+	index := map[string]int{}
+	for _, comment := range list {
+		if comment.ParentID == nil {
+			index[comment.ID] = len(threads)
+			threads = append(threads, models.CommentThread{Comment: comment, Replies: []models.Comment{}})
+			continue
+		}
+		if i, ok := index[*comment.ParentID]; ok {
+			threads[i].Replies = append(threads[i].Replies, comment)
+		}
+	}
+	return threads
+}
+
+// authoredComment loads the comment and checks the caller wrote it, writing
+// the error response and returning nil if not.
+func authoredComment(c *gin.Context, comments store.CommentStore, scope models.Scope, taskID, id string) *models.Comment {
+	comment, err := comments.Get(c.Request.Context(), scope, taskID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.NotFound("Comment not found"))
+		return nil
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get comment", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to get comment", err))
+		return nil
+	}
+	if comment.AuthorID != scope.UserID {
+		apierror.Respond(c, apierror.Forbidden("Only the author can change this comment"))
+		return nil
+	}
+	return comment
+}
+
+func UpdateCommentHandler(comments store.CommentStore, tasks store.TaskStore, directory mentions.Directory, notifier notify.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("commentId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+
+		html, ok := renderHTML(c)
+		if !ok {
+			return
+		}
+		before := authoredComment(c, comments, scope, taskID, id)
+		if before == nil {
+			return
+		}
+
+		input, ok := bindComment(c, directory, scope)
+		if !ok {
+			return
+		}
+
+		comment, err := comments.Update(c.Request.Context(), scope, taskID, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update comment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update comment", err))
+			return
+		}
+
+		// People mentioned before the edit have already heard about it.
+		notifyMentions(c.Request.Context(), notifier, tasks, scope, comment, before.Mentions)
+		if html {
+			setBodyHTML(comment)
+		}
+		c.JSON(http.StatusOK, comment)
+	}
+}
+
+func DeleteCommentHandler(comments store.CommentStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("commentId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+
+		if authoredComment(c, comments, scope, taskID, id) == nil {
+			return
+		}
+
+		err := comments.Delete(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete comment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete comment", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func CommentHistoryHandler(comments store.CommentStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("commentId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+
+		revisions, err := comments.History(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get comment history", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get comment history", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"revisions": revisions})
+	}
+}
+
+// PinCommentHandler pins the comment to its task, or unpins it, for everyone
+// who can see the task. Org admins can pin on any task; anyone else has to
+// own the task or have commented on it.
+func PinCommentHandler(comments store.CommentStore, tasks store.TaskStore, pinned bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, err := auth.CurrentUser(c)
+		if err != nil {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+		scope := u.Scope()
+
+		taskID, id := c.Param("id"), c.Param("commentId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		task, err := tasks.Get(ctx, scope, taskID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+
+		allowed := task.OwnerID == scope.UserID || (scope.IsOrg() && middlewares.HasPermission(u.Role, middlewares.PermPinComments))
+		if !allowed {
+			if allowed, err = comments.Commented(ctx, scope, taskID, scope.UserID); err != nil {
+				slog.ErrorContext(ctx, "Failed to check comment authors", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to pin comment", err))
+				return
+			}
+		}
+		if !allowed {
+			apierror.Respond(c, apierror.Forbidden("Only admins and people taking part in the task can pin comments"))
+			return
+		}
+
+		comment, err := comments.SetPinned(ctx, scope, taskID, id, pinned)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Comment not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to pin comment", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to pin comment", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, comment)
+	}
+}
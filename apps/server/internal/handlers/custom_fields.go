@@ -0,0 +1,301 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxCustomFieldNameLength = 100
+
+// CustomFieldStores are what checking a task's custom field values needs:
+// the field definitions, and the org's members for user fields.
+type CustomFieldStores struct {
+	Fields store.CustomFieldStore
+	Users  store.UserStore
+}
+
+// loadCustomFields returns the scope's custom field definitions, writing the
+// error response and returning false if they can't be loaded. Personal
+// tasks have none.
+func loadCustomFields(c *gin.Context, fields store.CustomFieldStore, scope models.Scope) ([]models.CustomField, bool) {
+	if !scope.IsOrg() {
+		return nil, true
+	}
+	defs, err := fields.List(c.Request.Context(), scope.OrgID)
+	if err != nil {
+		apierror.Abort(c, apierror.Internal("Failed to load custom fields", err))
+		return nil, false
+	}
+	return defs, true
+}
+
+func findCustomField(defs []models.CustomField, key string) (models.CustomField, bool) {
+	i := slices.IndexFunc(defs, func(f models.CustomField) bool { return f.Key == key })
+	if i < 0 {
+		return models.CustomField{}, false
+	}
+	return defs[i], true
+}
+
+// checkCustomFields validates a task's custom field values against the
+// org's definitions, normalizing them in place. Null values clear a field
+// and are only allowed when clearing is. It writes the error response and
+// returns false if any value doesn't fit.
+func checkCustomFields(c *gin.Context, fields store.CustomFieldStore, users store.UserStore, scope models.Scope, values map[string]any, clearing bool) bool {
+	if len(values) == 0 {
+		return true
+	}
+	if !scope.IsOrg() {
+		apierror.Abort(c, apierror.Validation("Custom fields need an active organization"))
+		return false
+	}
+	defs, ok := loadCustomFields(c, fields, scope)
+	if !ok {
+		return false
+	}
+
+	ctx := c.Request.Context()
+	for key, value := range values {
+		def, ok := findCustomField(defs, key)
+		if !ok {
+			apierror.Abort(c, apierror.Validation("Unknown custom field: "+key))
+			return false
+		}
+		if value == nil {
+			if !clearing {
+				apierror.Abort(c, apierror.Validation("Invalid custom field: "+key+" can't be null"))
+				return false
+			}
+			continue
+		}
+
+		normalized, err := def.Normalize(value)
+		if err != nil {
+			apierror.Abort(c, apierror.Validation("Invalid custom field: "+err.Error()))
+			return false
+		}
+		if def.Type == models.CustomFieldUser {
+			_, err := users.GetMember(ctx, scope.OrgID, normalized.(string))
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Abort(c, apierror.Validation("Invalid custom field: "+key+" must be a member of the organization"))
+				return false
+			}
+			if err != nil {
+				apierror.Abort(c, apierror.Internal("Failed to check custom fields", err))
+				return false
+			}
+		}
+		values[key] = normalized
+	}
+	return true
+}
+
+// typeFieldFilters checks the filter's custom field terms against the
+// field definitions, converting each value to its field's type. "me" in a
+// user field is userID.
+func typeFieldFilters(filter *models.TaskFilter, defs []models.CustomField, userID string) error {
+	for i := range filter.Fields {
+		f := &filter.Fields[i]
+		def, ok := findCustomField(defs, f.Key)
+		if !ok {
+			return fmt.Errorf("unknown custom field %q", f.Key)
+		}
+		f.Type = def.Type
+		if f.Op != "=" && def.Type != models.CustomFieldNumber && def.Type != models.CustomFieldDate {
+			return fmt.Errorf("%s can only be compared with =", f.Key)
+		}
+
+		raw, _ := f.Value.(string)
+		var value any = raw
+		switch {
+		case def.Type == models.CustomFieldNumber:
+			n, err := strconv.ParseFloat(raw, 64)
+			if err != nil {
+				return fmt.Errorf("%s must be a number", f.Key)
+			}
+			value = n
+		case def.Type == models.CustomFieldUser && strings.EqualFold(raw, "me"):
+			value = userID
+		}
+		normalized, err := def.Normalize(value)
+		if err != nil {
+			return err
+		}
+		f.Value = normalized
+	}
+	return nil
+}
+
+// checkCustomFieldInput validates a field's name and options, writing the
+// error response and returning false if either is invalid.
+func checkCustomFieldInput(c *gin.Context, fieldType string, name *string, options []string) bool {
+	if name != nil {
+		*name = strings.TrimSpace(*name)
+		if *name == "" || len(*name) > maxCustomFieldNameLength {
+			apierror.Abort(c, apierror.Validation("Invalid name"))
+			return false
+		}
+	}
+	if err := models.CheckCustomFieldOptions(fieldType, options); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid options: "+err.Error()))
+		return false
+	}
+	return true
+}
+
+// CreateCustomFieldHandler defines a custom field for the org's tasks. The
+// key is what tasks and filters refer to the field by, and can't change.
+func CreateCustomFieldHandler(fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Abort(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateCustomFieldInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if !models.IsValidCustomFieldKey(input.Key) {
+			apierror.Abort(c, apierror.Validation("Invalid key"))
+			return
+		}
+		if !models.IsValidCustomFieldType(input.Type) {
+			apierror.Abort(c, apierror.Validation("Invalid type"))
+			return
+		}
+		if !checkCustomFieldInput(c, input.Type, &input.Name, input.Options) {
+			return
+		}
+
+		defs, ok := loadCustomFields(c, fields, scope)
+		if !ok {
+			return
+		}
+		if len(defs) >= models.MaxCustomFields {
+			apierror.Abort(c, apierror.Validation(fmt.Sprintf("An organization can have at most %d custom fields", models.MaxCustomFields)))
+			return
+		}
+
+		field, err := fields.Create(c.Request.Context(), scope.OrgID, scope.UserID, input)
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Abort(c, apierror.Conflict("A custom field with this key already exists"))
+			return
+		}
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to create custom field", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, field)
+	}
+}
+
+func ListCustomFieldsHandler(fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Abort(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := fields.List(c.Request.Context(), scope.OrgID)
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to list custom fields", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"customFields": list})
+	}
+}
+
+// UpdateCustomFieldHandler renames a field or replaces a select field's
+// options. Tasks set to an option that's dropped lose their value.
+func UpdateCustomFieldHandler(fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Abort(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Abort(c, apierror.NotFound("Custom field not found"))
+			return
+		}
+
+		var input models.UpdateCustomFieldInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		if input.Options != nil {
+			defs, ok := loadCustomFields(c, fields, scope)
+			if !ok {
+				return
+			}
+			i := slices.IndexFunc(defs, func(f models.CustomField) bool { return f.ID == id })
+			if i < 0 {
+				apierror.Abort(c, apierror.NotFound("Custom field not found"))
+				return
+			}
+			if !checkCustomFieldInput(c, defs[i].Type, input.Name, *input.Options) {
+				return
+			}
+		} else if !checkCustomFieldInput(c, "", input.Name, nil) {
+			return
+		}
+
+		field, err := fields.Update(c.Request.Context(), scope.OrgID, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Abort(c, apierror.NotFound("Custom field not found"))
+			return
+		}
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to update custom field", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, field)
+	}
+}
+
+// DeleteCustomFieldHandler removes a field and its values from every task.
+func DeleteCustomFieldHandler(fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Abort(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Abort(c, apierror.NotFound("Custom field not found"))
+			return
+		}
+
+		err := fields.Delete(c.Request.Context(), scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Abort(c, apierror.NotFound("Custom field not found"))
+			return
+		}
+		if err != nil {
+			apierror.Abort(c, apierror.Internal("Failed to delete custom field", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PprofHandler serves net/http/pprof under /debug/pprof/*name. The index
+// also serves the named profiles (heap, goroutine, allocs, block, ...).
+func PprofHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Param("name") {
+		case "/cmdline":
+			pprof.Cmdline(c.Writer, c.Request)
+		case "/profile":
+			pprof.Profile(c.Writer, c.Request)
+		case "/symbol":
+			pprof.Symbol(c.Writer, c.Request)
+		case "/trace":
+			pprof.Trace(c.Writer, c.Request)
+		default:
+			pprof.Index(c.Writer, c.Request)
+		}
+	}
+}
+
+// GoroutineDumpHandler writes every goroutine's full stack as text, the
+// same as a SIGQUIT would without killing the process.
+func GoroutineDumpHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Content-Type", "text/plain; charset=utf-8")
+		c.Status(http.StatusOK)
+		rpprof.Lookup("goroutine").WriteTo(c.Writer, 2)
+	}
+}
+
+// HeapDumpHandler collects garbage and then sends a heap profile to open
+// with `go tool pprof`.
+func HeapDumpHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		runtime.GC()
+
+		name := fmt.Sprintf("heap-%s.pb.gz", time.Now().UTC().Format("20060102T150405Z"))
+		c.Header("Content-Type", "application/octet-stream")
+		c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+		c.Status(http.StatusOK)
+		rpprof.Lookup("heap").WriteTo(c.Writer, 0)
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetEmailPreferencesHandler(prefs store.EmailPreferenceStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		p, err := prefs.Get(c.Request.Context(), scope.UserID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get email preferences", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get email preferences", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, p)
+	}
+}
+
+func UpdateEmailPreferencesHandler(prefs store.EmailPreferenceStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.UpdateEmailPreferencesInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		p, err := prefs.Update(c.Request.Context(), scope.UserID, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update email preferences", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update email preferences", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, p)
+	}
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+func taskETag(t *models.Task) string {
+	return `"` + strconv.Itoa(t.Version) + `"`
+}
+
+func setTaskETag(c *gin.Context, t *models.Task) {
+	c.Header("ETag", taskETag(t))
+}
+
+// ifMatchVersion reads the task version a write is conditional on from
+// If-Match. "*" matches any version and yields nil. A missing or malformed
+// header has already been answered with 428 or 412.
+func ifMatchVersion(c *gin.Context) (*int, bool) {
+	raw := strings.TrimSpace(c.GetHeader("If-Match"))
+	if raw == "" {
+		apierror.Respond(c, apierror.New(http.StatusPreconditionRequired, "If-Match header is required"))
+		return nil, false
+	}
+	if raw == "*" {
+		return nil, true
+	}
+
+	// Weak tags are accepted too; proxies sometimes weaken them.
+	tag := strings.Trim(strings.TrimPrefix(raw, "W/"), `"`)
+	version, err := strconv.Atoi(tag)
+	if err != nil {
+		apierror.Respond(c, apierror.New(http.StatusPreconditionFailed, "Task has been modified"))
+		return nil, false
+	}
+	return &version, true
+}
+
+// writeListJSON responds with a list page and a weak ETag for it, or with
+// 304 Not Modified when If-None-Match already has that tag, so clients that
+// poll a list only download it when it changed. The tag hashes the page as
+// it would be sent, so anything that changes the response, labels and due
+// today included, changes the tag; a max(updated_at) would miss those.
+func writeListJSON(c *gin.Context, body any) {
+	b, err := json.Marshal(body)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to encode response", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to encode response", err))
+		return
+	}
+	sum := sha256.Sum256(b)
+	tag := `W/"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+
+	c.Header("ETag", tag)
+	// Caches may keep it, but only for this user and only after asking.
+	c.Header("Cache-Control", "private, no-cache")
+	if noneMatch(c.GetHeader("If-None-Match"), tag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(http.StatusOK, "application/json; charset=utf-8", b)
+}
+
+// noneMatch reports whether an If-None-Match header lists tag, compared
+// weakly as RFC 9110 has it for GET.
+func noneMatch(header, tag string) bool {
+	tag = strings.TrimPrefix(tag, "W/")
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == tag {
+			return true
+		}
+	}
+	return false
+}
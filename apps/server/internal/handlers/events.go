@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/events"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventsKeepAlive is how often an idle stream sends a comment line, short
+// enough that proxies and load balancers don't close it as idle.
+const eventsKeepAlive = 15 * time.Second
+
+// EventsHandler streams task events for the caller's active org (or their
+// personal tasks) as Server-Sent Events, leaving out those of tasks the
+// caller can't see.
+func EventsHandler(broker *events.Broker, access events.Access) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		stream, unsubscribe := broker.Subscribe(events.Topic(scope))
+		defer unsubscribe()
+
+		header := c.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		// Stops nginx from buffering the stream.
+		header.Set("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		// Tell EventSource to wait a few seconds before reconnecting.
+		fmt.Fprint(c.Writer, "retry: 3000\n\n")
+		c.Writer.Flush()
+
+		ticker := time.NewTicker(eventsKeepAlive)
+		defer ticker.Stop()
+
+		ctx := c.Request.Context()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				fmt.Fprint(c.Writer, ": ping\n\n")
+			case e, ok := <-stream:
+				if !ok {
+					// The server is shutting down; EventSource reconnects
+					// to another instance.
+					return
+				}
+				if !events.Allowed(ctx, access, scope, e) {
+					continue
+				}
+				data, err := json.Marshal(e)
+				if err != nil {
+					slog.ErrorContext(c.Request.Context(), "Failed to encode event", "error", err)
+					continue
+				}
+				fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", e.Type, data)
+			}
+			c.Writer.Flush()
+		}
+	}
+}
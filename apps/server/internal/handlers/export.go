@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportSort pages through tasks oldest first, so the file reads in the
+// order the tasks were made.
+var exportSort = []models.SortField{{Field: "created_at"}}
+
+var exportColumns = []string{
+	"id", "title", "description", "status", "priority", "due_date", "due_timezone", "recurrence",
+	"project", "labels", "comments", "parent_id", "visibility", "archived_at", "created_at", "updated_at",
+}
+
+// ExportedTask is one task in an export, with what it'd otherwise take
+// more requests to put together.
+type ExportedTask struct {
+	models.Task
+	ProjectName  *string `json:"projectName"`
+	CommentCount int     `json:"commentCount"`
+}
+
+type ExportStores struct {
+	Tasks    store.TaskStore
+	Projects store.ProjectStore
+	Labels   store.LabelStore
+	Comments store.CommentStore
+}
+
+// ExportTasksHandler streams every task in scope, archived ones included, as
+// CSV or a JSON array. Tasks are read and written a page at a time, and each
+// page is flushed, so large exports go out chunked rather than buffered.
+func ExportTasksHandler(stores ExportStores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		format := c.DefaultQuery("format", "csv")
+		if format != "csv" && format != "json" {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid format: use csv or json"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		export := taskExport{stores: stores, scope: scope}
+		if err := export.loadProjects(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to list projects", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to export tasks", err))
+			return
+		}
+		// The first page is read before anything is written, so a failure
+		// there can still get a proper error response.
+		page, err := export.next(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to export tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to export tasks", err))
+			return
+		}
+
+		name := fmt.Sprintf("tasks-%s.%s", time.Now().UTC().Format("20060102"), format)
+		c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+		c.Header("X-Accel-Buffering", "no")
+
+		var w exportWriter
+		if format == "csv" {
+			c.Header("Content-Type", "text/csv; charset=utf-8")
+			w = &csvExportWriter{w: csv.NewWriter(c.Writer)}
+		} else {
+			c.Header("Content-Type", "application/json; charset=utf-8")
+			w = &jsonExportWriter{w: c.Writer}
+		}
+		c.Status(http.StatusOK)
+
+		if err := w.begin(); err != nil {
+			return
+		}
+		for len(page) > 0 {
+			for i := range page {
+				if err := w.write(&page[i]); err != nil {
+					// The client went away; there's no one to tell.
+					return
+				}
+			}
+			if err := w.flush(); err != nil {
+				return
+			}
+			c.Writer.Flush()
+
+			if page, err = export.next(ctx); err != nil {
+				// The status is long gone, so all that's left is to stop;
+				// a JSON export cut short won't parse.
+				slog.ErrorContext(ctx, "Failed to export tasks", "error", err)
+				return
+			}
+		}
+		if err := w.end(); err != nil {
+			return
+		}
+		c.Writer.Flush()
+	}
+}
+
+// taskExport pages through the tasks in scope, filling in what
+// ExportedTask adds.
+type taskExport struct {
+	stores   ExportStores
+	scope    models.Scope
+	projects map[string]string
+	after    []string
+	done     bool
+}
+
+// loadProjects reads the org's project names up front; there are few enough
+// of them, and tasks pick them up by id.
+func (e *taskExport) loadProjects(ctx context.Context) error {
+	var err error
+	e.projects, err = projectNames(ctx, e.stores.Projects, e.scope)
+	return err
+}
+
+// projectNames maps the id of every project in the scope's org, archived
+// ones included, to its name. Guests don't get to see projects.
+func projectNames(ctx context.Context, projects store.ProjectStore, scope models.Scope) (map[string]string, error) {
+	names := map[string]string{}
+	if !scope.IsOrg() || scope.Guest {
+		return names, nil
+	}
+	page := models.Page{Limit: api.MaxLimit}
+	for {
+		list, err := projects.List(ctx, scope.OrgID, true, page)
+		if err != nil {
+			return nil, err
+		}
+		list, hasMore := api.Trim(list, page.Limit)
+		for _, p := range list {
+			names[p.ID] = p.Name
+		}
+		if !hasMore {
+			return names, nil
+		}
+		last := list[len(list)-1]
+		page.After = []string{last.Name, last.ID}
+	}
+}
+
+// next returns the next page of tasks, or none once they've all been read.
+func (e *taskExport) next(ctx context.Context) ([]ExportedTask, error) {
+	if e.done {
+		return nil, nil
+	}
+
+	filter := models.TaskFilter{IncludeArchived: true, Sort: exportSort}
+	page := models.Page{Limit: api.MaxLimit, After: e.after}
+	list, err := e.stores.Tasks.List(ctx, e.scope, filter, page)
+	if err != nil {
+		return nil, err
+	}
+	list, hasMore := api.Trim(list, page.Limit)
+	if len(list) == 0 {
+		e.done = true
+		return nil, nil
+	}
+	if err := loadTaskLabels(ctx, e.stores.Labels, e.scope, list); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(list))
+	for i, t := range list {
+		ids[i] = t.ID
+	}
+	counts, err := e.stores.Comments.Counts(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]ExportedTask, len(list))
+	for i, t := range list {
+		exported[i] = ExportedTask{Task: t, CommentCount: counts[t.ID]}
+		if t.ProjectID != nil {
+			if name, ok := e.projects[*t.ProjectID]; ok {
+				exported[i].ProjectName = &name
+			}
+		}
+	}
+
+	last := &list[len(list)-1]
+	e.after = []string{models.TaskSortValue(last, exportSort[0]), last.ID}
+	e.done = !hasMore
+	return exported, nil
+}
+
+type exportWriter interface {
+	begin() error
+	write(t *ExportedTask) error
+	// flush pushes out what's been written so far.
+	flush() error
+	end() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+func (w *csvExportWriter) begin() error {
+	return w.w.Write(exportColumns)
+}
+
+func (w *csvExportWriter) write(t *ExportedTask) error {
+	labels := make([]string, len(t.Labels))
+	for i, l := range t.Labels {
+		labels[i] = l.Name
+	}
+	return w.w.Write([]string{
+		t.ID,
+		csvText(t.Title),
+		csvText(t.Description),
+		t.Status,
+		strconv.Itoa(t.Priority),
+		csvTime(t.DueDate),
+		deref(t.DueTimezone),
+		deref(t.Recurrence),
+		csvText(deref(t.ProjectName)),
+		csvText(strings.Join(labels, ", ")),
+		strconv.Itoa(t.CommentCount),
+		deref(t.ParentID),
+		t.Visibility,
+		csvTime(t.ArchivedAt),
+		t.CreatedAt.Format(time.RFC3339),
+		t.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+func (w *csvExportWriter) flush() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *csvExportWriter) end() error {
+	return w.flush()
+}
+
+// csvText keeps user text from being read as a formula when the file is
+// opened in a spreadsheet. encoding/csv takes care of quoting.
+func csvText(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+func csvTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+type jsonExportWriter struct {
+	w       http.ResponseWriter
+	written bool
+}
+
+func (w *jsonExportWriter) begin() error {
+	_, err := w.w.Write([]byte("["))
+	return err
+}
+
+func (w *jsonExportWriter) write(t *ExportedTask) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	if w.written {
+		data = append([]byte(",\n"), data...)
+	}
+	w.written = true
+	_, err = w.w.Write(data)
+	return err
+}
+
+func (w *jsonExportWriter) flush() error {
+	return nil
+}
+
+func (w *jsonExportWriter) end() error {
+	_, err := w.w.Write([]byte("]\n"))
+	return err
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/flags"
+
+	"github.com/gin-gonic/gin"
+)
+
+// flagTarget checks a scope and the org or user id it goes with.
+func flagTarget(c *gin.Context, scope, targetID string) bool {
+	if !flags.ValidScope(scope) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "scope must be global, org or user"))
+		return false
+	}
+	if (scope == flags.ScopeGlobal) != (targetID == "") {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "targetId is required for org and user flags, and not allowed for global ones"))
+		return false
+	}
+	return true
+}
+
+func ListFlagsHandler(f *flags.Flags) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		list, err := f.List(c.Request.Context())
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list feature flags", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list flags", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"flags": list})
+	}
+}
+
+// SetFlagHandler turns a flag on or off globally, for an org or for a user.
+func SetFlagHandler(f *flags.Flags) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Param("key")
+		if !flags.ValidKey(key) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid flag key"))
+			return
+		}
+
+		var input flags.SetInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if !flagTarget(c, input.Scope, input.TargetID) {
+			return
+		}
+
+		flag, err := f.Set(c.Request.Context(), flags.Flag{Key: key, Scope: input.Scope, TargetID: input.TargetID, Enabled: *input.Enabled})
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to set feature flag", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to set flag", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, flag)
+	}
+}
+
+// DeleteFlagHandler removes the setting named by the scope and targetId
+// query parameters, leaving the flag to its less specific settings.
+func DeleteFlagHandler(f *flags.Flags) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.Param("key")
+		scope := c.DefaultQuery("scope", flags.ScopeGlobal)
+		targetID := c.Query("targetId")
+		if !flagTarget(c, scope, targetID) {
+			return
+		}
+
+		err := f.Delete(c.Request.Context(), key, scope, targetID)
+		if errors.Is(err, flags.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Flag not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete feature flag", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete flag", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
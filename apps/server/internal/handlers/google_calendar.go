@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/gcal"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConnectGoogleCalendarHandler returns the Google consent screen to send
+// the user to. The API is called with a bearer token, so the client does the
+// redirect itself; Google then sends the user to the callback.
+func ConnectGoogleCalendarHandler(client *gcal.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"url": client.AuthURL(client.State(scope, time.Now()))})
+	}
+}
+
+// GoogleCalendarCallbackHandler finishes the OAuth flow Google redirects
+// back to, then sends the user to the app's integration settings with
+// googleCalendar=connected or googleCalendar=error. Without an app URL it
+// answers in JSON instead.
+func GoogleCalendarCallbackHandler(client *gcal.Client, connections store.GoogleCalendarStore, appURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fail := func(status int, msg string) {
+			if appURL == "" {
+				apierror.Respond(c, apierror.New(status, msg))
+				return
+			}
+			c.Redirect(http.StatusFound, strings.TrimRight(appURL, "/")+"/settings/integrations?googleCalendar=error")
+		}
+
+		scope, err := client.VerifyState(c.Query("state"), time.Now())
+		if err != nil {
+			fail(http.StatusBadRequest, "Invalid or expired state")
+			return
+		}
+		// Set when the user declined on the consent screen.
+		if c.Query("error") != "" || c.Query("code") == "" {
+			fail(http.StatusBadRequest, "Google Calendar access was not granted")
+			return
+		}
+
+		ctx := c.Request.Context()
+		token, err := client.Exchange(ctx, c.Query("code"))
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to exchange Google authorization code", "error", err)
+			fail(http.StatusBadGateway, "Failed to connect Google Calendar")
+			return
+		}
+		if token.RefreshToken == "" {
+			slog.ErrorContext(ctx, "Google sent no refresh token")
+			fail(http.StatusBadGateway, "Failed to connect Google Calendar")
+			return
+		}
+		email, err := client.Email(ctx, token.AccessToken)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get Google account", "error", err)
+			fail(http.StatusBadGateway, "Failed to connect Google Calendar")
+			return
+		}
+
+		conn, err := connections.Connect(ctx, scope, email, token)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to save Google Calendar connection", "error", err)
+			fail(http.StatusInternalServerError, "Failed to connect Google Calendar")
+			return
+		}
+
+		if appURL == "" {
+			c.JSON(http.StatusCreated, conn)
+			return
+		}
+		c.Redirect(http.StatusFound, strings.TrimRight(appURL, "/")+"/settings/integrations?googleCalendar=connected")
+	}
+}
+
+func GetGoogleCalendarHandler(connections store.GoogleCalendarStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		conn, err := connections.Get(c.Request.Context(), scope)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Google Calendar is not connected"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get Google Calendar connection", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get Google Calendar connection", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, conn)
+	}
+}
+
+// DisconnectGoogleCalendarHandler stops the sync and revokes yata's access
+// to the calendar. The events already made stay on it.
+func DisconnectGoogleCalendarHandler(client *gcal.Client, connections store.GoogleCalendarStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		conn, err := connections.Get(ctx, scope)
+		if err == nil {
+			err = connections.Delete(ctx, scope)
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Google Calendar is not connected"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to delete Google Calendar connection", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to disconnect Google Calendar", err))
+			return
+		}
+
+		// The connection is gone either way; a failed revoke only leaves the
+		// grant listed on the user's Google account.
+		if err := client.Revoke(ctx, conn.Token.RefreshToken); err != nil {
+			slog.WarnContext(ctx, "Failed to revoke Google Calendar access", "error", err)
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/graph"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/extension"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/gin-gonic/gin"
+)
+
+// graphQLComplexityLimit caps the number of fields one query may select,
+// so a deeply nested query can't fan out into thousands of store calls.
+const graphQLComplexityLimit = 500
+
+// GraphQLHandler serves the GraphQL schema as the authenticated user,
+// with the same scope the REST handlers would use.
+func GraphQLHandler(resolver *graph.Resolver) gin.HandlerFunc {
+	srv := handler.New(graph.NewExecutableSchema(graph.Config{Resolvers: resolver}))
+	srv.AddTransport(transport.GET{})
+	srv.AddTransport(transport.POST{})
+	srv.Use(extension.Introspection{})
+	srv.Use(extension.FixedComplexityLimit(graphQLComplexityLimit))
+
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		srv.ServeHTTP(c.Writer, c.Request.WithContext(resolver.WithScope(c.Request.Context(), scope)))
+	}
+}
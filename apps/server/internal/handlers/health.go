@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+	"yata/apps/server/internal/version"
+
+	"github.com/gin-gonic/gin"
+)
+
+// readinessTimeout bounds each check so a hung dependency fails the probe
+// instead of stalling it.
+const readinessTimeout = 2 * time.Second
+
+// ReadinessCheck reports whether a dependency can serve traffic.
+type ReadinessCheck func(ctx context.Context) error
+
+// HealthzHandler is the liveness probe: it only says the process is up and
+// serving, so a database outage doesn't get every pod restarted.
+func HealthzHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "ok"})
+	}
+}
+
+// ReadyzHandler runs every check concurrently and answers 503 when any of
+// them fails, taking the instance out of the load balancer.
+func ReadyzHandler(checks map[string]ReadinessCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+		defer cancel()
+
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		results := map[string]string{}
+		ready := true
+		for name, check := range checks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result := "ok"
+				if err := check(ctx); err != nil {
+					result = err.Error()
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				results[name] = result
+				ready = ready && result == "ok"
+			}()
+		}
+		wg.Wait()
+
+		if !ready {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": results})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"status": "ok", "checks": results})
+	}
+}
+
+func VersionHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, version.Get())
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"regexp"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/validation"
+
+	"github.com/gin-gonic/gin"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+func isValidID(id string) bool {
+	return uuidPattern.MatchString(id)
+}
+
+// bindJSON decodes and validates the request body into obj. If it doesn't
+// fit, the 400 lists each field that didn't and why.
+func bindJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		apierror.Abort(c, apierror.Validation("Invalid request body").WithDetails(validation.Fields(err)))
+		return false
+	}
+	return true
+}
+
+// bindOptionalJSON is bindJSON for bodies that can be left out altogether,
+// leaving obj as it was.
+func bindOptionalJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Abort(c, apierror.Validation("Invalid request body").WithDetails(validation.Fields(err)))
+		return false
+	}
+	return true
+}
+
+// invalidField answers 400 for a field that bound but isn't usable.
+func invalidField(c *gin.Context, field, message string) {
+	apierror.Abort(c, apierror.Validation("Invalid request body").WithDetails([]validation.FieldError{{Field: field, Error: message}}))
+}
+
+// scopeFromContext is the scope of the request's user, as set by the
+// CurrentUser middleware.
+func scopeFromContext(c *gin.Context) (models.Scope, bool) {
+	u, err := auth.CurrentUser(c)
+	if err != nil {
+		return models.Scope{}, false
+	}
+	return u.Scope(), true
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"path/filepath"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/importers"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxImportSize caps the uploaded export.
+const maxImportSize = 10 << 20
+
+// CreateImportHandler takes an export uploaded as the multipart "file"
+// field, with "source" naming the tool it came from. The file is read here
+// so a bad one is rejected straight away; the tasks are created by the job
+// worker, and GET /import/:id follows along.
+func CreateImportHandler(imports store.ImportStore, queue jobs.Queue) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		upload, ok := readImport(c)
+		if !ok {
+			return
+		}
+		input := upload.input
+		input.Total = upload.report.Valid + len(upload.report.Errors)
+
+		imp, err := imports.Create(c.Request.Context(), scope, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create import", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to start import", err))
+			return
+		}
+		if err := importers.Enqueue(c.Request.Context(), queue, imp.ID); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to queue import", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to start import", err))
+			return
+		}
+
+		c.JSON(http.StatusAccepted, imp)
+	}
+}
+
+// ValidateImportHandler reads an upload the way CreateImportHandler does
+// and reports what importing it would do, without importing anything.
+func ValidateImportHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := scopeFromContext(c); !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		upload, ok := readImport(c)
+		if !ok {
+			return
+		}
+		c.JSON(http.StatusOK, upload.report)
+	}
+}
+
+// importUpload is an uploaded export, read.
+type importUpload struct {
+	input  models.CreateImportInput
+	report models.ImportReport
+}
+
+// readImport reads the upload of an import request and the export in it
+// with importers.Read, answering the request itself when either is bad.
+func readImport(c *gin.Context) (importUpload, bool) {
+	loc, ok := requestTimezone(c)
+	if !ok {
+		return importUpload{}, false
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize+1<<20)
+	source := c.PostForm("source")
+	if !models.ValidImportSource(source) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid source: use todoist, trello or asana"))
+		return importUpload{}, false
+	}
+	header, err := c.FormFile("file")
+	if err != nil {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "A file is required"))
+		return importUpload{}, false
+	}
+	if header.Size > maxImportSize {
+		apierror.Respond(c, apierror.New(http.StatusRequestEntityTooLarge, "File is too large"))
+		return importUpload{}, false
+	}
+	file, err := header.Open()
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to open upload", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to read file", err))
+		return importUpload{}, false
+	}
+	defer file.Close()
+	data, err := io.ReadAll(file)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to read upload", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to read file", err))
+		return importUpload{}, false
+	}
+
+	input := models.CreateImportInput{Source: source, FileName: filepath.Base(header.Filename), Data: data}
+	if loc != nil {
+		name := loc.String()
+		input.Timezone = &name
+	} else {
+		loc = time.UTC
+	}
+	_, report, err := importers.Read(source, data, loc)
+	if errors.Is(err, importers.ErrTooManyTasks) {
+		apierror.Respond(c, apierror.New(http.StatusRequestEntityTooLarge, "File has too many tasks"))
+		return importUpload{}, false
+	}
+	if err != nil {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid file: "+err.Error()))
+		return importUpload{}, false
+	}
+	return importUpload{input: input, report: report}, true
+}
+
+func GetImportHandler(imports store.ImportStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Import not found"))
+			return
+		}
+
+		imp, err := imports.Get(c.Request.Context(), scope.UserID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Import not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get import", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get import", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, imp)
+	}
+}
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/inbound"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxInboundEmail is above the 25MB providers accept, for the encoding.
+const maxInboundEmail = 40 << 20
+
+// inboundTokenEncoding keeps addresses lowercase, since mail servers are
+// free to change the case of the local part.
+var inboundTokenEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+func withAddress(a models.InboundAddress, domain string) models.InboundAddress {
+	a.Address = a.Token + "@" + domain
+	return a
+}
+
+func ListInboundAddressesHandler(addresses store.InboundAddressStore, domain string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := addresses.List(c.Request.Context(), scope)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list inbound addresses", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list inbound addresses", err))
+			return
+		}
+		for i := range list {
+			list[i] = withAddress(list[i], domain)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"addresses": list})
+	}
+}
+
+// RotateInboundAddressHandler creates the address for the scope, or for a
+// project with projectId, replacing any earlier one so it stops working.
+func RotateInboundAddressHandler(addresses store.InboundAddressStore, projects store.ProjectStore, domain string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.RotateInboundAddressInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.ProjectID != nil && !checkTaskProject(c, projects, scope, *input.ProjectID) {
+			return
+		}
+
+		b := make([]byte, 10)
+		if _, err := rand.Read(b); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to generate inbound address", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create inbound address", err))
+			return
+		}
+
+		address, err := addresses.Rotate(c.Request.Context(), scope, input.ProjectID, inboundTokenEncoding.EncodeToString(b))
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to save inbound address", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create inbound address", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, withAddress(*address, domain))
+	}
+}
+
+func DeleteInboundAddressHandler(addresses store.InboundAddressStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Inbound address not found"))
+			return
+		}
+
+		err := addresses.Delete(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Inbound address not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete inbound address", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete inbound address", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// InboundEmailStores is what turning an email into a task reads and writes.
+type InboundEmailStores struct {
+	Addresses   store.InboundAddressStore
+	Users       store.UserStore
+	Tasks       store.TaskStore
+	Projects    store.ProjectStore
+	Workflows   store.WorkflowStore
+	OrgSettings store.OrgSettingsStore
+	Attachments store.AttachmentStore
+}
+
+// InboundEmail turns received email into tasks. Files is nil without
+// object storage, and attachments are then dropped.
+type InboundEmail struct {
+	Domain string
+	Stores InboundEmailStores
+	Files  storage.Storage
+	Limits AttachmentLimits
+}
+
+// MailgunInboundHandler receives the messages a Mailgun route forwards.
+// Mailgun retries anything but a 2xx or 406, so mail that can never become a
+// task, such as to an unknown address, is refused with 406.
+func MailgunInboundHandler(signingKey string, in InboundEmail) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxInboundEmail)
+		email, err := inbound.ParseMailgun(c.Request, signingKey, time.Now())
+		if errors.Is(err, inbound.ErrInvalidSignature) {
+			apierror.Respond(c, apierror.Unauthorized("Invalid signature"))
+			return
+		}
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+		if c.Request.MultipartForm != nil {
+			defer c.Request.MultipartForm.RemoveAll()
+		}
+
+		in.receive(c, email)
+	}
+}
+
+func (in InboundEmail) receive(c *gin.Context, email *inbound.Email) {
+	ctx := c.Request.Context()
+	token := email.Token(in.Domain)
+	if token == "" {
+		apierror.Respond(c, apierror.New(http.StatusNotAcceptable, "Unknown address"))
+		return
+	}
+	address, err := in.Stores.Addresses.Lookup(ctx, token)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.New(http.StatusNotAcceptable, "Unknown address"))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to look up inbound address", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to receive email", err))
+		return
+	}
+
+	scope := address.Scope()
+	if scope.IsOrg() {
+		member, err := in.Stores.Users.GetMember(ctx, scope.OrgID, scope.UserID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.New(http.StatusNotAcceptable, "Unknown address"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get member", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to receive email", err))
+			return
+		}
+		scope.Guest = member.Role == middlewares.OrgGuestRole
+	}
+	if address.ProjectID != nil {
+		project, err := in.Stores.Projects.Get(ctx, scope.OrgID, *address.ProjectID)
+		if errors.Is(err, store.ErrNotFound) || (err == nil && project.ArchivedAt != nil) {
+			apierror.Respond(c, apierror.New(http.StatusNotAcceptable, "Project is no longer open"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to receive email", err))
+			return
+		}
+	}
+
+	title, description := email.Task()
+	task, err := createMessageTask(ctx, in.Stores.Tasks, in.Stores.Workflows, in.Stores.OrgSettings, scope, models.CreateTaskInput{
+		Title:       title,
+		Description: description,
+		ProjectID:   address.ProjectID,
+	})
+	if quotaExceeded(c, err) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to create task from email", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to receive email", err))
+		return
+	}
+
+	// The task is there now, so a retry would duplicate it; attachments
+	// that fail are only logged.
+	attached := 0
+	if in.Files != nil {
+		for _, file := range email.Attachments {
+			filename := strings.TrimSpace(file.Filename)
+			if filename == "" {
+				filename = "attachment"
+			}
+			contentType := mediaType(file.Header.Get("Content-Type"))
+			// Mail clients often send a generic type; the extension says more.
+			if contentType == "" || contentType == "application/octet-stream" {
+				if guess := mediaType(mime.TypeByExtension(path.Ext(filename))); guess != "" {
+					contentType = guess
+				}
+			}
+			if !in.Limits.allows(contentType, file.Size) {
+				continue
+			}
+			if err := in.attach(ctx, scope, task.ID, filename, contentType, file); err != nil {
+				slog.WarnContext(ctx, "Failed to attach file from email", "task_id", task.ID, "error", err)
+				continue
+			}
+			attached++
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"taskId": task.ID, "attachments": attached, "skippedAttachments": len(email.Attachments) - attached})
+}
+
+func (in InboundEmail) attach(ctx context.Context, scope models.Scope, taskID, filename, contentType string, file *multipart.FileHeader) error {
+	attachment, err := in.Stores.Attachments.Create(ctx, scope, taskID, models.CreateAttachmentInput{
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        file.Size,
+		Key:         storage.NewKey("attachments/"+taskID, attachmentObjectName),
+	})
+	if err != nil {
+		return err
+	}
+
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := in.Files.Put(ctx, attachment.Key, contentType, f, file.Size); err != nil {
+		return err
+	}
+
+	_, err = in.Stores.Attachments.Confirm(ctx, scope, taskID, attachment.ID)
+	return err
+}
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/invitations"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Invitations is what the invitation handlers need besides the store.
+type Invitations struct {
+	Sender invitations.Sender
+	// AppURL is the web app the guest lands on to accept.
+	AppURL string
+}
+
+func (i Invitations) acceptURL(token string) string {
+	return strings.TrimRight(i.AppURL, "/") + "/invitations/accept?token=" + url.QueryEscape(token)
+}
+
+// CreateInvitationHandler invites an email to the project as a guest. Clerk
+// sends the email and adds them to the org with the guest role; the project
+// is shared with them once they accept here.
+func CreateInvitationHandler(invites store.InvitationStore, projects store.ProjectStore, inv Invitations) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		var input models.CreateInvitationInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		address, err := mail.ParseAddress(input.Email)
+		if err != nil || address.Name != "" {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid email"))
+			return
+		}
+		if !models.ValidShareRole(input.Role) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid role"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		_, err = projects.Get(ctx, scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to invite guest", err))
+			return
+		}
+
+		token, hash := invitations.NewToken()
+		input.Email = strings.ToLower(address.Address)
+		input.ProjectID = id
+		input.TokenHash = hash
+		input.ExpiresAt = time.Now().Add(models.InvitationTTL)
+
+		input.ClerkInvitationID, err = inv.Sender.Invite(ctx, invitations.Invite{
+			OrgID:       scope.OrgID,
+			InviterID:   scope.UserID,
+			Email:       input.Email,
+			Role:        middlewares.OrgGuestRole,
+			RedirectURL: inv.acceptURL(token),
+			ExpiresIn:   models.InvitationTTL,
+			Metadata:    map[string]string{"projectId": id},
+		})
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to send invitation", "error", err)
+			apierror.Respond(c, apierror.New(http.StatusBadGateway, "Failed to send invitation"))
+			return
+		}
+
+		invitation, err := invites.Create(ctx, scope.OrgID, scope.UserID, input)
+		if err != nil {
+			// Without the row the emailed link can't be accepted, so take
+			// the Clerk invitation back too.
+			if revokeErr := inv.Sender.Revoke(ctx, scope.OrgID, input.ClerkInvitationID, scope.UserID); revokeErr != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to revoke invitation", "error", revokeErr)
+			}
+		}
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create invitation", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to invite guest", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, invitation)
+	}
+}
+
+func ListInvitationsHandler(invites store.InvitationStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		list, err := invites.ListForProject(c.Request.Context(), scope.OrgID, id)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list invitations", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list invitations", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"invitations": list})
+	}
+}
+
+// RevokeInvitationHandler withdraws a pending invitation here and in Clerk.
+// Guests who already accepted are removed by unsharing the project.
+func RevokeInvitationHandler(invites store.InvitationStore, inv Invitations) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id, invitationID := c.Param("id"), c.Param("invitationId")
+		if !isValidID(id) || !isValidID(invitationID) {
+			apierror.Respond(c, apierror.NotFound("Invitation not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		invitation, err := invites.Revoke(ctx, scope.OrgID, id, invitationID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Invitation not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to revoke invitation", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to revoke invitation", err))
+			return
+		}
+
+		// The token no longer works here either way, so a Clerk failure
+		// only leaves a dangling email behind.
+		if err := inv.Sender.Revoke(ctx, scope.OrgID, invitation.ClerkInvitationID, scope.UserID); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to revoke Clerk invitation", "error", err)
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// AcceptInvitationHandler is called by the guest once Clerk has signed them
+// in to the inviting org. The token has to match an invitation sent to the
+// guest's own email.
+func AcceptInvitationHandler(invites store.InvitationStore, users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.AcceptInvitationInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		ctx := c.Request.Context()
+		invitation, err := invites.GetByToken(ctx, scope.OrgID, invitations.HashToken(input.Token))
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Invitation not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get invitation", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to accept invitation", err))
+			return
+		}
+		if !invitation.Pending(time.Now()) {
+			apierror.Respond(c, apierror.New(http.StatusGone, "Invitation is no longer valid"))
+			return
+		}
+
+		user, err := users.GetUser(ctx, scope.UserID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			slog.ErrorContext(c.Request.Context(), "Failed to get user", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to accept invitation", err))
+			return
+		}
+		if user == nil || user.Email == nil || !strings.EqualFold(*user.Email, invitation.Email) {
+			apierror.Respond(c, apierror.Forbidden("Invitation was sent to a different email"))
+			return
+		}
+
+		invitation, err = invites.Accept(ctx, scope, invitation.ID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.New(http.StatusGone, "Invitation is no longer valid"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to accept invitation", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to accept invitation", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, invitation)
+	}
+}
@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxLabelNameLength = 50
+
+func validLabelName(name string) bool {
+	name = strings.TrimSpace(name)
+	return name != "" && len(name) <= maxLabelNameLength
+}
+
+// labelColorAllowed checks color against the org's allowed colors, writing
+// the error response and returning false if it isn't one of them.
+func labelColorAllowed(c *gin.Context, settings store.OrgSettingsStore, orgID, color string) bool {
+	s, err := settings.Get(c.Request.Context(), orgID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get org settings", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to check label color", err))
+		return false
+	}
+	if !s.AllowsLabelColor(color) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Color is not allowed in this organization"))
+		return false
+	}
+	return true
+}
+
+func CreateLabelHandler(labels store.LabelStore, settings store.OrgSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateLabelInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		input.Name = strings.TrimSpace(input.Name)
+		if !validLabelName(input.Name) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid name"))
+			return
+		}
+		if !models.IsValidLabelColor(input.Color) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid color"))
+			return
+		}
+		if !labelColorAllowed(c, settings, scope.OrgID, input.Color) {
+			return
+		}
+
+		label, err := labels.Create(c.Request.Context(), scope.OrgID, scope.UserID, input)
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("Label already exists"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create label", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create label", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, label)
+	}
+}
+
+func ListLabelsHandler(labels store.LabelStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := labels.List(c.Request.Context(), scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list labels", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list labels", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"labels": list})
+	}
+}
+
+func UpdateLabelHandler(labels store.LabelStore, settings store.OrgSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Label not found"))
+			return
+		}
+
+		var input models.UpdateLabelInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.Name != nil {
+			name := strings.TrimSpace(*input.Name)
+			if !validLabelName(name) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid name"))
+				return
+			}
+			input.Name = &name
+		}
+		if input.Color != nil && !models.IsValidLabelColor(*input.Color) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid color"))
+			return
+		}
+		if input.Color != nil && !labelColorAllowed(c, settings, scope.OrgID, *input.Color) {
+			return
+		}
+
+		label, err := labels.Update(c.Request.Context(), scope.OrgID, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Label not found"))
+			return
+		}
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("Label already exists"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update label", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update label", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, label)
+	}
+}
+
+func DeleteLabelHandler(labels store.LabelStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Label not found"))
+			return
+		}
+
+		err := labels.Delete(c.Request.Context(), scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Label not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete label", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete label", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func AttachLabelHandler(labels store.LabelStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.AttachLabelInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if !isValidID(input.LabelID) {
+			invalidField(c, "labelId", "must be an ID")
+			return
+		}
+
+		err := labels.Attach(c.Request.Context(), scope.OrgID, taskID, input.LabelID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task or label not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to attach label", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to attach label", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func DetachLabelHandler(labels store.LabelStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, labelID := c.Param("id"), c.Param("labelId")
+		if !isValidID(taskID) || !isValidID(labelID) {
+			apierror.Respond(c, apierror.NotFound("Label not attached"))
+			return
+		}
+
+		err := labels.Detach(c.Request.Context(), scope.OrgID, taskID, labelID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Label not attached"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to detach label", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to detach label", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// loadTaskLabels fills in Labels on each task. Labels only exist in orgs, so
+// personal tasks are left alone.
+func loadTaskLabels(ctx context.Context, labels store.LabelStore, scope models.Scope, tasks []models.Task) error {
+	if !scope.IsOrg() || len(tasks) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID
+	}
+
+	byTask, err := labels.ForTasks(ctx, scope.OrgID, ids)
+	if err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].Labels = byTask[tasks[i].ID]
+	}
+	return nil
+}
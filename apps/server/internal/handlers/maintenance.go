@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/maintenance"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetMaintenanceHandler(s *maintenance.Switch) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"mode": s.Mode(c.Request.Context())})
+	}
+}
+
+// SetMaintenanceHandler puts every instance into off, read_only or full
+// maintenance mode, unless MAINTENANCE_MODE pins it.
+func SetMaintenanceHandler(s *maintenance.Switch) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input struct {
+			Mode string `json:"mode" binding:"required,oneof=off read_only full"`
+		}
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		err := s.Set(c.Request.Context(), input.Mode)
+		if errors.Is(err, maintenance.ErrFixed) {
+			apierror.Respond(c, apierror.Conflict("Maintenance mode is set by MAINTENANCE_MODE"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to set maintenance mode", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to set maintenance mode", err))
+			return
+		}
+
+		slog.InfoContext(c.Request.Context(), "Maintenance mode set", "mode", input.Mode)
+		c.JSON(http.StatusOK, gin.H{"mode": input.Mode})
+	}
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/markdown"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// renderHTML reports whether the request asked, with ?render=html, for
+// Markdown fields to come back rendered as well. On any other mode it has
+// already written the response.
+func renderHTML(c *gin.Context) (bool, bool) {
+	switch c.Query("render") {
+	case "":
+		return false, true
+	case "html":
+		return true, true
+	}
+	apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid render mode"))
+	return false, false
+}
+
+func setDescriptionHTML(t *models.Task) {
+	html := markdown.ToHTML(t.Description)
+	t.DescriptionHTML = &html
+}
+
+func setBodyHTML(comment *models.Comment) {
+	html := markdown.ToHTML(comment.Body)
+	comment.BodyHTML = &html
+}
@@ -1,20 +1,24 @@
 package handlers
 
 import (
+	"errors"
+	"log/slog"
 	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/store"
 
 	"github.com/clerk/clerk-sdk-go/v2"
 	"github.com/gin-gonic/gin"
 )
 
-func GetMeHandler() gin.HandlerFunc {
+// GetMeHandler describes the session, along with the user's profile from
+// the local mirror; "user" is null until Clerk's user event has arrived.
+func GetMeHandler(users store.UserStore) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		claims, ok := clerk.SessionClaimsFromContext(c.Request.Context())
 
 		if !ok {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Unauthorized",
-			})
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
 			return
 		}
 
@@ -23,12 +27,20 @@ func GetMeHandler() gin.HandlerFunc {
 		orgSlug := claims.ActiveOrganizationSlug
 		orgRole := claims.ActiveOrganizationRole
 
+		user, err := users.GetUser(c.Request.Context(), userId)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			slog.ErrorContext(c.Request.Context(), "Failed to get user", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get user", err))
+			return
+		}
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Me handler ran",
 			"userId":  userId,
 			"orgId":   orgId,
 			"orgSlug": orgSlug,
 			"orgRole": orgRole,
+			"user":    user,
 		})
 
 	}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListNotificationsHandler(notifications store.NotificationStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		unreadOnly := c.Query("unread") == "true"
+
+		page, err := api.ParsePage(c, "notifications")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		list, err := notifications.List(c.Request.Context(), scope.UserID, unreadOnly, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list notifications", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list notifications", err))
+			return
+		}
+
+		list, hasMore := api.Trim(list, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			last := list[len(list)-1]
+			pageInfo.NextCursor = api.EncodeCursor("notifications", last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"notifications": list, "pageInfo": pageInfo})
+	}
+}
+
+func UnreadNotificationCountHandler(notifications store.NotificationStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		count, err := notifications.UnreadCount(c.Request.Context(), scope.UserID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to count unread notifications", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to count unread notifications", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"unread": count})
+	}
+}
+
+// MarkNotificationHandler marks one notification read or unread.
+func MarkNotificationHandler(notifications store.NotificationStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Notification not found"))
+			return
+		}
+
+		var input models.MarkNotificationInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		n, err := notifications.SetRead(c.Request.Context(), scope.UserID, id, *input.Read)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Notification not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update notification", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update notification", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, n)
+	}
+}
+
+func MarkAllNotificationsReadHandler(notifications store.NotificationStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		count, err := notifications.MarkAllRead(c.Request.Context(), scope.UserID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to mark notifications read", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to mark notifications read", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"updated": count})
+	}
+}
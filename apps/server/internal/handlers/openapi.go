@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"yata/apps/server/internal/openapi"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed swagger/index.html
+var swaggerUI []byte
+
+// OpenAPIHandler serves the document, encoded once since routes don't
+// change after startup.
+func OpenAPIHandler(doc *openapi.Document) (gin.HandlerFunc, error) {
+	body, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json; charset=utf-8", body)
+	}, nil
+}
+
+// SwaggerUIHandler serves a page that loads Swagger UI from its CDN and
+// points it at /api/openapi.json.
+func SwaggerUIHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", swaggerUI)
+	}
+}
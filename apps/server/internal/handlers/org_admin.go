@@ -0,0 +1,261 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/members"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ListAdminMembersHandler lists the org's members along with how many of
+// the org's tasks each one owns.
+func ListAdminMembersHandler(users store.UserStore, admin store.OrgAdminStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "members")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		list, err := users.ListMembers(ctx, scope.OrgID, page)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list org members", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list members", err))
+			return
+		}
+		list, hasMore := api.Trim(list, page.Limit)
+
+		ids := make([]string, len(list))
+		for i, m := range list {
+			ids[i] = m.ID
+		}
+		counts, err := admin.TaskCounts(ctx, scope.OrgID, ids)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to count member tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list members", err))
+			return
+		}
+
+		result := make([]models.AdminMember, len(list))
+		for i, m := range list {
+			result[i] = models.AdminMember{OrgMember: m, Tasks: counts[m.ID]}
+		}
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			pageInfo.NextCursor = api.EncodeCursor("members", list[len(list)-1].ID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"members": result, "pageInfo": pageInfo})
+	}
+}
+
+// checkNewOwner makes sure userID can take over tasks: a member of the org
+// other than the one giving them up, and not a guest.
+func checkNewOwner(c *gin.Context, users store.UserStore, scope models.Scope, userID, fromUserID string) bool {
+	if userID == fromUserID {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Tasks can't be transferred to the member they belong to"))
+		return false
+	}
+	member, err := users.GetMember(c.Request.Context(), scope.OrgID, userID)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "User is not a member of this organization"))
+		return false
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get member", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to reassign tasks", err))
+		return false
+	}
+	if member.Role == middlewares.OrgGuestRole {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Guests can't own org tasks"))
+		return false
+	}
+	return true
+}
+
+// TransferMemberTasksHandler hands every task a user owns in the org to
+// another member. The user needn't be a member any more, so tasks left
+// behind by someone who has gone can still be picked up.
+func TransferMemberTasksHandler(users store.UserStore, admin store.OrgAdminStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.TransferTasksInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		fromUserID := c.Param("userId")
+		if !checkNewOwner(c, users, scope, input.ToUserID, fromUserID) {
+			return
+		}
+
+		moved, err := admin.TransferTasks(c.Request.Context(), scope.OrgID, fromUserID, input.ToUserID)
+		if errors.Is(err, store.ErrNotFound) {
+			// They left the org since checkNewOwner.
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "User is not a member of this organization"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to transfer tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to reassign tasks", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"reassigned": moved})
+	}
+}
+
+// ReassignTasksHandler hands the given tasks to a member, whoever owns them
+// now. Tasks that aren't found in the org are skipped.
+func ReassignTasksHandler(users store.UserStore, admin store.OrgAdminStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.ReassignTasksInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		ids := make([]string, 0, len(input.TaskIDs))
+		for _, id := range input.TaskIDs {
+			if isValidID(id) {
+				ids = append(ids, id)
+			}
+		}
+		if !checkNewOwner(c, users, scope, input.ToUserID, "") {
+			return
+		}
+
+		moved, err := admin.ReassignTasks(c.Request.Context(), scope.OrgID, ids, input.ToUserID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "User is not a member of this organization"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to reassign tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to reassign tasks", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"reassigned": moved})
+	}
+}
+
+// DeactivateMemberHandler removes a member from the org in Clerk, which ends
+// their sessions' access to it, and drops the local membership right away so
+// their API tokens and share links stop working too. Their tasks go to transferTo if given;
+// otherwise they stay put until someone transfers them.
+func DeactivateMemberHandler(users store.UserStore, admin store.OrgAdminStore, links store.ShareLinkStore, remover members.Remover) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.DeactivateMemberInput
+		if !bindOptionalJSON(c, &input) {
+			return
+		}
+
+		userID := c.Param("userId")
+		if userID == scope.UserID {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "You can't deactivate yourself"))
+			return
+		}
+		ctx := c.Request.Context()
+		if _, err := users.GetMember(ctx, scope.OrgID, userID); errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Member not found"))
+			return
+		} else if err != nil {
+			slog.ErrorContext(ctx, "Failed to get member", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to deactivate member", err))
+			return
+		}
+		if input.TransferTo != nil && !checkNewOwner(c, users, scope, *input.TransferTo, userID) {
+			return
+		}
+
+		if err := remover.Remove(ctx, scope.OrgID, userID); err != nil {
+			slog.ErrorContext(ctx, "Failed to remove member from Clerk", "error", err)
+			apierror.Respond(c, apierror.New(http.StatusBadGateway, "Failed to deactivate member"))
+			return
+		}
+		if err := users.DeleteMembership(ctx, scope.OrgID, userID); err != nil {
+			slog.ErrorContext(ctx, "Failed to delete membership", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to deactivate member", err))
+			return
+		}
+		if err := links.RevokeMember(ctx, scope.OrgID, userID); err != nil {
+			// Their links stop opening anyway once the membership is gone.
+			slog.ErrorContext(ctx, "Failed to revoke share links", "error", err)
+		}
+
+		moved := []models.ReassignedTask{}
+		if input.TransferTo != nil {
+			var err error
+			moved, err = admin.TransferTasks(ctx, scope.OrgID, userID, *input.TransferTo)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.Conflict("Member deactivated, but the member to transfer their tasks to has left"))
+				return
+			}
+			if err != nil {
+				// The member is gone either way; the transfer can be retried.
+				slog.ErrorContext(ctx, "Failed to transfer tasks", "error", err)
+				apierror.Respond(c, apierror.Internal("Member deactivated, but their tasks couldn't be transferred", err))
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"reassigned": moved})
+	}
+}
+
+// SetOrgStatusHandler suspends or reactivates an org, for the operator. A
+// later status in the org's Clerk metadata overrides it.
+func SetOrgStatusHandler(users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input struct {
+			Status string `json:"status" binding:"required,oneof=active suspended"`
+		}
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		orgID := c.Param("orgId")
+		err := users.SetOrganizationStatus(c.Request.Context(), orgID, input.Status)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Organization not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to set org status", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to set status", err))
+			return
+		}
+
+		slog.InfoContext(c.Request.Context(), "Org status set", "org_id", orgID, "status", input.Status)
+		c.JSON(http.StatusOK, gin.H{"status": input.Status})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/ratelimit"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetOrgSettingsHandler(settings store.OrgSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		s, err := settings.Get(c.Request.Context(), scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get org settings", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get settings", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, s)
+	}
+}
+
+// UpdateOrgSettingsHandler changes only the fields sent. Existing labels keep
+// their colors when the allowed set shrinks; only new colors are checked.
+func UpdateOrgSettingsHandler(settings store.OrgSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.UpdateOrgSettingsInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		if input.WorkingDays != nil {
+			for _, d := range *input.WorkingDays {
+				if d < 0 || d > 6 {
+					apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid workingDays"))
+					return
+				}
+			}
+		}
+		if v := input.DefaultTaskVisibility; v != nil && !models.ValidTaskVisibility(*v) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid defaultTaskVisibility"))
+			return
+		}
+		if input.AllowedLabelColors != nil {
+			for _, color := range *input.AllowedLabelColors {
+				if !models.IsValidLabelColor(color) {
+					apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid allowedLabelColors"))
+					return
+				}
+			}
+		}
+		if r := input.TrashRetentionDays; r.Set && !r.Null && (r.Value < 1 || r.Value > models.MaxTrashRetentionDays) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid trashRetentionDays"))
+			return
+		}
+
+		s, err := settings.Update(c.Request.Context(), scope.OrgID, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update org settings", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update settings", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, s)
+	}
+}
+
+// SetOrgRateLimitHandler sets the "limit/window" rule an org's API requests
+// are held to in place of RATE_LIMITS, or clears it with null. It's for the
+// operator, so orgs can't raise their own limits; the new rule applies from
+// the next request.
+func SetOrgRateLimitHandler(settings store.OrgSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var input struct {
+			RateLimit models.Nullable[string] `json:"rateLimit"`
+		}
+		if !bindJSON(c, &input) {
+			return
+		}
+		if !input.RateLimit.Set {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "rateLimit is required"))
+			return
+		}
+		if !input.RateLimit.Null {
+			if _, err := ratelimit.ParseRule(input.RateLimit.Value); err != nil {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid rateLimit"))
+				return
+			}
+		}
+
+		orgID := c.Param("orgId")
+		s, err := settings.Update(c.Request.Context(), orgID, models.UpdateOrgSettingsInput{RateLimit: input.RateLimit})
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to set org rate limit", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to set rate limit", err))
+			return
+		}
+
+		slog.InfoContext(c.Request.Context(), "Org rate limit set", "org_id", orgID, "rate_limit", input.RateLimit.Value)
+		c.JSON(http.StatusOK, s)
+	}
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// pomodoroLeeway is how early a session may be completed. Its start and end
+// are both the server's time, so this only covers the request's trip.
+const pomodoroLeeway = 5 * time.Second
+
+func StartPomodoroHandler(pomodoros store.PomodoroStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.StartPomodoroInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.TaskID != nil && !isValidID(*input.TaskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		minutes := models.DefaultPomodoroMinutes
+		if input.Minutes != nil {
+			minutes = *input.Minutes
+		}
+		if minutes < 1 || minutes > models.MaxPomodoroMinutes {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "minutes must be between 1 and 120"))
+			return
+		}
+
+		pomodoro, err := pomodoros.Start(c.Request.Context(), scope, input.TaskID, minutes*60)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("A pomodoro is already running; complete or interrupt it first"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to start pomodoro", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to start pomodoro", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, pomodoro)
+	}
+}
+
+// GetRunningPomodoroHandler returns the caller's running session, whichever
+// scope it was started in.
+func GetRunningPomodoroHandler(pomodoros store.PomodoroStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		pomodoro, err := pomodoros.Running(c.Request.Context(), scope.UserID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("No pomodoro is running"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get running pomodoro", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get running pomodoro", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, pomodoro)
+	}
+}
+
+func ListPomodorosHandler(pomodoros store.PomodoroStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "pomodoros")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		list, err := pomodoros.List(c.Request.Context(), scope, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list pomodoros", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list pomodoros", err))
+			return
+		}
+
+		list, hasMore := api.Trim(list, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			last := list[len(list)-1]
+			pageInfo.NextCursor = api.EncodeCursor("pomodoros", last.StartedAt.Format(time.RFC3339Nano), last.ID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"pomodoros": list, "pageInfo": pageInfo})
+	}
+}
+
+// CompletePomodoroHandler completes a running session once its planned
+// time is up.
+func CompletePomodoroHandler(pomodoros store.PomodoroStore) gin.HandlerFunc {
+	return finishPomodoroHandler(pomodoros, models.PomodoroCompleted)
+}
+
+func InterruptPomodoroHandler(pomodoros store.PomodoroStore) gin.HandlerFunc {
+	return finishPomodoroHandler(pomodoros, models.PomodoroInterrupted)
+}
+
+func finishPomodoroHandler(pomodoros store.PomodoroStore, status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Pomodoro not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		current, err := pomodoros.Get(ctx, scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Pomodoro not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get pomodoro", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update pomodoro", err))
+			return
+		}
+		if current.Status != models.PomodoroRunning {
+			apierror.Respond(c, apierror.Conflict("The pomodoro is already "+current.Status))
+			return
+		}
+		if status == models.PomodoroCompleted && time.Now().Add(pomodoroLeeway).Before(current.DueAt()) {
+			apierror.Respond(c, apierror.Conflict("The pomodoro isn't over yet; interrupt it instead"))
+			return
+		}
+
+		pomodoro, err := pomodoros.Finish(ctx, scope, id, status)
+		if errors.Is(err, store.ErrNotFound) {
+			// Finished by another request in the meantime.
+			apierror.Respond(c, apierror.Conflict("The pomodoro is no longer running"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to finish pomodoro", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update pomodoro", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, pomodoro)
+	}
+}
+
+// PomodoroStatsHandler sums up the caller's sessions in scope by day and
+// week, with their streaks. Days are taken in the X-Timezone zone, or the
+// user's own, and weeks start on the user's week start.
+func PomodoroStatsHandler(pomodoros store.PomodoroStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		ctx := c.Request.Context()
+		s, err := settings.Get(ctx, scope.UserID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get user settings", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get pomodoro stats", err))
+			return
+		}
+		if loc == nil {
+			loc = s.Location()
+		}
+
+		days, err := pomodoros.Days(ctx, scope, loc)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to total pomodoros", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get pomodoro stats", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, pomodoroStats(days, time.Now().In(loc), s.WeekStartDay()))
+	}
+}
+
+// pomodoroStats works out the stats from the days with sessions, oldest
+// first. Dates are handled as UTC midnights so that adding days is exact.
+func pomodoroStats(days []models.PomodoroDay, now time.Time, weekStart time.Weekday) models.PomodoroStats {
+	today, _ := time.Parse(time.DateOnly, now.Format(time.DateOnly))
+	weekOf := func(d time.Time) time.Time {
+		return d.AddDate(0, 0, -((int(d.Weekday()) - int(weekStart) + 7) % 7))
+	}
+	thisWeek := weekOf(today)
+
+	byDate := map[string]models.PomodoroDay{}
+	for _, d := range days {
+		byDate[d.Date] = d
+	}
+	day := func(d time.Time) models.PomodoroDay {
+		date := d.Format(time.DateOnly)
+		if found, ok := byDate[date]; ok {
+			return found
+		}
+		return models.PomodoroDay{Date: date}
+	}
+
+	stats := models.PomodoroStats{
+		Today:    day(today),
+		ThisWeek: models.PomodoroDay{Date: thisWeek.Format(time.DateOnly)},
+		Days:     make([]models.PomodoroDay, 0, 7),
+	}
+	for i := 6; i >= 0; i-- {
+		stats.Days = append(stats.Days, day(today.AddDate(0, 0, -i)))
+	}
+
+	// Walk the days with a completed session, counting runs of consecutive
+	// days and of consecutive weeks.
+	var lastDay, lastWeek time.Time
+	dailyRun, weeklyRun := 0, 0
+	for _, d := range days {
+		date, err := time.Parse(time.DateOnly, d.Date)
+		if err != nil {
+			continue
+		}
+		if week := weekOf(date); week.Equal(thisWeek) {
+			stats.ThisWeek.Completed += d.Completed
+			stats.ThisWeek.Interrupted += d.Interrupted
+			stats.ThisWeek.FocusSeconds += d.FocusSeconds
+		}
+		if d.Completed == 0 {
+			continue
+		}
+
+		if !lastDay.IsZero() && date.Equal(lastDay.AddDate(0, 0, 1)) {
+			dailyRun++
+		} else {
+			dailyRun = 1
+		}
+		lastDay = date
+		stats.LongestDailyStreak = max(stats.LongestDailyStreak, dailyRun)
+
+		switch week := weekOf(date); {
+		case week.Equal(lastWeek):
+		case !lastWeek.IsZero() && week.Equal(lastWeek.AddDate(0, 0, 7)):
+			weeklyRun++
+			lastWeek = week
+		default:
+			weeklyRun = 1
+			lastWeek = week
+		}
+		stats.LongestWeeklyStreak = max(stats.LongestWeeklyStreak, weeklyRun)
+	}
+	if !lastDay.Before(today.AddDate(0, 0, -1)) {
+		stats.DailyStreak = dailyRun
+	}
+	if !lastWeek.Before(thisWeek.AddDate(0, 0, -7)) {
+		stats.WeeklyStreak = weeklyRun
+	}
+	return stats
+}
@@ -0,0 +1,551 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkProjectTemplate writes the error response and returns false unless
+// every parent comes before its subtasks and the dependencies point at
+// other tasks of the template without forming a cycle.
+func checkProjectTemplate(c *gin.Context, tasks []models.ProjectTemplateTask) bool {
+	if len(tasks) > models.MaxProjectTemplateTasks {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Project templates can have at most "+strconv.Itoa(models.MaxProjectTemplateTasks)+" tasks"))
+		return false
+	}
+	for i, t := range tasks {
+		if t.Parent != nil && (*t.Parent < 0 || *t.Parent >= i) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Task "+strconv.Itoa(i)+" must come after its parent"))
+			return false
+		}
+		for _, j := range t.BlockedBy {
+			if j < 0 || j >= len(tasks) || j == i {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Task "+strconv.Itoa(i)+" is blocked by a task that isn't in the template"))
+				return false
+			}
+		}
+	}
+
+	// Depth-first from each task along its blockers; meeting a task that's
+	// still on the path means a cycle.
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := make([]int, len(tasks))
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		state[i] = onPath
+		for _, j := range tasks[i].BlockedBy {
+			if state[j] == onPath || state[j] == unvisited && !visit(j) {
+				return false
+			}
+		}
+		state[i] = done
+		return true
+	}
+	for i := range tasks {
+		if state[i] == unvisited && !visit(i) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Template dependencies form a cycle"))
+			return false
+		}
+	}
+	return true
+}
+
+// templateRoles lists the roles the template's tasks are assigned to.
+func templateRoles(tasks []models.ProjectTemplateTask) map[string]bool {
+	roles := map[string]bool{}
+	for _, t := range tasks {
+		if t.Role != "" {
+			roles[t.Role] = true
+		}
+	}
+	return roles
+}
+
+// projectTemplateFromTasks turns a project's tasks, labels loaded, into a
+// template, parents first. Tasks whose parent isn't among them become
+// top-level. The caller's tasks get no role, so they go to whoever uses the
+// template; each other owner becomes a role of their own. Due dates count
+// from start, a dayNumber in zone.
+func projectTemplateFromTasks(list []models.Task, blockedBy map[string][]string, callerID string, start int, zone *time.Location) []models.ProjectTemplateTask {
+	byID := map[string]bool{}
+	children := map[string][]models.Task{}
+	for _, t := range list {
+		byID[t.ID] = true
+	}
+	order := []models.Task{}
+	for _, t := range list {
+		if t.ParentID != nil && byID[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			order = append(order, t)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		order = append(order, children[order[i].ID]...)
+	}
+
+	index := map[string]int{}
+	for i, t := range order {
+		index[t.ID] = i
+	}
+	roles := map[string]string{}
+	tasks := make([]models.ProjectTemplateTask, len(order))
+	for i, t := range order {
+		tt := models.ProjectTemplateTask{
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      t.Status,
+			Priority:    t.Priority,
+		}
+		tt.DueInDays, tt.DueTime = templateOffset(t.DueDate, start, zone)
+		for _, l := range t.Labels {
+			tt.Labels = append(tt.Labels, l.Name)
+		}
+		if t.OwnerID != callerID {
+			if _, ok := roles[t.OwnerID]; !ok {
+				roles[t.OwnerID] = "Assignee " + strconv.Itoa(len(roles)+1)
+			}
+			tt.Role = roles[t.OwnerID]
+		}
+		if t.ParentID != nil {
+			if p, ok := index[*t.ParentID]; ok {
+				tt.Parent = &p
+			}
+		}
+		for _, blocker := range blockedBy[t.ID] {
+			if j, ok := index[blocker]; ok {
+				tt.BlockedBy = append(tt.BlockedBy, j)
+			}
+		}
+		tasks[i] = tt
+	}
+	return tasks
+}
+
+// CreateProjectTemplateHandler saves a project template, either from an
+// existing project's live tasks or from a list given in full.
+func CreateProjectTemplateHandler(templates store.ProjectTemplateStore, projects store.ProjectStore, tasks store.TaskStore, labels store.LabelStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateProjectTemplateInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if (input.ProjectID == nil) == (input.Tasks == nil) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Either projectId or tasks is required"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		if input.ProjectID != nil {
+			if !isValidID(*input.ProjectID) {
+				apierror.Respond(c, apierror.NotFound("Project not found"))
+				return
+			}
+			project, err := projects.Get(ctx, scope.OrgID, *input.ProjectID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Project not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get project", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create template", err))
+				return
+			}
+
+			filter := models.TaskFilter{ProjectID: project.ID, Sort: []models.SortField{{Field: "position"}}}
+			list, err := tasks.List(ctx, scope, filter, models.Page{Limit: models.MaxProjectTemplateTasks})
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to list tasks", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create template", err))
+				return
+			}
+			if len(list) > models.MaxProjectTemplateTasks {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Project templates can have at most "+strconv.Itoa(models.MaxProjectTemplateTasks)+" tasks"))
+				return
+			}
+			if err := loadTaskLabels(ctx, labels, scope, list); err != nil {
+				slog.ErrorContext(ctx, "Failed to load task labels", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create template", err))
+				return
+			}
+			blockedBy := map[string][]string{}
+			for _, t := range list {
+				deps, err := tasks.Dependencies(ctx, scope, t.ID)
+				if err != nil {
+					slog.ErrorContext(ctx, "Failed to get dependencies", "error", err)
+					apierror.Respond(c, apierror.Internal("Failed to create template", err))
+					return
+				}
+				blockedBy[t.ID] = deps.BlockedBy
+			}
+
+			zone := loc
+			if zone == nil {
+				zone = time.UTC
+			}
+			input.Tasks = projectTemplateFromTasks(list, blockedBy, scope.UserID, dayNumber(project.CreatedAt, zone), zone)
+		}
+		if !checkProjectTemplate(c, input.Tasks) {
+			return
+		}
+
+		template, err := templates.Create(ctx, scope, input.Name, input.Tasks)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create project template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create template", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, template)
+	}
+}
+
+func ListProjectTemplatesHandler(templates store.ProjectTemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := templates.List(c.Request.Context(), scope)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list project templates", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list templates", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"templates": list})
+	}
+}
+
+func GetProjectTemplateHandler(templates store.ProjectTemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		template, err := templates.Get(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get project template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get template", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// UpdateProjectTemplateHandler renames a template or replaces its tasks;
+// only its creator may.
+func UpdateProjectTemplateHandler(templates store.ProjectTemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		var input models.UpdateProjectTemplateInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.Tasks != nil && !checkProjectTemplate(c, input.Tasks) {
+			return
+		}
+
+		template, err := templates.Update(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Only the template's creator can change it"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update project template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update template", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+func DeleteProjectTemplateHandler(templates store.ProjectTemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		err := templates.Delete(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Only the template's creator can change it"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete project template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete template", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ProjectTemplateStores are what instantiating a project template reads
+// and writes.
+type ProjectTemplateStores struct {
+	Templates   store.ProjectTemplateStore
+	Projects    store.ProjectStore
+	Labels      store.LabelStore
+	Users       store.UserStore
+	Workflows   store.WorkflowStore
+	OrgSettings store.OrgSettingsStore
+	Quotas      *quota.Enforcer
+}
+
+// InstantiateProjectTemplateHandler creates a project from a template with
+// a board of the org's workflow and all the template's tasks and their
+// dependencies, in one transaction. Labels the org
+// doesn't have yet are created first; they're left behind if that fails,
+// which is harmless, as the next try uses them.
+func InstantiateProjectTemplateHandler(stores ProjectTemplateStores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		var input models.InstantiateProjectTemplateInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		input.Name = strings.TrimSpace(input.Name)
+		if input.Name == "" {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid name"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		workflow, ok := loadWorkflow(c, stores.Workflows, scope)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		template, err := stores.Templates.Get(ctx, scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get project template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+			return
+		}
+
+		roles := templateRoles(template.Tasks)
+		for role, userID := range input.Assignees {
+			if !roles[role] {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Unknown role: "+role))
+				return
+			}
+			member, err := stores.Users.GetMember(ctx, scope.OrgID, userID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "User is not a member of this organization"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get member", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+				return
+			}
+			if member.Role == middlewares.OrgGuestRole {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Guests can't own org tasks"))
+				return
+			}
+		}
+
+		// Checked up front as well as by the store, since the template's
+		// labels are created before its project.
+		if !checkQuota(c, stores.Quotas, scope, quota.Tasks, int64(len(template.Tasks)), "Failed to instantiate template") {
+			return
+		}
+		orgSettings, err := stores.OrgSettings.Get(ctx, scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get org settings", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+			return
+		}
+		labelIDs, ok := templateLabels(c, stores.Labels, scope, orgSettings, template.Tasks)
+		if !ok {
+			return
+		}
+
+		var dueTimezone *string
+		if loc != nil {
+			name := loc.String()
+			dueTimezone = &name
+		}
+		start := templateStart(input.StartDate, loc)
+		batch := make([]models.NewTask, len(template.Tasks))
+		for i, t := range template.Tasks {
+			n := models.NewTask{
+				Input: models.CreateTaskInput{
+					Title:       t.Title,
+					Description: t.Description,
+					Status:      t.Status,
+					Visibility:  orgSettings.DefaultTaskVisibility,
+				},
+				Parent:    -1,
+				OwnerID:   input.Assignees[t.Role],
+				BlockedBy: t.BlockedBy,
+			}
+			if !workflow.IsValidStatus(n.Input.Status) {
+				n.Input.Status = workflow.DefaultStatus()
+			}
+			if workflow.IsValidPriority(t.Priority) {
+				n.Input.Priority = t.Priority
+			}
+			if n.Input.DueDate = templateDue(t.DueInDays, t.DueTime, start); n.Input.DueDate != nil {
+				n.Input.DueTimezone = dueTimezone
+			}
+			if t.Parent != nil {
+				n.Parent = *t.Parent
+			}
+			for _, name := range t.Labels {
+				if labelID, ok := labelIDs[strings.ToLower(strings.TrimSpace(name))]; ok {
+					n.LabelIDs = append(n.LabelIDs, labelID)
+				}
+			}
+			batch[i] = n
+		}
+
+		seeded, err := stores.Projects.CreateSeeded(ctx, scope, models.NewProject{
+			Input: models.CreateProjectInput{Name: input.Name},
+			Board: &models.CreateBoardInput{Name: models.DefaultBoardName, Columns: workflowColumns(workflow)},
+			Tasks: batch,
+		})
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create project from template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+			return
+		}
+		if err := loadTaskLabels(ctx, stores.Labels, scope, seeded.Tasks); err != nil {
+			slog.ErrorContext(ctx, "Failed to load task labels", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+			return
+		}
+		now := time.Now()
+		for i := range seeded.Tasks {
+			setDueToday(&seeded.Tasks[i], loc, now)
+		}
+
+		c.JSON(http.StatusCreated, seeded)
+	}
+}
+
+// templateLabels maps the lowercased names of the labels the template uses
+// to ids, creating the ones the org doesn't have.
+func templateLabels(c *gin.Context, labels store.LabelStore, scope models.Scope, settings models.OrgSettings, tasks []models.ProjectTemplateTask) (map[string]string, bool) {
+	ctx := c.Request.Context()
+	existing, err := labels.List(ctx, scope.OrgID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to list labels", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+		return nil, false
+	}
+	ids := map[string]string{}
+	for _, l := range existing {
+		ids[strings.ToLower(l.Name)] = l.ID
+	}
+
+	for _, t := range tasks {
+		for _, name := range t.Labels {
+			name = strings.TrimSpace(name)
+			key := strings.ToLower(name)
+			if _, ok := ids[key]; ok || !validLabelName(name) {
+				continue
+			}
+			label, err := labels.Create(ctx, scope.OrgID, scope.UserID, models.CreateLabelInput{Name: name, Color: settings.LabelColor()})
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to create label", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+				return nil, false
+			}
+			ids[key] = label.ID
+		}
+	}
+	return ids, true
+}
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func CreateProjectHandler(projects store.ProjectStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateProjectInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		project, err := projects.Create(c.Request.Context(), scope.OrgID, scope.UserID, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create project", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, project)
+	}
+}
+
+func ListProjectsHandler(projects store.ProjectStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		includeArchived := c.Query("archived") == "true"
+
+		page, err := api.ParsePage(c, "projects")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		list, err := projects.List(c.Request.Context(), scope.OrgID, includeArchived, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list projects", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list projects", err))
+			return
+		}
+
+		list, hasMore := api.Trim(list, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			last := list[len(list)-1]
+			pageInfo.NextCursor = api.EncodeCursor("projects", last.Name, last.ID)
+		}
+
+		writeListJSON(c, gin.H{"projects": list, "pageInfo": pageInfo})
+	}
+}
+
+func RenameProjectHandler(projects store.ProjectStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		var input models.RenameProjectInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		project, err := projects.Rename(c.Request.Context(), scope.OrgID, id, input.Name)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to rename project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to rename project", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, project)
+	}
+}
+
+func ArchiveProjectHandler(projects store.ProjectStore, archived bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		project, err := projects.SetArchived(c.Request.Context(), scope.OrgID, id, archived)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to archive project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to archive project", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, project)
+	}
+}
+
+// checkTaskProject makes sure a task can be attached to projectID in scope,
+// writing the error response and returning false if not.
+func checkTaskProject(c *gin.Context, projects store.ProjectStore, scope models.Scope, projectID string) bool {
+	if !scope.IsOrg() {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Projects require an active organization"))
+		return false
+	}
+
+	if !isValidID(projectID) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Project not found"))
+		return false
+	}
+
+	project, err := projects.Get(c.Request.Context(), scope.OrgID, projectID)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Project not found"))
+		return false
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get project", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to get project", err))
+		return false
+	}
+
+	if project.ArchivedAt != nil {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Project is archived"))
+		return false
+	}
+
+	return true
+}
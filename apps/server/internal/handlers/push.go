@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetVAPIDPublicKeyHandler returns the application server key the browser
+// passes to pushManager.subscribe.
+func GetVAPIDPublicKeyHandler(publicKey string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if publicKey == "" {
+			apierror.Respond(c, apierror.NotFound("Push notifications are not configured"))
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"publicKey": publicKey})
+	}
+}
+
+func CreatePushSubscriptionHandler(subs store.PushSubscriptionStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreatePushSubscriptionInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		sub, err := subs.Create(c.Request.Context(), scope.UserID, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create push subscription", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create push subscription", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, sub)
+	}
+}
+
+// DeletePushSubscriptionHandler takes the endpoint in the body, since
+// endpoints are URLs and don't fit in a path segment.
+func DeletePushSubscriptionHandler(subs store.PushSubscriptionStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.DeletePushSubscriptionInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		err := subs.Delete(c.Request.Context(), scope.UserID, input.Endpoint)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Push subscription not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete push subscription", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete push subscription", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/quickadd"
+	"yata/apps/server/internal/recurrence"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// QuickAddTaskHandler reads a line like "Pay rent tomorrow 5pm #finance
+// !high every month" and returns the task it describes without creating it.
+// Dates are read in the X-Timezone zone, or the user's own.
+func QuickAddTaskHandler(labels store.LabelStore, workflows store.WorkflowStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.QuickAddInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		if loc == nil {
+			s, err := settings.Get(c.Request.Context(), scope.UserID)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get user settings", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to parse task", err))
+				return
+			}
+			loc = s.Location()
+		}
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		parsed := quickadd.Parse(input.Text, time.Now(), loc)
+		result := models.QuickAddResult{
+			Task: models.CreateTaskInput{
+				Title:   parsed.Title,
+				DueDate: parsed.Due,
+			},
+			DueHasTime:    parsed.DueHasTime,
+			Labels:        []models.Label{},
+			UnknownLabels: []string{},
+		}
+		if parsed.Due != nil {
+			zone := loc.String()
+			result.Task.DueTimezone = &zone
+		}
+		if parsed.Recurrence != nil {
+			if rule, err := recurrence.Normalize(*parsed.Recurrence); err == nil {
+				result.Task.Recurrence = &rule
+			}
+		}
+
+		if parsed.Priority != "" {
+			level, ok := priorityNamed(workflow, parsed.Priority)
+			if ok {
+				result.Task.Priority = level
+			} else {
+				result.UnknownPriority = &parsed.Priority
+			}
+		}
+
+		// Guests can't see the org's labels, so theirs all come back unknown.
+		var known []models.Label
+		if len(parsed.Labels) > 0 && scope.IsOrg() && !scope.Guest {
+			var err error
+			known, err = labels.List(c.Request.Context(), scope.OrgID)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to list labels", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to parse task", err))
+				return
+			}
+		}
+		for _, name := range parsed.Labels {
+			found := false
+			for _, l := range known {
+				if strings.EqualFold(l.Name, name) {
+					result.Labels = append(result.Labels, l)
+					found = true
+					break
+				}
+			}
+			if !found {
+				result.UnknownLabels = append(result.UnknownLabels, name)
+			}
+		}
+
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// priorityNamed finds the workflow's level called name, or numbered name.
+func priorityNamed(workflow models.Workflow, name string) (int, bool) {
+	for _, p := range workflow.Priorities {
+		if strings.EqualFold(p.Name, name) {
+			return p.Level, true
+		}
+	}
+	if level, err := strconv.Atoi(name); err == nil && workflow.IsValidPriority(level) {
+		return level, true
+	}
+	return 0, false
+}
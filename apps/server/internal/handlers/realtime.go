@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"yata/apps/server/internal/realtime"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebSocketHandler hands the connection to the hub. It sits outside the
+// Clerk middleware because browsers can't set headers on a WebSocket; the
+// hub authenticates the first message instead.
+func WebSocketHandler(hub *realtime.Hub) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hub.Serve(c.Writer, c.Request)
+	}
+}
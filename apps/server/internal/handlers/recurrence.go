@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/recurrence"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPreviewCount = 5
+	maxPreviewCount     = 50
+)
+
+// PreviewRecurrenceHandler lists the next occurrences of ?rule= starting at
+// ?start= (default now), in the requester's timezone.
+func PreviewRecurrenceHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		if loc == nil {
+			loc = time.UTC
+		}
+
+		count := defaultPreviewCount
+		if raw := c.Query("count"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 || n > maxPreviewCount {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "count must be between 1 and 50"))
+				return
+			}
+			count = n
+		}
+
+		start := time.Now().In(loc).Truncate(time.Second)
+		if c.Query("start") != "" {
+			t, err := parseDateParam(c, "start")
+			if err != nil {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid start"))
+				return
+			}
+			start = t.In(loc)
+		}
+
+		occurrences, err := recurrence.Preview(c.Query("rule"), start, count)
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid recurrence"))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"occurrences": occurrences})
+	}
+}
+
+// MaterializeNextOccurrence creates the follow-up of a just-completed
+// recurring task and moves the rule onto it, so completing the old task
+// again doesn't spawn a second copy. It returns nil when the task doesn't
+// recur or its series has ended. The gRPC service completes tasks through
+// it too.
+func MaterializeNextOccurrence(ctx context.Context, tasks store.TaskStore, scope models.Scope, workflow models.Workflow, task *models.Task) (*models.Task, error) {
+	if task.Recurrence == nil || task.DueDate == nil {
+		return nil, nil
+	}
+
+	// Expand the rule in the zone the due date was set in, so "every day
+	// at 9" stays at 9 across DST changes.
+	current := *task.DueDate
+	if task.DueTimezone != nil {
+		if loc, ok := loadTimezone(*task.DueTimezone); ok {
+			current = current.In(loc)
+		}
+	}
+
+	due, rule, ok, err := recurrence.Next(*task.Recurrence, current)
+	if err != nil || !ok {
+		// A rule that no longer parses shouldn't block completing the task.
+		return nil, nil
+	}
+	due = due.UTC()
+
+	next, err := tasks.Create(ctx, scope, models.CreateTaskInput{
+		Title:        task.Title,
+		Description:  task.Description,
+		Status:       workflow.DefaultStatus(),
+		Priority:     task.Priority,
+		DueDate:      &due,
+		DueTimezone:  task.DueTimezone,
+		Recurrence:   &rule,
+		ProjectID:    task.ProjectID,
+		ParentID:     task.ParentID,
+		Visibility:   task.Visibility,
+		CustomFields: task.CustomFields,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := tasks.Update(ctx, scope, task.ID, models.UpdateTaskInput{
+		Recurrence: models.Nullable[string]{Set: true, Null: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	*task = *updated
+
+	return next, nil
+}
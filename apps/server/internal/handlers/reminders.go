@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// A week covers "remind me the Monday before" without allowing nonsense.
+const maxMinutesBefore = 7 * 24 * 60
+
+func CreateReminderHandler(reminders store.ReminderStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.CreateReminderInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if (input.RemindAt == nil) == (input.MinutesBefore == nil) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Exactly one of remindAt or minutesBefore is required"))
+			return
+		}
+		if input.MinutesBefore != nil && (*input.MinutesBefore < 0 || *input.MinutesBefore > maxMinutesBefore) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid minutesBefore"))
+			return
+		}
+
+		reminder, err := reminders.Create(c.Request.Context(), scope, taskID, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create reminder", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create reminder", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, reminder)
+	}
+}
+
+func ListRemindersHandler(reminders store.ReminderStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		list, err := reminders.List(c.Request.Context(), scope, taskID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list reminders", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list reminders", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"reminders": list})
+	}
+}
+
+func DeleteReminderHandler(reminders store.ReminderStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("reminderId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Reminder not found"))
+			return
+		}
+
+		err := reminders.Delete(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Reminder not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete reminder", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete reminder", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
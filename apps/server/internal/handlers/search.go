@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+	maxSearchQuery     = 200
+)
+
+func SearchHandler(search store.SearchStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		q := strings.TrimSpace(c.Query("q"))
+		if q == "" || len(q) > maxSearchQuery {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Query must be between 1 and 200 characters"))
+			return
+		}
+
+		limit := defaultSearchLimit
+		if raw := c.Query("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid limit"))
+				return
+			}
+			limit = min(n, maxSearchLimit)
+		}
+
+		results, err := search.SearchTasks(c.Request.Context(), scope, q, limit)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to search tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to search", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"results": results})
+	}
+}
+
+const defaultSuggestLimit = 10
+
+// SuggestHandler powers the label, member and project pickers: matches for
+// what's been typed so far, or, with no q, the most recently used.
+func SuggestHandler(search store.SearchStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		kind := c.Query("type")
+		if kind != models.SuggestLabel && kind != models.SuggestMember && kind != models.SuggestProject {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Type must be one of: label, member, project"))
+			return
+		}
+		q := strings.TrimSpace(c.Query("q"))
+		if len(q) > maxSearchQuery {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Query must be at most 200 characters"))
+			return
+		}
+
+		limit := defaultSuggestLimit
+		if raw := c.Query("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid limit"))
+				return
+			}
+			limit = min(n, maxSearchLimit)
+		}
+
+		suggestions, err := search.Suggest(c.Request.Context(), scope, kind, q, limit)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to suggest", "type", kind, "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to suggest", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"suggestions": suggestions})
+	}
+}
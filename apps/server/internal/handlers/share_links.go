@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/sharelinks"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUserAgentLength keeps one noisy client from bloating the access log.
+const maxUserAgentLength = 512
+
+// ShareLinkURLs turns links into the public URLs handed out for them.
+type ShareLinkURLs struct {
+	Signer *sharelinks.Signer
+	// BaseURL is where the API is reachable from outside; without it URLs
+	// are relative to the API's own host.
+	BaseURL string
+}
+
+func (u ShareLinkURLs) fill(l *models.ShareLink) {
+	l.URL = strings.TrimRight(u.BaseURL, "/") + "/share/" + u.Signer.Sign(l.ID, l.ExpiresAt)
+}
+
+func CreateTaskShareLinkHandler(links store.ShareLinkStore, urls ShareLinkURLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		createShareLink(c, links, urls, models.CreateShareLinkInput{TaskID: &id}, "Task not found")
+	}
+}
+
+func CreateProjectShareLinkHandler(links store.ShareLinkStore, urls ShareLinkURLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		createShareLink(c, links, urls, models.CreateShareLinkInput{ProjectID: &id}, "Project not found")
+	}
+}
+
+func createShareLink(c *gin.Context, links store.ShareLinkStore, urls ShareLinkURLs, input models.CreateShareLinkInput, notFound string) {
+	scope, ok := scopeFromContext(c)
+	if !ok {
+		apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+		return
+	}
+
+	// The body is optional; without one the link gets the default expiry.
+	target := input
+	if !bindOptionalJSON(c, &input) {
+		return
+	}
+	input.TaskID, input.ProjectID = target.TaskID, target.ProjectID
+
+	now := time.Now()
+	if input.ExpiresAt == nil {
+		expiresAt := now.Add(models.DefaultShareLinkTTL)
+		input.ExpiresAt = &expiresAt
+	}
+	if !input.ExpiresAt.After(now) || input.ExpiresAt.After(now.Add(models.MaxShareLinkTTL)) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid expiry").WithDetails(gin.H{"maxExpiresAt": now.Add(models.MaxShareLinkTTL).UTC()}))
+		return
+	}
+
+	link, err := links.Create(c.Request.Context(), scope, input)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.NotFound(notFound))
+		return
+	}
+	if errors.Is(err, store.ErrReadOnly) {
+		apierror.Respond(c, apierror.Forbidden("Task is read-only"))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create share link", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to create share link", err))
+		return
+	}
+
+	urls.fill(link)
+	c.JSON(http.StatusCreated, link)
+}
+
+func ListTaskShareLinksHandler(links store.ShareLinkStore, urls ShareLinkURLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		list, err := links.ListForTask(c.Request.Context(), scope, id)
+		respondShareLinks(c, urls, list, err)
+	}
+}
+
+func ListProjectShareLinksHandler(links store.ShareLinkStore, urls ShareLinkURLs) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		list, err := links.ListForProject(c.Request.Context(), scope, id)
+		respondShareLinks(c, urls, list, err)
+	}
+}
+
+func respondShareLinks(c *gin.Context, urls ShareLinkURLs, list []models.ShareLink, err error) {
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list share links", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to list share links", err))
+		return
+	}
+
+	for i := range list {
+		urls.fill(&list[i])
+	}
+	c.JSON(http.StatusOK, gin.H{"shareLinks": list})
+}
+
+// RevokeShareLinkHandler lets the link's creator turn it off before it
+// expires. The link is kept so its access log stays readable.
+func RevokeShareLinkHandler(links store.ShareLinkStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Share link not found"))
+			return
+		}
+
+		_, err := links.Revoke(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Share link not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to revoke share link", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to revoke share link", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ListShareLinkAccessesHandler(links store.ShareLinkStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Share link not found"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "share_link_accesses")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		list, err := links.ListAccesses(c.Request.Context(), scope, id, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list share link accesses", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list accesses", err))
+			return
+		}
+
+		list, hasMore := api.Trim(list, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			last := list[len(list)-1]
+			pageInfo.NextCursor = api.EncodeCursor("share_link_accesses", last.AccessedAt.Format(time.RFC3339Nano), last.ID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"accesses": list, "pageInfo": pageInfo})
+	}
+}
+
+// PublicShareStores are the stores PublicShareHandler reads.
+type PublicShareStores struct {
+	Links    store.ShareLinkStore
+	Tasks    store.TaskStore
+	Projects store.ProjectStore
+	Users    store.UserStore
+}
+
+// PublicShareHandler serves GET /share/:token without authentication. The
+// link reads as its creator, with their current role in the org, so it stops
+// working once they leave or lose access to what was shared, and every
+// successful open is logged.
+func PublicShareHandler(stores PublicShareStores, signer *sharelinks.Signer) gin.HandlerFunc {
+	links, tasks, projects := stores.Links, stores.Tasks, stores.Projects
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-store")
+		c.Header("X-Robots-Tag", "noindex")
+
+		now := time.Now()
+		id, err := signer.Verify(c.Param("token"), now)
+		if errors.Is(err, sharelinks.ErrExpiredToken) {
+			apierror.Respond(c, apierror.New(http.StatusGone, "Share link has expired"))
+			return
+		}
+		if err != nil || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Share link not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		link, err := links.Get(ctx, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Share link not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get share link", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to open share link", err))
+			return
+		}
+		if !link.Active(now) {
+			apierror.Respond(c, apierror.New(http.StatusGone, "Share link is no longer available"))
+			return
+		}
+
+		scope := link.Scope()
+		if scope.IsOrg() {
+			member, err := stores.Users.GetMember(ctx, scope.OrgID, scope.UserID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Share link not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get member", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to open share link", err))
+				return
+			}
+			scope.Guest = member.Role == middlewares.OrgGuestRole
+		}
+
+		var body gin.H
+		if link.TaskID != nil {
+			task, err := tasks.Get(ctx, scope, *link.TaskID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Share link not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get shared task", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to open share link", err))
+				return
+			}
+			body = gin.H{"task": models.NewPublicTask(*task)}
+		} else {
+			project, err := projects.Get(ctx, scope.OrgID, *link.ProjectID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Share link not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get shared project", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to open share link", err))
+				return
+			}
+
+			// The list runs as the link's creator, who may see private tasks
+			// and tasks shared with them. Only what the project shows everyone
+			// goes out: the org's tasks in it, or in a personal project the
+			// creator's own.
+			filter := models.TaskFilter{ProjectID: project.ID, Sort: models.DefaultTaskSort}
+			if scope.OrgID != "" {
+				filter.Visibility = models.TaskVisibilityOrg
+			} else {
+				filter.OwnerID = link.CreatedBy
+			}
+			list, err := tasks.List(ctx, scope, filter, models.Page{Limit: api.MaxLimit})
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to list shared tasks", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to open share link", err))
+				return
+			}
+			list, _ = api.Trim(list, api.MaxLimit)
+
+			public := make([]models.PublicTask, 0, len(list))
+			for _, t := range list {
+				public = append(public, models.NewPublicTask(t))
+			}
+			body = gin.H{"project": gin.H{"id": project.ID, "name": project.Name}, "tasks": public}
+		}
+
+		userAgent := c.Request.UserAgent()
+		if len(userAgent) > maxUserAgentLength {
+			userAgent = userAgent[:maxUserAgentLength]
+		}
+		if err := links.RecordAccess(ctx, link.ID, c.ClientIP(), userAgent); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to record share link access", "error", err)
+		}
+
+		body["expiresAt"] = link.ExpiresAt
+		c.JSON(http.StatusOK, body)
+	}
+}
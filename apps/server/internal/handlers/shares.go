@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bindShareInput reads a share request and checks the role and that the
+// user is in the active org.
+func bindShareInput(c *gin.Context, users store.UserStore, scope models.Scope) (models.ShareInput, bool) {
+	var input models.ShareInput
+	if !bindJSON(c, &input) {
+		return input, false
+	}
+	if !models.ValidShareRole(input.Role) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid role"))
+		return input, false
+	}
+	if input.UserID == scope.UserID {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Cannot share with yourself"))
+		return input, false
+	}
+
+	_, err := users.GetMember(c.Request.Context(), scope.OrgID, input.UserID)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "User is not a member of this organization"))
+		return input, false
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get member", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to share", err))
+		return input, false
+	}
+	return input, true
+}
+
+// ownTask is for the task share handlers: only the task's owner decides who
+// else can see it.
+func ownTask(c *gin.Context, tasks store.TaskStore, scope models.Scope, id string) bool {
+	task, err := tasks.Get(c.Request.Context(), scope, id)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.NotFound("Task not found"))
+		return false
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get task", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to get task", err))
+		return false
+	}
+	if task.OwnerID != scope.UserID {
+		apierror.Respond(c, apierror.Forbidden("Only the owner can share this task"))
+		return false
+	}
+	return true
+}
+
+func ShareTaskHandler(shares store.ShareStore, tasks store.TaskStore, users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if !ownTask(c, tasks, scope, id) {
+			return
+		}
+		input, ok := bindShareInput(c, users, scope)
+		if !ok {
+			return
+		}
+
+		share, err := shares.ShareTask(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to share task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to share task", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, share)
+	}
+}
+
+func ListTaskSharesHandler(shares store.ShareStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		list, err := shares.ListTaskShares(c.Request.Context(), scope, id)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list task shares", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list shares", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"shares": list})
+	}
+}
+
+func UnshareTaskHandler(shares store.ShareStore, tasks store.TaskStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if !ownTask(c, tasks, scope, id) {
+			return
+		}
+
+		err := shares.UnshareTask(c.Request.Context(), scope, id, c.Param("userId"))
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Share not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to unshare task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to unshare task", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ShareProjectHandler(shares store.ShareStore, users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		input, ok := bindShareInput(c, users, scope)
+		if !ok {
+			return
+		}
+
+		share, err := shares.ShareProject(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to share project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to share project", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, share)
+	}
+}
+
+func ListProjectSharesHandler(shares store.ShareStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		list, err := shares.ListProjectShares(c.Request.Context(), scope, id)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list project shares", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list shares", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"shares": list})
+	}
+}
+
+func UnshareProjectHandler(shares store.ShareStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		err := shares.UnshareProject(c.Request.Context(), scope, id, c.Param("userId"))
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Share not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to unshare project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to unshare project", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/slack"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxSlackChannel is Slack's limit on channel names.
+	maxSlackChannel = 80
+	// maxSlackCommand caps a slash command's form body.
+	maxSlackCommand = 16 << 10
+)
+
+var slackTeamID = regexp.MustCompile(`^T[A-Z0-9]{2,20}$`)
+
+func GetSlackIntegrationHandler(integrations store.SlackStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		integration, err := integrations.Get(c.Request.Context(), scope.OrgID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Slack is not connected"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get Slack integration", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get Slack integration", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, integration)
+	}
+}
+
+// SetSlackIntegrationHandler saves the integration. Its workspace is the
+// one Slack says the bot token belongs to, never one the caller names, so
+// an org can't take over another workspace's slash commands.
+func SetSlackIntegrationHandler(integrations store.SlackStore, client *slack.Client) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.SetSlackIntegrationInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		for _, ch := range []*string{input.TaskAssignedChannel, input.DueSoonChannel} {
+			if ch != nil && (strings.TrimSpace(*ch) == "" || len(*ch) > maxSlackChannel) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid channel"))
+				return
+			}
+		}
+		if t := input.BotToken; t.Set && !t.Null && !strings.HasPrefix(t.Value, "xoxb-") {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid botToken: use the bot user OAuth token"))
+			return
+		}
+		if u := input.WebhookURL; u.Set && !u.Null && !strings.HasPrefix(u.Value, slack.WebhookPrefix) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid webhookUrl"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		switch t := input.BotToken; {
+		case t.Set && !t.Null:
+			teamID, err := client.TeamID(ctx, t.Value)
+			var apiErr *slack.APIError
+			if errors.As(err, &apiErr) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid botToken: Slack answered "+apiErr.Code))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to check Slack bot token", "error", err)
+				apierror.Respond(c, apierror.New(http.StatusBadGateway, "Failed to reach Slack"))
+				return
+			}
+			if !slackTeamID.MatchString(teamID) {
+				slog.ErrorContext(ctx, "Slack returned an unexpected team id", "team_id", teamID)
+				apierror.Respond(c, apierror.New(http.StatusBadGateway, "Failed to reach Slack"))
+				return
+			}
+			input.TeamID = &teamID
+		case !t.Set:
+			// The stored token, and so its workspace, stays.
+			current, err := integrations.Get(ctx, scope.OrgID)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				slog.ErrorContext(ctx, "Failed to get Slack integration", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to save Slack integration", err))
+				return
+			}
+			if err == nil {
+				input.TeamID = current.TeamID
+			}
+		}
+
+		integration, err := integrations.Set(ctx, scope.OrgID, input)
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("That Slack workspace is connected to another organization"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to save Slack integration", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to save Slack integration", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, integration)
+	}
+}
+
+func DeleteSlackIntegrationHandler(integrations store.SlackStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		err := integrations.Delete(c.Request.Context(), scope.OrgID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Slack is not connected"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete Slack integration", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete Slack integration", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// SlackCommandStores is what slash commands read and write.
+type SlackCommandStores struct {
+	Integrations store.SlackStore
+	Users        store.UserStore
+	Tasks        store.TaskStore
+	Workflows    store.WorkflowStore
+	OrgSettings  store.OrgSettingsStore
+}
+
+const slackUsage = "Usage: `/yata add <task title>` adds a task in this workspace's organization."
+
+// SlackCommandHandler answers the /yata slash command. Requests are
+// authenticated by Slack's signature alone; the workspace picks the org and
+// the sender's Slack email picks the member they act as. Slack shows any
+// 200 reply to the sender, so failures are answered that way too.
+func SlackCommandHandler(signingSecret string, client *slack.Client, stores SlackCommandStores, appURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxSlackCommand+1))
+		if err != nil || len(body) > maxSlackCommand {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+		err = slack.VerifySignature(signingSecret, c.GetHeader("X-Slack-Request-Timestamp"), c.GetHeader("X-Slack-Signature"), body, time.Now())
+		if err != nil {
+			apierror.Respond(c, apierror.Unauthorized("Invalid signature"))
+			return
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+		reply := func(text string) {
+			c.JSON(http.StatusOK, gin.H{"response_type": "ephemeral", "text": text})
+		}
+
+		verb, rest, _ := strings.Cut(strings.TrimSpace(form.Get("text")), " ")
+		title := strings.TrimSpace(rest)
+		if !strings.EqualFold(verb, "add") || title == "" {
+			reply(slackUsage)
+			return
+		}
+
+		integration, err := stores.Integrations.GetByTeam(ctx, form.Get("team_id"))
+		if errors.Is(err, store.ErrNotFound) {
+			reply("This Slack workspace isn't connected to a yata organization.")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get Slack integration", "error", err)
+			reply("Something went wrong; try again in a moment.")
+			return
+		}
+		if integration.BotToken == "" {
+			reply("Ask an admin to add the Slack bot token in yata, so it can tell who you are.")
+			return
+		}
+
+		email, err := client.UserEmail(ctx, integration.BotToken, form.Get("user_id"))
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to look up Slack user", "error", err)
+			reply("Something went wrong; try again in a moment.")
+			return
+		}
+		member, err := stores.Users.FindMemberByEmail(ctx, integration.OrgID, email)
+		if email == "" || errors.Is(err, store.ErrNotFound) {
+			reply("No one in the yata organization has your Slack email address.")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to find member by email", "error", err)
+			reply("Something went wrong; try again in a moment.")
+			return
+		}
+		scope := models.Scope{UserID: member.ID, OrgID: integration.OrgID, Guest: member.Role == middlewares.OrgGuestRole}
+
+		task, err := createMessageTask(ctx, stores.Tasks, stores.Workflows, stores.OrgSettings, scope, models.CreateTaskInput{Title: title})
+		if isQuotaExceeded(err) {
+			reply(quotaReply)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create task from Slack", "error", err)
+			reply("Something went wrong; try again in a moment.")
+			return
+		}
+		text := "Added *" + slack.Escape(task.Title) + "*"
+		if appURL != "" {
+			text = "Added *<" + strings.TrimRight(appURL, "/") + "/tasks/" + task.ID + "|" + slack.Escape(task.Title) + ">*"
+		}
+		reply(text)
+	}
+}
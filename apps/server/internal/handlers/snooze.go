@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	snoozeLaterToday  = 3 * time.Hour
+	snoozeMorningHour = 9
+	maxSnooze         = 365 * 24 * time.Hour
+)
+
+// snoozeUntil is when preset ends, counting days in loc.
+func snoozeUntil(preset string, now time.Time, loc *time.Location, weekStart time.Weekday) (time.Time, bool) {
+	local := now.In(loc)
+	morning := func(days int) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days, snoozeMorningHour, 0, 0, 0, loc)
+	}
+
+	switch preset {
+	case models.SnoozeLaterToday:
+		return now.Add(snoozeLaterToday), true
+	case models.SnoozeTomorrow:
+		return morning(1), true
+	case models.SnoozeNextWeek:
+		days := (int(weekStart) - int(local.Weekday()) + 7) % 7
+		if days == 0 {
+			days = 7
+		}
+		return morning(days), true
+	}
+	return time.Time{}, false
+}
+
+// SnoozeTaskHandler hides a task from today's lists and holds back its
+// reminders until a preset or a given time. Presets go by the requester's
+// zone, from X-Timezone or else their settings, and their week start.
+func SnoozeTaskHandler(tasks store.TaskStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.SnoozeTaskInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if (input.Preset == "") == (input.Until == nil) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Exactly one of preset or until is required"))
+			return
+		}
+
+		now := time.Now()
+		var until time.Time
+		if input.Until != nil {
+			until = *input.Until
+		} else {
+			loc, ok := requestTimezone(c)
+			if !ok {
+				return
+			}
+			s, err := settings.Get(c.Request.Context(), scope.UserID)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get settings", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to snooze task", err))
+				return
+			}
+			if loc == nil {
+				loc = s.Location()
+			}
+			if until, ok = snoozeUntil(input.Preset, now, loc, s.WeekStartDay()); !ok {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid preset"))
+				return
+			}
+		}
+		if !until.After(now) || until.Sub(now) > maxSnooze {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "until must be in the future, and at most a year away"))
+			return
+		}
+
+		writeSnoozedTask(c, tasks, scope, id, &until)
+	}
+}
+
+// UnsnoozeTaskHandler ends a task's snooze early.
+func UnsnoozeTaskHandler(tasks store.TaskStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		writeSnoozedTask(c, tasks, scope, id, nil)
+	}
+}
+
+func writeSnoozedTask(c *gin.Context, tasks store.TaskStore, scope models.Scope, id string, until *time.Time) {
+	task, err := tasks.Snooze(c.Request.Context(), scope, id, until)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.NotFound("Task not found"))
+		return
+	}
+	if errors.Is(err, store.ErrReadOnly) {
+		apierror.Respond(c, apierror.Forbidden("Task is read-only"))
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to snooze task", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to snooze task", err))
+		return
+	}
+
+	setTaskETag(c, task)
+	c.JSON(http.StatusOK, task)
+}
@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// statsRange is the periods a stats request covers, as local midnights in
+// the requester's zone: each period runs from one bound to the next.
+type statsRange struct {
+	interval string
+	bounds   []time.Time
+	now      time.Time
+}
+
+// dates names each period by the date it starts on.
+func (r statsRange) dates() []string {
+	dates := make([]string, len(r.bounds)-1)
+	for i := range dates {
+		dates[i] = r.bounds[i].Format(time.DateOnly)
+	}
+	return dates
+}
+
+// points is when each period ends, or now for one that hasn't yet.
+func (r statsRange) points() []time.Time {
+	points := make([]time.Time, len(r.bounds)-1)
+	for i := range points {
+		points[i] = r.bounds[i+1]
+		if points[i].After(r.now) {
+			points[i] = r.now
+		}
+	}
+	return points
+}
+
+// parseStatsRange reads interval (day or week), from and to. Dates are taken
+// in the X-Timezone zone, or the user's own, and both ends are inclusive.
+// Without them the range is the last 30 days or 12 weeks, and weeks start on
+// the user's week start. It writes the error response when it fails.
+func parseStatsRange(c *gin.Context, settings store.UserSettingsStore, scope models.Scope) (statsRange, bool) {
+	loc, ok := requestTimezone(c)
+	if !ok {
+		return statsRange{}, false
+	}
+	ctx := c.Request.Context()
+	s, err := settings.Get(ctx, scope.UserID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get user settings", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to get stats", err))
+		return statsRange{}, false
+	}
+	if loc == nil {
+		loc = s.Location()
+	}
+
+	r := statsRange{interval: c.DefaultQuery("interval", models.StatsIntervalDay), now: time.Now().In(loc)}
+	step, periods := 1, 30
+	switch r.interval {
+	case models.StatsIntervalDay:
+	case models.StatsIntervalWeek:
+		step, periods = 7, 12
+	default:
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "interval must be day or week"))
+		return statsRange{}, false
+	}
+
+	date := func(name string, fallback time.Time) (time.Time, bool) {
+		raw := c.Query(name)
+		if raw == "" {
+			return fallback, true
+		}
+		d, err := time.ParseInLocation(time.DateOnly, raw, loc)
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid "+name+"; use YYYY-MM-DD"))
+			return time.Time{}, false
+		}
+		return d, true
+	}
+	today := time.Date(r.now.Year(), r.now.Month(), r.now.Day(), 0, 0, 0, 0, loc)
+	to, ok := date("to", today)
+	if !ok {
+		return statsRange{}, false
+	}
+	from, ok := date("from", to.AddDate(0, 0, -step*(periods-1)))
+	if !ok {
+		return statsRange{}, false
+	}
+	if to.Before(from) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "to must not be before from"))
+		return statsRange{}, false
+	}
+	if step == 7 {
+		from = from.AddDate(0, 0, -((int(from.Weekday()) - int(s.WeekStartDay()) + 7) % 7))
+	}
+
+	end := to.AddDate(0, 0, 1)
+	for d := from; d.Before(end); d = d.AddDate(0, 0, step) {
+		if len(r.bounds) == models.MaxStatsPeriods {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "The range can cover at most 366 periods"))
+			return statsRange{}, false
+		}
+		r.bounds = append(r.bounds, d)
+	}
+	r.bounds = append(r.bounds, r.bounds[len(r.bounds)-1].AddDate(0, 0, step))
+	return r, true
+}
+
+// CompletedStatsHandler counts the tasks completed in each day or week.
+func CompletedStatsHandler(stats store.StatsStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		r, ok := parseStatsRange(c, settings, scope)
+		if !ok {
+			return
+		}
+
+		counts, err := stats.Completed(c.Request.Context(), scope, r.bounds)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to count completed tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get stats", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, statsSeries(r, counts))
+	}
+}
+
+// OverdueStatsHandler counts the tasks that were overdue at the end of each
+// day or week.
+func OverdueStatsHandler(stats store.StatsStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		r, ok := parseStatsRange(c, settings, scope)
+		if !ok {
+			return
+		}
+
+		counts, err := stats.Overdue(c.Request.Context(), scope, r.points())
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to count overdue tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get stats", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, statsSeries(r, counts))
+	}
+}
+
+func statsSeries(r statsRange, counts []int) models.StatsSeries {
+	series := models.StatsSeries{Interval: r.interval, Points: make([]models.StatsCount, 0, len(counts))}
+	for i, date := range r.dates() {
+		if i < len(counts) {
+			series.Points = append(series.Points, models.StatsCount{Date: date, Count: counts[i]})
+		}
+	}
+	return series
+}
+
+// ThroughputStatsHandler counts what each org member completed of the tasks
+// they own between from and to.
+func ThroughputStatsHandler(stats store.StatsStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		r, ok := parseStatsRange(c, settings, scope)
+		if !ok {
+			return
+		}
+
+		from, to := r.bounds[0], r.bounds[len(r.bounds)-1]
+		members, err := stats.Throughput(c.Request.Context(), scope, from, to)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get throughput", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get stats", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, models.ThroughputStats{
+			From:    from.Format(time.DateOnly),
+			To:      to.AddDate(0, 0, -1).Format(time.DateOnly),
+			Members: members,
+		})
+	}
+}
+
+// defaultWorkloadDays is the range WorkloadHandler covers without from and
+// to: today and the thirteen days after it.
+const defaultWorkloadDays = 14
+
+// WorkloadHandler sums up each org member's open tasks due from one date to
+// another, both inclusive in the X-Timezone zone or the user's own, so work
+// can be spread across the team.
+func WorkloadHandler(stats store.StatsStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		ctx := c.Request.Context()
+		if loc == nil {
+			s, err := settings.Get(ctx, scope.UserID)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get user settings", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to get workload", err))
+				return
+			}
+			loc = s.Location()
+		}
+
+		date := func(name string, fallback time.Time) (time.Time, bool) {
+			raw := c.Query(name)
+			if raw == "" {
+				return fallback, true
+			}
+			d, err := time.ParseInLocation(time.DateOnly, raw, loc)
+			if err != nil {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid "+name+"; use YYYY-MM-DD"))
+				return time.Time{}, false
+			}
+			return d, true
+		}
+		now := time.Now().In(loc)
+		from, ok := date("from", time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc))
+		if !ok {
+			return
+		}
+		to, ok := date("to", from.AddDate(0, 0, defaultWorkloadDays-1))
+		if !ok {
+			return
+		}
+		if to.Before(from) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "to must not be before from"))
+			return
+		}
+		if to.After(from.AddDate(0, 0, models.MaxStatsPeriods-1)) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "The range can cover at most 366 days"))
+			return
+		}
+
+		members, err := stats.Workload(ctx, scope, from, to.AddDate(0, 0, 1))
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get workload", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get workload", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, models.WorkloadStats{
+			From:    from.Format(time.DateOnly),
+			To:      to.Format(time.DateOnly),
+			Members: members,
+		})
+	}
+}
+
+// BurndownStatsHandler reports a project's total and open tasks at the end
+// of each day or week.
+func BurndownStatsHandler(stats store.StatsStore, projects store.ProjectStore, settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		if _, err := projects.Get(ctx, scope.OrgID, id); errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		} else if err != nil {
+			slog.ErrorContext(ctx, "Failed to get project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get stats", err))
+			return
+		}
+
+		r, ok := parseStatsRange(c, settings, scope)
+		if !ok {
+			return
+		}
+
+		points, err := stats.Burndown(ctx, scope, id, r.points())
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get burndown", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get stats", err))
+			return
+		}
+		for i, date := range r.dates() {
+			if i < len(points) {
+				points[i].Date = date
+			}
+		}
+
+		c.JSON(http.StatusOK, models.BurndownStats{Interval: r.interval, Points: points})
+	}
+}
@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SyncPushHandler applies a batch of offline edits. Invalid ops are
+// reported as rejected without failing the rest of the batch, but an op on
+// a task id that's taken outside the caller's scope fails it with 409.
+func SyncPushHandler(sync store.SyncStore, projects store.ProjectStore, workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.SyncPushInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		now := time.Now()
+		valid := []models.SyncOp{}
+		rejected := map[string]string{}
+		for _, op := range input.Ops {
+			if err := validateSyncOp(c, projects, workflow, scope, op, now); err != nil {
+				rejected[op.ID] = err.Error()
+				continue
+			}
+			valid = append(valid, op)
+		}
+
+		applied, checkpoint, err := sync.Push(c.Request.Context(), scope, workflow.DefaultStatus(), valid)
+		if quotaExceeded(c, err) {
+			return
+		}
+		var conflict *store.SyncConflictError
+		if errors.As(err, &conflict) {
+			apierror.Respond(c, apierror.Conflict("taskId is already used by another task").WithDetails(gin.H{"opId": conflict.OpID, "taskId": conflict.TaskID}))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to apply sync ops", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to apply sync ops", err))
+			return
+		}
+
+		// Report results in the order the ops were sent.
+		results := make([]models.SyncResult, 0, len(input.Ops))
+		for _, op := range input.Ops {
+			if reason, ok := rejected[op.ID]; ok {
+				results = append(results, models.SyncResult{ID: op.ID, Status: models.SyncRejected, Error: reason})
+				continue
+			}
+			results = append(results, applied[0])
+			applied = applied[1:]
+		}
+
+		c.JSON(http.StatusOK, gin.H{"results": results, "checkpoint": checkpoint})
+	}
+}
+
+func validateSyncOp(c *gin.Context, projects store.ProjectStore, workflow models.Workflow, scope models.Scope, op models.SyncOp, now time.Time) error {
+	if !isValidID(op.ID) || !isValidID(op.TaskID) {
+		return errors.New("invalid id")
+	}
+	if err := op.Timestamp.Validate(now); err != nil {
+		return err
+	}
+	if op.Type == models.SyncOpDelete {
+		return nil
+	}
+	if !slices.Contains(models.SyncFields, op.Field) {
+		return errors.New("unknown field")
+	}
+
+	null := len(op.Value) == 0 || string(op.Value) == "null"
+	switch op.Field {
+	case "title", "description", "status":
+		var s string
+		if null || json.Unmarshal(op.Value, &s) != nil {
+			return errors.New("invalid " + op.Field)
+		}
+		if op.Field == "title" && strings.TrimSpace(s) == "" {
+			return errors.New("title is required")
+		}
+		if op.Field == "status" && !workflow.IsValidStatus(s) {
+			return errors.New("invalid status")
+		}
+	case "priority":
+		var p int
+		if null || json.Unmarshal(op.Value, &p) != nil || !workflow.IsValidPriority(p) {
+			return errors.New("invalid priority")
+		}
+	case "dueDate":
+		var t time.Time
+		if !null && json.Unmarshal(op.Value, &t) != nil {
+			return errors.New("invalid dueDate")
+		}
+	case "dueTimezone":
+		var tz string
+		if !null {
+			if json.Unmarshal(op.Value, &tz) != nil {
+				return errors.New("invalid dueTimezone")
+			}
+			if _, ok := loadTimezone(tz); !ok {
+				return errors.New("invalid dueTimezone")
+			}
+		}
+	case "projectId":
+		if null {
+			return nil
+		}
+		var id string
+		if !scope.IsOrg() || json.Unmarshal(op.Value, &id) != nil || !isValidID(id) {
+			return errors.New("invalid projectId")
+		}
+		if _, err := projects.Get(c.Request.Context(), scope.OrgID, id); err != nil {
+			return errors.New("invalid projectId")
+		}
+	}
+	return nil
+}
+
+// SyncPullHandler returns ops applied after ?since=<checkpoint>. Edits made
+// through the regular task endpoints aren't in the log, so clients load
+// their initial state from GET /tasks and only pull afterwards.
+func SyncPullHandler(sync store.SyncStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var since int64
+		if raw := c.Query("since"); raw != "" {
+			var err error
+			since, err = strconv.ParseInt(raw, 10, 64)
+			if err != nil || since < 0 {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid since"))
+				return
+			}
+		}
+
+		limit, ok := syncLimit(c)
+		if !ok {
+			return
+		}
+
+		ops, err := sync.Pull(c.Request.Context(), scope, since, limit)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to pull sync ops", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to pull sync ops", err))
+			return
+		}
+
+		ops, hasMore := api.Trim(ops, limit)
+		checkpoint := since
+		if len(ops) > 0 {
+			checkpoint = ops[len(ops)-1].Seq
+		}
+
+		c.JSON(http.StatusOK, gin.H{"ops": ops, "checkpoint": checkpoint, "hasMore": hasMore})
+	}
+}
+
+// syncLimit reads ?limit=, capped at api.MaxLimit; a bad one has already
+// been answered with 400.
+func syncLimit(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return api.DefaultLimit, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		apierror.Respond(c, &apierror.Error{Status: http.StatusBadRequest, Code: "INVALID_LIMIT", Message: "Invalid limit"})
+		return 0, false
+	}
+	return min(n, api.MaxLimit), true
+}
+
+// changeCursorKind signs delta sync cursors, which also carry when they were
+// issued so ones older than the change log's retention can be refused.
+const changeCursorKind = "changes"
+
+// SyncChangesHandler returns the tasks, projects and labels created,
+// updated or deleted since ?since=, a cursor from an earlier call, with the
+// cursor to pass next time. Without since it returns only a cursor to start
+// from: fetch it before loading the lists, so nothing written in between is
+// missed. A cursor older than retention gets 410, as what changed since may
+// have been pruned.
+func SyncChangesHandler(changes store.ChangeStore, labels store.LabelStore, retention time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		limit, ok := syncLimit(c)
+		if !ok {
+			return
+		}
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+
+		raw := c.Query("since")
+		if raw == "" {
+			cursor, err := changes.Current(c.Request.Context(), scope)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get change cursor", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to sync changes", err))
+				return
+			}
+			c.JSON(http.StatusOK, gin.H{"cursor": encodeChangeCursor(cursor)})
+			return
+		}
+
+		since, issued, err := decodeChangeCursor(raw)
+		if err != nil {
+			apierror.Respond(c, &apierror.Error{Status: http.StatusBadRequest, Code: "INVALID_CURSOR", Message: "Invalid since"})
+			return
+		}
+		if time.Since(issued) > retention {
+			apierror.Respond(c, &apierror.Error{Status: http.StatusGone, Code: "SYNC_CURSOR_EXPIRED", Message: "Sync cursor expired"})
+			return
+		}
+
+		result, err := changes.Since(c.Request.Context(), scope, since, limit)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list changes", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to sync changes", err))
+			return
+		}
+		if err := loadTaskLabels(c.Request.Context(), labels, scope, result.Tasks); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to load task labels", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to sync changes", err))
+			return
+		}
+		now := time.Now()
+		for i := range result.Tasks {
+			setDueToday(&result.Tasks[i], loc, now)
+		}
+
+		result.Cursor = encodeChangeCursor(result.Next)
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+func encodeChangeCursor(cursor models.ChangeCursor) string {
+	return api.EncodeCursor(changeCursorKind, cursor.Tx, strconv.FormatInt(cursor.Seq, 10), strconv.FormatInt(time.Now().Unix(), 10))
+}
+
+func decodeChangeCursor(raw string) (models.ChangeCursor, time.Time, error) {
+	values, err := api.DecodeCursor(changeCursorKind, raw)
+	if err != nil {
+		return models.ChangeCursor{}, time.Time{}, err
+	}
+	if len(values) != 3 {
+		return models.ChangeCursor{}, time.Time{}, api.ErrInvalidCursor
+	}
+	seq, err := strconv.ParseInt(values[1], 10, 64)
+	if err != nil {
+		return models.ChangeCursor{}, time.Time{}, api.ErrInvalidCursor
+	}
+	issued, err := strconv.ParseInt(values[2], 10, 64)
+	if err != nil {
+		return models.ChangeCursor{}, time.Time{}, api.ErrInvalidCursor
+	}
+	return models.ChangeCursor{Tx: values[0], Seq: seq}, time.Unix(issued, 0), nil
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AddTaskDependencyHandler marks the task in the body as blocking :id.
+func AddTaskDependencyHandler(tasks store.TaskStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.AddDependencyInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if !isValidID(input.BlockedByID) {
+			invalidField(c, "blockedById", "must be an ID")
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		err := tasks.AddDependency(ctx, scope, id, input.BlockedByID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrDependencyCycle) {
+			apierror.Respond(c, apierror.Conflict("Dependency would create a cycle"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to add task dependency", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to add dependency", err))
+			return
+		}
+
+		deps, err := tasks.Dependencies(ctx, scope, id)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get task dependencies", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to add dependency", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, deps)
+	}
+}
+
+func RemoveTaskDependencyHandler(tasks store.TaskStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		blockedByID := c.Param("blockedById")
+		if !isValidID(id) || !isValidID(blockedByID) {
+			apierror.Respond(c, apierror.NotFound("Dependency not found"))
+			return
+		}
+
+		err := tasks.RemoveDependency(c.Request.Context(), scope, id, blockedByID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Dependency not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to remove task dependency", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to remove dependency", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseTaskFilter turns the list query string, e.g.
+// ?status=open&due_before=2025-01-01&sort=-due_date,priority, into a filter.
+func parseTaskFilter(c *gin.Context, workflow models.Workflow) (models.TaskFilter, error) {
+	return parseTaskQuery(c.Request.URL.Query(), workflow)
+}
+
+// parseTaskQuery is parseTaskFilter for query parameters from anywhere.
+func parseTaskQuery(query url.Values, workflow models.Workflow) (models.TaskFilter, error) {
+	filter := models.TaskFilter{
+		ProjectID:    query.Get("projectId"),
+		DoneStatuses: workflow.DoneStatuses(),
+		Sort:         models.DefaultTaskSort,
+	}
+
+	if filter.ProjectID != "" && !isValidID(filter.ProjectID) {
+		return filter, fmt.Errorf("invalid projectId")
+	}
+
+	filter.LabelID = query.Get("labelId")
+	if filter.LabelID != "" && !isValidID(filter.LabelID) {
+		return filter, fmt.Errorf("invalid labelId")
+	}
+
+	filter.OwnerID = query.Get("ownerId")
+
+	switch parent := query.Get("parentId"); {
+	case parent == "none":
+		filter.TopLevel = true
+	case parent != "" && !isValidID(parent):
+		return filter, fmt.Errorf("invalid parentId")
+	default:
+		filter.ParentID = parent
+	}
+
+	if raw := query.Get("status"); raw != "" {
+		for _, s := range strings.Split(raw, ",") {
+			s = strings.TrimSpace(s)
+			switch {
+			case s == "open":
+				filter.OpenOnly = true
+			case workflow.IsValidStatus(s):
+				filter.Statuses = append(filter.Statuses, s)
+			default:
+				return filter, fmt.Errorf("invalid status %q", s)
+			}
+		}
+	}
+
+	if raw := query.Get("overdue"); raw != "" {
+		overdue, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid overdue")
+		}
+		filter.Overdue = overdue
+	}
+
+	var err error
+	if filter.DueBefore, err = parseDate(query.Get("due_before"), "due_before"); err != nil {
+		return filter, err
+	}
+	if filter.DueAfter, err = parseDate(query.Get("due_after"), "due_after"); err != nil {
+		return filter, err
+	}
+
+	// Overdue and due-date lists are what "today" is built from, so tasks
+	// snoozed for now are left out of them unless asked for.
+	filter.HideSnoozed = filter.Overdue || filter.DueBefore != nil || filter.DueAfter != nil
+	if raw := query.Get("include_snoozed"); raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid include_snoozed")
+		}
+		filter.HideSnoozed = filter.HideSnoozed && !include
+	}
+
+	if raw := query.Get("include_archived"); raw != "" {
+		include, err := strconv.ParseBool(raw)
+		if err != nil {
+			return filter, fmt.Errorf("invalid include_archived")
+		}
+		filter.IncludeArchived = include
+	}
+
+	if raw := query.Get("priority"); raw != "" {
+		p, err := strconv.Atoi(raw)
+		if err != nil || !workflow.IsValidPriority(p) {
+			return filter, fmt.Errorf("invalid priority")
+		}
+		filter.Priority = &p
+	}
+
+	if filter.Fields, err = parseFieldFilters(query); err != nil {
+		return filter, err
+	}
+
+	if raw := query.Get("sort"); raw != "" {
+		sorts, err := models.ParseSort(raw, models.TaskSortFields)
+		if err != nil {
+			return filter, err
+		}
+		if len(sorts) > 0 {
+			filter.Sort = sorts
+		}
+	}
+
+	return filter, nil
+}
+
+// parseFieldFilters reads the field.<key> parameters, each a value with an
+// optional comparison in front: field.points=>=3. A key can be given more
+// than once for a range. Values stay strings until typeFieldFilters has
+// the field definitions.
+func parseFieldFilters(query url.Values) ([]models.FieldFilter, error) {
+	var filters []models.FieldFilter
+	for name, values := range query {
+		key, ok := strings.CutPrefix(name, "field.")
+		if !ok {
+			continue
+		}
+		if !models.IsValidCustomFieldKey(key) {
+			return nil, fmt.Errorf("invalid custom field %q", key)
+		}
+		for _, raw := range values {
+			f := models.FieldFilter{Key: key, Op: "=", Value: raw}
+			for _, op := range models.FieldFilterOps {
+				if rest, ok := strings.CutPrefix(raw, op); ok {
+					f.Op, f.Value = op, rest
+					break
+				}
+			}
+			if f.Value == "" {
+				return nil, fmt.Errorf("missing value for field.%s", key)
+			}
+			filters = append(filters, f)
+		}
+	}
+	slices.SortStableFunc(filters, func(a, b models.FieldFilter) int { return strings.Compare(a.Key, b.Key) })
+	return filters, nil
+}
+
+// parseDateParam accepts either a date (2025-01-01, midnight UTC) or an RFC 3339 timestamp.
+func parseDateParam(c *gin.Context, name string) (*time.Time, error) {
+	return parseDate(c.Query(name), name)
+}
+
+func parseDate(raw, name string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.DateOnly, raw); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s", name)
+	}
+	return &t, nil
+}
+
+// taskCursor encodes the position of t in a listing sorted by sorts.
+func taskCursor(t *models.Task, sorts []models.SortField) string {
+	values := make([]string, 0, len(sorts)+1)
+	for _, s := range sorts {
+		values = append(values, models.TaskSortValue(t, s))
+	}
+	return api.EncodeCursor(taskCursorKind(sorts), append(values, t.ID)...)
+}
+
+// Cursors are bound to the sort they were issued for.
+func taskCursorKind(sorts []models.SortField) string {
+	return "tasks:" + models.SortString(sorts)
+}
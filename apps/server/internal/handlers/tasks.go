@@ -0,0 +1,737 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/recurrence"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDuplicates is how many likely duplicates check_duplicates reports.
+const maxDuplicates = 5
+
+// CreateTaskHandler creates a task. With ?check_duplicates=true it first
+// looks for open tasks in the same project with a similar title and, if
+// there are any, answers 409 with them instead; the client creates the task
+// anyway by sending it again without the flag.
+func CreateTaskHandler(tasks store.TaskStore, projects store.ProjectStore, workflows store.WorkflowStore, settings store.OrgSettingsStore, search store.SearchStore, fields CustomFieldStores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateTaskInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		createTask(c, tasks, projects, workflows, settings, search, fields, scope, input)
+	}
+}
+
+func CreateSubtaskHandler(tasks store.TaskStore, projects store.ProjectStore, workflows store.WorkflowStore, settings store.OrgSettingsStore, search store.SearchStore, fields CustomFieldStores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.CreateTaskInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		input.ParentID = &id
+		createTask(c, tasks, projects, workflows, settings, search, fields, scope, input)
+	}
+}
+
+func createTask(c *gin.Context, tasks store.TaskStore, projects store.ProjectStore, workflows store.WorkflowStore, settings store.OrgSettingsStore, search store.SearchStore, fields CustomFieldStores, scope models.Scope, input models.CreateTaskInput) {
+	ctx := c.Request.Context()
+
+	workflow, ok := loadWorkflow(c, workflows, scope)
+	if !ok {
+		return
+	}
+
+	loc, ok := requestTimezone(c)
+	if !ok {
+		return
+	}
+	if !checkDueTimezone(c, &input.DueTimezone, input.DueDate != nil, loc) {
+		return
+	}
+
+	if input.Recurrence != nil {
+		rule, err := recurrence.Normalize(*input.Recurrence)
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid recurrence"))
+			return
+		}
+		input.Recurrence = &rule
+	}
+	if input.EstimateMinutes != nil && !models.ValidEstimate(*input.EstimateMinutes) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid estimate"))
+		return
+	}
+
+	if input.Status == "" {
+		input.Status = workflow.DefaultStatus()
+	}
+	if !workflow.IsValidStatus(input.Status) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid status"))
+		return
+	}
+	if !workflow.IsValidPriority(input.Priority) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid priority"))
+		return
+	}
+
+	if input.Visibility != "" && !models.ValidTaskVisibility(input.Visibility) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid visibility"))
+		return
+	}
+	if input.Visibility == "" && scope.IsOrg() {
+		orgSettings, err := settings.Get(ctx, scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get org settings", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create task", err))
+			return
+		}
+		input.Visibility = orgSettings.DefaultTaskVisibility
+	}
+
+	if input.ParentID != nil {
+		if !isValidID(*input.ParentID) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Parent task not found"))
+			return
+		}
+
+		parent, err := tasks.Get(ctx, scope, *input.ParentID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Parent task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get parent task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create task", err))
+			return
+		}
+
+		// Subtasks live in their parent's project unless told otherwise.
+		if input.ProjectID == nil {
+			input.ProjectID = parent.ProjectID
+		}
+	}
+
+	if input.ProjectID != nil && !checkTaskProject(c, projects, scope, *input.ProjectID) {
+		return
+	}
+	if !checkCustomFields(c, fields.Fields, fields.Users, scope, input.CustomFields, false) {
+		return
+	}
+
+	if c.Query("check_duplicates") == "true" {
+		duplicates, err := search.SimilarTasks(ctx, scope, input.ProjectID, input.Title, workflow.DoneStatuses(), maxDuplicates)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to check for duplicate tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create task", err))
+			return
+		}
+		if len(duplicates) > 0 {
+			apierror.Respond(c, (&apierror.Error{Status: http.StatusConflict, Code: "LIKELY_DUPLICATE", Message: "Likely duplicate of an open task"}).WithDetails(gin.H{"duplicates": duplicates}))
+			return
+		}
+	}
+
+	task, err := tasks.Create(ctx, scope, input)
+	if quotaExceeded(c, err) {
+		return
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create task", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to create task", err))
+		return
+	}
+	setDueToday(task, loc, time.Now())
+
+	setTaskETag(c, task)
+	c.JSON(http.StatusCreated, task)
+}
+
+// createMessageTask creates a task sent from a chat integration, which
+// only has a title and maybe a description, filling in the rest the way the
+// API does.
+func createMessageTask(ctx context.Context, tasks store.TaskStore, workflows store.WorkflowStore, settings store.OrgSettingsStore, scope models.Scope, input models.CreateTaskInput) (*models.Task, error) {
+	workflow := models.DefaultWorkflow()
+	if scope.IsOrg() {
+		var err error
+		if workflow, err = workflows.Get(ctx, scope.OrgID); err != nil {
+			return nil, err
+		}
+		orgSettings, err := settings.Get(ctx, scope.OrgID)
+		if err != nil {
+			return nil, err
+		}
+		input.Visibility = orgSettings.DefaultTaskVisibility
+	}
+
+	input.Status = workflow.DefaultStatus()
+	// Orgs can drop levels from the default scale.
+	if !workflow.IsValidPriority(input.Priority) {
+		input.Priority = workflow.Priorities[0].Level
+	}
+	return tasks.Create(ctx, scope, input)
+}
+
+func ListTasksHandler(tasks store.TaskStore, labels store.LabelStore, workflows store.WorkflowStore, fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+
+		filter, err := parseTaskFilter(c, workflow)
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid filter: "+err.Error()))
+			return
+		}
+		if len(filter.Fields) > 0 {
+			defs, ok := loadCustomFields(c, fields, scope)
+			if !ok {
+				return
+			}
+			if err := typeFieldFilters(&filter, defs, scope.UserID); err != nil {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid filter: "+err.Error()))
+				return
+			}
+		}
+
+		writeTaskPage(c, tasks, labels, scope, filter, loc)
+	}
+}
+
+// writeTaskPage responds with the page of tasks matching filter that the
+// request's limit and cursor ask for.
+func writeTaskPage(c *gin.Context, tasks store.TaskStore, labels store.LabelStore, scope models.Scope, filter models.TaskFilter, loc *time.Location) {
+	html, ok := renderHTML(c)
+	if !ok {
+		return
+	}
+	page, err := api.ParsePage(c, taskCursorKind(filter.Sort))
+	if err != nil {
+		api.PageError(c, err)
+		return
+	}
+
+	list, err := tasks.List(c.Request.Context(), scope, filter, page)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list tasks", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to list tasks", err))
+		return
+	}
+
+	list, hasMore := api.Trim(list, page.Limit)
+	pageInfo := api.PageInfo{HasMore: hasMore}
+	if hasMore {
+		pageInfo.NextCursor = taskCursor(&list[len(list)-1], filter.Sort)
+	}
+
+	if err := loadTaskLabels(c.Request.Context(), labels, scope, list); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to load task labels", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to list tasks", err))
+		return
+	}
+
+	now := time.Now()
+	for i := range list {
+		setDueToday(&list[i], loc, now)
+		if html {
+			setDescriptionHTML(&list[i])
+		}
+	}
+
+	writeListJSON(c, gin.H{"tasks": list, "pageInfo": pageInfo})
+}
+
+func GetTaskHandler(tasks store.TaskStore, labels store.LabelStore, workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		html, ok := renderHTML(c)
+		if !ok {
+			return
+		}
+
+		task, err := tasks.Get(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+
+		progress, err := tasks.Progress(c.Request.Context(), scope, id, workflow.DoneStatuses())
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get task progress", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+		task.Progress = &progress
+
+		if task.SubtaskEstimateTotal, err = tasks.SubtaskEstimate(c.Request.Context(), scope, id); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get subtask estimates", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+
+		deps, err := tasks.Dependencies(c.Request.Context(), scope, id)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get task dependencies", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+		task.TaskDependencies = &deps
+
+		withLabels := []models.Task{*task}
+		if err := loadTaskLabels(c.Request.Context(), labels, scope, withLabels); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to load task labels", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+		task = &withLabels[0]
+		setDueToday(task, loc, time.Now())
+		if html {
+			setDescriptionHTML(task)
+		}
+
+		setTaskETag(c, task)
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+func UpdateTaskHandler(tasks store.TaskStore, projects store.ProjectStore, workflows store.WorkflowStore, fields CustomFieldStores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		ifVersion, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		var input models.UpdateTaskInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		input.IfVersion = ifVersion
+
+		if input.Title != nil && *input.Title == "" {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Title cannot be empty"))
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+		if input.Status != nil && !workflow.IsValidStatus(*input.Status) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid status"))
+			return
+		}
+		if input.Priority != nil && !workflow.IsValidPriority(*input.Priority) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid priority"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		if !input.DueTimezone.Null {
+			var tz *string
+			if input.DueTimezone.Set {
+				tz = &input.DueTimezone.Value
+			}
+			if !checkDueTimezone(c, &tz, input.DueDate.Set && !input.DueDate.Null, loc) {
+				return
+			}
+			if tz != nil {
+				input.DueTimezone = models.Nullable[string]{Set: true, Value: *tz}
+			}
+		}
+
+		if input.Recurrence.Set && !input.Recurrence.Null {
+			rule, err := recurrence.Normalize(input.Recurrence.Value)
+			if err != nil {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid recurrence"))
+				return
+			}
+			input.Recurrence.Value = rule
+		}
+		if input.EstimateMinutes.Set && !input.EstimateMinutes.Null && !models.ValidEstimate(input.EstimateMinutes.Value) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid estimate"))
+			return
+		}
+
+		if input.ProjectID.Set && !input.ProjectID.Null && !checkTaskProject(c, projects, scope, input.ProjectID.Value) {
+			return
+		}
+		if input.Visibility != nil && !models.ValidTaskVisibility(*input.Visibility) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid visibility"))
+			return
+		}
+		if !checkCustomFields(c, fields.Fields, fields.Users, scope, input.CustomFields, true) {
+			return
+		}
+
+		// Completing a recurring task spawns its next occurrence, so we need
+		// to know whether this update is what completes it. Only the owner
+		// may change who can see a task.
+		wasDone := false
+		if (input.Status != nil && workflow.IsDone(*input.Status)) || input.Visibility != nil {
+			before, err := tasks.Get(c.Request.Context(), scope, id)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Task not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get task", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to update task", err))
+				return
+			}
+			if input.Visibility != nil && before.OwnerID != scope.UserID {
+				apierror.Respond(c, apierror.Forbidden("Only the owner can change the task's visibility"))
+				return
+			}
+			wasDone = workflow.IsDone(before.Status)
+		}
+
+		task, err := tasks.Update(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Task is read-only"))
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			apierror.Respond(c, apierror.New(http.StatusPreconditionFailed, "Task has been modified"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update task", err))
+			return
+		}
+
+		if input.Status != nil && workflow.IsDone(task.Status) && !wasDone {
+			next, err := MaterializeNextOccurrence(c.Request.Context(), tasks, scope, workflow, task)
+			if quotaExceeded(c, err) {
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to create next occurrence", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create next occurrence", err))
+				return
+			}
+			if next != nil {
+				setDueToday(next, loc, time.Now())
+				task.NextOccurrence = next
+			}
+		}
+
+		setDueToday(task, loc, time.Now())
+
+		setTaskETag(c, task)
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+func DeleteTaskHandler(tasks store.TaskStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		ifVersion, ok := ifMatchVersion(c)
+		if !ok {
+			return
+		}
+
+		err := tasks.Delete(c.Request.Context(), scope, id, ifVersion)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Task is read-only"))
+			return
+		}
+		if errors.Is(err, store.ErrVersionMismatch) {
+			apierror.Respond(c, apierror.New(http.StatusPreconditionFailed, "Task has been modified"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete task", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func ArchiveTaskHandler(tasks store.TaskStore, archived bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		task, err := tasks.SetArchived(c.Request.Context(), scope, id, archived)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Task is read-only"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to archive task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to archive task", err))
+			return
+		}
+
+		setTaskETag(c, task)
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+// ReorderTaskHandler moves a task in the manual order (sort=position) to
+// between the tasks the client shows around it. Only the moved task's
+// position changes, so concurrent moves of other tasks don't clash with it.
+func ReorderTaskHandler(tasks store.TaskStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.ReorderTaskInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.After == nil && input.Before == nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "after or before is required"))
+			return
+		}
+		for _, neighbour := range []*string{input.After, input.Before} {
+			if neighbour == nil {
+				continue
+			}
+			if *neighbour == id {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "A task can't be placed next to itself"))
+				return
+			}
+			if !isValidID(*neighbour) {
+				apierror.Respond(c, apierror.NotFound("Task not found"))
+				return
+			}
+		}
+
+		task, err := tasks.Reorder(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Task is read-only"))
+			return
+		}
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("after and before are no longer in that order"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to reorder task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to reorder task", err))
+			return
+		}
+
+		setTaskETag(c, task)
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+func GetTaskTreeHandler(tasks store.TaskStore, workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+
+		task, err := tasks.Get(ctx, scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+
+		subtree, err := tasks.Subtree(ctx, scope, id)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get subtasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get task", err))
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		now := time.Now()
+		setDueToday(task, loc, now)
+		for i := range subtree {
+			setDueToday(&subtree[i], loc, now)
+		}
+
+		c.JSON(http.StatusOK, buildTaskTree(*task, subtree, workflow))
+	}
+}
+
+// buildTaskTree nests subtree (parents listed before children) under root and
+// fills in each node's progress from its own descendants.
+func buildTaskTree(root models.Task, subtree []models.Task, workflow models.Workflow) *models.TaskNode {
+	rootNode := &models.TaskNode{Task: root, Subtasks: []*models.TaskNode{}}
+	nodes := map[string]*models.TaskNode{root.ID: rootNode}
+
+	for _, t := range subtree {
+		node := &models.TaskNode{Task: t, Subtasks: []*models.TaskNode{}}
+		nodes[t.ID] = node
+		if t.ParentID != nil {
+			if parent, ok := nodes[*t.ParentID]; ok {
+				parent.Subtasks = append(parent.Subtasks, node)
+			}
+		}
+	}
+
+	var rollup func(n *models.TaskNode) (int, int)
+	rollup = func(n *models.TaskNode) (int, int) {
+		total, done := 0, 0
+		for _, child := range n.Subtasks {
+			t, d := rollup(child)
+			total += t + 1
+			done += d
+			if workflow.IsDone(child.Status) {
+				done++
+			}
+		}
+		progress := models.NewTaskProgress(total, done)
+		n.Progress = &progress
+		return total, done
+	}
+	rollup(rootNode)
+
+	return rootNode
+}
@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"html"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/telegram"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// telegramLinkTTL is how long a link from settings can be opened.
+	telegramLinkTTL = 15 * time.Minute
+	// maxTelegramUpdate caps an update's body; the bot only reads text
+	// messages.
+	maxTelegramUpdate = 64 << 10
+)
+
+func hashTelegramCode(code string) []byte {
+	return hashFeedToken(code)
+}
+
+func GetTelegramLinkHandler(links store.TelegramStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		link, err := links.Get(c.Request.Context(), scope.UserID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Telegram is not linked"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get Telegram link", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get Telegram link", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, link)
+	}
+}
+
+// CreateTelegramLinkHandler returns a t.me link that opens the bot with a
+// one-time code; pressing Start sends the code back to the webhook, which
+// links that chat. Tasks sent to the bot go to the scope this was called in.
+func CreateTelegramLinkHandler(links store.TelegramStore, botUsername string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		// Telegram allows up to 64 of [A-Za-z0-9_-] in a start parameter.
+		b := make([]byte, 24)
+		if _, err := rand.Read(b); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to generate Telegram link code", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create Telegram link", err))
+			return
+		}
+		code := base64.RawURLEncoding.EncodeToString(b)
+		expiresAt := time.Now().Add(telegramLinkTTL).UTC()
+
+		if err := links.CreateLinkCode(c.Request.Context(), scope, hashTelegramCode(code), expiresAt); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to save Telegram link code", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create Telegram link", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, gin.H{
+			"url":       "https://t.me/" + url.PathEscape(botUsername) + "?start=" + code,
+			"expiresAt": expiresAt,
+		})
+	}
+}
+
+func DeleteTelegramLinkHandler(links store.TelegramStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		err := links.Delete(c.Request.Context(), scope.UserID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Telegram is not linked"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete Telegram link", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to unlink Telegram", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// TelegramStores is what the bot reads and writes.
+type TelegramStores struct {
+	Links       store.TelegramStore
+	Users       store.UserStore
+	Tasks       store.TaskStore
+	Workflows   store.WorkflowStore
+	OrgSettings store.OrgSettingsStore
+}
+
+const telegramHelp = "Send me a message and I'll add it to yata as a task: the first line is the title, the rest its description. " +
+	"Your task reminders arrive here too. Send /stop to unlink this chat."
+
+// TelegramWebhookHandler receives the bot's updates, authenticated by the
+// secret token the webhook was registered with. It answers private text
+// messages by returning a sendMessage call, which Telegram makes for us;
+// anything else gets an empty 200 so Telegram doesn't retry it.
+func TelegramWebhookHandler(secret string, stores TelegramStores, appURL string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !telegram.VerifySecret(secret, c.GetHeader("X-Telegram-Bot-Api-Secret-Token")) {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxTelegramUpdate+1))
+		if err != nil || len(body) > maxTelegramUpdate {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+		var update telegram.Update
+		if err := json.Unmarshal(body, &update); err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+		msg := update.Message
+		if msg == nil || msg.Chat.Type != "private" || strings.TrimSpace(msg.Text) == "" {
+			c.Status(http.StatusOK)
+			return
+		}
+
+		ctx := c.Request.Context()
+		reply := func(text string) {
+			c.JSON(http.StatusOK, gin.H{
+				"method":                   "sendMessage",
+				"chat_id":                  msg.Chat.ID,
+				"text":                     text,
+				"parse_mode":               "HTML",
+				"disable_web_page_preview": true,
+			})
+		}
+		const failed = "Something went wrong; try again in a moment."
+
+		text := strings.TrimSpace(msg.Text)
+		command, arg, _ := strings.Cut(text, " ")
+		switch command {
+		case "/start":
+			if arg == "" {
+				reply("Open yata's settings and link Telegram from there. " + telegramHelp)
+				return
+			}
+			var username *string
+			if msg.From != nil && msg.From.Username != "" {
+				username = &msg.From.Username
+			}
+			_, err := stores.Links.Link(ctx, hashTelegramCode(strings.TrimSpace(arg)), msg.Chat.ID, username)
+			if errors.Is(err, store.ErrNotFound) {
+				reply("That link has expired or was already used. Get a new one from yata's settings.")
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to link Telegram chat", "error", err)
+				reply(failed)
+				return
+			}
+			reply("Linked to your yata account. " + telegramHelp)
+			return
+		case "/stop":
+			err := stores.Links.DeleteByChat(ctx, msg.Chat.ID)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				slog.ErrorContext(ctx, "Failed to unlink Telegram chat", "error", err)
+				reply(failed)
+				return
+			}
+			reply("This chat is no longer linked to yata.")
+			return
+		case "/help":
+			reply(telegramHelp)
+			return
+		}
+		if strings.HasPrefix(command, "/") {
+			reply(telegramHelp)
+			return
+		}
+
+		link, err := stores.Links.GetByChat(ctx, msg.Chat.ID)
+		if errors.Is(err, store.ErrNotFound) {
+			reply("This chat isn't linked yet. Open yata's settings and link Telegram from there.")
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get Telegram link", "error", err)
+			reply(failed)
+			return
+		}
+
+		scope := link.Scope()
+		if scope.IsOrg() {
+			member, err := stores.Users.GetMember(ctx, scope.OrgID, scope.UserID)
+			if errors.Is(err, store.ErrNotFound) {
+				reply("You're no longer in the organization this chat adds tasks to. Link Telegram again from yata's settings.")
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get member", "error", err)
+				reply(failed)
+				return
+			}
+			scope.Guest = member.Role == middlewares.OrgGuestRole
+		}
+
+		title, description, _ := strings.Cut(text, "\n")
+		task, err := createMessageTask(ctx, stores.Tasks, stores.Workflows, stores.OrgSettings, scope, models.CreateTaskInput{
+			Title:       strings.TrimSpace(title),
+			Description: strings.TrimSpace(description),
+		})
+		if isQuotaExceeded(err) {
+			reply(quotaReply)
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create task from Telegram", "error", err)
+			reply(failed)
+			return
+		}
+		added := "<b>" + html.EscapeString(task.Title) + "</b>"
+		if appURL != "" {
+			added = `<a href="` + html.EscapeString(strings.TrimRight(appURL, "/")) + "/tasks/" + task.ID + `">` + added + "</a>"
+		}
+		reply("Added " + added)
+	}
+}
@@ -0,0 +1,436 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkTemplate writes the error response and returns false if the template
+// would make too many tasks or nest them too deeply.
+func checkTemplate(c *gin.Context, task models.TemplateTask) bool {
+	n, depth := task.Count()
+	if n > models.MaxTemplateTasks {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Templates can have at most "+strconv.Itoa(models.MaxTemplateTasks)+" tasks"))
+		return false
+	}
+	if depth > models.MaxTemplateDepth {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Template subtasks can nest at most "+strconv.Itoa(models.MaxTemplateDepth)+" deep"))
+		return false
+	}
+	return true
+}
+
+// dayNumber counts the days from the epoch to t's date in zone.
+func dayNumber(t time.Time, zone *time.Location) int {
+	y, m, d := t.In(zone).Date()
+	return int(time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// templateOffset is due as a template keeps it: days from start, a
+// dayNumber, and the time of day unless it's midnight.
+func templateOffset(due *time.Time, start int, zone *time.Location) (*int, string) {
+	if due == nil {
+		return nil, ""
+	}
+	days := dayNumber(*due, zone) - start
+	clock := ""
+	if t := due.In(zone); t.Hour() != 0 || t.Minute() != 0 {
+		clock = t.Format("15:04")
+	}
+	return &days, clock
+}
+
+// templateDue turns a template's due offset back into a date, counting from
+// start's date in its location.
+func templateDue(days *int, clock string, start time.Time) *time.Time {
+	if days == nil {
+		return nil
+	}
+	at, _ := time.Parse("15:04", clock)
+	due := time.Date(start.Year(), start.Month(), start.Day()+*days, at.Hour(), at.Minute(), 0, 0, start.Location())
+	return &due
+}
+
+// templateStart is the day an instantiated template's due dates count from:
+// date if given, or else today, in loc or UTC.
+func templateStart(date *string, loc *time.Location) time.Time {
+	zone := loc
+	if zone == nil {
+		zone = time.UTC
+	}
+	if date == nil {
+		return time.Now().In(zone)
+	}
+	start, _ := time.ParseInLocation("2006-01-02", *date, zone)
+	return start
+}
+
+// templateFromTask turns root and its subtree, labels loaded, into a
+// template. Due dates become days from the day root was created, in zone.
+func templateFromTask(root models.Task, subtree []models.Task, zone *time.Location) models.TemplateTask {
+	children := map[string][]models.Task{}
+	for _, t := range subtree {
+		if t.ParentID != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+	start := dayNumber(root.CreatedAt, zone)
+
+	var convert func(t models.Task) models.TemplateTask
+	convert = func(t models.Task) models.TemplateTask {
+		tt := models.TemplateTask{
+			Title:       t.Title,
+			Description: t.Description,
+			Status:      t.Status,
+			Priority:    t.Priority,
+		}
+		tt.DueInDays, tt.DueTime = templateOffset(t.DueDate, start, zone)
+		for _, l := range t.Labels {
+			tt.LabelIDs = append(tt.LabelIDs, l.ID)
+		}
+		for _, child := range children[t.ID] {
+			tt.Subtasks = append(tt.Subtasks, convert(child))
+		}
+		return tt
+	}
+	return convert(root)
+}
+
+// templateBatch lays the template out as a batch, parents first. Due dates
+// count from start's date, in its location, and get loc, the request's
+// timezone, if it sent one. A status or priority the workflow no longer has
+// falls back to its default, since the template may be older than the
+// workflow.
+func templateBatch(tmpl models.TemplateTask, start time.Time, loc *time.Location, workflow models.Workflow, base models.CreateTaskInput) []models.NewTask {
+	var dueTimezone *string
+	if loc != nil {
+		name := loc.String()
+		dueTimezone = &name
+	}
+
+	batch := []models.NewTask{}
+	var add func(t models.TemplateTask, parent int)
+	add = func(t models.TemplateTask, parent int) {
+		input := base
+		input.Title = t.Title
+		input.Description = t.Description
+		input.Status = t.Status
+		if !workflow.IsValidStatus(input.Status) {
+			input.Status = workflow.DefaultStatus()
+		}
+		if workflow.IsValidPriority(t.Priority) {
+			input.Priority = t.Priority
+		}
+		if input.DueDate = templateDue(t.DueInDays, t.DueTime, start); input.DueDate != nil {
+			input.DueTimezone = dueTimezone
+		}
+		batch = append(batch, models.NewTask{Input: input, Parent: parent, LabelIDs: t.LabelIDs})
+
+		index := len(batch) - 1
+		for _, item := range t.Checklist {
+			check := base
+			check.Title, check.Status = item, workflow.DefaultStatus()
+			batch = append(batch, models.NewTask{Input: check, Parent: index})
+		}
+		for _, sub := range t.Subtasks {
+			add(sub, index)
+		}
+	}
+	add(tmpl, -1)
+	return batch
+}
+
+// CreateTemplateHandler saves a template, either from the tree under an
+// existing task or from one given in full.
+func CreateTemplateHandler(templates store.TemplateStore, tasks store.TaskStore, labels store.LabelStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateTemplateInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if (input.TaskID == nil) == (input.Task == nil) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Either taskId or task is required"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+
+		ctx := c.Request.Context()
+		if input.TaskID != nil {
+			if !isValidID(*input.TaskID) {
+				apierror.Respond(c, apierror.NotFound("Task not found"))
+				return
+			}
+			root, err := tasks.Get(ctx, scope, *input.TaskID)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("Task not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get task", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create template", err))
+				return
+			}
+			subtree, err := tasks.Subtree(ctx, scope, root.ID)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get subtasks", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create template", err))
+				return
+			}
+			all := append([]models.Task{*root}, subtree...)
+			if err := loadTaskLabels(ctx, labels, scope, all); err != nil {
+				slog.ErrorContext(ctx, "Failed to load task labels", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to create template", err))
+				return
+			}
+
+			zone := loc
+			if zone == nil && root.DueTimezone != nil {
+				zone, _ = loadTimezone(*root.DueTimezone)
+			}
+			if zone == nil {
+				zone = time.UTC
+			}
+			task := templateFromTask(all[0], all[1:], zone)
+			input.Task = &task
+		}
+		if !checkTemplate(c, *input.Task) {
+			return
+		}
+
+		template, err := templates.Create(ctx, scope, input.Name, *input.Task)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create template", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, template)
+	}
+}
+
+func ListTemplatesHandler(templates store.TemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := templates.List(c.Request.Context(), scope)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list templates", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list templates", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"templates": list})
+	}
+}
+
+func GetTemplateHandler(templates store.TemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		template, err := templates.Get(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get template", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+// UpdateTemplateHandler renames a template or replaces its task; only its
+// owner may.
+func UpdateTemplateHandler(templates store.TemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		var input models.UpdateTemplateInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.Task != nil && !checkTemplate(c, *input.Task) {
+			return
+		}
+
+		template, err := templates.Update(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Only the template's owner can change it"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update template", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, template)
+	}
+}
+
+func DeleteTemplateHandler(templates store.TemplateStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		err := templates.Delete(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Only the template's owner can change it"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete template", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// InstantiateTemplateHandler creates the template's tasks in one go and
+// returns them as a tree. Labels deleted since the template was saved are
+// left off.
+func InstantiateTemplateHandler(templates store.TemplateStore, tasks store.TaskStore, projects store.ProjectStore, labels store.LabelStore, workflows store.WorkflowStore, settings store.OrgSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+
+		var input models.InstantiateTemplateInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		start := templateStart(input.StartDate, loc)
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+		if input.ProjectID != nil && !checkTaskProject(c, projects, scope, *input.ProjectID) {
+			return
+		}
+
+		ctx := c.Request.Context()
+		template, err := templates.Get(ctx, scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Template not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get template", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+			return
+		}
+
+		base := models.CreateTaskInput{ProjectID: input.ProjectID}
+		if scope.IsOrg() {
+			orgSettings, err := settings.Get(ctx, scope.OrgID)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to get org settings", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+				return
+			}
+			base.Visibility = orgSettings.DefaultTaskVisibility
+		}
+		batch := templateBatch(template.Task, start, loc, workflow, base)
+		if input.Title != nil {
+			batch[0].Input.Title = *input.Title
+		}
+		created, err := tasks.CreateBatch(ctx, scope, batch)
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+			return
+		}
+		if err := loadTaskLabels(ctx, labels, scope, created); err != nil {
+			slog.ErrorContext(ctx, "Failed to load task labels", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to instantiate template", err))
+			return
+		}
+		now := time.Now()
+		for i := range created {
+			setDueToday(&created[i], loc, now)
+		}
+
+		c.JSON(http.StatusCreated, buildTaskTree(created[0], created[1:], workflow))
+	}
+}
@@ -0,0 +1,562 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// clockSkew is how far past the server's now an entry may end, for
+	// clients whose clocks run a little fast.
+	clockSkew = time.Minute
+	// maxTimeExportRange bounds how much one export reads.
+	maxTimeExportRange = 366 * 24 * time.Hour
+)
+
+var timeExportColumns = []string{
+	"date", "started_at", "ended_at", "hours", "user_id", "user", "project", "task_id", "task", "note",
+}
+
+// checkTimeRange writes the error response and returns false if the entry
+// doesn't end after it starts, or ends in the future.
+func checkTimeRange(c *gin.Context, startedAt, endedAt time.Time) bool {
+	if !endedAt.After(startedAt) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "endedAt must be after startedAt"))
+		return false
+	}
+	if endedAt.After(time.Now().Add(clockSkew)) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "endedAt can't be in the future"))
+		return false
+	}
+	return true
+}
+
+func checkTimeEntryNote(c *gin.Context, note string) bool {
+	if utf8.RuneCountInString(note) > models.MaxTimeEntryNoteLength {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, fmt.Sprintf("note must be at most %d characters", models.MaxTimeEntryNoteLength)))
+		return false
+	}
+	return true
+}
+
+func StartTimerHandler(entries store.TimeEntryStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		// The body is optional.
+		var input models.StartTimerInput
+		if !bindOptionalJSON(c, &input) {
+			return
+		}
+		if !checkTimeEntryNote(c, input.Note) {
+			return
+		}
+
+		entry, err := entries.Start(c.Request.Context(), scope, taskID, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("Another timer was started at the same time"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to start timer", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to start timer", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, entry)
+	}
+}
+
+func StopTimerHandler(entries store.TimeEntryStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		entry, err := entries.Stop(c.Request.Context(), scope, taskID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("No timer is running on this task"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to stop timer", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to stop timer", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+// GetRunningTimerHandler returns the caller's running timer, whichever org
+// its task is in.
+func GetRunningTimerHandler(entries store.TimeEntryStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		entry, err := entries.Running(c.Request.Context(), scope.UserID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("No timer is running"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get running timer", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get running timer", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+func CreateTimeEntryHandler(entries store.TimeEntryStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		var input models.CreateTimeEntryInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if !checkTimeRange(c, input.StartedAt, input.EndedAt) || !checkTimeEntryNote(c, input.Note) {
+			return
+		}
+
+		entry, err := entries.Create(c.Request.Context(), scope, taskID, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create time entry", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create time entry", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, entry)
+	}
+}
+
+// ListTimeEntriesHandler pages through the task's entries, newest first,
+// along with the task's totals.
+func ListTimeEntriesHandler(entries store.TimeEntryStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID := c.Param("id")
+		if !isValidID(taskID) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "time-entries")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		totals, err := entries.TaskTotals(ctx, scope, taskID)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to total time entries", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list time entries", err))
+			return
+		}
+		list, err := entries.List(ctx, scope, taskID, page)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list time entries", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list time entries", err))
+			return
+		}
+
+		list, hasMore := api.Trim(list, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			last := list[len(list)-1]
+			pageInfo.NextCursor = api.EncodeCursor("time-entries", last.StartedAt.Format(time.RFC3339Nano), last.ID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"timeEntries": list, "totals": totals, "pageInfo": pageInfo})
+	}
+}
+
+// ownTimeEntry loads the entry and checks the caller logged it, writing the
+// error response and returning nil if not.
+func ownTimeEntry(c *gin.Context, entries store.TimeEntryStore, scope models.Scope, taskID, id string) *models.TimeEntry {
+	entry, err := entries.Get(c.Request.Context(), scope, taskID, id)
+	if errors.Is(err, store.ErrNotFound) {
+		apierror.Respond(c, apierror.NotFound("Time entry not found"))
+		return nil
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get time entry", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to get time entry", err))
+		return nil
+	}
+	if entry.UserID != scope.UserID {
+		apierror.Respond(c, apierror.Forbidden("Only the person who logged this time can change it"))
+		return nil
+	}
+	return entry
+}
+
+func UpdateTimeEntryHandler(entries store.TimeEntryStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("entryId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Time entry not found"))
+			return
+		}
+
+		var input models.UpdateTimeEntryInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.Note != nil && !checkTimeEntryNote(c, *input.Note) {
+			return
+		}
+
+		current := ownTimeEntry(c, entries, scope, taskID, id)
+		if current == nil {
+			return
+		}
+		startedAt := current.StartedAt
+		if input.StartedAt != nil {
+			startedAt = *input.StartedAt
+		}
+		switch {
+		case current.EndedAt == nil && input.EndedAt != nil:
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Stop the timer to end it"))
+			return
+		case current.EndedAt == nil:
+			if startedAt.After(time.Now().Add(clockSkew)) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "startedAt can't be in the future"))
+				return
+			}
+		default:
+			endedAt := *current.EndedAt
+			if input.EndedAt != nil {
+				endedAt = *input.EndedAt
+			}
+			if !checkTimeRange(c, startedAt, endedAt) {
+				return
+			}
+		}
+
+		entry, err := entries.Update(c.Request.Context(), scope, taskID, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Time entry not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update time entry", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update time entry", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, entry)
+	}
+}
+
+func DeleteTimeEntryHandler(entries store.TimeEntryStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		taskID, id := c.Param("id"), c.Param("entryId")
+		if !isValidID(taskID) || !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Time entry not found"))
+			return
+		}
+
+		if ownTimeEntry(c, entries, scope, taskID, id) == nil {
+			return
+		}
+
+		err := entries.Delete(c.Request.Context(), scope, taskID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Time entry not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete time entry", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete time entry", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ProjectTimeHandler totals the time logged on the project's tasks, by task
+// and by user.
+func ProjectTimeHandler(entries store.TimeEntryStore, projects store.ProjectStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		ctx := c.Request.Context()
+		if _, err := projects.Get(ctx, scope.OrgID, id); errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		} else if err != nil {
+			slog.ErrorContext(ctx, "Failed to get project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to total project time", err))
+			return
+		}
+
+		totals, err := entries.ProjectTotals(ctx, scope, id)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to total project time", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to total project time", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, totals)
+	}
+}
+
+// TimeExportStores is what an export of time entries reads.
+type TimeExportStores struct {
+	Entries  store.TimeEntryStore
+	Projects store.ProjectStore
+	Users    store.UserStore
+}
+
+// ExportTimeEntriesHandler streams the finished time entries in scope that
+// started between from and to as CSV, for invoicing. A date-only to takes
+// in the whole day. Dates in the file are in the X-Timezone zone, or UTC.
+func ExportTimeEntriesHandler(stores TimeExportStores) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+		if loc == nil {
+			loc = time.UTC
+		}
+		from, err := parseDateParam(c, "from")
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		to, err := parseDateParam(c, "to")
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, err.Error()))
+			return
+		}
+		if from == nil || to == nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "from and to are required"))
+			return
+		}
+		if len(c.Query("to")) == len(time.DateOnly) {
+			*to = to.AddDate(0, 0, 1)
+		}
+		if !to.After(*from) || to.Sub(*from) > maxTimeExportRange {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "to must be after from, and at most a year later"))
+			return
+		}
+
+		filter := models.TimeEntryFilter{From: *from, To: *to}
+		if id := c.Query("projectId"); id != "" {
+			if !isValidID(id) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid projectId"))
+				return
+			}
+			filter.ProjectID = &id
+		}
+		if id := c.Query("userId"); id != "" {
+			filter.UserID = &id
+		}
+
+		ctx := c.Request.Context()
+		export := timeExport{stores: stores, scope: scope, filter: filter, names: map[string]string{}}
+		if export.projects, err = projectNames(ctx, stores.Projects, scope); err != nil {
+			slog.ErrorContext(ctx, "Failed to list projects", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to export time entries", err))
+			return
+		}
+		page, err := export.next(ctx)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to export time entries", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to export time entries", err))
+			return
+		}
+
+		name := fmt.Sprintf("time-%s-%s.csv", from.In(loc).Format("20060102"), to.Add(-time.Nanosecond).In(loc).Format("20060102"))
+		c.Header("Content-Disposition", `attachment; filename="`+name+`"`)
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+
+		w := csv.NewWriter(c.Writer)
+		if err := w.Write(timeExportColumns); err != nil {
+			return
+		}
+		for len(page) > 0 {
+			for i := range page {
+				if err := w.Write(export.row(ctx, &page[i], loc)); err != nil {
+					return
+				}
+			}
+			if w.Flush(); w.Error() != nil {
+				return
+			}
+			c.Writer.Flush()
+
+			if page, err = export.next(ctx); err != nil {
+				slog.ErrorContext(ctx, "Failed to export time entries", "error", err)
+				return
+			}
+		}
+	}
+}
+
+// timeExport pages through the entries being exported.
+type timeExport struct {
+	stores   TimeExportStores
+	scope    models.Scope
+	filter   models.TimeEntryFilter
+	projects map[string]string
+	// names caches who logged the entries, by user id.
+	names map[string]string
+	after []string
+	done  bool
+}
+
+func (e *timeExport) next(ctx context.Context) ([]models.ExportedTimeEntry, error) {
+	if e.done {
+		return nil, nil
+	}
+	page := models.Page{Limit: api.MaxLimit, After: e.after}
+	list, err := e.stores.Entries.Export(ctx, e.scope, e.filter, page)
+	if err != nil {
+		return nil, err
+	}
+	list, hasMore := api.Trim(list, page.Limit)
+	if len(list) == 0 {
+		e.done = true
+		return nil, nil
+	}
+	last := list[len(list)-1]
+	e.after = []string{last.StartedAt.Format(time.RFC3339Nano), last.ID}
+	e.done = !hasMore
+	return list, nil
+}
+
+func (e *timeExport) row(ctx context.Context, t *models.ExportedTimeEntry, loc *time.Location) []string {
+	project := ""
+	if t.ProjectID != nil {
+		project = e.projects[*t.ProjectID]
+	}
+	return []string{
+		t.StartedAt.In(loc).Format(time.DateOnly),
+		t.StartedAt.In(loc).Format(time.RFC3339),
+		t.EndedAt.In(loc).Format(time.RFC3339),
+		strconv.FormatFloat(float64(t.Seconds)/3600, 'f', 2, 64),
+		t.UserID,
+		csvText(e.name(ctx, t.UserID)),
+		csvText(project),
+		t.TaskID,
+		csvText(t.TaskTitle),
+		csvText(t.Note),
+	}
+}
+
+// name is the user's full name, or their email; the export goes on without
+// one when the user can't be read.
+func (e *timeExport) name(ctx context.Context, userID string) string {
+	if name, ok := e.names[userID]; ok {
+		return name
+	}
+	name := ""
+	if u, err := e.stores.Users.GetUser(ctx, userID); err == nil {
+		name = strings.TrimSpace(deref(u.FirstName) + " " + deref(u.LastName))
+		if name == "" {
+			name = deref(u.Email)
+		}
+	}
+	e.names[userID] = name
+	return name
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TimezoneHeader carries the requester's IANA zone, e.g. "America/New_York".
+// It decides what "today" means for dueToday and is the default zone for
+// due dates set in the request.
+const TimezoneHeader = "X-Timezone"
+
+func loadTimezone(name string) (*time.Location, bool) {
+	// LoadLocation maps "" to UTC and "Local" to the server's zone; neither
+	// is something a client should be sending.
+	if name == "" || name == "Local" {
+		return nil, false
+	}
+	loc, err := time.LoadLocation(name)
+	return loc, err == nil
+}
+
+// requestTimezone returns the zone from TimezoneHeader, or nil when the
+// header is absent. On an invalid zone it has already written the response.
+func requestTimezone(c *gin.Context) (*time.Location, bool) {
+	name := c.GetHeader(TimezoneHeader)
+	if name == "" {
+		return nil, true
+	}
+
+	loc, ok := loadTimezone(name)
+	if !ok {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid timezone"))
+		return nil, false
+	}
+	return loc, true
+}
+
+// setDueToday computes t.DueToday. "Today" is taken in loc when the requester
+// sent a zone, otherwise in the zone the due date was set in, falling back to
+// UTC. Snoozed tasks aren't due today until the snooze ends.
+func setDueToday(t *models.Task, loc *time.Location, now time.Time) {
+	if t.DueDate == nil || (t.SnoozedUntil != nil && t.SnoozedUntil.After(now)) {
+		t.DueToday = false
+		return
+	}
+
+	zone := loc
+	if zone == nil && t.DueTimezone != nil {
+		zone, _ = loadTimezone(*t.DueTimezone)
+	}
+	if zone == nil {
+		zone = time.UTC
+	}
+
+	dy, dm, dd := t.DueDate.In(zone).Date()
+	ny, nm, nd := now.In(zone).Date()
+	t.DueToday = dy == ny && dm == nm && dd == nd
+}
+
+// checkDueTimezone validates an explicit due timezone, or defaults it to the
+// requester's zone when only a due date was sent. It returns false if it
+// wrote an error response.
+func checkDueTimezone(c *gin.Context, tz **string, dueDateSet bool, loc *time.Location) bool {
+	if *tz != nil {
+		if _, ok := loadTimezone(**tz); !ok {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid dueTimezone"))
+			return false
+		}
+		return true
+	}
+	if dueDateSet && loc != nil {
+		name := loc.String()
+		*tz = &name
+	}
+	return true
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func ListTrashHandler(trash store.TrashStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		tasks, err := trash.ListTasks(c.Request.Context(), scope)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list trashed tasks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list trash", err))
+			return
+		}
+
+		// Projects only exist in orgs.
+		projects := []models.Project{}
+		if scope.IsOrg() {
+			projects, err = trash.ListProjects(c.Request.Context(), scope.OrgID)
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to list trashed projects", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to list trash", err))
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, gin.H{"tasks": tasks, "projects": projects})
+	}
+}
+
+func RestoreTaskHandler(trash store.TrashStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+
+		task, err := trash.RestoreTask(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Task not found"))
+			return
+		}
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("Restore the parent task or project first"))
+			return
+		}
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to restore task", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to restore task", err))
+			return
+		}
+
+		setTaskETag(c, task)
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+func DeleteProjectHandler(trash store.TrashStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		err := trash.DeleteProject(c.Request.Context(), scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete project", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func RestoreProjectHandler(trash store.TrashStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+
+		project, err := trash.RestoreProject(c.Request.Context(), scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Project not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to restore project", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to restore project", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, project)
+	}
+}
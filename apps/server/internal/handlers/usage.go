@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// quotaExceeded answers 402 and reports true when err is a write the
+// store turned down for taking the org past its plan's limit; see
+// quota.WithQuotas.
+func quotaExceeded(c *gin.Context, err error) bool {
+	var exceeded *quota.ExceededError
+	if !errors.As(err, &exceeded) {
+		return false
+	}
+	apierror.Respond(c, (&apierror.Error{Status: http.StatusPaymentRequired, Code: "QUOTA_EXCEEDED", Message: "Your organization's plan limit has been reached"}).WithDetails(gin.H{"resource": exceeded.Resource, "limit": exceeded.Limit, "used": exceeded.Used}))
+	return true
+}
+
+// quotaReply is what chat integrations answer with when quotaExceeded
+// would have.
+const quotaReply = "Your organization has reached its plan's limit on tasks."
+
+func isQuotaExceeded(err error) bool {
+	var exceeded *quota.ExceededError
+	return errors.As(err, &exceeded)
+}
+
+// checkQuota answers like quotaExceeded ahead of a write, for handlers that
+// would otherwise leave things behind before the store turns it down, and
+// 500, as failure, when usage can't be read.
+func checkQuota(c *gin.Context, quotas *quota.Enforcer, scope models.Scope, resource string, n int64, failure string) bool {
+	err := quotas.Check(c.Request.Context(), scope, resource, n)
+	if quotaExceeded(c, err) {
+		return false
+	}
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to check quota", "resource", resource, "error", err)
+		apierror.Respond(c, apierror.Internal(failure, err))
+		return false
+	}
+	return true
+}
+
+func GetOrgUsageHandler(quotas *quota.Enforcer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		usage, err := quotas.Usage(c.Request.Context(), scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get org usage", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get usage", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, usage)
+	}
+}
+
+// GetOrgLimitsHandler reports the rate limit the request was counted under,
+// so it must be behind the limiter, along with the org's usage.
+func GetOrgLimitsHandler(quotas *quota.Enforcer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		usage, err := quotas.Usage(c.Request.Context(), scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get org usage", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get limits", err))
+			return
+		}
+
+		limits := models.OrgLimits{
+			Storage: usage.AttachmentBytes,
+			Caps:    models.OrgCaps{Tasks: usage.Tasks, WebhookEndpoints: usage.WebhookEndpoints},
+		}
+		if v, ok := c.Get(middlewares.RateLimitKey); ok {
+			result := v.(ratelimit.Result)
+			limits.RateLimit = &models.RateLimitStatus{Limit: result.Limit, Remaining: result.Remaining, ResetAt: result.ResetAt.UTC()}
+		}
+		c.JSON(http.StatusOK, limits)
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/i18n"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func GetUserSettingsHandler(settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		s, err := settings.Get(c.Request.Context(), scope.UserID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get user settings", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get settings", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, s)
+	}
+}
+
+// UpdateUserSettingsHandler changes only the fields sent. A default project
+// has to belong to the org the request is made in.
+func UpdateUserSettingsHandler(settings store.UserSettingsStore, projects store.ProjectStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.UpdateUserSettingsInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		if tz := input.Timezone; tz.Set && !tz.Null {
+			if _, ok := loadTimezone(tz.Value); !ok {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid timezone"))
+				return
+			}
+		}
+		if l := input.Locale; l.Set && !l.Null && !i18n.IsSupported(l.Value) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid locale"))
+			return
+		}
+		if input.WeekStart != nil && !models.ValidWeekStart(*input.WeekStart) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid weekStart"))
+			return
+		}
+		if input.DigestFrequency != nil && !models.ValidDigestFrequency(*input.DigestFrequency) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid digestFrequency"))
+			return
+		}
+		if input.DigestTime != nil && !models.ValidDigestTime(*input.DigestTime) {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid digestTime"))
+			return
+		}
+		if p := input.DefaultProjectID; p.Set && !p.Null {
+			if !scope.IsOrg() || !isValidID(p.Value) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid defaultProjectId"))
+				return
+			}
+			_, err := projects.Get(c.Request.Context(), scope.OrgID, p.Value)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid defaultProjectId"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get default project", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to update settings", err))
+				return
+			}
+		}
+
+		s, err := settings.Update(c.Request.Context(), scope.UserID, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update user settings", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update settings", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, s)
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetUserHandler returns a profile from the local mirror of Clerk's users.
+// Callers can see themselves and, in an org, the org's members; anyone else
+// is reported as not found.
+func GetUserHandler(users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if id == scope.UserID {
+			user, err := users.GetUser(c.Request.Context(), id)
+			if errors.Is(err, store.ErrNotFound) {
+				apierror.Respond(c, apierror.NotFound("User not found"))
+				return
+			}
+			if err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to get user", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to get user", err))
+				return
+			}
+			c.JSON(http.StatusOK, user)
+			return
+		}
+
+		if !scope.IsOrg() {
+			apierror.Respond(c, apierror.NotFound("User not found"))
+			return
+		}
+		member, err := users.GetMember(c.Request.Context(), scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("User not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get org member", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get user", err))
+			return
+		}
+		c.JSON(http.StatusOK, member)
+	}
+}
+
+func ListOrgMembersHandler(users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "members")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		members, err := users.ListMembers(c.Request.Context(), scope.OrgID, page)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list org members", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list members", err))
+			return
+		}
+
+		members, hasMore := api.Trim(members, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			pageInfo.NextCursor = api.EncodeCursor("members", members[len(members)-1].ID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"members": members, "pageInfo": pageInfo})
+	}
+}
@@ -0,0 +1,422 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// parseViewQuery turns a view's query into task list parameters, so a view
+// filters the way GET /tasks does. Terms are separated by spaces, and "&"
+// or "and" may go between them:
+//
+//	is:open  is:overdue  is:toplevel
+//	status:todo,in_progress
+//	assignee:me  assignee:<user id>
+//	#urgent  label:urgent  label:"needs review"
+//	project:<id>  priority:<level>  due_before:<date>  due_after:<date>
+//	field.<key>:<value>  field.<key>:>=<value>  field.owner:me
+//
+// "me" is whoever is reading the view, so a shared view works for everyone.
+// Labels are returned by name, for the caller to look up.
+func parseViewQuery(raw, userID string) (url.Values, string, error) {
+	query := url.Values{}
+	label := ""
+	setOnce := func(key, name, value string) error {
+		if query.Has(key) {
+			return fmt.Errorf("%s given twice", name)
+		}
+		query.Set(key, value)
+		return nil
+	}
+
+	terms, err := viewTerms(raw)
+	if err != nil {
+		return nil, "", err
+	}
+	for _, term := range terms {
+		if strings.EqualFold(term, "and") || term == "&" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(term, "#"); ok {
+			term = "label:" + name
+		}
+		key, value, ok := strings.Cut(term, ":")
+		if !ok || value == "" {
+			return nil, "", fmt.Errorf("unknown term %q", term)
+		}
+
+		switch strings.ToLower(key) {
+		case "is":
+			switch strings.ToLower(value) {
+			case "open":
+				query.Add("status", "open")
+			case "overdue":
+				err = setOnce("overdue", "is:overdue", "true")
+			case "toplevel":
+				err = setOnce("parentId", "is:toplevel", "none")
+			default:
+				err = fmt.Errorf("unknown term %q", term)
+			}
+		case "status":
+			query.Add("status", value)
+		case "assignee":
+			if strings.EqualFold(value, "me") {
+				value = userID
+			}
+			err = setOnce("ownerId", "assignee", value)
+		case "label":
+			if label != "" {
+				err = errors.New("only one label can be given")
+			}
+			label = value
+		case "project":
+			err = setOnce("projectId", "project", value)
+		case "priority":
+			err = setOnce("priority", "priority", value)
+		case "due_before", "due_after":
+			err = setOnce(strings.ToLower(key), key, value)
+		default:
+			if strings.HasPrefix(strings.ToLower(key), "field.") {
+				query.Add(strings.ToLower(key), value)
+			} else {
+				err = fmt.Errorf("unknown term %q", term)
+			}
+		}
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	// Several status terms widen the match, as a list would.
+	if statuses := query["status"]; len(statuses) > 1 {
+		query.Set("status", strings.Join(statuses, ","))
+	}
+	return query, label, nil
+}
+
+// viewTerms splits a view query on spaces, keeping double-quoted runs
+// together without their quotes.
+func viewTerms(raw string) ([]string, error) {
+	terms := []string{}
+	var term strings.Builder
+	quoted := false
+	for _, r := range raw {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if term.Len() > 0 {
+				terms = append(terms, term.String())
+				term.Reset()
+			}
+		default:
+			term.WriteRune(r)
+		}
+	}
+	if quoted {
+		return nil, errors.New("unterminated quote")
+	}
+	if term.Len() > 0 {
+		terms = append(terms, term.String())
+	}
+	return terms, nil
+}
+
+// viewFilter is the task filter for a view's query and sort, as read by
+// scope. Problems with the view itself come back as a 400 and a false ok
+// is returned; an error is the caller's to report.
+func viewFilter(c *gin.Context, labels store.LabelStore, fields store.CustomFieldStore, workflow models.Workflow, scope models.Scope, rawQuery, sort string) (models.TaskFilter, bool, error) {
+	bad := func(err error) (models.TaskFilter, bool, error) {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid view query: "+err.Error()))
+		return models.TaskFilter{}, false, nil
+	}
+
+	query, label, err := parseViewQuery(rawQuery, scope.UserID)
+	if err != nil {
+		return bad(err)
+	}
+	if label != "" {
+		if !scope.IsOrg() {
+			return bad(errors.New("labels need an active organization"))
+		}
+		list, err := labels.List(c.Request.Context(), scope.OrgID)
+		if err != nil {
+			return models.TaskFilter{}, false, err
+		}
+		for _, l := range list {
+			if strings.EqualFold(l.Name, label) {
+				query.Set("labelId", l.ID)
+			}
+		}
+		if !query.Has("labelId") {
+			return bad(fmt.Errorf("no label named %q", label))
+		}
+	}
+	query.Set("sort", sort)
+
+	filter, err := parseTaskQuery(query, workflow)
+	if err != nil {
+		return bad(err)
+	}
+	if len(filter.Fields) > 0 {
+		var defs []models.CustomField
+		if scope.IsOrg() {
+			if defs, err = fields.List(c.Request.Context(), scope.OrgID); err != nil {
+				return models.TaskFilter{}, false, err
+			}
+		}
+		if err := typeFieldFilters(&filter, defs, scope.UserID); err != nil {
+			return bad(err)
+		}
+	}
+	return filter, true, nil
+}
+
+func CreateViewHandler(views store.ViewStore, labels store.LabelStore, workflows store.WorkflowStore, fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateViewInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.Shared && !scope.IsOrg() {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Only views in an organization can be shared"))
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+		if _, ok, err := viewFilter(c, labels, fields, workflow, scope, input.Query, input.Sort); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to load view filter", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create view", err))
+			return
+		} else if !ok {
+			return
+		}
+
+		view, err := views.Create(c.Request.Context(), scope, input)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to create view", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create view", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, view)
+	}
+}
+
+// ListViewsHandler lists the caller's views and those shared with their org.
+func ListViewsHandler(views store.ViewStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := views.List(c.Request.Context(), scope)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list views", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list views", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"views": list})
+	}
+}
+
+func GetViewHandler(views store.ViewStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+
+		view, err := views.Get(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get view", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to get view", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, view)
+	}
+}
+
+// UpdateViewHandler changes a view; only its owner may.
+func UpdateViewHandler(views store.ViewStore, labels store.LabelStore, workflows store.WorkflowStore, fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+
+		var input models.UpdateViewInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.Shared != nil && *input.Shared && !scope.IsOrg() {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Only views in an organization can be shared"))
+			return
+		}
+
+		// The query and the sort don't depend on each other, so each is
+		// checked on its own.
+		if input.Query != nil || input.Sort != nil {
+			workflow, ok := loadWorkflow(c, workflows, scope)
+			if !ok {
+				return
+			}
+			query, sort := "", ""
+			if input.Query != nil {
+				query = *input.Query
+			}
+			if input.Sort != nil {
+				sort = *input.Sort
+			}
+			if _, ok, err := viewFilter(c, labels, fields, workflow, scope, query, sort); err != nil {
+				slog.ErrorContext(c.Request.Context(), "Failed to load view filter", "error", err)
+				apierror.Respond(c, apierror.Internal("Failed to update view", err))
+				return
+			} else if !ok {
+				return
+			}
+		}
+
+		view, err := views.Update(c.Request.Context(), scope, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Only the view's owner can change it"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update view", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update view", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, view)
+	}
+}
+
+func DeleteViewHandler(views store.ViewStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+
+		err := views.Delete(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+		if errors.Is(err, store.ErrReadOnly) {
+			apierror.Respond(c, apierror.Forbidden("Only the view's owner can delete it"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete view", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete view", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ViewTasksHandler lists the tasks a view matches for the caller, paged
+// like GET /tasks. A view whose query has gone stale, say by naming a label
+// that was since deleted, is a 400 until it's fixed.
+func ViewTasksHandler(views store.ViewStore, tasks store.TaskStore, labels store.LabelStore, workflows store.WorkflowStore, fields store.CustomFieldStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+
+		loc, ok := requestTimezone(c)
+		if !ok {
+			return
+		}
+
+		view, err := views.Get(c.Request.Context(), scope, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("View not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to get view", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list tasks", err))
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+		filter, ok, err := viewFilter(c, labels, fields, workflow, scope, view.Query, view.Sort)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to load view filter", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list tasks", err))
+			return
+		}
+		if !ok {
+			return
+		}
+
+		writeTaskPage(c, tasks, labels, scope, filter, loc)
+	}
+}
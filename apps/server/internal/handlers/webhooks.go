@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/webhooks"
+
+	"github.com/gin-gonic/gin"
+)
+
+// checkWebhookEvents validates and dedupes events in place, writing the
+// error response and returning false if they don't check out.
+func checkWebhookEvents(c *gin.Context, events *[]string) bool {
+	if err := models.CheckWebhookEvents(*events); err != nil {
+		apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid events: "+err.Error()).WithDetails(gin.H{"events": models.WebhookEvents}))
+		return false
+	}
+	slices.Sort(*events)
+	*events = slices.Compact(*events)
+	return true
+}
+
+// CreateWebhookHandler registers an endpoint for the org's events. The
+// signing secret is in this response only.
+func CreateWebhookHandler(hooks store.WebhookStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.CreateWebhookInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if err := models.CheckWebhookURL(input.URL); err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid url: "+err.Error()))
+			return
+		}
+		if !checkWebhookEvents(c, &input.Events) {
+			return
+		}
+
+		ctx := c.Request.Context()
+		existing, err := hooks.List(ctx, scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list webhooks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create webhook", err))
+			return
+		}
+		if len(existing) >= models.MaxWebhookEndpoints {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, fmt.Sprintf("An organization can have at most %d webhooks", models.MaxWebhookEndpoints)))
+			return
+		}
+
+		input.Secret = webhooks.NewSecret()
+		endpoint, err := hooks.Create(ctx, scope.OrgID, scope.UserID, input)
+		if quotaExceeded(c, err) {
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to create webhook", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to create webhook", err))
+			return
+		}
+
+		c.JSON(http.StatusCreated, endpoint)
+	}
+}
+
+func ListWebhooksHandler(hooks store.WebhookStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		list, err := hooks.List(c.Request.Context(), scope.OrgID)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to list webhooks", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list webhooks", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"webhooks": list})
+	}
+}
+
+// UpdateWebhookHandler changes an endpoint's url or events, or pauses it.
+// Deliveries already queued for a paused endpoint are dropped.
+func UpdateWebhookHandler(hooks store.WebhookStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Webhook not found"))
+			return
+		}
+
+		var input models.UpdateWebhookInput
+		if !bindJSON(c, &input) {
+			return
+		}
+		if input.URL != nil {
+			if err := models.CheckWebhookURL(*input.URL); err != nil {
+				apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid url: "+err.Error()))
+				return
+			}
+		}
+		if input.Events != nil && !checkWebhookEvents(c, input.Events) {
+			return
+		}
+
+		endpoint, err := hooks.Update(c.Request.Context(), scope.OrgID, id, input)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Webhook not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to update webhook", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update webhook", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, endpoint)
+	}
+}
+
+func DeleteWebhookHandler(hooks store.WebhookStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Webhook not found"))
+			return
+		}
+
+		err := hooks.Delete(c.Request.Context(), scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Webhook not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to delete webhook", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to delete webhook", err))
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// ListWebhookDeliveriesHandler pages through an endpoint's delivery
+// attempts, newest first, with what was sent and how the endpoint answered,
+// and the state of the breaker on its URL.
+func ListWebhookDeliveriesHandler(hooks store.WebhookStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		id := c.Param("id")
+		if !isValidID(id) {
+			apierror.Respond(c, apierror.NotFound("Webhook not found"))
+			return
+		}
+
+		page, err := api.ParsePage(c, "webhook_deliveries")
+		if err != nil {
+			api.PageError(c, err)
+			return
+		}
+
+		ctx := c.Request.Context()
+		endpoint, err := hooks.Get(ctx, scope.OrgID, id)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Webhook not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get webhook", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list deliveries", err))
+			return
+		}
+		list, err := hooks.Deliveries(ctx, scope.OrgID, id, page)
+		if errors.Is(err, store.ErrNotFound) {
+			apierror.Respond(c, apierror.NotFound("Webhook not found"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to list webhook deliveries", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list deliveries", err))
+			return
+		}
+		breaker, err := hooks.Breaker(ctx, scope.OrgID, endpoint.URL)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get webhook breaker", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to list deliveries", err))
+			return
+		}
+
+		list, hasMore := api.Trim(list, page.Limit)
+		pageInfo := api.PageInfo{HasMore: hasMore}
+		if hasMore {
+			last := list[len(list)-1]
+			pageInfo.NextCursor = api.EncodeCursor("webhook_deliveries", last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+		}
+
+		c.JSON(http.StatusOK, gin.H{"deliveries": list, "pageInfo": pageInfo, "breaker": breaker})
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// loadWorkflow returns the statuses and priorities tasks in scope are
+// validated against. Personal tasks always use the defaults. On failure it
+// has already written the response.
+func loadWorkflow(c *gin.Context, workflows store.WorkflowStore, scope models.Scope) (models.Workflow, bool) {
+	if !scope.IsOrg() {
+		return models.DefaultWorkflow(), true
+	}
+
+	workflow, err := workflows.Get(c.Request.Context(), scope.OrgID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get org workflow", "error", err)
+		apierror.Respond(c, apierror.Internal("Failed to load organization settings", err))
+		return models.Workflow{}, false
+	}
+	return workflow, true
+}
+
+// GetWorkflowHandler returns the org's full workflow; both the statuses and
+// priorities endpoints serve it so clients get one consistent shape.
+func GetWorkflowHandler(workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		workflow, ok := loadWorkflow(c, workflows, scope)
+		if !ok {
+			return
+		}
+
+		c.JSON(http.StatusOK, workflow)
+	}
+}
+
+func SetStatusesHandler(workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.SetStatusesInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		statuses, err := models.NormalizeStatuses(input.Statuses)
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid statuses: "+err.Error()))
+			return
+		}
+
+		workflow, err := workflows.SetStatuses(c.Request.Context(), scope.OrgID, statuses)
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("Some tasks still use a status that would be removed"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to set org statuses", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update statuses", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, workflow)
+	}
+}
+
+func SetPrioritiesHandler(workflows store.WorkflowStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope, ok := scopeFromContext(c)
+		if !ok {
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		var input models.SetPrioritiesInput
+		if !bindJSON(c, &input) {
+			return
+		}
+
+		priorities, err := models.NormalizePriorities(input.Priorities)
+		if err != nil {
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid priorities: "+err.Error()))
+			return
+		}
+
+		workflow, err := workflows.SetPriorities(c.Request.Context(), scope.OrgID, priorities)
+		if errors.Is(err, store.ErrConflict) {
+			apierror.Respond(c, apierror.Conflict("Some tasks still use a priority that would be removed"))
+			return
+		}
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to set org priorities", "error", err)
+			apierror.Respond(c, apierror.Internal("Failed to update priorities", err))
+			return
+		}
+
+		c.JSON(http.StatusOK, workflow)
+	}
+}
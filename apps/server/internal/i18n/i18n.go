@@ -0,0 +1,111 @@
+// Package i18n translates the text users read: API error messages, emails
+// and digests. Messages are looked up by their English text, so English
+// needs no catalog and anything not translated yet stays readable.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the locale used when neither the user nor the request picked
+// a supported one.
+const Default = "en"
+
+// Locales are the supported locales, Default first.
+var Locales = []string{"en", "hi", "es"}
+
+//go:embed catalogs/*.json
+var catalogFS embed.FS
+
+// catalogs maps a locale to its translations, keyed by the English message.
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[string]map[string]string {
+	out := map[string]map[string]string{}
+	for _, locale := range Locales[1:] {
+		b, err := catalogFS.ReadFile("catalogs/" + locale + ".json")
+		if err != nil {
+			panic(err)
+		}
+		messages := map[string]string{}
+		if err := json.Unmarshal(b, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: catalog %s: %v", locale, err))
+		}
+		out[locale] = messages
+	}
+	return out
+}
+
+func IsSupported(locale string) bool { return slices.Contains(Locales, locale) }
+
+// T translates msg into locale, falling back to msg itself, and formats the
+// result with args the way fmt.Sprintf does when there are any.
+func T(locale, msg string, args ...any) string {
+	if s, ok := catalogs[locale][msg]; ok {
+		msg = s
+	}
+	if len(args) == 0 {
+		return msg
+	}
+	return fmt.Sprintf(msg, args...)
+}
+
+// Message translates an API error message. Many are a fixed prefix and a
+// detail, such as "Invalid filter: unknown status", in which case only the
+// prefix is translated when the whole message isn't in the catalog.
+func Message(locale, msg string) string {
+	if s, ok := catalogs[locale][msg]; ok {
+		return s
+	}
+	prefix, detail, ok := strings.Cut(msg, ": ")
+	if !ok {
+		return msg
+	}
+	if s, ok := catalogs[locale][prefix]; ok {
+		return s + ": " + detail
+	}
+	return msg
+}
+
+// Match returns the supported locale an Accept-Language header prefers, or
+// "" if it names none of them. Regional variants match their language, so
+// es-MX picks es.
+func Match(header string) string {
+	type tag struct {
+		lang string
+		q    float64
+	}
+	var tags []tag
+	for _, part := range strings.Split(header, ",") {
+		lang, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		lang, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(lang)), "-")
+		if lang == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = f
+		}
+		if q > 0 {
+			tags = append(tags, tag{lang, q})
+		}
+	}
+	sort.SliceStable(tags, func(i, j int) bool { return tags[i].q > tags[j].q })
+
+	for _, t := range tags {
+		if IsSupported(t.lang) {
+			return t.lang
+		}
+	}
+	return ""
+}
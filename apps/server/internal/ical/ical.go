@@ -0,0 +1,106 @@
+// Package ical writes iCalendar (RFC 5545) feeds that calendar apps can
+// subscribe to.
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxLineOctets is where content lines are folded.
+const maxLineOctets = 75
+
+const (
+	utcLayout   = "20060102T150405Z"
+	localLayout = "20060102T150405"
+)
+
+// Event is one VEVENT.
+type Event struct {
+	UID         string
+	Summary     string
+	Description string
+	URL         string
+	Start       time.Time
+	// TZID, when set, gives Start in that zone so recurrences keep their
+	// local time across DST changes; otherwise Start is written in UTC.
+	TZID string
+	// RRule is a rule without the "RRULE:" prefix.
+	RRule    string
+	Modified time.Time
+}
+
+// Calendar is a VCALENDAR of events.
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Write writes the calendar with CRLF line endings and long lines folded.
+func (cal Calendar) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeFolded(bw, name+":"+value)
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//yata//tasks//EN")
+	line("CALSCALE", "GREGORIAN")
+	line("METHOD", "PUBLISH")
+	if cal.Name != "" {
+		line("X-WR-CALNAME", escape(cal.Name))
+	}
+	for _, e := range cal.Events {
+		line("BEGIN", "VEVENT")
+		line("UID", e.UID)
+		line("DTSTAMP", e.Modified.UTC().Format(utcLayout))
+		line("LAST-MODIFIED", e.Modified.UTC().Format(utcLayout))
+		if loc, err := time.LoadLocation(e.TZID); e.TZID != "" && err == nil {
+			line("DTSTART;TZID="+e.TZID, e.Start.In(loc).Format(localLayout))
+		} else {
+			line("DTSTART", e.Start.UTC().Format(utcLayout))
+		}
+		if e.RRule != "" {
+			line("RRULE", strings.TrimPrefix(e.RRule, "RRULE:"))
+		}
+		line("SUMMARY", escape(e.Summary))
+		if e.Description != "" {
+			line("DESCRIPTION", escape(e.Description))
+		}
+		if e.URL != "" {
+			line("URL", e.URL)
+		}
+		line("END", "VEVENT")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+// escape escapes a TEXT value.
+func escape(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writeFolded writes a content line, folding it every 75 octets without
+// splitting a UTF-8 sequence.
+func writeFolded(w *bufio.Writer, s string) {
+	limit := maxLineOctets
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(s[cut]) {
+			cut--
+		}
+		w.WriteString(s[:cut])
+		w.WriteString("\r\n ")
+		s = s[cut:]
+		// The leading space counts towards the continuation line.
+		limit = maxLineOctets - 1
+	}
+	w.WriteString(s)
+	w.WriteString("\r\n")
+}
@@ -0,0 +1,46 @@
+package importers
+
+import (
+	"time"
+)
+
+// parseAsana reads a project exported from Asana as CSV. Sections and tags
+// become labels, and the first of a task's projects its project. Asana
+// names a subtask's parent rather than giving its id, so a subtask goes
+// under the closest task above it with that name.
+func parseAsana(data []byte, loc *time.Location) ([]string, []Task, error) {
+	columns, rows, err := csvRows(data, "Task ID", "Name")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tasks []Task
+	byName := map[string]string{}
+	for _, row := range rows {
+		cells := row.cells
+		task := Task{
+			Ref:         cells["TASK ID"],
+			Line:        row.line,
+			Title:       cells["NAME"],
+			Description: cells["NOTES"],
+			Done:        cells["COMPLETED AT"] != "",
+		}
+		task.DueDate, _ = parseDate(cells["DUE DATE"], loc)
+		if projects := splitList(cells["PROJECTS"]); len(projects) > 0 {
+			task.Project = projects[0]
+		}
+		if section := cells["SECTION/COLUMN"]; section != "" {
+			task.Labels = append(task.Labels, section)
+		}
+		task.Labels = append(task.Labels, splitList(cells["TAGS"])...)
+		if parent := cells["PARENT TASK"]; parent != "" {
+			task.ParentRef = byName[parent]
+		}
+
+		if task.Ref != "" && task.Title != "" {
+			byName[task.Title] = task.Ref
+		}
+		tasks = append(tasks, task)
+	}
+	return columns, tasks, nil
+}
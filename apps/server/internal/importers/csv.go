@@ -0,0 +1,73 @@
+package importers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// csvRow is a row of a CSV export, its cells keyed by upper-cased column
+// name.
+type csvRow struct {
+	// line is where the row starts in the file, counting from 1.
+	line  int
+	cells map[string]string
+}
+
+// csvRows reads a CSV export with a header row, returning the columns as
+// the file names them and the rows after it. It fails unless every column
+// in required is present.
+func csvRows(data []byte, required ...string) ([]string, []csvRow, error) {
+	// Spreadsheet apps like to start the file with a byte order mark.
+	data = bytes.TrimPrefix(data, []byte("\uFEFF"))
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, fmt.Errorf("the file is empty")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, name := range header {
+		header[i] = strings.TrimSpace(name)
+	}
+	for _, name := range required {
+		if !containsFold(header, name) {
+			return nil, nil, fmt.Errorf("missing the %s column", name)
+		}
+	}
+
+	var rows []csvRow
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := r.FieldPos(0)
+		row := csvRow{line: line, cells: make(map[string]string, len(header))}
+		for i, cell := range record {
+			if i < len(header) {
+				row.cells[strings.ToUpper(header[i])] = strings.TrimSpace(cell)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return header, rows, nil
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
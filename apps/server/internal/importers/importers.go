@@ -0,0 +1,113 @@
+// Package importers reads the exports of other task managers into tasks,
+// and runs the job that creates them. Each source has its own parser; they
+// all map onto Task, which the import job then creates the way the API would.
+package importers
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+// MaxTasks caps how many entries one file may hold.
+const MaxTasks = 5000
+
+// ErrTooManyTasks is returned for files over MaxTasks.
+var ErrTooManyTasks = fmt.Errorf("an import can hold at most %d tasks", MaxTasks)
+
+// Task is one entry of an export, mapped onto yata's fields.
+type Task struct {
+	// Ref identifies the entry within the file; ParentRef points at the
+	// entry it's a subtask of, which always comes earlier.
+	Ref string
+	// Line is where a CSV entry is in the file; JSON entries have none.
+	Line        int
+	ParentRef   string
+	Title       string
+	Description string
+	Done        bool
+	// Priority is on the default workflow's scale.
+	Priority int
+	DueDate  *time.Time
+	// Project and Labels are names; they're matched against the org's, and
+	// created when missing.
+	Project string
+	Labels  []string
+}
+
+// Read reads a source's export, returning the tasks to create and a report
+// of the entries that are skipped and why. The import job and the
+// validation endpoint both go through it, so a file validates exactly as
+// it would import. Date-only due dates are read as midnight in loc.
+func Read(source string, data []byte, loc *time.Location) ([]Task, models.ImportReport, error) {
+	var entries []Task
+	var columns []string
+	var err error
+	switch source {
+	case models.ImportSourceTodoist:
+		columns, entries, err = parseTodoist(data, loc)
+	case models.ImportSourceTrello:
+		entries, err = parseTrello(data)
+	case models.ImportSourceAsana:
+		columns, entries, err = parseAsana(data, loc)
+	default:
+		return nil, models.ImportReport{}, errors.New("unknown source")
+	}
+	if err != nil {
+		return nil, models.ImportReport{}, err
+	}
+	if len(entries) > MaxTasks {
+		return nil, models.ImportReport{}, ErrTooManyTasks
+	}
+
+	report := models.ImportReport{Columns: columns, Errors: []models.ImportRowError{}}
+	if report.Columns == nil {
+		report.Columns = []string{}
+	}
+	tasks := make([]Task, 0, len(entries))
+	for _, t := range entries {
+		t.Title = strings.TrimSpace(t.Title)
+		// Blank titles are all the API turns away that a file can have.
+		if t.Title == "" {
+			rowErr := models.ImportRowError{Line: t.Line, Message: "Title is empty"}
+			if t.Line == 0 {
+				rowErr.Ref = t.Ref
+			}
+			report.Errors = append(report.Errors, rowErr)
+			continue
+		}
+		tasks = append(tasks, t)
+	}
+	report.Valid = len(tasks)
+	return tasks, report, nil
+}
+
+// parseDate reads the date formats the sources export.
+func parseDate(s string, loc *time.Location) (*time.Time, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, true
+	}
+	for _, layout := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return &t, true
+		}
+	}
+	return nil, false
+}
+
+// splitList splits a comma-separated cell, dropping blanks.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
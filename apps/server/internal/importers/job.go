@@ -0,0 +1,275 @@
+package importers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/store"
+)
+
+// JobKind is the job that imports an uploaded file.
+const JobKind = "import"
+
+// progressEvery is how many tasks go by between progress updates.
+const progressEvery = 50
+
+// maxLabelName matches what the labels API accepts.
+const maxLabelName = 50
+
+type jobPayload struct {
+	ImportID string `json:"importId"`
+}
+
+// Enqueue schedules the import. It runs once: the job's progress isn't a
+// checkpoint it could pick up from, so a retry would only duplicate tasks.
+func Enqueue(ctx context.Context, queue jobs.Queue, importID string) error {
+	return queue.Enqueue(ctx, JobKind, jobPayload{ImportID: importID}, jobs.MaxAttempts(1))
+}
+
+type Stores struct {
+	Imports     store.ImportStore
+	Tasks       store.TaskStore
+	Projects    store.ProjectStore
+	Labels      store.LabelStore
+	Workflows   store.WorkflowStore
+	OrgSettings store.OrgSettingsStore
+}
+
+// JobHandler creates the tasks of queued imports, reporting progress on the
+// import as it goes. Failures end up on the import rather than as job
+// errors, since the job isn't retried.
+func JobHandler(stores Stores) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var p jobPayload
+		if err := json.Unmarshal(payload, &p); err != nil {
+			return err
+		}
+
+		imp, data, err := stores.Imports.Start(ctx, p.ImportID)
+		if errors.Is(err, store.ErrNotFound) {
+			slog.WarnContext(ctx, "Import already started", "import", p.ImportID)
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		run := &importRun{stores: stores, imp: imp, scope: imp.Scope()}
+		err = run.run(ctx, data)
+		progress := models.ImportProgress{Status: models.ImportStatusDone, Imported: run.imported, Skipped: run.skipped}
+		if err != nil {
+			slog.ErrorContext(ctx, "Import failed", "import", imp.ID, "error", err)
+			msg := "The import stopped partway; the tasks created so far were kept"
+			var exceeded *quota.ExceededError
+			if errors.As(err, &exceeded) {
+				msg = "The import stopped at your organization's plan limit on tasks; the tasks created so far were kept"
+			} else if run.imported == 0 {
+				msg = "Nothing was imported"
+			}
+			progress.Status = models.ImportStatusFailed
+			progress.Error = &msg
+		}
+		// Recorded even if ctx was cancelled by a shutdown.
+		return stores.Imports.Progress(context.WithoutCancel(ctx), imp.ID, progress)
+	}
+}
+
+type importRun struct {
+	stores   Stores
+	imp      *models.Import
+	scope    models.Scope
+	workflow models.Workflow
+	// visibility is the org's default for new tasks.
+	visibility string
+	// projects and labels are keyed by lowercased name.
+	projects map[string]string
+	labels   map[string]string
+	color    string
+	// created maps entry Refs to the tasks made for them.
+	created  map[string]string
+	imported int
+	skipped  int
+}
+
+func (r *importRun) run(ctx context.Context, data []byte) error {
+	loc := time.UTC
+	if r.imp.Timezone != nil {
+		if l, err := time.LoadLocation(*r.imp.Timezone); err == nil {
+			loc = l
+		}
+	}
+	tasks, report, err := Read(r.imp.Source, data, loc)
+	if err != nil {
+		return err
+	}
+	r.skipped = len(report.Errors)
+	if err := r.prepare(ctx); err != nil {
+		return err
+	}
+
+	r.created = make(map[string]string, len(tasks))
+	for i, t := range tasks {
+		if err := r.create(ctx, t); err != nil {
+			return err
+		}
+		if (i+1)%progressEvery == 0 {
+			err := r.stores.Imports.Progress(ctx, r.imp.ID, models.ImportProgress{
+				Status:   models.ImportStatusRunning,
+				Imported: r.imported,
+				Skipped:  r.skipped,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// prepare loads what every task is checked against: the workflow, and in an
+// org its default visibility and the projects and labels to match names to.
+func (r *importRun) prepare(ctx context.Context) error {
+	r.workflow = models.DefaultWorkflow()
+	r.projects = map[string]string{}
+	r.labels = map[string]string{}
+	if !r.scope.IsOrg() {
+		return nil
+	}
+
+	workflow, err := r.stores.Workflows.Get(ctx, r.scope.OrgID)
+	if err != nil {
+		return err
+	}
+	r.workflow = workflow
+
+	settings, err := r.stores.OrgSettings.Get(ctx, r.scope.OrgID)
+	if err != nil {
+		return err
+	}
+	r.visibility = settings.DefaultTaskVisibility
+	r.color = settings.LabelColor()
+
+	page := models.Page{Limit: api.MaxLimit}
+	for {
+		list, err := r.stores.Projects.List(ctx, r.scope.OrgID, false, page)
+		if err != nil {
+			return err
+		}
+		list, hasMore := api.Trim(list, page.Limit)
+		for _, p := range list {
+			r.projects[strings.ToLower(p.Name)] = p.ID
+		}
+		if !hasMore {
+			break
+		}
+		last := list[len(list)-1]
+		page.After = []string{last.Name, last.ID}
+	}
+
+	labels, err := r.stores.Labels.List(ctx, r.scope.OrgID)
+	if err != nil {
+		return err
+	}
+	for _, l := range labels {
+		r.labels[strings.ToLower(l.Name)] = l.ID
+	}
+	return nil
+}
+
+func (r *importRun) create(ctx context.Context, t Task) error {
+	input := models.CreateTaskInput{
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      r.workflow.DefaultStatus(),
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		Visibility:  r.visibility,
+	}
+	if t.Done && r.workflow.CompletedStatus() != "" {
+		input.Status = r.workflow.CompletedStatus()
+	}
+	// Orgs can drop levels from the default scale.
+	if !r.workflow.IsValidPriority(input.Priority) {
+		input.Priority = r.workflow.Priorities[0].Level
+	}
+	if input.DueDate != nil && r.imp.Timezone != nil {
+		input.DueTimezone = r.imp.Timezone
+	}
+	// A subtask whose parent didn't make it is imported at the top level.
+	if parentID, ok := r.created[t.ParentRef]; ok && t.ParentRef != "" {
+		input.ParentID = &parentID
+	}
+	if r.scope.IsOrg() && t.Project != "" {
+		projectID, err := r.project(ctx, t.Project)
+		if err != nil {
+			return err
+		}
+		input.ProjectID = &projectID
+	}
+
+	task, err := r.stores.Tasks.Create(ctx, r.scope, input)
+	if err != nil {
+		return err
+	}
+	r.created[t.Ref] = task.ID
+	r.imported++
+
+	if !r.scope.IsOrg() {
+		return nil
+	}
+	attached := map[string]bool{}
+	for _, name := range t.Labels {
+		labelID, ok, err := r.label(ctx, name)
+		if err != nil {
+			return err
+		}
+		if !ok || attached[labelID] {
+			continue
+		}
+		if err := r.stores.Labels.Attach(ctx, r.scope.OrgID, task.ID, labelID); err != nil {
+			return err
+		}
+		attached[labelID] = true
+	}
+	return nil
+}
+
+// project finds the org's project with name, creating it the first time.
+func (r *importRun) project(ctx context.Context, name string) (string, error) {
+	key := strings.ToLower(name)
+	if id, ok := r.projects[key]; ok {
+		return id, nil
+	}
+	project, err := r.stores.Projects.Create(ctx, r.scope.OrgID, r.scope.UserID, models.CreateProjectInput{Name: name})
+	if err != nil {
+		return "", err
+	}
+	r.projects[key] = project.ID
+	return project.ID, nil
+}
+
+// label finds the org's label with name, creating it the first time. Names
+// the labels API wouldn't take are dropped.
+func (r *importRun) label(ctx context.Context, name string) (string, bool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxLabelName {
+		return "", false, nil
+	}
+	key := strings.ToLower(name)
+	if id, ok := r.labels[key]; ok {
+		return id, true, nil
+	}
+	label, err := r.stores.Labels.Create(ctx, r.scope.OrgID, r.scope.UserID, models.CreateLabelInput{Name: name, Color: r.color})
+	if err != nil {
+		return "", false, err
+	}
+	r.labels[key] = label.ID
+	return label.ID, true, nil
+}
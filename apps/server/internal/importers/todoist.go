@@ -0,0 +1,86 @@
+package importers
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+// todoistPriorities maps Todoist's p1 (most urgent) to p4 (none).
+var todoistPriorities = map[string]int{
+	"1": models.PriorityUrgent,
+	"2": models.PriorityHigh,
+	"3": models.PriorityMedium,
+	"4": models.PriorityNone,
+}
+
+// parseTodoist reads a project exported from Todoist as CSV. Sections
+// become labels, since yata has nothing between a project and its tasks;
+// @labels in a task's content do too. Subtasks are nested by INDENT under
+// the closest task above them. Notes are comments and aren't imported.
+func parseTodoist(data []byte, loc *time.Location) ([]string, []Task, error) {
+	columns, rows, err := csvRows(data, "TYPE", "CONTENT")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var tasks []Task
+	var section string
+	// parents[d] is the Ref of the last task at indent d+1.
+	var parents []string
+	for i, row := range rows {
+		switch strings.ToLower(row.cells["TYPE"]) {
+		case "section":
+			section = row.cells["CONTENT"]
+			parents = nil
+			continue
+		case "task":
+		default:
+			continue
+		}
+
+		title, labels := todoistLabels(row.cells["CONTENT"])
+		task := Task{
+			Ref:         strconv.Itoa(i),
+			Line:        row.line,
+			Title:       title,
+			Description: row.cells["DESCRIPTION"],
+			Priority:    todoistPriorities[row.cells["PRIORITY"]],
+			Labels:      labels,
+		}
+		if section != "" {
+			task.Labels = append(task.Labels, section)
+		}
+		// DATE holds whatever was typed, e.g. "every monday"; only real
+		// dates carry over.
+		task.DueDate, _ = parseDate(row.cells["DATE"], loc)
+
+		indent, err := strconv.Atoi(row.cells["INDENT"])
+		if err != nil || indent < 1 {
+			indent = 1
+		}
+		if indent > len(parents)+1 {
+			indent = len(parents) + 1
+		}
+		parents = append(parents[:indent-1], task.Ref)
+		if indent > 1 {
+			task.ParentRef = parents[indent-2]
+		}
+		tasks = append(tasks, task)
+	}
+	return columns, tasks, nil
+}
+
+// todoistLabels pulls the @labels out of a task's content.
+func todoistLabels(content string) (string, []string) {
+	var words, labels []string
+	for _, word := range strings.Fields(content) {
+		if len(word) > 1 && word[0] == '@' {
+			labels = append(labels, word[1:])
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), labels
+}
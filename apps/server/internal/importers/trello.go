@@ -0,0 +1,99 @@
+package importers
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// trelloBoard is the part of Trello's board JSON export that's imported.
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string     `json:"id"`
+		Name        string     `json:"name"`
+		Desc        string     `json:"desc"`
+		Due         *time.Time `json:"due"`
+		DueComplete bool       `json:"dueComplete"`
+		Closed      bool       `json:"closed"`
+		IDList      string     `json:"idList"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string `json:"idCard"`
+		CheckItems []struct {
+			ID    string `json:"id"`
+			Name  string `json:"name"`
+			State string `json:"state"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+// parseTrello reads a board exported from Trello as JSON. The board becomes
+// the project and each card a task, labelled with its list (Trello's
+// columns) and its own labels. Checklist items become subtasks. Archived
+// cards are left out.
+func parseTrello(data []byte) ([]Task, error) {
+	var board trelloBoard
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, errors.New("not a Trello board export")
+	}
+	if board.Cards == nil {
+		return nil, errors.New("not a Trello board export")
+	}
+
+	lists := make(map[string]string, len(board.Lists))
+	for _, l := range board.Lists {
+		lists[l.ID] = l.Name
+	}
+	checklists := map[string][]Task{}
+	for _, cl := range board.Checklists {
+		for _, item := range cl.CheckItems {
+			checklists[cl.IDCard] = append(checklists[cl.IDCard], Task{
+				Ref:       item.ID,
+				ParentRef: cl.IDCard,
+				Title:     item.Name,
+				Done:      item.State == "complete",
+				Project:   board.Name,
+			})
+		}
+	}
+
+	var tasks []Task
+	for _, card := range board.Cards {
+		if card.Closed {
+			continue
+		}
+		task := Task{
+			Ref:         card.ID,
+			Title:       card.Name,
+			Description: card.Desc,
+			Done:        card.DueComplete,
+			DueDate:     card.Due,
+			Project:     board.Name,
+		}
+		if list := lists[card.IDList]; list != "" {
+			task.Labels = append(task.Labels, list)
+		}
+		for _, l := range card.Labels {
+			// Trello labels can be just a color.
+			name := l.Name
+			if name == "" {
+				name = l.Color
+			}
+			if name != "" {
+				task.Labels = append(task.Labels, name)
+			}
+		}
+		tasks = append(tasks, task)
+		tasks = append(tasks, checklists[card.ID]...)
+	}
+	return tasks, nil
+}
@@ -0,0 +1,132 @@
+// Package inbound reads the email a mail provider forwards to the API and
+// works out which inbound address it was sent to and what task it makes.
+package inbound
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSignatureAge is how old a signed webhook may be, against replays.
+const maxSignatureAge = 10 * time.Minute
+
+// maxMemory is how much of a message is parsed in memory; the rest of the
+// attachments spill to temporary files.
+const maxMemory = 8 << 20
+
+var ErrInvalidSignature = errors.New("inbound email signature is invalid")
+
+// Email is a received message. Text leaves out quoted replies and the
+// signature when the provider could tell them apart.
+type Email struct {
+	Recipients  []string
+	From        string
+	Subject     string
+	Text        string
+	Attachments []*multipart.FileHeader
+}
+
+// ParseMailgun reads a message posted by a Mailgun route with the forward()
+// action, after checking it was signed with the webhook signing key.
+func ParseMailgun(r *http.Request, signingKey string, now time.Time) (*Email, error) {
+	err := r.ParseMultipartForm(maxMemory)
+	// Messages without attachments come form-encoded.
+	if err != nil && !errors.Is(err, http.ErrNotMultipart) {
+		return nil, err
+	}
+	if err := verifyMailgun(signingKey, r.PostFormValue("timestamp"), r.PostFormValue("token"), r.PostFormValue("signature"), now); err != nil {
+		return nil, err
+	}
+
+	email := &Email{
+		From:    r.PostFormValue("from"),
+		Subject: r.PostFormValue("subject"),
+		Text:    r.PostFormValue("stripped-text"),
+	}
+	if strings.TrimSpace(email.Text) == "" {
+		email.Text = r.PostFormValue("body-plain")
+	}
+	if list, err := mail.ParseAddressList(r.PostFormValue("recipient")); err == nil {
+		for _, addr := range list {
+			email.Recipients = append(email.Recipients, addr.Address)
+		}
+	}
+	if r.MultipartForm != nil {
+		count, _ := strconv.Atoi(r.PostFormValue("attachment-count"))
+		for i := 1; i <= count; i++ {
+			email.Attachments = append(email.Attachments, r.MultipartForm.File[fmt.Sprintf("attachment-%d", i)]...)
+		}
+	}
+	return email, nil
+}
+
+// verifyMailgun checks the hex HMAC-SHA256 of timestamp and token.
+func verifyMailgun(key, timestamp, token, signature string, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return ErrInvalidSignature
+	}
+	sig, err := hex.DecodeString(signature)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(timestamp + token))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Token returns the local part of the first recipient at domain, which
+// identifies the inbound address, or "" if none is there.
+func (e *Email) Token(domain string) string {
+	for _, addr := range e.Recipients {
+		local, host, ok := strings.Cut(addr, "@")
+		if ok && strings.EqualFold(host, domain) {
+			return strings.ToLower(local)
+		}
+	}
+	return ""
+}
+
+// forwardPrefixes are what mail clients put in front of a forwarded subject.
+var forwardPrefixes = []string{"fwd:", "fw:"}
+
+// Task returns the task's title and description: the subject without any
+// forwarding prefix and the body, or the body's first line as the title
+// when there's no subject.
+func (e *Email) Task() (title, description string) {
+	title = strings.TrimSpace(e.Subject)
+	for trimmed := true; trimmed; {
+		trimmed = false
+		for _, p := range forwardPrefixes {
+			if len(title) >= len(p) && strings.EqualFold(title[:len(p)], p) {
+				title = strings.TrimSpace(title[len(p):])
+				trimmed = true
+			}
+		}
+	}
+	description = strings.TrimSpace(strings.ReplaceAll(e.Text, "\r\n", "\n"))
+	if title == "" {
+		title, description, _ = strings.Cut(description, "\n")
+		title, description = strings.TrimSpace(title), strings.TrimSpace(description)
+	}
+	if title == "" {
+		title = "Email from " + e.From
+	}
+	return title, description
+}
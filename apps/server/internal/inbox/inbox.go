@@ -0,0 +1,34 @@
+// Package inbox records notifications in the in-app inbox.
+package inbox
+
+import (
+	"context"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/store"
+)
+
+// Notifier stores every notification it's given in the recipient's inbox.
+type Notifier struct {
+	Notifications store.NotificationStore
+}
+
+func (n *Notifier) Notify(ctx context.Context, note notify.Notification) error {
+	_, err := n.Notifications.Create(ctx, models.CreateNotificationInput{
+		UserID:  note.UserID,
+		OrgID:   note.OrgID,
+		Kind:    note.Kind,
+		TaskID:  optional(note.TaskID),
+		ActorID: optional(note.ActorID),
+		Title:   note.Title,
+		Body:    note.Body,
+	})
+	return err
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
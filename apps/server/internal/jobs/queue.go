@@ -0,0 +1,112 @@
+// Package jobs is a small Postgres-backed queue for deferred work such as
+// notification delivery. Jobs are claimed with FOR UPDATE SKIP LOCKED, so any
+// number of workers across instances can share one table.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+const defaultMaxAttempts = 5
+
+// Queue is what producers depend on.
+type Queue interface {
+	Enqueue(ctx context.Context, kind string, payload any, opts ...EnqueueOption) error
+}
+
+type enqueueOptions struct {
+	runAt       time.Time
+	maxAttempts int
+}
+
+type EnqueueOption func(*enqueueOptions)
+
+// RunAt delays the job until t.
+func RunAt(t time.Time) EnqueueOption {
+	return func(o *enqueueOptions) { o.runAt = t }
+}
+
+// MaxAttempts caps how often a failing job is retried before it's marked failed.
+func MaxAttempts(n int) EnqueueOption {
+	return func(o *enqueueOptions) { o.maxAttempts = n }
+}
+
+type PostgresQueue struct {
+	pool *pgxpool.Pool
+}
+
+func NewPostgresQueue(pool *pgxpool.Pool) *PostgresQueue {
+	return &PostgresQueue{pool: pool}
+}
+
+func (q *PostgresQueue) Enqueue(ctx context.Context, kind string, payload any, opts ...EnqueueOption) error {
+	return enqueue(ctx, q.pool, kind, payload, opts)
+}
+
+// TxQueue enqueues within tx, so its jobs only exist if tx commits.
+func TxQueue(tx pgx.Tx) Queue {
+	return txQueue{tx: tx}
+}
+
+type txQueue struct {
+	tx pgx.Tx
+}
+
+func (q txQueue) Enqueue(ctx context.Context, kind string, payload any, opts ...EnqueueOption) error {
+	return enqueue(ctx, q.tx, kind, payload, opts)
+}
+
+// execer is a pool or a transaction.
+type execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+func enqueue(ctx context.Context, db execer, kind string, payload any, opts []EnqueueOption) error {
+	o := enqueueOptions{runAt: time.Now(), maxAttempts: defaultMaxAttempts}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+
+	_, err = db.Exec(ctx,
+		`INSERT INTO jobs (kind, payload, run_at, max_attempts, trace_context) VALUES ($1, $2, $3, $4, $5)`,
+		kind, data, o.runAt, o.maxAttempts, carrier,
+	)
+	return err
+}
+
+// Depth counts the jobs in each status: those waiting to run, running, and
+// given up on.
+func (q *PostgresQueue) Depth(ctx context.Context) (map[string]int, error) {
+	rows, err := q.pool.Query(ctx, `SELECT status, COUNT(*) FROM jobs GROUP BY status`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	depth := map[string]int{}
+	for rows.Next() {
+		var status string
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		depth[status] = n
+	}
+	return depth, rows.Err()
+}
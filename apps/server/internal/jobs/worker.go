@@ -0,0 +1,221 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"yata/apps/server/internal/tracing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Handler runs one job. Returning an error schedules a retry with backoff.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Postpone is what a handler returns when it can't run its job yet, rather
+// than failed to: the job runs again after d, at least a second from now,
+// and the attempt doesn't count towards its MaxAttempts.
+func Postpone(d time.Duration) error {
+	return &postponed{delay: max(d, time.Second)}
+}
+
+type postponed struct {
+	delay time.Duration
+}
+
+func (p *postponed) Error() string {
+	return "postponed for " + p.delay.String()
+}
+
+type job struct {
+	ID          int64
+	Kind        string
+	Payload     json.RawMessage
+	Attempts    int
+	MaxAttempts int
+	Trace       propagation.MapCarrier
+}
+
+// Worker polls the jobs table and dispatches to the handler registered for
+// each kind.
+type Worker struct {
+	Concurrency  int
+	PollInterval time.Duration
+	// Lease is how long a claimed job may run before another worker may
+	// pick it up again, e.g. after a crash.
+	Lease time.Duration
+
+	pool     *pgxpool.Pool
+	handlers map[string]Handler
+	loops    sync.WaitGroup
+}
+
+func NewWorker(pool *pgxpool.Pool, concurrency int, pollInterval time.Duration) *Worker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &Worker{
+		Concurrency:  concurrency,
+		PollInterval: pollInterval,
+		Lease:        5 * time.Minute,
+		pool:         pool,
+		handlers:     map[string]Handler{},
+	}
+}
+
+// Handle registers h for kind. Call it before Start.
+func (w *Worker) Handle(kind string, h Handler) {
+	w.handlers[kind] = h
+}
+
+// Start runs Concurrency polling loops until ctx is done. Jobs already
+// running when it's done are left to finish; Wait blocks until they have.
+func (w *Worker) Start(ctx context.Context) {
+	for i := 0; i < w.Concurrency; i++ {
+		w.loops.Add(1)
+		go func() {
+			defer w.loops.Done()
+			w.loop(ctx)
+		}()
+	}
+}
+
+func (w *Worker) Wait() {
+	w.loops.Wait()
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	for {
+		ran, err := w.RunOne(ctx)
+		if err != nil && ctx.Err() == nil {
+			slog.Error("Failed to run job", "error", err)
+		}
+		if ran {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(w.PollInterval):
+		}
+	}
+}
+
+// RunOne claims and runs a single job. It reports false when nothing was due.
+func (w *Worker) RunOne(ctx context.Context) (bool, error) {
+	j, err := w.claim(ctx)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	// Once claimed, the job runs and is recorded even if ctx is cancelled
+	// meanwhile, so a shutdown doesn't leave it to wait out its lease.
+	ctx = context.WithoutCancel(ctx)
+
+	// The job's span continues the trace of whatever enqueued it, and the
+	// bookkeeping below shows up under it.
+	ctx = otel.GetTextMapPropagator().Extract(ctx, j.Trace)
+	ctx, span := otel.Tracer(tracing.Name).Start(ctx, "job "+j.Kind,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.Int64("job.id", j.ID),
+			attribute.String("job.kind", j.Kind),
+			attribute.Int("job.attempt", j.Attempts),
+		),
+	)
+	defer span.End()
+
+	runErr := w.run(ctx, j)
+	var p *postponed
+	if errors.As(runErr, &p) {
+		span.SetAttributes(attribute.String("job.postponed", p.delay.String()))
+		_, err = w.pool.Exec(ctx,
+			`UPDATE jobs SET status = 'pending', attempts = attempts - 1, locked_until = NULL, run_at = NOW() + $2::interval, updated_at = NOW()
+			 WHERE id = $1`,
+			j.ID, p.delay,
+		)
+		return true, err
+	}
+	if runErr != nil {
+		span.RecordError(runErr)
+		span.SetStatus(codes.Error, runErr.Error())
+	}
+	if runErr == nil {
+		_, err = w.pool.Exec(ctx, `DELETE FROM jobs WHERE id = $1`, j.ID)
+		return true, err
+	}
+
+	slog.Error("Job failed", "job_id", j.ID, "kind", j.Kind, "attempt", j.Attempts, "error", runErr)
+	if j.Attempts >= j.MaxAttempts {
+		_, err = w.pool.Exec(ctx,
+			`UPDATE jobs SET status = 'failed', locked_until = NULL, last_error = $2, updated_at = NOW() WHERE id = $1`,
+			j.ID, runErr.Error(),
+		)
+		return true, err
+	}
+
+	_, err = w.pool.Exec(ctx,
+		`UPDATE jobs SET status = 'pending', locked_until = NULL, run_at = NOW() + $2::interval, last_error = $3, updated_at = NOW()
+		 WHERE id = $1`,
+		j.ID, backoff(j.Attempts), runErr.Error(),
+	)
+	return true, err
+}
+
+func (w *Worker) claim(ctx context.Context) (job, error) {
+	var j job
+	err := w.pool.QueryRow(ctx,
+		`UPDATE jobs SET status = 'running', attempts = attempts + 1,
+		        locked_until = NOW() + $1::interval, updated_at = NOW()
+		 WHERE id = (
+			SELECT id FROM jobs
+			WHERE (status = 'pending' AND run_at <= NOW())
+			   OR (status = 'running' AND locked_until < NOW())
+			ORDER BY run_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		 )
+		 RETURNING id, kind, payload, attempts, max_attempts, trace_context`,
+		w.Lease,
+	).Scan(&j.ID, &j.Kind, &j.Payload, &j.Attempts, &j.MaxAttempts, &j.Trace)
+	return j, err
+}
+
+func (w *Worker) run(ctx context.Context, j job) (err error) {
+	h, ok := w.handlers[j.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %q", j.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, w.Lease)
+	defer cancel()
+	return h(ctx, j.Payload)
+}
+
+// backoff grows exponentially from 10s and tops out at an hour.
+func backoff(attempt int) time.Duration {
+	d := 10 * time.Second
+	for i := 1; i < attempt && d < time.Hour; i++ {
+		d *= 2
+	}
+	return min(d, time.Hour)
+}
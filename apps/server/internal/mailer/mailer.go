@@ -0,0 +1,71 @@
+// Package mailer sends transactional email through a configurable provider.
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Mailer is implemented by each provider.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Config struct {
+	// Provider is one of "log", "smtp", "ses" or "resend".
+	Provider string
+	From     string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+
+	// SESRegion selects Amazon SES's SMTP endpoint; SMTPUsername and
+	// SMTPPassword are the SES SMTP credentials.
+	SESRegion string
+
+	ResendAPIKey string
+}
+
+// New builds the Mailer for cfg.Provider.
+func New(cfg Config) (Mailer, error) {
+	switch cfg.Provider {
+	case "", "log":
+		return LogMailer{}, nil
+	case "smtp":
+		if cfg.SMTPHost == "" {
+			return nil, fmt.Errorf("smtp mailer needs a host")
+		}
+		return NewSMTP(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case "ses":
+		if cfg.SESRegion == "" {
+			return nil, fmt.Errorf("ses mailer needs a region")
+		}
+		host := "email-smtp." + cfg.SESRegion + ".amazonaws.com"
+		return NewSMTP(host, 587, cfg.SMTPUsername, cfg.SMTPPassword, cfg.From), nil
+	case "resend":
+		if cfg.ResendAPIKey == "" {
+			return nil, fmt.Errorf("resend mailer needs an API key")
+		}
+		return NewResend(cfg.ResendAPIKey, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unknown mail provider %q", cfg.Provider)
+	}
+}
+
+// LogMailer only logs what it would send, for development.
+type LogMailer struct{}
+
+func (LogMailer) Send(_ context.Context, msg Message) error {
+	slog.Info("Email", "to", msg.To, "subject", msg.Subject)
+	return nil
+}
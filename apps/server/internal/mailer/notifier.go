@@ -0,0 +1,123 @@
+package mailer
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/store"
+
+	clerkuser "github.com/clerk/clerk-sdk-go/v2/user"
+)
+
+// Directory resolves user ids to the address email goes to and the name
+// shown for them.
+type Directory interface {
+	Email(ctx context.Context, userID string) (string, error)
+	Name(ctx context.Context, userID string) (string, error)
+}
+
+// ClerkDirectory looks users up in Clerk.
+type ClerkDirectory struct{}
+
+func (ClerkDirectory) Email(ctx context.Context, userID string) (string, error) {
+	u, err := clerkuser.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	for _, e := range u.EmailAddresses {
+		if u.PrimaryEmailAddressID != nil && e.ID == *u.PrimaryEmailAddressID {
+			return e.EmailAddress, nil
+		}
+	}
+	return "", nil
+}
+
+func (ClerkDirectory) Name(ctx context.Context, userID string) (string, error) {
+	u, err := clerkuser.Get(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	return displayName(u.FirstName, u.LastName), nil
+}
+
+// Notifier emails notifications the recipient hasn't opted out of.
+type Notifier struct {
+	Mailer      Mailer
+	Templates   *Templates
+	Directory   Directory
+	Preferences store.EmailPreferenceStore
+	// Settings can turn email off entirely and decide the language and the
+	// zone times are shown in.
+	Settings store.UserSettingsStore
+	// AppURL is the web app's base URL, used to link to tasks.
+	AppURL string
+}
+
+func (n *Notifier) Notify(ctx context.Context, note notify.Notification) error {
+	template, wanted := "", func(models.EmailPreferences) bool { return false }
+	switch note.Kind {
+	case notify.KindReminder:
+		template, wanted = TemplateDueSoon, func(p models.EmailPreferences) bool { return p.DueSoon }
+	case notify.KindTaskAssigned:
+		template, wanted = TemplateTaskAssigned, func(p models.EmailPreferences) bool { return p.TaskAssigned }
+	case notify.KindCommentMention:
+		template, wanted = TemplateCommentMention, func(p models.EmailPreferences) bool { return p.CommentMention }
+	default:
+		return nil
+	}
+
+	settings, err := n.Settings.Get(ctx, note.UserID)
+	if err != nil {
+		return err
+	}
+	if !settings.NotificationChannels.Email {
+		return nil
+	}
+	prefs, err := n.Preferences.Get(ctx, note.UserID)
+	if err != nil {
+		return err
+	}
+	if !wanted(prefs) {
+		return nil
+	}
+
+	to, err := n.Directory.Email(ctx, note.UserID)
+	if err != nil {
+		return err
+	}
+	if to == "" {
+		slog.Warn("No email address, skipping notification", "user_id", note.UserID, "kind", note.Kind)
+		return nil
+	}
+
+	data := TemplateData{TaskTitle: note.Title, Body: note.Body, Locale: settings.PreferredLocale()}
+	if note.DueDate != nil {
+		data.DueDate = note.DueDate.In(settings.Location()).Format(time.RFC1123)
+	}
+	if n.AppURL != "" && note.TaskID != "" {
+		data.TaskURL = strings.TrimRight(n.AppURL, "/") + "/tasks/" + note.TaskID
+	}
+	if note.ActorID != "" {
+		// The email still makes sense without a name, so don't fail on it.
+		data.ActorName, _ = n.Directory.Name(ctx, note.ActorID)
+	}
+
+	msg, err := n.Templates.Render(template, to, data)
+	if err != nil {
+		return err
+	}
+	return n.Mailer.Send(ctx, msg)
+}
+
+func displayName(first, last *string) string {
+	parts := []string{}
+	for _, p := range []*string{first, last} {
+		if p != nil && *p != "" {
+			parts = append(parts, *p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
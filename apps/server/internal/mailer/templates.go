@@ -0,0 +1,116 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	texttemplate "text/template"
+	"yata/apps/server/internal/i18n"
+)
+
+//go:embed templates
+var templateFS embed.FS
+
+const (
+	TemplateTaskAssigned   = "task_assigned"
+	TemplateDueSoon        = "due_soon"
+	TemplateCommentMention = "comment_mention"
+	TemplateDigest         = "digest"
+)
+
+// TemplateData is what every email template renders from.
+type TemplateData struct {
+	TaskTitle string
+	TaskURL   string
+	DueDate   string
+	ActorName string
+	Body      string
+	// Locale is the language the email is written in.
+	Locale string
+
+	// Digests list tasks by section instead of naming one; Frequency is
+	// daily or weekly.
+	Frequency string
+	Overdue   []DigestItem
+	Due       []DigestItem
+	Assigned  []DigestItem
+}
+
+// DigestItem is a task listed in a digest.
+type DigestItem struct {
+	Title   string
+	URL     string
+	DueDate string
+}
+
+// Templates write their text as {{t .Locale "English %s" args}}. Text
+// templates set titles off with quote, HTML ones with strong; in HTML, t
+// escapes its arguments rather than its message so they can carry markup.
+var (
+	textFuncs = texttemplate.FuncMap{
+		"t":     i18n.T,
+		"quote": func(s string) string { return `"` + s + `"` },
+	}
+	htmlFuncs = htmltemplate.FuncMap{
+		"t": func(locale, msg string, args ...any) htmltemplate.HTML {
+			for i, a := range args {
+				if _, ok := a.(htmltemplate.HTML); !ok {
+					args[i] = htmltemplate.HTMLEscapeString(fmt.Sprint(a))
+				}
+			}
+			return htmltemplate.HTML(i18n.T(locale, msg, args...))
+		},
+		"strong": func(s string) htmltemplate.HTML {
+			return htmltemplate.HTML("<strong>" + htmltemplate.HTMLEscapeString(s) + "</strong>")
+		},
+	}
+)
+
+type emailTemplate struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// Templates holds the parsed templates; each name has a .txt file defining
+// "subject" and "text" and a .html file for the HTML body.
+type Templates struct {
+	byName map[string]emailTemplate
+}
+
+func LoadTemplates() (*Templates, error) {
+	t := &Templates{byName: map[string]emailTemplate{}}
+	for _, name := range []string{TemplateTaskAssigned, TemplateDueSoon, TemplateCommentMention, TemplateDigest} {
+		text, err := texttemplate.New(name+".txt").Funcs(textFuncs).ParseFS(templateFS, "templates/"+name+".txt")
+		if err != nil {
+			return nil, err
+		}
+		html, err := htmltemplate.New(name+".html").Funcs(htmlFuncs).ParseFS(templateFS, "templates/"+name+".html")
+		if err != nil {
+			return nil, err
+		}
+		t.byName[name] = emailTemplate{text: text, html: html}
+	}
+	return t, nil
+}
+
+// Render builds the message for name, addressed to to.
+func (t *Templates) Render(name, to string, data TemplateData) (Message, error) {
+	tmpl, ok := t.byName[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return Message{}, err
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{To: to, Subject: headerSafe(subject.String()), Text: text.String(), HTML: html.String()}, nil
+}
@@ -0,0 +1,69 @@
+// Package maintenance switches the API into read-only or full maintenance
+// mode, so risky migrations can run without writes racing them. The mode
+// comes from MAINTENANCE_MODE when it's set, and otherwise from two global
+// feature flags, so admins can flip it on every instance without a deploy.
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/flags"
+)
+
+const (
+	Off      = "off"
+	ReadOnly = "read_only"
+	Full     = "full"
+)
+
+// The global flags the mode is kept in; full wins when both are on.
+const (
+	fullFlag     = "maintenance.full"
+	readOnlyFlag = "maintenance.read_only"
+)
+
+// ErrFixed is setting the mode when MAINTENANCE_MODE decides it.
+var ErrFixed = errors.New("maintenance mode is set by MAINTENANCE_MODE")
+
+// Valid reports whether mode is one of Off, ReadOnly and Full.
+func Valid(mode string) bool {
+	return mode == Off || mode == ReadOnly || mode == Full
+}
+
+// Switch reads and sets the mode. Flags are cached per instance, so a
+// change made on another instance takes up to half a minute to apply;
+// wait that long before starting the migration.
+type Switch struct {
+	flags *flags.Flags
+	fixed string
+}
+
+// New reads the mode from f, unless fixed, from config, is a mode.
+func New(f *flags.Flags, fixed string) *Switch {
+	return &Switch{flags: f, fixed: fixed}
+}
+
+func (s *Switch) Mode(ctx context.Context) string {
+	switch {
+	case Valid(s.fixed):
+		return s.fixed
+	case s.flags.Enabled(ctx, fullFlag, "", ""):
+		return Full
+	case s.flags.Enabled(ctx, readOnlyFlag, "", ""):
+		return ReadOnly
+	}
+	return Off
+}
+
+// Set turns the mode on everywhere, or returns ErrFixed.
+func (s *Switch) Set(ctx context.Context, mode string) error {
+	if Valid(s.fixed) {
+		return ErrFixed
+	}
+	for key, on := range map[string]bool{fullFlag: mode == Full, readOnlyFlag: mode == ReadOnly} {
+		if _, err := s.flags.Set(ctx, flags.Flag{Key: key, Scope: flags.ScopeGlobal, Enabled: on}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
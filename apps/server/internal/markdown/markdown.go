@@ -0,0 +1,45 @@
+// Package markdown renders the Markdown users write in task descriptions and
+// comments to HTML that is safe to put on a page.
+package markdown
+
+import (
+	"bytes"
+	"html"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	gmhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// GitHub flavored, with single newlines kept as line breaks since that's
+// how people type comments. Raw HTML in the source is left out.
+var renderer = goldmark.New(
+	goldmark.WithExtensions(extension.GFM),
+	goldmark.WithRendererOptions(gmhtml.WithHardWraps()),
+)
+
+var policy = newPolicy()
+
+// newPolicy is bluemonday's policy for user generated content, plus the
+// disabled checkboxes of GFM task lists.
+func newPolicy() *bluemonday.Policy {
+	p := bluemonday.UGCPolicy()
+	p.AllowAttrs("type").Matching(regexp.MustCompile(`^checkbox$`)).OnElements("input")
+	p.AllowAttrs("checked", "disabled").OnElements("input")
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+	return p
+}
+
+// ToHTML renders src and sanitizes the result. The renderer already drops
+// raw HTML and dangerous link targets; the sanitizer is there so that
+// neither has to be relied on alone.
+func ToHTML(src string) string {
+	var buf bytes.Buffer
+	if err := renderer.Convert([]byte(src), &buf); err != nil {
+		// Converting only fails when writing to the buffer does.
+		return "<p>" + html.EscapeString(src) + "</p>"
+	}
+	return string(policy.SanitizeBytes(buf.Bytes()))
+}
@@ -0,0 +1,33 @@
+// Package members removes people from orgs in Clerk.
+package members
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/organizationmembership"
+)
+
+// Remover takes a member out of an org, ending their access to it.
+type Remover interface {
+	Remove(ctx context.Context, orgID, userID string) error
+}
+
+// ClerkRemover deletes the Clerk organization membership. Clerk's webhook
+// later deletes the local copy too.
+type ClerkRemover struct{}
+
+// Remove treats a membership Clerk no longer has as removed.
+func (ClerkRemover) Remove(ctx context.Context, orgID, userID string) error {
+	_, err := organizationmembership.Delete(ctx, &organizationmembership.DeleteParams{
+		OrganizationID: orgID,
+		UserID:         userID,
+	})
+	var apiErr *clerk.APIErrorResponse
+	if errors.As(err, &apiErr) && apiErr.HTTPStatusCode == http.StatusNotFound {
+		return nil
+	}
+	return err
+}
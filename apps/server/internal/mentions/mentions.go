@@ -0,0 +1,70 @@
+// Package mentions finds @username mentions in comment bodies and resolves
+// them to org members.
+package mentions
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/organizationmembership"
+)
+
+// MaxPerComment caps how many distinct users one comment can mention.
+const MaxPerComment = 20
+
+// A mention starts the body or follows a character that can't be part of an
+// email address, so "me@example.com" isn't one.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w.@])@([A-Za-z0-9_][A-Za-z0-9_.-]{0,63})`)
+
+// Parse returns the distinct usernames mentioned in body, lowercased, in the
+// order they first appear.
+func Parse(body string) []string {
+	usernames := []string{}
+	seen := map[string]bool{}
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		name := strings.ToLower(strings.TrimRight(m[1], ".-"))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		usernames = append(usernames, name)
+		if len(usernames) == MaxPerComment {
+			break
+		}
+	}
+	return usernames
+}
+
+// Directory resolves usernames to the ids of the users in an org that have
+// them; usernames without a member are left out.
+type Directory interface {
+	Members(ctx context.Context, orgID string, usernames []string) ([]string, error)
+}
+
+// ClerkDirectory looks members up in Clerk.
+type ClerkDirectory struct{}
+
+func (ClerkDirectory) Members(ctx context.Context, orgID string, usernames []string) ([]string, error) {
+	if len(usernames) == 0 {
+		return []string{}, nil
+	}
+
+	list, err := organizationmembership.List(ctx, &organizationmembership.ListParams{
+		OrganizationID: orgID,
+		Usernames:      usernames,
+		ListParams:     clerk.ListParams{Limit: clerk.Int64(MaxPerComment)},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []string{}
+	for _, m := range list.OrganizationMemberships {
+		if m.PublicUserData != nil {
+			ids = append(ids, m.PublicUserData.UserID)
+		}
+	}
+	return ids, nil
+}
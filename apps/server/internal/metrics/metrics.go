@@ -0,0 +1,138 @@
+// Package metrics holds the Prometheus collectors served on /metrics.
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/database"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+var (
+	HTTPRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yata_http_requests_total",
+		Help: "HTTP requests by method, route pattern and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "yata_http_request_duration_seconds",
+		Help:    "HTTP request latency by method and route pattern.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	WebhookFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yata_webhook_failures_total",
+		Help: "Incoming webhook deliveries that were rejected or failed to apply.",
+	}, []string{"source", "reason"})
+
+	AuditEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yata_audit_events_delivered_total",
+		Help: "Audit events delivered, by sink.",
+	}, []string{"sink"})
+
+	AuditFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "yata_audit_delivery_failures_total",
+		Help: "Audit batches a sink failed to take, by sink.",
+	}, []string{"sink"})
+
+	AuditDeliveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "yata_audit_delivery_duration_seconds",
+		Help:    "Time to deliver an audit batch, by sink.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"sink"})
+
+	AuditLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "yata_audit_lag_seconds",
+		Help: "Age of the oldest audit event a sink has yet to take, as of its last batch.",
+	}, []string{"sink"})
+)
+
+// NewRegistry registers the HTTP, webhook and audit metrics along with the Go
+// runtime's. Pool stats and queue depth are added by whoever has them.
+func NewRegistry() *prometheus.Registry {
+	r := prometheus.NewRegistry()
+	r.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		HTTPRequests,
+		HTTPDuration,
+		WebhookFailures,
+		AuditEvents,
+		AuditFailures,
+		AuditDeliveryDuration,
+		AuditLag,
+	)
+	return r
+}
+
+var (
+	poolAcquired = prometheus.NewDesc("yata_db_pool_acquired_conns", "Connections currently checked out of the pool.", poolLabels, nil)
+	poolIdle     = prometheus.NewDesc("yata_db_pool_idle_conns", "Idle connections in the pool.", poolLabels, nil)
+	poolTotal    = prometheus.NewDesc("yata_db_pool_total_conns", "Connections open in the pool.", poolLabels, nil)
+	poolMax      = prometheus.NewDesc("yata_db_pool_max_conns", "Current maximum size of the pool.", poolLabels, nil)
+	poolWaits    = prometheus.NewDesc("yata_db_pool_acquire_waits_total", "Acquires that had to wait for a connection.", poolLabels, nil)
+	poolWaitTime = prometheus.NewDesc("yata_db_pool_acquire_wait_seconds_total", "Time spent waiting for connections.", poolLabels, nil)
+)
+
+// poolLabels tells the primary's pool from each replica's.
+var poolLabels = []string{"target"}
+
+// PoolCollector reads pgx pool stats at scrape time, per database target.
+type PoolCollector struct {
+	Targets []database.Target
+}
+
+func (c PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{poolAcquired, poolIdle, poolTotal, poolMax, poolWaits, poolWaitTime} {
+		ch <- d
+	}
+}
+
+func (c PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, t := range c.Targets {
+		s := t.Pool.Stat()
+		ch <- prometheus.MustNewConstMetric(poolAcquired, prometheus.GaugeValue, float64(s.AcquiredConns()), t.Name)
+		ch <- prometheus.MustNewConstMetric(poolIdle, prometheus.GaugeValue, float64(s.IdleConns()), t.Name)
+		ch <- prometheus.MustNewConstMetric(poolTotal, prometheus.GaugeValue, float64(s.TotalConns()), t.Name)
+		ch <- prometheus.MustNewConstMetric(poolMax, prometheus.GaugeValue, float64(s.MaxConns()), t.Name)
+		ch <- prometheus.MustNewConstMetric(poolWaits, prometheus.CounterValue, float64(s.EmptyAcquireCount()), t.Name)
+		ch <- prometheus.MustNewConstMetric(poolWaitTime, prometheus.CounterValue, s.AcquireDuration().Seconds(), t.Name)
+	}
+}
+
+var queueDepth = prometheus.NewDesc("yata_job_queue_depth", "Jobs in the queue by status.", []string{"status"}, nil)
+
+// queueDepthTimeout keeps a slow count from stalling the scrape.
+const queueDepthTimeout = 2 * time.Second
+
+// QueueDepther is the part of the job queue QueueCollector reads.
+type QueueDepther interface {
+	Depth(ctx context.Context) (map[string]int, error)
+}
+
+// QueueCollector counts the job queue at scrape time.
+type QueueCollector struct {
+	Queue QueueDepther
+}
+
+func (c QueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- queueDepth
+}
+
+func (c QueueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), queueDepthTimeout)
+	defer cancel()
+
+	depth, err := c.Queue.Depth(ctx)
+	if err != nil {
+		slog.Error("Failed to read job queue depth", "error", err)
+		ch <- prometheus.NewInvalidMetric(queueDepth, err)
+		return
+	}
+	for _, status := range []string{"pending", "running", "failed"} {
+		ch <- prometheus.MustNewConstMetric(queueDepth, prometheus.GaugeValue, float64(depth[status]), status)
+	}
+}
@@ -0,0 +1,57 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net"
+	"strings"
+	"yata/apps/server/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdminIP only lets through requests whose remote address is in one of
+// the allowed IPs or CIDR ranges. An empty list blocks everything.
+func RequireAdminIP(allowed []string) gin.HandlerFunc {
+	nets := parseNets(allowed)
+
+	return func(c *gin.Context) {
+		// RemoteIP ignores X-Forwarded-For, which clients can set freely.
+		if ipAllowed(nets, net.ParseIP(c.RemoteIP())) {
+			c.Next()
+			return
+		}
+
+		c.Abort()
+		apierror.Respond(c, apierror.Forbidden("Forbidden"))
+	}
+}
+
+// parseNets turns IPs and CIDR ranges into networks, skipping bad entries.
+func parseNets(allowed []string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, a := range allowed {
+		if !strings.Contains(a, "/") {
+			if strings.Contains(a, ":") {
+				a += "/128"
+			} else {
+				a += "/32"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(a)
+		if err != nil {
+			slog.Warn("Ignoring invalid allowed IP", "ip", a, "error", err)
+			continue
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets
+}
+
+func ipAllowed(nets []*net.IPNet, ip net.IP) bool {
+	for _, n := range nets {
+		if ip != nil && n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,102 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+)
+
+// APIVersionHeader names the version a response was served by. Requests
+// to unversioned paths may set it to pick a version instead of the
+// current one.
+const APIVersionHeader = "API-Version"
+
+// APIVersions routes /api/<version>/... as is and serves the unversioned
+// /api/... paths as an alias of a version, so clients calling the old
+// paths keep working through the transition. Alias responses carry
+// Deprecation, Link and (when set) Sunset headers unless the client chose
+// a version with API-Version.
+type APIVersions struct {
+	// Supported lists the versions mounted under /api, e.g. "v1".
+	Supported []string
+	// Current is what unversioned requests get.
+	Current string
+	// Sunset is when the unversioned alias goes away; zero leaves the
+	// header out.
+	Sunset time.Time
+	// Unversioned lists paths under /api that describe the API as a whole,
+	// like the OpenAPI document, and are served as is.
+	Unversioned []string
+}
+
+const apiPrefix = "/api/"
+
+func (v APIVersions) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rest, ok := strings.CutPrefix(r.URL.Path, apiPrefix)
+		if !ok || slices.Contains(v.Unversioned, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		first, _, _ := strings.Cut(rest, "/")
+		if slices.Contains(v.Supported, first) {
+			w.Header().Set(APIVersionHeader, first)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		version := v.Current
+		if requested := r.Header.Get(APIVersionHeader); requested != "" {
+			version = strings.ToLower(strings.TrimSpace(requested))
+			if !strings.HasPrefix(version, "v") {
+				version = "v" + version
+			}
+			if !slices.Contains(v.Supported, version) {
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(apierror.New(http.StatusBadRequest, "Unsupported API version").WithDetails(map[string]any{"supported": v.Supported}).Body())
+				return
+			}
+		} else {
+			successor := apiPrefix + version + "/" + rest
+			w.Header().Set("Deprecation", "true")
+			w.Header().Add("Link", "<"+successor+`>; rel="successor-version"`)
+			if !v.Sunset.IsZero() {
+				w.Header().Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+			}
+		}
+		w.Header().Set(APIVersionHeader, version)
+
+		// Like http.StripPrefix, route a shallow copy with the versioned path.
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.URL = new(url.URL)
+		*r2.URL = *r.URL
+		r2.URL.Path = apiPrefix + version + "/" + rest
+		if r.URL.RawPath != "" {
+			r2.URL.RawPath = apiPrefix + version + "/" + strings.TrimPrefix(r.URL.RawPath, apiPrefix)
+		}
+		next.ServeHTTP(w, r2)
+	})
+}
+
+// VersionRouteKeys copies "METHOD /api/..." keys, such as ROUTE_TIMEOUTS
+// entries written before versioning, to the same route under version.
+func VersionRouteKeys[V any](m map[string]V, version string) {
+	for key, value := range m {
+		method, path, ok := strings.Cut(key, " ")
+		rest, isAPI := strings.CutPrefix(path, apiPrefix)
+		if !ok || !isAPI || strings.HasPrefix(rest, version+"/") {
+			continue
+		}
+		versioned := method + " " + apiPrefix + version + "/" + rest
+		if _, set := m[versioned]; !set {
+			m[versioned] = value
+		}
+	}
+}
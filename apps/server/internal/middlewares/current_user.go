@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"errors"
+	"log/slog"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/store"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// CurrentUser sets the auth.User of requests let through by Authenticate
+// or ServiceAuth, with the email from the local mirror of Clerk's users. It
+// leaves a user already set, as by tests, alone, and passes requests with
+// neither kind of claims on for the handler to turn away.
+func CurrentUser(users store.UserStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if _, ok := auth.FromContext(ctx); ok {
+			c.Next()
+			return
+		}
+
+		var u auth.User
+		if service, ok := ServiceClaimsFromContext(ctx); ok {
+			u = auth.User{ID: service.Principal(), OrgID: service.OrgID}
+		} else if claims, ok := clerk.SessionClaimsFromContext(ctx); ok {
+			u = SessionUser(claims)
+			user, err := users.GetUser(ctx, u.ID)
+			if err != nil && !errors.Is(err, store.ErrNotFound) {
+				slog.ErrorContext(ctx, "Failed to get user", "error", err)
+				c.Abort()
+				apierror.Respond(c, apierror.Internal("Failed to authenticate", err))
+				return
+			}
+			if user != nil && user.Email != nil {
+				u.Email = *user.Email
+			}
+		} else {
+			c.Next()
+			return
+		}
+
+		c.Request = c.Request.WithContext(auth.WithUser(ctx, u))
+		c.Next()
+	}
+}
+
+// SessionUser is the user a Clerk session is for, without the email.
+func SessionUser(claims *clerk.SessionClaims) auth.User {
+	return auth.User{
+		ID:    claims.Subject,
+		OrgID: claims.ActiveOrganizationID,
+		Role:  claims.ActiveOrganizationRole,
+		Guest: claims.ActiveOrganizationID != "" && claims.ActiveOrganizationRole == OrgGuestRole,
+	}
+}
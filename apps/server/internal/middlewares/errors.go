@@ -0,0 +1,88 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"yata/apps/server/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Errors responds with the last error a handler passed to c.Error, if it
+// didn't write a response itself. Errors that aren't an *apierror.Error are
+// mapped with apierror.From. 5xx causes are logged, and the code is kept
+// for RecordRecentErrors, so register it after that. Retryable errors are
+// sent with a Retry-After from retry, here and wherever apierror.Respond is
+// used after it.
+//
+// Handlers respond with apierror.Respond. As a fallback, bodies written as
+// gin.H{"error": message, ...} are sent as an apierror too, with the code
+// their status maps to and any other fields as details, so every error has
+// the same envelope.
+func Errors(retry apierror.RetryPolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apierror.RetryPolicyKey, retry)
+		c.Writer = &envelopeWriter{ResponseWriter: c.Writer, c: c}
+		c.Next()
+
+		if c.Writer.Written() || len(c.Errors) == 0 {
+			return
+		}
+		err := apierror.From(c.Errors.Last().Err, "Something went wrong")
+		if err.Status >= 500 {
+			slog.ErrorContext(c.Request.Context(), err.Message, "error", err.Cause)
+		}
+		apierror.Respond(c, err)
+	}
+}
+
+// envelopeWriter rewrites JSON error bodies with an "error" message, which
+// gin writes in a single call, as apierror bodies.
+type envelopeWriter struct {
+	gin.ResponseWriter
+	c       *gin.Context
+	started bool
+}
+
+func (w *envelopeWriter) Write(b []byte) (int, error) {
+	if w.started || w.Status() < 400 || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") || !bytes.HasPrefix(b, []byte("{")) {
+		w.started = true
+		return w.ResponseWriter.Write(b)
+	}
+	w.started = true
+
+	var body map[string]json.RawMessage
+	var msg string
+	if json.Unmarshal(b, &body) != nil || json.Unmarshal(body["error"], &msg) != nil {
+		return w.ResponseWriter.Write(b)
+	}
+	err := apierror.New(w.Status(), msg)
+	if code, ok := body["code"]; ok {
+		json.Unmarshal(code, &err.Code)
+	}
+	delete(body, "error")
+	delete(body, "code")
+	if details, ok := body["details"]; ok {
+		err.Details = details
+	} else if len(body) > 0 {
+		err.Details = body
+	}
+
+	out, marshalErr := json.Marshal(err.Body())
+	if marshalErr != nil {
+		return w.ResponseWriter.Write(b)
+	}
+	if _, set := w.c.Get(ErrorCodeKey); !set {
+		w.c.Set(ErrorCodeKey, err.Code)
+	}
+	if _, err := w.ResponseWriter.Write(out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *envelopeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
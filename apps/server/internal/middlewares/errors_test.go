@@ -0,0 +1,99 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		handler gin.HandlerFunc
+		status  int
+		want    string
+	}{
+		{
+			"apierror",
+			func(c *gin.Context) {
+				apierror.Abort(c, apierror.Validation("Invalid request body").WithDetails([]string{"title"}))
+			},
+			http.StatusBadRequest,
+			`{"code":"VALIDATION_FAILED","details":["title"],"message":"Invalid request body"}`,
+		},
+		{
+			"store error",
+			func(c *gin.Context) { c.Error(store.ErrNotFound) },
+			http.StatusNotFound,
+			`{"code":"NOT_FOUND","message":"Not found"}`,
+		},
+		{
+			"unmapped error",
+			func(c *gin.Context) { c.Error(errors.New("connection reset")) },
+			http.StatusInternalServerError,
+			`{"code":"INTERNAL","message":"Something went wrong"}`,
+		},
+		{
+			"gin.H error",
+			func(c *gin.Context) { c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"}) },
+			http.StatusNotFound,
+			`{"code":"NOT_FOUND","message":"Task not found"}`,
+		},
+		{
+			"gin.H error with a code and fields",
+			func(c *gin.Context) {
+				c.JSON(http.StatusConflict, gin.H{"error": "Likely duplicate of an open task", "code": "LIKELY_DUPLICATE", "duplicates": []string{"t1"}})
+			},
+			http.StatusConflict,
+			`{"code":"LIKELY_DUPLICATE","details":{"duplicates":["t1"]},"message":"Likely duplicate of an open task"}`,
+		},
+		{
+			"status only",
+			func(c *gin.Context) { c.JSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"}) },
+			http.StatusTooManyRequests,
+			`{"code":"RATE_LIMITED","message":"Too many requests"}`,
+		},
+		{
+			"success left alone",
+			func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{"error": "not an error"}) },
+			http.StatusOK,
+			`{"error":"not an error"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var code any
+			r := gin.New()
+			r.Use(func(c *gin.Context) {
+				c.Next()
+				code, _ = c.Get(ErrorCodeKey)
+			}, Errors(apierror.RetryPolicy{}))
+			r.GET("/", tt.handler)
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if got := w.Body.String(); got != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+			if tt.status >= 400 {
+				var body struct{ Code string }
+				json.Unmarshal(w.Body.Bytes(), &body)
+				if code != body.Code {
+					t.Errorf("recorded code = %v, want %s", code, body.Code)
+				}
+			}
+		})
+	}
+}
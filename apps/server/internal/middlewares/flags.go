@@ -0,0 +1,30 @@
+package middlewares
+
+import (
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/flags"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FlagEnabled reports whether the flag is on for the signed-in user in their
+// active org, for handlers that only change behavior behind a flag.
+func FlagEnabled(c *gin.Context, f *flags.Flags, key string) bool {
+	u, _ := auth.FromContext(c.Request.Context())
+	return f.Enabled(c.Request.Context(), key, u.ID, u.OrgID)
+}
+
+// RequireFlag hides a route unless the flag is on, answering as if it didn't
+// exist. It has to run after authentication to see per-user and per-org
+// settings.
+func RequireFlag(f *flags.Flags, key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !FlagEnabled(c, f, key) {
+			c.Abort()
+			apierror.Respond(c, apierror.NotFound("Not found"))
+			return
+		}
+		c.Next()
+	}
+}
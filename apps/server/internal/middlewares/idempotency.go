@@ -0,0 +1,135 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader      = "Idempotency-Key"
+	IdempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	maxIdempotentRequestBytes = 1 << 20
+)
+
+// Idempotency replays the stored response when a POST is retried with the
+// same Idempotency-Key, instead of running the handler again. Responses are
+// kept for ttl. Server errors aren't stored, so those requests can be
+// retried for real. It must run after the auth middleware.
+func Idempotency(keys store.IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.Abort()
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid Idempotency-Key"))
+			return
+		}
+
+		u, ok := auth.FromContext(c.Request.Context())
+		if !ok {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentRequestBytes+1))
+		if err != nil {
+			c.Abort()
+			apierror.Respond(c, apierror.New(http.StatusBadRequest, "Invalid request body"))
+			return
+		}
+		if len(body) > maxIdempotentRequestBytes {
+			c.Abort()
+			apierror.Respond(c, apierror.New(http.StatusRequestEntityTooLarge, "Request body too large"))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.New()
+		io.WriteString(sum, c.Request.Method+" "+c.Request.URL.Path+"\n")
+		sum.Write(body)
+		fingerprint := hex.EncodeToString(sum.Sum(nil))
+
+		ctx := c.Request.Context()
+		scope := u.Scope()
+		stored, reserved, err := keys.Reserve(ctx, scope, key, fingerprint, ttl)
+		if err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to reserve idempotency key", "error", err)
+			c.Abort()
+			apierror.Respond(c, apierror.Internal("Failed to process request", err))
+			return
+		}
+
+		if !reserved {
+			switch {
+			case stored.Fingerprint != fingerprint:
+				c.Abort()
+				apierror.Respond(c, apierror.New(http.StatusUnprocessableEntity, "Idempotency-Key was used with a different request"))
+			case stored.StatusCode == 0:
+				c.Abort()
+				apierror.Respond(c, apierror.Conflict("A request with this Idempotency-Key is in progress"))
+			default:
+				c.Header(IdempotentReplayedHeader, "true")
+				c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+				c.Abort()
+			}
+			return
+		}
+
+		release := func() {
+			if err := keys.Release(ctx, scope, key); err != nil {
+				slog.ErrorContext(ctx, "Failed to release idempotency key", "error", err)
+			}
+		}
+		// A panicking handler never gets to store a response; free the key
+		// so a retry isn't stuck on "in progress" until ttl runs out.
+		defer func() {
+			if p := recover(); p != nil {
+				release()
+				panic(p)
+			}
+		}()
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		status := c.Writer.Status()
+		if status >= 500 {
+			release()
+			return
+		}
+		if err := keys.Complete(ctx, scope, key, status, c.Writer.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			slog.ErrorContext(c.Request.Context(), "Failed to store idempotent response", "error", err)
+		}
+	}
+}
+
+// bodyRecorder copies everything written to the response.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,72 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	calls := 0
+	r := gin.New()
+	r.Use(gin.CustomRecovery(func(c *gin.Context, _ any) {
+		c.AbortWithStatus(http.StatusInternalServerError)
+	}))
+	r.Use(func(c *gin.Context) {
+		u := auth.User{ID: "user_1", OrgID: c.GetHeader("X-Test-Org")}
+		c.Request = c.Request.WithContext(auth.WithUser(c.Request.Context(), u))
+	})
+	r.Use(Idempotency(store.NewMemory().Idempotency(), time.Hour))
+	r.POST("/tasks", func(c *gin.Context) {
+		calls++
+		if c.GetHeader("X-Test-Panic") != "" {
+			panic("handler bug")
+		}
+		c.JSON(http.StatusCreated, gin.H{"call": calls})
+	})
+
+	post := func(org, key string, panics bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/tasks", strings.NewReader(`{"title":"Pay rent"}`))
+		req.Header.Set(IdempotencyKeyHeader, key)
+		req.Header.Set("X-Test-Org", org)
+		if panics {
+			req.Header.Set("X-Test-Panic", "1")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	first := post("org_a", "k1", false)
+	replay := post("org_a", "k1", false)
+	if replay.Header().Get(IdempotentReplayedHeader) != "true" || replay.Body.String() != first.Body.String() {
+		t.Errorf("retry in the same org was not replayed: %d %s", replay.Code, replay.Body)
+	}
+
+	other := post("org_b", "k1", false)
+	if other.Header().Get(IdempotentReplayedHeader) != "" || other.Body.String() == first.Body.String() {
+		t.Errorf("same key from another org replayed org_a's response: %s", other.Body)
+	}
+	personal := post("", "k1", false)
+	if personal.Header().Get(IdempotentReplayedHeader) != "" {
+		t.Errorf("same key outside an org replayed an org response: %s", personal.Body)
+	}
+	if calls != 3 {
+		t.Errorf("handler ran %d times, want 3", calls)
+	}
+
+	if w := post("org_a", "k2", true); w.Code != http.StatusInternalServerError {
+		t.Fatalf("panicking request = %d, want 500", w.Code)
+	}
+	if w := post("org_a", "k2", false); w.Code != http.StatusCreated {
+		t.Errorf("retry after a panic = %d %s, want the key released and the request run", w.Code, w.Body)
+	}
+}
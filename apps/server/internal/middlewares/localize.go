@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"yata/apps/server/internal/i18n"
+	"yata/apps/server/internal/store"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// Localize translates the message of JSON error bodies into the
+// caller's language: the locale in their settings if they're signed in and
+// picked one, else the best match for Accept-Language. The locale is only
+// worked out once there's an error to translate, by which point auth has
+// run.
+func Localize(settings store.UserSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Writer = &localizeWriter{ResponseWriter: c.Writer, locale: func() string { return requestLocale(c, settings) }}
+		c.Next()
+	}
+}
+
+func requestLocale(c *gin.Context, settings store.UserSettingsStore) string {
+	ctx := c.Request.Context()
+	if claims, ok := clerk.SessionClaimsFromContext(ctx); ok {
+		s, err := settings.Get(ctx, claims.Subject)
+		if err != nil {
+			slog.WarnContext(ctx, "Failed to get settings for locale", "error", err)
+		} else if l := s.PreferredLocale(); i18n.IsSupported(l) {
+			return l
+		}
+	}
+	if l := i18n.Match(c.GetHeader("Accept-Language")); l != "" {
+		return l
+	}
+	return i18n.Default
+}
+
+// localizeWriter rewrites JSON error bodies, which gin writes in a single
+// call, with their message translated.
+type localizeWriter struct {
+	gin.ResponseWriter
+	locale  func() string
+	started bool
+}
+
+func (w *localizeWriter) Write(b []byte) (int, error) {
+	if w.started || w.Status() < 400 || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") || !bytes.HasPrefix(b, []byte("{")) {
+		w.started = true
+		return w.ResponseWriter.Write(b)
+	}
+	w.started = true
+
+	var body map[string]json.RawMessage
+	var msg string
+	if json.Unmarshal(b, &body) != nil || json.Unmarshal(body["message"], &msg) != nil || msg == "" {
+		return w.ResponseWriter.Write(b)
+	}
+	locale := w.locale()
+	translated := i18n.Message(locale, msg)
+	if translated == msg {
+		return w.ResponseWriter.Write(b)
+	}
+
+	body["message"], _ = json.Marshal(translated)
+	out, err := json.Marshal(body)
+	if err != nil {
+		return w.ResponseWriter.Write(b)
+	}
+	w.Header().Set("Content-Language", locale)
+	if _, err := w.ResponseWriter.Write(out); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *localizeWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
@@ -0,0 +1,45 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/maintenance"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Maintenance answers 503 with Retry-After while the API is in maintenance:
+// every request in full mode, and writes in read-only mode. Paths starting
+// with one of exempt, like health checks and the admin routes that turn
+// maintenance off again, are always let through.
+func Maintenance(s *maintenance.Switch, retryAfter time.Duration, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, prefix := range exempt {
+			if strings.HasPrefix(c.Request.URL.Path, prefix) {
+				c.Next()
+				return
+			}
+		}
+
+		switch s.Mode(c.Request.Context()) {
+		case maintenance.Full:
+			c.Abort()
+			apierror.Respond(c, &apierror.Error{Status: http.StatusServiceUnavailable, Code: "MAINTENANCE", Message: "Down for maintenance", RetryAfter: retryAfter})
+			return
+		case maintenance.ReadOnly:
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				if readRoutes[c.FullPath()] {
+					break
+				}
+				c.Abort()
+				apierror.Respond(c, &apierror.Error{Status: http.StatusServiceUnavailable, Code: "READ_ONLY", Message: "Read-only during maintenance", RetryAfter: retryAfter})
+				return
+			}
+		}
+		c.Next()
+	}
+}
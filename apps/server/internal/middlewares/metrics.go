@@ -0,0 +1,56 @@
+package middlewares
+
+import (
+	"crypto/subtle"
+	"net"
+	"strconv"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Metrics counts requests and their latency by route pattern, so ids in
+// paths don't each become a series.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.HTTPRequests.WithLabelValues(c.Request.Method, route, strconv.Itoa(c.Writer.Status())).Inc()
+		metrics.HTTPDuration.WithLabelValues(c.Request.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
+
+// RequireMetricsAccess lets scrapers in from the allowed IPs or CIDR ranges,
+// or with the basic auth credentials when a username is set.
+func RequireMetricsAccess(allowed []string, username, password string) gin.HandlerFunc {
+	nets := parseNets(allowed)
+
+	return func(c *gin.Context) {
+		if ipAllowed(nets, net.ParseIP(c.RemoteIP())) {
+			c.Next()
+			return
+		}
+		if user, pass, ok := c.Request.BasicAuth(); ok && username != "" &&
+			subtle.ConstantTimeCompare([]byte(user), []byte(username)) == 1 &&
+			subtle.ConstantTimeCompare([]byte(pass), []byte(password)) == 1 {
+			c.Next()
+			return
+		}
+
+		if username != "" {
+			c.Header("WWW-Authenticate", `Basic realm="metrics"`)
+			c.Abort()
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+		c.Abort()
+		apierror.Respond(c, apierror.Forbidden("Forbidden"))
+	}
+}
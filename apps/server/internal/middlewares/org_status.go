@@ -0,0 +1,58 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CodeOrgSuspended is what requests a suspended org can't make fail with.
+const CodeOrgSuspended = "ORG_SUSPENDED"
+
+// RequireActiveOrg answers 403 ORG_SUSPENDED to writes in a suspended org,
+// and to reads too when blockReads is set. Routes in exempt, by their full
+// path, like the exports customers take their data out with, are always
+// let through, as are requests outside an org, so it can go on a whole
+// group after RequireOrg or CurrentUser. Requests are let through if the
+// status can't be read.
+func RequireActiveOrg(users store.UserStore, blockReads bool, exempt ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		u, ok := auth.FromContext(ctx)
+		if !ok || u.OrgID == "" || slices.Contains(exempt, c.FullPath()) {
+			c.Next()
+			return
+		}
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			if !blockReads {
+				c.Next()
+				return
+			}
+		default:
+			if readRoutes[c.FullPath()] && !blockReads {
+				c.Next()
+				return
+			}
+		}
+
+		status, err := users.GetOrganizationStatus(ctx, u.OrgID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get org status", "error", err)
+			c.Next()
+			return
+		}
+		if status == models.OrgStatusSuspended {
+			c.Abort()
+			apierror.Respond(c, &apierror.Error{Status: http.StatusForbidden, Code: CodeOrgSuspended, Message: "Your organization is suspended"})
+			return
+		}
+		c.Next()
+	}
+}
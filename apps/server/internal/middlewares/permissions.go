@@ -0,0 +1,82 @@
+package middlewares
+
+import (
+	"slices"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OrgMemberRole is Clerk's default role for people who join an org.
+const OrgMemberRole = "org:member"
+
+// OrgGuestRole is the custom Clerk role that guest invitations grant. It has
+// to exist in the Clerk instance for invitations to be sent.
+const OrgGuestRole = "org:guest"
+
+// Permissions name actions that not every org role may take.
+const (
+	PermDeleteProject       = "projects:delete"
+	PermInviteGuests        = "guests:invite"
+	PermManageProjectShares = "project_shares:manage"
+	PermManageOrgSettings   = "org_settings:manage"
+	PermReadOrgActivity     = "org_activity:read"
+	PermManageMembers       = "members:manage"
+	PermPinComments         = "comments:pin"
+)
+
+// rolePermissions is the permission matrix. Roles missing from it,
+// including custom Clerk roles nobody added here, have no permissions.
+var rolePermissions = map[string][]string{
+	OrgAdminRole:  {PermDeleteProject, PermInviteGuests, PermManageProjectShares, PermManageOrgSettings, PermReadOrgActivity, PermManageMembers, PermPinComments},
+	OrgMemberRole: {},
+	OrgGuestRole:  {},
+}
+
+// HasPermission reports whether role grants perm.
+func HasPermission(role, perm string) bool {
+	return slices.Contains(rolePermissions[role], perm)
+}
+
+// RequireRole lets the request through only when the active org role is one
+// of roles.
+func RequireRole(roles ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, ok := auth.FromContext(c.Request.Context())
+
+		if !ok || u.OrgID == "" || !slices.Contains(roles, u.Role) {
+			apierror.Abort(c, apierror.Forbidden("Insufficient role"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// RejectGuests keeps guests out of routes that deal with the org as a whole,
+// rather than the tasks and projects they were invited to.
+func RejectGuests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, ok := auth.FromContext(c.Request.Context())
+
+		if ok && u.Guest {
+			apierror.Abort(c, apierror.Forbidden("Not available to guests"))
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePermission lets the request through only when the active org role
+// grants perm in the permission matrix.
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		u, ok := auth.FromContext(c.Request.Context())
+
+		if !ok || u.OrgID == "" || !HasPermission(u.Role, perm) {
+			apierror.Abort(c, apierror.Forbidden("Permission denied").WithDetails(gin.H{"permission": perm}))
+			return
+		}
+		c.Next()
+	}
+}
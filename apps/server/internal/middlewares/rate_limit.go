@@ -0,0 +1,85 @@
+package middlewares
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/ratelimit"
+	"yata/apps/server/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimitKey is where the rate limiters leave the ratelimit.Result that
+// counted the request, for handlers that report it.
+const RateLimitKey = "rateLimit"
+
+// RateLimit holds each caller to rule within group: signed-in users by
+// their user id, everyone else by IP. Service routes count per service.
+// It must run after CurrentUser to see who's calling.
+// Requests are let through if the limiter fails.
+func RateLimit(limiter ratelimit.Limiter, group string, rule ratelimit.Rule) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := group + ":ip:" + c.ClientIP()
+		if u, ok := auth.FromContext(c.Request.Context()); ok {
+			key = group + ":user:" + u.ID
+		}
+		allowRequest(c, limiter, key, rule)
+	}
+}
+
+// OrgRateLimit is RateLimit with the org's rate limit setting in place of
+// rule for users signed in to an org that has one. Users are counted per
+// org and per rule, so changing an org's limit starts its users on a fresh
+// window at the new limit. Settings are read on every request, so settings
+// should be cached.
+func OrgRateLimit(limiter ratelimit.Limiter, group string, rule ratelimit.Rule, settings store.OrgSettingsStore) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		u, ok := auth.FromContext(ctx)
+		if !ok {
+			allowRequest(c, limiter, group+":ip:"+c.ClientIP(), rule)
+			return
+		}
+		if u.OrgID == "" {
+			allowRequest(c, limiter, group+":user:"+u.ID, rule)
+			return
+		}
+
+		effective := rule
+		s, err := settings.Get(ctx, u.OrgID)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to get org rate limit", "error", err)
+		} else if s.RateLimit != nil {
+			if effective, err = ratelimit.ParseRule(*s.RateLimit); err != nil {
+				slog.ErrorContext(ctx, "Invalid org rate limit", "org_id", u.OrgID, "error", err)
+				effective = rule
+			}
+		}
+		allowRequest(c, limiter, group+":org:"+u.OrgID+":user:"+u.ID+":"+effective.String(), effective)
+	}
+}
+
+func allowRequest(c *gin.Context, limiter ratelimit.Limiter, key string, rule ratelimit.Rule) {
+	result, err := limiter.Allow(c.Request.Context(), key, rule)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to check rate limit", "error", err)
+		c.Next()
+		return
+	}
+
+	c.Set(RateLimitKey, result)
+	c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(result.ResetAt.Unix(), 10))
+
+	if !result.Allowed {
+		c.Abort()
+		apierror.Respond(c, apierror.New(http.StatusTooManyRequests, "Too many requests").WithRetryAfter(time.Until(result.ResetAt)))
+		return
+	}
+	c.Next()
+}
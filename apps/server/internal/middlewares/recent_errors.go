@@ -0,0 +1,93 @@
+package middlewares
+
+import (
+	"sync"
+	"time"
+	"yata/apps/server/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorCodeKey is set on the gin context by apierror.Respond, or by hand,
+// so the code shows up in recorded errors.
+const ErrorCodeKey = apierror.CodeKey
+
+type ErrorRecord struct {
+	Method    string    `json:"method"`
+	Route     string    `json:"route"`
+	Status    int       `json:"status"`
+	Code      string    `json:"code,omitempty"`
+	RequestID string    `json:"requestId,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RecentErrors is a fixed-size ring buffer of the most recent 5xx responses.
+type RecentErrors struct {
+	mu      sync.Mutex
+	records []ErrorRecord
+	next    int
+	full    bool
+}
+
+func NewRecentErrors(size int) *RecentErrors {
+	if size < 1 {
+		size = 1
+	}
+	return &RecentErrors{records: make([]ErrorRecord, size)}
+}
+
+func (r *RecentErrors) Add(rec ErrorRecord) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[r.next] = rec
+	r.next = (r.next + 1) % len(r.records)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// List returns the recorded errors, newest first.
+func (r *RecentErrors) List() []ErrorRecord {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	count := r.next
+	if r.full {
+		count = len(r.records)
+	}
+
+	out := make([]ErrorRecord, 0, count)
+	for i := 1; i <= count; i++ {
+		idx := (r.next - i + len(r.records)) % len(r.records)
+		out = append(out, r.records[idx])
+	}
+	return out
+}
+
+func RecordRecentErrors(buf *RecentErrors) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < 500 {
+			return
+		}
+
+		// Only the route pattern is kept, so ids and query strings in the
+		// raw path never end up in the buffer.
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		buf.Add(ErrorRecord{
+			Method:    c.Request.Method,
+			Route:     route,
+			Status:    status,
+			Code:      c.GetString(ErrorCodeKey),
+			RequestID: c.GetString(RequestIDKey),
+			Timestamp: time.Now().UTC(),
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRecordRecentErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	buf := NewRecentErrors(3)
+	r := gin.New()
+	r.Use(RequestID(RequestIDFormatUUID), RecordRecentErrors(buf))
+	r.GET("/tasks/:id", func(c *gin.Context) {
+		status, _ := strconv.Atoi(c.Query("status"))
+		c.Set(ErrorCodeKey, "boom")
+		c.Status(status)
+	})
+
+	get := func(path string) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	get("/tasks/secret-id?status=200")
+	get("/tasks/secret-id?status=404")
+	if got := buf.List(); len(got) != 0 {
+		t.Fatalf("recorded %d non-5xx responses", len(got))
+	}
+
+	get("/tasks/secret-id?status=500")
+	got := buf.List()
+	if len(got) != 1 {
+		t.Fatalf("recorded %d errors, want 1", len(got))
+	}
+	rec := got[0]
+	if rec.Route != "/tasks/:id" || rec.Method != http.MethodGet || rec.Status != 500 || rec.Code != "boom" {
+		t.Errorf("record = %+v", rec)
+	}
+	if rec.RequestID == "" || rec.Timestamp.IsZero() {
+		t.Errorf("record missing request id or timestamp: %+v", rec)
+	}
+
+	for _, status := range []string{"501", "502", "503", "504"} {
+		get("/tasks/x?status=" + status)
+	}
+	got = buf.List()
+	if len(got) != 3 {
+		t.Fatalf("buffer holds %d errors, want it capped at 3", len(got))
+	}
+	for i, want := range []int{504, 503, 502} {
+		if got[i].Status != want {
+			t.Errorf("List()[%d].Status = %d, want %d (newest first)", i, got[i].Status, want)
+		}
+	}
+}
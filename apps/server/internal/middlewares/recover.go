@@ -0,0 +1,81 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"syscall"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/errorreport"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recover replaces gin.Recovery: a panic is logged with its stack and
+// answered with a 500, and both panics and 5xx responses go to reporter
+// with the request ID, route and caller. It goes right after RequestLogger,
+// so the panic's 500 is still logged as a request.
+func Recover(reporter errorreport.Reporter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			if r == http.ErrAbortHandler {
+				// net/http's way of dropping the connection; it logs nothing.
+				panic(r)
+			}
+
+			ctx := c.Request.Context()
+			if err, ok := r.(error); ok && (errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)) {
+				// The client went away mid-response; there's no one to answer.
+				slog.WarnContext(ctx, "Client disconnected", "error", err)
+				c.Abort()
+				return
+			}
+
+			stack := errorreport.Callers(1)
+			slog.ErrorContext(ctx, "Panic serving request", "panic", r, "stack", errorreport.FormatStack(stack))
+			if !c.Writer.Written() {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, apierror.Internal("Internal server error", nil).Body())
+			} else {
+				c.Abort()
+			}
+			reporter.Report(ctx, report(c, fmt.Sprint(r), stack))
+		}()
+
+		c.Next()
+
+		if c.Writer.Status() >= 500 {
+			message := http.StatusText(c.Writer.Status())
+			if len(c.Errors) > 0 {
+				message = c.Errors.String()
+			}
+			reporter.Report(c.Request.Context(), report(c, message, nil))
+		}
+	}
+}
+
+func report(c *gin.Context, message string, stack []runtime.Frame) errorreport.Report {
+	route := c.FullPath()
+	if route == "" {
+		route = "unmatched"
+	}
+	userID, orgID := caller(c.Request.Context())
+	return errorreport.Report{
+		Message:   message,
+		Panic:     stack != nil,
+		Stack:     stack,
+		RequestID: c.GetString(RequestIDKey),
+		Method:    c.Request.Method,
+		Route:     route,
+		Status:    c.Writer.Status(),
+		UserID:    userID,
+		OrgID:     orgID,
+		Time:      time.Now(),
+	}
+}
@@ -0,0 +1,109 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"regexp"
+	"strings"
+	"yata/apps/server/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	RequestIDKey    = "requestId"
+	RequestIDHeader = "X-Request-ID"
+
+	RequestIDFormatUUID   = "uuid"
+	RequestIDFormatNanoID = "nanoid"
+
+	maxRequestIDLength = 64
+	nanoIDLength       = 21
+	base62             = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+	// nanoIDPattern takes the whole default nanoid alphabet, so ids from
+	// clients using it are kept; newNanoID only draws from base62.
+	nanoIDPattern = regexp.MustCompile(`^[0-9A-Za-z_-]{` + fmt.Sprint(nanoIDLength) + `}$`)
+)
+
+// RequestID reuses a valid incoming X-Request-ID or generates a new one in the
+// configured format. It exposes it on the gin context, the request context
+// for the log lines written with it, and the response, where JSON error bodies get it as
+// "request_id" too so it can be quoted back to support.
+func RequestID(format string) gin.HandlerFunc {
+	generate, valid := newUUID, uuidPattern.MatchString
+	if format == RequestIDFormatNanoID {
+		generate, valid = newNanoID, nanoIDPattern.MatchString
+	}
+
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if len(id) > maxRequestIDLength || !valid(id) {
+			id = generate()
+		}
+
+		c.Set(RequestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Writer = &requestIDWriter{ResponseWriter: c.Writer, id: id}
+		c.Next()
+	}
+}
+
+// requestIDWriter adds the request ID to JSON error bodies, which gin
+// writes in a single call.
+type requestIDWriter struct {
+	gin.ResponseWriter
+	id      string
+	started bool
+}
+
+func (w *requestIDWriter) Write(b []byte) (int, error) {
+	if w.started || w.Status() < 400 || !strings.HasPrefix(w.Header().Get("Content-Type"), "application/json") || !bytes.HasPrefix(b, []byte("{")) {
+		w.started = true
+		return w.ResponseWriter.Write(b)
+	}
+	w.started = true
+
+	// The ID is alphanumeric with dashes and underscores, so it needs no
+	// escaping.
+	field := `{"request_id":"` + w.id + `"`
+	if !bytes.HasPrefix(bytes.TrimSpace(b[1:]), []byte("}")) {
+		field += ","
+	}
+	if _, err := w.ResponseWriter.WriteString(field); err != nil {
+		return 0, err
+	}
+	if _, err := w.ResponseWriter.Write(b[1:]); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (w *requestIDWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func newUUID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+func newNanoID() string {
+	out := make([]byte, nanoIDLength)
+	max := big.NewInt(int64(len(base62)))
+	for i := range out {
+		n, _ := rand.Int(rand.Reader, max)
+		out[i] = base62[n.Int64()]
+	}
+	return string(out)
+}
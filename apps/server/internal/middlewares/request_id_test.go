@@ -0,0 +1,92 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"yata/apps/server/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	const (
+		validUUID   = "3f2b8c1e-7a4d-4c2e-9b1a-0d5e6f7a8b9c"
+		validNanoID = "V1StGXR8Z5jdHi6BmyTaB"
+	)
+
+	tests := []struct {
+		name     string
+		format   string
+		incoming string
+		keep     bool
+	}{
+		{"uuid generated", RequestIDFormatUUID, "", false},
+		{"nanoid generated", RequestIDFormatNanoID, "", false},
+		{"valid uuid kept", RequestIDFormatUUID, validUUID, true},
+		{"valid nanoid kept", RequestIDFormatNanoID, validNanoID, true},
+		{"nanoid with _ and - kept", RequestIDFormatNanoID, "V1St_XR8Z5jd-i6BmyTaB", true},
+		{"nanoid rejected by uuid format", RequestIDFormatUUID, validNanoID, false},
+		{"uuid rejected by nanoid format", RequestIDFormatNanoID, validUUID, false},
+		{"control characters", RequestIDFormatUUID, validUUID[:35] + "\n", false},
+		{"log injection", RequestIDFormatNanoID, "abc\r\nlevel=ERROR msg=forged", false},
+		{"too long", RequestIDFormatNanoID, strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			r := gin.New()
+			r.Use(RequestID(tt.format))
+			r.GET("/", func(c *gin.Context) { seen = c.GetString(RequestIDKey) })
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			got := w.Header().Get(RequestIDHeader)
+			if got != seen {
+				t.Errorf("header %q differs from context %q", got, seen)
+			}
+			if tt.keep {
+				if got != tt.incoming {
+					t.Errorf("id = %q, want incoming %q kept", got, tt.incoming)
+				}
+				return
+			}
+			if got == tt.incoming {
+				t.Errorf("invalid incoming id %q was kept", got)
+			}
+			valid := uuidPattern.MatchString
+			if tt.format == RequestIDFormatNanoID {
+				valid = nanoIDPattern.MatchString
+			}
+			if !valid(got) {
+				t.Errorf("generated id %q is not a valid %s", got, tt.format)
+			}
+		})
+	}
+}
+
+func TestRequestIDInErrorBodies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	r := gin.New()
+	r.Use(RequestID(RequestIDFormatNanoID), Errors(apierror.RetryPolicy{}))
+	r.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Task not found"})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := `{"request_id":"` + w.Header().Get(RequestIDHeader) + `","code":"NOT_FOUND","message":"Task not found"}`
+	if got := w.Body.String(); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
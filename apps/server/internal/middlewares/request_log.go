@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestLogger replaces gin's access log with one slog line per request.
+// Later middlewares fill in the request ID and the caller, so it goes first.
+// Routes are logged by pattern; raw paths can hold ids and tokens.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		}
+
+		ctx := c.Request.Context()
+		if userID, orgID := caller(ctx); userID != "" {
+			attrs = append(attrs, slog.String("user_id", userID))
+			if orgID != "" {
+				attrs = append(attrs, slog.String("org_id", orgID))
+			}
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		slog.LogAttrs(ctx, level, "Request", attrs...)
+	}
+}
+
+// caller is who the request is from, once auth has run: the signed-in
+// user or a service's principal, and their active org if any.
+func caller(ctx context.Context) (userID, orgID string) {
+	if claims, ok := clerk.SessionClaimsFromContext(ctx); ok {
+		return claims.Subject, claims.ActiveOrganizationID
+	}
+	if service, ok := ServiceClaimsFromContext(ctx); ok {
+		return service.Principal(), service.OrgID
+	}
+	return "", ""
+}
@@ -0,0 +1,134 @@
+package middlewares
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/flags"
+	"yata/apps/server/internal/maintenance"
+	"yata/apps/server/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestRetryAfter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	policy := apierror.RetryPolicy{Base: 2 * time.Second, Jitter: 3 * time.Second}
+	maintenanceMode := func(mode string) gin.HandlerFunc {
+		return Maintenance(maintenance.New(flags.New(flags.NewMemoryStore()), mode), 30*time.Second)
+	}
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	tests := []struct {
+		name   string
+		method string
+		// route is the middleware and handler, built afresh for each try.
+		route    func() []gin.HandlerFunc
+		tries    int
+		status   int
+		code     string
+		min, max int
+	}{
+		{
+			name:   "rate limited",
+			method: http.MethodGet,
+			route: func() []gin.HandlerFunc {
+				return []gin.HandlerFunc{RateLimit(ratelimit.NewMemory(), "api", ratelimit.Rule{Limit: 1, Window: 10 * time.Second}), ok}
+			},
+			tries:  2,
+			status: http.StatusTooManyRequests,
+			code:   apierror.CodeRateLimited,
+			// The window resets in just under 10s.
+			min: 10, max: 13,
+		},
+		{
+			name:   "maintenance",
+			method: http.MethodGet,
+			route:  func() []gin.HandlerFunc { return []gin.HandlerFunc{maintenanceMode(maintenance.Full), ok} },
+			status: http.StatusServiceUnavailable,
+			code:   "MAINTENANCE",
+			min:    30, max: 33,
+		},
+		{
+			name:   "read-only",
+			method: http.MethodPost,
+			route:  func() []gin.HandlerFunc { return []gin.HandlerFunc{maintenanceMode(maintenance.ReadOnly), ok} },
+			status: http.StatusServiceUnavailable,
+			code:   "READ_ONLY",
+			min:    30, max: 33,
+		},
+		{
+			name:   "timed out",
+			method: http.MethodGet,
+			route: func() []gin.HandlerFunc {
+				return []gin.HandlerFunc{Timeout(time.Millisecond, nil), func(c *gin.Context) { <-c.Request.Context().Done() }}
+			},
+			status: http.StatusGatewayTimeout,
+			code:   apierror.CodeTimeout,
+			min:    2, max: 5,
+		},
+		{
+			name:   "database busy",
+			method: http.MethodGet,
+			route: func() []gin.HandlerFunc {
+				return []gin.HandlerFunc{func(c *gin.Context) {
+					apierror.Respond(c, apierror.Internal("Failed to list tasks", &pgconn.PgError{Code: "53300"}))
+				}}
+			},
+			status: http.StatusServiceUnavailable,
+			code:   apierror.CodeUnavailable,
+			min:    2, max: 5,
+		},
+		{
+			name:   "not retryable",
+			method: http.MethodGet,
+			route: func() []gin.HandlerFunc {
+				return []gin.HandlerFunc{func(c *gin.Context) { apierror.Respond(c, apierror.NotFound("Task not found")) }}
+			},
+			status: http.StatusNotFound,
+			code:   apierror.CodeNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The jitter is random; a few runs give it the chance to stray.
+			for range 10 {
+				r := gin.New()
+				r.Use(Errors(policy))
+				r.Handle(tt.method, "/", tt.route()...)
+
+				var w *httptest.ResponseRecorder
+				for range max(tt.tries, 1) {
+					w = httptest.NewRecorder()
+					r.ServeHTTP(w, httptest.NewRequest(tt.method, "/", nil))
+				}
+
+				if w.Code != tt.status {
+					t.Fatalf("status = %d, want %d", w.Code, tt.status)
+				}
+				var body struct{ Code string }
+				if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || body.Code != tt.code {
+					t.Errorf("body = %s, want code %s", w.Body, tt.code)
+				}
+
+				header := w.Header().Get("Retry-After")
+				if tt.max == 0 {
+					if header != "" {
+						t.Errorf("Retry-After = %q, want none", header)
+					}
+					continue
+				}
+				seconds, err := strconv.Atoi(header)
+				if err != nil || seconds < tt.min || seconds > tt.max {
+					t.Errorf("Retry-After = %q, want %d to %d", header, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
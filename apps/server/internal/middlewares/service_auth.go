@@ -0,0 +1,49 @@
+package middlewares
+
+import (
+	"context"
+	"strings"
+	"time"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/servicetokens"
+
+	"github.com/gin-gonic/gin"
+)
+
+type serviceClaimsKey struct{}
+
+// ServiceClaimsFromContext returns the claims of the service token the
+// request was authenticated with by ServiceAuth.
+func ServiceClaimsFromContext(ctx context.Context) (*servicetokens.Claims, bool) {
+	claims, ok := ctx.Value(serviceClaimsKey{}).(*servicetokens.Claims)
+	return claims, ok
+}
+
+// ServiceAuth is the auth middleware for services. It only accepts service
+// tokens, and like personal access tokens they need the write scope for
+// anything but GET and HEAD.
+func ServiceAuth(issuer *servicetokens.Issuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		credential, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok {
+			c.Abort()
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+		claims, err := issuer.Verify(credential, time.Now())
+		if err != nil {
+			c.Abort()
+			apierror.Respond(c, apierror.Unauthorized("Unauthorized"))
+			return
+		}
+
+		if !scopesAllow(claims.Scopes, c.Request.Method, c.FullPath()) {
+			c.Abort()
+			apierror.Respond(c, apierror.Forbidden("Token scope does not allow this request"))
+			return
+		}
+
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), serviceClaimsKey{}, claims))
+		c.Next()
+	}
+}
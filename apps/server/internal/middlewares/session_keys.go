@@ -0,0 +1,116 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/clerk/clerk-sdk-go/v2/jwks"
+)
+
+// minKeyReload spaces out the reloads asked for by tokens naming a key the
+// set doesn't have, so made-up key ids can't have every request fetch it.
+const minKeyReload = 30 * time.Second
+
+var errUnknownKey = errors.New("session token signed with an unknown key")
+
+// SessionKeys is Clerk's JWKS kept in memory, so session tokens are
+// verified without a network call. Start reloads it in the background; a
+// token signed with a key it doesn't have yet, as after Clerk rotates
+// keys, reloads it early.
+type SessionKeys struct {
+	fetch func(ctx context.Context) (*clerk.JSONWebKeySet, error)
+
+	mu   sync.RWMutex
+	keys map[string]*clerk.JSONWebKey
+
+	// reloadMu is held for a fetch, so concurrent misses share one.
+	reloadMu sync.Mutex
+	triedAt  time.Time
+}
+
+func NewSessionKeys() *SessionKeys {
+	return &SessionKeys{
+		fetch: func(ctx context.Context) (*clerk.JSONWebKeySet, error) {
+			return jwks.Get(ctx, &jwks.GetParams{})
+		},
+		keys: map[string]*clerk.JSONWebKey{},
+	}
+}
+
+// Start loads the keys, then reloads them every interval until ctx is
+// done. A failed reload is logged and the keys already loaded are kept.
+func (k *SessionKeys) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			k.reloadMu.Lock()
+			err := k.reload(ctx)
+			k.reloadMu.Unlock()
+			if err != nil && ctx.Err() == nil {
+				slog.WarnContext(ctx, "Failed to load Clerk signing keys", "error", err)
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Key returns the key with the id, reloading the set first when it isn't
+// there and the last try was long enough ago.
+func (k *SessionKeys) Key(ctx context.Context, kid string) (*clerk.JSONWebKey, error) {
+	if key := k.get(kid); key != nil {
+		return key, nil
+	}
+
+	k.reloadMu.Lock()
+	defer k.reloadMu.Unlock()
+	// It may have come in with a reload this one waited on.
+	if key := k.get(kid); key != nil {
+		return key, nil
+	}
+	if time.Since(k.triedAt) < minKeyReload {
+		return nil, errUnknownKey
+	}
+	if err := k.reload(ctx); err != nil {
+		return nil, err
+	}
+	if key := k.get(kid); key != nil {
+		return key, nil
+	}
+	return nil, errUnknownKey
+}
+
+func (k *SessionKeys) get(kid string) *clerk.JSONWebKey {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.keys[kid]
+}
+
+// reload replaces the keys with the current set; reloadMu must be held.
+func (k *SessionKeys) reload(ctx context.Context) error {
+	k.triedAt = time.Now()
+	set, err := k.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]*clerk.JSONWebKey, len(set.Keys))
+	for _, key := range set.Keys {
+		if key != nil && key.KeyID != "" {
+			keys[key.KeyID] = key
+		}
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
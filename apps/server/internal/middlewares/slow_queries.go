@@ -0,0 +1,48 @@
+package middlewares
+
+import (
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+type slowQueryLogStat struct {
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+	TotalMs int64  `json:"totalMs"`
+}
+
+// LogSlowQueries logs a breakdown of the queries a request ran when their
+// combined time is at least threshold. Query arguments are never logged.
+func LogSlowQueries(threshold time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats := database.NewQueryStats()
+		c.Request = c.Request.WithContext(database.ContextWithQueryStats(c.Request.Context(), stats))
+
+		c.Next()
+
+		total := stats.Total()
+		if total < threshold {
+			return
+		}
+
+		queries := []slowQueryLogStat{}
+		for _, q := range stats.Breakdown() {
+			queries = append(queries, slowQueryLogStat{
+				Name:    q.Name,
+				Count:   q.Count,
+				TotalMs: q.Duration.Milliseconds(),
+			})
+		}
+
+		slog.WarnContext(c.Request.Context(), "Slow database time",
+			"type", "slow_db",
+			"method", c.Request.Method,
+			"route", c.FullPath(),
+			"total_ms", total.Milliseconds(),
+			"queries", queries,
+		)
+	}
+}
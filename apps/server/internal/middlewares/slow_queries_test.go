@@ -0,0 +1,63 @@
+package middlewares
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"yata/apps/server/internal/database"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+func TestLogSlowQueries(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// The tracer the pool would run, driven by hand instead of by queries.
+	cfg, err := database.ParseConfig("postgres://yata@localhost/yata", database.WithQueryStats())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer := cfg.ConnConfig.Tracer
+	query := func(c *gin.Context, sql string, d time.Duration) {
+		ctx := tracer.TraceQueryStart(c.Request.Context(), nil, pgx.TraceQueryStartData{SQL: sql, Args: []any{"secret-arg"}})
+		time.Sleep(d)
+		tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	}
+
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(slog.New(slog.NewJSONHandler(&logs, nil)))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+
+	r := gin.New()
+	r.Use(LogSlowQueries(30 * time.Millisecond))
+	r.GET("/slow", func(c *gin.Context) {
+		query(c, "-- name: ListTasks :many\nSELECT * FROM tasks WHERE owner_id = $1", 20*time.Millisecond)
+		query(c, "-- name: ListTasks :many\nSELECT * FROM tasks WHERE owner_id = $1", 20*time.Millisecond)
+		query(c, "SELECT count(*) FROM labels", 0)
+	})
+	r.GET("/fast", func(c *gin.Context) {
+		query(c, "-- name: GetTask :one\nSELECT * FROM tasks WHERE id = $1", 0)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if logs.Len() != 0 {
+		t.Fatalf("fast request logged: %s", logs.String())
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/slow", nil))
+	out := logs.String()
+	for _, want := range []string{`"msg":"Slow database time"`, `"route":"/slow"`, `"name":"ListTasks","count":2`, `"name":"SELECT count(*) FROM labels","count":1`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("log missing %s: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "secret-arg") {
+		t.Errorf("query arguments logged: %s", out)
+	}
+}
@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/apierror"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout puts a deadline on the request context. Routes found in overrides,
+// keyed by "METHOD /route/:pattern", use their own timeout instead of the
+// default. A timeout of zero disables the deadline.
+func Timeout(defaultTimeout time.Duration, overrides map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := defaultTimeout
+		if t, ok := overrides[c.Request.Method+" "+c.FullPath()]; ok {
+			timeout = t
+		}
+
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) && !c.Writer.Written() {
+			c.Abort()
+			apierror.Respond(c, apierror.New(http.StatusGatewayTimeout, "Request timed out"))
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestTimeoutOverrides(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// slow waits for the delay unless the request deadline fires first.
+	slow := func(c *gin.Context) {
+		select {
+		case <-time.After(50 * time.Millisecond):
+			c.Status(http.StatusOK)
+		case <-c.Request.Context().Done():
+		}
+	}
+
+	r := gin.New()
+	r.Use(Timeout(10*time.Millisecond, map[string]time.Duration{
+		"GET /export":    time.Second,
+		"GET /unlimited": 0,
+	}))
+	r.GET("/export", slow)
+	r.GET("/unlimited", slow)
+	r.GET("/tasks/:id", slow)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/export", http.StatusOK},
+		{"/unlimited", http.StatusOK},
+		{"/tasks/1", http.StatusGatewayTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d", tt.path, w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// DeletedUserID stands in for an erased user on what they leave behind in
+// orgs: the tasks, comments and activity other members still rely on.
+const DeletedUserID = "deleted_user"
+
+const (
+	DataExportPending = "pending"
+	DataExportRunning = "running"
+	DataExportDone    = "done"
+	DataExportFailed  = "failed"
+)
+
+// DataExportTTL is how long a finished export can be downloaded.
+const DataExportTTL = 7 * 24 * time.Hour
+
+// DataExport is an archive of a user's data being built by the job worker.
+// DownloadURL is filled in, for a short while, on exports that are done and
+// haven't expired.
+type DataExport struct {
+	ID          string     `json:"id"`
+	UserID      string     `json:"userId"`
+	Status      string     `json:"status"`
+	Size        *int64     `json:"size"`
+	Error       *string    `json:"error"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	FinishedAt  *time.Time `json:"finishedAt"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	DownloadURL *string    `json:"downloadUrl,omitempty"`
+
+	Key *string `json:"-"`
+}
+
+// Downloadable reports whether the archive is there to download at now.
+func (e DataExport) Downloadable(now time.Time) bool {
+	return e.Status == DataExportDone && e.Key != nil && e.ExpiresAt != nil && now.Before(*e.ExpiresAt)
+}
+
+// AccountData is everything kept about a user: the tasks they own, personal
+// and in orgs, trashed ones included, and what they wrote or did elsewhere.
+// User is nil if Clerk never told us about them.
+type AccountData struct {
+	User             *User            `json:"user"`
+	Memberships      []OrgMembership  `json:"memberships"`
+	Settings         UserSettings     `json:"settings"`
+	EmailPreferences EmailPreferences `json:"emailPreferences"`
+	Tasks            []Task           `json:"tasks"`
+	Comments         []Comment        `json:"comments"`
+	TimeEntries      []TimeEntry      `json:"timeEntries"`
+	Pomodoros        []Pomodoro       `json:"pomodoros"`
+	Activity         []Activity       `json:"activity"`
+	Notifications    []Notification   `json:"notifications"`
+}
+
+// ErasedAccount is what erasing a user leaves to clean up outside the
+// database: the orgs they were in and the objects of their exports.
+type ErasedAccount struct {
+	OrgIDs     []string
+	ExportKeys []string
+}
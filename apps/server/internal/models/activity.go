@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+const (
+	ActivityTaskCreated           = "task.created"
+	ActivityTaskUpdated           = "task.updated"
+	ActivityTaskDeleted           = "task.deleted"
+	ActivityTaskRestored          = "task.restored"
+	ActivityTaskArchived          = "task.archived"
+	ActivityTaskUnarchived        = "task.unarchived"
+	ActivityTaskSnoozed           = "task.snoozed"
+	ActivityTaskLabeled           = "task.labeled"
+	ActivityTaskUnlabeled         = "task.unlabeled"
+	ActivityTaskDependencyAdded   = "task.dependency_added"
+	ActivityTaskDependencyRemoved = "task.dependency_removed"
+	ActivityProjectCreated        = "project.created"
+	ActivityProjectRenamed        = "project.renamed"
+	ActivityProjectArchived       = "project.archived"
+	ActivityProjectUnarchived     = "project.unarchived"
+	ActivityProjectDeleted        = "project.deleted"
+	ActivityProjectRestored       = "project.restored"
+)
+
+// Activity is one entry in the audit trail: who did what to which task or
+// project, with the old and new value of every field it changed.
+type Activity struct {
+	ID        string                 `json:"id"`
+	OrgID     *string                `json:"orgId"`
+	ActorID   string                 `json:"actorId"`
+	TaskID    *string                `json:"taskId"`
+	ProjectID *string                `json:"projectId"`
+	Action    string                 `json:"action"`
+	Changes   map[string]FieldChange `json:"changes"`
+	CreatedAt time.Time              `json:"createdAt"`
+}
+
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+type CreateActivityInput struct {
+	OrgID     *string
+	ActorID   string
+	TaskID    *string
+	ProjectID *string
+	Action    string
+	Changes   map[string]FieldChange
+}
+
+// AssigneeChange is one change of a task's owner, from the activity log.
+// From is nil for the first owner the log knows of.
+type AssigneeChange struct {
+	ActivityID string    `json:"activityId"`
+	From       *Person   `json:"from"`
+	To         *Person   `json:"to"`
+	Actor      Person    `json:"actor"`
+	ChangedAt  time.Time `json:"changedAt"`
+}
+
+// Person is a user's ID with their display name, which is empty when the
+// user can't be read.
+type Person struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
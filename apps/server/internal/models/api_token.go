@@ -0,0 +1,58 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// API token scopes. Reads are GET and HEAD requests; everything else is a
+// write.
+const (
+	TokenScopeRead  = "read"
+	TokenScopeWrite = "write"
+)
+
+func ValidTokenScope(scope string) bool {
+	return scope == TokenScopeRead || scope == TokenScopeWrite
+}
+
+// MaxAPITokenTTL caps how far out a token's expiry may be set. Tokens
+// without one never expire.
+const MaxAPITokenTTL = 365 * 24 * time.Hour
+
+// APIToken is a personal access token. Token is only filled in the response
+// that mints it.
+type APIToken struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	OrgID      *string    `json:"orgId"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expiresAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	Token      string     `json:"token,omitempty"`
+}
+
+func (t APIToken) HasScope(scope string) bool {
+	return slices.Contains(t.Scopes, scope)
+}
+
+// Scope is who the token acts as.
+func (t APIToken) Scope() Scope {
+	s := Scope{UserID: t.UserID}
+	if t.OrgID != nil {
+		s.OrgID = *t.OrgID
+	}
+	return s
+}
+
+type CreateAPITokenInput struct {
+	Name      string     `json:"name" binding:"required,max=100"`
+	Scopes    []string   `json:"scopes" binding:"required,min=1"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+
+	Prefix    string `json:"-"`
+	TokenHash []byte `json:"-"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type Attachment struct {
+	ID          string `json:"id"`
+	TaskID      string `json:"taskId"`
+	UploaderID  string `json:"uploaderId"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	// Key is where the contents live in object storage.
+	Key string `json:"-"`
+	// ConfirmedAt is nil until the upload has been checked.
+	ConfirmedAt *time.Time `json:"confirmedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+}
+
+// CreateAttachmentInput describes a file the client is about to upload.
+type CreateAttachmentInput struct {
+	Filename    string `json:"filename" binding:"required"`
+	ContentType string `json:"contentType" binding:"required"`
+	Size        int64  `json:"size" binding:"required"`
+
+	// Key is picked by the handler.
+	Key string `json:"-"`
+}
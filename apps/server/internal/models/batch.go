@@ -0,0 +1,22 @@
+package models
+
+import "encoding/json"
+
+// BatchRequest is one read in a batch: an API path, relative to the API
+// root and with its query, like "/tasks/abc" or "/notifications/unread-count".
+// ID is echoed back so clients can match responses without relying on order.
+type BatchRequest struct {
+	ID   string `json:"id" binding:"max=100"`
+	Path string `json:"path" binding:"required,startswith=/"`
+}
+
+type BatchInput struct {
+	Requests []BatchRequest `json:"requests" binding:"required,min=1,max=50,dive"`
+}
+
+// BatchResponse is what the read would have answered on its own.
+type BatchResponse struct {
+	ID     string          `json:"id,omitempty"`
+	Status int             `json:"status"`
+	Body   json.RawMessage `json:"body,omitempty"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import "time"
+
+// MaxBoardColumns caps how many columns one board has.
+const MaxBoardColumns = 20
+
+// DefaultBoardName names the board a project made from a template comes with.
+const DefaultBoardName = "Board"
+
+// Board lays a project's tasks out in columns, one per workflow status.
+type Board struct {
+	ID        string        `json:"id"`
+	OrgID     string        `json:"orgId"`
+	ProjectID string        `json:"projectId"`
+	Name      string        `json:"name"`
+	CreatedBy string        `json:"createdBy"`
+	Columns   []BoardColumn `json:"columns"`
+	CreatedAt time.Time     `json:"createdAt"`
+	UpdatedAt time.Time     `json:"updatedAt"`
+}
+
+// BoardColumn holds the project's tasks in Status. Tasks is only filled in
+// when the board is read with its cards, in board order.
+type BoardColumn struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Status   string `json:"status"`
+	Position int    `json:"position"`
+	Tasks    []Task `json:"tasks,omitempty"`
+}
+
+type BoardColumnInput struct {
+	Name   string `json:"name" binding:"required,max=100"`
+	Status string `json:"status" binding:"required"`
+}
+
+// CreateBoardInput leaves Columns out to get one per workflow status.
+type CreateBoardInput struct {
+	Name    string             `json:"name" binding:"required,max=200"`
+	Columns []BoardColumnInput `json:"columns" binding:"omitempty,max=20,dive"`
+}
+
+// UpdateBoardInput's Columns replace the board's, in order. A column keeps
+// its id as long as its status stays on the board.
+type UpdateBoardInput struct {
+	Name    *string            `json:"name" binding:"omitempty,min=1,max=200"`
+	Columns []BoardColumnInput `json:"columns" binding:"omitempty,min=1,max=20,dive"`
+}
+
+// MoveCardInput puts a task at Index in a column, counting from the top;
+// past the end means last.
+type MoveCardInput struct {
+	TaskID   string `json:"taskId" binding:"required"`
+	ColumnID string `json:"columnId" binding:"required"`
+	Index    int    `json:"index" binding:"min=0"`
+}
+
+// BoardMove is a task after a move, with the status it moved out of.
+type BoardMove struct {
+	Task           Task   `json:"task"`
+	ColumnID       string `json:"columnId"`
+	Index          int    `json:"index"`
+	PreviousStatus string `json:"previousStatus"`
+}
@@ -0,0 +1,32 @@
+package models
+
+const (
+	BulkComplete = "complete"
+	BulkMove     = "move"
+	BulkRelabel  = "relabel"
+	BulkDelete   = "delete"
+)
+
+// BulkTaskOp is one item of a bulk request. Move uses ProjectID (null moves
+// the task out of its project); relabel replaces the task's labels with
+// LabelIDs.
+type BulkTaskOp struct {
+	TaskID    string   `json:"taskId" binding:"required"`
+	Action    string   `json:"action" binding:"required,oneof=complete move relabel delete"`
+	ProjectID *string  `json:"projectId"`
+	LabelIDs  []string `json:"labelIds"`
+}
+
+type BulkTaskInput struct {
+	Ops []BulkTaskOp `json:"ops" binding:"required,min=1,max=500,dive"`
+}
+
+// BulkTaskResult reports one op. Completed is set when a complete op moved
+// the task into a done status, so follow-ups like recurrence can run.
+type BulkTaskResult struct {
+	TaskID    string `json:"taskId"`
+	Action    string `json:"action"`
+	OK        bool   `json:"ok"`
+	Error     string `json:"error,omitempty"`
+	Completed *Task  `json:"-"`
+}
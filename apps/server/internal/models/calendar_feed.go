@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// CalendarFeed is a user's iCalendar feed in one scope. URL is only filled
+// in the response that creates it, since only the token's hash is kept.
+type CalendarFeed struct {
+	ID         string     `json:"id"`
+	UserID     string     `json:"userId"`
+	OrgID      *string    `json:"orgId"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	URL        string     `json:"url,omitempty"`
+}
+
+// Scope is who the feed reads as, before the org role is looked up.
+func (f CalendarFeed) Scope() Scope {
+	s := Scope{UserID: f.UserID}
+	if f.OrgID != nil {
+		s.OrgID = *f.OrgID
+	}
+	return s
+}
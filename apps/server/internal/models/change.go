@@ -0,0 +1,37 @@
+package models
+
+// Entities the change log records writes to.
+const (
+	ChangeTask    = "task"
+	ChangeProject = "project"
+	ChangeLabel   = "label"
+)
+
+// ChangeCursor is where a delta sync left off in the change log. Tx orders
+// entries by the transaction that wrote them, so one that commits late
+// isn't skipped; stores without transactions leave it empty.
+type ChangeCursor struct {
+	Tx  string
+	Seq int64
+}
+
+// Changes is what was created, updated or deleted in a scope between two
+// cursors. Items are as they are now; an id is in Deleted when its item is
+// gone, in the trash or no longer visible to the caller.
+type Changes struct {
+	Tasks    []Task    `json:"tasks"`
+	Projects []Project `json:"projects"`
+	Labels   []Label   `json:"labels"`
+	Deleted  Deleted   `json:"deleted"`
+	// Next is where the following sync starts from; handlers send it
+	// signed, as Cursor.
+	Next    ChangeCursor `json:"-"`
+	Cursor  string       `json:"cursor"`
+	HasMore bool         `json:"hasMore"`
+}
+
+type Deleted struct {
+	Tasks    []string `json:"tasks"`
+	Projects []string `json:"projects"`
+	Labels   []string `json:"labels"`
+}
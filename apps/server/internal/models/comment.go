@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+const MaxCommentLength = 10000
+
+type Comment struct {
+	ID       string `json:"id"`
+	TaskID   string `json:"taskId"`
+	AuthorID string `json:"authorId"`
+	// ParentID is the top-level comment this one replies to.
+	ParentID *string `json:"parentId"`
+	Body     string  `json:"body"`
+	// BodyHTML is Body rendered from Markdown and sanitized, when the
+	// request asks for it.
+	BodyHTML *string `json:"bodyHtml,omitempty"`
+	// Mentions are the user ids of the org members @mentioned in Body.
+	Mentions []string `json:"mentions"`
+	Edited   bool     `json:"edited"`
+	// PinnedAt and PinnedBy are set while the comment is pinned to its
+	// task, which everyone who can see the task sees.
+	PinnedAt  *time.Time `json:"pinnedAt"`
+	PinnedBy  *string    `json:"pinnedBy"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+// CommentRevision is a body a comment had before it was edited.
+type CommentRevision struct {
+	ID        string    `json:"id"`
+	CommentID string    `json:"commentId"`
+	Body      string    `json:"body"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CommentInput is the body of both creating and editing a comment.
+type CommentInput struct {
+	Body string `json:"body" binding:"required"`
+	// ParentID makes a new comment a reply; replying to a reply answers
+	// its top-level comment. Edits leave it as it was.
+	ParentID *string `json:"parentId"`
+
+	// Mentions is filled in by the handler once the body's @mentions are
+	// resolved.
+	Mentions []string `json:"-"`
+}
+
+// CommentThread is a top-level comment with its replies, oldest first.
+type CommentThread struct {
+	Comment
+	Replies []Comment `json:"replies"`
+}
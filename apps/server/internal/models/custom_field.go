@@ -0,0 +1,149 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"time"
+	"unicode/utf8"
+)
+
+// Custom field types. A field's type is fixed when it's created, since
+// tasks already hold values of that type.
+const (
+	CustomFieldText   = "text"
+	CustomFieldNumber = "number"
+	CustomFieldSelect = "select"
+	CustomFieldDate   = "date"
+	CustomFieldUser   = "user"
+)
+
+const (
+	MaxCustomFields        = 50
+	MaxCustomFieldOptions  = 100
+	MaxCustomFieldTextSize = 1000
+)
+
+var customFieldKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// IsValidCustomFieldKey accepts lowercase keys of up to 40 letters, digits
+// and underscores, starting with a letter.
+func IsValidCustomFieldKey(key string) bool {
+	return customFieldKeyRegex.MatchString(key)
+}
+
+func IsValidCustomFieldType(t string) bool {
+	switch t {
+	case CustomFieldText, CustomFieldNumber, CustomFieldSelect, CustomFieldDate, CustomFieldUser:
+		return true
+	}
+	return false
+}
+
+// CustomField is an org's definition of a task attribute. Tasks hold their
+// values under Key in Task.CustomFields.
+type CustomField struct {
+	ID    string `json:"id"`
+	OrgID string `json:"orgId"`
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	// Options are the choices of a select field, in display order.
+	Options   []string  `json:"options"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Normalize checks a task's value for the field against its type, returning
+// it in the form it's stored in: text, select, date (YYYY-MM-DD) and user
+// (a user id) values are strings, numbers are float64. Whether a user is a
+// member of the org is for the caller to check.
+func (f CustomField) Normalize(value any) (any, error) {
+	switch f.Type {
+	case CustomFieldNumber:
+		n, ok := value.(float64)
+		if !ok {
+			return nil, fmt.Errorf("%s must be a number", f.Key)
+		}
+		return n, nil
+	}
+
+	s, ok := value.(string)
+	if !ok || s == "" {
+		return nil, fmt.Errorf("%s must be a non-empty string", f.Key)
+	}
+	switch f.Type {
+	case CustomFieldText:
+		if utf8.RuneCountInString(s) > MaxCustomFieldTextSize {
+			return nil, fmt.Errorf("%s is too long", f.Key)
+		}
+	case CustomFieldSelect:
+		if !slices.Contains(f.Options, s) {
+			return nil, fmt.Errorf("%s must be one of its options", f.Key)
+		}
+	case CustomFieldDate:
+		if _, err := time.Parse(time.DateOnly, s); err != nil {
+			return nil, fmt.Errorf("%s must be a date (YYYY-MM-DD)", f.Key)
+		}
+	}
+	return s, nil
+}
+
+// CheckCustomFieldOptions validates the options of a field of type t: a
+// select field needs at least one, all different, and other types none.
+func CheckCustomFieldOptions(t string, options []string) error {
+	if t != CustomFieldSelect {
+		if len(options) > 0 {
+			return errors.New("only select fields have options")
+		}
+		return nil
+	}
+	if len(options) == 0 {
+		return errors.New("select fields need at least one option")
+	}
+	if len(options) > MaxCustomFieldOptions {
+		return fmt.Errorf("select fields can have at most %d options", MaxCustomFieldOptions)
+	}
+	seen := map[string]bool{}
+	for _, o := range options {
+		if o == "" || utf8.RuneCountInString(o) > 100 {
+			return errors.New("options must be 1 to 100 characters")
+		}
+		if seen[o] {
+			return fmt.Errorf("option %q given twice", o)
+		}
+		seen[o] = true
+	}
+	return nil
+}
+
+type CreateCustomFieldInput struct {
+	Key     string   `json:"key" binding:"required"`
+	Name    string   `json:"name" binding:"required"`
+	Type    string   `json:"type" binding:"required"`
+	Options []string `json:"options"`
+}
+
+// UpdateCustomFieldInput can't change a field's key or type. Tasks set to
+// an option that's dropped lose their value for the field.
+type UpdateCustomFieldInput struct {
+	Name    *string   `json:"name"`
+	Options *[]string `json:"options"`
+}
+
+// FieldFilter matches tasks by a custom field value. Op is one of =, <, <=,
+// > and >=; the ordering ones only apply to number and date fields, and =
+// on a text field is a case-insensitive substring match. Value is in its
+// stored form, see CustomField.Normalize.
+type FieldFilter struct {
+	Key   string
+	Type  string
+	Op    string
+	Value any
+}
+
+// FieldFilterOps are the comparisons a FieldFilter can make, longest first
+// so they can be matched as prefixes.
+var FieldFilterOps = []string{">=", "<=", ">", "<", "="}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// DigestSubscriber is a user who wants digest emails, and when they were
+// last sent one.
+type DigestSubscriber struct {
+	Settings   UserSettings
+	LastSentAt *time.Time
+}
+
+// Digest is what a user's digest lists: their open tasks, across every org
+// and their personal ones, by section. A task is only in the first section
+// it fits.
+type Digest struct {
+	Overdue []Task
+	Due     []Task
+	// Assigned are tasks someone else created for the user or handed to
+	// them since the last digest.
+	Assigned []Task
+}
+
+func (d Digest) Empty() bool {
+	return len(d.Overdue) == 0 && len(d.Due) == 0 && len(d.Assigned) == 0
+}
+
+// DigestQuery bounds a digest's sections: tasks due before DueFrom are
+// overdue, those due before DueTo are due, and assignments count from
+// AssignedSince on. Snoozed tasks are left out of the due sections. Each
+// section holds at most Limit tasks.
+type DigestQuery struct {
+	DueFrom       time.Time
+	DueTo         time.Time
+	AssignedSince time.Time
+	Limit         int
+}
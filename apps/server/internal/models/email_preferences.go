@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// EmailPreferences are per-user opt-outs; every kind defaults to on.
+type EmailPreferences struct {
+	UserID         string    `json:"userId"`
+	TaskAssigned   bool      `json:"taskAssigned"`
+	DueSoon        bool      `json:"dueSoon"`
+	CommentMention bool      `json:"commentMention"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func DefaultEmailPreferences(userID string) EmailPreferences {
+	return EmailPreferences{UserID: userID, TaskAssigned: true, DueSoon: true, CommentMention: true}
+}
+
+type UpdateEmailPreferencesInput struct {
+	TaskAssigned   *bool `json:"taskAssigned"`
+	DueSoon        *bool `json:"dueSoon"`
+	CommentMention *bool `json:"commentMention"`
+}
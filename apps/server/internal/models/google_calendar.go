@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// GoogleCalendarConnection links a user's scope to their Google Calendar.
+// The OAuth tokens never leave the server.
+type GoogleCalendarConnection struct {
+	ID           string     `json:"id"`
+	UserID       string     `json:"userId"`
+	OrgID        *string    `json:"orgId"`
+	Email        string     `json:"email"`
+	CalendarID   string     `json:"calendarId"`
+	LastSyncedAt *time.Time `json:"lastSyncedAt"`
+	// LastError is why the latest sync failed, cleared once one succeeds.
+	LastError *string   `json:"lastError"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	Token GoogleCalendarToken `json:"-"`
+}
+
+// Scope is who the connection syncs as, before the org role is looked up.
+func (c GoogleCalendarConnection) Scope() Scope {
+	s := Scope{UserID: c.UserID}
+	if c.OrgID != nil {
+		s.OrgID = *c.OrgID
+	}
+	return s
+}
+
+// GoogleCalendarToken is an OAuth grant. Google only sends RefreshToken on
+// the first exchange, so an empty one leaves the stored token in place.
+type GoogleCalendarToken struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// GoogleCalendarEvent records the event made for a task. Due and
+// TaskUpdatedAt are as of the last sync, so the next one can tell which
+// side changed.
+type GoogleCalendarEvent struct {
+	ConnectionID  string
+	TaskID        string
+	EventID       string
+	Due           time.Time
+	TaskUpdatedAt time.Time
+}
@@ -0,0 +1,10 @@
+package models
+
+// IdempotentRequest is what's stored for an Idempotency-Key. StatusCode is
+// zero until the first request carrying the key has finished.
+type IdempotentRequest struct {
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
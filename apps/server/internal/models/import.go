@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// Import sources, the tools whose exports can be uploaded.
+const (
+	ImportSourceTodoist = "todoist"
+	ImportSourceTrello  = "trello"
+	ImportSourceAsana   = "asana"
+)
+
+func ValidImportSource(source string) bool {
+	return source == ImportSourceTodoist || source == ImportSourceTrello || source == ImportSourceAsana
+}
+
+const (
+	ImportStatusPending = "pending"
+	ImportStatusRunning = "running"
+	ImportStatusDone    = "done"
+	ImportStatusFailed  = "failed"
+)
+
+// Import is one uploaded export being turned into tasks by the job worker.
+// Total is known once the file has been read; Imported counts the tasks
+// created so far and Skipped the entries that couldn't be.
+type Import struct {
+	ID       string  `json:"id"`
+	UserID   string  `json:"userId"`
+	OrgID    *string `json:"orgId"`
+	Source   string  `json:"source"`
+	FileName string  `json:"fileName"`
+	// Timezone is the zone date-only due dates in the file are read in.
+	Timezone   *string    `json:"timezone"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Imported   int        `json:"imported"`
+	Skipped    int        `json:"skipped"`
+	Error      *string    `json:"error"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	FinishedAt *time.Time `json:"finishedAt"`
+}
+
+// Scope is who the import creates tasks as.
+func (i Import) Scope() Scope {
+	s := Scope{UserID: i.UserID}
+	if i.OrgID != nil {
+		s.OrgID = *i.OrgID
+	}
+	return s
+}
+
+// Finished reports whether the import is done or gave up.
+func (i Import) Finished() bool {
+	return i.Status == ImportStatusDone || i.Status == ImportStatusFailed
+}
+
+// ImportReport is what reading an export found: the entries that would be
+// imported, and why the rest would be skipped.
+type ImportReport struct {
+	// Columns are a CSV export's columns as the file names them; Trello
+	// exports have none.
+	Columns []string         `json:"columns"`
+	Valid   int              `json:"valid"`
+	Errors  []ImportRowError `json:"errors"`
+}
+
+// ImportRowError is an entry that would be skipped. CSV entries are found
+// by Line; Trello's, which have no lines, by Ref, the card or checklist
+// item id.
+type ImportRowError struct {
+	Line    int    `json:"line,omitempty"`
+	Ref     string `json:"ref,omitempty"`
+	Message string `json:"message"`
+}
+
+type CreateImportInput struct {
+	Source   string
+	FileName string
+	Timezone *string
+	Total    int
+	// Data is the uploaded file, kept until the import finishes.
+	Data []byte
+}
+
+// ImportProgress is what the job reports as it goes. Setting a finished
+// status also drops the uploaded file.
+type ImportProgress struct {
+	Status   string
+	Imported int
+	Skipped  int
+	Error    *string
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// InboundAddress is an email address that turns what's sent to it into the
+// user's tasks, in a project when ProjectID is set. Address is filled in by
+// the handler from the token and the configured domain.
+type InboundAddress struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	OrgID     *string   `json:"orgId"`
+	ProjectID *string   `json:"projectId"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	Token string `json:"-"`
+}
+
+// Scope is who tasks are created as, before the org role is looked up.
+func (a InboundAddress) Scope() Scope {
+	s := Scope{UserID: a.UserID}
+	if a.OrgID != nil {
+		s.OrgID = *a.OrgID
+	}
+	return s
+}
+
+type RotateInboundAddressInput struct {
+	// ProjectID picks the project's address rather than the scope's own.
+	ProjectID *string `json:"projectId"`
+}
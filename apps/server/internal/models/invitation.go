@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// InvitationTTL is how long a guest has to accept, here and in Clerk.
+const InvitationTTL = 7 * 24 * time.Hour
+
+// Invitation brings an external collaborator into one project with a share
+// role.
+type Invitation struct {
+	ID                string     `json:"id"`
+	OrgID             string     `json:"orgId"`
+	ProjectID         string     `json:"projectId"`
+	Email             string     `json:"email"`
+	Role              string     `json:"role"`
+	ClerkInvitationID string     `json:"-"`
+	InvitedBy         string     `json:"invitedBy"`
+	ExpiresAt         time.Time  `json:"expiresAt"`
+	AcceptedBy        *string    `json:"acceptedBy"`
+	AcceptedAt        *time.Time `json:"acceptedAt"`
+	RevokedAt         *time.Time `json:"revokedAt"`
+	CreatedAt         time.Time  `json:"createdAt"`
+}
+
+// Pending reports whether the invitation can still be accepted at now.
+func (i Invitation) Pending(now time.Time) bool {
+	return i.AcceptedAt == nil && i.RevokedAt == nil && now.Before(i.ExpiresAt)
+}
+
+type CreateInvitationInput struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+
+	ProjectID         string    `json:"-"`
+	TokenHash         []byte    `json:"-"`
+	ClerkInvitationID string    `json:"-"`
+	ExpiresAt         time.Time `json:"-"`
+}
+
+type AcceptInvitationInput struct {
+	Token string `json:"token" binding:"required"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"regexp"
+	"time"
+)
+
+type Label struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"orgId"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+var colorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// IsValidLabelColor accepts hex colors in #rrggbb form.
+func IsValidLabelColor(color string) bool {
+	return colorRegex.MatchString(color)
+}
+
+type CreateLabelInput struct {
+	Name  string `json:"name" binding:"required"`
+	Color string `json:"color" binding:"required"`
+}
+
+type UpdateLabelInput struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+type AttachLabelInput struct {
+	LabelID string `json:"labelId" binding:"required"`
+}
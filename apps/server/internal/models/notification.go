@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Notification is an entry in a user's in-app inbox.
+type Notification struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"userId"`
+	OrgID     *string    `json:"orgId"`
+	Kind      string     `json:"kind"`
+	TaskID    *string    `json:"taskId"`
+	ActorID   *string    `json:"actorId"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type CreateNotificationInput struct {
+	UserID  string
+	OrgID   *string
+	Kind    string
+	TaskID  *string
+	ActorID *string
+	Title   string
+	Body    string
+}
+
+type MarkNotificationInput struct {
+	Read *bool `json:"read" binding:"required"`
+}
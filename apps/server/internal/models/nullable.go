@@ -0,0 +1,28 @@
+package models
+
+import "encoding/json"
+
+// Nullable tells apart a field missing from a PATCH body, an explicit null
+// and a value, so clients can clear optional fields.
+type Nullable[T any] struct {
+	Set   bool
+	Null  bool
+	Value T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &n.Value)
+}
+
+// Ptr returns nil for an explicit null and a pointer to the value otherwise.
+func (n Nullable[T]) Ptr() *T {
+	if n.Null {
+		return nil
+	}
+	return &n.Value
+}
@@ -0,0 +1,35 @@
+package models
+
+// MemberTaskCounts counts the org's live tasks a member owns. Open tasks
+// leave out archived ones.
+type MemberTaskCounts struct {
+	Open      int `json:"open"`
+	Overdue   int `json:"overdue"`
+	Completed int `json:"completed"`
+}
+
+type AdminMember struct {
+	OrgMember
+	Tasks MemberTaskCounts `json:"tasks"`
+}
+
+type TransferTasksInput struct {
+	ToUserID string `json:"toUserId" binding:"required"`
+}
+
+type ReassignTasksInput struct {
+	TaskIDs  []string `json:"taskIds" binding:"required,min=1,max=500"`
+	ToUserID string   `json:"toUserId" binding:"required"`
+}
+
+type DeactivateMemberInput struct {
+	// TransferTo takes over the member's tasks, if set.
+	TransferTo *string `json:"transferTo"`
+}
+
+// ReassignedTask is a task that changed owner.
+type ReassignedTask struct {
+	ID              string  `json:"id"`
+	ProjectID       *string `json:"projectId"`
+	PreviousOwnerID string  `json:"previousOwnerId"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	TaskVisibilityOrg     = "org"
+	TaskVisibilityPrivate = "private"
+)
+
+func ValidTaskVisibility(v string) bool {
+	return v == TaskVisibilityOrg || v == TaskVisibilityPrivate
+}
+
+// MaxTrashRetentionDays bounds what an org can ask the trash to keep.
+const MaxTrashRetentionDays = 365
+
+type OrgSettings struct {
+	OrgID string `json:"orgId"`
+	// WorkingDays are time.Weekday values, 0 being Sunday.
+	WorkingDays           []int  `json:"workingDays"`
+	DefaultTaskVisibility string `json:"defaultTaskVisibility"`
+	// AllowedLabelColors restricts label colors when it isn't empty.
+	AllowedLabelColors []string `json:"allowedLabelColors"`
+	// TrashRetentionDays overrides the server's trash retention when set.
+	TrashRetentionDays *int `json:"trashRetentionDays"`
+	// RateLimit is a "limit/window" rule overriding the server's limit on
+	// the org's API requests when set. Only the operator changes it.
+	RateLimit *string   `json:"rateLimit"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+func DefaultOrgSettings(orgID string) OrgSettings {
+	return OrgSettings{
+		OrgID:                 orgID,
+		WorkingDays:           []int{1, 2, 3, 4, 5},
+		DefaultTaskVisibility: TaskVisibilityOrg,
+		AllowedLabelColors:    []string{},
+	}
+}
+
+// AllowsLabelColor compares case-insensitively, as hex colors are.
+func (s OrgSettings) AllowsLabelColor(color string) bool {
+	if len(s.AllowedLabelColors) == 0 {
+		return true
+	}
+	return slices.ContainsFunc(s.AllowedLabelColors, func(c string) bool { return strings.EqualFold(c, color) })
+}
+
+// DefaultLabelColor is what labels made on the user's behalf, by an import
+// say, are colored.
+const DefaultLabelColor = "#6b7280"
+
+// LabelColor is the color for a label made on the user's behalf:
+// DefaultLabelColor unless the org doesn't allow it.
+func (s OrgSettings) LabelColor() string {
+	if s.AllowsLabelColor(DefaultLabelColor) {
+		return DefaultLabelColor
+	}
+	return s.AllowedLabelColors[0]
+}
+
+type UpdateOrgSettingsInput struct {
+	WorkingDays           *[]int        `json:"workingDays"`
+	DefaultTaskVisibility *string       `json:"defaultTaskVisibility"`
+	AllowedLabelColors    *[]string     `json:"allowedLabelColors"`
+	TrashRetentionDays    Nullable[int] `json:"trashRetentionDays"`
+	// RateLimit is left out of PATCH bodies; see SetOrgRateLimitHandler.
+	RateLimit Nullable[string] `json:"-"`
+}
+
+// Apply returns s with the fields set in input replaced.
+func (input UpdateOrgSettingsInput) Apply(s OrgSettings) OrgSettings {
+	if input.WorkingDays != nil {
+		days := slices.Clone(*input.WorkingDays)
+		slices.Sort(days)
+		s.WorkingDays = slices.Compact(days)
+	}
+	if input.DefaultTaskVisibility != nil {
+		s.DefaultTaskVisibility = *input.DefaultTaskVisibility
+	}
+	if input.AllowedLabelColors != nil {
+		s.AllowedLabelColors = slices.Clone(*input.AllowedLabelColors)
+	}
+	if input.TrashRetentionDays.Set {
+		s.TrashRetentionDays = input.TrashRetentionDays.Ptr()
+	}
+	if input.RateLimit.Set {
+		s.RateLimit = input.RateLimit.Ptr()
+	}
+	return s
+}
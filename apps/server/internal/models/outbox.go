@@ -0,0 +1,27 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Events recorded in the outbox alongside the writes they describe.
+const (
+	EventTaskCreated   = "task.created"
+	EventTaskCompleted = "task.completed"
+	EventTaskDeleted   = "task.deleted"
+	EventCommentAdded  = "comment.added"
+)
+
+// OutboxEvent is a change recorded in the same transaction as the write that
+// made it, waiting to be relayed. Payload is the event's data: the task, or
+// the task id and the comment.
+type OutboxEvent struct {
+	ID        string
+	Event     string
+	OrgID     *string
+	ActorID   string
+	TaskID    *string
+	Payload   json.RawMessage
+	CreatedAt time.Time
+}
@@ -0,0 +1,8 @@
+package models
+
+// Page is a keyset page request: the page size and the sort key of the last
+// item already returned, if any.
+type Page struct {
+	Limit int
+	After []string
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+const (
+	PomodoroRunning     = "running"
+	PomodoroCompleted   = "completed"
+	PomodoroInterrupted = "interrupted"
+
+	DefaultPomodoroMinutes = 25
+	MaxPomodoroMinutes     = 120
+)
+
+// Pomodoro is a focus session a user ran in a scope, on a task or not.
+type Pomodoro struct {
+	ID     string  `json:"id"`
+	UserID string  `json:"userId"`
+	OrgID  *string `json:"orgId"`
+	// TaskID is cleared when the task is deleted for good.
+	TaskID         *string   `json:"taskId"`
+	Status         string    `json:"status"`
+	PlannedSeconds int       `json:"plannedSeconds"`
+	StartedAt      time.Time `json:"startedAt"`
+	// EndedAt is when it was completed or interrupted.
+	EndedAt *time.Time `json:"endedAt"`
+}
+
+// DueAt is when the session's planned time is up.
+func (p *Pomodoro) DueAt() time.Time {
+	return p.StartedAt.Add(time.Duration(p.PlannedSeconds) * time.Second)
+}
+
+type StartPomodoroInput struct {
+	TaskID *string `json:"taskId"`
+	// Minutes defaults to DefaultPomodoroMinutes.
+	Minutes *int `json:"minutes"`
+}
+
+// PomodoroDay is what the user finished on one day in their timezone.
+// FocusSeconds counts interrupted sessions up to when they stopped.
+type PomodoroDay struct {
+	Date         string `json:"date"`
+	Completed    int    `json:"completed"`
+	Interrupted  int    `json:"interrupted"`
+	FocusSeconds int64  `json:"focusSeconds"`
+}
+
+// PomodoroStats sums up the user's sessions in a scope. A day or week
+// counts towards a streak with at least one completed session; the current
+// streak is still alive while the last day or week is the current one or
+// the one before.
+type PomodoroStats struct {
+	Today    PomodoroDay `json:"today"`
+	ThisWeek PomodoroDay `json:"thisWeek"`
+	// Days is the last seven days, oldest first.
+	Days                []PomodoroDay `json:"days"`
+	DailyStreak         int           `json:"dailyStreak"`
+	LongestDailyStreak  int           `json:"longestDailyStreak"`
+	WeeklyStreak        int           `json:"weeklyStreak"`
+	LongestWeeklyStreak int           `json:"longestWeeklyStreak"`
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type Project struct {
+	ID         string     `json:"id"`
+	OrgID      string     `json:"orgId"`
+	Name       string     `json:"name"`
+	CreatedBy  string     `json:"createdBy"`
+	ArchivedAt *time.Time `json:"archivedAt"`
+	DeletedAt  *time.Time `json:"deletedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+}
+
+type CreateProjectInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type RenameProjectInput struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// NewProject is a project to create with what it starts out with: Board, if
+// set, and the batch of Tasks (see NewTask).
+type NewProject struct {
+	Input CreateProjectInput
+	Board *CreateBoardInput
+	Tasks []NewTask
+}
+
+// SeededProject is a NewProject once created.
+type SeededProject struct {
+	Project Project `json:"project"`
+	Board   *Board  `json:"board,omitempty"`
+	Tasks   []Task  `json:"tasks"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import "time"
+
+// MaxProjectTemplateTasks caps the tasks in a project template.
+const MaxProjectTemplateTasks = 500
+
+// ProjectTemplate is a saved project that new projects can be created
+// from. Everyone in the org can use it, but only its creator can change it.
+type ProjectTemplate struct {
+	ID        string                `json:"id"`
+	OrgID     string                `json:"orgId"`
+	Name      string                `json:"name"`
+	CreatedBy string                `json:"createdBy"`
+	Tasks     []ProjectTemplateTask `json:"tasks"`
+	CreatedAt time.Time             `json:"createdAt"`
+	UpdatedAt time.Time             `json:"updatedAt"`
+}
+
+// ProjectTemplateTask is one task of a project template. Labels are names,
+// created in the org if it doesn't have them; sections come across as
+// labels, the way the importers bring them in. Role is who the task goes
+// to, by a name like "manager" that's mapped to a member on instantiation.
+// Parent and BlockedBy are indexes into the template's tasks, with a
+// parent coming before its subtasks. Due dates are relative, as in
+// TemplateTask.
+type ProjectTemplateTask struct {
+	Title       string   `json:"title" binding:"required,max=500"`
+	Description string   `json:"description"`
+	Status      string   `json:"status,omitempty"`
+	Priority    int      `json:"priority"`
+	DueInDays   *int     `json:"dueInDays,omitempty" binding:"omitempty,min=-3650,max=3650"`
+	DueTime     string   `json:"dueTime,omitempty" binding:"omitempty,datetime=15:04"`
+	Labels      []string `json:"labels,omitempty" binding:"max=20,dive,required,max=50"`
+	Role        string   `json:"role,omitempty" binding:"max=50"`
+	Parent      *int     `json:"parent,omitempty"`
+	BlockedBy   []int    `json:"blockedBy,omitempty"`
+}
+
+// CreateProjectTemplateInput saves either an existing project's live tasks,
+// named by ProjectID, or an explicit list of Tasks.
+type CreateProjectTemplateInput struct {
+	Name      string                `json:"name" binding:"required,max=100"`
+	ProjectID *string               `json:"projectId"`
+	Tasks     []ProjectTemplateTask `json:"tasks" binding:"dive"`
+}
+
+// UpdateProjectTemplateInput replaces the template's tasks when Tasks is
+// set.
+type UpdateProjectTemplateInput struct {
+	Name  *string               `json:"name" binding:"omitempty,min=1,max=100"`
+	Tasks []ProjectTemplateTask `json:"tasks" binding:"dive"`
+}
+
+// InstantiateProjectTemplateInput names the new project and maps the
+// template's roles to members; tasks of a role left out go to the caller.
+// StartDate is as in InstantiateTemplateInput.
+type InstantiateProjectTemplateInput struct {
+	Name      string            `json:"name" binding:"required"`
+	StartDate *string           `json:"startDate" binding:"omitempty,datetime=2006-01-02"`
+	Assignees map[string]string `json:"assignees"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// PushSubscription is a browser's Web Push endpoint and the keys used to
+// encrypt payloads for it.
+type PushSubscription struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"userId"`
+	Endpoint  string    `json:"endpoint"`
+	P256dh    string    `json:"-"`
+	Auth      string    `json:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CreatePushSubscriptionInput matches what PushSubscription.toJSON() returns
+// in the browser.
+type CreatePushSubscriptionInput struct {
+	Endpoint string `json:"endpoint" binding:"required,url"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys"`
+}
+
+type DeletePushSubscriptionInput struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+}
@@ -0,0 +1,21 @@
+package models
+
+type QuickAddInput struct {
+	Text string `json:"text" binding:"required"`
+}
+
+// QuickAddResult is how a quick-add line was read, for the client to show
+// before creating anything.
+type QuickAddResult struct {
+	// Task can be sent to POST /tasks as is.
+	Task CreateTaskInput `json:"task"`
+	// DueHasTime is false when only a day was given.
+	DueHasTime bool `json:"dueHasTime"`
+	// Labels are the org's labels the text named, to attach once the task
+	// exists.
+	Labels []Label `json:"labels"`
+	// UnknownLabels and UnknownPriority were named but don't exist in the
+	// org.
+	UnknownLabels   []string `json:"unknownLabels"`
+	UnknownPriority *string  `json:"unknownPriority"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Reminder fires either at RemindAt or MinutesBefore the task's due date.
+// Relative reminders follow the due date when it moves.
+type Reminder struct {
+	ID            string     `json:"id"`
+	TaskID        string     `json:"taskId"`
+	UserID        string     `json:"userId"`
+	RemindAt      *time.Time `json:"remindAt"`
+	MinutesBefore *int       `json:"minutesBefore"`
+	// FireAt is when the reminder will go off, nil for a relative reminder
+	// on a task without a due date.
+	FireAt    *time.Time `json:"fireAt"`
+	SentAt    *time.Time `json:"sentAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type CreateReminderInput struct {
+	RemindAt      *time.Time `json:"remindAt"`
+	MinutesBefore *int       `json:"minutesBefore"`
+}
+
+// DueReminder is a reminder claimed for delivery along with its task.
+type DueReminder struct {
+	Reminder Reminder
+	Task     Task
+}
@@ -0,0 +1,22 @@
+package models
+
+// Scope is who a request acts for. With an active organization everything is
+// shared across the org; without one, data is personal to the user.
+type Scope struct {
+	UserID string
+	OrgID  string
+	// Guest is set for external collaborators, who only see the tasks they
+	// own or that were shared with them, rather than everything the org can.
+	Guest bool
+}
+
+func (s Scope) IsOrg() bool {
+	return s.OrgID != ""
+}
+
+func (s Scope) OrgIDPtr() *string {
+	if s.OrgID == "" {
+		return nil
+	}
+	return &s.OrgID
+}
@@ -0,0 +1,19 @@
+package models
+
+type SearchResult struct {
+	Task    Task    `json:"task"`
+	Rank    float32 `json:"rank"`
+	Title   string  `json:"titleHighlight"`
+	Snippet string  `json:"snippet"`
+}
+
+// DuplicateSimilarity is how close, by trigram similarity from 0 to 1, an
+// open task's title must be to a new one's to be flagged as a likely
+// duplicate.
+const DuplicateSimilarity = 0.5
+
+// SimilarTask is an open task whose title is close to one being created.
+type SimilarTask struct {
+	Task       Task    `json:"task"`
+	Similarity float32 `json:"similarity"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// Share roles. Editors can change a private task; viewers can only read it.
+const (
+	ShareRoleViewer = "viewer"
+	ShareRoleEditor = "editor"
+)
+
+// Share grants one org member access to a private task, or to every private
+// task in a project. Exactly one of TaskID and ProjectID is set.
+type Share struct {
+	TaskID    *string   `json:"taskId,omitempty"`
+	ProjectID *string   `json:"projectId,omitempty"`
+	UserID    string    `json:"userId"`
+	Role      string    `json:"role"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type ShareInput struct {
+	UserID string `json:"userId" binding:"required"`
+	Role   string `json:"role" binding:"required"`
+}
+
+func ValidShareRole(role string) bool {
+	return role == ShareRoleViewer || role == ShareRoleEditor
+}
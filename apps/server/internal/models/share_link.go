@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+const (
+	DefaultShareLinkTTL = 7 * 24 * time.Hour
+	MaxShareLinkTTL     = 90 * 24 * time.Hour
+)
+
+// ShareLink is a public, read-only link to one task or project. Exactly one
+// of TaskID and ProjectID is set. URL is only filled in by handlers.
+type ShareLink struct {
+	ID        string     `json:"id"`
+	OrgID     *string    `json:"orgId"`
+	TaskID    *string    `json:"taskId,omitempty"`
+	ProjectID *string    `json:"projectId,omitempty"`
+	CreatedBy string     `json:"createdBy"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	URL       string     `json:"url,omitempty"`
+}
+
+// Scope is who the link reads as: its creator, in the org it was made in.
+func (l ShareLink) Scope() Scope {
+	s := Scope{UserID: l.CreatedBy}
+	if l.OrgID != nil {
+		s.OrgID = *l.OrgID
+	}
+	return s
+}
+
+// Active reports whether the link can still be opened at now.
+func (l ShareLink) Active(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// CreateShareLinkInput takes the task or project from the route.
+type CreateShareLinkInput struct {
+	TaskID    *string `json:"-"`
+	ProjectID *string `json:"-"`
+	// ExpiresAt defaults to DefaultShareLinkTTL from now.
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+type ShareLinkAccess struct {
+	ID         string    `json:"id"`
+	LinkID     string    `json:"linkId"`
+	IP         string    `json:"ip"`
+	UserAgent  string    `json:"userAgent"`
+	AccessedAt time.Time `json:"accessedAt"`
+}
+
+// PublicTask is what a share link shows of a task; who owns it and which
+// org it's in stay private.
+type PublicTask struct {
+	ID          string     `json:"id"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	DueDate     *time.Time `json:"dueDate"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func NewPublicTask(t Task) PublicTask {
+	return PublicTask{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+	}
+}
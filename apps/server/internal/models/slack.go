@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// SlackIntegration is an org's Slack workspace. Notifications are posted
+// with the bot token when there is one, and through the incoming webhook,
+// which is tied to its own channel, otherwise. Slash commands need the bot
+// token to tell who sent them.
+type SlackIntegration struct {
+	OrgID string `json:"orgId"`
+	// TeamID is the workspace whose /yata commands act in this org.
+	TeamID *string `json:"teamId"`
+	// A kind of notification is posted while its channel is set.
+	TaskAssignedChannel *string   `json:"taskAssignedChannel"`
+	DueSoonChannel      *string   `json:"dueSoonChannel"`
+	HasBotToken         bool      `json:"hasBotToken"`
+	HasWebhook          bool      `json:"hasWebhook"`
+	UpdatedAt           time.Time `json:"updatedAt"`
+
+	BotToken   string `json:"-"`
+	WebhookURL string `json:"-"`
+}
+
+// SetSlackIntegrationInput replaces the integration. The secrets can't be
+// read back, so leaving them out keeps the stored ones; null clears them.
+type SetSlackIntegrationInput struct {
+	// TeamID is filled in by the handler with the workspace the bot token
+	// belongs to, as Slack reports it.
+	TeamID              *string          `json:"-"`
+	TaskAssignedChannel *string          `json:"taskAssignedChannel"`
+	DueSoonChannel      *string          `json:"dueSoonChannel"`
+	BotToken            Nullable[string] `json:"botToken"`
+	WebhookURL          Nullable[string] `json:"webhookUrl"`
+}
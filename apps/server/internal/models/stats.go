@@ -0,0 +1,70 @@
+package models
+
+const (
+	StatsIntervalDay  = "day"
+	StatsIntervalWeek = "week"
+
+	// MaxStatsPeriods bounds how many periods one series covers.
+	MaxStatsPeriods = 366
+)
+
+// StatsCount is a count for one period of a series, named by the date the
+// period starts on in the requester's timezone.
+type StatsCount struct {
+	Date  string `json:"date"`
+	Count int    `json:"count"`
+}
+
+// StatsSeries is a count per day or week, oldest first.
+type StatsSeries struct {
+	Interval string       `json:"interval"`
+	Points   []StatsCount `json:"points"`
+}
+
+// MemberThroughput is how many of the tasks a member owns they completed in
+// a range. Members who completed none are left out.
+type MemberThroughput struct {
+	UserID    string `json:"userId"`
+	Completed int    `json:"completed"`
+	// AvgCycleSeconds is the mean time from creating a task to completing it.
+	AvgCycleSeconds int64 `json:"avgCycleSeconds"`
+}
+
+type ThroughputStats struct {
+	From    string             `json:"from"`
+	To      string             `json:"to"`
+	Members []MemberThroughput `json:"members"`
+}
+
+// BurndownPoint is where a project stood at the end of a period: the tasks
+// it had by then and how many of them were still open.
+type BurndownPoint struct {
+	Date      string `json:"date"`
+	Total     int    `json:"total"`
+	Remaining int    `json:"remaining"`
+}
+
+type BurndownStats struct {
+	Interval string          `json:"interval"`
+	Points   []BurndownPoint `json:"points"`
+}
+
+// MemberWorkload is what an org member has on their plate: the open tasks
+// they own that are due in a range, and every open one already past due.
+type MemberWorkload struct {
+	UserID string `json:"userId"`
+	Open   int    `json:"open"`
+	// EstimateMinutes sums the estimates of the open tasks; Unestimated
+	// counts those without one.
+	EstimateMinutes int64 `json:"estimateMinutes"`
+	Unestimated     int   `json:"unestimated"`
+	// Overdue isn't limited to the range, so tasks that slipped before it
+	// started still count against the member.
+	Overdue int `json:"overdue"`
+}
+
+type WorkloadStats struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	Members []MemberWorkload `json:"members"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// What a suggestion can be for.
+const (
+	SuggestLabel   = "label"
+	SuggestMember  = "member"
+	SuggestProject = "project"
+)
+
+// Suggestion is one match for a picker: a label, a member or a project, by
+// the name it's shown with. LastUsedAt is when it was last put on a task or,
+// for a member, last did something in the org; suggestions are ranked by it
+// after whole-name prefix matches.
+type Suggestion struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Color      string     `json:"color,omitempty"`
+	Email      *string    `json:"email,omitempty"`
+	ImageURL   *string    `json:"imageUrl,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import (
+	"encoding/json"
+	"yata/apps/server/internal/crdt"
+)
+
+const (
+	SyncOpSet    = "set"
+	SyncOpDelete = "delete"
+)
+
+// SyncFields are the task fields offline clients can set, by JSON name.
+var SyncFields = []string{"title", "description", "status", "priority", "dueDate", "dueTimezone", "projectId"}
+
+// SyncOp is one offline edit. A set on a task id the server hasn't seen
+// creates the task. Deleting a task is final: later sets on it lose.
+type SyncOp struct {
+	// ID is generated by the client so retried pushes aren't applied twice.
+	ID        string          `json:"id" binding:"required"`
+	TaskID    string          `json:"taskId" binding:"required"`
+	Type      string          `json:"type" binding:"required,oneof=set delete"`
+	Field     string          `json:"field,omitempty"`
+	Value     json.RawMessage `json:"value,omitempty"`
+	Timestamp crdt.Timestamp  `json:"timestamp"`
+	// Seq is the op's position in the server log, set on pulled ops.
+	Seq int64 `json:"seq,omitempty"`
+}
+
+const (
+	SyncApplied    = "applied"
+	SyncSuperseded = "superseded"
+	SyncRejected   = "rejected"
+)
+
+// SyncResult says what happened to a pushed op. Superseded ops lost to a
+// newer edit of the same field, or to the task being deleted.
+type SyncResult struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type SyncPushInput struct {
+	Ops []SyncOp `json:"ops" binding:"required,max=500,dive"`
+}
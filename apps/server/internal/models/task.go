@@ -0,0 +1,214 @@
+package models
+
+import "time"
+
+// Default statuses, used for personal tasks and orgs that haven't configured
+// their own.
+const (
+	TaskStatusTodo       = "todo"
+	TaskStatusInProgress = "in_progress"
+	TaskStatusDone       = "done"
+)
+
+// MaxEstimateMinutes caps a task's estimate at a year of working days.
+const MaxEstimateMinutes = 250 * 8 * 60
+
+func ValidEstimate(minutes int) bool {
+	return minutes >= 0 && minutes <= MaxEstimateMinutes
+}
+
+// maxRollUpDepth bounds how deep estimates are rolled up from, as it does
+// the queries that do it.
+const maxRollUpDepth = 50
+
+// RollUpEstimate totals the estimates of the subtasks of id, given tasks by
+// their parent's id: each subtask counts its own estimate or, without one,
+// its subtasks' total. It's nil when none of them has an estimate.
+func RollUpEstimate(children map[string][]Task, id string) *int {
+	return rollUpEstimate(children, id, 1)
+}
+
+func rollUpEstimate(children map[string][]Task, id string, depth int) *int {
+	var total *int
+	for _, c := range children[id] {
+		estimate := c.EstimateMinutes
+		if estimate == nil && depth < maxRollUpDepth {
+			estimate = rollUpEstimate(children, c.ID, depth+1)
+		}
+		if estimate == nil {
+			continue
+		}
+		if total == nil {
+			total = new(int)
+		}
+		*total += *estimate
+	}
+	return total
+}
+
+// Priorities are ranks, higher is more urgent. These are the defaults; orgs
+// may configure their own levels, see Workflow.
+const (
+	PriorityNone   = 0
+	PriorityLow    = 1
+	PriorityMedium = 2
+	PriorityHigh   = 3
+	PriorityUrgent = 4
+)
+
+type Task struct {
+	ID          string     `json:"id"`
+	OwnerID     string     `json:"ownerId"`
+	OrgID       *string    `json:"orgId"`
+	ProjectID   *string    `json:"projectId"`
+	ParentID    *string    `json:"parentId"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	DueDate     *time.Time `json:"dueDate"`
+	DueTimezone *string    `json:"dueTimezone"`
+	Recurrence  *string    `json:"recurrence"`
+	// EstimateMinutes is how long the task is expected to take, if anyone
+	// has said.
+	EstimateMinutes *int `json:"estimateMinutes"`
+	// Visibility is TaskVisibilityOrg or TaskVisibilityPrivate; private tasks
+	// are only visible to their owner and the members they're shared with.
+	Visibility string `json:"visibility"`
+	// Version goes up by one on every write; it backs the task's ETag.
+	Version int `json:"version"`
+	// Position is the task's key in the manual order of its org, or of its
+	// owner's personal tasks; see the ordering package.
+	Position string `json:"position"`
+	// ArchivedAt is set once the task is archived; archived tasks are left
+	// out of listings unless asked for.
+	ArchivedAt *time.Time `json:"archivedAt"`
+	// CompletedAt is when the task last moved into a done status; moving
+	// it out clears it.
+	CompletedAt *time.Time `json:"completedAt"`
+	// SnoozedUntil keeps the task out of today's lists, and holds back its
+	// reminders, until then.
+	SnoozedUntil *time.Time `json:"snoozedUntil"`
+	// CustomFields holds the task's values for its org's custom fields,
+	// keyed by field key.
+	CustomFields map[string]any `json:"customFields"`
+	// DeletedAt is set while the task is in the trash.
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+
+	// DueToday is computed per request in the requester's timezone.
+	DueToday bool `json:"dueToday"`
+	// DescriptionHTML is the description rendered from Markdown and
+	// sanitized, when the request asks for it.
+	DescriptionHTML *string `json:"descriptionHtml,omitempty"`
+	Labels          []Label `json:"labels,omitempty"`
+	// NextOccurrence is set on the response that completes a recurring task.
+	NextOccurrence *Task         `json:"nextOccurrence,omitempty"`
+	Progress       *TaskProgress `json:"progress,omitempty"`
+	// SubtaskEstimateTotal is set on the task's detail when its subtasks
+	// have estimates; see RollUpEstimate. Without an estimate of its own,
+	// it's the task's estimate in reports.
+	SubtaskEstimateTotal *int `json:"subtaskEstimateTotal,omitempty"`
+	*TaskDependencies
+}
+
+// TaskDependencies lists the ids of tasks that block this one and the ids of
+// tasks this one blocks.
+type TaskDependencies struct {
+	BlockedBy []string `json:"blockedBy"`
+	Blocks    []string `json:"blocks"`
+}
+
+type AddDependencyInput struct {
+	BlockedByID string `json:"blockedById" binding:"required"`
+}
+
+// TaskProgress rolls up completion over all descendants of a task.
+type TaskProgress struct {
+	Total   int `json:"total"`
+	Done    int `json:"done"`
+	Percent int `json:"percent"`
+}
+
+func NewTaskProgress(total, done int) TaskProgress {
+	p := TaskProgress{Total: total, Done: done}
+	if total > 0 {
+		p.Percent = done * 100 / total
+	}
+	return p
+}
+
+// TaskNode is a task with its subtasks nested under it.
+type TaskNode struct {
+	Task
+	Subtasks []*TaskNode `json:"subtasks"`
+}
+
+type CreateTaskInput struct {
+	Title       string     `json:"title" binding:"required"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    int        `json:"priority"`
+	DueDate     *time.Time `json:"dueDate"`
+	DueTimezone *string    `json:"dueTimezone"`
+	Recurrence  *string    `json:"recurrence"`
+	ProjectID   *string    `json:"projectId"`
+	ParentID    *string    `json:"parentId"`
+	// EstimateMinutes is between 0 and MaxEstimateMinutes.
+	EstimateMinutes *int `json:"estimateMinutes"`
+	// Visibility defaults to the org's default task visibility.
+	Visibility   string         `json:"visibility"`
+	CustomFields map[string]any `json:"customFields"`
+}
+
+// Snooze presets, worked out in the requester's zone: later_today is a few
+// hours from now, tomorrow and next_week the morning of that day, next_week
+// being the next first day of the week.
+const (
+	SnoozeLaterToday = "later_today"
+	SnoozeTomorrow   = "tomorrow"
+	SnoozeNextWeek   = "next_week"
+)
+
+// SnoozeTaskInput takes either a preset or a time to snooze until.
+type SnoozeTaskInput struct {
+	Preset string     `json:"preset"`
+	Until  *time.Time `json:"until"`
+}
+
+// ReorderTaskInput moves a task in the manual order to just after After and
+// before Before. With only one of them it goes right next to that task.
+type ReorderTaskInput struct {
+	After  *string `json:"after"`
+	Before *string `json:"before"`
+}
+
+// UpdateTaskInput only touches the fields present in the request body.
+type UpdateTaskInput struct {
+	Title       *string             `json:"title"`
+	Description *string             `json:"description"`
+	Status      *string             `json:"status"`
+	Priority    *int                `json:"priority"`
+	DueDate     Nullable[time.Time] `json:"dueDate"`
+	DueTimezone Nullable[string]    `json:"dueTimezone"`
+	Recurrence  Nullable[string]    `json:"recurrence"`
+	ProjectID   Nullable[string]    `json:"projectId"`
+	Visibility  *string             `json:"visibility"`
+	// EstimateMinutes is between 0 and MaxEstimateMinutes.
+	EstimateMinutes Nullable[int] `json:"estimateMinutes"`
+	// CustomFields sets the fields given and keeps the rest; a null value
+	// clears that field.
+	CustomFields map[string]any `json:"customFields"`
+
+	// IfVersion, when set, makes the update fail with a version mismatch
+	// unless the task is still at that version.
+	IfVersion *int `json:"-"`
+}
+
+// Empty reports whether the update has nothing to change.
+func (in UpdateTaskInput) Empty() bool {
+	return in.Title == nil && in.Description == nil && in.Status == nil && in.Priority == nil &&
+		!in.DueDate.Set && !in.DueTimezone.Set && !in.Recurrence.Set && !in.ProjectID.Set &&
+		in.Visibility == nil && !in.EstimateMinutes.Set && len(in.CustomFields) == 0
+}
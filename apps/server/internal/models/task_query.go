@@ -0,0 +1,119 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// TaskFilter is the parsed form of the task list query string.
+type TaskFilter struct {
+	ProjectID string
+	LabelID   string
+	OwnerID   string
+	// Visibility limits to tasks with that visibility, e.g. TaskVisibilityOrg.
+	Visibility string
+	// ParentID limits to direct subtasks of a task; TopLevel to tasks without a parent.
+	ParentID string
+	TopLevel bool
+	Statuses []string
+	// OpenOnly excludes tasks in any of DoneStatuses.
+	OpenOnly     bool
+	DoneStatuses []string
+	// Overdue keeps open tasks whose due date has passed.
+	Overdue   bool
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// HideSnoozed leaves out tasks snoozed until later than now.
+	HideSnoozed bool
+	Priority    *int
+	// Fields must all match; see FieldFilter.
+	Fields []FieldFilter
+	// IncludeArchived also returns archived tasks.
+	IncludeArchived bool
+	Sort            []SortField
+}
+
+var TaskSortFields = []string{"created_at", "updated_at", "due_date", "priority", "title", "status", "position"}
+
+var DefaultTaskSort = []SortField{{Field: "created_at", Desc: true}}
+
+// ParseSort parses "-due_date,priority" style sort specs against an allowlist.
+func ParseSort(raw string, allowed []string) ([]SortField, error) {
+	fields := []SortField{}
+	seen := map[string]bool{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		f := SortField{Field: part}
+		if strings.HasPrefix(part, "-") {
+			f = SortField{Field: part[1:], Desc: true}
+		}
+
+		ok := false
+		for _, a := range allowed {
+			if a == f.Field {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown sort field %q", f.Field)
+		}
+		if seen[f.Field] {
+			return nil, fmt.Errorf("duplicate sort field %q", f.Field)
+		}
+		seen[f.Field] = true
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+func SortString(fields []SortField) string {
+	parts := make([]string, len(fields))
+	for i, f := range fields {
+		parts[i] = f.Field
+		if f.Desc {
+			parts[i] = "-" + f.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// TaskSortValue is the cursor representation of t's value for a sort field.
+// Missing due dates sort last in either direction, matching the repository.
+func TaskSortValue(t *Task, f SortField) string {
+	switch f.Field {
+	case "created_at":
+		return t.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		return t.UpdatedAt.Format(time.RFC3339Nano)
+	case "due_date":
+		if t.DueDate == nil {
+			if f.Desc {
+				return "-infinity"
+			}
+			return "infinity"
+		}
+		return t.DueDate.Format(time.RFC3339Nano)
+	case "priority":
+		return strconv.Itoa(t.Priority)
+	case "title":
+		return t.Title
+	case "status":
+		return t.Status
+	case "position":
+		return t.Position
+	}
+	return ""
+}
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// TelegramLink is a user's chat with the bot. Reminders go to it wherever
+// they come from; tasks sent to the bot land in the scope linked from.
+type TelegramLink struct {
+	UserID    string    `json:"userId"`
+	OrgID     *string   `json:"orgId"`
+	Username  *string   `json:"username"`
+	CreatedAt time.Time `json:"createdAt"`
+
+	ChatID int64 `json:"-"`
+}
+
+// Scope is who messages to the bot act as, before the org role is looked up.
+func (l TelegramLink) Scope() Scope {
+	s := Scope{UserID: l.UserID}
+	if l.OrgID != nil {
+		s.OrgID = *l.OrgID
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+package models
+
+import "time"
+
+const (
+	// MaxTemplateTasks caps the tasks in a template, counting checklist
+	// items, which become subtasks.
+	MaxTemplateTasks = 200
+	// MaxTemplateDepth caps how deep a template's subtasks nest.
+	MaxTemplateDepth = 10
+)
+
+// TaskTemplate is a saved task tree that can be instantiated as new tasks.
+// Org templates are visible to the whole org, but only their owner can
+// change them.
+type TaskTemplate struct {
+	ID        string       `json:"id"`
+	OwnerID   string       `json:"ownerId"`
+	OrgID     *string      `json:"orgId"`
+	Name      string       `json:"name"`
+	Task      TemplateTask `json:"task"`
+	CreatedAt time.Time    `json:"createdAt"`
+	UpdatedAt time.Time    `json:"updatedAt"`
+}
+
+// TemplateTask is one task of a template. Due dates are relative:
+// DueInDays counts days from the date the template is instantiated for,
+// and DueTime ("15:04") is the time on that day, midnight if empty.
+// Checklist items become subtasks without details of their own.
+type TemplateTask struct {
+	Title       string         `json:"title" binding:"required,max=500"`
+	Description string         `json:"description"`
+	Status      string         `json:"status,omitempty"`
+	Priority    int            `json:"priority"`
+	DueInDays   *int           `json:"dueInDays,omitempty" binding:"omitempty,min=-3650,max=3650"`
+	DueTime     string         `json:"dueTime,omitempty" binding:"omitempty,datetime=15:04"`
+	LabelIDs    []string       `json:"labelIds,omitempty"`
+	Checklist   []string       `json:"checklist,omitempty" binding:"dive,required,max=500"`
+	Subtasks    []TemplateTask `json:"subtasks,omitempty" binding:"dive"`
+}
+
+// Count returns the number of tasks t makes and how deep they nest, with
+// t alone at depth 1.
+func (t TemplateTask) Count() (tasks, depth int) {
+	tasks = 1 + len(t.Checklist)
+	depth = 1
+	if len(t.Checklist) > 0 {
+		depth = 2
+	}
+	for _, sub := range t.Subtasks {
+		n, d := sub.Count()
+		tasks += n
+		depth = max(depth, d+1)
+	}
+	return tasks, depth
+}
+
+// CreateTemplateInput saves either the tree under an existing task, named
+// by TaskID, or an explicit Task.
+type CreateTemplateInput struct {
+	Name   string        `json:"name" binding:"required,max=100"`
+	TaskID *string       `json:"taskId"`
+	Task   *TemplateTask `json:"task"`
+}
+
+type UpdateTemplateInput struct {
+	Name *string       `json:"name" binding:"omitempty,min=1,max=100"`
+	Task *TemplateTask `json:"task"`
+}
+
+// InstantiateTemplateInput places the new tasks. StartDate ("2006-01-02")
+// is what due dates count from, today in the request's timezone if unset;
+// Title overrides the root task's.
+type InstantiateTemplateInput struct {
+	StartDate *string `json:"startDate" binding:"omitempty,datetime=2006-01-02"`
+	ProjectID *string `json:"projectId"`
+	Title     *string `json:"title" binding:"omitempty,min=1,max=500"`
+}
+
+// NewTask is one task of a batch. Parent is the index of an earlier task in
+// the batch to nest it under, or -1 for none, and BlockedBy the indexes of
+// the tasks blocking it. OwnerID defaults to the caller. LabelIDs that
+// aren't labels of the org are skipped.
+type NewTask struct {
+	Input     CreateTaskInput
+	Parent    int
+	OwnerID   string
+	LabelIDs  []string
+	BlockedBy []int
+}
@@ -0,0 +1,82 @@
+package models
+
+import "time"
+
+const MaxTimeEntryNoteLength = 1000
+
+// TimeEntry is time a user logged against a task. Anyone who can see the
+// task sees its entries; only the user who logged one can change it.
+type TimeEntry struct {
+	ID        string    `json:"id"`
+	TaskID    string    `json:"taskId"`
+	UserID    string    `json:"userId"`
+	StartedAt time.Time `json:"startedAt"`
+	// EndedAt is nil while the timer is running.
+	EndedAt *time.Time `json:"endedAt"`
+	// Seconds runs up to now for a running timer.
+	Seconds   int64     `json:"seconds"`
+	Note      string    `json:"note"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// SetSeconds fills in Seconds, counting a running timer up to now.
+func (e *TimeEntry) SetSeconds(now time.Time) {
+	end := now
+	if e.EndedAt != nil {
+		end = *e.EndedAt
+	}
+	e.Seconds = max(int64(end.Sub(e.StartedAt)/time.Second), 0)
+}
+
+type StartTimerInput struct {
+	Note string `json:"note"`
+}
+
+// CreateTimeEntryInput logs time by hand; the entry has to have ended.
+type CreateTimeEntryInput struct {
+	StartedAt time.Time `json:"startedAt" binding:"required"`
+	EndedAt   time.Time `json:"endedAt" binding:"required"`
+	Note      string    `json:"note"`
+}
+
+// UpdateTimeEntryInput changes the fields that are set. A running timer's
+// EndedAt can't be set this way; it's stopped instead.
+type UpdateTimeEntryInput struct {
+	StartedAt *time.Time `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+	Note      *string    `json:"note"`
+}
+
+// TimeTotal is the time logged by one user or on one task.
+type TimeTotal struct {
+	ID string `json:"id"`
+	// Title is the task's, in totals by task.
+	Title   string `json:"title,omitempty"`
+	Seconds int64  `json:"seconds"`
+}
+
+// TimeTotals sums up the time logged on a task or project, running timers
+// included up to now.
+type TimeTotals struct {
+	Seconds int64       `json:"seconds"`
+	ByUser  []TimeTotal `json:"byUser"`
+	// ByTask is left out of a single task's totals.
+	ByTask []TimeTotal `json:"byTask,omitempty"`
+}
+
+// TimeEntryFilter narrows an export of time entries. Entries are picked by
+// when they started, From inclusive and To exclusive.
+type TimeEntryFilter struct {
+	From      time.Time
+	To        time.Time
+	ProjectID *string
+	UserID    *string
+}
+
+// ExportedTimeEntry is a finished time entry with its task filled in.
+type ExportedTimeEntry struct {
+	TimeEntry
+	TaskTitle string  `json:"taskTitle"`
+	ProjectID *string `json:"projectId"`
+}
@@ -0,0 +1,46 @@
+package models
+
+import "time"
+
+// Usage is what an org holds against its quotas: live tasks, bytes of
+// attachments, uploads still pending included, and webhook endpoints.
+type Usage struct {
+	Tasks            int64
+	AttachmentBytes  int64
+	WebhookEndpoints int64
+}
+
+// UsageItem is one quota, with a null Limit when there isn't one.
+type UsageItem struct {
+	Used  int64  `json:"used"`
+	Limit *int64 `json:"limit"`
+}
+
+type OrgUsage struct {
+	Tasks            UsageItem `json:"tasks"`
+	AttachmentBytes  UsageItem `json:"attachmentBytes"`
+	WebhookEndpoints UsageItem `json:"webhookEndpoints"`
+}
+
+// RateLimitStatus is the caller's window under the org's API rate limit,
+// with the request that asked counted.
+type RateLimitStatus struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// OrgLimits is where an org stands against every limit it's held to.
+type OrgLimits struct {
+	// RateLimit is the org's own limit where it has one, and null when the
+	// request couldn't be counted.
+	RateLimit *RateLimitStatus `json:"rateLimit"`
+	Storage   UsageItem        `json:"storage"`
+	Caps      OrgCaps          `json:"caps"`
+}
+
+// OrgCaps are the plan's limits on how many of each thing an org keeps.
+type OrgCaps struct {
+	Tasks            UsageItem `json:"tasks"`
+	WebhookEndpoints UsageItem `json:"webhookEndpoints"`
+}
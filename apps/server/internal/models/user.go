@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// User is the local copy of a Clerk user. UpdatedAt is Clerk's timestamp,
+// not when the row was written.
+type User struct {
+	ID        string     `json:"id"`
+	Email     *string    `json:"email"`
+	FirstName *string    `json:"firstName"`
+	LastName  *string    `json:"lastName"`
+	Username  *string    `json:"username"`
+	ImageURL  *string    `json:"imageUrl"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// Organization is the local copy of a Clerk organization.
+type Organization struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Slug     *string `json:"slug"`
+	ImageURL *string `json:"imageUrl"`
+	// Status is OrgStatusActive or OrgStatusSuspended. Upserts leave it
+	// as it was when it's empty.
+	Status    string     `json:"status"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+	DeletedAt *time.Time `json:"deletedAt,omitempty"`
+}
+
+// Suspended orgs can read and export their data but not change it.
+const (
+	OrgStatusActive    = "active"
+	OrgStatusSuspended = "suspended"
+)
+
+func ValidOrgStatus(s string) bool {
+	return s == OrgStatusActive || s == OrgStatusSuspended
+}
+
+type OrgMembership struct {
+	OrgID     string    `json:"orgId"`
+	UserID    string    `json:"userId"`
+	Role      string    `json:"role"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// OrgMember is a user along with their role in the org. Members whose user
+// event hasn't arrived yet have only their id filled in.
+type OrgMember struct {
+	User
+	Role string `json:"role"`
+}
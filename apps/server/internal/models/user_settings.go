@@ -0,0 +1,155 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+var (
+	WeekStarts        = []string{"monday", "sunday", "saturday"}
+	DigestFrequencies = []string{"off", "daily", "weekly"}
+)
+
+// NotificationChannels turns whole delivery channels on or off. The in-app
+// inbox is always on; EmailPreferences still picks which kinds get email.
+type NotificationChannels struct {
+	Email bool `json:"email"`
+	Push  bool `json:"push"`
+	// Telegram only matters once the user linked a chat.
+	Telegram bool `json:"telegram"`
+}
+
+type UserSettings struct {
+	UserID string `json:"userId"`
+	// Timezone is an IANA zone used to show times in emails and digests.
+	Timezone *string `json:"timezone"`
+	// Locale is the language of emails, digests and API errors. Unset, API
+	// errors follow Accept-Language and everything else is in English.
+	Locale               *string              `json:"locale"`
+	WeekStart            string               `json:"weekStart"`
+	DefaultProjectID     *string              `json:"defaultProjectId"`
+	NotificationChannels NotificationChannels `json:"notificationChannels"`
+	DigestFrequency      string               `json:"digestFrequency"`
+	// DigestTime is when digests go out, as HH:MM in Timezone. Weekly ones
+	// go out on the first day of the week.
+	DigestTime string    `json:"digestTime"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+func DefaultUserSettings(userID string) UserSettings {
+	return UserSettings{
+		UserID:               userID,
+		WeekStart:            "monday",
+		NotificationChannels: NotificationChannels{Email: true, Push: true, Telegram: true},
+		DigestFrequency:      "off",
+		DigestTime:           "08:00",
+	}
+}
+
+// Location is the user's zone, or UTC when they haven't set a valid one.
+func (s UserSettings) Location() *time.Location {
+	if s.Timezone != nil && *s.Timezone != "" {
+		if loc, err := time.LoadLocation(*s.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// PreferredLocale is the locale the user picked, or "" if they haven't.
+func (s UserSettings) PreferredLocale() string {
+	if s.Locale == nil {
+		return ""
+	}
+	return *s.Locale
+}
+
+// WeekStartDay is the day the user's weeks start on.
+func (s UserSettings) WeekStartDay() time.Weekday {
+	switch s.WeekStart {
+	case "sunday":
+		return time.Sunday
+	case "saturday":
+		return time.Saturday
+	}
+	return time.Monday
+}
+
+type UpdateUserSettingsInput struct {
+	Timezone             Nullable[string] `json:"timezone"`
+	Locale               Nullable[string] `json:"locale"`
+	WeekStart            *string          `json:"weekStart"`
+	DefaultProjectID     Nullable[string] `json:"defaultProjectId"`
+	NotificationChannels *struct {
+		Email    *bool `json:"email"`
+		Push     *bool `json:"push"`
+		Telegram *bool `json:"telegram"`
+	} `json:"notificationChannels"`
+	DigestFrequency *string `json:"digestFrequency"`
+	DigestTime      *string `json:"digestTime"`
+}
+
+// Apply returns s with the fields set in input replaced.
+func (input UpdateUserSettingsInput) Apply(s UserSettings) UserSettings {
+	if input.Timezone.Set {
+		s.Timezone = input.Timezone.Ptr()
+	}
+	if input.Locale.Set {
+		s.Locale = input.Locale.Ptr()
+	}
+	if input.WeekStart != nil {
+		s.WeekStart = *input.WeekStart
+	}
+	if input.DefaultProjectID.Set {
+		s.DefaultProjectID = input.DefaultProjectID.Ptr()
+	}
+	if ch := input.NotificationChannels; ch != nil {
+		if ch.Email != nil {
+			s.NotificationChannels.Email = *ch.Email
+		}
+		if ch.Push != nil {
+			s.NotificationChannels.Push = *ch.Push
+		}
+		if ch.Telegram != nil {
+			s.NotificationChannels.Telegram = *ch.Telegram
+		}
+	}
+	if input.DigestFrequency != nil {
+		s.DigestFrequency = *input.DigestFrequency
+	}
+	if input.DigestTime != nil {
+		s.DigestTime = *input.DigestTime
+	}
+	return s
+}
+
+func ValidWeekStart(v string) bool       { return slices.Contains(WeekStarts, v) }
+func ValidDigestFrequency(v string) bool { return slices.Contains(DigestFrequencies, v) }
+
+func ValidDigestTime(v string) bool {
+	_, err := time.Parse("15:04", v)
+	return err == nil && len(v) == len("15:04")
+}
+
+// LastDigestSlot is the most recent time at or before now that the user's
+// digest was scheduled for, in their zone: today's DigestTime for daily
+// digests, and that time on the latest first day of the week for weekly
+// ones. ok is false when they don't get digests.
+func (s UserSettings) LastDigestSlot(now time.Time) (slot time.Time, ok bool) {
+	at, err := time.Parse("15:04", s.DigestTime)
+	if err != nil || (s.DigestFrequency != "daily" && s.DigestFrequency != "weekly") {
+		return time.Time{}, false
+	}
+
+	local := now.In(s.Location())
+	slot = time.Date(local.Year(), local.Month(), local.Day(), at.Hour(), at.Minute(), 0, 0, local.Location())
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if s.DigestFrequency == "weekly" {
+		for slot.Weekday() != s.WeekStartDay() {
+			slot = slot.AddDate(0, 0, -1)
+		}
+	}
+	return slot, true
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// View is a saved task filter. Query is in the view language (see
+// handlers.parseViewQuery), e.g. "is:overdue & assignee:me & #urgent", and
+// Sort is a list sort such as "-due_date,priority". A shared view is visible
+// to the whole org, but only its owner can change it.
+type View struct {
+	ID        string    `json:"id"`
+	OwnerID   string    `json:"ownerId"`
+	OrgID     *string   `json:"orgId"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Sort      string    `json:"sort"`
+	Shared    bool      `json:"shared"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type CreateViewInput struct {
+	Name   string `json:"name" binding:"required,max=100"`
+	Query  string `json:"query" binding:"max=500"`
+	Sort   string `json:"sort" binding:"max=200"`
+	Shared bool   `json:"shared"`
+}
+
+type UpdateViewInput struct {
+	Name   *string `json:"name" binding:"omitempty,min=1,max=100"`
+	Query  *string `json:"query" binding:"omitempty,max=500"`
+	Sort   *string `json:"sort" binding:"omitempty,max=200"`
+	Shared *bool   `json:"shared"`
+}
@@ -0,0 +1,169 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"slices"
+	"time"
+)
+
+// WebhookEvents are the outbox events an org can subscribe a webhook
+// endpoint to.
+var WebhookEvents = []string{EventTaskCreated, EventTaskCompleted, EventCommentAdded}
+
+const MaxWebhookEndpoints = 20
+
+// CheckWebhookEvents accepts a non-empty list of known events.
+func CheckWebhookEvents(events []string) error {
+	if len(events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, e := range events {
+		if !slices.Contains(WebhookEvents, e) {
+			return errors.New("unknown event: " + e)
+		}
+	}
+	return nil
+}
+
+// CheckWebhookURL accepts absolute https URLs without credentials. Where
+// the host resolves to is checked when delivering.
+func CheckWebhookURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" || u.User != nil || len(raw) > 2000 {
+		return errors.New("url must be an https URL")
+	}
+	return nil
+}
+
+// WebhookEndpoint is a URL an org has asked to be sent events. Secret signs
+// each delivery; it's only filled in the response that creates the
+// endpoint, and when delivering.
+type WebhookEndpoint struct {
+	ID        string    `json:"id"`
+	OrgID     string    `json:"orgId"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Active    bool      `json:"active"`
+	CreatedBy string    `json:"createdBy"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Secret    string    `json:"secret,omitempty"`
+}
+
+func (e WebhookEndpoint) Subscribed(event string) bool {
+	return e.Active && slices.Contains(e.Events, event)
+}
+
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required"`
+	Events []string `json:"events" binding:"required"`
+
+	Secret string `json:"-"`
+}
+
+// UpdateWebhookInput can't change the secret; replace the endpoint to
+// rotate it.
+type UpdateWebhookInput struct {
+	URL    *string   `json:"url"`
+	Events *[]string `json:"events"`
+	Active *bool     `json:"active"`
+}
+
+// WebhookEvent is the body POSTed to an endpoint. ID is the same on every
+// attempt at delivering it, so receivers can drop duplicates.
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	OrgID     string          `json:"orgId"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// WebhookDelivery is one attempt at delivering an event, kept so orgs can
+// see what their endpoint was sent and how it answered. StatusCode is nil
+// when no response came back, and Error says why.
+type WebhookDelivery struct {
+	ID         string          `json:"id"`
+	EndpointID string          `json:"endpointId"`
+	EventID    string          `json:"eventId"`
+	Event      string          `json:"event"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempt    int             `json:"attempt"`
+	StatusCode *int            `json:"statusCode"`
+	Error      *string         `json:"error"`
+	DurationMs int             `json:"durationMs"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+func (d WebhookDelivery) Succeeded() bool {
+	return d.StatusCode != nil && *d.StatusCode >= 200 && *d.StatusCode < 300
+}
+
+// Breaker states. A closed breaker lets deliveries through. An open one
+// holds them until its cooldown is over, when it's half open and lets one
+// delivery through to probe the URL: success closes it, failure opens it
+// again for twice as long.
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// WebhookBreaker is the circuit breaker on an org's deliveries to one URL,
+// so an endpoint that keeps failing doesn't hold up the others. Failures
+// counts failed deliveries since the last success and Trips how often it
+// has opened since then.
+type WebhookBreaker struct {
+	URL       string     `json:"url"`
+	State     string     `json:"state"`
+	Failures  int        `json:"failures"`
+	Trips     int        `json:"trips"`
+	OpenUntil *time.Time `json:"openUntil"`
+
+	// Probing is set while a delivery probes the half-open breaker.
+	Probing bool `json:"-"`
+}
+
+// WebhookBreakerPolicy opens a breaker after Threshold failures in a row,
+// for Cooldown the first time and twice as long each time after, up to
+// MaxCooldown.
+type WebhookBreakerPolicy struct {
+	Threshold   int
+	Cooldown    time.Duration
+	MaxCooldown time.Duration
+}
+
+// SetState works out the breaker's state at now.
+func (b *WebhookBreaker) SetState(now time.Time) {
+	switch {
+	case b.OpenUntil == nil:
+		b.State = BreakerClosed
+	case b.Probing || !b.OpenUntil.After(now):
+		b.State = BreakerHalfOpen
+	default:
+		b.State = BreakerOpen
+	}
+}
+
+// Fail counts a failed delivery at now, opening the breaker when it's a
+// failed probe or the failure that reaches p.Threshold. Deliveries that were
+// already under way when it opened don't open it again.
+func (b *WebhookBreaker) Fail(now time.Time, p WebhookBreakerPolicy) {
+	b.Failures++
+	if !b.Probing && (b.OpenUntil != nil || b.Failures < p.Threshold) {
+		b.SetState(now)
+		return
+	}
+
+	cooldown := p.Cooldown
+	for i := 0; i < b.Trips && cooldown < p.MaxCooldown; i++ {
+		cooldown *= 2
+	}
+	until := now.Add(min(cooldown, p.MaxCooldown))
+	b.OpenUntil = &until
+	b.Trips++
+	b.Probing = false
+	b.SetState(now)
+}
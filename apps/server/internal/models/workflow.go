@@ -0,0 +1,183 @@
+package models
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+// StatusDefinition is one column of an org's workflow. Key is what's stored
+// on tasks; Done marks statuses that count as complete.
+type StatusDefinition struct {
+	Key   string `json:"key"`
+	Name  string `json:"name"`
+	Order int    `json:"order"`
+	Done  bool   `json:"done"`
+}
+
+type PriorityLevel struct {
+	Level int    `json:"level"`
+	Name  string `json:"name"`
+}
+
+// Workflow is the set of statuses and priorities tasks are validated against.
+type Workflow struct {
+	Statuses   []StatusDefinition `json:"statuses"`
+	Priorities []PriorityLevel    `json:"priorities"`
+}
+
+func DefaultStatuses() []StatusDefinition {
+	return []StatusDefinition{
+		{Key: TaskStatusTodo, Name: "To do", Order: 0},
+		{Key: TaskStatusInProgress, Name: "In progress", Order: 1},
+		{Key: TaskStatusDone, Name: "Done", Order: 2, Done: true},
+	}
+}
+
+func DefaultPriorities() []PriorityLevel {
+	return []PriorityLevel{
+		{Level: PriorityNone, Name: "None"},
+		{Level: PriorityLow, Name: "Low"},
+		{Level: PriorityMedium, Name: "Medium"},
+		{Level: PriorityHigh, Name: "High"},
+		{Level: PriorityUrgent, Name: "Urgent"},
+	}
+}
+
+func DefaultWorkflow() Workflow {
+	return Workflow{Statuses: DefaultStatuses(), Priorities: DefaultPriorities()}
+}
+
+func (w Workflow) IsValidStatus(key string) bool {
+	for _, s := range w.Statuses {
+		if s.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+func (w Workflow) IsDone(key string) bool {
+	for _, s := range w.Statuses {
+		if s.Key == key {
+			return s.Done
+		}
+	}
+	return false
+}
+
+func (w Workflow) DoneStatuses() []string {
+	done := []string{}
+	for _, s := range w.Statuses {
+		if s.Done {
+			done = append(done, s.Key)
+		}
+	}
+	return done
+}
+
+// CompletedStatus is what completing a task moves it to: the first done
+// status, or "" when the workflow has none.
+func (w Workflow) CompletedStatus() string {
+	for _, s := range w.Statuses {
+		if s.Done {
+			return s.Key
+		}
+	}
+	return ""
+}
+
+// DefaultStatus is what new tasks start in: the first status that isn't done.
+func (w Workflow) DefaultStatus() string {
+	for _, s := range w.Statuses {
+		if !s.Done {
+			return s.Key
+		}
+	}
+	return w.Statuses[0].Key
+}
+
+func (w Workflow) IsValidPriority(level int) bool {
+	for _, p := range w.Priorities {
+		if p.Level == level {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	maxStatuses   = 20
+	maxPriorities = 10
+	maxNameLength = 50
+)
+
+var statusKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,32}$`)
+
+// NormalizeStatuses validates an org's status list and sorts it by Order.
+func NormalizeStatuses(statuses []StatusDefinition) ([]StatusDefinition, error) {
+	if len(statuses) == 0 || len(statuses) > maxStatuses {
+		return nil, fmt.Errorf("between 1 and %d statuses are required", maxStatuses)
+	}
+
+	seen := map[string]bool{}
+	hasOpen, hasDone := false, false
+	for _, s := range statuses {
+		if !statusKeyPattern.MatchString(s.Key) {
+			return nil, fmt.Errorf("invalid status key %q", s.Key)
+		}
+		if seen[s.Key] {
+			return nil, fmt.Errorf("duplicate status key %q", s.Key)
+		}
+		if s.Name == "" || len(s.Name) > maxNameLength {
+			return nil, fmt.Errorf("invalid name for status %q", s.Key)
+		}
+		seen[s.Key] = true
+		hasDone = hasDone || s.Done
+		hasOpen = hasOpen || !s.Done
+	}
+	if !hasOpen || !hasDone {
+		return nil, fmt.Errorf("at least one open and one done status are required")
+	}
+
+	sorted := append([]StatusDefinition(nil), statuses...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Order < sorted[j].Order })
+	return sorted, nil
+}
+
+// NormalizePriorities validates an org's priority levels and sorts them from
+// least to most urgent. Level 0 must exist since it's the default for new tasks.
+func NormalizePriorities(priorities []PriorityLevel) ([]PriorityLevel, error) {
+	if len(priorities) == 0 || len(priorities) > maxPriorities {
+		return nil, fmt.Errorf("between 1 and %d priority levels are required", maxPriorities)
+	}
+
+	seen := map[int]bool{}
+	for _, p := range priorities {
+		if p.Level < 0 || p.Level > 100 {
+			return nil, fmt.Errorf("invalid priority level %d", p.Level)
+		}
+		if seen[p.Level] {
+			return nil, fmt.Errorf("duplicate priority level %d", p.Level)
+		}
+		if p.Name == "" || len(p.Name) > maxNameLength {
+			return nil, fmt.Errorf("invalid name for priority level %d", p.Level)
+		}
+		seen[p.Level] = true
+	}
+	if !seen[PriorityNone] {
+		return nil, fmt.Errorf("priority level 0 is required")
+	}
+
+	sorted := append([]PriorityLevel(nil), priorities...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Level < sorted[j].Level })
+	return sorted, nil
+}
+
+type SetStatusesInput struct {
+	Statuses []StatusDefinition `json:"statuses" binding:"required"`
+}
+
+type SetPrioritiesInput struct {
+	Priorities []PriorityLevel `json:"priorities" binding:"required"`
+}
@@ -0,0 +1,55 @@
+// Package notify delivers user-facing notifications. Senders build a
+// Notification and hand it to a Notifier; which channels it reaches depends
+// on the Notifier wired up in main.
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	KindReminder       = "reminder"
+	KindTaskAssigned   = "task_assigned"
+	KindCommentMention = "comment_mention"
+)
+
+type Notification struct {
+	Kind    string     `json:"kind"`
+	UserID  string     `json:"userId"`
+	OrgID   *string    `json:"orgId"`
+	TaskID  string     `json:"taskId"`
+	Title   string     `json:"title"`
+	Body    string     `json:"body"`
+	DueDate *time.Time `json:"dueDate,omitempty"`
+	// ActorID is the user whose action caused the notification, if any.
+	ActorID string `json:"actorId,omitempty"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the log. It's the default until a
+// real delivery channel is configured.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(_ context.Context, n Notification) error {
+	slog.Info("Notification", "kind", n.Kind, "user_id", n.UserID, "task_id", n.TaskID, "title", n.Title)
+	return nil
+}
+
+// Multi fans a notification out to several notifiers, returning the first
+// error after trying all of them.
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, n Notification) error {
+	var first error
+	for _, next := range m {
+		if err := next.Notify(ctx, n); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
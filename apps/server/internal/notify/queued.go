@@ -0,0 +1,31 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"yata/apps/server/internal/jobs"
+)
+
+// JobKind is the job that carries a Notification to the real Notifier.
+const JobKind = "notify"
+
+// QueuedNotifier defers delivery to the job queue, so a failing channel is
+// retried instead of dropped.
+type QueuedNotifier struct {
+	Queue jobs.Queue
+}
+
+func (q QueuedNotifier) Notify(ctx context.Context, n Notification) error {
+	return q.Queue.Enqueue(ctx, JobKind, n)
+}
+
+// JobHandler delivers queued notifications through next.
+func JobHandler(next Notifier) jobs.Handler {
+	return func(ctx context.Context, payload json.RawMessage) error {
+		var n Notification
+		if err := json.Unmarshal(payload, &n); err != nil {
+			return err
+		}
+		return next.Notify(ctx, n)
+	}
+}
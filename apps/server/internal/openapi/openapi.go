@@ -0,0 +1,191 @@
+// Package openapi builds an OpenAPI 3.0 document from the router's routes
+// and a registry of typed operation descriptions, so the document can't
+// list a route the server doesn't have.
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Op describes one route. Request and Response are sample values of the
+// body types, e.g. models.CreateTaskInput{}; nil means no body.
+type Op struct {
+	Summary string
+	Tag     string
+	Query   []string
+	Request any
+	// Response is the success body, sent with Status (200 when unset).
+	Response any
+	Status   int
+	// Conditional routes send an ETag and answer a matching If-None-Match
+	// with 304 Not Modified.
+	Conditional bool
+}
+
+// Ops maps "METHOD /path" as registered with gin to its description.
+type Ops map[string]Op
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description,omitempty"`
+}
+
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+const bearerAuth = "bearerAuth"
+
+// errorBody is the envelope every failing status is sent with.
+type errorBody struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id"`
+}
+
+// Build documents the routes under prefix. Routes without an entry in ops
+// are still listed, with their parameters but no body types.
+func Build(info Info, routes gin.RoutesInfo, prefix string, ops Ops) *Document {
+	s := newSchemas()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    info,
+		Paths:   map[string]map[string]*Operation{},
+		Components: Components{
+			Schemas: s.components,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", Description: "A Clerk session token, an API token, or a service token for /service routes."},
+			},
+		},
+	}
+	errorSchema := s.schemaFor(reflect.TypeFor[errorBody]())
+
+	for _, route := range routes {
+		if !strings.HasPrefix(route.Path, prefix) {
+			continue
+		}
+		path, params := pathTemplate(route.Path)
+		spec := ops[route.Method+" "+route.Path]
+
+		op := &Operation{
+			Summary:     spec.Summary,
+			OperationID: operationID(route.Method, strings.TrimPrefix(route.Path, prefix)),
+			Parameters:  params,
+			Responses:   map[string]Response{},
+			Security:    []map[string][]string{{bearerAuth: {}}},
+		}
+		if spec.Tag != "" {
+			op.Tags = []string{spec.Tag}
+		}
+		for _, name := range spec.Query {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "query", Schema: &Schema{Type: "string"}})
+		}
+		if spec.Request != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: jsonContent(s.schemaFor(reflect.TypeOf(spec.Request)))}
+		}
+
+		status := spec.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		if spec.Response != nil {
+			success.Content = jsonContent(s.schemaFor(reflect.TypeOf(spec.Response)))
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		op.Responses["default"] = Response{Description: "Error", Content: jsonContent(errorSchema)}
+		if spec.Conditional {
+			op.Parameters = append(op.Parameters, Parameter{Name: "If-None-Match", In: "header", Schema: &Schema{Type: "string"}})
+			op.Responses[strconv.Itoa(http.StatusNotModified)] = Response{Description: http.StatusText(http.StatusNotModified)}
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = map[string]*Operation{}
+		}
+		doc.Paths[path][strings.ToLower(route.Method)] = op
+	}
+	return doc
+}
+
+func jsonContent(schema *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: schema}}
+}
+
+// pathTemplate turns gin's /tasks/:id and /files/*name into OpenAPI's
+// /tasks/{id} and /files/{name}.
+func pathTemplate(route string) (string, []Parameter) {
+	var params []Parameter
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if segment == "" || segment[0] != ':' && segment[0] != '*' {
+			continue
+		}
+		name := segment[1:]
+		segments[i] = "{" + name + "}"
+		params = append(params, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// operationID derives a stable id like getTasksIdComments from the route.
+func operationID(method, route string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, word := range strings.FieldsFunc(route, func(r rune) bool {
+		return r == '/' || r == ':' || r == '*' || r == '-' || r == '{' || r == '}'
+	}) {
+		b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	return b.String()
+}
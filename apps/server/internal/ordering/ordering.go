@@ -0,0 +1,217 @@
+// Package ordering generates sort keys for manually ordered lists. A key
+// sorts byte-wise, and there is always another key between any two, so an
+// item can be moved by changing only its own key.
+//
+// Keys are an integer part followed by a fraction, in base 62. The first
+// character of the integer part gives its length, which keeps keys short
+// when items are only ever added at the ends.
+package ordering
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// First is the key of an item in an otherwise empty list.
+const First = "a0"
+
+// smallestInteger is the lowest integer part. Nothing can go below a key
+// made of it alone, so it is never handed out.
+const smallestInteger = "A00000000000000000000000000"
+
+var ErrInvalidKey = errors.New("invalid ordering key")
+
+// Between returns a key that sorts after a and before b. An empty a means
+// the start of the list and an empty b its end.
+func Between(a, b string) (string, error) {
+	if a != "" && !Valid(a) || b != "" && !Valid(b) {
+		return "", ErrInvalidKey
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrInvalidKey
+	}
+
+	if a == "" {
+		if b == "" {
+			return First, nil
+		}
+		ib := integerPart(b)
+		if ib == smallestInteger {
+			return ib + midpoint("", b[len(ib):]), nil
+		}
+		if ib < b {
+			return ib, nil
+		}
+		key, ok := decrement(ib)
+		if !ok {
+			return "", ErrInvalidKey
+		}
+		return key, nil
+	}
+
+	ia := integerPart(a)
+	if b == "" {
+		if key, ok := increment(ia); ok {
+			return key, nil
+		}
+		return ia + midpoint(a[len(ia):], ""), nil
+	}
+
+	ib := integerPart(b)
+	if ia == ib {
+		return ia + midpoint(a[len(ia):], b[len(ib):]), nil
+	}
+	key, ok := increment(ia)
+	if !ok {
+		return "", ErrInvalidKey
+	}
+	if key < b {
+		return key, nil
+	}
+	return ia + midpoint(a[len(ia):], ""), nil
+}
+
+// Valid reports whether key could have come from Between.
+func Valid(key string) bool {
+	if key == "" || key == smallestInteger {
+		return false
+	}
+	n := integerLength(key[0])
+	if n == 0 || n > len(key) {
+		return false
+	}
+	for i := 1; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return false
+		}
+	}
+	return !strings.HasSuffix(key[n:], "0")
+}
+
+// integerLength is the length of the integer part headed by head: a to z
+// for positive integers of two characters up, Z to A for negative ones.
+func integerLength(head byte) int {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2
+	}
+	return 0
+}
+
+func integerPart(key string) string {
+	return key[:integerLength(key[0])]
+}
+
+// midpoint returns a fraction between a and b, with an empty b meaning
+// past every fraction. Neither may end in a zero.
+func midpoint(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(tail(a, n), b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(digits, a[0])
+	}
+	db := len(digits)
+	if b != "" {
+		db = strings.IndexByte(digits, b[0])
+	}
+	if db-da > 1 {
+		return string(digits[(da+db+1)/2])
+	}
+	if len(b) > 1 {
+		return b[:1]
+	}
+	return string(digits[da]) + midpoint(tail(a, 1), "")
+}
+
+// digitAt is the i-th digit of s, reading a missing one as zero.
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func tail(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
+
+// increment returns the integer part after x, growing it by a digit when
+// its length runs out. It fails past the largest integer part.
+func increment(x string) (string, bool) {
+	head, ds := x[0], []byte(x[1:])
+	carry := true
+	for i := len(ds) - 1; carry && i >= 0; i-- {
+		d := strings.IndexByte(digits, ds[i]) + 1
+		if d == len(digits) {
+			ds[i] = digits[0]
+		} else {
+			ds[i] = digits[d]
+			carry = false
+		}
+	}
+	if !carry {
+		return string(head) + string(ds), true
+	}
+
+	switch head {
+	case 'Z':
+		return "a" + string(digits[0]), true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		ds = append(ds, digits[0])
+	} else {
+		ds = ds[:len(ds)-1]
+	}
+	return string(head) + string(ds), true
+}
+
+// decrement is increment the other way.
+func decrement(x string) (string, bool) {
+	head, ds := x[0], []byte(x[1:])
+	borrow := true
+	for i := len(ds) - 1; borrow && i >= 0; i-- {
+		d := strings.IndexByte(digits, ds[i]) - 1
+		if d < 0 {
+			ds[i] = digits[len(digits)-1]
+		} else {
+			ds[i] = digits[d]
+			borrow = false
+		}
+	}
+	if !borrow {
+		return string(head) + string(ds), true
+	}
+
+	switch head {
+	case 'a':
+		return "Z" + string(digits[len(digits)-1]), true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		ds = append(ds, digits[len(digits)-1])
+	} else {
+		ds = ds[:len(ds)-1]
+	}
+	return string(head) + string(ds), true
+}
@@ -0,0 +1,97 @@
+// Package origins decides which browser origins may call the API: exact
+// origins, wildcard subdomains like https://*.yata.app, and regular
+// expressions for hosts that can't be listed, like Vercel preview URLs.
+package origins
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Matcher is built once from config and safe for concurrent use.
+type Matcher struct {
+	any       bool
+	exact     map[string]bool
+	wildcards []wildcard
+	patterns  []*regexp.Regexp
+}
+
+// wildcard is "https://*.yata.app" split into what comes before the "*"
+// and the host suffix after it.
+type wildcard struct {
+	prefix string
+	suffix string
+}
+
+// New allows the origins in allowed, where a leading "*." in the host
+// stands for one or more subdomain labels and "*" on its own allows any
+// origin, and those matching one of patterns in full.
+func New(allowed, patterns []string) (*Matcher, error) {
+	m := &Matcher{exact: map[string]bool{}}
+	for _, origin := range allowed {
+		origin = strings.ToLower(strings.TrimSuffix(origin, "/"))
+		switch {
+		case origin == "*":
+			m.any = true
+		case strings.Contains(origin, "*"):
+			scheme, host, ok := strings.Cut(origin, "://")
+			if !ok || !strings.HasPrefix(host, "*.") || strings.Count(host, "*") != 1 || strings.Contains(host, "/") {
+				return nil, fmt.Errorf("%q: a wildcard must be the first label of the host, as in https://*.yata.app", origin)
+			}
+			m.wildcards = append(m.wildcards, wildcard{prefix: scheme + "://", suffix: host[1:]})
+		default:
+			m.exact[origin] = true
+		}
+	}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("%q: %w", pattern, err)
+		}
+		m.patterns = append(m.patterns, re)
+	}
+	return m, nil
+}
+
+// AllowsAny is whether "*" was allowed, for CORS to answer with a plain
+// "*" rather than echoing every origin back with credentials.
+func (m *Matcher) AllowsAny() bool {
+	return m.any
+}
+
+// Allows reports whether a request with this Origin header may be answered.
+func (m *Matcher) Allows(origin string) bool {
+	if m.any {
+		return true
+	}
+	origin, ok := normalize(origin)
+	if !ok {
+		return false
+	}
+	if m.exact[origin] {
+		return true
+	}
+	for _, w := range m.wildcards {
+		if label, ok := strings.CutPrefix(origin, w.prefix); ok && len(label) > len(w.suffix) && strings.HasSuffix(label, w.suffix) {
+			return true
+		}
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize is origin as scheme://host[:port] in lower case, or false for
+// anything else, so a path or credentials can't slip past a suffix match.
+func normalize(origin string) (string, bool) {
+	u, err := url.Parse(origin)
+	if err != nil || u.Scheme == "" || u.Host == "" || u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", false
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), true
+}
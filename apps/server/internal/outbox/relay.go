@@ -0,0 +1,153 @@
+// Package outbox relays the events task writes leave in the outbox table to
+// the publishers that act on them, such as webhooks. An event is recorded in
+// the same transaction as its write, and marked relayed in the same
+// transaction its publishers work in, so a publisher that only writes to
+// Postgres (the job queue, say) sees each event exactly once, crash or not.
+//
+// Live listeners still get changes straight from the in-process broker:
+// they only ever see their own instance, and refetch when they reconnect.
+package outbox
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	batchSize = 100
+	// maxAttempts is how often an event whose publishers keep failing is
+	// tried before it's set aside, with its last error kept on the row.
+	maxAttempts = 10
+	// retention is how long relayed events are kept, and purgeInterval how
+	// often older ones are deleted.
+	retention     = 7 * 24 * time.Hour
+	purgeInterval = time.Hour
+)
+
+// Publisher acts on relayed events. Publish runs in tx, the transaction
+// that marks e relayed; an error rolls back whatever it wrote and leaves e
+// to be tried again.
+type Publisher interface {
+	Publish(ctx context.Context, tx pgx.Tx, e models.OutboxEvent) error
+}
+
+// Relay claims pending events with FOR UPDATE SKIP LOCKED, so a relay can run
+// on every instance. Events are handed on in the order they were recorded
+// within a batch, but two relays can each take a batch at once.
+type Relay struct {
+	pool       *pgxpool.Pool
+	interval   time.Duration
+	publishers []Publisher
+}
+
+func NewRelay(pool *pgxpool.Pool, interval time.Duration) *Relay {
+	return &Relay{pool: pool, interval: interval}
+}
+
+// Add registers p; every event goes to every publisher.
+func (r *Relay) Add(p Publisher) {
+	r.publishers = append(r.publishers, p)
+}
+
+// Start relays until ctx is done, draining the backlog on each tick.
+func (r *Relay) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+
+		var purged time.Time
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for {
+					n, err := r.Run(ctx)
+					if err != nil {
+						slog.Error("Failed to relay outbox events", "error", err)
+					}
+					if err != nil || n < batchSize {
+						break
+					}
+				}
+				if time.Since(purged) >= purgeInterval {
+					purged = time.Now()
+					if err := r.purge(ctx); err != nil {
+						slog.Error("Failed to purge outbox", "error", err)
+					}
+				}
+			}
+		}
+	}()
+}
+
+// Run relays one batch of pending events and returns how many went out.
+// Each event gets a savepoint, so one whose publishers fail is rolled back
+// and retried later without holding up the rest.
+func (r *Relay) Run(ctx context.Context) (int, error) {
+	var relayed int
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx,
+			`SELECT seq, id, event, org_id, actor_id, task_id, payload, created_at FROM outbox
+			 WHERE relayed_at IS NULL
+			 ORDER BY seq
+			 LIMIT $1
+			 FOR UPDATE SKIP LOCKED`,
+			batchSize,
+		)
+		if err != nil {
+			return err
+		}
+		var seqs []int64
+		var events []models.OutboxEvent
+		for rows.Next() {
+			var seq int64
+			var e models.OutboxEvent
+			if err := rows.Scan(&seq, &e.ID, &e.Event, &e.OrgID, &e.ActorID, &e.TaskID, &e.Payload, &e.CreatedAt); err != nil {
+				rows.Close()
+				return err
+			}
+			seqs = append(seqs, seq)
+			events = append(events, e)
+		}
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		for i, e := range events {
+			publishErr := pgx.BeginFunc(ctx, tx, func(tx pgx.Tx) error {
+				for _, p := range r.publishers {
+					if err := p.Publish(ctx, tx, e); err != nil {
+						return err
+					}
+				}
+				_, err := tx.Exec(ctx, `UPDATE outbox SET relayed_at = NOW(), attempts = attempts + 1 WHERE seq = $1`, seqs[i])
+				return err
+			})
+			if publishErr == nil {
+				relayed++
+				continue
+			}
+			slog.Warn("Failed to publish outbox event", "event", e.Event, "id", e.ID, "error", publishErr)
+			if _, err := tx.Exec(ctx,
+				`UPDATE outbox SET attempts = attempts + 1, last_error = $2,
+				     relayed_at = CASE WHEN attempts + 1 >= $3 THEN NOW() END
+				 WHERE seq = $1`,
+				seqs[i], publishErr.Error(), maxAttempts,
+			); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return relayed, err
+}
+
+func (r *Relay) purge(ctx context.Context) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM outbox WHERE relayed_at < $1`, time.Now().Add(-retention))
+	return err
+}
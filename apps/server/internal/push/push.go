@@ -0,0 +1,118 @@
+// Package push delivers notifications to browsers over Web Push, signing
+// requests with the server's VAPID key pair.
+package push
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/store"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+)
+
+// Config holds the VAPID key pair. Subject is a mailto: or https: URL push
+// services can use to contact the sender.
+type Config struct {
+	PublicKey  string
+	PrivateKey string
+	Subject    string
+}
+
+// ttl is how long, in seconds, a push service holds a message for an
+// offline browser.
+const ttl = 24 * 60 * 60
+
+// payload is what the service worker receives in the push event.
+type payload struct {
+	Kind   string `json:"kind"`
+	TaskID string `json:"taskId"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// Notifier sends reminder, assignment and mention notifications to every
+// browser the user subscribed from.
+type Notifier struct {
+	Config        Config
+	Subscriptions store.PushSubscriptionStore
+	// Settings can turn push off entirely.
+	Settings store.UserSettingsStore
+}
+
+func (n *Notifier) Notify(ctx context.Context, note notify.Notification) error {
+	switch note.Kind {
+	case notify.KindReminder, notify.KindTaskAssigned, notify.KindCommentMention:
+	default:
+		return nil
+	}
+
+	settings, err := n.Settings.Get(ctx, note.UserID)
+	if err != nil {
+		return err
+	}
+	if !settings.NotificationChannels.Push {
+		return nil
+	}
+
+	subs, err := n.Subscriptions.ListForUser(ctx, note.UserID)
+	if err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	message, err := json.Marshal(payload{Kind: note.Kind, TaskID: note.TaskID, Title: note.Title, Body: note.Body})
+	if err != nil {
+		return err
+	}
+
+	var first error
+	for _, sub := range subs {
+		err := n.send(ctx, message, &webpush.Subscription{
+			Endpoint: sub.Endpoint,
+			Keys:     webpush.Keys{P256dh: sub.P256dh, Auth: sub.Auth},
+		})
+		if errors.Is(err, errGone) {
+			// The browser unsubscribed or the subscription expired.
+			if err := n.Subscriptions.Delete(ctx, sub.UserID, sub.Endpoint); err != nil {
+				slog.Error("Failed to delete expired push subscription", "error", err)
+			}
+			continue
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+var errGone = errors.New("push subscription is gone")
+
+func (n *Notifier) send(ctx context.Context, message []byte, sub *webpush.Subscription) error {
+	resp, err := webpush.SendNotificationWithContext(ctx, message, sub, &webpush.Options{
+		Subscriber:      n.Config.Subject,
+		VAPIDPublicKey:  n.Config.PublicKey,
+		VAPIDPrivateKey: n.Config.PrivateKey,
+		TTL:             ttl,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return errGone
+	case resp.StatusCode >= 300:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("push service returned %s: %s", resp.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,489 @@
+// Package quickadd reads a one-line task such as "Pay rent tomorrow 5pm
+// #finance !high every month" into its title, due date, labels, priority and
+// recurrence. What it recognizes is taken out of the title; everything else
+// is left as typed.
+package quickadd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Result is what Parse understood. Labels and Priority are the names as
+// typed, for the caller to match against the org's.
+type Result struct {
+	Title string
+	Due   *time.Time
+	// DueHasTime is false when only a day was given; Due is then midnight.
+	DueHasTime bool
+	Labels     []string
+	Priority   string
+	// Recurrence is an RRULE without DTSTART, anchored on Due.
+	Recurrence *string
+}
+
+// Parse reads text as of now in loc, which decides what "today" and "5pm"
+// mean.
+func Parse(text string, now time.Time, loc *time.Location) Result {
+	p := parser{now: now.In(loc), loc: loc, words: strings.Fields(text)}
+	p.run()
+	return p.result()
+}
+
+type parser struct {
+	now   time.Time
+	loc   *time.Location
+	words []string
+	title []string
+
+	labels   []string
+	priority string
+	rule     string
+	// ruleDays are the weekdays an "every monday" rule falls on.
+	ruleDays []time.Weekday
+
+	date    *time.Time
+	hour    int
+	minute  int
+	hasTime bool
+}
+
+func (p *parser) run() {
+	for i := 0; i < len(p.words); {
+		if n := p.match(i); n > 0 {
+			i += n
+			continue
+		}
+		p.title = append(p.title, p.words[i])
+		i++
+	}
+}
+
+// word is the ith word lowercased, without trailing punctuation, or "" past
+// the end.
+func (p *parser) word(i int) string {
+	if i >= len(p.words) {
+		return ""
+	}
+	return strings.TrimRight(strings.ToLower(p.words[i]), ",.;")
+}
+
+// match tries each kind of token at i and returns how many words it took.
+func (p *parser) match(i int) int {
+	w := p.words[i]
+	switch {
+	case len(w) > 1 && w[0] == '#':
+		p.labels = append(p.labels, strings.TrimRight(w[1:], ",.;"))
+		return 1
+	case len(w) > 1 && w[0] == '!' && p.priority == "":
+		p.priority = strings.TrimRight(w[1:], ",.;")
+		return 1
+	}
+
+	if p.rule == "" {
+		if n := p.matchRecurrence(i); n > 0 {
+			return n
+		}
+	}
+	if p.date == nil {
+		if n := p.matchDate(i); n > 0 {
+			return n
+		}
+		// "on friday", "by oct 3", "due tomorrow"
+		switch p.word(i) {
+		case "on", "by", "due":
+			if n := p.matchDate(i + 1); n > 0 {
+				return n + 1
+			}
+		}
+	}
+	if !p.hasTime {
+		if n := p.matchTime(i, false); n > 0 {
+			return n
+		}
+		if p.word(i) == "at" {
+			if n := p.matchTime(i+1, true); n > 0 {
+				return n + 1
+			}
+		}
+	}
+	return 0
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "sun": time.Sunday,
+	"monday": time.Monday, "mon": time.Monday,
+	"tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+	"wednesday": time.Wednesday, "wed": time.Wednesday,
+	"thursday": time.Thursday, "thu": time.Thursday, "thur": time.Thursday, "thurs": time.Thursday,
+	"friday": time.Friday, "fri": time.Friday,
+	"saturday": time.Saturday, "sat": time.Saturday,
+}
+
+var months = map[string]time.Month{
+	"january": time.January, "jan": time.January,
+	"february": time.February, "feb": time.February,
+	"march": time.March, "mar": time.March,
+	"april": time.April, "apr": time.April,
+	"may":  time.May,
+	"june": time.June, "jun": time.June,
+	"july": time.July, "jul": time.July,
+	"august": time.August, "aug": time.August,
+	"september": time.September, "sep": time.September, "sept": time.September,
+	"october": time.October, "oct": time.October,
+	"november": time.November, "nov": time.November,
+	"december": time.December, "dec": time.December,
+}
+
+// units maps a period word, singular or plural, to its RRULE frequency.
+var units = map[string]string{
+	"day": "DAILY", "days": "DAILY",
+	"week": "WEEKLY", "weeks": "WEEKLY",
+	"month": "MONTHLY", "months": "MONTHLY",
+	"year": "YEARLY", "years": "YEARLY",
+}
+
+var ruleDayNames = map[time.Weekday]string{
+	time.Sunday: "SU", time.Monday: "MO", time.Tuesday: "TU", time.Wednesday: "WE",
+	time.Thursday: "TH", time.Friday: "FR", time.Saturday: "SA",
+}
+
+// matchRecurrence reads "every [N|other] day/week/month/year", "every
+// weekday" and "every monday [and thursday]".
+func (p *parser) matchRecurrence(i int) int {
+	if p.word(i) != "every" {
+		return 0
+	}
+	interval, n := 1, 1
+	switch w := p.word(i + 1); {
+	case w == "other":
+		interval, n = 2, 2
+	case number(w) > 0:
+		interval, n = number(w), 2
+	}
+
+	if freq, ok := units[p.word(i+n)]; ok {
+		p.rule = "FREQ=" + freq
+		if interval > 1 {
+			p.rule += ";INTERVAL=" + strconv.Itoa(interval)
+		}
+		return n + 1
+	}
+	if n > 1 && p.word(i+1) != "other" {
+		return 0
+	}
+
+	if p.word(i+n) == "weekday" {
+		p.ruleDays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+		n++
+	} else {
+		for {
+			d, ok := weekdays[p.word(i+n)]
+			if !ok {
+				break
+			}
+			p.ruleDays = append(p.ruleDays, d)
+			n++
+			if p.word(i+n) == "and" {
+				if _, ok := weekdays[p.word(i+n+1)]; ok {
+					n++
+				}
+			}
+		}
+	}
+	if len(p.ruleDays) == 0 {
+		return 0
+	}
+	days := make([]string, len(p.ruleDays))
+	for j, d := range p.ruleDays {
+		days[j] = ruleDayNames[d]
+	}
+	p.rule = "FREQ=WEEKLY;BYDAY=" + strings.Join(days, ",")
+	if interval > 1 {
+		p.rule += ";INTERVAL=" + strconv.Itoa(interval)
+	}
+	return n
+}
+
+func (p *parser) today() time.Time {
+	y, m, d := p.now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, p.loc)
+}
+
+func (p *parser) setDate(t time.Time) {
+	y, m, d := t.Date()
+	day := time.Date(y, m, d, 0, 0, 0, 0, p.loc)
+	p.date = &day
+}
+
+// matchDate reads relative days, weekdays, "in N units", ISO dates and
+// "oct 3"/"3 oct" with an optional year.
+func (p *parser) matchDate(i int) int {
+	today := p.today()
+	w := p.word(i)
+	switch w {
+	case "today":
+		p.setDate(today)
+		return 1
+	case "tonight":
+		p.setDate(today)
+		if !p.hasTime {
+			p.hour, p.minute, p.hasTime = 20, 0, true
+		}
+		return 1
+	case "tomorrow", "tmrw", "tmr":
+		p.setDate(today.AddDate(0, 0, 1))
+		return 1
+	case "next":
+		next := p.word(i + 1)
+		if d, ok := weekdays[next]; ok {
+			// A week past the coming one, so "next friday" said on a
+			// Monday is the Friday of the week after.
+			p.setDate(today.AddDate(0, 0, daysUntil(today.Weekday(), d)+7))
+			return 2
+		}
+		switch next {
+		case "week":
+			// The coming Monday.
+			p.setDate(today.AddDate(0, 0, daysUntil(today.Weekday(), time.Monday)))
+			return 2
+		case "month":
+			p.setDate(time.Date(today.Year(), today.Month()+1, 1, 0, 0, 0, 0, p.loc))
+			return 2
+		}
+		return 0
+	case "in":
+		amount, n := 0, 2
+		switch a := p.word(i + 1); {
+		case a == "a" || a == "an":
+			amount = 1
+		case number(a) > 0:
+			amount = number(a)
+		default:
+			return 0
+		}
+		switch p.word(i + n) {
+		case "minute", "minutes", "min", "mins":
+			p.setInstant(p.now.Add(time.Duration(amount) * time.Minute))
+		case "hour", "hours":
+			p.setInstant(p.now.Add(time.Duration(amount) * time.Hour))
+		case "day", "days":
+			p.setDate(today.AddDate(0, 0, amount))
+		case "week", "weeks":
+			p.setDate(today.AddDate(0, 0, 7*amount))
+		case "month", "months":
+			p.setDate(today.AddDate(0, amount, 0))
+		default:
+			return 0
+		}
+		return n + 1
+	}
+
+	if d, ok := weekdays[w]; ok {
+		p.setDate(today.AddDate(0, 0, daysUntil(today.Weekday(), d)))
+		return 1
+	}
+	if t, err := time.ParseInLocation("2006-01-02", w, p.loc); err == nil {
+		p.setDate(t)
+		return 1
+	}
+	// "oct 3", "october 3rd"
+	if m, ok := months[w]; ok {
+		if day := ordinal(p.word(i + 1)); day > 0 {
+			if n, ok := p.monthDay(m, day, p.word(i+2)); ok {
+				return 2 + n
+			}
+		}
+	}
+	// "3 oct", "3rd of october"
+	if day := ordinal(w); day > 0 {
+		n := 1
+		if p.word(i+n) == "of" {
+			n++
+		}
+		if m, ok := months[p.word(i+n)]; ok {
+			if used, ok := p.monthDay(m, day, p.word(i+n+1)); ok {
+				return n + 1 + used
+			}
+		}
+	}
+	return 0
+}
+
+// monthDay sets the date to day of m, in year if that's a year and
+// otherwise the next time that date comes round. It returns 1 if it used
+// the year, and false if there's no such day, such as "feb 31", so the
+// words stay in the title.
+func (p *parser) monthDay(m time.Month, day int, year string) (int, bool) {
+	if y := number(year); y >= 2000 && y < 2200 {
+		t, ok := p.calendarDate(y, m, day)
+		if ok {
+			p.setDate(t)
+		}
+		return 1, ok
+	}
+
+	// Feb 29 only comes round in a leap year, which is at most 8 years away.
+	today := p.today()
+	for y := today.Year(); y <= today.Year()+8; y++ {
+		if t, ok := p.calendarDate(y, m, day); ok && !t.Before(today) {
+			p.setDate(t)
+			return 0, true
+		}
+	}
+	return 0, false
+}
+
+// calendarDate is midnight on y-m-day, and false when time.Date had to
+// normalize the day into the next month because m is shorter than that.
+func (p *parser) calendarDate(y int, m time.Month, day int) (time.Time, bool) {
+	t := time.Date(y, m, day, 0, 0, 0, 0, p.loc)
+	return t, t.Month() == m && t.Day() == day
+}
+
+// setInstant sets both the date and the time, for "in 2 hours".
+func (p *parser) setInstant(t time.Time) {
+	p.setDate(t)
+	p.hour, p.minute, p.hasTime = t.Hour(), t.Minute(), true
+}
+
+// matchTime reads "5pm", "5:30 pm", "17:00", "noon" and "midnight". A bare
+// hour such as "5" only counts after "at", which is what afterAt says.
+func (p *parser) matchTime(i int, afterAt bool) int {
+	w := p.word(i)
+	switch w {
+	case "noon", "midday":
+		p.hour, p.minute, p.hasTime = 12, 0, true
+		return 1
+	case "midnight":
+		p.hour, p.minute, p.hasTime = 0, 0, true
+		return 1
+	}
+
+	n := 1
+	suffix := ""
+	for _, s := range []string{"a.m", "p.m", "am", "pm"} {
+		if strings.HasSuffix(w, s) {
+			w, suffix = strings.TrimSuffix(w, s), s[:1]
+			break
+		}
+	}
+	if suffix == "" {
+		switch p.word(i + 1) {
+		case "am", "a.m":
+			suffix, n = "a", 2
+		case "pm", "p.m":
+			suffix, n = "p", 2
+		}
+	}
+
+	hourText, minuteText, hasMinutes := strings.Cut(w, ":")
+	hour, minute := number(hourText), 0
+	if hourText == "0" || hourText == "00" {
+		hour = 0
+	} else if hour <= 0 {
+		return 0
+	}
+	if hasMinutes {
+		if len(minuteText) != 2 {
+			return 0
+		}
+		if minute = number(minuteText); minute < 0 || minute > 59 {
+			return 0
+		}
+	}
+	if suffix == "" && !hasMinutes && !afterAt {
+		return 0
+	}
+
+	switch suffix {
+	case "a", "p":
+		if hour < 1 || hour > 12 {
+			return 0
+		}
+		hour %= 12
+		if suffix == "p" {
+			hour += 12
+		}
+	default:
+		if hour > 23 {
+			return 0
+		}
+	}
+	p.hour, p.minute, p.hasTime = hour, minute, true
+	return n
+}
+
+func (p *parser) result() Result {
+	r := Result{Title: strings.Join(p.title, " "), Labels: p.labels, Priority: p.priority}
+	if p.rule != "" {
+		r.Recurrence = &p.rule
+	}
+
+	date := p.date
+	if date == nil && (p.hasTime || p.rule != "") {
+		day := p.today()
+		// A time that's already gone today means tomorrow.
+		if p.hasTime && !p.at(day).After(p.now) {
+			day = day.AddDate(0, 0, 1)
+		}
+		// Then on to the rule's first day.
+		for range 7 {
+			if len(p.ruleDays) == 0 || slices.Contains(p.ruleDays, day.Weekday()) {
+				break
+			}
+			day = day.AddDate(0, 0, 1)
+		}
+		date = &day
+	}
+	if date == nil {
+		return r
+	}
+
+	due := *date
+	if p.hasTime {
+		due = p.at(due)
+	}
+	r.Due, r.DueHasTime = &due, p.hasTime
+	return r
+}
+
+// at is the parsed time of day on day.
+func (p *parser) at(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), p.hour, p.minute, 0, 0, p.loc)
+}
+
+// daysUntil is how many days after from the next to weekday is, counting a
+// full week when they're the same day.
+func daysUntil(from, to time.Weekday) int {
+	d := (int(to) - int(from) + 7) % 7
+	if d == 0 {
+		d = 7
+	}
+	return d
+}
+
+// number parses a small positive integer, or returns -1.
+func number(s string) int {
+	if s == "" || len(s) > 4 {
+		return -1
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
+
+// ordinal parses a day of the month such as "3", "3rd" or "21st".
+func ordinal(s string) int {
+	for _, suffix := range []string{"st", "nd", "rd", "th"} {
+		s = strings.TrimSuffix(s, suffix)
+	}
+	if n := number(s); n >= 1 && n <= 31 {
+		return n
+	}
+	return 0
+}
@@ -0,0 +1,44 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCalendarDates(t *testing.T) {
+	loc, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, time.October, 14, 9, 0, 0, 0, loc)
+
+	tests := []struct {
+		text  string
+		title string
+		due   string
+	}{
+		{"Pay rent feb 28", "Pay rent", "2027-02-28"},
+		{"Pay rent oct 3 2027", "Pay rent", "2027-10-03"},
+		{"Pay rent 3rd of november", "Pay rent", "2026-11-03"},
+		{"Leap day party feb 29", "Leap day party", "2028-02-29"},
+		{"Leap day party feb 29 2028", "Leap day party", "2028-02-29"},
+		{"Pay rent feb 31", "Pay rent feb 31", ""},
+		{"Pay rent 31st of april", "Pay rent 31st of april", ""},
+		{"Pay rent feb 29 2027", "Pay rent feb 29 2027", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			r := Parse(tt.text, now, loc)
+			if r.Title != tt.title {
+				t.Errorf("Title = %q, want %q", r.Title, tt.title)
+			}
+			got := ""
+			if r.Due != nil {
+				got = r.Due.Format(time.DateOnly)
+			}
+			if got != tt.due {
+				t.Errorf("Due = %q, want %q", got, tt.due)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+// Package quota holds orgs to plan limits on how many tasks, how many bytes
+// of attachments and how many webhook endpoints they keep.
+package quota
+
+import (
+	"context"
+	"fmt"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+// What a quota can be on.
+const (
+	Tasks            = "tasks"
+	AttachmentBytes  = "attachmentBytes"
+	WebhookEndpoints = "webhookEndpoints"
+)
+
+// Limits are one plan's quotas; zero is no limit.
+type Limits struct {
+	Tasks            int64
+	AttachmentBytes  int64
+	WebhookEndpoints int64
+}
+
+// LimitsFrom reads the plan limits from the QUOTA_MAX_* settings.
+func LimitsFrom(cfg *config.Config) Limits {
+	return Limits{
+		Tasks:            cfg.QUOTA_MAX_TASKS,
+		AttachmentBytes:  cfg.QUOTA_MAX_ATTACHMENT_BYTES,
+		WebhookEndpoints: cfg.QUOTA_MAX_WEBHOOK_ENDPOINTS,
+	}
+}
+
+func (l Limits) of(resource string) int64 {
+	switch resource {
+	case Tasks:
+		return l.Tasks
+	case AttachmentBytes:
+		return l.AttachmentBytes
+	case WebhookEndpoints:
+		return l.WebhookEndpoints
+	}
+	return 0
+}
+
+// ExceededError is a write that would take an org past a limit.
+type ExceededError struct {
+	Resource string
+	Limit    int64
+	Used     int64
+}
+
+func (e *ExceededError) Error() string {
+	return fmt.Sprintf("%s quota of %d exceeded: %d used", e.Resource, e.Limit, e.Used)
+}
+
+// Enforcer checks writes against the limits; WithQuotas puts it in front
+// of a store. Usage is counted in the same transaction as the writes, but
+// checked before them, so requests racing each other can take an org a
+// little past a limit.
+type Enforcer struct {
+	usage  store.UsageStore
+	limits Limits
+}
+
+func New(usage store.UsageStore, limits Limits) *Enforcer {
+	return &Enforcer{usage: usage, limits: limits}
+}
+
+// Check returns an *ExceededError when adding n of resource would take the
+// org past its limit. Personal scopes have no quotas.
+func (e *Enforcer) Check(ctx context.Context, scope models.Scope, resource string, n int64) error {
+	limit := e.limits.of(resource)
+	if limit <= 0 || n <= 0 || !scope.IsOrg() {
+		return nil
+	}
+	usage, err := e.usage.Get(ctx, scope.OrgID)
+	if err != nil {
+		return err
+	}
+	used := map[string]int64{
+		Tasks:            usage.Tasks,
+		AttachmentBytes:  usage.AttachmentBytes,
+		WebhookEndpoints: usage.WebhookEndpoints,
+	}[resource]
+	if used+n > limit {
+		return &ExceededError{Resource: resource, Limit: limit, Used: used}
+	}
+	return nil
+}
+
+// Usage is the org's usage next to its limits.
+func (e *Enforcer) Usage(ctx context.Context, orgID string) (*models.OrgUsage, error) {
+	usage, err := e.usage.Get(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	item := func(used, limit int64) models.UsageItem {
+		if limit <= 0 {
+			return models.UsageItem{Used: used}
+		}
+		return models.UsageItem{Used: used, Limit: &limit}
+	}
+	return &models.OrgUsage{
+		Tasks:            item(usage.Tasks, e.limits.Tasks),
+		AttachmentBytes:  item(usage.AttachmentBytes, e.limits.AttachmentBytes),
+		WebhookEndpoints: item(usage.WebhookEndpoints, e.limits.WebhookEndpoints),
+	}, nil
+}
@@ -0,0 +1,144 @@
+package quota
+
+import (
+	"context"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+// WithQuotas checks every write made through the returned Store that adds
+// tasks, attachment bytes or webhook endpoints against e, and fails it with
+// an *ExceededError instead, whether it comes from the API, gRPC, an import,
+// a chat integration or sync.
+func WithQuotas(s store.Store, e *Enforcer) store.Store {
+	return quotaStore{Store: s, e: e}
+}
+
+type quotaStore struct {
+	store.Store
+	e *Enforcer
+}
+
+func (s quotaStore) Tasks() store.TaskStore {
+	return quotaTasks{TaskStore: s.Store.Tasks(), e: s.e}
+}
+
+func (s quotaStore) Projects() store.ProjectStore {
+	return quotaProjects{ProjectStore: s.Store.Projects(), e: s.e}
+}
+
+func (s quotaStore) Sync() store.SyncStore {
+	return quotaSync{SyncStore: s.Store.Sync(), tasks: s.Store.Tasks(), e: s.e}
+}
+
+func (s quotaStore) Trash() store.TrashStore {
+	return quotaTrash{TrashStore: s.Store.Trash(), e: s.e}
+}
+
+func (s quotaStore) Attachments() store.AttachmentStore {
+	return quotaAttachments{AttachmentStore: s.Store.Attachments(), e: s.e}
+}
+
+func (s quotaStore) Webhooks() store.WebhookStore {
+	return quotaWebhooks{WebhookStore: s.Store.Webhooks(), e: s.e}
+}
+
+type quotaTasks struct {
+	store.TaskStore
+	e *Enforcer
+}
+
+func (t quotaTasks) Create(ctx context.Context, scope models.Scope, input models.CreateTaskInput) (*models.Task, error) {
+	if err := t.e.Check(ctx, scope, Tasks, 1); err != nil {
+		return nil, err
+	}
+	return t.TaskStore.Create(ctx, scope, input)
+}
+
+func (t quotaTasks) CreateBatch(ctx context.Context, scope models.Scope, batch []models.NewTask) ([]models.Task, error) {
+	if err := t.e.Check(ctx, scope, Tasks, int64(len(batch))); err != nil {
+		return nil, err
+	}
+	return t.TaskStore.CreateBatch(ctx, scope, batch)
+}
+
+type quotaProjects struct {
+	store.ProjectStore
+	e *Enforcer
+}
+
+func (p quotaProjects) CreateSeeded(ctx context.Context, scope models.Scope, input models.NewProject) (*models.SeededProject, error) {
+	if err := p.e.Check(ctx, scope, Tasks, int64(len(input.Tasks))); err != nil {
+		return nil, err
+	}
+	return p.ProjectStore.CreateSeeded(ctx, scope, input)
+}
+
+// quotaSync counts a push as creating every task its set ops name that the
+// scope can't see yet, which is what Push creates them for.
+type quotaSync struct {
+	store.SyncStore
+	tasks store.TaskStore
+	e     *Enforcer
+}
+
+func (s quotaSync) Push(ctx context.Context, scope models.Scope, defaultStatus string, ops []models.SyncOp) ([]models.SyncResult, int64, error) {
+	if scope.IsOrg() {
+		ids := []string{}
+		seen := map[string]bool{}
+		for _, op := range ops {
+			if op.Type == models.SyncOpSet && !seen[op.TaskID] {
+				seen[op.TaskID] = true
+				ids = append(ids, op.TaskID)
+			}
+		}
+		if len(ids) > 0 {
+			visible, err := s.tasks.Visible(ctx, scope, ids)
+			if err != nil {
+				return nil, 0, err
+			}
+			if err := s.e.Check(ctx, scope, Tasks, int64(len(ids)-len(visible))); err != nil {
+				return nil, 0, err
+			}
+		}
+	}
+	return s.SyncStore.Push(ctx, scope, defaultStatus, ops)
+}
+
+type quotaTrash struct {
+	store.TrashStore
+	e *Enforcer
+}
+
+// RestoreTask counts the task again, so trashing tasks to make room and
+// then restoring them can't go past the limit.
+func (t quotaTrash) RestoreTask(ctx context.Context, scope models.Scope, id string) (*models.Task, error) {
+	if err := t.e.Check(ctx, scope, Tasks, 1); err != nil {
+		return nil, err
+	}
+	return t.TrashStore.RestoreTask(ctx, scope, id)
+}
+
+type quotaAttachments struct {
+	store.AttachmentStore
+	e *Enforcer
+}
+
+func (a quotaAttachments) Create(ctx context.Context, scope models.Scope, taskID string, input models.CreateAttachmentInput) (*models.Attachment, error) {
+	if err := a.e.Check(ctx, scope, AttachmentBytes, input.Size); err != nil {
+		return nil, err
+	}
+	return a.AttachmentStore.Create(ctx, scope, taskID, input)
+}
+
+type quotaWebhooks struct {
+	store.WebhookStore
+	e *Enforcer
+}
+
+func (w quotaWebhooks) Create(ctx context.Context, orgID, userID string, input models.CreateWebhookInput) (*models.WebhookEndpoint, error) {
+	if err := w.e.Check(ctx, models.Scope{UserID: userID, OrgID: orgID}, WebhookEndpoints, 1); err != nil {
+		return nil, err
+	}
+	return w.WebhookStore.Create(ctx, orgID, userID, input)
+}
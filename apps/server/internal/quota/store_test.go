@@ -0,0 +1,67 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+func TestWithQuotas(t *testing.T) {
+	ctx := context.Background()
+	base := store.NewMemory()
+	db := WithQuotas(base, New(base.Usage(), Limits{Tasks: 3, WebhookEndpoints: 1}))
+	org := models.Scope{UserID: "user_1", OrgID: "org_1"}
+
+	exceeded := func(t *testing.T, err error, resource string) {
+		t.Helper()
+		var e *ExceededError
+		if !errors.As(err, &e) || e.Resource != resource {
+			t.Fatalf("err = %v, want %s quota exceeded", err, resource)
+		}
+	}
+
+	if _, err := db.Tasks().Create(ctx, org, models.CreateTaskInput{Title: "one"}); err != nil {
+		t.Fatal(err)
+	}
+	batch := []models.NewTask{{Input: models.CreateTaskInput{Title: "two"}, Parent: -1}, {Input: models.CreateTaskInput{Title: "three"}, Parent: -1}, {Input: models.CreateTaskInput{Title: "four"}, Parent: -1}}
+	_, err := db.Tasks().CreateBatch(ctx, org, batch)
+	exceeded(t, err, Tasks)
+	if _, err := db.Tasks().CreateBatch(ctx, org, batch[:2]); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = db.Tasks().Create(ctx, org, models.CreateTaskInput{Title: "four"})
+	exceeded(t, err, Tasks)
+	_, err = db.Projects().CreateSeeded(ctx, org, models.NewProject{Input: models.CreateProjectInput{Name: "p"}, Tasks: batch[:1]})
+	exceeded(t, err, Tasks)
+
+	title, _ := json.Marshal("synced")
+	op := models.SyncOp{ID: "op-1", TaskID: "6f1c2a8e-3b4d-4e5f-8a9b-0c1d2e3f4a5b", Type: models.SyncOpSet, Field: "title", Value: title}
+	_, _, err = db.Sync().Push(ctx, org, models.TaskStatusTodo, []models.SyncOp{op})
+	exceeded(t, err, Tasks)
+
+	// Other orgs and personal lists aren't held to this org's usage.
+	if _, err := db.Tasks().Create(ctx, models.Scope{UserID: "user_1", OrgID: "org_2"}, models.CreateTaskInput{Title: "elsewhere"}); err != nil {
+		t.Errorf("create in another org: %v", err)
+	}
+	if _, err := db.Tasks().Create(ctx, models.Scope{UserID: "user_1"}, models.CreateTaskInput{Title: "personal"}); err != nil {
+		t.Errorf("create in a personal list: %v", err)
+	}
+
+	if _, err := db.Webhooks().Create(ctx, org.OrgID, org.UserID, models.CreateWebhookInput{URL: "https://example.com/a", Events: []string{"task.created"}}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Webhooks().Create(ctx, org.OrgID, org.UserID, models.CreateWebhookInput{URL: "https://example.com/b", Events: []string{"task.created"}})
+	exceeded(t, err, WebhookEndpoints)
+
+	usage, err := base.Usage().Get(ctx, org.OrgID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if usage.Tasks != 3 || usage.WebhookEndpoints != 1 {
+		t.Errorf("usage = %+v, want 3 tasks and 1 webhook", usage)
+	}
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired windows are dropped from memory.
+const sweepInterval = time.Minute
+
+type window struct {
+	count   int
+	resetAt time.Time
+}
+
+// Memory counts in this process only, so each instance enforces the limits
+// on its own.
+type Memory struct {
+	mu        sync.Mutex
+	windows   map[string]window
+	nextSweep time.Time
+}
+
+func NewMemory() *Memory {
+	return &Memory{windows: map[string]window{}}
+}
+
+func (m *Memory) Allow(_ context.Context, key string, rule Rule) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.After(m.nextSweep) {
+		for k, w := range m.windows {
+			if !now.Before(w.resetAt) {
+				delete(m.windows, k)
+			}
+		}
+		m.nextSweep = now.Add(sweepInterval)
+	}
+
+	w, ok := m.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = window{resetAt: now.Add(rule.Window)}
+	}
+	w.count++
+	m.windows[key] = w
+	return newResult(rule, w.count, w.resetAt), nil
+}
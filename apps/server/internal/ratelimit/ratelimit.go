@@ -0,0 +1,79 @@
+// Package ratelimit counts requests in fixed windows, in Redis when the API
+// runs on more than one instance and in memory otherwise.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Rule allows Limit requests per Window.
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// ParseRule reads a rule written as "limit/window", e.g. "300/1m".
+func ParseRule(s string) (Rule, error) {
+	rawLimit, rawWindow, ok := strings.Cut(s, "/")
+	if !ok {
+		return Rule{}, fmt.Errorf("rate limit %q is not limit/window", s)
+	}
+	limit, err := strconv.Atoi(strings.TrimSpace(rawLimit))
+	if err != nil || limit < 1 {
+		return Rule{}, fmt.Errorf("rate limit %q has an invalid limit", s)
+	}
+	window, err := time.ParseDuration(strings.TrimSpace(rawWindow))
+	if err != nil || window < time.Second {
+		return Rule{}, fmt.Errorf("rate limit %q has an invalid window", s)
+	}
+	return Rule{Limit: limit, Window: window}, nil
+}
+
+// String writes r the way ParseRule reads it.
+func (r Rule) String() string {
+	return strconv.Itoa(r.Limit) + "/" + r.Window.String()
+}
+
+// Result is the state of a key's window after counting a request.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+}
+
+func newResult(rule Rule, count int, resetAt time.Time) Result {
+	return Result{
+		Allowed:   count <= rule.Limit,
+		Limit:     rule.Limit,
+		Remaining: max(rule.Limit-count, 0),
+		ResetAt:   resetAt,
+	}
+}
+
+// Limiter counts one request against key under rule.
+type Limiter interface {
+	Allow(ctx context.Context, key string, rule Rule) (Result, error)
+}
+
+// Fallback uses Primary and switches to Secondary for requests Primary
+// can't count, so an unreachable Redis doesn't take the API down with it.
+type Fallback struct {
+	Primary   Limiter
+	Secondary Limiter
+}
+
+func (f Fallback) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	result, err := f.Primary.Allow(ctx, key, rule)
+	if err == nil || errors.Is(err, context.Canceled) {
+		return result, err
+	}
+	slog.ErrorContext(ctx, "Rate limiter unavailable, counting in memory", "error", err)
+	return f.Secondary.Allow(ctx, key, rule)
+}
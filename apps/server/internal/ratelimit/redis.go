@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// countScript bumps the key's counter, starting its window on the first
+// request, and returns the count and the milliseconds left in the window.
+var countScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return {n, redis.call('PTTL', KEYS[1])}
+`)
+
+// Redis counts in Redis, so the limits hold across instances.
+type Redis struct {
+	Client redis.Scripter
+}
+
+func (r Redis) Allow(ctx context.Context, key string, rule Rule) (Result, error) {
+	values, err := countScript.Run(ctx, r.Client, []string{"ratelimit:" + key}, rule.Window.Milliseconds()).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	ttl := time.Duration(values[1]) * time.Millisecond
+	if ttl < 0 {
+		ttl = rule.Window
+	}
+	return newResult(rule, int(values[0]), time.Now().Add(ttl)), nil
+}
@@ -0,0 +1,216 @@
+// Package realtime serves task events to browsers over WebSocket. Each
+// connection joins its org's channel (or the user's own, outside an org)
+// after authenticating with a Clerk session token.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+	"yata/apps/server/internal/events"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/origins"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// authTimeout is how long a new connection has to send its auth message.
+	authTimeout = 10 * time.Second
+	pingPeriod  = 30 * time.Second
+	// pongWait must be longer than pingPeriod.
+	pongWait     = 60 * time.Second
+	writeWait    = 10 * time.Second
+	maxReadBytes = 4096
+)
+
+// Message is the envelope for everything sent over the socket.
+type Message struct {
+	Type  string        `json:"type"`
+	Token string        `json:"token,omitempty"`
+	Error string        `json:"error,omitempty"`
+	Event *events.Event `json:"event,omitempty"`
+}
+
+const (
+	MessageAuth  = "auth"
+	MessageReady = "ready"
+	MessageEvent = "event"
+	MessageError = "error"
+)
+
+var errNotAuth = errors.New("first message must be an auth message")
+
+// Hub tracks open connections per channel and feeds each one the events
+// published for its channel that its user can see.
+type Hub struct {
+	broker   *events.Broker
+	access   events.Access
+	sessions middlewares.SessionCheck
+	upgrader websocket.Upgrader
+
+	mu       sync.Mutex
+	channels map[string]map[*conn]struct{}
+}
+
+// NewHub accepts connections from allowedOrigins only, and from clients
+// that send no Origin, which aren't browsers. Session tokens are verified
+// by sessions, as they are for HTTP requests, and events are checked
+// against access as they are for the SSE stream.
+func NewHub(broker *events.Broker, access events.Access, allowedOrigins *origins.Matcher, sessions middlewares.SessionCheck) *Hub {
+	h := &Hub{
+		broker:   broker,
+		access:   access,
+		sessions: sessions,
+		channels: map[string]map[*conn]struct{}{},
+	}
+	h.upgrader = websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+			return origin == "" || allowedOrigins.Allows(origin)
+		},
+	}
+	return h
+}
+
+// Connections returns how many sockets are joined to channel.
+func (h *Hub) Connections(channel string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.channels[channel])
+}
+
+// Serve upgrades the request and runs the connection until it closes. The
+// client's first message must be {"type":"auth","token":"<session token>"}.
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request) {
+	ws, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written the error response.
+		return
+	}
+	defer ws.Close()
+	ws.SetReadLimit(maxReadBytes)
+	c := &conn{ws: ws}
+
+	scope, err := h.authenticate(r.Context(), ws)
+	if err != nil {
+		c.write(Message{Type: MessageError, Error: "Unauthorized"})
+		ws.WriteControl(websocket.CloseMessage,
+			websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "unauthorized"),
+			time.Now().Add(writeWait))
+		return
+	}
+
+	channel := events.Topic(scope)
+	h.join(channel, c)
+	defer h.leave(channel, c)
+
+	stream, unsubscribe := h.broker.Subscribe(channel)
+	defer unsubscribe()
+
+	if err := c.write(Message{Type: MessageReady}); err != nil {
+		return
+	}
+
+	closed := make(chan struct{})
+	go c.readPump(closed)
+
+	ticker := time.NewTicker(pingPeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case <-ticker.C:
+			ws.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := ws.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case e, ok := <-stream:
+			if !ok {
+				ws.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+					time.Now().Add(writeWait))
+				return
+			}
+			if !events.Allowed(r.Context(), h.access, scope, e) {
+				continue
+			}
+			if err := c.write(Message{Type: MessageEvent, Event: &e}); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (h *Hub) authenticate(ctx context.Context, ws *websocket.Conn) (models.Scope, error) {
+	ws.SetReadDeadline(time.Now().Add(authTimeout))
+
+	var msg Message
+	if err := ws.ReadJSON(&msg); err != nil {
+		return models.Scope{}, err
+	}
+	if msg.Type != MessageAuth || msg.Token == "" {
+		return models.Scope{}, errNotAuth
+	}
+
+	claims, err := h.sessions.Verify(ctx, msg.Token)
+	if err != nil {
+		return models.Scope{}, err
+	}
+	return middlewares.SessionUser(claims).Scope(), nil
+}
+
+func (h *Hub) join(channel string, c *conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.channels[channel] == nil {
+		h.channels[channel] = map[*conn]struct{}{}
+	}
+	h.channels[channel][c] = struct{}{}
+}
+
+func (h *Hub) leave(channel string, c *conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.channels[channel], c)
+	if len(h.channels[channel]) == 0 {
+		delete(h.channels, channel)
+	}
+}
+
+type conn struct {
+	ws *websocket.Conn
+}
+
+func (c *conn) write(msg Message) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		slog.Error("Failed to encode websocket message", "error", err)
+		return err
+	}
+	c.ws.SetWriteDeadline(time.Now().Add(writeWait))
+	return c.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// readPump keeps the read deadline moving on pongs and discards anything
+// else the client sends. It closes closed when the connection goes away.
+func (c *conn) readPump(closed chan<- struct{}) {
+	defer close(closed)
+
+	c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+	for {
+		if _, _, err := c.ws.NextReader(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package recurrence wraps RFC 5545 RRULEs for recurring tasks. Rules are
+// stored without DTSTART; the task's due date is the anchor.
+package recurrence
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/teambition/rrule-go"
+)
+
+const maxRuleLength = 500
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+// Parse validates rule, accepting it with or without a leading "RRULE:".
+func Parse(rule string) (*rrule.ROption, error) {
+	rule = strings.TrimPrefix(strings.TrimSpace(rule), "RRULE:")
+	if rule == "" || len(rule) > maxRuleLength || strings.ContainsAny(rule, "\r\n") {
+		return nil, ErrInvalidRule
+	}
+
+	opt, err := rrule.StrToROption(rule)
+	if err != nil {
+		return nil, ErrInvalidRule
+	}
+	// Anything finer than hourly would flood the task list.
+	if opt.Freq == rrule.MINUTELY || opt.Freq == rrule.SECONDLY {
+		return nil, ErrInvalidRule
+	}
+	if _, err := rrule.NewRRule(*opt); err != nil {
+		return nil, ErrInvalidRule
+	}
+	return opt, nil
+}
+
+// Normalize returns the canonical form of rule, which is what gets stored.
+func Normalize(rule string) (string, error) {
+	opt, err := Parse(rule)
+	if err != nil {
+		return "", err
+	}
+	return opt.RRuleString(), nil
+}
+
+// Preview returns up to n occurrences of rule starting at start (inclusive).
+func Preview(rule string, start time.Time, n int) ([]time.Time, error) {
+	opt, err := Parse(rule)
+	if err != nil {
+		return nil, err
+	}
+	opt.Dtstart = start
+
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return nil, ErrInvalidRule
+	}
+
+	out := []time.Time{}
+	next := r.Iterator()
+	for len(out) < n {
+		t, ok := next()
+		if !ok {
+			break
+		}
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+// Next returns the occurrence after current, treating current as the first
+// occurrence, along with the rule the next task should carry. A COUNT limit
+// is decremented so the series still ends where it was meant to. ok is false
+// once the series is exhausted.
+func Next(rule string, current time.Time) (next time.Time, nextRule string, ok bool, err error) {
+	opt, err := Parse(rule)
+	if err != nil {
+		return time.Time{}, "", false, err
+	}
+
+	if opt.Count == 1 {
+		return time.Time{}, "", false, nil
+	}
+
+	opt.Dtstart = current
+	r, err := rrule.NewRRule(*opt)
+	if err != nil {
+		return time.Time{}, "", false, ErrInvalidRule
+	}
+
+	next = r.After(current, false)
+	if next.IsZero() {
+		return time.Time{}, "", false, nil
+	}
+
+	if opt.Count > 0 {
+		opt.Count--
+	}
+	opt.Dtstart = time.Time{}
+	return next, opt.RRuleString(), true, nil
+}
@@ -0,0 +1,72 @@
+// Package reminders delivers task reminders once they come due.
+package reminders
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/store"
+)
+
+const batchSize = 100
+
+// Scheduler polls for due reminders every Interval and hands them to the
+// Notifier. Reminders are claimed before delivery, so a failed send is
+// logged rather than retried.
+type Scheduler struct {
+	Reminders store.ReminderStore
+	Notifier  notify.Notifier
+	Interval  time.Duration
+}
+
+func NewScheduler(reminders store.ReminderStore, notifier notify.Notifier, interval time.Duration) *Scheduler {
+	return &Scheduler{Reminders: reminders, Notifier: notifier, Interval: interval}
+}
+
+// Start runs the scheduler until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(s.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run delivers everything that is currently due, a batch at a time.
+func (s *Scheduler) Run(ctx context.Context) {
+	for {
+		due, err := s.Reminders.ClaimDue(ctx, batchSize)
+		if err != nil {
+			slog.Error("Failed to claim due reminders", "error", err)
+			return
+		}
+
+		for _, d := range due {
+			err := s.Notifier.Notify(ctx, notify.Notification{
+				Kind:    notify.KindReminder,
+				UserID:  d.Reminder.UserID,
+				OrgID:   d.Task.OrgID,
+				TaskID:  d.Task.ID,
+				Title:   d.Task.Title,
+				Body:    d.Task.Description,
+				DueDate: d.Task.DueDate,
+			})
+			if err != nil {
+				slog.Error("Failed to deliver reminder", "reminder_id", d.Reminder.ID, "error", err)
+			}
+		}
+
+		if len(due) < batchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,273 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const dataExportColumns = `id, user_id, status, key, size, error, created_at, finished_at, expires_at`
+
+type AccountRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAccountRepository(pool *pgxpool.Pool) *AccountRepository {
+	return &AccountRepository{pool: pool}
+}
+
+func scanDataExport(row pgx.Row) (*models.DataExport, error) {
+	var e models.DataExport
+	err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.Key, &e.Size, &e.Error, &e.CreatedAt, &e.FinishedAt, &e.ExpiresAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateExport leans on the unique index of unfinished exports: losing the
+// insert to one means there's one to return.
+func (r *AccountRepository) CreateExport(ctx context.Context, userID string) (*models.DataExport, error) {
+	e, err := scanDataExport(r.pool.QueryRow(ctx,
+		`INSERT INTO data_exports (user_id) VALUES ($1)
+		 ON CONFLICT (user_id) WHERE status IN ('pending', 'running') DO NOTHING
+		 RETURNING `+dataExportColumns,
+		userID,
+	))
+	if !errors.Is(err, ErrNotFound) {
+		return e, err
+	}
+	return scanDataExport(r.pool.QueryRow(ctx,
+		`SELECT `+dataExportColumns+` FROM data_exports
+		 WHERE user_id = $1 AND status IN ('pending', 'running')`,
+		userID,
+	))
+}
+
+func (r *AccountRepository) GetExport(ctx context.Context, userID, id string) (*models.DataExport, error) {
+	return scanDataExport(r.pool.QueryRow(ctx,
+		`SELECT `+dataExportColumns+` FROM data_exports WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	))
+}
+
+func (r *AccountRepository) StartExport(ctx context.Context, id string) (*models.DataExport, error) {
+	return scanDataExport(r.pool.QueryRow(ctx,
+		`UPDATE data_exports SET status = 'running'
+		 WHERE id = $1 AND status = 'pending'
+		 RETURNING `+dataExportColumns,
+		id,
+	))
+}
+
+func (r *AccountRepository) FinishExport(ctx context.Context, id, key string, size int64, expiresAt time.Time) error {
+	return r.finish(ctx,
+		`UPDATE data_exports SET status = 'done', key = $2, size = $3, expires_at = $4, finished_at = NOW()
+		 WHERE id = $1`,
+		id, key, size, expiresAt,
+	)
+}
+
+func (r *AccountRepository) FailExport(ctx context.Context, id, message string) error {
+	return r.finish(ctx,
+		`UPDATE data_exports SET status = 'failed', error = $2, finished_at = NOW() WHERE id = $1`,
+		id, message,
+	)
+}
+
+func (r *AccountRepository) finish(ctx context.Context, query string, args ...any) error {
+	tag, err := r.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// collect runs query in tx and scans every row with scan.
+func collect[T any](ctx context.Context, tx pgx.Tx, scan func(pgx.Row) (*T, error), query string, args ...any) ([]T, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []T{}
+	for rows.Next() {
+		v, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *v)
+	}
+	return list, rows.Err()
+}
+
+// Collect reads everything in one snapshot, so the sections agree with
+// each other.
+func (r *AccountRepository) Collect(ctx context.Context, userID string) (*models.AccountData, error) {
+	data := &models.AccountData{}
+	opts := pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly}
+	err := pgx.BeginTxFunc(ctx, r.pool, opts, func(tx pgx.Tx) error {
+		var u models.User
+		err := tx.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, userID).
+			Scan(&u.ID, &u.Email, &u.FirstName, &u.LastName, &u.Username, &u.ImageURL, &u.UpdatedAt, &u.DeletedAt)
+		switch {
+		case err == nil:
+			data.User = &u
+		case !errors.Is(err, pgx.ErrNoRows):
+			return err
+		}
+
+		if data.Memberships, err = collect(ctx, tx, func(row pgx.Row) (*models.OrgMembership, error) {
+			var m models.OrgMembership
+			return &m, row.Scan(&m.OrgID, &m.UserID, &m.Role, &m.UpdatedAt)
+		}, `SELECT org_id, user_id, role, updated_at FROM org_memberships WHERE user_id = $1 ORDER BY org_id`, userID); err != nil {
+			return err
+		}
+
+		if data.Settings, err = getSettings(ctx, tx, userID, false); err != nil {
+			return err
+		}
+		data.EmailPreferences = models.DefaultEmailPreferences(userID)
+		p := &data.EmailPreferences
+		err = tx.QueryRow(ctx,
+			`SELECT task_assigned, due_soon, comment_mention, updated_at FROM email_preferences WHERE user_id = $1`,
+			userID,
+		).Scan(&p.TaskAssigned, &p.DueSoon, &p.CommentMention, &p.UpdatedAt)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if data.Tasks, err = collect(ctx, tx, scanTask,
+			`SELECT `+taskColumns+` FROM tasks WHERE owner_id = $1 ORDER BY created_at, id`, userID); err != nil {
+			return err
+		}
+		if data.Comments, err = collect(ctx, tx, scanComment,
+			`SELECT `+commentColumns+` FROM comments WHERE author_id = $1 ORDER BY created_at, id`, userID); err != nil {
+			return err
+		}
+		if data.TimeEntries, err = collect(ctx, tx, scanTimeEntry,
+			`SELECT `+timeEntryColumns+` FROM time_entries WHERE user_id = $1 ORDER BY started_at, id`, userID); err != nil {
+			return err
+		}
+		if data.Pomodoros, err = collect(ctx, tx, scanPomodoro,
+			`SELECT `+pomodoroColumns+` FROM pomodoros WHERE user_id = $1 ORDER BY started_at, id`, userID); err != nil {
+			return err
+		}
+		if data.Activity, err = collect(ctx, tx, func(row pgx.Row) (*models.Activity, error) {
+			var a models.Activity
+			return &a, row.Scan(&a.ID, &a.OrgID, &a.ActorID, &a.TaskID, &a.ProjectID, &a.Action, &a.Changes, &a.CreatedAt)
+		}, `SELECT `+activityColumns+` FROM activity WHERE actor_id = $1 ORDER BY created_at, id`, userID); err != nil {
+			return err
+		}
+		data.Notifications, err = collect(ctx, tx, func(row pgx.Row) (*models.Notification, error) {
+			var n models.Notification
+			return &n, row.Scan(notificationFields(&n)...)
+		}, `SELECT `+notificationColumns+` FROM notifications WHERE user_id = $1 ORDER BY created_at, id`, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// eraseStatements run in order with the user's id as $1 and
+// models.DeletedUserID as $2. Deleting a task takes what hangs off it with
+// it, through the foreign keys; attachment objects are left to the bucket's
+// lifecycle rules, as when a task is purged from the trash.
+var eraseStatements = []string{
+	// Tasks only they could see.
+	`DELETE FROM tasks WHERE owner_id = $1 AND (org_id IS NULL OR visibility = 'private')`,
+	`DELETE FROM sync_ops WHERE owner_id = $1 AND org_id IS NULL`,
+
+	// What only concerns them.
+	`DELETE FROM task_reminders WHERE user_id = $1`,
+	`DELETE FROM notifications WHERE user_id = $1`,
+	`DELETE FROM pomodoros WHERE user_id = $1`,
+	`DELETE FROM task_shares WHERE user_id = $1`,
+	`DELETE FROM project_shares WHERE user_id = $1`,
+	`DELETE FROM views WHERE owner_id = $1`,
+	`DELETE FROM task_templates WHERE owner_id = $1`,
+	`DELETE FROM user_settings WHERE user_id = $1`,
+	`DELETE FROM email_preferences WHERE user_id = $1`,
+	`DELETE FROM push_subscriptions WHERE user_id = $1`,
+	`DELETE FROM api_tokens WHERE user_id = $1`,
+	`DELETE FROM calendar_feeds WHERE user_id = $1`,
+	`DELETE FROM google_calendar_connections WHERE user_id = $1`,
+	`DELETE FROM telegram_links WHERE user_id = $1`,
+	`DELETE FROM telegram_link_codes WHERE user_id = $1`,
+	`DELETE FROM inbound_addresses WHERE user_id = $1`,
+	`DELETE FROM imports WHERE user_id = $1`,
+	`DELETE FROM idempotency_keys WHERE user_id = $1`,
+
+	// What the orgs they were in keep. A running timer is stopped, since
+	// only its user could.
+	`UPDATE tasks SET owner_id = $2 WHERE owner_id = $1`,
+	`UPDATE sync_ops SET owner_id = $2 WHERE owner_id = $1`,
+	`UPDATE comments SET author_id = $2 WHERE author_id = $1`,
+	`UPDATE activity SET actor_id = $2 WHERE actor_id = $1`,
+	`UPDATE outbox SET actor_id = $2 WHERE actor_id = $1`,
+	`UPDATE notifications SET actor_id = NULL WHERE actor_id = $1`,
+	`UPDATE time_entries SET user_id = $2, ended_at = COALESCE(ended_at, NOW()), updated_at = NOW() WHERE user_id = $1`,
+	`UPDATE attachments SET uploader_id = $2 WHERE uploader_id = $1`,
+	`UPDATE projects SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE labels SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE boards SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE project_templates SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE custom_fields SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE webhook_endpoints SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE share_links SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE task_shares SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE project_shares SET created_by = $2 WHERE created_by = $1`,
+	`UPDATE project_invitations SET invited_by = $2 WHERE invited_by = $1`,
+	`UPDATE project_invitations SET accepted_by = $2 WHERE accepted_by = $1`,
+
+	// The tombstone, like DeleteUser's but without the profile.
+	`INSERT INTO users (id, updated_at, deleted_at) VALUES ($1, NOW(), NOW())
+	 ON CONFLICT (id) DO UPDATE SET
+		email = NULL, first_name = NULL, last_name = NULL, username = NULL, image_url = NULL,
+		deleted_at = COALESCE(users.deleted_at, NOW())`,
+}
+
+func (r *AccountRepository) Erase(ctx context.Context, userID string) (models.ErasedAccount, error) {
+	var erased models.ErasedAccount
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, statement := range eraseStatements {
+			if _, err := tx.Exec(ctx, statement, userID, models.DeletedUserID); err != nil {
+				return err
+			}
+		}
+
+		orgIDs, err := collect(ctx, tx, func(row pgx.Row) (*string, error) {
+			var id string
+			return &id, row.Scan(&id)
+		}, `DELETE FROM org_memberships WHERE user_id = $1 RETURNING org_id`, userID)
+		if err != nil {
+			return err
+		}
+		keys, err := collect(ctx, tx, func(row pgx.Row) (*string, error) {
+			var key string
+			return &key, row.Scan(&key)
+		}, `DELETE FROM data_exports WHERE user_id = $1 AND key IS NOT NULL RETURNING key`, userID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM data_exports WHERE user_id = $1`, userID); err != nil {
+			return err
+		}
+		erased = models.ErasedAccount{OrgIDs: orgIDs, ExportKeys: keys}
+		return nil
+	})
+	return erased, err
+}
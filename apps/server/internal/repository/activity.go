@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const activityColumns = `id, org_id, actor_id, task_id, project_id, action, changes, created_at`
+
+// ActivityRepository records on the primary and lists from reads.
+type ActivityRepository struct {
+	pool  *pgxpool.Pool
+	reads Reader
+}
+
+func NewActivityRepository(pool *pgxpool.Pool, reads Reader) *ActivityRepository {
+	return &ActivityRepository{pool: pool, reads: reads}
+}
+
+func (r *ActivityRepository) Record(ctx context.Context, input models.CreateActivityInput) error {
+	changes := input.Changes
+	if changes == nil {
+		changes = map[string]models.FieldChange{}
+	}
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO activity (org_id, actor_id, task_id, project_id, action, changes)
+		 VALUES ($1, $2, $3, $4, $5, $6)`,
+		input.OrgID, input.ActorID, input.TaskID, input.ProjectID, input.Action, changes,
+	)
+	return err
+}
+
+// ListForTask returns up to page.Limit+1 entries for the task, newest first.
+// Personal tasks only ever have their owner as the actor.
+func (r *ActivityRepository) ListForTask(ctx context.Context, scope models.Scope, taskID string, page models.Page) ([]models.Activity, error) {
+	where, arg := `org_id = $2`, any(scope.OrgID)
+	if !scope.IsOrg() {
+		where, arg = `org_id IS NULL AND actor_id = $2`, scope.UserID
+	}
+	return r.list(ctx, `task_id = $1 AND `+where, page, taskID, arg)
+}
+
+// ListForOrg returns up to page.Limit+1 entries across the org, newest first.
+func (r *ActivityRepository) ListForOrg(ctx context.Context, orgID string, page models.Page) ([]models.Activity, error) {
+	return r.list(ctx, `org_id = $1`, page, orgID)
+}
+
+// ListOwnerChanges returns the task's entries that changed its owner,
+// oldest first.
+func (r *ActivityRepository) ListOwnerChanges(ctx context.Context, scope models.Scope, taskID string) ([]models.Activity, error) {
+	where, arg := `org_id = $2`, any(scope.OrgID)
+	if !scope.IsOrg() {
+		where, arg = `org_id IS NULL AND actor_id = $2`, scope.UserID
+	}
+	rows, err := r.reads.Query(ctx,
+		`SELECT `+activityColumns+` FROM activity
+		 WHERE task_id = $1 AND `+where+` AND changes ? 'ownerId'
+		 ORDER BY created_at, id`,
+		taskID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanActivity(rows)
+}
+
+func (r *ActivityRepository) list(ctx context.Context, where string, page models.Page, args ...any) ([]models.Activity, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	n := len(args)
+	args = append(args, afterTime, afterID, page.Limit+1)
+	rows, err := r.reads.Query(ctx,
+		`SELECT `+activityColumns+` FROM activity
+		 WHERE `+where+fmt.Sprintf(`
+		   AND ($%[1]d::timestamptz IS NULL OR (created_at, id) < ($%[1]d, $%[2]d::uuid))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $%[3]d`, n+1, n+2, n+3),
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanActivity(rows)
+}
+
+func scanActivity(rows pgx.Rows) ([]models.Activity, error) {
+	entries := []models.Activity{}
+	for rows.Next() {
+		var a models.Activity
+		if err := rows.Scan(&a.ID, &a.OrgID, &a.ActorID, &a.TaskID, &a.ProjectID, &a.Action, &a.Changes, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, a)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const apiTokenColumns = `id, user_id, org_id, name, prefix, scopes, expires_at, last_used_at, created_at`
+
+type APITokenRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAPITokenRepository(pool *pgxpool.Pool) *APITokenRepository {
+	return &APITokenRepository{pool: pool}
+}
+
+func scanAPIToken(row pgx.Row) (*models.APIToken, error) {
+	var t models.APIToken
+	err := row.Scan(&t.ID, &t.UserID, &t.OrgID, &t.Name, &t.Prefix, &t.Scopes, &t.ExpiresAt, &t.LastUsedAt, &t.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *APITokenRepository) Create(ctx context.Context, scope models.Scope, input models.CreateAPITokenInput) (*models.APIToken, error) {
+	return scanAPIToken(r.pool.QueryRow(ctx,
+		`INSERT INTO api_tokens (user_id, org_id, name, prefix, token_hash, scopes, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+apiTokenColumns,
+		scope.UserID, scope.OrgIDPtr(), input.Name, input.Prefix, input.TokenHash, input.Scopes, input.ExpiresAt,
+	))
+}
+
+func (r *APITokenRepository) ListForUser(ctx context.Context, userID string) ([]models.APIToken, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+apiTokenColumns+` FROM api_tokens WHERE user_id = $1 ORDER BY created_at DESC, id DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tokens := []models.APIToken{}
+	for rows.Next() {
+		t, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *t)
+	}
+	return tokens, rows.Err()
+}
+
+func (r *APITokenRepository) Delete(ctx context.Context, userID, id string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM api_tokens WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Authenticate finds the unexpired token with tokenHash and records the
+// use in the same statement.
+func (r *APITokenRepository) Authenticate(ctx context.Context, tokenHash []byte) (*models.APIToken, error) {
+	return scanAPIToken(r.pool.QueryRow(ctx,
+		`UPDATE api_tokens SET last_used_at = NOW()
+		 WHERE token_hash = $1 AND (expires_at IS NULL OR expires_at > NOW())
+		 RETURNING `+apiTokenColumns,
+		tokenHash,
+	))
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// SetArchived archives or unarchives a single task. Either way updated_at
+// moves, so an unarchived task isn't swept straight back by ArchiveCompleted.
+func (r *TaskRepository) SetArchived(ctx context.Context, scope models.Scope, id string, archived bool) (*models.Task, error) {
+	where, arg := editableTaskClause(scope, 3)
+	row := r.pool.QueryRow(ctx,
+		`UPDATE tasks
+		 SET archived_at = CASE WHEN $2 THEN COALESCE(archived_at, NOW()) END, version = version + 1, updated_at = NOW()
+		 WHERE id = $1 AND `+where+`
+		 RETURNING `+taskColumns,
+		id, archived, arg,
+	)
+	t, err := scanTask(row)
+	if errors.Is(err, ErrNotFound) {
+		return nil, r.writeMiss(ctx, scope, id, nil)
+	}
+	return t, err
+}
+
+// Snooze re-arms the caller's reminders in the same transaction, so they
+// go off again when the snooze ends.
+func (r *TaskRepository) Snooze(ctx context.Context, scope models.Scope, id string, until *time.Time) (*models.Task, error) {
+	var task *models.Task
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		where, arg := editableTaskClause(scope, 3)
+		var err error
+		task, err = scanTask(tx.QueryRow(ctx,
+			`UPDATE tasks SET snoozed_until = $2, version = version + 1, updated_at = NOW()
+			 WHERE id = $1 AND `+where+`
+			 RETURNING `+taskColumns,
+			id, until, arg,
+		))
+		if err != nil || until == nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE task_reminders SET sent_at = NULL WHERE task_id = $1 AND user_id = $2 AND sent_at IS NOT NULL`,
+			id, scope.UserID,
+		)
+		return err
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, r.writeMiss(ctx, scope, id, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// doneStatusKeys lists the done statuses of the org task t belongs to,
+// falling back to the default workflow for personal tasks and orgs that
+// haven't configured one.
+const doneStatusKeys = `
+	SELECT s->>'key' FROM org_workflows w, jsonb_array_elements(w.statuses) s
+	WHERE w.org_id = t.org_id AND (s->>'done')::boolean
+	UNION ALL
+	SELECT $2::text WHERE NOT EXISTS (
+		SELECT 1 FROM org_workflows w WHERE w.org_id = t.org_id AND w.statuses IS NOT NULL
+	)`
+
+// ArchiveCompleted archives up to limit done tasks that haven't changed
+// since before, returning how many it archived.
+func (r *TaskRepository) ArchiveCompleted(ctx context.Context, before time.Time, limit int) (int, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE tasks SET archived_at = NOW(), version = version + 1
+		 WHERE id IN (
+			SELECT t.id FROM tasks t
+			WHERE t.archived_at IS NULL AND t.deleted_at IS NULL AND t.updated_at < $1
+			  AND t.status IN (`+doneStatusKeys+`)
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		 )`,
+		before, models.TaskStatusDone, limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
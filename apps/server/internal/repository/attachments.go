@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/crypto"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const attachmentColumns = `id, task_id, uploader_id, key, filename, content_type, size, confirmed_at, created_at`
+
+// AttachmentRepository stores filenames and content types encrypted with
+// fields, which can be nil to store them in the clear. The rest of a row is
+// left in the clear on purpose: the ids of the task and uploader, which
+// queries join and filter on, the size, which quotas sum, the timestamps,
+// and the object key, which is random apart from a fixed name. The
+// contents are in object storage, for its own encryption at rest.
+type AttachmentRepository struct {
+	pool   *pgxpool.Pool
+	fields *crypto.Fields
+}
+
+func NewAttachmentRepository(pool *pgxpool.Pool, fields *crypto.Fields) *AttachmentRepository {
+	return &AttachmentRepository{pool: pool, fields: fields}
+}
+
+// dataKeyOwner is whose data key seals a scope's attachments: its org's,
+// or for personal tasks its user's. Every query goes through the scope's
+// access clause, so it's also the owner of any row read back.
+func dataKeyOwner(scope models.Scope) string {
+	if scope.IsOrg() {
+		return scope.OrgID
+	}
+	return scope.UserID
+}
+
+func (r *AttachmentRepository) scan(ctx context.Context, scope models.Scope, row pgx.Row) (*models.Attachment, error) {
+	var a models.Attachment
+	err := row.Scan(&a.ID, &a.TaskID, &a.UploaderID, &a.Key, &a.Filename, &a.ContentType, &a.Size, &a.ConfirmedAt, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	owner := dataKeyOwner(scope)
+	if a.Filename, err = r.fields.Decrypt(ctx, owner, a.Filename); err != nil {
+		return nil, err
+	}
+	if a.ContentType, err = r.fields.Decrypt(ctx, owner, a.ContentType); err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// Create records a pending attachment on the task, which has to be live and
+// in scope.
+func (r *AttachmentRepository) Create(ctx context.Context, scope models.Scope, taskID string, input models.CreateAttachmentInput) (*models.Attachment, error) {
+	owner := dataKeyOwner(scope)
+	filename, err := r.fields.Encrypt(ctx, owner, input.Filename)
+	if err != nil {
+		return nil, err
+	}
+	contentType, err := r.fields.Encrypt(ctx, owner, input.ContentType)
+	if err != nil {
+		return nil, err
+	}
+
+	where, arg := editableTaskClause(scope, 7)
+	return r.scan(ctx, scope, r.pool.QueryRow(ctx,
+		`INSERT INTO attachments (task_id, uploader_id, key, filename, content_type, size)
+		 SELECT id, $2, $3, $4, $5, $6 FROM tasks WHERE id = $1 AND `+where+`
+		 RETURNING `+attachmentColumns,
+		taskID, scope.UserID, input.Key, filename, contentType, input.Size, arg,
+	))
+}
+
+func (r *AttachmentRepository) Get(ctx context.Context, scope models.Scope, taskID, id string) (*models.Attachment, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	return r.scan(ctx, scope, r.pool.QueryRow(ctx,
+		`SELECT `+qualifiedColumns("a", attachmentColumns)+`
+		 FROM attachments a JOIN tasks t ON t.id = a.task_id
+		 WHERE a.id = $1 AND a.task_id = $2 AND `+where,
+		id, taskID, arg,
+	))
+}
+
+// List returns the task's confirmed attachments, oldest first.
+func (r *AttachmentRepository) List(ctx context.Context, scope models.Scope, taskID string) ([]models.Attachment, error) {
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+qualifiedColumns("a", attachmentColumns)+`
+		 FROM attachments a JOIN tasks t ON t.id = a.task_id
+		 WHERE a.task_id = $1 AND a.confirmed_at IS NOT NULL AND `+where+`
+		 ORDER BY a.created_at, a.id`,
+		taskID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []models.Attachment{}
+	for rows.Next() {
+		a, err := r.scan(ctx, scope, rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *a)
+	}
+	return attachments, rows.Err()
+}
+
+// Confirm marks the attachment as uploaded; confirming twice is a no-op.
+func (r *AttachmentRepository) Confirm(ctx context.Context, scope models.Scope, taskID, id string) (*models.Attachment, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleEditor)
+	return r.scan(ctx, scope, r.pool.QueryRow(ctx,
+		`UPDATE attachments a SET confirmed_at = COALESCE(a.confirmed_at, NOW())
+		 FROM tasks t
+		 WHERE a.id = $1 AND a.task_id = $2 AND t.id = a.task_id AND `+where+`
+		 RETURNING `+qualifiedColumns("a", attachmentColumns),
+		id, taskID, arg,
+	))
+}
+
+func (r *AttachmentRepository) Delete(ctx context.Context, scope models.Scope, taskID, id string) error {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleEditor)
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM attachments a USING tasks t
+		 WHERE a.id = $1 AND a.task_id = $2 AND t.id = a.task_id AND `+where,
+		id, taskID, arg,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeletePending leaves out attachments confirmed while it runs, as the
+// delete checks confirmed_at itself.
+func (r *AttachmentRepository) DeletePending(ctx context.Context, cutoff time.Time) ([]string, error) {
+	rows, err := r.pool.Query(ctx,
+		`DELETE FROM attachments WHERE confirmed_at IS NULL AND created_at < $1 RETURNING key`,
+		cutoff,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
@@ -0,0 +1,340 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const boardColumns = `id, org_id, project_id, name, created_by, created_at, updated_at`
+
+type BoardRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewBoardRepository(pool *pgxpool.Pool) *BoardRepository {
+	return &BoardRepository{pool: pool}
+}
+
+func scanBoard(row pgx.Row) (*models.Board, error) {
+	var b models.Board
+	err := row.Scan(&b.ID, &b.OrgID, &b.ProjectID, &b.Name, &b.CreatedBy, &b.CreatedAt, &b.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// rowsQuerier is what both the pool and a transaction can list rows with.
+type rowsQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func loadBoardColumns(ctx context.Context, q rowsQuerier, b *models.Board) error {
+	rows, err := q.Query(ctx,
+		`SELECT id, name, status, position FROM board_columns WHERE board_id = $1 ORDER BY position`,
+		b.ID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	b.Columns = []models.BoardColumn{}
+	for rows.Next() {
+		var col models.BoardColumn
+		if err := rows.Scan(&col.ID, &col.Name, &col.Status, &col.Position); err != nil {
+			return err
+		}
+		b.Columns = append(b.Columns, col)
+	}
+	return rows.Err()
+}
+
+// setBoardColumns makes columns the board's, in order, keeping the ids of
+// the ones whose status was already there.
+func setBoardColumns(ctx context.Context, tx pgx.Tx, boardID string, columns []models.BoardColumnInput) error {
+	statuses := make([]string, len(columns))
+	for i, col := range columns {
+		statuses[i] = col.Status
+	}
+	if _, err := tx.Exec(ctx,
+		`DELETE FROM board_columns WHERE board_id = $1 AND status <> ALL($2)`,
+		boardID, statuses,
+	); err != nil {
+		return err
+	}
+	for i, col := range columns {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO board_columns (board_id, name, status, position) VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (board_id, status) DO UPDATE SET name = EXCLUDED.name, position = EXCLUDED.position`,
+			boardID, col.Name, col.Status, i,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertBoard returns ErrNotFound unless the project is live in the
+// scope's org.
+func insertBoard(ctx context.Context, tx pgx.Tx, scope models.Scope, projectID string, input models.CreateBoardInput) (*models.Board, error) {
+	b, err := scanBoard(tx.QueryRow(ctx,
+		`INSERT INTO boards (org_id, project_id, name, created_by)
+		 SELECT org_id, id, $3, $4 FROM projects WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+		 RETURNING `+boardColumns,
+		projectID, scope.OrgID, input.Name, scope.UserID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := setBoardColumns(ctx, tx, b.ID, input.Columns); err != nil {
+		return nil, err
+	}
+	if err := loadBoardColumns(ctx, tx, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Create returns ErrNotFound unless the project is live in the scope's org.
+func (r *BoardRepository) Create(ctx context.Context, scope models.Scope, projectID string, input models.CreateBoardInput) (*models.Board, error) {
+	var b *models.Board
+	err := database.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		b, err = insertBoard(ctx, tx, scope, projectID, input)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *BoardRepository) Get(ctx context.Context, scope models.Scope, id string) (*models.Board, error) {
+	b, err := scanBoard(r.pool.QueryRow(ctx,
+		`SELECT `+boardColumns+` FROM boards WHERE id = $1 AND org_id = $2`,
+		id, scope.OrgID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	if err := loadBoardColumns(ctx, r.pool, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// ListForProject returns the project's boards by name, without columns.
+func (r *BoardRepository) ListForProject(ctx context.Context, scope models.Scope, projectID string) ([]models.Board, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+boardColumns+` FROM boards WHERE project_id = $1 AND org_id = $2 ORDER BY name, id`,
+		projectID, scope.OrgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	boards := []models.Board{}
+	for rows.Next() {
+		b, err := scanBoard(rows)
+		if err != nil {
+			return nil, err
+		}
+		boards = append(boards, *b)
+	}
+	return boards, rows.Err()
+}
+
+func (r *BoardRepository) Update(ctx context.Context, scope models.Scope, id string, input models.UpdateBoardInput) (*models.Board, error) {
+	var b *models.Board
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		b, err = scanBoard(tx.QueryRow(ctx,
+			`UPDATE boards SET name = COALESCE($3, name), updated_at = NOW()
+			 WHERE id = $1 AND org_id = $2
+			 RETURNING `+boardColumns,
+			id, scope.OrgID, input.Name,
+		))
+		if err != nil {
+			return err
+		}
+		if input.Columns != nil {
+			if err := setBoardColumns(ctx, tx, id, input.Columns); err != nil {
+				return err
+			}
+		}
+		return loadBoardColumns(ctx, tx, b)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *BoardRepository) Delete(ctx context.Context, scope models.Scope, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM boards WHERE id = $1 AND org_id = $2`, id, scope.OrgID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *BoardRepository) Cards(ctx context.Context, scope models.Scope, id string) (*models.Board, error) {
+	b, err := r.Get(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]string, len(b.Columns))
+	byStatus := map[string]int{}
+	for i, col := range b.Columns {
+		statuses[i] = col.Status
+		byStatus[col.Status] = i
+		b.Columns[i].Tasks = []models.Task{}
+	}
+
+	where, arg := liveTaskAccess("t", scope, 4, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+qualifiedColumns("t", taskColumns)+` FROM tasks t
+		 LEFT JOIN board_cards c ON c.board_id = $1 AND c.task_id = t.id
+		 WHERE t.project_id = $2 AND t.status = ANY($3) AND t.archived_at IS NULL AND `+where+`
+		 ORDER BY c.position NULLS LAST, t.created_at, t.id`,
+		b.ID, b.ProjectID, statuses, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		col := &b.Columns[byStatus[t.Status]]
+		col.Tasks = append(col.Tasks, *t)
+	}
+	return b, rows.Err()
+}
+
+// Move locks the board while it works, so concurrent moves on it can't
+// interleave their renumbering of a column.
+func (r *BoardRepository) Move(ctx context.Context, scope models.Scope, id string, input models.MoveCardInput) (*models.BoardMove, error) {
+	var move *models.BoardMove
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var projectID, status string
+		err := tx.QueryRow(ctx,
+			`SELECT b.project_id, c.status FROM boards b JOIN board_columns c ON c.board_id = b.id
+			 WHERE b.id = $1 AND b.org_id = $2 AND c.id = $3
+			 FOR UPDATE OF b`,
+			id, scope.OrgID, input.ColumnID,
+		).Scan(&projectID, &status)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+		task, err := scanTask(tx.QueryRow(ctx,
+			`SELECT `+qualifiedColumns("t", taskColumns)+` FROM tasks t
+			 WHERE t.id = $1 AND t.project_id = $2 AND `+where+`
+			 FOR UPDATE`,
+			input.TaskID, projectID, arg,
+		))
+		if err != nil {
+			return err
+		}
+		move = &models.BoardMove{ColumnID: input.ColumnID, PreviousStatus: task.Status}
+
+		if task.Status != status {
+			wasCompleted := task.CompletedAt != nil
+			editable, editArg := editableTaskClause(scope, 3)
+			task, err = scanTask(tx.QueryRow(ctx,
+				`UPDATE tasks SET status = $2, version = version + 1, updated_at = NOW()
+				 WHERE id = $1 AND `+editable+`
+				 RETURNING `+taskColumns,
+				input.TaskID, status, editArg,
+			))
+			if err == nil && !wasCompleted && task.CompletedAt != nil {
+				err = recordTaskEvent(ctx, tx, scope, models.EventTaskCompleted, task)
+			}
+		} else {
+			editable, editArg := editableTaskClause(scope, 2)
+			err = tx.QueryRow(ctx, `SELECT 1 FROM tasks WHERE id = $1 AND `+editable, input.TaskID, editArg).Scan(new(int))
+			if errors.Is(err, pgx.ErrNoRows) {
+				err = ErrNotFound
+			}
+		}
+		if errors.Is(err, ErrNotFound) {
+			return ErrReadOnly
+		}
+		if err != nil {
+			return err
+		}
+		move.Task = *task
+
+		// The index counts the tasks the caller can see, so the task goes in
+		// ahead of the index-th of those.
+		rows, err := tx.Query(ctx,
+			`SELECT t.id, (`+where+`) FROM tasks t
+			 LEFT JOIN board_cards c ON c.board_id = $1 AND c.task_id = t.id
+			 WHERE t.project_id = $2 AND t.status = $4 AND t.id <> $5 AND t.deleted_at IS NULL AND t.archived_at IS NULL
+			 ORDER BY c.position NULLS LAST, t.created_at, t.id`,
+			id, projectID, arg, status, input.TaskID,
+		)
+		if err != nil {
+			return err
+		}
+		order, seen := []string{}, 0
+		placed := false
+		for rows.Next() {
+			var taskID string
+			var visible bool
+			if err := rows.Scan(&taskID, &visible); err != nil {
+				rows.Close()
+				return err
+			}
+			if visible && seen == input.Index && !placed {
+				order, placed = append(order, input.TaskID), true
+			}
+			if visible {
+				seen++
+			}
+			order = append(order, taskID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		if !placed {
+			order = append(order, input.TaskID)
+		}
+		move.Index = min(input.Index, seen)
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO board_cards (board_id, task_id, position)
+			 SELECT $1, o.task_id, o.n - 1 FROM unnest($2::uuid[]) WITH ORDINALITY AS o(task_id, n)
+			 ON CONFLICT (board_id, task_id) DO UPDATE SET position = EXCLUDED.position`,
+			id, order,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return move, nil
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Bulk runs ops in one transaction. Each op gets a savepoint, so one that
+// fails (say, a task that doesn't exist) is reported and rolled back
+// without undoing the others.
+func (r *TaskRepository) Bulk(ctx context.Context, scope models.Scope, ops []models.BulkTaskOp, completedStatus string, doneStatuses []string) ([]models.BulkTaskResult, error) {
+	results := make([]models.BulkTaskResult, 0, len(ops))
+
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		results = results[:0]
+		for _, op := range ops {
+			result := models.BulkTaskResult{TaskID: op.TaskID, Action: op.Action}
+			err := pgx.BeginFunc(ctx, tx, func(tx pgx.Tx) error {
+				completed, err := bulkApply(ctx, tx, scope, op, completedStatus, doneStatuses)
+				result.Completed = completed
+				return err
+			})
+			switch {
+			case errors.Is(err, ErrNotFound):
+				result.Error = "Task not found"
+			case err != nil:
+				return err
+			default:
+				result.OK = true
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func bulkApply(ctx context.Context, tx pgx.Tx, scope models.Scope, op models.BulkTaskOp, completedStatus string, doneStatuses []string) (*models.Task, error) {
+	where, arg := editableTaskClause(scope, 2)
+
+	switch op.Action {
+	case models.BulkComplete:
+		var done bool
+		if err := tx.QueryRow(ctx,
+			`SELECT status = ANY($3) FROM tasks WHERE id = $1 AND `+where,
+			op.TaskID, arg, doneStatuses,
+		).Scan(&done); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return nil, ErrNotFound
+			}
+			return nil, err
+		}
+		if done {
+			return nil, nil
+		}
+		t, err := scanTask(tx.QueryRow(ctx,
+			`UPDATE tasks SET status = $3, version = version + 1, updated_at = NOW()
+			 WHERE id = $1 AND `+where+`
+			 RETURNING `+taskColumns,
+			op.TaskID, arg, completedStatus,
+		))
+		if err != nil {
+			return nil, err
+		}
+		return t, recordTaskEvent(ctx, tx, scope, models.EventTaskCompleted, t)
+
+	case models.BulkMove:
+		_, err := scanTask(tx.QueryRow(ctx,
+			`UPDATE tasks SET project_id = $3, version = version + 1, updated_at = NOW()
+			 WHERE id = $1 AND `+where+`
+			 RETURNING `+taskColumns,
+			op.TaskID, arg, op.ProjectID,
+		))
+		return nil, err
+
+	case models.BulkRelabel:
+		// Labels only exist in orgs; the handler rejects relabel elsewhere.
+		var found bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND `+where+`)`,
+			op.TaskID, arg,
+		).Scan(&found); err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, ErrNotFound
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM task_labels WHERE task_id = $1`, op.TaskID); err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO task_labels (task_id, label_id)
+			 SELECT $1, id FROM labels WHERE id = ANY($2) AND org_id = $3`,
+			op.TaskID, op.LabelIDs, scope.OrgID,
+		); err != nil {
+			return nil, err
+		}
+		_, err := tx.Exec(ctx, `UPDATE tasks SET version = version + 1, updated_at = NOW() WHERE id = $1`, op.TaskID)
+		return nil, err
+
+	case models.BulkDelete:
+		var deletedAt time.Time
+		err := tx.QueryRow(ctx,
+			`UPDATE tasks SET deleted_at = NOW(), version = version + 1, updated_at = NOW()
+			 WHERE id = $1 AND `+where+`
+			 RETURNING deleted_at`,
+			op.TaskID, arg,
+		).Scan(&deletedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		return nil, trashSubtasks(ctx, tx, []string{op.TaskID}, deletedAt)
+	}
+	return nil, nil
+}
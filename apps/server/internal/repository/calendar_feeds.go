@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const calendarFeedColumns = `id, user_id, org_id, last_used_at, created_at`
+
+type CalendarFeedRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCalendarFeedRepository(pool *pgxpool.Pool) *CalendarFeedRepository {
+	return &CalendarFeedRepository{pool: pool}
+}
+
+func scanCalendarFeed(row pgx.Row) (*models.CalendarFeed, error) {
+	var f models.CalendarFeed
+	err := row.Scan(&f.ID, &f.UserID, &f.OrgID, &f.LastUsedAt, &f.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *CalendarFeedRepository) Get(ctx context.Context, scope models.Scope) (*models.CalendarFeed, error) {
+	return scanCalendarFeed(r.pool.QueryRow(ctx,
+		`SELECT `+calendarFeedColumns+` FROM calendar_feeds
+		 WHERE user_id = $1 AND COALESCE(org_id, '') = $2`,
+		scope.UserID, scope.OrgID,
+	))
+}
+
+// Rotate replaces the whole row, so the feed gets a new id and a clean
+// last_used_at along with the new token.
+func (r *CalendarFeedRepository) Rotate(ctx context.Context, scope models.Scope, tokenHash []byte) (*models.CalendarFeed, error) {
+	return scanCalendarFeed(r.pool.QueryRow(ctx,
+		`INSERT INTO calendar_feeds (user_id, org_id, token_hash) VALUES ($1, $2, $3)
+		 ON CONFLICT (user_id, COALESCE(org_id, '')) DO UPDATE
+		 SET id = gen_random_uuid(), token_hash = EXCLUDED.token_hash, last_used_at = NULL, created_at = NOW()
+		 RETURNING `+calendarFeedColumns,
+		scope.UserID, scope.OrgIDPtr(), tokenHash,
+	))
+}
+
+func (r *CalendarFeedRepository) Delete(ctx context.Context, scope models.Scope) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM calendar_feeds WHERE user_id = $1 AND COALESCE(org_id, '') = $2`,
+		scope.UserID, scope.OrgID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *CalendarFeedRepository) Lookup(ctx context.Context, tokenHash []byte) (*models.CalendarFeed, error) {
+	return scanCalendarFeed(r.pool.QueryRow(ctx,
+		`UPDATE calendar_feeds SET last_used_at = NOW() WHERE token_hash = $1
+		 RETURNING `+calendarFeedColumns,
+		tokenHash,
+	))
+}
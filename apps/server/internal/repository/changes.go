@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ChangeRepository reads change_log, which triggers fill in. Entries are
+// read in transaction order and only up to the oldest transaction still
+// running, its horizon, so a cursor never passes an entry that hasn't
+// committed yet.
+type ChangeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewChangeRepository(pool *pgxpool.Pool) *ChangeRepository {
+	return &ChangeRepository{pool: pool}
+}
+
+func (r *ChangeRepository) horizon(ctx context.Context) (string, error) {
+	var tx string
+	err := r.pool.QueryRow(ctx, `SELECT pg_snapshot_xmin(pg_current_snapshot())::text`).Scan(&tx)
+	return tx, err
+}
+
+func (r *ChangeRepository) Current(ctx context.Context, _ models.Scope) (models.ChangeCursor, error) {
+	tx, err := r.horizon(ctx)
+	if err != nil {
+		return models.ChangeCursor{}, err
+	}
+	return models.ChangeCursor{Tx: tx}, nil
+}
+
+type changeEntry struct {
+	cursor   models.ChangeCursor
+	entity   string
+	entityID string
+}
+
+func (r *ChangeRepository) Since(ctx context.Context, scope models.Scope, after models.ChangeCursor, limit int) (*models.Changes, error) {
+	horizon, err := r.horizon(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if after.Tx == "" {
+		after.Tx = "0"
+	}
+
+	where, arg := scopeClause(scope, 1)
+	if scope.Guest {
+		// Guests only see the tasks they were given.
+		where += ` AND entity = 'task'`
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT tx::text, seq, entity, entity_id FROM change_log
+		 WHERE `+where+` AND (tx, seq) > ($2::text::xid8, $3) AND tx < $4::text::xid8
+		 ORDER BY tx, seq
+		 LIMIT $5`,
+		arg, after.Tx, after.Seq, horizon, limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (changeEntry, error) {
+		var e changeEntry
+		err := row.Scan(&e.cursor.Tx, &e.cursor.Seq, &e.entity, &e.entityID)
+		return e, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &models.Changes{
+		Tasks:    []models.Task{},
+		Projects: []models.Project{},
+		Labels:   []models.Label{},
+		Deleted:  models.Deleted{Tasks: []string{}, Projects: []string{}, Labels: []string{}},
+		Next:     models.ChangeCursor{Tx: horizon},
+	}
+	if len(entries) > limit {
+		entries = entries[:limit]
+		changes.HasMore = true
+		changes.Next = entries[len(entries)-1].cursor
+	}
+
+	ids := map[string][]string{}
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if key := e.entity + "/" + e.entityID; !seen[key] {
+			seen[key] = true
+			ids[e.entity] = append(ids[e.entity], e.entityID)
+		}
+	}
+	if err := r.loadTasks(ctx, scope, ids[models.ChangeTask], changes); err != nil {
+		return nil, err
+	}
+	if err := r.loadProjects(ctx, scope, ids[models.ChangeProject], changes); err != nil {
+		return nil, err
+	}
+	if err := r.loadLabels(ctx, scope, ids[models.ChangeLabel], changes); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// loadTasks adds the live tasks the caller can see and, as deleted, those
+// that are gone or in the trash. Tasks that were only hidden from the
+// caller are left out rather than reveal their ids.
+func (r *ChangeRepository) loadTasks(ctx context.Context, scope models.Scope, ids []string, changes *models.Changes) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	access, arg := taskAccessClause("tasks", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+taskColumns+`, `+access+` FROM tasks WHERE id = ANY($1)`,
+		ids, arg,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := map[string]bool{}
+	for rows.Next() {
+		var t models.Task
+		var visible bool
+		if err := rows.Scan(append(taskFields(&t), &visible)...); err != nil {
+			return err
+		}
+		found[t.ID] = true
+		switch {
+		case !visible:
+		case t.DeletedAt != nil:
+			changes.Deleted.Tasks = append(changes.Deleted.Tasks, t.ID)
+		default:
+			changes.Tasks = append(changes.Tasks, t)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !found[id] {
+			changes.Deleted.Tasks = append(changes.Deleted.Tasks, id)
+		}
+	}
+	return nil
+}
+
+func (r *ChangeRepository) loadProjects(ctx context.Context, scope models.Scope, ids []string, changes *models.Changes) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+projectColumns+` FROM projects WHERE id = ANY($1) AND org_id = $2`,
+		ids, scope.OrgID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := map[string]bool{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return err
+		}
+		found[p.ID] = true
+		if p.DeletedAt != nil {
+			changes.Deleted.Projects = append(changes.Deleted.Projects, p.ID)
+			continue
+		}
+		changes.Projects = append(changes.Projects, *p)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !found[id] {
+			changes.Deleted.Projects = append(changes.Deleted.Projects, id)
+		}
+	}
+	return nil
+}
+
+func (r *ChangeRepository) loadLabels(ctx context.Context, scope models.Scope, ids []string, changes *models.Changes) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+labelColumns+` FROM labels WHERE id = ANY($1) AND org_id = $2`,
+		ids, scope.OrgID,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	found := map[string]bool{}
+	for rows.Next() {
+		l, err := scanLabel(rows)
+		if err != nil {
+			return err
+		}
+		found[l.ID] = true
+		changes.Labels = append(changes.Labels, *l)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, id := range ids {
+		if !found[id] {
+			changes.Deleted.Labels = append(changes.Deleted.Labels, id)
+		}
+	}
+	return nil
+}
+
+func (r *ChangeRepository) Prune(ctx context.Context, cutoff time.Time) (int, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM change_log WHERE changed_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
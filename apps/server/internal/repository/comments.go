@@ -0,0 +1,281 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const commentColumns = `id, task_id, author_id, parent_id, body, mentions, pinned_at, pinned_by, created_at, updated_at`
+
+type CommentRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCommentRepository(pool *pgxpool.Pool) *CommentRepository {
+	return &CommentRepository{pool: pool}
+}
+
+func scanComment(row pgx.Row) (*models.Comment, error) {
+	var c models.Comment
+	err := row.Scan(&c.ID, &c.TaskID, &c.AuthorID, &c.ParentID, &c.Body, &c.Mentions, &c.PinnedAt, &c.PinnedBy, &c.CreatedAt, &c.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.Edited = c.UpdatedAt.After(c.CreatedAt)
+	return &c, nil
+}
+
+// Create comments on the task, which has to be live and in scope.
+func (r *CommentRepository) Create(ctx context.Context, scope models.Scope, taskID string, input models.CommentInput) (*models.Comment, error) {
+	where, arg := liveTaskClause(scope, 6)
+	var comment *models.Comment
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		// A parent that isn't on the task finds no row, so the insert
+		// doesn't happen either.
+		comment, err = scanComment(tx.QueryRow(ctx,
+			`INSERT INTO comments (task_id, author_id, parent_id, body, mentions)
+			 SELECT tasks.id, $2, p.id, $4, $5
+			 FROM tasks LEFT JOIN comments p ON p.id = $3 AND p.task_id = tasks.id
+			 WHERE tasks.id = $1 AND ($3::uuid IS NULL OR p.id IS NOT NULL) AND `+where+`
+			 RETURNING `+commentColumns,
+			taskID, scope.UserID, input.ParentID, input.Body, mentionsOrEmpty(input.Mentions), arg,
+		))
+		if err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, scope, models.EventCommentAdded, taskID, map[string]any{"taskId": taskID, "comment": comment})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (r *CommentRepository) Get(ctx context.Context, scope models.Scope, taskID, id string) (*models.Comment, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	return scanComment(r.pool.QueryRow(ctx,
+		`SELECT `+qualifiedColumns("c", commentColumns)+`
+		 FROM comments c JOIN tasks t ON t.id = c.task_id
+		 WHERE c.id = $1 AND c.task_id = $2 AND `+where,
+		id, taskID, arg,
+	))
+}
+
+// List returns up to page.Limit+1 of the task's comments, oldest first.
+func (r *CommentRepository) List(ctx context.Context, scope models.Scope, taskID string, page models.Page) ([]models.Comment, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+qualifiedColumns("c", commentColumns)+`
+		 FROM comments c JOIN tasks t ON t.id = c.task_id
+		 WHERE c.task_id = $1 AND `+where+`
+		   AND ($3::timestamptz IS NULL OR (c.created_at, c.id) > ($3, $4::uuid))
+		 ORDER BY c.created_at, c.id
+		 LIMIT $5`,
+		taskID, arg, afterTime, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *c)
+	}
+	return comments, rows.Err()
+}
+
+// Update replaces the comment's body, keeping the old one as a revision.
+func (r *CommentRepository) Update(ctx context.Context, scope models.Scope, taskID, id string, input models.CommentInput) (*models.Comment, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	var comment *models.Comment
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		current, err := scanComment(tx.QueryRow(ctx,
+			`SELECT `+qualifiedColumns("c", commentColumns)+`
+			 FROM comments c JOIN tasks t ON t.id = c.task_id
+			 WHERE c.id = $1 AND c.task_id = $2 AND `+where+`
+			 FOR UPDATE OF c`,
+			id, taskID, arg,
+		))
+		if err != nil {
+			return err
+		}
+		if current.Body == input.Body {
+			comment = current
+			return nil
+		}
+
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO comment_revisions (comment_id, body, created_at) VALUES ($1, $2, $3)`,
+			id, current.Body, current.UpdatedAt,
+		); err != nil {
+			return err
+		}
+		comment, err = scanComment(tx.QueryRow(ctx,
+			`UPDATE comments SET body = $2, mentions = $3, updated_at = NOW() WHERE id = $1
+			 RETURNING `+commentColumns,
+			id, input.Body, mentionsOrEmpty(input.Mentions),
+		))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, scope models.Scope, taskID, id string) error {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM comments c USING tasks t
+		 WHERE c.id = $1 AND c.task_id = $2 AND t.id = c.task_id AND `+where,
+		id, taskID, arg,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// History returns the bodies the comment had before each edit, oldest
+// first, each stamped with when it was written.
+func (r *CommentRepository) History(ctx context.Context, scope models.Scope, taskID, id string) ([]models.CommentRevision, error) {
+	if _, err := r.Get(ctx, scope, taskID, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, comment_id, body, created_at FROM comment_revisions
+		 WHERE comment_id = $1
+		 ORDER BY created_at, id`,
+		id,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	revisions := []models.CommentRevision{}
+	for rows.Next() {
+		var rev models.CommentRevision
+		if err := rows.Scan(&rev.ID, &rev.CommentID, &rev.Body, &rev.CreatedAt); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, rev)
+	}
+	return revisions, rows.Err()
+}
+
+// SetPinned pins or unpins the comment; pinning one that's pinned already
+// leaves it as it was.
+func (r *CommentRepository) SetPinned(ctx context.Context, scope models.Scope, taskID, id string, pinned bool) (*models.Comment, error) {
+	where, arg := liveTaskAccess("t", scope, 5, models.ShareRoleViewer)
+	return scanComment(r.pool.QueryRow(ctx,
+		`UPDATE comments c
+		 SET pinned_at = CASE WHEN $3 THEN COALESCE(c.pinned_at, NOW()) END,
+		     pinned_by = CASE WHEN $3 THEN COALESCE(c.pinned_by, $4) END
+		 FROM tasks t
+		 WHERE c.id = $1 AND c.task_id = $2 AND t.id = c.task_id AND `+where+`
+		 RETURNING `+qualifiedColumns("c", commentColumns),
+		id, taskID, pinned, scope.UserID, arg,
+	))
+}
+
+// Pinned returns the task's pinned comments, in the order they were pinned.
+func (r *CommentRepository) Pinned(ctx context.Context, scope models.Scope, taskID string) ([]models.Comment, error) {
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+qualifiedColumns("c", commentColumns)+`
+		 FROM comments c JOIN tasks t ON t.id = c.task_id
+		 WHERE c.task_id = $1 AND c.pinned_at IS NOT NULL AND `+where+`
+		 ORDER BY c.pinned_at, c.id`,
+		taskID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []models.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *c)
+	}
+	return comments, rows.Err()
+}
+
+func (r *CommentRepository) Commented(ctx context.Context, scope models.Scope, taskID, userID string) (bool, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	var commented bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (
+		   SELECT 1 FROM comments c JOIN tasks t ON t.id = c.task_id
+		   WHERE c.task_id = $1 AND c.author_id = $2 AND `+where+`
+		 )`,
+		taskID, userID, arg,
+	).Scan(&commented)
+	return commented, err
+}
+
+// mentionsOrEmpty keeps a nil slice from being written as NULL.
+func mentionsOrEmpty(mentions []string) []string {
+	if mentions == nil {
+		return []string{}
+	}
+	return mentions
+}
+
+func (r *CommentRepository) Counts(ctx context.Context, taskIDs []string) (map[string]int, error) {
+	counts := map[string]int{}
+	if len(taskIDs) == 0 {
+		return counts, nil
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT task_id, count(*) FROM comments WHERE task_id = ANY($1) GROUP BY task_id`,
+		taskIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID string
+		var n int
+		if err := rows.Scan(&taskID, &n); err != nil {
+			return nil, err
+		}
+		counts[taskID] = n
+	}
+	return counts, rows.Err()
+}
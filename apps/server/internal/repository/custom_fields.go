@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const customFieldColumns = `id, org_id, key, name, type, options, created_by, created_at, updated_at`
+
+type CustomFieldRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewCustomFieldRepository(pool *pgxpool.Pool) *CustomFieldRepository {
+	return &CustomFieldRepository{pool: pool}
+}
+
+func scanCustomField(row pgx.Row) (*models.CustomField, error) {
+	var f models.CustomField
+	err := row.Scan(&f.ID, &f.OrgID, &f.Key, &f.Name, &f.Type, &f.Options, &f.CreatedBy, &f.CreatedAt, &f.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
+
+func (r *CustomFieldRepository) Create(ctx context.Context, orgID, userID string, input models.CreateCustomFieldInput) (*models.CustomField, error) {
+	options := input.Options
+	if options == nil {
+		options = []string{}
+	}
+	row := r.pool.QueryRow(ctx,
+		`INSERT INTO custom_fields (org_id, key, name, type, options, created_by)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+customFieldColumns,
+		orgID, input.Key, input.Name, input.Type, options, userID,
+	)
+	return scanCustomField(row)
+}
+
+func (r *CustomFieldRepository) List(ctx context.Context, orgID string) ([]models.CustomField, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+customFieldColumns+` FROM custom_fields WHERE org_id = $1 ORDER BY created_at, id`,
+		orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := []models.CustomField{}
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, *f)
+	}
+	return fields, rows.Err()
+}
+
+func (r *CustomFieldRepository) Update(ctx context.Context, orgID, id string, input models.UpdateCustomFieldInput) (*models.CustomField, error) {
+	sets := []string{}
+	args := []any{id, orgID}
+
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if input.Name != nil {
+		set("name", *input.Name)
+	}
+	if input.Options != nil {
+		set("options", *input.Options)
+	}
+
+	if len(sets) == 0 {
+		return scanCustomField(r.pool.QueryRow(ctx,
+			`SELECT `+customFieldColumns+` FROM custom_fields WHERE id = $1 AND org_id = $2`,
+			args...,
+		))
+	}
+
+	var field *models.CustomField
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		field, err = scanCustomField(tx.QueryRow(ctx,
+			`UPDATE custom_fields SET `+strings.Join(sets, ", ")+`, updated_at = NOW()
+			 WHERE id = $1 AND org_id = $2
+			 RETURNING `+customFieldColumns,
+			args...,
+		))
+		if err != nil || input.Options == nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE tasks SET custom_fields = custom_fields - $2, version = version + 1, updated_at = NOW()
+			 WHERE org_id = $1 AND custom_fields ? $2 AND NOT (custom_fields->>$2 = ANY($3))`,
+			orgID, field.Key, field.Options,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (r *CustomFieldRepository) Delete(ctx context.Context, orgID, id string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var key string
+		err := tx.QueryRow(ctx,
+			`DELETE FROM custom_fields WHERE id = $1 AND org_id = $2 RETURNING key`,
+			id, orgID,
+		).Scan(&key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE tasks SET custom_fields = custom_fields - $2, version = version + 1, updated_at = NOW()
+			 WHERE org_id = $1 AND custom_fields ? $2`,
+			orgID, key,
+		)
+		return err
+	})
+}
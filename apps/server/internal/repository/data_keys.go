@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/crypto"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DataKeyRepository is the crypto.KeyStore of wrapped data keys.
+type DataKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDataKeyRepository(pool *pgxpool.Pool) *DataKeyRepository {
+	return &DataKeyRepository{pool: pool}
+}
+
+func (r *DataKeyRepository) Get(ctx context.Context, ownerID string) ([]byte, error) {
+	var wrapped []byte
+	err := r.pool.QueryRow(ctx, `SELECT wrapped_key FROM data_keys WHERE owner_id = $1`, ownerID).Scan(&wrapped)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, crypto.ErrNoDataKey
+	}
+	return wrapped, err
+}
+
+// Create keeps the key already stored when two processes race to make one.
+func (r *DataKeyRepository) Create(ctx context.Context, ownerID string, wrapped []byte) ([]byte, error) {
+	var stored []byte
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO data_keys (owner_id, wrapped_key) VALUES ($1, $2)
+		 ON CONFLICT (owner_id) DO UPDATE SET owner_id = EXCLUDED.owner_id
+		 RETURNING wrapped_key`,
+		ownerID, wrapped,
+	).Scan(&stored)
+	return stored, err
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Dependencies returns the ids of tasks blocking id and the ids it blocks.
+func (r *TaskRepository) Dependencies(ctx context.Context, scope models.Scope, id string) (models.TaskDependencies, error) {
+	where, arg := liveTaskClause(scope, 2)
+	deps := models.TaskDependencies{BlockedBy: []string{}, Blocks: []string{}}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT blocker_id, blocked_id FROM task_dependencies
+		 WHERE (blocked_id = $1 OR blocker_id = $1)
+		   AND EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND `+where+`)
+		   AND NOT EXISTS (
+			SELECT 1 FROM tasks o WHERE o.id IN (blocker_id, blocked_id) AND o.deleted_at IS NOT NULL
+		   )
+		 ORDER BY created_at`,
+		id, arg,
+	)
+	if err != nil {
+		return deps, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var blocker, blocked string
+		if err := rows.Scan(&blocker, &blocked); err != nil {
+			return deps, err
+		}
+		if blocked == id {
+			deps.BlockedBy = append(deps.BlockedBy, blocker)
+		} else {
+			deps.Blocks = append(deps.Blocks, blocked)
+		}
+	}
+	return deps, rows.Err()
+}
+
+// AddDependency records that blockedByID blocks id. Both tasks must be in
+// scope, and the edge is rejected with ErrDependencyCycle if id already
+// (transitively) blocks blockedByID.
+func (r *TaskRepository) AddDependency(ctx context.Context, scope models.Scope, id, blockedByID string) error {
+	if id == blockedByID {
+		return ErrDependencyCycle
+	}
+
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// Serialise writers so two concurrent inserts can't close a cycle
+		// that neither sees on its own.
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('task_dependencies'))`); err != nil {
+			return err
+		}
+
+		where, arg := editableTaskClause(scope, 2)
+		var visible int
+		err := tx.QueryRow(ctx,
+			`SELECT count(*) FROM tasks WHERE id = ANY($1) AND `+where,
+			[]string{id, blockedByID}, arg,
+		).Scan(&visible)
+		if err != nil {
+			return err
+		}
+		if visible != 2 {
+			return ErrNotFound
+		}
+
+		var cycle bool
+		err = tx.QueryRow(ctx,
+			`WITH RECURSIVE reach AS (
+				SELECT blocked_id AS id FROM task_dependencies WHERE blocker_id = $1
+				UNION
+				SELECT d.blocked_id FROM task_dependencies d JOIN reach r ON d.blocker_id = r.id
+			)
+			SELECT EXISTS (SELECT 1 FROM reach WHERE id = $2)`,
+			id, blockedByID,
+		).Scan(&cycle)
+		if err != nil {
+			return err
+		}
+		if cycle {
+			return ErrDependencyCycle
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO task_dependencies (blocker_id, blocked_id) VALUES ($1, $2)
+			 ON CONFLICT DO NOTHING`,
+			blockedByID, id,
+		)
+		return err
+	})
+}
+
+func (r *TaskRepository) RemoveDependency(ctx context.Context, scope models.Scope, id, blockedByID string) error {
+	where, arg := editableTaskClause(scope, 3)
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM task_dependencies
+		 WHERE blocked_id = $1 AND blocker_id = $2
+		   AND EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND `+where+`)`,
+		id, blockedByID, arg,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
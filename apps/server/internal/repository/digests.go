@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type DigestRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewDigestRepository(pool *pgxpool.Pool) *DigestRepository {
+	return &DigestRepository{pool: pool}
+}
+
+func (r *DigestRepository) Subscribers(ctx context.Context) ([]models.DigestSubscriber, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT user_id, `+userSettingsColumns+`, digest_sent_at FROM user_settings
+		 WHERE digest_frequency <> 'off' AND email_enabled
+		 ORDER BY user_id`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscribers := []models.DigestSubscriber{}
+	for rows.Next() {
+		var sub models.DigestSubscriber
+		fields := append([]any{&sub.Settings.UserID}, settingsFields(&sub.Settings)...)
+		if err := rows.Scan(append(fields, &sub.LastSentAt)...); err != nil {
+			return nil, err
+		}
+		subscribers = append(subscribers, sub)
+	}
+	return subscribers, rows.Err()
+}
+
+func (r *DigestRepository) Claim(ctx context.Context, userID string, slot, sentAt time.Time) (bool, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE user_settings SET digest_sent_at = $3
+		 WHERE user_id = $1 AND (digest_sent_at IS NULL OR digest_sent_at < $2)`,
+		userID, slot, sentAt,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// openOwnedTasks is the user's ($1) open tasks in every scope; $2 is the
+// default workflow's done status.
+const openOwnedTasks = `FROM tasks t
+	WHERE t.owner_id = $1 AND t.deleted_at IS NULL AND t.archived_at IS NULL
+	  AND t.status NOT IN (` + doneStatusKeys + `)`
+
+// notSnoozed keeps snoozed tasks out of the due sections.
+const notSnoozed = `(t.snoozed_until IS NULL OR t.snoozed_until <= NOW())`
+
+// assignedSince ($3) matches tasks someone other than the user created for
+// them or handed over.
+const assignedSince = `EXISTS (
+	SELECT 1 FROM activity a
+	WHERE a.task_id = t.id AND a.actor_id <> $1 AND a.created_at >= $3
+	  AND (a.action = '` + models.ActivityTaskCreated + `' OR a.changes->'ownerId'->>'new' = $1)
+)`
+
+func (r *DigestRepository) Digest(ctx context.Context, userID string, q models.DigestQuery) (models.Digest, error) {
+	var d models.Digest
+	var err error
+	d.Overdue, err = r.tasks(ctx,
+		`SELECT `+qualifiedColumns("t", taskColumns)+` `+openOwnedTasks+`
+		 AND t.due_date < $3 AND `+notSnoozed+`
+		 ORDER BY t.due_date, t.id LIMIT $4`,
+		userID, models.TaskStatusDone, q.DueFrom, q.Limit,
+	)
+	if err != nil {
+		return d, err
+	}
+	d.Due, err = r.tasks(ctx,
+		`SELECT `+qualifiedColumns("t", taskColumns)+` `+openOwnedTasks+`
+		 AND t.due_date >= $3 AND t.due_date < $4 AND `+notSnoozed+`
+		 ORDER BY t.due_date, t.id LIMIT $5`,
+		userID, models.TaskStatusDone, q.DueFrom, q.DueTo, q.Limit,
+	)
+	if err != nil {
+		return d, err
+	}
+	d.Assigned, err = r.tasks(ctx,
+		`SELECT `+qualifiedColumns("t", taskColumns)+` `+openOwnedTasks+`
+		 AND (t.due_date IS NULL OR t.due_date >= $4) AND `+assignedSince+`
+		 ORDER BY t.created_at DESC, t.id LIMIT $5`,
+		userID, models.TaskStatusDone, q.AssignedSince, q.DueTo, q.Limit,
+	)
+	return d, err
+}
+
+func (r *DigestRepository) tasks(ctx context.Context, query string, args ...any) ([]models.Task, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
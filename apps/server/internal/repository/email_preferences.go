@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type EmailPreferenceRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewEmailPreferenceRepository(pool *pgxpool.Pool) *EmailPreferenceRepository {
+	return &EmailPreferenceRepository{pool: pool}
+}
+
+// Get returns the defaults for users who never changed anything.
+func (r *EmailPreferenceRepository) Get(ctx context.Context, userID string) (models.EmailPreferences, error) {
+	p := models.DefaultEmailPreferences(userID)
+	err := r.pool.QueryRow(ctx,
+		`SELECT task_assigned, due_soon, comment_mention, updated_at FROM email_preferences WHERE user_id = $1`,
+		userID,
+	).Scan(&p.TaskAssigned, &p.DueSoon, &p.CommentMention, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return p, nil
+	}
+	return p, err
+}
+
+func (r *EmailPreferenceRepository) Update(ctx context.Context, userID string, input models.UpdateEmailPreferencesInput) (models.EmailPreferences, error) {
+	p := models.EmailPreferences{UserID: userID}
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO email_preferences (user_id, task_assigned, due_soon, comment_mention)
+		 VALUES ($1, COALESCE($2, TRUE), COALESCE($3, TRUE), COALESCE($4, TRUE))
+		 ON CONFLICT (user_id) DO UPDATE SET
+			task_assigned = COALESCE($2, email_preferences.task_assigned),
+			due_soon = COALESCE($3, email_preferences.due_soon),
+			comment_mention = COALESCE($4, email_preferences.comment_mention),
+			updated_at = NOW()
+		 RETURNING task_assigned, due_soon, comment_mention, updated_at`,
+		userID, input.TaskAssigned, input.DueSoon, input.CommentMention,
+	).Scan(&p.TaskAssigned, &p.DueSoon, &p.CommentMention, &p.UpdatedAt)
+	return p, err
+}
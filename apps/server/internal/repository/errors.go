@@ -0,0 +1,11 @@
+package repository
+
+import "errors"
+
+var (
+	ErrNotFound        = errors.New("not found")
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+	ErrConflict        = errors.New("already exists")
+	ErrVersionMismatch = errors.New("version does not match")
+	ErrReadOnly        = errors.New("read only")
+)
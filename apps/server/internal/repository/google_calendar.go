@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const gcalConnectionColumns = `id, user_id, org_id, email, calendar_id, last_synced_at, last_error, created_at,
+	access_token, refresh_token, token_expires_at`
+
+type GoogleCalendarRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGoogleCalendarRepository(pool *pgxpool.Pool) *GoogleCalendarRepository {
+	return &GoogleCalendarRepository{pool: pool}
+}
+
+func scanGoogleCalendarConnection(row pgx.Row) (*models.GoogleCalendarConnection, error) {
+	var c models.GoogleCalendarConnection
+	err := row.Scan(
+		&c.ID, &c.UserID, &c.OrgID, &c.Email, &c.CalendarID, &c.LastSyncedAt, &c.LastError, &c.CreatedAt,
+		&c.Token.AccessToken, &c.Token.RefreshToken, &c.Token.ExpiresAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r *GoogleCalendarRepository) Get(ctx context.Context, scope models.Scope) (*models.GoogleCalendarConnection, error) {
+	return scanGoogleCalendarConnection(r.pool.QueryRow(ctx,
+		`SELECT `+gcalConnectionColumns+` FROM google_calendar_connections
+		 WHERE user_id = $1 AND COALESCE(org_id, '') = $2`,
+		scope.UserID, scope.OrgID,
+	))
+}
+
+// Connect replaces any earlier connection outright, so its events go with it
+// through the cascade.
+func (r *GoogleCalendarRepository) Connect(ctx context.Context, scope models.Scope, email string, token models.GoogleCalendarToken) (*models.GoogleCalendarConnection, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx,
+		`DELETE FROM google_calendar_connections WHERE user_id = $1 AND COALESCE(org_id, '') = $2`,
+		scope.UserID, scope.OrgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	c, err := scanGoogleCalendarConnection(tx.QueryRow(ctx,
+		`INSERT INTO google_calendar_connections (user_id, org_id, email, access_token, refresh_token, token_expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+gcalConnectionColumns,
+		scope.UserID, scope.OrgIDPtr(), email, token.AccessToken, token.RefreshToken, token.ExpiresAt,
+	))
+	if err != nil {
+		return nil, err
+	}
+	return c, tx.Commit(ctx)
+}
+
+func (r *GoogleCalendarRepository) Delete(ctx context.Context, scope models.Scope) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM google_calendar_connections WHERE user_id = $1 AND COALESCE(org_id, '') = $2`,
+		scope.UserID, scope.OrgID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimDue pushes next_sync_at forward on up to limit due connections and
+// returns them. SKIP LOCKED keeps two workers from syncing one at once.
+func (r *GoogleCalendarRepository) ClaimDue(ctx context.Context, interval time.Duration, limit int) ([]models.GoogleCalendarConnection, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH due AS (
+			SELECT id FROM google_calendar_connections
+			WHERE next_sync_at <= NOW()
+			ORDER BY next_sync_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE google_calendar_connections SET next_sync_at = NOW() + make_interval(secs => $1)
+		WHERE id IN (SELECT id FROM due)
+		RETURNING `+gcalConnectionColumns,
+		interval.Seconds(), limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []models.GoogleCalendarConnection{}
+	for rows.Next() {
+		c, err := scanGoogleCalendarConnection(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, *c)
+	}
+	return due, rows.Err()
+}
+
+func (r *GoogleCalendarRepository) SaveToken(ctx context.Context, id string, token models.GoogleCalendarToken) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE google_calendar_connections
+		 SET access_token = $2, refresh_token = COALESCE(NULLIF($3, ''), refresh_token), token_expires_at = $4
+		 WHERE id = $1`,
+		id, token.AccessToken, token.RefreshToken, token.ExpiresAt,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GoogleCalendarRepository) Synced(ctx context.Context, id string, at time.Time, syncErr *string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE google_calendar_connections
+		 SET last_synced_at = CASE WHEN $3::text IS NULL THEN $2 ELSE last_synced_at END, last_error = $3
+		 WHERE id = $1`,
+		id, at, syncErr,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *GoogleCalendarRepository) Events(ctx context.Context, connectionID string) ([]models.GoogleCalendarEvent, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT connection_id, task_id, event_id, due, task_updated_at
+		 FROM google_calendar_events WHERE connection_id = $1`,
+		connectionID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []models.GoogleCalendarEvent{}
+	for rows.Next() {
+		var e models.GoogleCalendarEvent
+		if err := rows.Scan(&e.ConnectionID, &e.TaskID, &e.EventID, &e.Due, &e.TaskUpdatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+func (r *GoogleCalendarRepository) SaveEvent(ctx context.Context, e models.GoogleCalendarEvent) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO google_calendar_events (connection_id, task_id, event_id, due, task_updated_at)
+		 VALUES ($1, $2, $3, $4, $5)
+		 ON CONFLICT (connection_id, task_id) DO UPDATE
+		 SET event_id = EXCLUDED.event_id, due = EXCLUDED.due, task_updated_at = EXCLUDED.task_updated_at`,
+		e.ConnectionID, e.TaskID, e.EventID, e.Due, e.TaskUpdatedAt,
+	)
+	return err
+}
+
+func (r *GoogleCalendarRepository) DeleteEvent(ctx context.Context, connectionID, taskID string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM google_calendar_events WHERE connection_id = $1 AND task_id = $2`,
+		connectionID, taskID,
+	)
+	return err
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool}
+}
+
+// Reserve claims key for a new request and reports true, or returns the
+// request already stored under it. Keys older than ttl are reclaimed.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, scope models.Scope, key, fingerprint string, ttl time.Duration) (models.IdempotentRequest, bool, error) {
+	var reserved bool
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO idempotency_keys (user_id, org_id, key, fingerprint) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id, org_id, key) DO UPDATE
+		 SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, content_type = NULL, body = NULL, created_at = NOW()
+		 WHERE idempotency_keys.created_at < NOW() - make_interval(secs => $5)
+		 RETURNING TRUE`,
+		scope.UserID, scope.OrgID, key, fingerprint, ttl.Seconds(),
+	).Scan(&reserved)
+	if err == nil {
+		return models.IdempotentRequest{}, true, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return models.IdempotentRequest{}, false, err
+	}
+
+	var req models.IdempotentRequest
+	var status *int
+	var contentType *string
+	err = r.pool.QueryRow(ctx,
+		`SELECT fingerprint, status_code, content_type, body FROM idempotency_keys WHERE user_id = $1 AND org_id = $2 AND key = $3`,
+		scope.UserID, scope.OrgID, key,
+	).Scan(&req.Fingerprint, &status, &contentType, &req.Body)
+	if err != nil {
+		return req, false, err
+	}
+	if status != nil {
+		req.StatusCode = *status
+	}
+	if contentType != nil {
+		req.ContentType = *contentType
+	}
+	return req, false, nil
+}
+
+func (r *IdempotencyRepository) Complete(ctx context.Context, scope models.Scope, key string, statusCode int, contentType string, body []byte) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE idempotency_keys SET status_code = $4, content_type = $5, body = $6 WHERE user_id = $1 AND org_id = $2 AND key = $3`,
+		scope.UserID, scope.OrgID, key, statusCode, contentType, body,
+	)
+	return err
+}
+
+// Release forgets key so the request can be retried.
+func (r *IdempotencyRepository) Release(ctx context.Context, scope models.Scope, key string) error {
+	_, err := r.pool.Exec(ctx, `DELETE FROM idempotency_keys WHERE user_id = $1 AND org_id = $2 AND key = $3`, scope.UserID, scope.OrgID, key)
+	return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const importColumns = `id, user_id, org_id, source, file_name, timezone, status, total, imported, skipped, error, created_at, updated_at, finished_at`
+
+type ImportRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewImportRepository(pool *pgxpool.Pool) *ImportRepository {
+	return &ImportRepository{pool: pool}
+}
+
+func importFields(i *models.Import) []any {
+	return []any{&i.ID, &i.UserID, &i.OrgID, &i.Source, &i.FileName, &i.Timezone, &i.Status, &i.Total, &i.Imported, &i.Skipped, &i.Error, &i.CreatedAt, &i.UpdatedAt, &i.FinishedAt}
+}
+
+func scanImport(row pgx.Row) (*models.Import, error) {
+	var i models.Import
+	err := row.Scan(importFields(&i)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *ImportRepository) Create(ctx context.Context, scope models.Scope, input models.CreateImportInput) (*models.Import, error) {
+	return scanImport(r.pool.QueryRow(ctx,
+		`INSERT INTO imports (user_id, org_id, source, file_name, timezone, total, data)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+importColumns,
+		scope.UserID, scope.OrgIDPtr(), input.Source, input.FileName, input.Timezone, input.Total, input.Data,
+	))
+}
+
+func (r *ImportRepository) Get(ctx context.Context, userID, id string) (*models.Import, error) {
+	return scanImport(r.pool.QueryRow(ctx,
+		`SELECT `+importColumns+` FROM imports WHERE id = $1 AND user_id = $2`,
+		id, userID,
+	))
+}
+
+func (r *ImportRepository) Start(ctx context.Context, id string) (*models.Import, []byte, error) {
+	var i models.Import
+	var data []byte
+	err := r.pool.QueryRow(ctx,
+		`UPDATE imports SET status = 'running', updated_at = NOW()
+		 WHERE id = $1 AND status = 'pending'
+		 RETURNING `+importColumns+`, data`,
+		id,
+	).Scan(append(importFields(&i), &data)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return &i, data, nil
+}
+
+// Progress drops the uploaded file once the import has finished.
+func (r *ImportRepository) Progress(ctx context.Context, id string, progress models.ImportProgress) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE imports SET status = $2, imported = $3, skipped = $4, error = $5, updated_at = NOW(),
+		     finished_at = CASE WHEN $2 IN ('done', 'failed') THEN NOW() END,
+		     data = CASE WHEN $2 IN ('done', 'failed') THEN NULL ELSE data END
+		 WHERE id = $1`,
+		id, progress.Status, progress.Imported, progress.Skipped, progress.Error,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
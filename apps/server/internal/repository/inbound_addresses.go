@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const inboundAddressColumns = `id, user_id, org_id, project_id, token, created_at`
+
+type InboundAddressRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInboundAddressRepository(pool *pgxpool.Pool) *InboundAddressRepository {
+	return &InboundAddressRepository{pool: pool}
+}
+
+func scanInboundAddress(row pgx.Row) (*models.InboundAddress, error) {
+	var a models.InboundAddress
+	err := row.Scan(&a.ID, &a.UserID, &a.OrgID, &a.ProjectID, &a.Token, &a.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+func (r *InboundAddressRepository) List(ctx context.Context, scope models.Scope) ([]models.InboundAddress, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+inboundAddressColumns+` FROM inbound_addresses
+		 WHERE user_id = $1 AND COALESCE(org_id, '') = $2
+		 ORDER BY project_id NULLS FIRST, created_at`,
+		scope.UserID, scope.OrgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	addresses := []models.InboundAddress{}
+	for rows.Next() {
+		a, err := scanInboundAddress(rows)
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, *a)
+	}
+	return addresses, rows.Err()
+}
+
+func (r *InboundAddressRepository) Rotate(ctx context.Context, scope models.Scope, projectID *string, token string) (*models.InboundAddress, error) {
+	return scanInboundAddress(r.pool.QueryRow(ctx,
+		`INSERT INTO inbound_addresses (user_id, org_id, project_id, token) VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (user_id, COALESCE(org_id, ''), COALESCE(project_id::text, '')) DO UPDATE
+		 SET token = EXCLUDED.token, created_at = NOW()
+		 RETURNING `+inboundAddressColumns,
+		scope.UserID, scope.OrgIDPtr(), projectID, token,
+	))
+}
+
+func (r *InboundAddressRepository) Delete(ctx context.Context, scope models.Scope, id string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM inbound_addresses WHERE id = $1 AND user_id = $2 AND COALESCE(org_id, '') = $3`,
+		id, scope.UserID, scope.OrgID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *InboundAddressRepository) Lookup(ctx context.Context, token string) (*models.InboundAddress, error) {
+	return scanInboundAddress(r.pool.QueryRow(ctx,
+		`SELECT `+inboundAddressColumns+` FROM inbound_addresses WHERE token = $1`,
+		token,
+	))
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const invitationColumns = `id, org_id, project_id, email, role, clerk_invitation_id, invited_by, expires_at, accepted_by, accepted_at, revoked_at, created_at`
+
+// pendingInvitation is true for invitations that can still be accepted.
+const pendingInvitation = `accepted_at IS NULL AND revoked_at IS NULL AND expires_at > NOW()`
+
+type InvitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewInvitationRepository(pool *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{pool: pool}
+}
+
+func scanInvitation(row pgx.Row) (*models.Invitation, error) {
+	var i models.Invitation
+	err := row.Scan(&i.ID, &i.OrgID, &i.ProjectID, &i.Email, &i.Role, &i.ClerkInvitationID, &i.InvitedBy, &i.ExpiresAt, &i.AcceptedBy, &i.AcceptedAt, &i.RevokedAt, &i.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+func (r *InvitationRepository) Create(ctx context.Context, orgID, invitedBy string, input models.CreateInvitationInput) (*models.Invitation, error) {
+	return scanInvitation(r.pool.QueryRow(ctx,
+		`INSERT INTO project_invitations (org_id, project_id, email, role, token_hash, clerk_invitation_id, invited_by, expires_at)
+		 SELECT org_id, id, $3, $4, $5, $6, $7, $8 FROM projects WHERE id = $2 AND org_id = $1 AND deleted_at IS NULL
+		 RETURNING `+invitationColumns,
+		orgID, input.ProjectID, input.Email, input.Role, input.TokenHash, input.ClerkInvitationID, invitedBy, input.ExpiresAt,
+	))
+}
+
+func (r *InvitationRepository) GetByToken(ctx context.Context, orgID string, tokenHash []byte) (*models.Invitation, error) {
+	return scanInvitation(r.pool.QueryRow(ctx,
+		`SELECT `+invitationColumns+` FROM project_invitations WHERE token_hash = $1 AND org_id = $2`,
+		tokenHash, orgID,
+	))
+}
+
+func (r *InvitationRepository) ListForProject(ctx context.Context, orgID, projectID string) ([]models.Invitation, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+invitationColumns+` FROM project_invitations
+		 WHERE project_id = $1 AND org_id = $2
+		 ORDER BY created_at DESC, id DESC`,
+		projectID, orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invitations := []models.Invitation{}
+	for rows.Next() {
+		i, err := scanInvitation(rows)
+		if err != nil {
+			return nil, err
+		}
+		invitations = append(invitations, *i)
+	}
+	return invitations, rows.Err()
+}
+
+func (r *InvitationRepository) Revoke(ctx context.Context, orgID, projectID, id string) (*models.Invitation, error) {
+	return scanInvitation(r.pool.QueryRow(ctx,
+		`UPDATE project_invitations SET revoked_at = NOW()
+		 WHERE id = $1 AND project_id = $2 AND org_id = $3 AND `+pendingInvitation+`
+		 RETURNING `+invitationColumns,
+		id, projectID, orgID,
+	))
+}
+
+// Accept marks the invitation accepted and shares its project in one
+// transaction, so a guest never ends up half let in. A share the user
+// already had keeps the higher of the two roles.
+func (r *InvitationRepository) Accept(ctx context.Context, scope models.Scope, id string) (*models.Invitation, error) {
+	var invitation *models.Invitation
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		invitation, err = scanInvitation(tx.QueryRow(ctx,
+			`UPDATE project_invitations SET accepted_by = $3, accepted_at = NOW()
+			 WHERE id = $1 AND org_id = $2 AND `+pendingInvitation+`
+			 RETURNING `+invitationColumns,
+			id, scope.OrgID, scope.UserID,
+		))
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx,
+			`INSERT INTO project_shares (project_id, user_id, role, created_by)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (project_id, user_id) DO UPDATE
+			 SET role = CASE WHEN project_shares.role = 'editor' THEN 'editor' ELSE EXCLUDED.role END`,
+			invitation.ProjectID, scope.UserID, invitation.Role, invitation.InvitedBy,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return invitation, nil
+}
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const labelColumns = `id, org_id, name, color, created_by, created_at, updated_at`
+
+type LabelRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewLabelRepository(pool *pgxpool.Pool) *LabelRepository {
+	return &LabelRepository{pool: pool}
+}
+
+func labelFields(l *models.Label) []any {
+	return []any{&l.ID, &l.OrgID, &l.Name, &l.Color, &l.CreatedBy, &l.CreatedAt, &l.UpdatedAt}
+}
+
+func scanLabel(row pgx.Row) (*models.Label, error) {
+	var l models.Label
+	err := row.Scan(labelFields(&l)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *LabelRepository) Create(ctx context.Context, orgID, userID string, input models.CreateLabelInput) (*models.Label, error) {
+	row := r.pool.QueryRow(ctx,
+		`INSERT INTO labels (org_id, name, color, created_by)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+labelColumns,
+		orgID, input.Name, input.Color, userID,
+	)
+	return scanLabel(row)
+}
+
+func (r *LabelRepository) List(ctx context.Context, orgID string) ([]models.Label, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+labelColumns+` FROM labels WHERE org_id = $1 ORDER BY lower(name), id`,
+		orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	labels := []models.Label{}
+	for rows.Next() {
+		l, err := scanLabel(rows)
+		if err != nil {
+			return nil, err
+		}
+		labels = append(labels, *l)
+	}
+	return labels, rows.Err()
+}
+
+func (r *LabelRepository) Update(ctx context.Context, orgID, id string, input models.UpdateLabelInput) (*models.Label, error) {
+	sets := []string{}
+	args := []any{id, orgID}
+
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if input.Name != nil {
+		set("name", *input.Name)
+	}
+	if input.Color != nil {
+		set("color", *input.Color)
+	}
+
+	if len(sets) == 0 {
+		return scanLabel(r.pool.QueryRow(ctx,
+			`SELECT `+labelColumns+` FROM labels WHERE id = $1 AND org_id = $2`,
+			args...,
+		))
+	}
+
+	row := r.pool.QueryRow(ctx,
+		`UPDATE labels SET `+strings.Join(sets, ", ")+`, updated_at = NOW()
+		 WHERE id = $1 AND org_id = $2
+		 RETURNING `+labelColumns,
+		args...,
+	)
+	return scanLabel(row)
+}
+
+func (r *LabelRepository) Delete(ctx context.Context, orgID, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM labels WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Attach is idempotent; it returns ErrNotFound unless both the task and the
+// label belong to the org.
+func (r *LabelRepository) Attach(ctx context.Context, orgID, taskID, labelID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`INSERT INTO task_labels (task_id, label_id)
+		 SELECT t.id, l.id FROM tasks t, labels l
+		 WHERE t.id = $1 AND t.org_id = $3 AND t.deleted_at IS NULL AND l.id = $2 AND l.org_id = $3
+		 ON CONFLICT (task_id, label_id) DO UPDATE SET created_at = task_labels.created_at`,
+		taskID, labelID, orgID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *LabelRepository) Detach(ctx context.Context, orgID, taskID, labelID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM task_labels tl USING labels l
+		 WHERE tl.task_id = $1 AND tl.label_id = $2 AND l.id = tl.label_id AND l.org_id = $3`,
+		taskID, labelID, orgID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *LabelRepository) ForTasks(ctx context.Context, orgID string, taskIDs []string) (map[string][]models.Label, error) {
+	byTask := map[string][]models.Label{}
+	if len(taskIDs) == 0 {
+		return byTask, nil
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT tl.task_id, l.id, l.org_id, l.name, l.color, l.created_by, l.created_at, l.updated_at
+		 FROM task_labels tl JOIN labels l ON l.id = tl.label_id
+		 WHERE tl.task_id = ANY($1) AND l.org_id = $2
+		 ORDER BY lower(l.name), l.id`,
+		taskIDs, orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var taskID string
+		var l models.Label
+		if err := rows.Scan(append([]any{&taskID}, labelFields(&l)...)...); err != nil {
+			return nil, err
+		}
+		byTask[taskID] = append(byTask[taskID], l)
+	}
+	return byTask, rows.Err()
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const notificationColumns = `id, user_id, org_id, kind, task_id, actor_id, title, body, read_at, created_at`
+
+type NotificationRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewNotificationRepository(pool *pgxpool.Pool) *NotificationRepository {
+	return &NotificationRepository{pool: pool}
+}
+
+func notificationFields(n *models.Notification) []any {
+	return []any{&n.ID, &n.UserID, &n.OrgID, &n.Kind, &n.TaskID, &n.ActorID, &n.Title, &n.Body, &n.ReadAt, &n.CreatedAt}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, input models.CreateNotificationInput) (*models.Notification, error) {
+	var n models.Notification
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO notifications (user_id, org_id, kind, task_id, actor_id, title, body)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 RETURNING `+notificationColumns,
+		input.UserID, input.OrgID, input.Kind, input.TaskID, input.ActorID, input.Title, input.Body,
+	).Scan(notificationFields(&n)...)
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// List returns up to page.Limit+1 of the user's notifications, newest first.
+func (r *NotificationRepository) List(ctx context.Context, userID string, unreadOnly bool, page models.Page) ([]models.Notification, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+notificationColumns+` FROM notifications
+		 WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		   AND ($3::timestamptz IS NULL OR (created_at, id) < ($3, $4::uuid))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $5`,
+		userID, unreadOnly, afterTime, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(notificationFields(&n)...); err != nil {
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+	return notifications, rows.Err()
+}
+
+func (r *NotificationRepository) SetRead(ctx context.Context, userID, id string, read bool) (*models.Notification, error) {
+	var n models.Notification
+	err := r.pool.QueryRow(ctx,
+		`UPDATE notifications
+		 SET read_at = CASE WHEN $3 THEN COALESCE(read_at, NOW()) END
+		 WHERE id = $1 AND user_id = $2
+		 RETURNING `+notificationColumns,
+		id, userID, read,
+	).Scan(notificationFields(&n)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+// MarkAllRead returns how many notifications were unread.
+func (r *NotificationRepository) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`,
+		userID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *NotificationRepository) UnreadCount(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := r.pool.QueryRow(ctx,
+		`SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`,
+		userID,
+	).Scan(&count)
+	return count, err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OrgAdminRepository reads member counts from reads, which may be a
+// replica, and moves tasks on pool.
+type OrgAdminRepository struct {
+	pool  *pgxpool.Pool
+	reads Reader
+}
+
+func NewOrgAdminRepository(pool *pgxpool.Pool, reads Reader) *OrgAdminRepository {
+	return &OrgAdminRepository{pool: pool, reads: reads}
+}
+
+func (r *OrgAdminRepository) TaskCounts(ctx context.Context, orgID string, userIDs []string) (map[string]models.MemberTaskCounts, error) {
+	rows, err := r.reads.Query(ctx,
+		`SELECT owner_id,
+		        COUNT(*) FILTER (WHERE completed_at IS NULL AND archived_at IS NULL),
+		        COUNT(*) FILTER (WHERE completed_at IS NULL AND archived_at IS NULL AND due_date < NOW()),
+		        COUNT(*) FILTER (WHERE completed_at IS NOT NULL)
+		 FROM tasks
+		 WHERE org_id = $1 AND owner_id = ANY($2) AND deleted_at IS NULL
+		 GROUP BY owner_id`,
+		orgID, userIDs,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := map[string]models.MemberTaskCounts{}
+	for rows.Next() {
+		var userID string
+		var c models.MemberTaskCounts
+		if err := rows.Scan(&userID, &c.Open, &c.Overdue, &c.Completed); err != nil {
+			return nil, err
+		}
+		counts[userID] = c
+	}
+	return counts, rows.Err()
+}
+
+func (r *OrgAdminRepository) TransferTasks(ctx context.Context, orgID, fromUserID, toUserID string) ([]models.ReassignedTask, error) {
+	return r.reassign(ctx,
+		`UPDATE tasks SET owner_id = $3, version = version + 1, updated_at = NOW()
+		 WHERE org_id = $1 AND owner_id = $2 AND deleted_at IS NULL
+		 RETURNING id, project_id, $2::text`,
+		orgID, fromUserID, toUserID,
+	)
+}
+
+func (r *OrgAdminRepository) ReassignTasks(ctx context.Context, orgID string, ids []string, toUserID string) ([]models.ReassignedTask, error) {
+	// The old owner is read in the same statement, before the update.
+	return r.reassign(ctx,
+		`UPDATE tasks t SET owner_id = $3, version = t.version + 1, updated_at = NOW()
+		 FROM tasks old
+		 WHERE old.id = t.id AND t.org_id = $1 AND t.id = ANY($2::uuid[]) AND t.owner_id <> $3 AND t.deleted_at IS NULL
+		 RETURNING t.id, t.project_id, old.owner_id`,
+		orgID, ids, toUserID,
+	)
+}
+
+// reassign runs the update sql, whose $1 is the org and $3 the new owner,
+// once it has made sure the new owner is a member. The membership is held
+// FOR SHARE until the update commits, so it can't be dropped in between.
+func (r *OrgAdminRepository) reassign(ctx context.Context, sql string, args ...any) ([]models.ReassignedTask, error) {
+	var moved []models.ReassignedTask
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var member bool
+		err := tx.QueryRow(ctx,
+			`SELECT true FROM org_memberships WHERE org_id = $1 AND user_id = $2 FOR SHARE`,
+			args[0], args[2],
+		).Scan(&member)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, sql, args...)
+		if err != nil {
+			return err
+		}
+		moved, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.ReassignedTask, error) {
+			var t models.ReassignedTask
+			err := row.Scan(&t.ID, &t.ProjectID, &t.PreviousOwnerID)
+			return t, err
+		})
+		return err
+	})
+	return moved, err
+}
@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type OrgSettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewOrgSettingsRepository(pool *pgxpool.Pool) *OrgSettingsRepository {
+	return &OrgSettingsRepository{pool: pool}
+}
+
+// getOrgSettings returns the defaults for orgs that never changed anything.
+func getOrgSettings(ctx context.Context, q querier, orgID string, lock bool) (models.OrgSettings, error) {
+	query := `SELECT working_days, default_task_visibility, allowed_label_colors, trash_retention_days, rate_limit, updated_at
+		FROM org_settings WHERE org_id = $1`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	s := models.DefaultOrgSettings(orgID)
+	var days []int16
+	err := q.QueryRow(ctx, query, orgID).Scan(&days, &s.DefaultTaskVisibility, &s.AllowedLabelColors, &s.TrashRetentionDays, &s.RateLimit, &s.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	s.WorkingDays = make([]int, len(days))
+	for i, d := range days {
+		s.WorkingDays[i] = int(d)
+	}
+	return s, nil
+}
+
+func (r *OrgSettingsRepository) Get(ctx context.Context, orgID string) (models.OrgSettings, error) {
+	return getOrgSettings(ctx, r.pool, orgID, false)
+}
+
+func (r *OrgSettingsRepository) Update(ctx context.Context, orgID string, input models.UpdateOrgSettingsInput) (models.OrgSettings, error) {
+	var s models.OrgSettings
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		current, err := getOrgSettings(ctx, tx, orgID, true)
+		if err != nil {
+			return err
+		}
+		s = input.Apply(current)
+
+		return tx.QueryRow(ctx,
+			`INSERT INTO org_settings (org_id, working_days, default_task_visibility, allowed_label_colors, trash_retention_days, rate_limit)
+			 VALUES ($1, $2, $3, $4, $5, $6)
+			 ON CONFLICT (org_id) DO UPDATE SET
+				working_days = EXCLUDED.working_days,
+				default_task_visibility = EXCLUDED.default_task_visibility,
+				allowed_label_colors = EXCLUDED.allowed_label_colors,
+				trash_retention_days = EXCLUDED.trash_retention_days,
+				rate_limit = EXCLUDED.rate_limit,
+				updated_at = NOW()
+			 RETURNING updated_at`,
+			orgID, s.WorkingDays, s.DefaultTaskVisibility, s.AllowedLabelColors, s.TrashRetentionDays, s.RateLimit,
+		).Scan(&s.UpdatedAt)
+	})
+	return s, err
+}
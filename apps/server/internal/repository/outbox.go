@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// recordEvent adds an event to the outbox in tx, the transaction making the
+// change it describes, so the event is relayed if and only if the change
+// commits.
+func recordEvent(ctx context.Context, tx pgx.Tx, scope models.Scope, event, taskID string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO outbox (event, org_id, actor_id, task_id, payload) VALUES ($1, $2, $3, $4, $5)`,
+		event, scope.OrgIDPtr(), scope.UserID, taskID, payload,
+	)
+	return err
+}
+
+func recordTaskEvent(ctx context.Context, tx pgx.Tx, scope models.Scope, event string, t *models.Task) error {
+	return recordEvent(ctx, tx, scope, event, t.ID, map[string]any{"task": t})
+}
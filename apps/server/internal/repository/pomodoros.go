@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const pomodoroColumns = `id, user_id, org_id, task_id, status, planned_seconds, started_at, ended_at`
+
+type PomodoroRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPomodoroRepository(pool *pgxpool.Pool) *PomodoroRepository {
+	return &PomodoroRepository{pool: pool}
+}
+
+func scanPomodoro(row pgx.Row) (*models.Pomodoro, error) {
+	var p models.Pomodoro
+	err := row.Scan(&p.ID, &p.UserID, &p.OrgID, &p.TaskID, &p.Status, &p.PlannedSeconds, &p.StartedAt, &p.EndedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// Start begins a session, on a task that has to be live and in scope when
+// one is given. It returns ErrConflict while the user has one running.
+func (r *PomodoroRepository) Start(ctx context.Context, scope models.Scope, taskID *string, plannedSeconds int) (*models.Pomodoro, error) {
+	var p *models.Pomodoro
+	var err error
+	if taskID == nil {
+		p, err = scanPomodoro(r.pool.QueryRow(ctx,
+			`INSERT INTO pomodoros (user_id, org_id, planned_seconds) VALUES ($1, $2, $3)
+			 RETURNING `+pomodoroColumns,
+			scope.UserID, scope.OrgIDPtr(), plannedSeconds,
+		))
+	} else {
+		where, arg := liveTaskClause(scope, 5)
+		p, err = scanPomodoro(r.pool.QueryRow(ctx,
+			`INSERT INTO pomodoros (user_id, org_id, planned_seconds, task_id)
+			 SELECT $1, $2, $3, id FROM tasks WHERE id = $4 AND `+where+`
+			 RETURNING `+pomodoroColumns,
+			scope.UserID, scope.OrgIDPtr(), plannedSeconds, *taskID, arg,
+		))
+	}
+	if isUniqueViolation(err) {
+		return nil, ErrConflict
+	}
+	return p, err
+}
+
+func (r *PomodoroRepository) Get(ctx context.Context, scope models.Scope, id string) (*models.Pomodoro, error) {
+	return scanPomodoro(r.pool.QueryRow(ctx,
+		`SELECT `+pomodoroColumns+` FROM pomodoros
+		 WHERE id = $1 AND user_id = $2 AND COALESCE(org_id, '') = $3`,
+		id, scope.UserID, scope.OrgID,
+	))
+}
+
+// Running returns the user's running session in any scope.
+func (r *PomodoroRepository) Running(ctx context.Context, userID string) (*models.Pomodoro, error) {
+	return scanPomodoro(r.pool.QueryRow(ctx,
+		`SELECT `+pomodoroColumns+` FROM pomodoros WHERE user_id = $1 AND status = 'running'`,
+		userID,
+	))
+}
+
+// Finish ends the session as status, if it's still running.
+func (r *PomodoroRepository) Finish(ctx context.Context, scope models.Scope, id, status string) (*models.Pomodoro, error) {
+	return scanPomodoro(r.pool.QueryRow(ctx,
+		`UPDATE pomodoros SET status = $4, ended_at = NOW()
+		 WHERE id = $1 AND user_id = $2 AND COALESCE(org_id, '') = $3 AND status = 'running'
+		 RETURNING `+pomodoroColumns,
+		id, scope.UserID, scope.OrgID, status,
+	))
+}
+
+// List returns up to page.Limit+1 of the user's sessions in scope, newest
+// first.
+func (r *PomodoroRepository) List(ctx context.Context, scope models.Scope, page models.Page) ([]models.Pomodoro, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+pomodoroColumns+` FROM pomodoros
+		 WHERE user_id = $1 AND COALESCE(org_id, '') = $2
+		   AND ($3::timestamptz IS NULL OR (started_at, id) < ($3, $4::uuid))
+		 ORDER BY started_at DESC, id DESC
+		 LIMIT $5`,
+		scope.UserID, scope.OrgID, afterTime, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.Pomodoro{}
+	for rows.Next() {
+		p, err := scanPomodoro(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, *p)
+	}
+	return sessions, rows.Err()
+}
+
+// Days totals the user's finished sessions in scope by the day they started
+// on in loc, oldest first. Days without any are left out.
+func (r *PomodoroRepository) Days(ctx context.Context, scope models.Scope, loc *time.Location) ([]models.PomodoroDay, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT to_char((started_at AT TIME ZONE $3)::date, 'YYYY-MM-DD') AS day,
+		        COUNT(*) FILTER (WHERE status = 'completed'),
+		        COUNT(*) FILTER (WHERE status = 'interrupted'),
+		        COALESCE(SUM(EXTRACT(EPOCH FROM ended_at - started_at)), 0)::bigint
+		 FROM pomodoros
+		 WHERE user_id = $1 AND COALESCE(org_id, '') = $2 AND status <> 'running'
+		 GROUP BY day ORDER BY day`,
+		scope.UserID, scope.OrgID, loc.String(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	days := []models.PomodoroDay{}
+	for rows.Next() {
+		var d models.PomodoroDay
+		if err := rows.Scan(&d.Date, &d.Completed, &d.Interrupted, &d.FocusSeconds); err != nil {
+			return nil, err
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const projectTemplateColumns = `id, org_id, name, created_by, tasks, created_at, updated_at`
+
+type ProjectTemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewProjectTemplateRepository(pool *pgxpool.Pool) *ProjectTemplateRepository {
+	return &ProjectTemplateRepository{pool: pool}
+}
+
+func scanProjectTemplate(row pgx.Row) (*models.ProjectTemplate, error) {
+	var t models.ProjectTemplate
+	err := row.Scan(&t.ID, &t.OrgID, &t.Name, &t.CreatedBy, &t.Tasks, &t.CreatedAt, &t.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *ProjectTemplateRepository) Create(ctx context.Context, scope models.Scope, name string, tasks []models.ProjectTemplateTask) (*models.ProjectTemplate, error) {
+	return scanProjectTemplate(r.pool.QueryRow(ctx,
+		`INSERT INTO project_templates (org_id, name, created_by, tasks)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+projectTemplateColumns,
+		scope.OrgID, name, scope.UserID, tasks,
+	))
+}
+
+func (r *ProjectTemplateRepository) Get(ctx context.Context, scope models.Scope, id string) (*models.ProjectTemplate, error) {
+	return scanProjectTemplate(r.pool.QueryRow(ctx,
+		`SELECT `+projectTemplateColumns+` FROM project_templates WHERE id = $1 AND org_id = $2`,
+		id, scope.OrgID,
+	))
+}
+
+func (r *ProjectTemplateRepository) List(ctx context.Context, scope models.Scope) ([]models.ProjectTemplate, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+projectTemplateColumns+` FROM project_templates WHERE org_id = $1 ORDER BY name, id`,
+		scope.OrgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.ProjectTemplate{}
+	for rows.Next() {
+		t, err := scanProjectTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+func (r *ProjectTemplateRepository) Update(ctx context.Context, scope models.Scope, id string, input models.UpdateProjectTemplateInput) (*models.ProjectTemplate, error) {
+	var tasks any
+	if input.Tasks != nil {
+		tasks = input.Tasks
+	}
+	t, err := scanProjectTemplate(r.pool.QueryRow(ctx,
+		`UPDATE project_templates SET name = COALESCE($4, name), tasks = COALESCE($5, tasks), updated_at = NOW()
+		 WHERE id = $1 AND org_id = $2 AND created_by = $3
+		 RETURNING `+projectTemplateColumns,
+		id, scope.OrgID, scope.UserID, input.Name, tasks,
+	))
+	if errors.Is(err, ErrNotFound) {
+		return nil, r.writeMiss(ctx, scope, id)
+	}
+	return t, err
+}
+
+func (r *ProjectTemplateRepository) Delete(ctx context.Context, scope models.Scope, id string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM project_templates WHERE id = $1 AND org_id = $2 AND created_by = $3`,
+		id, scope.OrgID, scope.UserID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.writeMiss(ctx, scope, id)
+	}
+	return nil
+}
+
+// writeMiss tells a template the scope can't see from one it can see but
+// not change.
+func (r *ProjectTemplateRepository) writeMiss(ctx context.Context, scope models.Scope, id string) error {
+	if _, err := r.Get(ctx, scope, id); err != nil {
+		return err
+	}
+	return ErrReadOnly
+}
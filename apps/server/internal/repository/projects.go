@@ -0,0 +1,133 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/database"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const projectColumns = `id, org_id, name, created_by, archived_at, deleted_at, created_at, updated_at`
+
+type ProjectRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewProjectRepository(pool *pgxpool.Pool) *ProjectRepository {
+	return &ProjectRepository{pool: pool}
+}
+
+func scanProject(row pgx.Row) (*models.Project, error) {
+	var p models.Project
+	err := row.Scan(&p.ID, &p.OrgID, &p.Name, &p.CreatedBy, &p.ArchivedAt, &p.DeletedAt, &p.CreatedAt, &p.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func insertProject(ctx context.Context, q querier, orgID, userID string, input models.CreateProjectInput) (*models.Project, error) {
+	return scanProject(q.QueryRow(ctx,
+		`INSERT INTO projects (org_id, name, created_by)
+		 VALUES ($1, $2, $3)
+		 RETURNING `+projectColumns,
+		orgID, input.Name, userID,
+	))
+}
+
+func (r *ProjectRepository) Create(ctx context.Context, orgID, userID string, input models.CreateProjectInput) (*models.Project, error) {
+	return insertProject(ctx, r.pool, orgID, userID, input)
+}
+
+// CreateSeeded creates the project, its board and its tasks in one
+// transaction.
+func (r *ProjectRepository) CreateSeeded(ctx context.Context, scope models.Scope, input models.NewProject) (*models.SeededProject, error) {
+	var seeded *models.SeededProject
+	err := database.WithTx(ctx, r.pool, func(tx pgx.Tx) error {
+		project, err := insertProject(ctx, tx, scope.OrgID, scope.UserID, input.Input)
+		if err != nil {
+			return err
+		}
+		seeded = &models.SeededProject{Project: *project}
+		if input.Board != nil {
+			if seeded.Board, err = insertBoard(ctx, tx, scope, project.ID, *input.Board); err != nil {
+				return err
+			}
+		}
+		for i := range input.Tasks {
+			input.Tasks[i].Input.ProjectID = &project.ID
+		}
+		seeded.Tasks, err = insertTaskBatch(ctx, tx, scope, input.Tasks)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return seeded, nil
+}
+
+func (r *ProjectRepository) Get(ctx context.Context, orgID, id string) (*models.Project, error) {
+	row := r.pool.QueryRow(ctx,
+		`SELECT `+projectColumns+` FROM projects WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL`,
+		id, orgID,
+	)
+	return scanProject(row)
+}
+
+// List returns up to page.Limit+1 projects ordered by name.
+func (r *ProjectRepository) List(ctx context.Context, orgID string, includeArchived bool, page models.Page) ([]models.Project, error) {
+	afterName, afterID := "", ""
+	if len(page.After) == 2 {
+		afterName, afterID = page.After[0], page.After[1]
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+projectColumns+` FROM projects
+		 WHERE org_id = $1 AND deleted_at IS NULL AND ($2 OR archived_at IS NULL)
+		   AND ($3 = '' OR (name, id) > ($3, $4::uuid))
+		 ORDER BY name, id
+		 LIMIT $5`,
+		orgID, includeArchived, afterName, nullIfEmpty(afterID), page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *p)
+	}
+	return projects, rows.Err()
+}
+
+func (r *ProjectRepository) Rename(ctx context.Context, orgID, id, name string) (*models.Project, error) {
+	row := r.pool.QueryRow(ctx,
+		`UPDATE projects SET name = $3, updated_at = NOW()
+		 WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+		 RETURNING `+projectColumns,
+		id, orgID, name,
+	)
+	return scanProject(row)
+}
+
+func (r *ProjectRepository) SetArchived(ctx context.Context, orgID, id string, archived bool) (*models.Project, error) {
+	row := r.pool.QueryRow(ctx,
+		`UPDATE projects
+		 SET archived_at = CASE WHEN $3 THEN COALESCE(archived_at, NOW()) END, updated_at = NOW()
+		 WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+		 RETURNING `+projectColumns,
+		id, orgID, archived,
+	)
+	return scanProject(row)
+}
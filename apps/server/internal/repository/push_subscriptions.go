@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PushSubscriptionRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPushSubscriptionRepository(pool *pgxpool.Pool) *PushSubscriptionRepository {
+	return &PushSubscriptionRepository{pool: pool}
+}
+
+// Create registers the endpoint for userID. Browsers reuse an endpoint when
+// they resubscribe, so an existing row is taken over with the new keys.
+func (r *PushSubscriptionRepository) Create(ctx context.Context, userID string, input models.CreatePushSubscriptionInput) (*models.PushSubscription, error) {
+	var s models.PushSubscription
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO push_subscriptions (user_id, endpoint, p256dh, auth)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (endpoint) DO UPDATE SET user_id = $1, p256dh = $3, auth = $4
+		 RETURNING id, user_id, endpoint, p256dh, auth, created_at`,
+		userID, input.Endpoint, input.Keys.P256dh, input.Keys.Auth,
+	).Scan(&s.ID, &s.UserID, &s.Endpoint, &s.P256dh, &s.Auth, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *PushSubscriptionRepository) Delete(ctx context.Context, userID, endpoint string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM push_subscriptions WHERE user_id = $1 AND endpoint = $2`,
+		userID, endpoint,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *PushSubscriptionRepository) ListForUser(ctx context.Context, userID string) ([]models.PushSubscription, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT id, user_id, endpoint, p256dh, auth, created_at FROM push_subscriptions
+		 WHERE user_id = $1 ORDER BY created_at`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subs := []models.PushSubscription{}
+	for rows.Next() {
+		var s models.PushSubscription
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Endpoint, &s.P256dh, &s.Auth, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		subs = append(subs, s)
+	}
+	return subs, rows.Err()
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// reminderFireAt is when a reminder row goes off; it needs tasks joined as t.
+// A snoozed task's reminders wait for the snooze to end.
+const reminderFireAt = `GREATEST(` + reminderBaseFireAt + `, CASE WHEN ` + reminderBaseFireAt + ` IS NOT NULL THEN t.snoozed_until END)`
+
+const reminderBaseFireAt = `COALESCE(r.remind_at, t.due_date - make_interval(mins => r.minutes_before))`
+
+const reminderColumns = `r.id, r.task_id, r.user_id, r.remind_at, r.minutes_before, ` + reminderFireAt + `, r.sent_at, r.created_at`
+
+type ReminderRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewReminderRepository(pool *pgxpool.Pool) *ReminderRepository {
+	return &ReminderRepository{pool: pool}
+}
+
+func reminderFields(r *models.Reminder) []any {
+	return []any{&r.ID, &r.TaskID, &r.UserID, &r.RemindAt, &r.MinutesBefore, &r.FireAt, &r.SentAt, &r.CreatedAt}
+}
+
+func (r *ReminderRepository) Create(ctx context.Context, scope models.Scope, taskID string, input models.CreateReminderInput) (*models.Reminder, error) {
+	where, arg := liveTaskClause(scope, 5)
+	var rem models.Reminder
+	err := r.pool.QueryRow(ctx,
+		`WITH r AS (
+			INSERT INTO task_reminders (task_id, user_id, remind_at, minutes_before)
+			SELECT id, $2, $3, $4 FROM tasks WHERE id = $1 AND `+where+`
+			RETURNING *
+		)
+		SELECT `+reminderColumns+` FROM r JOIN tasks t ON t.id = r.task_id`,
+		taskID, scope.UserID, input.RemindAt, input.MinutesBefore, arg,
+	).Scan(reminderFields(&rem)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rem, nil
+}
+
+// List returns the caller's own reminders on the task.
+func (r *ReminderRepository) List(ctx context.Context, scope models.Scope, taskID string) ([]models.Reminder, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+reminderColumns+` FROM task_reminders r JOIN tasks t ON t.id = r.task_id
+		 WHERE r.task_id = $1 AND r.user_id = $2 AND `+where+`
+		 ORDER BY `+reminderFireAt+` NULLS LAST, r.created_at`,
+		taskID, scope.UserID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reminders := []models.Reminder{}
+	for rows.Next() {
+		var rem models.Reminder
+		if err := rows.Scan(reminderFields(&rem)...); err != nil {
+			return nil, err
+		}
+		reminders = append(reminders, rem)
+	}
+	return reminders, rows.Err()
+}
+
+func (r *ReminderRepository) Delete(ctx context.Context, scope models.Scope, taskID, id string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM task_reminders WHERE id = $1 AND task_id = $2 AND user_id = $3`,
+		id, taskID, scope.UserID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// ClaimDue marks up to limit unsent reminders that are due as sent and
+// returns them. SKIP LOCKED lets several instances poll at once without
+// delivering the same reminder twice.
+func (r *ReminderRepository) ClaimDue(ctx context.Context, limit int) ([]models.DueReminder, error) {
+	rows, err := r.pool.Query(ctx,
+		`WITH due AS (
+			SELECT r.id FROM task_reminders r JOIN tasks t ON t.id = r.task_id
+			WHERE r.sent_at IS NULL AND t.deleted_at IS NULL AND `+reminderFireAt+` <= NOW()
+			ORDER BY `+reminderFireAt+`
+			LIMIT $1
+			FOR UPDATE OF r SKIP LOCKED
+		), claimed AS (
+			UPDATE task_reminders SET sent_at = NOW()
+			WHERE id IN (SELECT id FROM due)
+			RETURNING *
+		)
+		SELECT `+reminderColumns+`, `+qualifiedColumns("t", taskColumns)+`
+		FROM claimed r JOIN tasks t ON t.id = r.task_id`,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	due := []models.DueReminder{}
+	for rows.Next() {
+		var d models.DueReminder
+		if err := rows.Scan(append(reminderFields(&d.Reminder), taskFields(&d.Task)...)...); err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+	return due, rows.Err()
+}
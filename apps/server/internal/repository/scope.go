@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"yata/apps/server/internal/models"
+)
+
+// scopeClause returns the predicate restricting a table to the scope, binding
+// its argument as $n. Org data is shared by the org; personal data belongs to
+// the user alone.
+func scopeClause(scope models.Scope, n int) (string, any) {
+	if scope.IsOrg() {
+		return fmt.Sprintf("org_id = $%d", n), scope.OrgID
+	}
+	return fmt.Sprintf("org_id IS NULL AND owner_id = $%d", n), scope.UserID
+}
+
+// ownerClause is scopeClause narrowed, in an org, to rows the scope's user
+// owns. It binds one argument at $n, or two in an org.
+func ownerClause(scope models.Scope, n int) (string, []any) {
+	where, arg := scopeClause(scope, n)
+	if !scope.IsOrg() {
+		return where, []any{arg}
+	}
+	return where + fmt.Sprintf(" AND owner_id = $%d", n+1), []any{arg, scope.UserID}
+}
+
+// taskAccessClause is scopeClause for table, the tasks table or its alias,
+// that also applies task visibility: in an org, private tasks are left out
+// unless the caller owns them or holds a share of at least role on them or
+// their project. Guests don't get the org-visible tasks either. Org tasks go
+// through a text[] of org and user id so that the clause still binds a
+// single argument.
+func taskAccessClause(table string, scope models.Scope, n int, role string) (string, any) {
+	if !scope.IsOrg() {
+		return fmt.Sprintf("%[1]s.org_id IS NULL AND %[1]s.owner_id = $%[2]d", table, n), scope.UserID
+	}
+
+	roles := `'editor'`
+	if role == models.ShareRoleViewer {
+		roles = `'viewer', 'editor'`
+	}
+	visible := fmt.Sprintf(`%s.visibility = 'org' OR `, table)
+	if scope.Guest {
+		visible = ""
+	}
+	return fmt.Sprintf(`%[1]s.org_id = ($%[2]d::text[])[1] AND (
+		%[4]s%[1]s.owner_id = ($%[2]d::text[])[2]
+		OR EXISTS (SELECT 1 FROM task_shares ts WHERE ts.task_id = %[1]s.id AND ts.user_id = ($%[2]d::text[])[2] AND ts.role IN (%[3]s))
+		OR EXISTS (SELECT 1 FROM project_shares ps WHERE ps.project_id = %[1]s.project_id AND ps.user_id = ($%[2]d::text[])[2] AND ps.role IN (%[3]s))
+	)`, table, n, roles, visible), []string{scope.OrgID, scope.UserID}
+}
+
+// liveTaskAccess is taskAccessClause that also leaves out tasks in the trash.
+func liveTaskAccess(table string, scope models.Scope, n int, role string) (string, any) {
+	where, arg := taskAccessClause(table, scope, n, role)
+	return where + " AND " + table + ".deleted_at IS NULL", arg
+}
+
+// liveTaskClause restricts an unaliased tasks table to live tasks the scope
+// can see.
+func liveTaskClause(scope models.Scope, n int) (string, any) {
+	return liveTaskAccess("tasks", scope, n, models.ShareRoleViewer)
+}
+
+// editableTaskClause restricts an unaliased tasks table to live tasks the
+// scope can change.
+func editableTaskClause(scope models.Scope, n int) (string, any) {
+	return liveTaskAccess("tasks", scope, n, models.ShareRoleEditor)
+}
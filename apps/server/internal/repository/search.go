@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"yata/apps/server/internal/models"
+)
+
+// Highlighted terms are wrapped in <mark>; everything else in the snippet is
+// plain task text, so clients must still escape it before rendering.
+const headlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2`
+
+type SearchRepository struct {
+	pool Reader
+}
+
+func NewSearchRepository(pool Reader) *SearchRepository {
+	return &SearchRepository{pool: pool}
+}
+
+func (r *SearchRepository) SearchTasks(ctx context.Context, scope models.Scope, query string, limit int) ([]models.SearchResult, error) {
+	q := &queryBuilder{}
+	q.scope(scope)
+	tsquery := "websearch_to_tsquery('english', " + q.arg(query) + ")"
+	q.where("search_vector @@ " + tsquery)
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+taskColumns+`,
+		        ts_rank(search_vector, `+tsquery+`) AS rank,
+		        ts_headline('english', title, `+tsquery+`, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>'),
+		        ts_headline('english', description, `+tsquery+`, '`+headlineOptions+`')
+		 FROM tasks
+		 WHERE `+q.clause()+`
+		 ORDER BY rank DESC, updated_at DESC
+		 LIMIT `+q.arg(limit),
+		q.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var res models.SearchResult
+		err := rows.Scan(append(taskFields(&res.Task), &res.Rank, &res.Title, &res.Snippet)...)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
+
+// memberSearchText is what a member is matched on. It must stay the same
+// expression as idx_users_search_trgm for the index to be used.
+const memberSearchText = `(coalesce(u.first_name, '') || ' ' || coalesce(u.last_name, '') || ' ' || coalesce(u.username, '') || ' ' || coalesce(u.email, ''))`
+
+// wordPrefix matches column when query starts it or any word in it, and
+// namePrefix, for ranking, only when query starts it.
+func wordPrefix(column, arg string) string {
+	return `(` + column + ` ILIKE ` + arg + ` || '%' OR ` + column + ` ILIKE '% ' || ` + arg + ` || '%')`
+}
+
+func namePrefix(column, arg string) string {
+	return `(` + column + ` ILIKE ` + arg + ` || '%')`
+}
+
+// escapeLike makes s match literally in a LIKE pattern.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+func (r *SearchRepository) Suggest(ctx context.Context, scope models.Scope, kind, query string, limit int) ([]models.Suggestion, error) {
+	var sql string
+	switch kind {
+	case models.SuggestLabel:
+		sql = `SELECT l.id, l.name, l.color, NULL::text, NULL::text, used.at
+		       FROM labels l
+		       LEFT JOIN LATERAL (SELECT max(tl.created_at) AS at FROM task_labels tl WHERE tl.label_id = l.id) used ON true
+		       WHERE l.org_id = $1 AND ` + wordPrefix("l.name", "$2") + `
+		       ORDER BY ` + namePrefix("l.name", "$2") + ` DESC, used.at DESC NULLS LAST, l.name
+		       LIMIT $3`
+	case models.SuggestProject:
+		sql = `SELECT p.id, p.name, '', NULL::text, NULL::text, used.at
+		       FROM projects p
+		       LEFT JOIN LATERAL (SELECT max(t.updated_at) AS at FROM tasks t WHERE t.project_id = p.id AND t.deleted_at IS NULL) used ON true
+		       WHERE p.org_id = $1 AND p.deleted_at IS NULL AND p.archived_at IS NULL AND ` + wordPrefix("p.name", "$2") + `
+		       ORDER BY ` + namePrefix("p.name", "$2") + ` DESC, used.at DESC NULLS LAST, p.name
+		       LIMIT $3`
+	case models.SuggestMember:
+		name := `coalesce(nullif(trim(coalesce(u.first_name, '') || ' ' || coalesce(u.last_name, '')), ''), u.username, u.email, m.user_id)`
+		sql = `SELECT m.user_id, ` + name + `, '', u.email, u.image_url, used.at
+		       FROM org_memberships m
+		       JOIN users u ON u.id = m.user_id AND u.deleted_at IS NULL
+		       LEFT JOIN LATERAL (SELECT max(a.created_at) AS at FROM activity a WHERE a.org_id = m.org_id AND a.actor_id = m.user_id) used ON true
+		       WHERE m.org_id = $1 AND ` + wordPrefix(memberSearchText, "$2") + `
+		       ORDER BY ` + namePrefix(name, "$2") + ` DESC, used.at DESC NULLS LAST, ` + name + `
+		       LIMIT $3`
+	default:
+		return nil, fmt.Errorf("unknown suggestion type %q", kind)
+	}
+
+	rows, err := r.pool.Query(ctx, sql, scope.OrgID, escapeLike(query), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	suggestions := []models.Suggestion{}
+	for rows.Next() {
+		var s models.Suggestion
+		if err := rows.Scan(&s.ID, &s.Name, &s.Color, &s.Email, &s.ImageURL, &s.LastUsedAt); err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, s)
+	}
+	return suggestions, rows.Err()
+}
+
+func (r *SearchRepository) SimilarTasks(ctx context.Context, scope models.Scope, projectID *string, title string, doneStatuses []string, limit int) ([]models.SimilarTask, error) {
+	q := &queryBuilder{}
+	q.scope(scope)
+	if projectID != nil {
+		q.where("project_id = " + q.arg(*projectID))
+	} else {
+		q.where("project_id IS NULL")
+	}
+	q.where("archived_at IS NULL")
+	q.where("status <> ALL(" + q.arg(doneStatuses) + ")")
+	// % narrows down with the index at pg_trgm's looser threshold first.
+	titleArg := q.arg(title)
+	q.where("title % " + titleArg)
+	q.where("similarity(title, " + titleArg + ") >= " + q.arg(models.DuplicateSimilarity))
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+taskColumns+`, similarity(title, `+titleArg+`) AS similarity
+		 FROM tasks
+		 WHERE `+q.clause()+`
+		 ORDER BY similarity DESC, updated_at DESC
+		 LIMIT `+q.arg(limit),
+		q.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	similar := []models.SimilarTask{}
+	for rows.Next() {
+		var s models.SimilarTask
+		if err := rows.Scan(append(taskFields(&s.Task), &s.Similarity)...); err != nil {
+			return nil, err
+		}
+		similar = append(similar, s)
+	}
+	return similar, rows.Err()
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const shareLinkColumns = `id, org_id, task_id, project_id, created_by, expires_at, revoked_at, created_at`
+
+const shareLinkAccessColumns = `id, link_id, ip, user_agent, accessed_at`
+
+type ShareLinkRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewShareLinkRepository(pool *pgxpool.Pool) *ShareLinkRepository {
+	return &ShareLinkRepository{pool: pool}
+}
+
+func scanShareLink(row pgx.Row) (*models.ShareLink, error) {
+	var l models.ShareLink
+	err := row.Scan(&l.ID, &l.OrgID, &l.TaskID, &l.ProjectID, &l.CreatedBy, &l.ExpiresAt, &l.RevokedAt, &l.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *ShareLinkRepository) list(ctx context.Context, where string, args ...any) ([]models.ShareLink, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+shareLinkColumns+` FROM share_links WHERE `+where+` ORDER BY created_at DESC, id DESC`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []models.ShareLink{}
+	for rows.Next() {
+		l, err := scanShareLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, *l)
+	}
+	return links, rows.Err()
+}
+
+// Create links a task the scope can change, or a live project of the org.
+// A task the scope may only view is ErrReadOnly.
+func (r *ShareLinkRepository) Create(ctx context.Context, scope models.Scope, input models.CreateShareLinkInput) (*models.ShareLink, error) {
+	if input.TaskID != nil {
+		where, arg := editableTaskClause(scope, 4)
+		link, err := scanShareLink(r.pool.QueryRow(ctx,
+			`INSERT INTO share_links (org_id, task_id, created_by, expires_at)
+			 SELECT org_id, id, $2, $3 FROM tasks WHERE id = $1 AND `+where+`
+			 RETURNING `+shareLinkColumns,
+			*input.TaskID, scope.UserID, input.ExpiresAt, arg,
+		))
+		if errors.Is(err, ErrNotFound) {
+			return nil, r.readOnlyMiss(ctx, scope, *input.TaskID)
+		}
+		return link, err
+	}
+	if input.ProjectID == nil || !scope.IsOrg() {
+		return nil, ErrNotFound
+	}
+	return scanShareLink(r.pool.QueryRow(ctx,
+		`INSERT INTO share_links (org_id, project_id, created_by, expires_at)
+		 SELECT org_id, id, $2, $3 FROM projects WHERE id = $1 AND org_id = $4 AND deleted_at IS NULL
+		 RETURNING `+shareLinkColumns,
+		*input.ProjectID, scope.UserID, input.ExpiresAt, scope.OrgID,
+	))
+}
+
+// readOnlyMiss tells a task the caller may only view, ErrReadOnly, from one
+// they can't see at all.
+func (r *ShareLinkRepository) readOnlyMiss(ctx context.Context, scope models.Scope, taskID string) error {
+	where, arg := liveTaskClause(scope, 2)
+	var visible bool
+	if err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND `+where+`)`,
+		taskID, arg,
+	).Scan(&visible); err != nil {
+		return err
+	}
+	if visible {
+		return ErrReadOnly
+	}
+	return ErrNotFound
+}
+
+func (r *ShareLinkRepository) Get(ctx context.Context, id string) (*models.ShareLink, error) {
+	return scanShareLink(r.pool.QueryRow(ctx,
+		`SELECT `+shareLinkColumns+` FROM share_links WHERE id = $1`,
+		id,
+	))
+}
+
+// ListForTask returns the links the caller made to the task, newest first.
+func (r *ShareLinkRepository) ListForTask(ctx context.Context, scope models.Scope, taskID string) ([]models.ShareLink, error) {
+	return r.list(ctx, `task_id = $1 AND created_by = $2 AND org_id IS NOT DISTINCT FROM $3`, taskID, scope.UserID, scope.OrgIDPtr())
+}
+
+// ListForProject returns the links the caller made to the project, newest
+// first.
+func (r *ShareLinkRepository) ListForProject(ctx context.Context, scope models.Scope, projectID string) ([]models.ShareLink, error) {
+	return r.list(ctx, `project_id = $1 AND created_by = $2 AND org_id IS NOT DISTINCT FROM $3`, projectID, scope.UserID, scope.OrgIDPtr())
+}
+
+// Revoke only revokes the caller's own links; revoking twice is a no-op.
+func (r *ShareLinkRepository) Revoke(ctx context.Context, scope models.Scope, id string) (*models.ShareLink, error) {
+	return scanShareLink(r.pool.QueryRow(ctx,
+		`UPDATE share_links SET revoked_at = COALESCE(revoked_at, NOW())
+		 WHERE id = $1 AND created_by = $2 AND org_id IS NOT DISTINCT FROM $3
+		 RETURNING `+shareLinkColumns,
+		id, scope.UserID, scope.OrgIDPtr(),
+	))
+}
+
+func (r *ShareLinkRepository) RevokeMember(ctx context.Context, orgID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`UPDATE share_links SET revoked_at = NOW()
+		 WHERE org_id = $1 AND created_by = $2 AND revoked_at IS NULL`,
+		orgID, userID,
+	)
+	return err
+}
+
+func (r *ShareLinkRepository) RecordAccess(ctx context.Context, linkID, ip, userAgent string) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO share_link_accesses (link_id, ip, user_agent) VALUES ($1, $2, $3)`,
+		linkID, ip, userAgent,
+	)
+	return err
+}
+
+// ListAccesses returns up to page.Limit+1 opens of one of the caller's
+// links, newest first.
+func (r *ShareLinkRepository) ListAccesses(ctx context.Context, scope models.Scope, id string, page models.Page) ([]models.ShareLinkAccess, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+qualifiedColumns("a", shareLinkAccessColumns)+`
+		 FROM share_link_accesses a JOIN share_links l ON l.id = a.link_id
+		 WHERE a.link_id = $1 AND l.created_by = $2 AND l.org_id IS NOT DISTINCT FROM $3
+		   AND ($4::timestamptz IS NULL OR (a.accessed_at, a.id) < ($4, $5::uuid))
+		 ORDER BY a.accessed_at DESC, a.id DESC
+		 LIMIT $6`,
+		id, scope.UserID, scope.OrgIDPtr(), afterTime, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	accesses := []models.ShareLinkAccess{}
+	for rows.Next() {
+		var a models.ShareLinkAccess
+		if err := rows.Scan(&a.ID, &a.LinkID, &a.IP, &a.UserAgent, &a.AccessedAt); err != nil {
+			return nil, err
+		}
+		accesses = append(accesses, a)
+	}
+	return accesses, rows.Err()
+}
@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ShareRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewShareRepository(pool *pgxpool.Pool) *ShareRepository {
+	return &ShareRepository{pool: pool}
+}
+
+func scanShare(row pgx.Row) (*models.Share, error) {
+	var s models.Share
+	err := row.Scan(&s.TaskID, &s.ProjectID, &s.UserID, &s.Role, &s.CreatedBy, &s.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func scanShares(rows pgx.Rows, err error) ([]models.Share, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shares := []models.Share{}
+	for rows.Next() {
+		s, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, *s)
+	}
+	return shares, rows.Err()
+}
+
+// ShareTask only shares org tasks; personal ones have nobody to share with.
+func (r *ShareRepository) ShareTask(ctx context.Context, scope models.Scope, taskID string, input models.ShareInput) (*models.Share, error) {
+	if !scope.IsOrg() {
+		return nil, ErrNotFound
+	}
+	where, arg := liveTaskClause(scope, 5)
+	return scanShare(r.pool.QueryRow(ctx,
+		`INSERT INTO task_shares (task_id, user_id, role, created_by)
+		 SELECT id, $2, $3, $4 FROM tasks WHERE id = $1 AND `+where+`
+		 ON CONFLICT (task_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		 RETURNING task_id::text, NULL::text, user_id, role, created_by, created_at`,
+		taskID, input.UserID, input.Role, scope.UserID, arg,
+	))
+}
+
+// ListTaskShares returns the task's shares, oldest first.
+func (r *ShareRepository) ListTaskShares(ctx context.Context, scope models.Scope, taskID string) ([]models.Share, error) {
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	return scanShares(r.pool.Query(ctx,
+		`SELECT s.task_id::text, NULL::text, s.user_id, s.role, s.created_by, s.created_at
+		 FROM task_shares s JOIN tasks t ON t.id = s.task_id
+		 WHERE s.task_id = $1 AND `+where+`
+		 ORDER BY s.created_at, s.user_id`,
+		taskID, arg,
+	))
+}
+
+func (r *ShareRepository) UnshareTask(ctx context.Context, scope models.Scope, taskID, userID string) error {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM task_shares s USING tasks t
+		 WHERE s.task_id = $1 AND s.user_id = $2 AND t.id = s.task_id AND `+where,
+		taskID, userID, arg,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *ShareRepository) ShareProject(ctx context.Context, scope models.Scope, projectID string, input models.ShareInput) (*models.Share, error) {
+	if !scope.IsOrg() {
+		return nil, ErrNotFound
+	}
+	return scanShare(r.pool.QueryRow(ctx,
+		`INSERT INTO project_shares (project_id, user_id, role, created_by)
+		 SELECT id, $2, $3, $4 FROM projects WHERE id = $1 AND org_id = $5 AND deleted_at IS NULL
+		 ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role
+		 RETURNING NULL::text, project_id::text, user_id, role, created_by, created_at`,
+		projectID, input.UserID, input.Role, scope.UserID, scope.OrgID,
+	))
+}
+
+// ListProjectShares returns the project's shares, oldest first.
+func (r *ShareRepository) ListProjectShares(ctx context.Context, scope models.Scope, projectID string) ([]models.Share, error) {
+	return scanShares(r.pool.Query(ctx,
+		`SELECT NULL::text, s.project_id::text, s.user_id, s.role, s.created_by, s.created_at
+		 FROM project_shares s JOIN projects p ON p.id = s.project_id
+		 WHERE s.project_id = $1 AND p.org_id = $2 AND p.deleted_at IS NULL
+		 ORDER BY s.created_at, s.user_id`,
+		projectID, scope.OrgID,
+	))
+}
+
+func (r *ShareRepository) UnshareProject(ctx context.Context, scope models.Scope, projectID, userID string) error {
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM project_shares s USING projects p
+		 WHERE s.project_id = $1 AND s.user_id = $2 AND p.id = s.project_id AND p.org_id = $3 AND p.deleted_at IS NULL`,
+		projectID, userID, scope.OrgID,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const slackColumns = `org_id, team_id, task_assigned_channel, due_soon_channel, updated_at,
+	COALESCE(bot_token, ''), COALESCE(webhook_url, '')`
+
+type SlackRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSlackRepository(pool *pgxpool.Pool) *SlackRepository {
+	return &SlackRepository{pool: pool}
+}
+
+func scanSlackIntegration(row pgx.Row) (*models.SlackIntegration, error) {
+	var s models.SlackIntegration
+	err := row.Scan(&s.OrgID, &s.TeamID, &s.TaskAssignedChannel, &s.DueSoonChannel, &s.UpdatedAt, &s.BotToken, &s.WebhookURL)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.HasBotToken = s.BotToken != ""
+	s.HasWebhook = s.WebhookURL != ""
+	return &s, nil
+}
+
+func (r *SlackRepository) Get(ctx context.Context, orgID string) (*models.SlackIntegration, error) {
+	return scanSlackIntegration(r.pool.QueryRow(ctx,
+		`SELECT `+slackColumns+` FROM slack_integrations WHERE org_id = $1`,
+		orgID,
+	))
+}
+
+// Set keeps the stored secrets unless the input sets or clears them.
+func (r *SlackRepository) Set(ctx context.Context, orgID string, input models.SetSlackIntegrationInput) (*models.SlackIntegration, error) {
+	var botToken, webhookURL *string
+	if input.BotToken.Set {
+		botToken = input.BotToken.Ptr()
+	}
+	if input.WebhookURL.Set {
+		webhookURL = input.WebhookURL.Ptr()
+	}
+	s, err := scanSlackIntegration(r.pool.QueryRow(ctx,
+		`INSERT INTO slack_integrations (org_id, team_id, task_assigned_channel, due_soon_channel, bot_token, webhook_url)
+		 VALUES ($1, $2, $3, $4, $6, $8)
+		 ON CONFLICT (org_id) DO UPDATE
+		 SET team_id = EXCLUDED.team_id,
+		     task_assigned_channel = EXCLUDED.task_assigned_channel,
+		     due_soon_channel = EXCLUDED.due_soon_channel,
+		     bot_token = CASE WHEN $5 THEN EXCLUDED.bot_token ELSE slack_integrations.bot_token END,
+		     webhook_url = CASE WHEN $7 THEN EXCLUDED.webhook_url ELSE slack_integrations.webhook_url END,
+		     updated_at = NOW()
+		 RETURNING `+slackColumns,
+		orgID, input.TeamID, input.TaskAssignedChannel, input.DueSoonChannel,
+		input.BotToken.Set, botToken, input.WebhookURL.Set, webhookURL,
+	))
+	if isUniqueViolation(err) {
+		return nil, ErrConflict
+	}
+	return s, err
+}
+
+func (r *SlackRepository) Delete(ctx context.Context, orgID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM slack_integrations WHERE org_id = $1`, orgID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *SlackRepository) GetByTeam(ctx context.Context, teamID string) (*models.SlackIntegration, error) {
+	return scanSlackIntegration(r.pool.QueryRow(ctx,
+		`SELECT `+slackColumns+` FROM slack_integrations WHERE team_id = $1`,
+		teamID,
+	))
+}
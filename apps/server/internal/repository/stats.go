@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type StatsRepository struct {
+	pool Reader
+}
+
+func NewStatsRepository(pool Reader) *StatsRepository {
+	return &StatsRepository{pool: pool}
+}
+
+// Completed counts per period with one pass over the completed tasks in the
+// whole range, bucketing each by the last bound at or before its completion.
+func (r *StatsRepository) Completed(ctx context.Context, scope models.Scope, bounds []time.Time) ([]int, error) {
+	counts := make([]int, max(len(bounds)-1, 0))
+	if len(counts) == 0 {
+		return counts, nil
+	}
+
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT width_bucket(t.completed_at, $1::timestamptz[]) AS bucket, COUNT(*)
+		 FROM tasks t
+		 WHERE t.completed_at >= ($1::timestamptz[])[1]
+		   AND t.completed_at < ($1::timestamptz[])[array_length($1::timestamptz[], 1)]
+		   AND `+where+`
+		 GROUP BY bucket`,
+		bounds, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var bucket, count int
+		if err := rows.Scan(&bucket, &count); err != nil {
+			return nil, err
+		}
+		if bucket >= 1 && bucket <= len(counts) {
+			counts[bucket-1] = count
+		}
+	}
+	return counts, rows.Err()
+}
+
+// Overdue gathers the spans the scope's tasks spent overdue once, then
+// counts the spans open at each point.
+func (r *StatsRepository) Overdue(ctx context.Context, scope models.Scope, points []time.Time) ([]int, error) {
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`WITH spans AS MATERIALIZED (
+		   SELECT GREATEST(t.due_date, t.created_at) AS since, t.completed_at AS until
+		   FROM tasks t
+		   WHERE t.due_date IS NOT NULL AND t.due_date < ($1::timestamptz[])[array_length($1::timestamptz[], 1)]
+		     AND (t.completed_at IS NULL OR t.completed_at > GREATEST(t.due_date, t.created_at))
+		     AND `+where+`
+		 )
+		 SELECT (SELECT COUNT(*) FROM spans WHERE since < p.at AND (until IS NULL OR until > p.at))
+		 FROM unnest($1::timestamptz[]) WITH ORDINALITY AS p(at, i)
+		 ORDER BY p.i`,
+		points, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// Throughput covers the tasks in the scope's org, most completed first.
+func (r *StatsRepository) Throughput(ctx context.Context, scope models.Scope, from, to time.Time) ([]models.MemberThroughput, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT t.owner_id, COUNT(*),
+		        COALESCE(AVG(EXTRACT(EPOCH FROM t.completed_at - t.created_at)), 0)::bigint
+		 FROM tasks t
+		 WHERE t.completed_at >= $1 AND t.completed_at < $2 AND `+where+`
+		 GROUP BY t.owner_id
+		 ORDER BY COUNT(*) DESC, t.owner_id`,
+		from, to, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.MemberThroughput{}
+	for rows.Next() {
+		var m models.MemberThroughput
+		if err := rows.Scan(&m.UserID, &m.Completed, &m.AvgCycleSeconds); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+func (r *StatsRepository) Burndown(ctx context.Context, scope models.Scope, projectID string, points []time.Time) ([]models.BurndownPoint, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`WITH project_tasks AS MATERIALIZED (
+		   SELECT t.created_at, t.completed_at FROM tasks t
+		   WHERE t.project_id = $2 AND `+where+`
+		 )
+		 SELECT COUNT(pt.created_at),
+		        COUNT(pt.created_at) FILTER (WHERE pt.completed_at IS NULL OR pt.completed_at > p.at)
+		 FROM unnest($1::timestamptz[]) WITH ORDINALITY AS p(at, i)
+		 LEFT JOIN project_tasks pt ON pt.created_at <= p.at
+		 GROUP BY p.i ORDER BY p.i`,
+		points, projectID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	burndown := make([]models.BurndownPoint, 0, len(points))
+	for rows.Next() {
+		var p models.BurndownPoint
+		if err := rows.Scan(&p.Total, &p.Remaining); err != nil {
+			return nil, err
+		}
+		burndown = append(burndown, p)
+	}
+	return burndown, rows.Err()
+}
+
+// Workload aggregates the open tasks once, with overdue ones reaching back
+// past from, then joins every member to their totals. Tasks due in the
+// range without an estimate take their subtasks' total, as SubtaskEstimate
+// rolls it up, and the subtasks rolled into one aren't counted again.
+func (r *StatsRepository) Workload(ctx context.Context, scope models.Scope, from, to time.Time) ([]models.MemberWorkload, error) {
+	where, arg := liveTaskAccess("t", scope, 4, models.ShareRoleViewer)
+	subtasks, _ := liveTaskAccess("c", scope, 4, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`WITH RECURSIVE open_tasks AS (
+		   SELECT t.id, t.owner_id, t.due_date, t.estimate_minutes, t.due_date >= $1 AND t.due_date < $2 AS in_range
+		   FROM tasks t
+		   WHERE t.completed_at IS NULL AND t.archived_at IS NULL
+		     AND (t.due_date >= $1 AND t.due_date < $2 OR t.due_date < NOW()) AND `+where+`
+		 ),
+		 rolled AS (
+		   SELECT o.id AS root, c.id, c.estimate_minutes, 1 AS depth
+		   FROM open_tasks o JOIN tasks c ON c.parent_id = o.id
+		   WHERE o.in_range AND o.estimate_minutes IS NULL AND `+subtasks+`
+		   UNION ALL
+		   SELECT r.root, c.id, c.estimate_minutes, r.depth + 1
+		   FROM rolled r JOIN tasks c ON c.parent_id = r.id
+		   WHERE r.estimate_minutes IS NULL AND r.depth < 50 AND `+subtasks+`
+		 ),
+		 load AS (
+		   SELECT o.owner_id,
+		          COUNT(*) FILTER (WHERE o.in_range) AS open,
+		          COALESCE(SUM(COALESCE(o.estimate_minutes, ru.estimate)) FILTER (WHERE o.in_range AND NOT o.rolled_up), 0)::bigint AS estimate,
+		          COUNT(*) FILTER (WHERE o.in_range AND NOT o.rolled_up AND o.estimate_minutes IS NULL AND ru.estimate IS NULL) AS unestimated,
+		          COUNT(*) FILTER (WHERE o.due_date < NOW()) AS overdue
+		   FROM (SELECT ot.*, EXISTS (SELECT 1 FROM rolled WHERE rolled.id = ot.id) AS rolled_up FROM open_tasks ot) o
+		   LEFT JOIN (SELECT root, SUM(estimate_minutes) AS estimate FROM rolled GROUP BY root) ru ON ru.root = o.id
+		   GROUP BY o.owner_id
+		 )
+		 SELECT m.user_id, COALESCE(l.open, 0), COALESCE(l.estimate, 0), COALESCE(l.unestimated, 0), COALESCE(l.overdue, 0)
+		 FROM org_memberships m
+		 LEFT JOIN load l ON l.owner_id = m.user_id
+		 WHERE m.org_id = $3
+		 ORDER BY COALESCE(l.estimate, 0) DESC, COALESCE(l.open, 0) DESC, m.user_id`,
+		from, to, scope.OrgID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.MemberWorkload{}
+	for rows.Next() {
+		var m models.MemberWorkload
+		if err := rows.Scan(&m.UserID, &m.Open, &m.EstimateMinutes, &m.Unestimated, &m.Overdue); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
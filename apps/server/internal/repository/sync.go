@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"yata/apps/server/internal/events"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// syncDeletedField is the clock a delete op stamps; once present, every
+// other op on the task is superseded.
+const syncDeletedField = "_deleted"
+
+// syncColumnValues maps a sync field to its column and the expression that
+// converts the op's JSON value, bound as $1, to the column type.
+var syncColumnValues = map[string][2]string{
+	"title":       {"title", `$1::jsonb #>> '{}'`},
+	"description": {"description", `$1::jsonb #>> '{}'`},
+	"status":      {"status", `$1::jsonb #>> '{}'`},
+	"priority":    {"priority", `($1::jsonb #>> '{}')::int`},
+	"dueDate":     {"due_date", `($1::jsonb #>> '{}')::timestamptz`},
+	"dueTimezone": {"due_timezone", `$1::jsonb #>> '{}'`},
+	"projectId":   {"project_id", `($1::jsonb #>> '{}')::uuid`},
+}
+
+var errSyncRejected = errors.New("task not found")
+
+// SyncConflictError is an op on a task id that another user or org's task
+// already has. It fails the whole push, since the client has to give the
+// task a new id before any of its ops can go through.
+type SyncConflictError struct {
+	OpID   string
+	TaskID string
+}
+
+func (e *SyncConflictError) Error() string {
+	return fmt.Sprintf("sync op %s: task id %s is taken", e.OpID, e.TaskID)
+}
+
+func (e *SyncConflictError) Unwrap() error { return ErrConflict }
+
+type SyncRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewSyncRepository(pool *pgxpool.Pool) *SyncRepository {
+	return &SyncRepository{pool: pool}
+}
+
+// Push applies ops in order and returns what happened to each, plus the
+// scope's checkpoint afterwards. Pushes in one scope are serialised so log
+// sequence numbers become visible in order and pulls can't skip any.
+func (r *SyncRepository) Push(ctx context.Context, scope models.Scope, defaultStatus string, ops []models.SyncOp) ([]models.SyncResult, int64, error) {
+	results := make([]models.SyncResult, 0, len(ops))
+	var checkpoint int64
+
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		results = results[:0]
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext('sync:' || $1))`, events.Topic(scope)); err != nil {
+			return err
+		}
+
+		for _, op := range ops {
+			status, err := r.applyOp(ctx, tx, scope, defaultStatus, op)
+			if errors.Is(err, errSyncRejected) {
+				results = append(results, models.SyncResult{ID: op.ID, Status: models.SyncRejected, Error: err.Error()})
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			results = append(results, models.SyncResult{ID: op.ID, Status: status})
+		}
+
+		where, arg := scopeClause(scope, 1)
+		return tx.QueryRow(ctx, `SELECT COALESCE(MAX(seq), 0) FROM sync_ops WHERE `+where, arg).Scan(&checkpoint)
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return results, checkpoint, nil
+}
+
+// applyOp runs in a savepoint so a rejected op leaves no partial writes.
+func (r *SyncRepository) applyOp(ctx context.Context, outer pgx.Tx, scope models.Scope, defaultStatus string, op models.SyncOp) (string, error) {
+	status := models.SyncSuperseded
+	err := pgx.BeginFunc(ctx, outer, func(tx pgx.Tx) error {
+		var seen bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM sync_ops WHERE owner_id = $1 AND id = $2)`,
+			scope.UserID, op.ID,
+		).Scan(&seen); err != nil {
+			return err
+		}
+		if seen {
+			// A retry of an op that already went through.
+			status = models.SyncApplied
+			return nil
+		}
+
+		scoped, scopedArg := scopeClause(scope, 2)
+		where, arg := taskAccessClause("tasks", scope, 3, models.ShareRoleEditor)
+		var ours, inScope bool
+		taskErr := tx.QueryRow(ctx,
+			`SELECT COALESCE(`+scoped+`, FALSE), COALESCE(`+where+`, FALSE) FROM tasks WHERE id = $1`,
+			op.TaskID, scopedArg, arg,
+		).Scan(&ours, &inScope)
+		if taskErr != nil && !errors.Is(taskErr, pgx.ErrNoRows) {
+			return taskErr
+		}
+		if taskErr == nil && !ours {
+			return &SyncConflictError{OpID: op.ID, TaskID: op.TaskID}
+		}
+
+		var deleted bool
+		if err := tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM task_field_clocks WHERE task_id = $1 AND field = $2)`,
+			op.TaskID, syncDeletedField,
+		).Scan(&deleted); err != nil {
+			return err
+		}
+		if deleted {
+			return nil
+		}
+
+		switch {
+		case taskErr != nil:
+			if op.Type == models.SyncOpSet {
+				position, err := nextTaskPosition(ctx, tx, scope)
+				if err != nil {
+					return err
+				}
+				if _, err := tx.Exec(ctx,
+					`INSERT INTO tasks (id, owner_id, org_id, title, status, position) VALUES ($1, $2, $3, '', $4, $5)`,
+					op.TaskID, scope.UserID, scope.OrgIDPtr(), defaultStatus, position,
+				); err != nil {
+					return err
+				}
+			}
+		case !inScope:
+			return errSyncRejected
+		}
+
+		field := op.Field
+		if op.Type == models.SyncOpDelete {
+			field = syncDeletedField
+		}
+		var won bool
+		err := tx.QueryRow(ctx,
+			`INSERT INTO task_field_clocks (task_id, field, ts_wall, ts_counter, ts_node)
+			 VALUES ($1, $2, $3, $4, $5)
+			 ON CONFLICT (task_id, field) DO UPDATE
+			 SET ts_wall = EXCLUDED.ts_wall, ts_counter = EXCLUDED.ts_counter, ts_node = EXCLUDED.ts_node
+			 WHERE (EXCLUDED.ts_wall, EXCLUDED.ts_counter, EXCLUDED.ts_node)
+			     > (task_field_clocks.ts_wall, task_field_clocks.ts_counter, task_field_clocks.ts_node)
+			 RETURNING TRUE`,
+			op.TaskID, field, op.Timestamp.Wall, op.Timestamp.Counter, op.Timestamp.Node,
+		).Scan(&won)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if op.Type == models.SyncOpDelete {
+			var deletedAt time.Time
+			err = tx.QueryRow(ctx,
+				`UPDATE tasks SET deleted_at = NOW(), version = version + 1, updated_at = NOW()
+				 WHERE id = $1 AND deleted_at IS NULL
+				 RETURNING deleted_at`,
+				op.TaskID,
+			).Scan(&deletedAt)
+			if err == nil {
+				err = trashSubtasks(ctx, tx, []string{op.TaskID}, deletedAt)
+			} else if errors.Is(err, pgx.ErrNoRows) {
+				err = nil
+			}
+		} else {
+			col := syncColumnValues[op.Field]
+			_, err = tx.Exec(ctx,
+				`UPDATE tasks SET `+col[0]+` = `+col[1]+`, version = version + 1, updated_at = NOW() WHERE id = $2`,
+				[]byte(op.Value), op.TaskID,
+			)
+		}
+		if err != nil {
+			return err
+		}
+
+		var value []byte
+		if op.Type == models.SyncOpSet {
+			value = op.Value
+		}
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO sync_ops (id, owner_id, org_id, task_id, type, field, value, ts_wall, ts_counter, ts_node)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`,
+			op.ID, scope.UserID, scope.OrgIDPtr(), op.TaskID, op.Type, nullIfEmpty(op.Field), value,
+			op.Timestamp.Wall, op.Timestamp.Counter, op.Timestamp.Node,
+		); err != nil {
+			return err
+		}
+		status = models.SyncApplied
+		return nil
+	})
+	return status, err
+}
+
+// Pull returns up to limit+1 logged ops after the since checkpoint, leaving
+// out ops on tasks the caller can no longer see.
+func (r *SyncRepository) Pull(ctx context.Context, scope models.Scope, since int64, limit int) ([]models.SyncOp, error) {
+	where, arg := scopeClause(scope, 2)
+	access, accessArg := taskAccessClause("t", scope, 4, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT seq, id, task_id, type, COALESCE(field, ''), value, ts_wall, ts_counter, ts_node
+		 FROM sync_ops WHERE seq > $1 AND `+where+`
+		   AND NOT EXISTS (SELECT 1 FROM tasks t WHERE t.id = sync_ops.task_id AND NOT (`+access+`))
+		 ORDER BY seq
+		 LIMIT $3`,
+		since, arg, limit+1, accessArg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ops := []models.SyncOp{}
+	for rows.Next() {
+		var op models.SyncOp
+		var value []byte
+		if err := rows.Scan(&op.Seq, &op.ID, &op.TaskID, &op.Type, &op.Field, &value,
+			&op.Timestamp.Wall, &op.Timestamp.Counter, &op.Timestamp.Node); err != nil {
+			return nil, err
+		}
+		op.Value = value
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/ordering"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateBatch creates the tasks in one transaction, in order, so each can
+// point at a parent created before it. They go at the end of the scope's
+// manual order, in batch order.
+func (r *TaskRepository) CreateBatch(ctx context.Context, scope models.Scope, batch []models.NewTask) ([]models.Task, error) {
+	var created []models.Task
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var err error
+		created, err = insertTaskBatch(ctx, tx, scope, batch)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// insertTaskBatch is CreateBatch within tx. The tasks are new, so nothing
+// else can depend on them yet and their dependencies can't close a cycle
+// outside the batch.
+func insertTaskBatch(ctx context.Context, tx pgx.Tx, scope models.Scope, batch []models.NewTask) ([]models.Task, error) {
+	created := make([]models.Task, 0, len(batch))
+	position, err := nextTaskPosition(ctx, tx, scope)
+	if err != nil {
+		return nil, err
+	}
+	for i, n := range batch {
+		input := n.Input
+		if n.Parent >= 0 {
+			input.ParentID = &created[n.Parent].ID
+		}
+		if i > 0 {
+			if position, err = ordering.Between(position, ""); err != nil {
+				return nil, err
+			}
+		}
+		owner := n.OwnerID
+		if owner == "" {
+			owner = scope.UserID
+		}
+		t, err := insertTask(ctx, tx, scope, owner, input, position)
+		if err != nil {
+			return nil, err
+		}
+		if err := recordTaskEvent(ctx, tx, scope, models.EventTaskCreated, t); err != nil {
+			return nil, err
+		}
+		if scope.IsOrg() && len(n.LabelIDs) > 0 {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO task_labels (task_id, label_id)
+				 SELECT $1, id FROM labels WHERE id = ANY($2::uuid[]) AND org_id = $3
+				 ON CONFLICT DO NOTHING`,
+				t.ID, n.LabelIDs, scope.OrgID,
+			); err != nil {
+				return nil, err
+			}
+		}
+		created = append(created, *t)
+	}
+
+	for i, n := range batch {
+		for _, j := range n.BlockedBy {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO task_dependencies (blocker_id, blocked_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+				created[j].ID, created[i].ID,
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return created, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/ordering"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// nextTaskPosition is a position after every task in scope's list, for a
+// task that's being added to it.
+func nextTaskPosition(ctx context.Context, q querier, scope models.Scope) (string, error) {
+	where, arg := scopeClause(scope, 1)
+	var last string
+	if err := q.QueryRow(ctx, `SELECT COALESCE(MAX(position), '') FROM tasks WHERE `+where, arg).Scan(&last); err != nil {
+		return "", err
+	}
+	return ordering.Between(last, "")
+}
+
+// Reorder gives the task a position between its neighbours'. They're locked
+// until it's written, so a concurrent move of one can't leave the task on
+// the wrong side of it; if they've already swapped, it's ErrConflict.
+// ErrNotFound is for the task or either neighbour.
+func (r *TaskRepository) Reorder(ctx context.Context, scope models.Scope, id string, input models.ReorderTaskInput) (*models.Task, error) {
+	var task *models.Task
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		live, liveArg := liveTaskClause(scope, 2)
+		editable, editArg := editableTaskClause(scope, 3)
+		var canEdit bool
+		err := tx.QueryRow(ctx,
+			`SELECT `+editable+` FROM tasks WHERE id = $1 AND `+live+` FOR UPDATE`,
+			id, liveArg, editArg,
+		).Scan(&canEdit)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if !canEdit {
+			return ErrReadOnly
+		}
+
+		neighbour := func(neighbourID *string) (string, error) {
+			if neighbourID == nil {
+				return "", nil
+			}
+			var position string
+			err := tx.QueryRow(ctx,
+				`SELECT position FROM tasks WHERE id = $1 AND `+live+` FOR SHARE`,
+				*neighbourID, liveArg,
+			).Scan(&position)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return "", ErrNotFound
+			}
+			return position, err
+		}
+		after, err := neighbour(input.After)
+		if err != nil {
+			return err
+		}
+		before, err := neighbour(input.Before)
+		if err != nil {
+			return err
+		}
+
+		// With one neighbour, the other is whatever sits next to it now.
+		where, arg := scopeClause(scope, 3)
+		switch {
+		case input.Before == nil:
+			err = tx.QueryRow(ctx,
+				`SELECT COALESCE(MIN(position), '') FROM tasks WHERE position > $1 AND id <> $2 AND `+where,
+				after, id, arg,
+			).Scan(&before)
+		case input.After == nil:
+			err = tx.QueryRow(ctx,
+				`SELECT COALESCE(MAX(position), '') FROM tasks WHERE position < $1 AND id <> $2 AND `+where,
+				before, id, arg,
+			).Scan(&after)
+		}
+		if err != nil {
+			return err
+		}
+		if after != "" && before != "" && after >= before {
+			return ErrConflict
+		}
+		position, err := ordering.Between(after, before)
+		if err != nil {
+			return err
+		}
+
+		task, err = scanTask(tx.QueryRow(ctx,
+			`UPDATE tasks SET position = $2, version = version + 1, updated_at = NOW()
+			 WHERE id = $1
+			 RETURNING `+taskColumns,
+			id, position,
+		))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
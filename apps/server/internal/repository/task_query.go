@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"yata/apps/server/internal/models"
+)
+
+type sortColumn struct {
+	asc  string
+	desc string
+	cast string
+}
+
+// Sortable task columns. Nullable columns are coalesced so NULLs sort last in
+// both directions and keyset comparisons never meet a NULL.
+var taskSortColumns = map[string]sortColumn{
+	"created_at": {asc: "created_at", desc: "created_at", cast: "timestamptz"},
+	"updated_at": {asc: "updated_at", desc: "updated_at", cast: "timestamptz"},
+	"due_date":   {asc: "COALESCE(due_date, 'infinity')", desc: "COALESCE(due_date, '-infinity')", cast: "timestamptz"},
+	"priority":   {asc: "priority", desc: "priority", cast: "smallint"},
+	"title":      {asc: "title", desc: "title", cast: "text"},
+	"status":     {asc: "status", desc: "status", cast: "text"},
+	"position":   {asc: "position", desc: "position", cast: "text"},
+}
+
+// queryBuilder accumulates WHERE conditions and their positional arguments.
+type queryBuilder struct {
+	conditions []string
+	args       []any
+}
+
+func (q *queryBuilder) arg(v any) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *queryBuilder) where(cond string) {
+	q.conditions = append(q.conditions, cond)
+}
+
+func (q *queryBuilder) scope(scope models.Scope) {
+	where, arg := liveTaskClause(scope, len(q.args)+1)
+	q.args = append(q.args, arg)
+	q.where(where)
+}
+
+func (q *queryBuilder) clause() string {
+	if len(q.conditions) == 0 {
+		return "TRUE"
+	}
+	return strings.Join(q.conditions, " AND ")
+}
+
+func (q *queryBuilder) applyTaskFilter(filter models.TaskFilter) {
+	if filter.ProjectID != "" {
+		q.where("project_id = " + q.arg(filter.ProjectID))
+	}
+	if filter.LabelID != "" {
+		q.where("EXISTS (SELECT 1 FROM task_labels tl WHERE tl.task_id = tasks.id AND tl.label_id = " + q.arg(filter.LabelID) + ")")
+	}
+	if filter.OwnerID != "" {
+		q.where("owner_id = " + q.arg(filter.OwnerID))
+	}
+	if filter.Visibility != "" {
+		q.where("visibility = " + q.arg(filter.Visibility))
+	}
+	if filter.ParentID != "" {
+		q.where("parent_id = " + q.arg(filter.ParentID))
+	}
+	if filter.TopLevel {
+		q.where("parent_id IS NULL")
+	}
+	if len(filter.Statuses) > 0 {
+		q.where("status = ANY(" + q.arg(filter.Statuses) + ")")
+	}
+	if filter.OpenOnly {
+		q.where("status <> ALL(" + q.arg(filter.DoneStatuses) + ")")
+	}
+	if filter.Overdue {
+		q.where("due_date < NOW() AND status <> ALL(" + q.arg(filter.DoneStatuses) + ")")
+	}
+	if filter.DueBefore != nil {
+		q.where("due_date < " + q.arg(*filter.DueBefore))
+	}
+	if filter.DueAfter != nil {
+		q.where("due_date >= " + q.arg(*filter.DueAfter))
+	}
+	if filter.HideSnoozed {
+		q.where("(snoozed_until IS NULL OR snoozed_until <= NOW())")
+	}
+	if filter.Priority != nil {
+		q.where("priority = " + q.arg(*filter.Priority))
+	}
+	for _, f := range filter.Fields {
+		q.field(f)
+	}
+	if !filter.IncludeArchived {
+		q.where("archived_at IS NULL")
+	}
+}
+
+// field adds the condition for a custom field filter. Equality is written as
+// containment so the GIN index on custom_fields can serve it; jsonb orders
+// numbers by value and strings as text, which suits YYYY-MM-DD dates.
+func (q *queryBuilder) field(f models.FieldFilter) {
+	value := q.arg(f.Value)
+	switch {
+	case f.Type == models.CustomFieldText:
+		q.where("strpos(lower(custom_fields->>" + q.arg(f.Key) + "), lower(" + value + "::text)) > 0")
+	case f.Op == "=":
+		q.where("custom_fields @> jsonb_build_object(" + q.arg(f.Key) + "::text, " + value + "::" + fieldCast(f.Type) + ")")
+	case slices.Contains(models.FieldFilterOps, f.Op):
+		q.where("custom_fields->" + q.arg(f.Key) + " " + f.Op + " to_jsonb(" + value + "::" + fieldCast(f.Type) + ")")
+	default:
+		q.where("FALSE")
+	}
+}
+
+func fieldCast(fieldType string) string {
+	if fieldType == models.CustomFieldNumber {
+		return "numeric"
+	}
+	return "text"
+}
+
+// orderBy returns the ORDER BY list for sorts, always ending with id as a tie-breaker.
+func orderBy(columns map[string]sortColumn, sorts []models.SortField) string {
+	parts := []string{}
+	for _, s := range sorts {
+		col := columns[s.Field]
+		if s.Desc {
+			parts = append(parts, col.desc+" DESC")
+		} else {
+			parts = append(parts, col.asc+" ASC")
+		}
+	}
+	last := "id ASC"
+	if len(sorts) > 0 && sorts[len(sorts)-1].Desc {
+		last = "id DESC"
+	}
+	return strings.Join(append(parts, last), ", ")
+}
+
+// keyset adds the condition selecting rows strictly after the cursor values
+// (one per sort field, then the id), expanded as
+// (a > x) OR (a = x AND b > y) OR (a = x AND b = y AND id > z).
+func (q *queryBuilder) keyset(columns map[string]sortColumn, sorts []models.SortField, after []string) {
+	if len(after) != len(sorts)+1 {
+		return
+	}
+
+	type key struct {
+		expr string
+		op   string
+		val  string
+	}
+
+	keys := []key{}
+	for i, s := range sorts {
+		col := columns[s.Field]
+		expr, op := col.asc, ">"
+		if s.Desc {
+			expr, op = col.desc, "<"
+		}
+		keys = append(keys, key{expr: expr, op: op, val: q.arg(after[i]) + "::" + col.cast})
+	}
+
+	idOp := ">"
+	if len(sorts) > 0 && sorts[len(sorts)-1].Desc {
+		idOp = "<"
+	}
+	keys = append(keys, key{expr: "id", op: idOp, val: q.arg(after[len(sorts)]) + "::uuid"})
+
+	ors := []string{}
+	for i, k := range keys {
+		ands := []string{}
+		for _, prev := range keys[:i] {
+			ands = append(ands, prev.expr+" = "+prev.val)
+		}
+		ands = append(ands, k.expr+" "+k.op+" "+k.val)
+		ors = append(ors, "("+strings.Join(ands, " AND ")+")")
+	}
+	q.where("(" + strings.Join(ors, " OR ") + ")")
+}
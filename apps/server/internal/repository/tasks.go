@@ -0,0 +1,349 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const taskColumns = `id, owner_id, org_id, project_id, parent_id, title, description, status, priority, due_date, due_timezone, recurrence, estimate_minutes, visibility, version, archived_at, completed_at, snoozed_until, custom_fields, position, deleted_at, created_at, updated_at`
+
+type TaskRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTaskRepository(pool *pgxpool.Pool) *TaskRepository {
+	return &TaskRepository{pool: pool}
+}
+
+// taskFields returns scan destinations matching taskColumns.
+func taskFields(t *models.Task) []any {
+	return []any{&t.ID, &t.OwnerID, &t.OrgID, &t.ProjectID, &t.ParentID, &t.Title, &t.Description, &t.Status, &t.Priority, &t.DueDate, &t.DueTimezone, &t.Recurrence, &t.EstimateMinutes, &t.Visibility, &t.Version, &t.ArchivedAt, &t.CompletedAt, &t.SnoozedUntil, &t.CustomFields, &t.Position, &t.DeletedAt, &t.CreatedAt, &t.UpdatedAt}
+}
+
+func scanTask(row pgx.Row) (*models.Task, error) {
+	var t models.Task
+	err := row.Scan(taskFields(&t)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TaskRepository) Create(ctx context.Context, scope models.Scope, input models.CreateTaskInput) (*models.Task, error) {
+	// Two tasks created at once can get the same position; the id breaks
+	// the tie, and reordering either one separates them.
+	position, err := nextTaskPosition(ctx, r.pool, scope)
+	if err != nil {
+		return nil, err
+	}
+	var task *models.Task
+	err = pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		task, err = insertTask(ctx, tx, scope, scope.UserID, input, position)
+		if err != nil {
+			return err
+		}
+		return recordTaskEvent(ctx, tx, scope, models.EventTaskCreated, task)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// insertTask adds a task to scope's list, owned by ownerID.
+func insertTask(ctx context.Context, q querier, scope models.Scope, ownerID string, input models.CreateTaskInput, position string) (*models.Task, error) {
+	status := input.Status
+	if status == "" {
+		status = models.TaskStatusTodo
+	}
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = models.TaskVisibilityOrg
+	}
+	fields := input.CustomFields
+	if fields == nil {
+		fields = map[string]any{}
+	}
+
+	row := q.QueryRow(ctx,
+		`INSERT INTO tasks (owner_id, org_id, project_id, parent_id, title, description, status, priority, due_date, due_timezone, recurrence, estimate_minutes, visibility, position, custom_fields)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		 RETURNING `+taskColumns,
+		ownerID, scope.OrgIDPtr(), input.ProjectID, input.ParentID, input.Title, input.Description, status, input.Priority, input.DueDate, input.DueTimezone, input.Recurrence, input.EstimateMinutes, visibility, position, fields,
+	)
+	return scanTask(row)
+}
+
+func (r *TaskRepository) Get(ctx context.Context, scope models.Scope, id string) (*models.Task, error) {
+	where, arg := liveTaskClause(scope, 2)
+	row := r.pool.QueryRow(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE id = $1 AND `+where,
+		id, arg,
+	)
+	return scanTask(row)
+}
+
+func (r *TaskRepository) Visible(ctx context.Context, scope models.Scope, ids []string) ([]string, error) {
+	where, arg := taskAccessClause("tasks", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx, `SELECT id FROM tasks WHERE id = ANY($1) AND `+where, ids, arg)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// Subtree returns every descendant of the task, parents before children.
+func (r *TaskRepository) Subtree(ctx context.Context, scope models.Scope, id string) ([]models.Task, error) {
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`WITH RECURSIVE subtree AS (
+			SELECT t.*, 1 AS depth FROM tasks t WHERE t.parent_id = $1 AND `+where+`
+			UNION ALL
+			SELECT t.*, s.depth + 1 FROM tasks t JOIN subtree s ON t.parent_id = s.id
+			WHERE s.depth < 50 AND `+where+`
+		)
+		SELECT `+taskColumns+` FROM subtree ORDER BY depth, created_at, position, id`,
+		id, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+// Progress counts all descendants of the task and how many are in one of
+// doneStatuses.
+func (r *TaskRepository) Progress(ctx context.Context, scope models.Scope, id string, doneStatuses []string) (models.TaskProgress, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	var total, done int
+	err := r.pool.QueryRow(ctx,
+		`WITH RECURSIVE subtree AS (
+			SELECT t.id, t.status, 1 AS depth FROM tasks t WHERE t.parent_id = $1 AND `+where+`
+			UNION ALL
+			SELECT t.id, t.status, s.depth + 1 FROM tasks t JOIN subtree s ON t.parent_id = s.id
+			WHERE s.depth < 50 AND `+where+`
+		)
+		SELECT count(*), count(*) FILTER (WHERE status = ANY($2)) FROM subtree`,
+		id, doneStatuses, arg,
+	).Scan(&total, &done)
+	if err != nil {
+		return models.TaskProgress{}, err
+	}
+	return models.NewTaskProgress(total, done), nil
+}
+
+// SubtaskEstimate walks down only through subtasks without an estimate of
+// their own, since those with one count it instead of their subtasks'.
+func (r *TaskRepository) SubtaskEstimate(ctx context.Context, scope models.Scope, id string) (*int, error) {
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	var total *int
+	err := r.pool.QueryRow(ctx,
+		`WITH RECURSIVE rolled AS (
+			SELECT t.id, t.estimate_minutes, 1 AS depth FROM tasks t WHERE t.parent_id = $1 AND `+where+`
+			UNION ALL
+			SELECT t.id, t.estimate_minutes, r.depth + 1 FROM tasks t JOIN rolled r ON t.parent_id = r.id
+			WHERE r.estimate_minutes IS NULL AND r.depth < 50 AND `+where+`
+		)
+		SELECT SUM(estimate_minutes) FROM rolled`,
+		id, arg,
+	).Scan(&total)
+	return total, err
+}
+
+// List returns up to page.Limit+1 tasks in filter.Sort order, so callers can
+// tell whether another page exists. page.After holds one value per sort field
+// followed by the id.
+func (r *TaskRepository) List(ctx context.Context, scope models.Scope, filter models.TaskFilter, page models.Page) ([]models.Task, error) {
+	q := &queryBuilder{}
+	q.scope(scope)
+	q.applyTaskFilter(filter)
+	q.keyset(taskSortColumns, filter.Sort, page.After)
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+taskColumns+` FROM tasks WHERE `+q.clause()+`
+		 ORDER BY `+orderBy(taskSortColumns, filter.Sort)+`
+		 LIMIT `+q.arg(page.Limit+1),
+		q.args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+func (r *TaskRepository) Update(ctx context.Context, scope models.Scope, id string, input models.UpdateTaskInput) (*models.Task, error) {
+	sets := []string{}
+	args := []any{id}
+
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if input.Title != nil {
+		set("title", *input.Title)
+	}
+	if input.Description != nil {
+		set("description", *input.Description)
+	}
+	if input.Status != nil {
+		set("status", *input.Status)
+	}
+	if input.Priority != nil {
+		set("priority", *input.Priority)
+	}
+	if input.DueDate.Set {
+		set("due_date", input.DueDate.Ptr())
+	}
+	if input.DueTimezone.Set {
+		set("due_timezone", input.DueTimezone.Ptr())
+	}
+	if input.Recurrence.Set {
+		set("recurrence", input.Recurrence.Ptr())
+	}
+	if input.ProjectID.Set {
+		set("project_id", input.ProjectID.Ptr())
+	}
+	if input.Visibility != nil {
+		set("visibility", *input.Visibility)
+	}
+	if input.EstimateMinutes.Set {
+		set("estimate_minutes", input.EstimateMinutes.Ptr())
+	}
+	if len(input.CustomFields) > 0 {
+		// Stored values are never null, so stripping nulls only drops the
+		// fields being cleared.
+		args = append(args, input.CustomFields)
+		sets = append(sets, fmt.Sprintf("custom_fields = jsonb_strip_nulls(custom_fields || $%d::jsonb)", len(args)))
+	}
+
+	if len(sets) == 0 {
+		t, err := r.Get(ctx, scope, id)
+		if err == nil && input.IfVersion != nil && t.Version != *input.IfVersion {
+			return nil, ErrVersionMismatch
+		}
+		return t, err
+	}
+
+	where, arg := editableTaskClause(scope, len(args)+1)
+	args = append(args, arg, input.IfVersion)
+
+	// Only a status change can complete the task, and then the row is read
+	// first to tell a completion from a move between done statuses.
+	var t *models.Task
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		wasCompleted := true
+		if input.Status != nil {
+			err := tx.QueryRow(ctx, `SELECT completed_at IS NOT NULL FROM tasks WHERE id = $1 FOR UPDATE`, id).Scan(&wasCompleted)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrNotFound
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		var err error
+		t, err = scanTask(tx.QueryRow(ctx,
+			`UPDATE tasks SET `+strings.Join(sets, ", ")+`, version = version + 1, updated_at = NOW()
+			 WHERE id = $1 AND `+where+fmt.Sprintf(` AND ($%d::int IS NULL OR version = $%d)`, len(args), len(args))+`
+			 RETURNING `+taskColumns,
+			args...,
+		))
+		if err != nil || wasCompleted || t.CompletedAt == nil {
+			return err
+		}
+		return recordTaskEvent(ctx, tx, scope, models.EventTaskCompleted, t)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return nil, r.writeMiss(ctx, scope, id, input.IfVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Delete moves the task and its live subtasks to the trash. They share one
+// deleted_at, which is how RestoreTask knows what to bring back together.
+func (r *TaskRepository) Delete(ctx context.Context, scope models.Scope, id string, ifVersion *int) error {
+	where, arg := editableTaskClause(scope, 2)
+	var deletedAt time.Time
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx,
+			`UPDATE tasks SET deleted_at = NOW(), version = version + 1, updated_at = NOW()
+			 WHERE id = $1 AND `+where+` AND ($3::int IS NULL OR version = $3)
+			 RETURNING deleted_at`,
+			id, arg, ifVersion,
+		).Scan(&deletedAt)
+		if err != nil {
+			return err
+		}
+		if err := trashSubtasks(ctx, tx, []string{id}, deletedAt); err != nil {
+			return err
+		}
+		return recordEvent(ctx, tx, scope, models.EventTaskDeleted, id, map[string]any{"taskId": id})
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return r.writeMiss(ctx, scope, id, ifVersion)
+	}
+	return err
+}
+
+// trashSubtasks stamps every live descendant of ids with deletedAt.
+func trashSubtasks(ctx context.Context, tx pgx.Tx, ids []string, deletedAt time.Time) error {
+	_, err := tx.Exec(ctx,
+		`WITH RECURSIVE subtree AS (
+			SELECT t.id, 1 AS depth FROM tasks t WHERE t.parent_id = ANY($1) AND t.deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, s.depth + 1 FROM tasks t JOIN subtree s ON t.parent_id = s.id
+			WHERE s.depth < 50 AND t.deleted_at IS NULL
+		)
+		UPDATE tasks SET deleted_at = $2, updated_at = NOW() WHERE id IN (SELECT id FROM subtree)`,
+		ids, deletedAt,
+	)
+	return err
+}
+
+// writeMiss tells apart the reasons a write can match no rows: the task is
+// gone or hidden, the caller may only view it, or its version moved on.
+func (r *TaskRepository) writeMiss(ctx context.Context, scope models.Scope, id string, ifVersion *int) error {
+	t, err := r.Get(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+	if ifVersion != nil && t.Version != *ifVersion {
+		return ErrVersionMismatch
+	}
+	return ErrReadOnly
+}
@@ -0,0 +1,114 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const telegramLinkColumns = `user_id, org_id, username, created_at, chat_id`
+
+type TelegramRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTelegramRepository(pool *pgxpool.Pool) *TelegramRepository {
+	return &TelegramRepository{pool: pool}
+}
+
+func scanTelegramLink(row pgx.Row) (*models.TelegramLink, error) {
+	var l models.TelegramLink
+	err := row.Scan(&l.UserID, &l.OrgID, &l.Username, &l.CreatedAt, &l.ChatID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+func (r *TelegramRepository) Get(ctx context.Context, userID string) (*models.TelegramLink, error) {
+	return scanTelegramLink(r.pool.QueryRow(ctx,
+		`SELECT `+telegramLinkColumns+` FROM telegram_links WHERE user_id = $1`,
+		userID,
+	))
+}
+
+func (r *TelegramRepository) CreateLinkCode(ctx context.Context, scope models.Scope, codeHash []byte, expiresAt time.Time) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM telegram_link_codes WHERE user_id = $1`, scope.UserID); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO telegram_link_codes (code_hash, user_id, org_id, expires_at) VALUES ($1, $2, $3, $4)`,
+			codeHash, scope.UserID, scope.OrgIDPtr(), expiresAt,
+		)
+		return err
+	})
+}
+
+func (r *TelegramRepository) Link(ctx context.Context, codeHash []byte, chatID int64, username *string) (*models.TelegramLink, error) {
+	var link *models.TelegramLink
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var userID string
+		var orgID *string
+		err := tx.QueryRow(ctx,
+			`DELETE FROM telegram_link_codes WHERE code_hash = $1 AND expires_at > NOW()
+			 RETURNING user_id, org_id`,
+			codeHash,
+		).Scan(&userID, &orgID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM telegram_links WHERE chat_id = $1 AND user_id <> $2`, chatID, userID); err != nil {
+			return err
+		}
+		link, err = scanTelegramLink(tx.QueryRow(ctx,
+			`INSERT INTO telegram_links (user_id, org_id, chat_id, username) VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (user_id) DO UPDATE
+			 SET org_id = EXCLUDED.org_id, chat_id = EXCLUDED.chat_id, username = EXCLUDED.username, created_at = NOW()
+			 RETURNING `+telegramLinkColumns,
+			userID, orgID, chatID, username,
+		))
+		return err
+	})
+	return link, err
+}
+
+func (r *TelegramRepository) Delete(ctx context.Context, userID string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM telegram_links WHERE user_id = $1`, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *TelegramRepository) GetByChat(ctx context.Context, chatID int64) (*models.TelegramLink, error) {
+	return scanTelegramLink(r.pool.QueryRow(ctx,
+		`SELECT `+telegramLinkColumns+` FROM telegram_links WHERE chat_id = $1`,
+		chatID,
+	))
+}
+
+func (r *TelegramRepository) DeleteByChat(ctx context.Context, chatID int64) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM telegram_links WHERE chat_id = $1`, chatID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const templateColumns = `id, owner_id, org_id, name, task, created_at, updated_at`
+
+type TemplateRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTemplateRepository(pool *pgxpool.Pool) *TemplateRepository {
+	return &TemplateRepository{pool: pool}
+}
+
+func scanTemplate(row pgx.Row) (*models.TaskTemplate, error) {
+	var t models.TaskTemplate
+	err := row.Scan(&t.ID, &t.OwnerID, &t.OrgID, &t.Name, &t.Task, &t.CreatedAt, &t.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *TemplateRepository) Create(ctx context.Context, scope models.Scope, name string, task models.TemplateTask) (*models.TaskTemplate, error) {
+	return scanTemplate(r.pool.QueryRow(ctx,
+		`INSERT INTO task_templates (owner_id, org_id, name, task)
+		 VALUES ($1, $2, $3, $4)
+		 RETURNING `+templateColumns,
+		scope.UserID, scope.OrgIDPtr(), name, task,
+	))
+}
+
+func (r *TemplateRepository) Get(ctx context.Context, scope models.Scope, id string) (*models.TaskTemplate, error) {
+	where, arg := scopeClause(scope, 2)
+	return scanTemplate(r.pool.QueryRow(ctx,
+		`SELECT `+templateColumns+` FROM task_templates WHERE id = $1 AND `+where,
+		id, arg,
+	))
+}
+
+func (r *TemplateRepository) List(ctx context.Context, scope models.Scope) ([]models.TaskTemplate, error) {
+	where, arg := scopeClause(scope, 1)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+templateColumns+` FROM task_templates WHERE `+where+` ORDER BY name, id`,
+		arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := []models.TaskTemplate{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, *t)
+	}
+	return templates, rows.Err()
+}
+
+func (r *TemplateRepository) Update(ctx context.Context, scope models.Scope, id string, input models.UpdateTemplateInput) (*models.TaskTemplate, error) {
+	where, args := ownerClause(scope, 4)
+	t, err := scanTemplate(r.pool.QueryRow(ctx,
+		`UPDATE task_templates SET name = COALESCE($2, name), task = COALESCE($3, task), updated_at = NOW()
+		 WHERE id = $1 AND `+where+`
+		 RETURNING `+templateColumns,
+		append([]any{id, input.Name, input.Task}, args...)...,
+	))
+	if errors.Is(err, ErrNotFound) {
+		return nil, r.writeMiss(ctx, scope, id)
+	}
+	return t, err
+}
+
+func (r *TemplateRepository) Delete(ctx context.Context, scope models.Scope, id string) error {
+	where, args := ownerClause(scope, 2)
+	tag, err := r.pool.Exec(ctx, `DELETE FROM task_templates WHERE id = $1 AND `+where, append([]any{id}, args...)...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.writeMiss(ctx, scope, id)
+	}
+	return nil
+}
+
+// writeMiss tells a template the scope can't see from one it can see but
+// not change.
+func (r *TemplateRepository) writeMiss(ctx context.Context, scope models.Scope, id string) error {
+	if _, err := r.Get(ctx, scope, id); err != nil {
+		return err
+	}
+	return ErrReadOnly
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const timeEntryColumns = `id, task_id, user_id, started_at, ended_at, note, created_at, updated_at`
+
+// timeEntrySeconds sums the entries of alias e, counting running timers up
+// to now.
+const timeEntrySeconds = `COALESCE(SUM(EXTRACT(EPOCH FROM COALESCE(e.ended_at, NOW()) - e.started_at)), 0)::bigint`
+
+type TimeEntryRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTimeEntryRepository(pool *pgxpool.Pool) *TimeEntryRepository {
+	return &TimeEntryRepository{pool: pool}
+}
+
+func scanTimeEntry(row pgx.Row) (*models.TimeEntry, error) {
+	var e models.TimeEntry
+	err := row.Scan(&e.ID, &e.TaskID, &e.UserID, &e.StartedAt, &e.EndedAt, &e.Note, &e.CreatedAt, &e.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	e.SetSeconds(time.Now())
+	return &e, nil
+}
+
+// Start stops the user's running timer, on whichever task it is, and starts
+// one on the task, which has to be live and in scope.
+func (r *TimeEntryRepository) Start(ctx context.Context, scope models.Scope, taskID string, input models.StartTimerInput) (*models.TimeEntry, error) {
+	where, arg := liveTaskClause(scope, 4)
+	var entry *models.TimeEntry
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			`UPDATE time_entries SET ended_at = NOW(), updated_at = NOW() WHERE user_id = $1 AND ended_at IS NULL`,
+			scope.UserID,
+		); err != nil {
+			return err
+		}
+		var err error
+		entry, err = scanTimeEntry(tx.QueryRow(ctx,
+			`INSERT INTO time_entries (task_id, user_id, started_at, note)
+			 SELECT id, $2, NOW(), $3 FROM tasks WHERE id = $1 AND `+where+`
+			 RETURNING `+timeEntryColumns,
+			taskID, scope.UserID, input.Note, arg,
+		))
+		return err
+	})
+	// Another start for the same user got in between.
+	if isUniqueViolation(err) {
+		return nil, ErrConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// Stop stops the user's running timer on the task.
+func (r *TimeEntryRepository) Stop(ctx context.Context, scope models.Scope, taskID string) (*models.TimeEntry, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	return scanTimeEntry(r.pool.QueryRow(ctx,
+		`UPDATE time_entries e SET ended_at = NOW(), updated_at = NOW()
+		 FROM tasks t
+		 WHERE e.task_id = $1 AND e.user_id = $2 AND e.ended_at IS NULL AND t.id = e.task_id AND `+where+`
+		 RETURNING `+qualifiedColumns("e", timeEntryColumns),
+		taskID, scope.UserID, arg,
+	))
+}
+
+// Running returns the user's running timer in any scope.
+func (r *TimeEntryRepository) Running(ctx context.Context, userID string) (*models.TimeEntry, error) {
+	return scanTimeEntry(r.pool.QueryRow(ctx,
+		`SELECT `+qualifiedColumns("e", timeEntryColumns)+`
+		 FROM time_entries e JOIN tasks t ON t.id = e.task_id
+		 WHERE e.user_id = $1 AND e.ended_at IS NULL AND t.deleted_at IS NULL`,
+		userID,
+	))
+}
+
+// Create logs finished time on the task, which has to be live and in scope.
+func (r *TimeEntryRepository) Create(ctx context.Context, scope models.Scope, taskID string, input models.CreateTimeEntryInput) (*models.TimeEntry, error) {
+	where, arg := liveTaskClause(scope, 6)
+	return scanTimeEntry(r.pool.QueryRow(ctx,
+		`INSERT INTO time_entries (task_id, user_id, started_at, ended_at, note)
+		 SELECT id, $2, $3, $4, $5 FROM tasks WHERE id = $1 AND `+where+`
+		 RETURNING `+timeEntryColumns,
+		taskID, scope.UserID, input.StartedAt, input.EndedAt, input.Note, arg,
+	))
+}
+
+func (r *TimeEntryRepository) Get(ctx context.Context, scope models.Scope, taskID, id string) (*models.TimeEntry, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	return scanTimeEntry(r.pool.QueryRow(ctx,
+		`SELECT `+qualifiedColumns("e", timeEntryColumns)+`
+		 FROM time_entries e JOIN tasks t ON t.id = e.task_id
+		 WHERE e.id = $1 AND e.task_id = $2 AND `+where,
+		id, taskID, arg,
+	))
+}
+
+func (r *TimeEntryRepository) Update(ctx context.Context, scope models.Scope, taskID, id string, input models.UpdateTimeEntryInput) (*models.TimeEntry, error) {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	return scanTimeEntry(r.pool.QueryRow(ctx,
+		`UPDATE time_entries e SET
+		   started_at = COALESCE($4, e.started_at),
+		   ended_at = COALESCE($5, e.ended_at),
+		   note = COALESCE($6, e.note),
+		   updated_at = NOW()
+		 FROM tasks t
+		 WHERE e.id = $1 AND e.task_id = $2 AND t.id = e.task_id AND `+where+`
+		 RETURNING `+qualifiedColumns("e", timeEntryColumns),
+		id, taskID, arg, input.StartedAt, input.EndedAt, input.Note,
+	))
+}
+
+func (r *TimeEntryRepository) Delete(ctx context.Context, scope models.Scope, taskID, id string) error {
+	where, arg := liveTaskAccess("t", scope, 3, models.ShareRoleViewer)
+	tag, err := r.pool.Exec(ctx,
+		`DELETE FROM time_entries e USING tasks t
+		 WHERE e.id = $1 AND e.task_id = $2 AND t.id = e.task_id AND `+where,
+		id, taskID, arg,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// List returns up to page.Limit+1 of the task's entries, newest first.
+func (r *TimeEntryRepository) List(ctx context.Context, scope models.Scope, taskID string, page models.Page) ([]models.TimeEntry, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+qualifiedColumns("e", timeEntryColumns)+`
+		 FROM time_entries e JOIN tasks t ON t.id = e.task_id
+		 WHERE e.task_id = $1 AND `+where+`
+		   AND ($3::timestamptz IS NULL OR (e.started_at, e.id) < ($3, $4::uuid))
+		 ORDER BY e.started_at DESC, e.id DESC
+		 LIMIT $5`,
+		taskID, arg, afterTime, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.TimeEntry{}
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, *e)
+	}
+	return entries, rows.Err()
+}
+
+// TaskTotals sums the task's entries by user. It returns ErrNotFound unless
+// the task is live and in scope.
+func (r *TimeEntryRepository) TaskTotals(ctx context.Context, scope models.Scope, taskID string) (*models.TimeTotals, error) {
+	where, arg := liveTaskClause(scope, 2)
+	var exists bool
+	if err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND `+where+`)`,
+		taskID, arg,
+	).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	totals := &models.TimeTotals{ByUser: []models.TimeTotal{}}
+	rows, err := r.pool.Query(ctx,
+		`SELECT e.user_id, `+timeEntrySeconds+` AS seconds
+		 FROM time_entries e WHERE e.task_id = $1
+		 GROUP BY e.user_id ORDER BY seconds DESC, e.user_id`,
+		taskID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t models.TimeTotal
+		if err := rows.Scan(&t.ID, &t.Seconds); err != nil {
+			return nil, err
+		}
+		totals.ByUser = append(totals.ByUser, t)
+		totals.Seconds += t.Seconds
+	}
+	return totals, rows.Err()
+}
+
+// ProjectTotals sums the entries on the project's live tasks that the scope
+// can see, by task and by user.
+func (r *TimeEntryRepository) ProjectTotals(ctx context.Context, scope models.Scope, projectID string) (*models.TimeTotals, error) {
+	where, arg := liveTaskAccess("t", scope, 2, models.ShareRoleViewer)
+	totals := &models.TimeTotals{ByUser: []models.TimeTotal{}, ByTask: []models.TimeTotal{}}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT t.id, t.title, `+timeEntrySeconds+` AS seconds
+		 FROM time_entries e JOIN tasks t ON t.id = e.task_id
+		 WHERE t.project_id = $1 AND `+where+`
+		 GROUP BY t.id, t.title ORDER BY seconds DESC, t.id`,
+		projectID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t models.TimeTotal
+		if err := rows.Scan(&t.ID, &t.Title, &t.Seconds); err != nil {
+			return nil, err
+		}
+		totals.ByTask = append(totals.ByTask, t)
+		totals.Seconds += t.Seconds
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rows, err = r.pool.Query(ctx,
+		`SELECT e.user_id, `+timeEntrySeconds+` AS seconds
+		 FROM time_entries e JOIN tasks t ON t.id = e.task_id
+		 WHERE t.project_id = $1 AND `+where+`
+		 GROUP BY e.user_id ORDER BY seconds DESC, e.user_id`,
+		projectID, arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t models.TimeTotal
+		if err := rows.Scan(&t.ID, &t.Seconds); err != nil {
+			return nil, err
+		}
+		totals.ByUser = append(totals.ByUser, t)
+	}
+	return totals, rows.Err()
+}
+
+// Export returns up to page.Limit+1 finished entries on tasks the scope can
+// see, oldest first. Trashed tasks stay in, since the time was still spent.
+func (r *TimeEntryRepository) Export(ctx context.Context, scope models.Scope, filter models.TimeEntryFilter, page models.Page) ([]models.ExportedTimeEntry, error) {
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	where, arg := taskAccessClause("t", scope, 1, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+qualifiedColumns("e", timeEntryColumns)+`, t.title, t.project_id
+		 FROM time_entries e JOIN tasks t ON t.id = e.task_id
+		 WHERE `+where+` AND e.ended_at IS NOT NULL
+		   AND e.started_at >= $2 AND e.started_at < $3
+		   AND ($4::uuid IS NULL OR t.project_id = $4)
+		   AND ($5::text IS NULL OR e.user_id = $5)
+		   AND ($6::timestamptz IS NULL OR (e.started_at, e.id) > ($6, $7::uuid))
+		 ORDER BY e.started_at, e.id
+		 LIMIT $8`,
+		arg, filter.From, filter.To, filter.ProjectID, filter.UserID, afterTime, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []models.ExportedTimeEntry{}
+	for rows.Next() {
+		var e models.ExportedTimeEntry
+		if err := rows.Scan(&e.ID, &e.TaskID, &e.UserID, &e.StartedAt, &e.EndedAt, &e.Note, &e.CreatedAt, &e.UpdatedAt, &e.TaskTitle, &e.ProjectID); err != nil {
+			return nil, err
+		}
+		e.SetSeconds(time.Now())
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
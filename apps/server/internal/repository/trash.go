@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type TrashRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewTrashRepository(pool *pgxpool.Pool) *TrashRepository {
+	return &TrashRepository{pool: pool}
+}
+
+// restoreSubtree clears deleted_at on the roots and every descendant that
+// was trashed along with them.
+const restoreSubtree = `WITH RECURSIVE subtree AS (
+	SELECT t.id, 1 AS depth FROM tasks t WHERE t.id = ANY($1) AND t.deleted_at = $2
+	UNION ALL
+	SELECT t.id, s.depth + 1 FROM tasks t JOIN subtree s ON t.parent_id = s.id
+	WHERE s.depth < 50 AND t.deleted_at = $2
+)
+UPDATE tasks SET deleted_at = NULL, version = version + 1, updated_at = NOW()
+WHERE id IN (SELECT id FROM subtree)`
+
+// ListTasks returns trashed tasks in scope, leaving out subtasks that went to
+// the trash with their parent; restoring the parent brings those back.
+func (r *TrashRepository) ListTasks(ctx context.Context, scope models.Scope) ([]models.Task, error) {
+	where, arg := taskAccessClause("t", scope, 1, models.ShareRoleViewer)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+taskColumns+` FROM tasks t
+		 WHERE t.deleted_at IS NOT NULL AND `+where+`
+		   AND NOT EXISTS (
+			SELECT 1 FROM tasks p WHERE p.id = t.parent_id AND p.deleted_at = t.deleted_at
+		   )
+		   AND NOT EXISTS (
+			SELECT 1 FROM projects pr WHERE pr.id = t.project_id AND pr.deleted_at = t.deleted_at
+		   )
+		 ORDER BY t.deleted_at DESC, t.id`,
+		arg,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tasks := []models.Task{}
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, *t)
+	}
+	return tasks, rows.Err()
+}
+
+func (r *TrashRepository) ListProjects(ctx context.Context, orgID string) ([]models.Project, error) {
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+projectColumns+` FROM projects
+		 WHERE org_id = $1 AND deleted_at IS NOT NULL
+		 ORDER BY deleted_at DESC, id`,
+		orgID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []models.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, *p)
+	}
+	return projects, rows.Err()
+}
+
+// RestoreTask brings a trashed task and the subtasks trashed with it back.
+// It fails with ErrConflict while the task's parent or project is still in
+// the trash.
+func (r *TrashRepository) RestoreTask(ctx context.Context, scope models.Scope, id string) (*models.Task, error) {
+	where, arg := taskAccessClause("t", scope, 2, models.ShareRoleEditor)
+	var task *models.Task
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var deletedAt time.Time
+		var blocked bool
+		err := tx.QueryRow(ctx,
+			`SELECT t.deleted_at,
+			        EXISTS (SELECT 1 FROM tasks p WHERE p.id = t.parent_id AND p.deleted_at IS NOT NULL)
+			     OR EXISTS (SELECT 1 FROM projects pr WHERE pr.id = t.project_id AND pr.deleted_at IS NOT NULL)
+			 FROM tasks t
+			 WHERE t.id = $1 AND t.deleted_at IS NOT NULL AND `+where+`
+			 FOR UPDATE OF t`,
+			id, arg,
+		).Scan(&deletedAt, &blocked)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if blocked {
+			return ErrConflict
+		}
+
+		if _, err := tx.Exec(ctx, restoreSubtree, []string{id}, deletedAt); err != nil {
+			return err
+		}
+		task, err = scanTask(tx.QueryRow(ctx, `SELECT `+taskColumns+` FROM tasks WHERE id = $1`, id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// DeleteProject moves the project, its tasks and their subtasks to the trash.
+func (r *TrashRepository) DeleteProject(ctx context.Context, orgID, id string) error {
+	return pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var deletedAt time.Time
+		err := tx.QueryRow(ctx,
+			`UPDATE projects SET deleted_at = NOW(), updated_at = NOW()
+			 WHERE id = $1 AND org_id = $2 AND deleted_at IS NULL
+			 RETURNING deleted_at`,
+			id, orgID,
+		).Scan(&deletedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx,
+			`UPDATE tasks SET deleted_at = $2, version = version + 1, updated_at = NOW()
+			 WHERE project_id = $1 AND deleted_at IS NULL
+			 RETURNING id`,
+			id, deletedAt,
+		)
+		if err != nil {
+			return err
+		}
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+		return trashSubtasks(ctx, tx, ids, deletedAt)
+	})
+}
+
+// RestoreProject brings the project back with everything trashed along
+// with it.
+func (r *TrashRepository) RestoreProject(ctx context.Context, orgID, id string) (*models.Project, error) {
+	var project *models.Project
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var deletedAt time.Time
+		err := tx.QueryRow(ctx,
+			`SELECT deleted_at FROM projects WHERE id = $1 AND org_id = $2 AND deleted_at IS NOT NULL FOR UPDATE`,
+			id, orgID,
+		).Scan(&deletedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.Query(ctx, `SELECT id FROM tasks WHERE project_id = $1 AND deleted_at = $2`, id, deletedAt)
+		if err != nil {
+			return err
+		}
+		ids, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(ctx, restoreSubtree, ids, deletedAt); err != nil {
+			return err
+		}
+
+		project, err = scanProject(tx.QueryRow(ctx,
+			`UPDATE projects SET deleted_at = NULL, updated_at = NOW() WHERE id = $1
+			 RETURNING `+projectColumns,
+			id,
+		))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// Purge permanently deletes everything trashed before cutoff and returns
+// how many tasks and projects went.
+// purgeCutoff is the org's own retention for rows of table, falling back to
+// the cutoff bound as $1.
+func purgeCutoff(table string) string {
+	return `COALESCE((
+		SELECT NOW() - make_interval(days => s.trash_retention_days) FROM org_settings s
+		WHERE s.org_id = ` + table + `.org_id AND s.trash_retention_days IS NOT NULL
+	), $1)`
+}
+
+func (r *TrashRepository) Purge(ctx context.Context, cutoff time.Time) (int, error) {
+	var purged int64
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, `DELETE FROM tasks t WHERE t.deleted_at < `+purgeCutoff("t"), cutoff)
+		if err != nil {
+			return err
+		}
+		purged = tag.RowsAffected()
+
+		tag, err = tx.Exec(ctx, `DELETE FROM projects p WHERE p.deleted_at < `+purgeCutoff("p"), cutoff)
+		if err != nil {
+			return err
+		}
+		purged += tag.RowsAffected()
+		return nil
+	})
+	return int(purged), err
+}
@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UsageRepository reads org_usage, which triggers keep up to date.
+type UsageRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUsageRepository(pool *pgxpool.Pool) *UsageRepository {
+	return &UsageRepository{pool: pool}
+}
+
+func (r *UsageRepository) Get(ctx context.Context, orgID string) (*models.Usage, error) {
+	var u models.Usage
+	err := r.pool.QueryRow(ctx,
+		`SELECT tasks, attachment_bytes, webhook_endpoints FROM org_usage WHERE org_id = $1`,
+		orgID,
+	).Scan(&u.Tasks, &u.AttachmentBytes, &u.WebhookEndpoints)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Orgs that have never written anything have no row yet.
+		return &models.Usage{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
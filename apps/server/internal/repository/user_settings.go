@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type UserSettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserSettingsRepository(pool *pgxpool.Pool) *UserSettingsRepository {
+	return &UserSettingsRepository{pool: pool}
+}
+
+const userSettingsColumns = `timezone, locale, week_start, default_project_id, email_enabled, push_enabled, telegram_enabled, digest_frequency, digest_time, updated_at`
+
+// settingsFields are the scan targets for userSettingsColumns.
+func settingsFields(s *models.UserSettings) []any {
+	return []any{
+		&s.Timezone, &s.Locale, &s.WeekStart, &s.DefaultProjectID,
+		&s.NotificationChannels.Email, &s.NotificationChannels.Push, &s.NotificationChannels.Telegram,
+		&s.DigestFrequency, &s.DigestTime, &s.UpdatedAt,
+	}
+}
+
+// getSettings returns the defaults for users who never changed anything.
+func getSettings(ctx context.Context, q querier, userID string, lock bool) (models.UserSettings, error) {
+	query := `SELECT ` + userSettingsColumns + ` FROM user_settings WHERE user_id = $1`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	s := models.DefaultUserSettings(userID)
+	err := q.QueryRow(ctx, query, userID).Scan(settingsFields(&s)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return s, nil
+	}
+	return s, err
+}
+
+func (r *UserSettingsRepository) Get(ctx context.Context, userID string) (models.UserSettings, error) {
+	return getSettings(ctx, r.pool, userID, false)
+}
+
+func (r *UserSettingsRepository) Update(ctx context.Context, userID string, input models.UpdateUserSettingsInput) (models.UserSettings, error) {
+	var s models.UserSettings
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		current, err := getSettings(ctx, tx, userID, true)
+		if err != nil {
+			return err
+		}
+		s = input.Apply(current)
+
+		return tx.QueryRow(ctx,
+			`INSERT INTO user_settings (user_id, timezone, locale, week_start, default_project_id, email_enabled, push_enabled, telegram_enabled, digest_frequency, digest_time)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			 ON CONFLICT (user_id) DO UPDATE SET
+				timezone = EXCLUDED.timezone,
+				locale = EXCLUDED.locale,
+				week_start = EXCLUDED.week_start,
+				default_project_id = EXCLUDED.default_project_id,
+				email_enabled = EXCLUDED.email_enabled,
+				push_enabled = EXCLUDED.push_enabled,
+				telegram_enabled = EXCLUDED.telegram_enabled,
+				digest_frequency = EXCLUDED.digest_frequency,
+				digest_time = EXCLUDED.digest_time,
+				updated_at = NOW()
+			 RETURNING updated_at`,
+			userID, s.Timezone, s.Locale, s.WeekStart, s.DefaultProjectID,
+			s.NotificationChannels.Email, s.NotificationChannels.Push, s.NotificationChannels.Telegram, s.DigestFrequency, s.DigestTime,
+		).Scan(&s.UpdatedAt)
+	})
+	return s, err
+}
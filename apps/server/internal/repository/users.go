@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UserRepository mirrors Clerk's users, organizations and memberships. Every
+// write ignores data older than what's already stored.
+type UserRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
+	return &UserRepository{pool: pool}
+}
+
+func (r *UserRepository) UpsertUser(ctx context.Context, u models.User) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO users (id, email, first_name, last_name, username, image_url, updated_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)
+		 ON CONFLICT (id) DO UPDATE SET
+			email = EXCLUDED.email,
+			first_name = EXCLUDED.first_name,
+			last_name = EXCLUDED.last_name,
+			username = EXCLUDED.username,
+			image_url = EXCLUDED.image_url,
+			updated_at = EXCLUDED.updated_at
+		 WHERE users.updated_at <= EXCLUDED.updated_at AND users.deleted_at IS NULL`,
+		u.ID, u.Email, u.FirstName, u.LastName, u.Username, u.ImageURL, u.UpdatedAt,
+	)
+	return err
+}
+
+// DeleteUser tombstones the user and drops their memberships. Deleting a
+// user we never heard of still leaves a tombstone behind.
+func (r *UserRepository) DeleteUser(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx,
+		`WITH gone AS (DELETE FROM org_memberships WHERE user_id = $1)
+		 INSERT INTO users (id, updated_at, deleted_at) VALUES ($1, NOW(), NOW())
+		 ON CONFLICT (id) DO UPDATE SET deleted_at = COALESCE(users.deleted_at, NOW())`,
+		id,
+	)
+	return err
+}
+
+func (r *UserRepository) UpsertOrganization(ctx context.Context, o models.Organization) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO organizations (id, name, slug, image_url, status, updated_at)
+		 VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), 'active'), $6)
+		 ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			slug = EXCLUDED.slug,
+			image_url = EXCLUDED.image_url,
+			status = COALESCE(NULLIF($5, ''), organizations.status),
+			updated_at = EXCLUDED.updated_at
+		 WHERE organizations.updated_at <= EXCLUDED.updated_at AND organizations.deleted_at IS NULL`,
+		o.ID, o.Name, o.Slug, o.ImageURL, o.Status, o.UpdatedAt,
+	)
+	return err
+}
+
+func (r *UserRepository) SetOrganizationStatus(ctx context.Context, id, status string) error {
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE organizations SET status = $2 WHERE id = $1 AND deleted_at IS NULL`,
+		id, status,
+	)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *UserRepository) GetOrganizationStatus(ctx context.Context, id string) (string, error) {
+	status := models.OrgStatusActive
+	err := r.pool.QueryRow(ctx, `SELECT status FROM organizations WHERE id = $1`, id).Scan(&status)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return models.OrgStatusActive, nil
+	}
+	return status, err
+}
+
+// DeleteOrganization tombstones the org and drops its memberships.
+func (r *UserRepository) DeleteOrganization(ctx context.Context, id string) error {
+	_, err := r.pool.Exec(ctx,
+		`WITH gone AS (DELETE FROM org_memberships WHERE org_id = $1)
+		 INSERT INTO organizations (id, name, updated_at, deleted_at) VALUES ($1, '', NOW(), NOW())
+		 ON CONFLICT (id) DO UPDATE SET deleted_at = COALESCE(organizations.deleted_at, NOW())`,
+		id,
+	)
+	return err
+}
+
+// UpsertMembership skips memberships of users or orgs that are already
+// deleted.
+func (r *UserRepository) UpsertMembership(ctx context.Context, m models.OrgMembership) error {
+	_, err := r.pool.Exec(ctx,
+		`INSERT INTO org_memberships (org_id, user_id, role, updated_at)
+		 SELECT $1, $2, $3, $4
+		 WHERE NOT EXISTS (SELECT 1 FROM organizations WHERE id = $1 AND deleted_at IS NOT NULL)
+		   AND NOT EXISTS (SELECT 1 FROM users WHERE id = $2 AND deleted_at IS NOT NULL)
+		 ON CONFLICT (org_id, user_id) DO UPDATE SET
+			role = EXCLUDED.role,
+			updated_at = EXCLUDED.updated_at
+		 WHERE org_memberships.updated_at <= EXCLUDED.updated_at`,
+		m.OrgID, m.UserID, m.Role, m.UpdatedAt,
+	)
+	return err
+}
+
+func (r *UserRepository) DeleteMembership(ctx context.Context, orgID, userID string) error {
+	_, err := r.pool.Exec(ctx,
+		`DELETE FROM org_memberships WHERE org_id = $1 AND user_id = $2`,
+		orgID, userID,
+	)
+	return err
+}
+
+const userColumns = `id, email, first_name, last_name, username, image_url, updated_at, deleted_at`
+
+// memberColumns reads a membership joined with a user row that may not
+// exist yet.
+const memberColumns = `m.user_id, u.email, u.first_name, u.last_name, u.username, u.image_url,
+	COALESCE(u.updated_at, m.updated_at), u.deleted_at, m.role`
+
+func scanMember(row pgx.Row) (*models.OrgMember, error) {
+	var m models.OrgMember
+	err := row.Scan(&m.ID, &m.Email, &m.FirstName, &m.LastName, &m.Username, &m.ImageURL, &m.UpdatedAt, &m.DeletedAt, &m.Role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id string) (*models.User, error) {
+	var u models.User
+	err := r.pool.QueryRow(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id).
+		Scan(&u.ID, &u.Email, &u.FirstName, &u.LastName, &u.Username, &u.ImageURL, &u.UpdatedAt, &u.DeletedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *UserRepository) GetMember(ctx context.Context, orgID, userID string) (*models.OrgMember, error) {
+	return scanMember(r.pool.QueryRow(ctx,
+		`SELECT `+memberColumns+`
+		 FROM org_memberships m LEFT JOIN users u ON u.id = m.user_id
+		 WHERE m.org_id = $1 AND m.user_id = $2`,
+		orgID, userID,
+	))
+}
+
+// FindMemberByEmail matches case-insensitively. Deleted users, and
+// members whose user row hasn't arrived yet, aren't found.
+func (r *UserRepository) FindMemberByEmail(ctx context.Context, orgID, email string) (*models.OrgMember, error) {
+	return scanMember(r.pool.QueryRow(ctx,
+		`SELECT `+memberColumns+`
+		 FROM org_memberships m JOIN users u ON u.id = m.user_id
+		 WHERE m.org_id = $1 AND lower(u.email) = lower($2) AND u.deleted_at IS NULL
+		 ORDER BY m.user_id
+		 LIMIT 1`,
+		orgID, email,
+	))
+}
+
+// ListMembers returns up to page.Limit+1 of the org's members ordered by
+// user id.
+func (r *UserRepository) ListMembers(ctx context.Context, orgID string, page models.Page) ([]models.OrgMember, error) {
+	var afterID *string
+	if len(page.After) == 1 {
+		afterID = &page.After[0]
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+memberColumns+`
+		 FROM org_memberships m LEFT JOIN users u ON u.id = m.user_id
+		 WHERE m.org_id = $1 AND ($2::text IS NULL OR m.user_id > $2)
+		 ORDER BY m.user_id
+		 LIMIT $3`,
+		orgID, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []models.OrgMember{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, *m)
+	}
+	return members, rows.Err()
+}
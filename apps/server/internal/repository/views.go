@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const viewColumns = `id, owner_id, org_id, name, query, sort, shared, created_at, updated_at`
+
+type ViewRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewViewRepository(pool *pgxpool.Pool) *ViewRepository {
+	return &ViewRepository{pool: pool}
+}
+
+func scanView(row pgx.Row) (*models.View, error) {
+	var v models.View
+	err := row.Scan(&v.ID, &v.OwnerID, &v.OrgID, &v.Name, &v.Query, &v.Sort, &v.Shared, &v.CreatedAt, &v.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// visibleViewClause matches the scope's own views and those shared with its
+// org, binding the user id at $n and the org id at $n+1.
+func visibleViewClause(scope models.Scope, n int) (string, []any) {
+	if !scope.IsOrg() {
+		return fmt.Sprintf("org_id IS NULL AND owner_id = $%d", n), []any{scope.UserID}
+	}
+	return fmt.Sprintf("org_id = $%d AND (owner_id = $%d OR shared)", n+1, n), []any{scope.UserID, scope.OrgID}
+}
+
+func (r *ViewRepository) Create(ctx context.Context, scope models.Scope, input models.CreateViewInput) (*models.View, error) {
+	return scanView(r.pool.QueryRow(ctx,
+		`INSERT INTO views (owner_id, org_id, name, query, sort, shared)
+		 VALUES ($1, $2, $3, $4, $5, $6)
+		 RETURNING `+viewColumns,
+		scope.UserID, scope.OrgIDPtr(), input.Name, input.Query, input.Sort, input.Shared,
+	))
+}
+
+func (r *ViewRepository) Get(ctx context.Context, scope models.Scope, id string) (*models.View, error) {
+	where, args := visibleViewClause(scope, 2)
+	return scanView(r.pool.QueryRow(ctx,
+		`SELECT `+viewColumns+` FROM views WHERE id = $1 AND `+where,
+		append([]any{id}, args...)...,
+	))
+}
+
+func (r *ViewRepository) List(ctx context.Context, scope models.Scope) ([]models.View, error) {
+	where, args := visibleViewClause(scope, 1)
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+viewColumns+` FROM views WHERE `+where+` ORDER BY name, id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []models.View{}
+	for rows.Next() {
+		v, err := scanView(rows)
+		if err != nil {
+			return nil, err
+		}
+		views = append(views, *v)
+	}
+	return views, rows.Err()
+}
+
+func (r *ViewRepository) Update(ctx context.Context, scope models.Scope, id string, input models.UpdateViewInput) (*models.View, error) {
+	sets := []string{}
+	args := []any{id}
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	if input.Name != nil {
+		set("name", *input.Name)
+	}
+	if input.Query != nil {
+		set("query", *input.Query)
+	}
+	if input.Sort != nil {
+		set("sort", *input.Sort)
+	}
+	if input.Shared != nil {
+		set("shared", *input.Shared)
+	}
+
+	where, whereArgs := ownerClause(scope, len(args)+1)
+	v, err := scanView(r.pool.QueryRow(ctx,
+		`UPDATE views SET `+strings.Join(append(sets, "updated_at = NOW()"), ", ")+`
+		 WHERE id = $1 AND `+where+`
+		 RETURNING `+viewColumns,
+		append(args, whereArgs...)...,
+	))
+	if errors.Is(err, ErrNotFound) {
+		return nil, r.writeMiss(ctx, scope, id)
+	}
+	return v, err
+}
+
+func (r *ViewRepository) Delete(ctx context.Context, scope models.Scope, id string) error {
+	where, args := ownerClause(scope, 2)
+	tag, err := r.pool.Exec(ctx, `DELETE FROM views WHERE id = $1 AND `+where, append([]any{id}, args...)...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return r.writeMiss(ctx, scope, id)
+	}
+	return nil
+}
+
+// writeMiss tells a view the scope can't see from one it can see but not
+// change.
+func (r *ViewRepository) writeMiss(ctx context.Context, scope models.Scope, id string) error {
+	if _, err := r.Get(ctx, scope, id); err != nil {
+		return err
+	}
+	return ErrReadOnly
+}
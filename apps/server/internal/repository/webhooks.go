@@ -0,0 +1,291 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const webhookColumns = `id, org_id, url, events, active, created_by, created_at, updated_at`
+
+const webhookDeliveryColumns = `id, endpoint_id, event_id, event, payload, attempt, status_code, error, duration_ms, created_at`
+
+type WebhookRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWebhookRepository(pool *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{pool: pool}
+}
+
+func webhookFields(e *models.WebhookEndpoint) []any {
+	return []any{&e.ID, &e.OrgID, &e.URL, &e.Events, &e.Active, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt}
+}
+
+func scanWebhook(row pgx.Row, extra ...any) (*models.WebhookEndpoint, error) {
+	var e models.WebhookEndpoint
+	err := row.Scan(append(webhookFields(&e), extra...)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (r *WebhookRepository) list(ctx context.Context, query string, args ...any) ([]models.WebhookEndpoint, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []models.WebhookEndpoint{}
+	for rows.Next() {
+		e, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *e)
+	}
+	return list, rows.Err()
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, orgID, userID string, input models.CreateWebhookInput) (*models.WebhookEndpoint, error) {
+	e, err := scanWebhook(r.pool.QueryRow(ctx,
+		`INSERT INTO webhook_endpoints (org_id, url, secret, events, created_by)
+		 VALUES ($1, $2, $3, $4, $5)
+		 RETURNING `+webhookColumns,
+		orgID, input.URL, input.Secret, input.Events, userID,
+	))
+	if err != nil {
+		return nil, err
+	}
+	e.Secret = input.Secret
+	return e, nil
+}
+
+func (r *WebhookRepository) List(ctx context.Context, orgID string) ([]models.WebhookEndpoint, error) {
+	return r.list(ctx,
+		`SELECT `+webhookColumns+` FROM webhook_endpoints WHERE org_id = $1 ORDER BY created_at, id`,
+		orgID,
+	)
+}
+
+func (r *WebhookRepository) Get(ctx context.Context, orgID, id string) (*models.WebhookEndpoint, error) {
+	return scanWebhook(r.pool.QueryRow(ctx,
+		`SELECT `+webhookColumns+` FROM webhook_endpoints WHERE id = $1 AND org_id = $2`,
+		id, orgID,
+	))
+}
+
+func (r *WebhookRepository) Update(ctx context.Context, orgID, id string, input models.UpdateWebhookInput) (*models.WebhookEndpoint, error) {
+	sets := []string{}
+	args := []any{id, orgID}
+
+	set := func(column string, value any) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if input.URL != nil {
+		set("url", *input.URL)
+	}
+	if input.Events != nil {
+		set("events", *input.Events)
+	}
+	if input.Active != nil {
+		set("active", *input.Active)
+	}
+
+	if len(sets) == 0 {
+		return r.Get(ctx, orgID, id)
+	}
+	return scanWebhook(r.pool.QueryRow(ctx,
+		`UPDATE webhook_endpoints SET `+strings.Join(sets, ", ")+`, updated_at = NOW()
+		 WHERE id = $1 AND org_id = $2
+		 RETURNING `+webhookColumns,
+		args...,
+	))
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, orgID, id string) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1 AND org_id = $2`, id, orgID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *WebhookRepository) Subscribed(ctx context.Context, orgID, event string) ([]models.WebhookEndpoint, error) {
+	return r.list(ctx,
+		`SELECT `+webhookColumns+` FROM webhook_endpoints
+		 WHERE org_id = $1 AND active AND $2 = ANY(events)
+		 ORDER BY id`,
+		orgID, event,
+	)
+}
+
+func (r *WebhookRepository) Target(ctx context.Context, id string) (*models.WebhookEndpoint, error) {
+	var secret string
+	e, err := scanWebhook(r.pool.QueryRow(ctx,
+		`SELECT `+webhookColumns+`, secret FROM webhook_endpoints WHERE id = $1`,
+		id,
+	), &secret)
+	if err != nil {
+		return nil, err
+	}
+	e.Secret = secret
+	return e, nil
+}
+
+func deliveryFields(d *models.WebhookDelivery) []any {
+	return []any{&d.ID, &d.EndpointID, &d.EventID, &d.Event, &d.Payload, &d.Attempt, &d.StatusCode, &d.Error, &d.DurationMs, &d.CreatedAt}
+}
+
+func (r *WebhookRepository) RecordDelivery(ctx context.Context, d models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	var out models.WebhookDelivery
+	err := r.pool.QueryRow(ctx,
+		`INSERT INTO webhook_deliveries (endpoint_id, event_id, event, payload, attempt, status_code, error, duration_ms)
+		 SELECT $1, $2, $3, $4,
+		        (SELECT COUNT(*) + 1 FROM webhook_deliveries WHERE endpoint_id = $1 AND event_id = $2),
+		        $5, $6, $7
+		 FROM webhook_endpoints WHERE id = $1
+		 RETURNING `+webhookDeliveryColumns,
+		d.EndpointID, d.EventID, d.Event, d.Payload, d.StatusCode, d.Error, d.DurationMs,
+	).Scan(deliveryFields(&out)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (r *WebhookRepository) Deliveries(ctx context.Context, orgID, endpointID string, page models.Page) ([]models.WebhookDelivery, error) {
+	if _, err := r.Get(ctx, orgID, endpointID); err != nil {
+		return nil, err
+	}
+
+	var afterTime *time.Time
+	var afterID *string
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		afterTime, afterID = &t, &page.After[1]
+	}
+
+	rows, err := r.pool.Query(ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries
+		 WHERE endpoint_id = $1
+		   AND ($2::timestamptz IS NULL OR (created_at, id) < ($2, $3::uuid))
+		 ORDER BY created_at DESC, id DESC
+		 LIMIT $4`,
+		endpointID, afterTime, afterID, page.Limit+1,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(deliveryFields(&d)...); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+const webhookBreakerColumns = `url, failures, trips, open_until, probing`
+
+func scanBreaker(row pgx.Row) (*models.WebhookBreaker, error) {
+	var b models.WebhookBreaker
+	err := row.Scan(&b.URL, &b.Failures, &b.Trips, &b.OpenUntil, &b.Probing)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *WebhookRepository) Breaker(ctx context.Context, orgID, url string) (*models.WebhookBreaker, error) {
+	b, err := scanBreaker(r.pool.QueryRow(ctx,
+		`SELECT `+webhookBreakerColumns+` FROM webhook_breakers WHERE org_id = $1 AND url = $2`,
+		orgID, url,
+	))
+	if errors.Is(err, ErrNotFound) {
+		b, err = &models.WebhookBreaker{URL: url}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.SetState(time.Now())
+	return b, nil
+}
+
+func (r *WebhookRepository) ClaimProbe(ctx context.Context, orgID, url string, lease time.Duration) (bool, error) {
+	now := time.Now()
+	tag, err := r.pool.Exec(ctx,
+		`UPDATE webhook_breakers SET open_until = $4, probing = TRUE, updated_at = NOW()
+		 WHERE org_id = $1 AND url = $2 AND open_until <= $3`,
+		orgID, url, now, now.Add(lease),
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *WebhookRepository) RecordBreakerResult(ctx context.Context, orgID, url string, succeeded bool, policy models.WebhookBreakerPolicy) (*models.WebhookBreaker, error) {
+	if succeeded {
+		if _, err := r.pool.Exec(ctx, `DELETE FROM webhook_breakers WHERE org_id = $1 AND url = $2`, orgID, url); err != nil {
+			return nil, err
+		}
+		return &models.WebhookBreaker{URL: url, State: models.BreakerClosed}, nil
+	}
+
+	var b *models.WebhookBreaker
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		// The upsert locks the row, so failures reported at the same time
+		// are counted one after the other.
+		var err error
+		b, err = scanBreaker(tx.QueryRow(ctx,
+			`INSERT INTO webhook_breakers (org_id, url) VALUES ($1, $2)
+			 ON CONFLICT (org_id, url) DO UPDATE SET updated_at = NOW()
+			 RETURNING `+webhookBreakerColumns,
+			orgID, url,
+		))
+		if err != nil {
+			return err
+		}
+		b.Fail(time.Now(), policy)
+		_, err = tx.Exec(ctx,
+			`UPDATE webhook_breakers SET failures = $3, trips = $4, open_until = $5, probing = $6, updated_at = NOW()
+			 WHERE org_id = $1 AND url = $2`,
+			orgID, url, b.Failures, b.Trips, b.OpenUntil, b.Probing,
+		)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"yata/apps/server/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type WorkflowRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewWorkflowRepository(pool *pgxpool.Pool) *WorkflowRepository {
+	return &WorkflowRepository{pool: pool}
+}
+
+type querier interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
+// Reader runs read-only queries: the pool, or database.Replicas when reads
+// that can be a little stale go to replicas.
+type Reader interface {
+	querier
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+func getWorkflow(ctx context.Context, q querier, orgID string) (models.Workflow, error) {
+	var statuses []models.StatusDefinition
+	var priorities []models.PriorityLevel
+	err := q.QueryRow(ctx,
+		`SELECT statuses, priorities FROM org_workflows WHERE org_id = $1`,
+		orgID,
+	).Scan(&statuses, &priorities)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return models.Workflow{}, err
+	}
+
+	w := models.DefaultWorkflow()
+	if statuses != nil {
+		w.Statuses = statuses
+	}
+	if priorities != nil {
+		w.Priorities = priorities
+	}
+	return w, nil
+}
+
+// Get returns the org's workflow, falling back to the defaults for anything
+// it hasn't configured.
+func (r *WorkflowRepository) Get(ctx context.Context, orgID string) (models.Workflow, error) {
+	return getWorkflow(ctx, r.pool, orgID)
+}
+
+// SetStatuses replaces the org's statuses. It returns ErrConflict if a task in
+// the org still uses a status that isn't in the new set.
+func (r *WorkflowRepository) SetStatuses(ctx context.Context, orgID string, statuses []models.StatusDefinition) (models.Workflow, error) {
+	keys := make([]string, len(statuses))
+	for i, s := range statuses {
+		keys[i] = s.Key
+	}
+
+	var w models.Workflow
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO org_workflows (org_id, statuses) VALUES ($1, $2)
+			 ON CONFLICT (org_id) DO UPDATE SET statuses = EXCLUDED.statuses, updated_at = NOW()`,
+			orgID, statuses,
+		)
+		if err != nil {
+			return err
+		}
+
+		var inUse bool
+		err = tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM tasks WHERE org_id = $1 AND status <> ALL($2))`,
+			orgID, keys,
+		).Scan(&inUse)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrConflict
+		}
+
+		w, err = getWorkflow(ctx, tx, orgID)
+		return err
+	})
+	return w, err
+}
+
+// SetPriorities replaces the org's priority levels, with the same in-use
+// check as SetStatuses.
+func (r *WorkflowRepository) SetPriorities(ctx context.Context, orgID string, priorities []models.PriorityLevel) (models.Workflow, error) {
+	levels := make([]int, len(priorities))
+	for i, p := range priorities {
+		levels[i] = p.Level
+	}
+
+	var w models.Workflow
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO org_workflows (org_id, priorities) VALUES ($1, $2)
+			 ON CONFLICT (org_id) DO UPDATE SET priorities = EXCLUDED.priorities, updated_at = NOW()`,
+			orgID, priorities,
+		)
+		if err != nil {
+			return err
+		}
+
+		var inUse bool
+		err = tx.QueryRow(ctx,
+			`SELECT EXISTS (SELECT 1 FROM tasks WHERE org_id = $1 AND priority <> ALL($2::smallint[]))`,
+			orgID, levels,
+		).Scan(&inUse)
+		if err != nil {
+			return err
+		}
+		if inUse {
+			return ErrConflict
+		}
+
+		w, err = getWorkflow(ctx, tx, orgID)
+		return err
+	})
+	return w, err
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/servicetokens"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type scopeKey struct{}
+
+// authenticate checks the service token on every call, the way
+// middlewares.ServiceAuth does for the REST service routes: Get and List
+// calls need the read scope, everything else write. Health checks are open
+// so probes don't need a token.
+func authenticate(issuer *servicetokens.Issuer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if strings.HasPrefix(info.FullMethod, "/"+grpc_health_v1.Health_ServiceDesc.ServiceName+"/") {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		var credential string
+		if values := md.Get("authorization"); len(values) > 0 {
+			credential, _ = strings.CutPrefix(values[0], "Bearer ")
+		}
+		claims, err := issuer.Verify(credential, time.Now())
+		if credential == "" || err != nil {
+			return nil, status.Error(codes.Unauthenticated, "Unauthorized")
+		}
+
+		if !scopesAllow(claims.Scopes, info.FullMethod) {
+			return nil, status.Error(codes.PermissionDenied, "Token scope does not allow this request")
+		}
+
+		scope := models.Scope{UserID: claims.Principal(), OrgID: claims.OrgID}
+		return handler(context.WithValue(ctx, scopeKey{}, scope), req)
+	}
+}
+
+func scopesAllow(scopes []string, fullMethod string) bool {
+	if slices.Contains(scopes, models.TokenScopeWrite) {
+		return true
+	}
+	method := fullMethod[strings.LastIndexByte(fullMethod, '/')+1:]
+	read := strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List")
+	return read && slices.Contains(scopes, models.TokenScopeRead)
+}
+
+func scopeFrom(ctx context.Context) models.Scope {
+	scope, _ := ctx.Value(scopeKey{}).(models.Scope)
+	return scope
+}
@@ -0,0 +1,71 @@
+package rpc
+
+import (
+	"context"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/rpc/yatav1"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type projectService struct {
+	yatav1.UnimplementedProjectServiceServer
+	stores Stores
+}
+
+func (s *projectService) GetProject(ctx context.Context, req *yatav1.GetProjectRequest) (*yatav1.Project, error) {
+	scope := scopeFrom(ctx)
+	if !scope.IsOrg() {
+		return nil, status.Error(codes.FailedPrecondition, "No organization selected")
+	}
+	if !isValidID(req.Id) {
+		return nil, status.Error(codes.NotFound, "Project not found")
+	}
+	project, err := s.stores.Projects.Get(ctx, scope.OrgID, req.Id)
+	if err != nil {
+		return nil, storeError(ctx, "Failed to get project", "Project not found", err)
+	}
+	return projectMessage(project), nil
+}
+
+func (s *projectService) ListProjects(ctx context.Context, req *yatav1.ListProjectsRequest) (*yatav1.ListProjectsResponse, error) {
+	scope := scopeFrom(ctx)
+	if !scope.IsOrg() {
+		return nil, status.Error(codes.FailedPrecondition, "No organization selected")
+	}
+	page, err := pageOf("projects", req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.stores.Projects.List(ctx, scope.OrgID, req.IncludeArchived, page)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list projects", err)
+	}
+
+	list, hasMore := api.Trim(list, page.Limit)
+	resp := &yatav1.ListProjectsResponse{Projects: make([]*yatav1.Project, 0, len(list))}
+	for i := range list {
+		resp.Projects = append(resp.Projects, projectMessage(&list[i]))
+	}
+	if hasMore {
+		last := list[len(list)-1]
+		resp.NextPageToken = api.EncodeCursor("projects", last.Name, last.ID)
+	}
+	return resp, nil
+}
+
+func projectMessage(p *models.Project) *yatav1.Project {
+	return &yatav1.Project{
+		Id:         p.ID,
+		OrgId:      p.OrgID,
+		Name:       p.Name,
+		CreatedBy:  p.CreatedBy,
+		ArchivedAt: timestamp(p.ArchivedAt),
+		CreatedAt:  timestamppb.New(p.CreatedAt),
+		UpdatedAt:  timestamppb.New(p.UpdatedAt),
+	}
+}
@@ -0,0 +1,117 @@
+// Package rpc serves the gRPC API in proto/yata/v1 for other backend
+// services. It sits beside the REST handlers on the same stores and checks
+// requests the same way; only the transport differs.
+package rpc
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=yata/apps/server --go-grpc_out=../.. --go-grpc_opt=module=yata/apps/server yata/v1/yata.proto
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"regexp"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/rpc/yatav1"
+	"yata/apps/server/internal/servicetokens"
+	"yata/apps/server/internal/store"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type Stores struct {
+	Tasks       store.TaskStore
+	Projects    store.ProjectStore
+	Workflows   store.WorkflowStore
+	OrgSettings store.OrgSettingsStore
+}
+
+// NewServer returns a gRPC server with the task and project services and
+// the standard health service registered.
+func NewServer(issuer *servicetokens.Issuer, stores Stores) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(recoverPanics, authenticate(issuer)))
+	yatav1.RegisterTaskServiceServer(srv, &taskService{stores: stores})
+	yatav1.RegisterProjectServiceServer(srv, &projectService{stores: stores})
+	grpc_health_v1.RegisterHealthServer(srv, health.NewServer())
+	return srv
+}
+
+// recoverPanics turns a panicking call into an Internal error instead of
+// taking the whole process down, like gin's recovery middleware.
+func recoverPanics(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "gRPC call panicked", "method", info.FullMethod, "panic", r)
+			err = status.Error(codes.Internal, "Internal error")
+		}
+	}()
+	return handler(ctx, req)
+}
+
+// internalError logs err and returns what the caller sees instead.
+func internalError(ctx context.Context, msg string, err error) error {
+	slog.ErrorContext(ctx, msg, "error", err)
+	return status.Error(codes.Internal, msg)
+}
+
+// storeError maps the store's sentinel errors, and quota rejections, to
+// status codes, and anything else to an internal error logged as msg.
+func storeError(ctx context.Context, msg, notFound string, err error) error {
+	var exceeded *quota.ExceededError
+	switch {
+	case errors.As(err, &exceeded):
+		return status.Error(codes.ResourceExhausted, "Your organization's plan limit has been reached")
+	case errors.Is(err, store.ErrNotFound):
+		return status.Error(codes.NotFound, notFound)
+	case errors.Is(err, store.ErrReadOnly):
+		return status.Error(codes.PermissionDenied, "Task is read-only")
+	case errors.Is(err, store.ErrVersionMismatch):
+		return status.Error(codes.FailedPrecondition, "Task has been modified")
+	}
+	return internalError(ctx, msg, err)
+}
+
+func invalid(msg string) error {
+	return status.Error(codes.InvalidArgument, msg)
+}
+
+func isValidID(id string) bool {
+	return uuidPattern.MatchString(id)
+}
+
+// pageOf reads page_size and page_token for the listing kind.
+func pageOf(kind string, size int32, token string) (models.Page, error) {
+	page := models.Page{Limit: api.DefaultLimit}
+	if size < 0 {
+		return page, invalid("Invalid page_size")
+	}
+	if size > 0 {
+		page.Limit = min(int(size), api.MaxLimit)
+	}
+	if token != "" {
+		after, err := api.DecodeCursor(kind, token)
+		if err != nil {
+			return page, invalid("Invalid page_token")
+		}
+		page.After = after
+	}
+	return page, nil
+}
+
+func loadWorkflow(ctx context.Context, workflows store.WorkflowStore, scope models.Scope) (models.Workflow, error) {
+	if !scope.IsOrg() {
+		return models.DefaultWorkflow(), nil
+	}
+	workflow, err := workflows.Get(ctx, scope.OrgID)
+	if err != nil {
+		return models.Workflow{}, internalError(ctx, "Failed to load organization settings", err)
+	}
+	return workflow, nil
+}
@@ -0,0 +1,348 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/handlers"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/recurrence"
+	"yata/apps/server/internal/rpc/yatav1"
+	"yata/apps/server/internal/store"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type taskService struct {
+	yatav1.UnimplementedTaskServiceServer
+	stores Stores
+}
+
+func (s *taskService) GetTask(ctx context.Context, req *yatav1.GetTaskRequest) (*yatav1.Task, error) {
+	if !isValidID(req.Id) {
+		return nil, status.Error(codes.NotFound, "Task not found")
+	}
+	task, err := s.stores.Tasks.Get(ctx, scopeFrom(ctx), req.Id)
+	if err != nil {
+		return nil, storeError(ctx, "Failed to get task", "Task not found", err)
+	}
+	return taskMessage(task), nil
+}
+
+func (s *taskService) ListTasks(ctx context.Context, req *yatav1.ListTasksRequest) (*yatav1.ListTasksResponse, error) {
+	scope := scopeFrom(ctx)
+	filter := models.TaskFilter{
+		ProjectID:       req.GetProjectId(),
+		ParentID:        req.GetParentId(),
+		Statuses:        req.Statuses,
+		IncludeArchived: req.IncludeArchived,
+		Sort:            models.DefaultTaskSort,
+	}
+	if filter.ProjectID != "" && !isValidID(filter.ProjectID) {
+		return nil, invalid("Invalid filter: invalid projectId")
+	}
+	if filter.ParentID != "" && !isValidID(filter.ParentID) {
+		return nil, invalid("Invalid filter: invalid parentId")
+	}
+
+	kind := "tasks:" + models.SortString(filter.Sort)
+	page, err := pageOf(kind, req.PageSize, req.PageToken)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := s.stores.Tasks.List(ctx, scope, filter, page)
+	if err != nil {
+		return nil, internalError(ctx, "Failed to list tasks", err)
+	}
+
+	list, hasMore := api.Trim(list, page.Limit)
+	resp := &yatav1.ListTasksResponse{Tasks: make([]*yatav1.Task, 0, len(list))}
+	for i := range list {
+		resp.Tasks = append(resp.Tasks, taskMessage(&list[i]))
+	}
+	if hasMore {
+		last := &list[len(list)-1]
+		values := make([]string, 0, len(filter.Sort)+1)
+		for _, sort := range filter.Sort {
+			values = append(values, models.TaskSortValue(last, sort))
+		}
+		resp.NextPageToken = api.EncodeCursor(kind, append(values, last.ID)...)
+	}
+	return resp, nil
+}
+
+func (s *taskService) CreateTask(ctx context.Context, req *yatav1.CreateTaskRequest) (*yatav1.Task, error) {
+	scope := scopeFrom(ctx)
+	input := models.CreateTaskInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      req.Status,
+		Priority:    int(req.Priority),
+		DueTimezone: req.DueTimezone,
+		Recurrence:  req.Recurrence,
+		ProjectID:   req.ProjectId,
+		ParentID:    req.ParentId,
+		Visibility:  req.Visibility,
+	}
+	if req.DueDate != nil {
+		due := req.DueDate.AsTime()
+		input.DueDate = &due
+	}
+	if input.Title == "" {
+		return nil, invalid("Invalid request body")
+	}
+
+	workflow, err := loadWorkflow(ctx, s.stores.Workflows, scope)
+	if err != nil {
+		return nil, err
+	}
+	if input.DueTimezone != nil && !validTimezone(*input.DueTimezone) {
+		return nil, invalid("Invalid dueTimezone")
+	}
+	if input.Recurrence != nil {
+		rule, err := recurrence.Normalize(*input.Recurrence)
+		if err != nil {
+			return nil, invalid("Invalid recurrence")
+		}
+		input.Recurrence = &rule
+	}
+
+	if input.Status == "" {
+		input.Status = workflow.DefaultStatus()
+	}
+	if !workflow.IsValidStatus(input.Status) {
+		return nil, invalid("Invalid status")
+	}
+	if !workflow.IsValidPriority(input.Priority) {
+		return nil, invalid("Invalid priority")
+	}
+
+	if input.Visibility != "" && !models.ValidTaskVisibility(input.Visibility) {
+		return nil, invalid("Invalid visibility")
+	}
+	if input.Visibility == "" && scope.IsOrg() {
+		settings, err := s.stores.OrgSettings.Get(ctx, scope.OrgID)
+		if err != nil {
+			return nil, internalError(ctx, "Failed to create task", err)
+		}
+		input.Visibility = settings.DefaultTaskVisibility
+	}
+
+	if input.ParentID != nil {
+		if !isValidID(*input.ParentID) {
+			return nil, invalid("Parent task not found")
+		}
+		parent, err := s.stores.Tasks.Get(ctx, scope, *input.ParentID)
+		if err != nil {
+			return nil, storeError(ctx, "Failed to create task", "Parent task not found", err)
+		}
+		// Subtasks live in their parent's project unless told otherwise.
+		if input.ProjectID == nil {
+			input.ProjectID = parent.ProjectID
+		}
+	}
+	if input.ProjectID != nil {
+		if err := s.checkProject(ctx, scope, *input.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	task, err := s.stores.Tasks.Create(ctx, scope, input)
+	if err != nil {
+		return nil, storeError(ctx, "Failed to create task", "Parent task not found", err)
+	}
+	return taskMessage(task), nil
+}
+
+func (s *taskService) UpdateTask(ctx context.Context, req *yatav1.UpdateTaskRequest) (*yatav1.UpdateTaskResponse, error) {
+	scope := scopeFrom(ctx)
+	if !isValidID(req.Id) {
+		return nil, status.Error(codes.NotFound, "Task not found")
+	}
+
+	input := models.UpdateTaskInput{
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      req.Status,
+		Visibility:  req.Visibility,
+	}
+	if req.Priority != nil {
+		priority := int(*req.Priority)
+		input.Priority = &priority
+	}
+	if req.IfVersion != nil {
+		version := int(*req.IfVersion)
+		input.IfVersion = &version
+	}
+	if req.DueDate != nil {
+		input.DueDate = models.Nullable[time.Time]{Set: true, Value: req.DueDate.AsTime()}
+	}
+	if req.DueTimezone != nil {
+		input.DueTimezone = models.Nullable[string]{Set: true, Value: *req.DueTimezone}
+	}
+	if req.Recurrence != nil {
+		input.Recurrence = models.Nullable[string]{Set: true, Value: *req.Recurrence}
+	}
+	if req.ProjectId != nil {
+		input.ProjectID = models.Nullable[string]{Set: true, Value: *req.ProjectId}
+	}
+	for _, field := range req.Clear {
+		switch field {
+		case "due_date":
+			input.DueDate = models.Nullable[time.Time]{Set: true, Null: true}
+		case "due_timezone":
+			input.DueTimezone = models.Nullable[string]{Set: true, Null: true}
+		case "recurrence":
+			input.Recurrence = models.Nullable[string]{Set: true, Null: true}
+		case "project_id":
+			input.ProjectID = models.Nullable[string]{Set: true, Null: true}
+		default:
+			return nil, invalid("Cannot clear " + field)
+		}
+	}
+
+	if input.Title != nil && *input.Title == "" {
+		return nil, invalid("Title cannot be empty")
+	}
+	workflow, err := loadWorkflow(ctx, s.stores.Workflows, scope)
+	if err != nil {
+		return nil, err
+	}
+	if input.Status != nil && !workflow.IsValidStatus(*input.Status) {
+		return nil, invalid("Invalid status")
+	}
+	if input.Priority != nil && !workflow.IsValidPriority(*input.Priority) {
+		return nil, invalid("Invalid priority")
+	}
+	if input.DueTimezone.Set && !input.DueTimezone.Null && !validTimezone(input.DueTimezone.Value) {
+		return nil, invalid("Invalid dueTimezone")
+	}
+	if input.Recurrence.Set && !input.Recurrence.Null {
+		rule, err := recurrence.Normalize(input.Recurrence.Value)
+		if err != nil {
+			return nil, invalid("Invalid recurrence")
+		}
+		input.Recurrence.Value = rule
+	}
+	if input.ProjectID.Set && !input.ProjectID.Null {
+		if err := s.checkProject(ctx, scope, input.ProjectID.Value); err != nil {
+			return nil, err
+		}
+	}
+	if input.Visibility != nil && !models.ValidTaskVisibility(*input.Visibility) {
+		return nil, invalid("Invalid visibility")
+	}
+
+	// Like the REST handler: completing a recurring task spawns its next
+	// occurrence, and only the owner may change who can see a task.
+	wasDone := false
+	if (input.Status != nil && workflow.IsDone(*input.Status)) || input.Visibility != nil {
+		before, err := s.stores.Tasks.Get(ctx, scope, req.Id)
+		if err != nil {
+			return nil, storeError(ctx, "Failed to update task", "Task not found", err)
+		}
+		if input.Visibility != nil && before.OwnerID != scope.UserID {
+			return nil, status.Error(codes.PermissionDenied, "Only the owner can change the task's visibility")
+		}
+		wasDone = workflow.IsDone(before.Status)
+	}
+
+	task, err := s.stores.Tasks.Update(ctx, scope, req.Id, input)
+	if err != nil {
+		return nil, storeError(ctx, "Failed to update task", "Task not found", err)
+	}
+
+	resp := &yatav1.UpdateTaskResponse{}
+	if input.Status != nil && workflow.IsDone(task.Status) && !wasDone {
+		next, err := handlers.MaterializeNextOccurrence(ctx, s.stores.Tasks, scope, workflow, task)
+		if err != nil {
+			return nil, storeError(ctx, "Failed to create next occurrence", "Task not found", err)
+		}
+		if next != nil {
+			resp.NextOccurrence = taskMessage(next)
+		}
+	}
+	resp.Task = taskMessage(task)
+	return resp, nil
+}
+
+func (s *taskService) DeleteTask(ctx context.Context, req *yatav1.DeleteTaskRequest) (*yatav1.DeleteTaskResponse, error) {
+	if !isValidID(req.Id) {
+		return nil, status.Error(codes.NotFound, "Task not found")
+	}
+	var ifVersion *int
+	if req.IfVersion != nil {
+		version := int(*req.IfVersion)
+		ifVersion = &version
+	}
+
+	if err := s.stores.Tasks.Delete(ctx, scopeFrom(ctx), req.Id, ifVersion); err != nil {
+		return nil, storeError(ctx, "Failed to delete task", "Task not found", err)
+	}
+	return &yatav1.DeleteTaskResponse{}, nil
+}
+
+// checkProject makes sure a task can be put in the project, like the REST
+// handlers' checkTaskProject.
+func (s *taskService) checkProject(ctx context.Context, scope models.Scope, projectID string) error {
+	if !scope.IsOrg() {
+		return invalid("Projects require an active organization")
+	}
+	if !isValidID(projectID) {
+		return invalid("Project not found")
+	}
+	project, err := s.stores.Projects.Get(ctx, scope.OrgID, projectID)
+	if errors.Is(err, store.ErrNotFound) {
+		return invalid("Project not found")
+	}
+	if err != nil {
+		return internalError(ctx, "Failed to get project", err)
+	}
+	if project.ArchivedAt != nil {
+		return invalid("Project is archived")
+	}
+	return nil
+}
+
+// validTimezone rejects "" and "Local", which LoadLocation would map to
+// UTC and the server's zone.
+func validTimezone(name string) bool {
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+func taskMessage(t *models.Task) *yatav1.Task {
+	return &yatav1.Task{
+		Id:          t.ID,
+		OwnerId:     t.OwnerID,
+		OrgId:       t.OrgID,
+		ProjectId:   t.ProjectID,
+		ParentId:    t.ParentID,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    int32(t.Priority),
+		DueDate:     timestamp(t.DueDate),
+		DueTimezone: t.DueTimezone,
+		Recurrence:  t.Recurrence,
+		Visibility:  t.Visibility,
+		Version:     int32(t.Version),
+		ArchivedAt:  timestamp(t.ArchivedAt),
+		CreatedAt:   timestamppb.New(t.CreatedAt),
+		UpdatedAt:   timestamppb.New(t.UpdatedAt),
+	}
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
@@ -0,0 +1,47 @@
+package seed
+
+var orgNames = []string{"Acme Corp", "Globex", "Initech", "Umbrella Labs", "Hooli", "Wayne Enterprises", "Stark Industries", "Soylent"}
+
+var firstNames = []string{"Ava", "Liam", "Priya", "Noah", "Mei", "Omar", "Sofia", "Arjun", "Chloe", "Mateo", "Zara", "Lucas", "Aisha", "Ethan", "Yuki", "Diego"}
+
+var lastNames = []string{"Patel", "Smith", "Chen", "Garcia", "Kim", "Nguyen", "Okafor", "Rossi", "Müller", "Sharma", "Silva", "Cohen", "Ali", "Johnson", "Tanaka", "Novak"}
+
+var projectNames = []string{"Website redesign", "Mobile app", "Q3 marketing", "Customer onboarding", "Infrastructure", "Support queue", "Hiring", "Data platform", "Billing", "Security review"}
+
+var labels = []struct{ name, color string }{
+	{"bug", "#ef4444"},
+	{"feature", "#3b82f6"},
+	{"design", "#a855f7"},
+	{"docs", "#22c55e"},
+	{"backend", "#f97316"},
+	{"frontend", "#06b6d4"},
+	{"blocked", "#6b7280"},
+}
+
+var taskVerbs = []string{"Fix", "Write", "Review", "Design", "Update", "Set up", "Investigate", "Document", "Refactor", "Plan", "Test", "Ship"}
+
+var taskObjects = []string{
+	"login page", "billing flow", "onboarding emails", "API rate limits", "release notes",
+	"search results", "dark mode", "CI pipeline", "signup form", "analytics dashboard",
+	"password reset", "invoice export", "notification settings", "mobile navigation", "error pages",
+	"audit log", "pricing page", "database backups", "team permissions", "weekly report",
+}
+
+var descriptions = []string{
+	"See the thread from last week's sync for context.",
+	"Customers have asked for this a few times; check the support tickets before starting.",
+	"Keep the scope small: we can follow up once the first version is out.",
+	"Pair with design before writing any code.\n\n- Agree on the copy\n- Check the empty states\n- Get sign-off",
+	"Blocked on access to the staging environment until ops sorts it out.",
+}
+
+var comments = []string{
+	"I can pick this up after the release.",
+	"Does this need a migration, or is it config only?",
+	"Left a few notes on the draft.",
+	"Moved the due date: we're waiting on the vendor.",
+	"Looks good to me, shipping it.",
+	"Can we split this into two tasks? The second half is much bigger.",
+	"Tried it on staging and it works.",
+	"Who owns the follow-up here?",
+}
@@ -0,0 +1,312 @@
+// Package seed fills a store with made-up orgs, users, projects, labels,
+// tasks and comments for local development and demos. Seeding is
+// idempotent: users and orgs have fixed ids, and a project that's already
+// there, by name, is left alone along with its tasks, so running it again
+// only adds what a bigger Size asks for.
+package seed
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+// Size is how much to seed: Users are members of every one of the Orgs,
+// each of which gets Projects with Tasks in each.
+type Size struct {
+	Orgs     int
+	Users    int
+	Projects int
+	Tasks    int
+}
+
+var DefaultSize = Size{Orgs: 2, Users: 6, Projects: 3, Tasks: 30}
+
+// seededAt is the UpdatedAt of seeded users and orgs; it's older than any
+// real Clerk event, so those always win over a reseed.
+var seededAt = time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+type Stores struct {
+	Users       store.UserStore
+	Projects    store.ProjectStore
+	Labels      store.LabelStore
+	Comments    store.CommentStore
+	Workflows   store.WorkflowStore
+	OrgSettings store.OrgSettingsStore
+}
+
+// Result counts what a run created.
+type Result struct {
+	Projects int
+	Tasks    int
+	Comments int
+}
+
+// UserID is the id of the i-th seeded user, counting from zero, for
+// signing in as them in development.
+func UserID(i int) string {
+	return fmt.Sprintf("user_seed_%02d", i+1)
+}
+
+// OrgID is the id of the i-th seeded org, counting from zero.
+func OrgID(i int) string {
+	return fmt.Sprintf("org_seed_%02d", i+1)
+}
+
+// Run seeds the stores. What it makes is the same every time for the same
+// size, except for dates, which are relative to now.
+func Run(ctx context.Context, stores Stores, size Size) (Result, error) {
+	var result Result
+	if size.Users < 1 {
+		return result, fmt.Errorf("seeding needs at least one user")
+	}
+
+	members := make([]string, size.Users)
+	for i := range members {
+		if err := stores.Users.UpsertUser(ctx, seedUser(i)); err != nil {
+			return result, err
+		}
+		members[i] = UserID(i)
+	}
+
+	for i := range size.Orgs {
+		name := numbered(orgNames, i)
+		slug := strings.ReplaceAll(strings.ToLower(name), " ", "-")
+		err := stores.Users.UpsertOrganization(ctx, models.Organization{ID: OrgID(i), Name: name, Slug: &slug, UpdatedAt: seededAt})
+		if err != nil {
+			return result, err
+		}
+		for j, userID := range members {
+			// The first user runs every org.
+			role := middlewares.OrgMemberRole
+			if j == 0 {
+				role = middlewares.OrgAdminRole
+			}
+			err := stores.Users.UpsertMembership(ctx, models.OrgMembership{OrgID: OrgID(i), UserID: userID, Role: role, UpdatedAt: seededAt})
+			if err != nil {
+				return result, err
+			}
+		}
+
+		o := &orgRun{stores: stores, size: size, index: i, members: members, result: &result}
+		if err := o.run(ctx); err != nil {
+			return result, fmt.Errorf("seeding %s: %w", name, err)
+		}
+	}
+	return result, nil
+}
+
+func seedUser(i int) models.User {
+	first := firstNames[i%len(firstNames)]
+	last := lastNames[i*5%len(lastNames)]
+	email := fmt.Sprintf("%s.%s%s@example.com", strings.ToLower(first), strings.ToLower(last), suffix(i, len(firstNames)))
+	return models.User{ID: UserID(i), Email: &email, FirstName: &first, LastName: &last, UpdatedAt: seededAt}
+}
+
+// numbered is the i-th of names, with a number after it once they run out.
+func numbered(names []string, i int) string {
+	name := names[i%len(names)]
+	if i >= len(names) {
+		name += " " + fmt.Sprint(i/len(names)+1)
+	}
+	return name
+}
+
+func suffix(i, n int) string {
+	if i < n {
+		return ""
+	}
+	return fmt.Sprint(i / n)
+}
+
+type orgRun struct {
+	stores   Stores
+	size     Size
+	index    int
+	members  []string
+	result   *Result
+	scope    models.Scope
+	workflow models.Workflow
+	settings models.OrgSettings
+	// labels are the ids of the org's seed labels.
+	labels []string
+}
+
+func (o *orgRun) run(ctx context.Context) error {
+	o.scope = models.Scope{UserID: o.members[0], OrgID: OrgID(o.index)}
+
+	workflow, err := o.stores.Workflows.Get(ctx, o.scope.OrgID)
+	if err != nil {
+		return err
+	}
+	o.workflow = workflow
+	if o.settings, err = o.stores.OrgSettings.Get(ctx, o.scope.OrgID); err != nil {
+		return err
+	}
+	if err := o.prepareLabels(ctx); err != nil {
+		return err
+	}
+
+	existing, err := o.projectNames(ctx)
+	if err != nil {
+		return err
+	}
+	for i := range o.size.Projects {
+		name := numbered(projectNames, i)
+		if existing[strings.ToLower(name)] {
+			continue
+		}
+		if err := o.project(ctx, i, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// prepareLabels creates the seed labels the org doesn't have yet.
+func (o *orgRun) prepareLabels(ctx context.Context) error {
+	existing, err := o.stores.Labels.List(ctx, o.scope.OrgID)
+	if err != nil {
+		return err
+	}
+	ids := map[string]string{}
+	for _, l := range existing {
+		ids[strings.ToLower(l.Name)] = l.ID
+	}
+
+	for _, l := range labels {
+		if id, ok := ids[l.name]; ok {
+			o.labels = append(o.labels, id)
+			continue
+		}
+		color := l.color
+		if !o.settings.AllowsLabelColor(color) {
+			color = o.settings.LabelColor()
+		}
+		label, err := o.stores.Labels.Create(ctx, o.scope.OrgID, o.scope.UserID, models.CreateLabelInput{Name: l.name, Color: color})
+		if err != nil {
+			return err
+		}
+		o.labels = append(o.labels, label.ID)
+	}
+	return nil
+}
+
+// projectNames is the lowercased names of the org's projects, archived
+// ones included.
+func (o *orgRun) projectNames(ctx context.Context) (map[string]bool, error) {
+	names := map[string]bool{}
+	page := models.Page{Limit: api.MaxLimit}
+	for {
+		list, err := o.stores.Projects.List(ctx, o.scope.OrgID, true, page)
+		if err != nil {
+			return nil, err
+		}
+		list, hasMore := api.Trim(list, page.Limit)
+		for _, p := range list {
+			names[strings.ToLower(p.Name)] = true
+		}
+		if !hasMore {
+			return names, nil
+		}
+		last := list[len(list)-1]
+		page.After = []string{last.Name, last.ID}
+	}
+}
+
+// project creates the project with its board and tasks, then comments on
+// some of the tasks. A run that fails between the two leaves the project
+// without comments; it's skipped next time like any other.
+func (o *orgRun) project(ctx context.Context, index int, name string) error {
+	rng := rand.New(rand.NewPCG(uint64(o.index), uint64(index)))
+
+	columns := []models.BoardColumnInput{}
+	for _, s := range o.workflow.Statuses {
+		if len(columns) < models.MaxBoardColumns {
+			columns = append(columns, models.BoardColumnInput{Name: s.Name, Status: s.Key})
+		}
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	batch := make([]models.NewTask, o.size.Tasks)
+	for i := range batch {
+		batch[i] = o.task(rng, batch[:i], today)
+	}
+
+	seeded, err := o.stores.Projects.CreateSeeded(ctx, o.scope, models.NewProject{
+		Input: models.CreateProjectInput{Name: name},
+		Board: &models.CreateBoardInput{Name: models.DefaultBoardName, Columns: columns},
+		Tasks: batch,
+	})
+	if err != nil {
+		return err
+	}
+	o.result.Projects++
+	o.result.Tasks += len(seeded.Tasks)
+
+	for _, task := range seeded.Tasks {
+		// About a third of tasks get a short discussion.
+		if rng.IntN(3) != 0 {
+			continue
+		}
+		for range 1 + rng.IntN(3) {
+			author := models.Scope{UserID: o.members[rng.IntN(len(o.members))], OrgID: o.scope.OrgID}
+			body := comments[rng.IntN(len(comments))]
+			if _, err := o.stores.Comments.Create(ctx, author, task.ID, models.CommentInput{Body: body}); err != nil {
+				return err
+			}
+			o.result.Comments++
+		}
+	}
+	return nil
+}
+
+// task makes a task to follow the ones before it in the batch: any status
+// and priority, due anywhere from ten days ago to a month out or not at
+// all, sometimes a subtask of an earlier task or blocked by one.
+func (o *orgRun) task(rng *rand.Rand, before []models.NewTask, today time.Time) models.NewTask {
+	status := o.workflow.Statuses[rng.IntN(len(o.workflow.Statuses))]
+	t := models.NewTask{
+		Input: models.CreateTaskInput{
+			Title:      taskVerbs[rng.IntN(len(taskVerbs))] + " " + taskObjects[rng.IntN(len(taskObjects))],
+			Status:     status.Key,
+			Visibility: o.settings.DefaultTaskVisibility,
+		},
+		Parent:  -1,
+		OwnerID: o.members[rng.IntN(len(o.members))],
+	}
+	if len(o.workflow.Priorities) > 0 {
+		t.Input.Priority = o.workflow.Priorities[rng.IntN(len(o.workflow.Priorities))].Level
+	}
+	if rng.IntN(3) == 0 {
+		t.Input.Description = descriptions[rng.IntN(len(descriptions))]
+	}
+	if rng.IntN(5) < 3 {
+		due := today.AddDate(0, 0, rng.IntN(41)-10).Add(17 * time.Hour)
+		t.Input.DueDate = &due
+	}
+	for _, k := range rng.Perm(len(o.labels))[:rng.IntN(3)] {
+		t.LabelIDs = append(t.LabelIDs, o.labels[k])
+	}
+
+	if len(before) > 0 {
+		other := rng.IntN(len(before))
+		switch rng.IntN(10) {
+		case 0, 1:
+			if before[other].Parent == -1 {
+				t.Parent = other
+			}
+		case 2:
+			if !o.workflow.IsDone(t.Input.Status) {
+				t.BlockedBy = []int{other}
+			}
+		}
+	}
+	return t
+}
@@ -0,0 +1,365 @@
+package server
+
+import (
+	"net/http"
+	"time"
+	"yata/apps/server/internal/api"
+	"yata/apps/server/internal/handlers"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/openapi"
+)
+
+// apiOps describes the request and response bodies of the /api/v1
+// routes for the OpenAPI document. The routes themselves come from the
+// router, so a route missing here is still documented, just without
+// bodies; add an entry next to any new handler.
+var apiOps = openapi.Ops{
+	"GET /api/v1/me": {Summary: "Get the signed-in user", Tag: "Me", Response: struct {
+		UserID  string       `json:"userId"`
+		OrgID   string       `json:"orgId"`
+		OrgSlug string       `json:"orgSlug"`
+		OrgRole string       `json:"orgRole"`
+		User    *models.User `json:"user"`
+	}{}},
+	"DELETE /api/v1/me":                    {Summary: "Delete the account and erase its data", Tag: "Me", Status: http.StatusNoContent},
+	"POST /api/v1/me/export":               {Summary: "Start exporting all of the user's data", Tag: "Me", Response: models.DataExport{}, Status: http.StatusAccepted},
+	"GET /api/v1/me/export/:id":            {Summary: "Get a data export, with its download URL once done", Tag: "Me", Response: models.DataExport{}},
+	"GET /api/v1/me/settings":              {Summary: "Get user settings", Tag: "Me", Response: models.UserSettings{}},
+	"PATCH /api/v1/me/settings":            {Summary: "Update user settings", Tag: "Me", Request: models.UpdateUserSettingsInput{}, Response: models.UserSettings{}},
+	"GET /api/v1/me/email-preferences":     {Summary: "Get email preferences", Tag: "Me", Response: models.EmailPreferences{}},
+	"PATCH /api/v1/me/email-preferences":   {Summary: "Update email preferences", Tag: "Me", Request: models.UpdateEmailPreferencesInput{}, Response: models.EmailPreferences{}},
+	"POST /api/v1/me/push-subscriptions":   {Summary: "Subscribe a browser to push notifications", Tag: "Me", Request: models.CreatePushSubscriptionInput{}, Response: models.PushSubscription{}, Status: http.StatusCreated},
+	"DELETE /api/v1/me/push-subscriptions": {Summary: "Unsubscribe a browser from push notifications", Tag: "Me", Request: models.DeletePushSubscriptionInput{}, Status: http.StatusNoContent},
+	"GET /api/v1/me/calendar-feed":         {Summary: "Get the calendar feed", Tag: "Me", Response: models.CalendarFeed{}},
+	"POST /api/v1/me/calendar-feed":        {Summary: "Create or rotate the calendar feed URL", Tag: "Me", Response: models.CalendarFeed{}, Status: http.StatusCreated},
+	"DELETE /api/v1/me/calendar-feed":      {Summary: "Revoke the calendar feed", Tag: "Me", Status: http.StatusNoContent},
+	"POST /api/v1/me/google-calendar/connect": {Summary: "Start connecting Google Calendar", Tag: "Me", Response: struct {
+		URL string `json:"url"`
+	}{}},
+	"GET /api/v1/me/google-calendar":    {Summary: "Get the Google Calendar connection", Tag: "Me", Response: models.GoogleCalendarConnection{}},
+	"DELETE /api/v1/me/google-calendar": {Summary: "Disconnect Google Calendar", Tag: "Me", Status: http.StatusNoContent},
+	"GET /api/v1/me/inbound-addresses": {Summary: "List email-to-task addresses", Tag: "Me", Response: struct {
+		Addresses []models.InboundAddress `json:"addresses"`
+	}{}},
+	"POST /api/v1/me/inbound-addresses":       {Summary: "Create or rotate an email-to-task address", Tag: "Me", Request: models.RotateInboundAddressInput{}, Response: models.InboundAddress{}, Status: http.StatusCreated},
+	"DELETE /api/v1/me/inbound-addresses/:id": {Summary: "Delete an email-to-task address", Tag: "Me", Status: http.StatusNoContent},
+	"POST /api/v1/me/telegram/link": {Summary: "Start linking a Telegram chat", Tag: "Me", Response: struct {
+		URL       string    `json:"url"`
+		ExpiresAt time.Time `json:"expiresAt"`
+	}{}, Status: http.StatusCreated},
+	"GET /api/v1/me/telegram":    {Summary: "Get the linked Telegram chat", Tag: "Me", Response: models.TelegramLink{}},
+	"DELETE /api/v1/me/telegram": {Summary: "Unlink Telegram", Tag: "Me", Status: http.StatusNoContent},
+	"POST /api/v1/me/tokens":     {Summary: "Create an API token", Tag: "Me", Request: models.CreateAPITokenInput{}, Response: models.APIToken{}, Status: http.StatusCreated},
+	"GET /api/v1/me/tokens": {Summary: "List API tokens", Tag: "Me", Response: struct {
+		Tokens []models.APIToken `json:"tokens"`
+	}{}},
+	"DELETE /api/v1/me/tokens/:id": {Summary: "Revoke an API token", Tag: "Me", Status: http.StatusNoContent},
+	"GET /api/v1/push/vapid-public-key": {Summary: "Get the VAPID public key", Tag: "Me", Response: struct {
+		PublicKey string `json:"publicKey"`
+	}{}},
+
+	"POST /api/v1/tasks": {Summary: "Create a task", Tag: "Tasks", Query: []string{"check_duplicates"}, Request: models.CreateTaskInput{}, Response: models.Task{}, Status: http.StatusCreated},
+	"GET /api/v1/tasks": {Summary: "List tasks", Tag: "Tasks", Query: []string{"projectId", "labelId", "ownerId", "parentId", "status", "priority", "overdue", "include_snoozed", "include_archived", "sort", "limit", "cursor", "render"}, Response: struct {
+		Tasks    []models.Task `json:"tasks"`
+		PageInfo api.PageInfo  `json:"pageInfo"`
+	}{}, Conditional: true},
+	"POST /api/v1/tasks/quick": {Summary: "Parse a quick-add line into a task without creating it", Tag: "Tasks", Request: models.QuickAddInput{}, Response: models.QuickAddResult{}},
+	"POST /api/v1/tasks/bulk": {Summary: "Apply operations to many tasks", Tag: "Tasks", Request: models.BulkTaskInput{}, Response: struct {
+		Results []models.BulkTaskResult `json:"results"`
+	}{}},
+	"GET /api/v1/tasks/:id":                              {Summary: "Get a task", Tag: "Tasks", Query: []string{"render"}, Response: models.Task{}},
+	"PATCH /api/v1/tasks/:id":                            {Summary: "Update a task", Tag: "Tasks", Request: models.UpdateTaskInput{}, Response: models.Task{}},
+	"DELETE /api/v1/tasks/:id":                           {Summary: "Move a task to the trash", Tag: "Tasks", Status: http.StatusNoContent},
+	"POST /api/v1/tasks/:id/restore":                     {Summary: "Restore a task from the trash", Tag: "Tasks", Response: models.Task{}},
+	"POST /api/v1/tasks/:id/archive":                     {Summary: "Archive a task", Tag: "Tasks", Response: models.Task{}},
+	"POST /api/v1/tasks/:id/unarchive":                   {Summary: "Unarchive a task", Tag: "Tasks", Response: models.Task{}},
+	"POST /api/v1/tasks/:id/snooze":                      {Summary: "Snooze a task", Tag: "Tasks", Request: models.SnoozeTaskInput{}, Response: models.Task{}},
+	"DELETE /api/v1/tasks/:id/snooze":                    {Summary: "End a task's snooze", Tag: "Tasks", Response: models.Task{}},
+	"POST /api/v1/tasks/:id/reorder":                     {Summary: "Move a task in the manual order", Tag: "Tasks", Request: models.ReorderTaskInput{}, Response: models.Task{}},
+	"POST /api/v1/tasks/:id/subtasks":                    {Summary: "Create a subtask", Tag: "Tasks", Query: []string{"check_duplicates"}, Request: models.CreateTaskInput{}, Response: models.Task{}, Status: http.StatusCreated},
+	"GET /api/v1/tasks/:id/tree":                         {Summary: "Get a task with its subtasks", Tag: "Tasks", Response: models.TaskNode{}},
+	"POST /api/v1/tasks/:id/dependencies":                {Summary: "Mark a task as blocked by another", Tag: "Tasks", Request: models.AddDependencyInput{}, Response: models.TaskDependencies{}, Status: http.StatusCreated},
+	"DELETE /api/v1/tasks/:id/dependencies/:blockedById": {Summary: "Remove a dependency", Tag: "Tasks", Status: http.StatusNoContent},
+	"GET /api/v1/tasks/:id/activity": {Summary: "List a task's activity", Tag: "Tasks", Query: []string{"limit", "cursor"}, Response: struct {
+		Activity []models.Activity `json:"activity"`
+		PageInfo api.PageInfo      `json:"pageInfo"`
+	}{}},
+	"GET /api/v1/tasks/:id/assignees/history": {Summary: "List the changes of a task's owner, oldest first", Tag: "Tasks", Response: struct {
+		History []models.AssigneeChange `json:"history"`
+	}{}},
+
+	"POST /api/v1/tasks/:id/comments": {Summary: "Comment on a task", Tag: "Comments", Query: []string{"render"}, Request: models.CommentInput{}, Response: models.Comment{}, Status: http.StatusCreated},
+	"GET /api/v1/tasks/:id/comments": {Summary: "List a task's comments, with the pinned ones first on the first page", Tag: "Comments", Query: []string{"limit", "cursor", "render"}, Response: struct {
+		Pinned   []models.Comment `json:"pinned"`
+		Comments []models.Comment `json:"comments"`
+		PageInfo api.PageInfo     `json:"pageInfo"`
+	}{}},
+	"GET /api/v1/tasks/:id/comments/tree": {Summary: "Get a task's comments as threads, with replies nested", Tag: "Comments", Query: []string{"render"}, Response: struct {
+		Threads   []models.CommentThread `json:"threads"`
+		Truncated bool                   `json:"truncated"`
+	}{}},
+	"PATCH /api/v1/tasks/:id/comments/:commentId":  {Summary: "Edit a comment", Tag: "Comments", Query: []string{"render"}, Request: models.CommentInput{}, Response: models.Comment{}},
+	"DELETE /api/v1/tasks/:id/comments/:commentId": {Summary: "Delete a comment", Tag: "Comments", Status: http.StatusNoContent},
+	"GET /api/v1/tasks/:id/comments/:commentId/history": {Summary: "List a comment's edits", Tag: "Comments", Response: struct {
+		Revisions []models.CommentRevision `json:"revisions"`
+	}{}},
+	"POST /api/v1/tasks/:id/comments/:commentId/pin":   {Summary: "Pin a comment to its task", Tag: "Comments", Response: models.Comment{}},
+	"DELETE /api/v1/tasks/:id/comments/:commentId/pin": {Summary: "Unpin a comment", Tag: "Comments", Response: models.Comment{}},
+
+	"POST /api/v1/tasks/:id/timer/start":  {Summary: "Start a timer on a task", Tag: "Time tracking", Request: models.StartTimerInput{}, Response: models.TimeEntry{}, Status: http.StatusCreated},
+	"POST /api/v1/tasks/:id/timer/stop":   {Summary: "Stop the timer on a task", Tag: "Time tracking", Response: models.TimeEntry{}},
+	"GET /api/v1/me/timer":                {Summary: "Get the running timer", Tag: "Time tracking", Response: models.TimeEntry{}},
+	"POST /api/v1/tasks/:id/time-entries": {Summary: "Log time on a task", Tag: "Time tracking", Request: models.CreateTimeEntryInput{}, Response: models.TimeEntry{}, Status: http.StatusCreated},
+	"GET /api/v1/tasks/:id/time-entries": {Summary: "List the time logged on a task", Tag: "Time tracking", Query: []string{"limit", "cursor"}, Response: struct {
+		TimeEntries []models.TimeEntry `json:"timeEntries"`
+		Totals      models.TimeTotals  `json:"totals"`
+		PageInfo    api.PageInfo       `json:"pageInfo"`
+	}{}},
+	"PATCH /api/v1/tasks/:id/time-entries/:entryId":  {Summary: "Edit a time entry", Tag: "Time tracking", Request: models.UpdateTimeEntryInput{}, Response: models.TimeEntry{}},
+	"DELETE /api/v1/tasks/:id/time-entries/:entryId": {Summary: "Delete a time entry", Tag: "Time tracking", Status: http.StatusNoContent},
+	"GET /api/v1/projects/:id/time":                  {Summary: "Total the time logged on a project", Tag: "Time tracking", Response: models.TimeTotals{}},
+	"GET /api/v1/time-entries/export":                {Summary: "Export time entries as CSV", Tag: "Time tracking", Query: []string{"from", "to", "projectId", "userId"}},
+
+	"POST /api/v1/views": {Summary: "Save a view", Tag: "Views", Request: models.CreateViewInput{}, Response: models.View{}, Status: http.StatusCreated},
+	"GET /api/v1/views": {Summary: "List your views and the org's shared ones", Tag: "Views", Response: struct {
+		Views []models.View `json:"views"`
+	}{}},
+	"GET /api/v1/views/:id":    {Summary: "Get a view", Tag: "Views", Response: models.View{}},
+	"PATCH /api/v1/views/:id":  {Summary: "Update a view", Tag: "Views", Request: models.UpdateViewInput{}, Response: models.View{}},
+	"DELETE /api/v1/views/:id": {Summary: "Delete a view", Tag: "Views", Status: http.StatusNoContent},
+	"GET /api/v1/views/:id/tasks": {Summary: "List the tasks a view matches", Tag: "Views", Query: []string{"limit", "cursor", "render"}, Response: struct {
+		Tasks    []models.Task `json:"tasks"`
+		PageInfo api.PageInfo  `json:"pageInfo"`
+	}{}, Conditional: true},
+
+	"POST /api/v1/templates": {Summary: "Save a task template", Tag: "Templates", Request: models.CreateTemplateInput{}, Response: models.TaskTemplate{}, Status: http.StatusCreated},
+	"GET /api/v1/templates": {Summary: "List task templates", Tag: "Templates", Response: struct {
+		Templates []models.TaskTemplate `json:"templates"`
+	}{}},
+	"GET /api/v1/templates/:id":              {Summary: "Get a task template", Tag: "Templates", Response: models.TaskTemplate{}},
+	"PATCH /api/v1/templates/:id":            {Summary: "Update a task template", Tag: "Templates", Request: models.UpdateTemplateInput{}, Response: models.TaskTemplate{}},
+	"DELETE /api/v1/templates/:id":           {Summary: "Delete a task template", Tag: "Templates", Status: http.StatusNoContent},
+	"POST /api/v1/templates/:id/instantiate": {Summary: "Create tasks from a template", Tag: "Templates", Request: models.InstantiateTemplateInput{}, Response: models.TaskNode{}, Status: http.StatusCreated},
+
+	"POST /api/v1/project-templates": {Summary: "Save a project template", Tag: "Templates", Request: models.CreateProjectTemplateInput{}, Response: models.ProjectTemplate{}, Status: http.StatusCreated},
+	"GET /api/v1/project-templates": {Summary: "List the org's project templates", Tag: "Templates", Response: struct {
+		Templates []models.ProjectTemplate `json:"templates"`
+	}{}},
+	"GET /api/v1/project-templates/:id":              {Summary: "Get a project template", Tag: "Templates", Response: models.ProjectTemplate{}},
+	"PATCH /api/v1/project-templates/:id":            {Summary: "Update a project template", Tag: "Templates", Request: models.UpdateProjectTemplateInput{}, Response: models.ProjectTemplate{}},
+	"DELETE /api/v1/project-templates/:id":           {Summary: "Delete a project template", Tag: "Templates", Status: http.StatusNoContent},
+	"POST /api/v1/project-templates/:id/instantiate": {Summary: "Create a project from a template", Tag: "Templates", Request: models.InstantiateProjectTemplateInput{}, Response: models.SeededProject{}, Status: http.StatusCreated},
+
+	"POST /api/v1/projects/:id/boards": {Summary: "Create a board on a project", Tag: "Boards", Request: models.CreateBoardInput{}, Response: models.Board{}, Status: http.StatusCreated},
+	"GET /api/v1/projects/:id/boards": {Summary: "List a project's boards", Tag: "Boards", Response: struct {
+		Boards []models.Board `json:"boards"`
+	}{}},
+	"GET /api/v1/boards/:id":       {Summary: "Get a board with its cards", Tag: "Boards", Response: models.Board{}},
+	"PATCH /api/v1/boards/:id":     {Summary: "Rename a board or change its columns", Tag: "Boards", Request: models.UpdateBoardInput{}, Response: models.Board{}},
+	"DELETE /api/v1/boards/:id":    {Summary: "Delete a board", Tag: "Boards", Status: http.StatusNoContent},
+	"POST /api/v1/boards/:id/move": {Summary: "Move a card to a column and position", Tag: "Boards", Request: models.MoveCardInput{}, Response: models.BoardMove{}},
+
+	"POST /api/v1/pomodoros": {Summary: "Start a pomodoro", Tag: "Pomodoros", Request: models.StartPomodoroInput{}, Response: models.Pomodoro{}, Status: http.StatusCreated},
+	"GET /api/v1/pomodoros": {Summary: "List pomodoros", Tag: "Pomodoros", Query: []string{"limit", "cursor"}, Response: struct {
+		Pomodoros []models.Pomodoro `json:"pomodoros"`
+		PageInfo  api.PageInfo      `json:"pageInfo"`
+	}{}},
+	"GET /api/v1/pomodoros/current":           {Summary: "Get the running pomodoro", Tag: "Pomodoros", Response: models.Pomodoro{}},
+	"POST /api/v1/pomodoros/:id/complete":     {Summary: "Complete a pomodoro", Tag: "Pomodoros", Response: models.Pomodoro{}},
+	"POST /api/v1/pomodoros/:id/interrupt":    {Summary: "Interrupt a pomodoro", Tag: "Pomodoros", Response: models.Pomodoro{}},
+	"GET /api/v1/stats/pomodoro":              {Summary: "Get pomodoro totals and streaks", Tag: "Stats", Response: models.PomodoroStats{}},
+	"GET /api/v1/stats/completed":             {Summary: "Count tasks completed per day or week", Tag: "Stats", Query: []string{"interval", "from", "to"}, Response: models.StatsSeries{}},
+	"GET /api/v1/stats/overdue":               {Summary: "Count overdue tasks at the end of each day or week", Tag: "Stats", Query: []string{"interval", "from", "to"}, Response: models.StatsSeries{}},
+	"GET /api/v1/stats/throughput":            {Summary: "Count tasks each org member completed", Tag: "Stats", Query: []string{"from", "to"}, Response: models.ThroughputStats{}},
+	"GET /api/v1/stats/projects/:id/burndown": {Summary: "Get a project's burndown", Tag: "Stats", Query: []string{"interval", "from", "to"}, Response: models.BurndownStats{}},
+
+	"POST /api/v1/tasks/:id/attachments/uploads": {Summary: "Start an attachment upload", Tag: "Attachments", Request: models.CreateAttachmentInput{}, Status: http.StatusCreated, Response: struct {
+		Attachment models.Attachment `json:"attachment"`
+		Upload     struct {
+			Method    string            `json:"method"`
+			URL       string            `json:"url"`
+			Headers   map[string]string `json:"headers"`
+			ExpiresAt time.Time         `json:"expiresAt"`
+		} `json:"upload"`
+	}{}},
+	"POST /api/v1/tasks/:id/attachments/:attachmentId/confirm": {Summary: "Confirm an upload finished", Tag: "Attachments", Response: models.Attachment{}},
+	"GET /api/v1/tasks/:id/attachments": {Summary: "List a task's attachments", Tag: "Attachments", Response: struct {
+		Attachments []models.Attachment `json:"attachments"`
+	}{}},
+	"GET /api/v1/tasks/:id/attachments/:attachmentId/download": {Summary: "Redirect to an attachment's download URL", Tag: "Attachments", Status: http.StatusFound},
+	"DELETE /api/v1/tasks/:id/attachments/:attachmentId":       {Summary: "Delete an attachment", Tag: "Attachments", Status: http.StatusNoContent},
+
+	"POST /api/v1/tasks/:id/reminders": {Summary: "Set a reminder", Tag: "Reminders", Request: models.CreateReminderInput{}, Response: models.Reminder{}, Status: http.StatusCreated},
+	"GET /api/v1/tasks/:id/reminders": {Summary: "List a task's reminders", Tag: "Reminders", Response: struct {
+		Reminders []models.Reminder `json:"reminders"`
+	}{}},
+	"DELETE /api/v1/tasks/:id/reminders/:reminderId": {Summary: "Delete a reminder", Tag: "Reminders", Status: http.StatusNoContent},
+
+	"POST /api/v1/tasks/:id/shares": {Summary: "Share a task with a member", Tag: "Sharing", Request: models.ShareInput{}, Response: models.Share{}},
+	"GET /api/v1/tasks/:id/shares": {Summary: "List who a task is shared with", Tag: "Sharing", Response: struct {
+		Shares []models.Share `json:"shares"`
+	}{}},
+	"DELETE /api/v1/tasks/:id/shares/:userId": {Summary: "Stop sharing a task with a member", Tag: "Sharing", Status: http.StatusNoContent},
+	"POST /api/v1/tasks/:id/share-links":      {Summary: "Create a public link to a task", Tag: "Sharing", Request: models.CreateShareLinkInput{}, Response: models.ShareLink{}, Status: http.StatusCreated},
+	"GET /api/v1/tasks/:id/share-links": {Summary: "List a task's public links", Tag: "Sharing", Response: struct {
+		ShareLinks []models.ShareLink `json:"shareLinks"`
+	}{}},
+	"DELETE /api/v1/share-links/:id": {Summary: "Revoke a public link", Tag: "Sharing", Status: http.StatusNoContent},
+	"GET /api/v1/share-links/:id/accesses": {Summary: "List opens of a public link", Tag: "Sharing", Query: []string{"limit", "cursor"}, Response: struct {
+		Accesses []models.ShareLinkAccess `json:"accesses"`
+		PageInfo api.PageInfo             `json:"pageInfo"`
+	}{}},
+
+	"POST /api/v1/tasks/:id/labels":            {Summary: "Attach a label", Tag: "Labels", Request: models.AttachLabelInput{}, Status: http.StatusNoContent},
+	"DELETE /api/v1/tasks/:id/labels/:labelId": {Summary: "Detach a label", Tag: "Labels", Status: http.StatusNoContent},
+	"POST /api/v1/labels":                      {Summary: "Create a label", Tag: "Labels", Request: models.CreateLabelInput{}, Response: models.Label{}, Status: http.StatusCreated},
+	"GET /api/v1/labels": {Summary: "List labels", Tag: "Labels", Response: struct {
+		Labels []models.Label `json:"labels"`
+	}{}},
+	"PATCH /api/v1/labels/:id":  {Summary: "Update a label", Tag: "Labels", Request: models.UpdateLabelInput{}, Response: models.Label{}},
+	"DELETE /api/v1/labels/:id": {Summary: "Delete a label", Tag: "Labels", Status: http.StatusNoContent},
+
+	"POST /api/v1/custom-fields": {Summary: "Define a custom field", Tag: "Custom fields", Request: models.CreateCustomFieldInput{}, Response: models.CustomField{}, Status: http.StatusCreated},
+	"GET /api/v1/custom-fields": {Summary: "List the org's custom fields", Tag: "Custom fields", Response: struct {
+		CustomFields []models.CustomField `json:"customFields"`
+	}{}},
+	"PATCH /api/v1/custom-fields/:id":  {Summary: "Rename a custom field or change its options", Tag: "Custom fields", Request: models.UpdateCustomFieldInput{}, Response: models.CustomField{}},
+	"DELETE /api/v1/custom-fields/:id": {Summary: "Delete a custom field and its values", Tag: "Custom fields", Status: http.StatusNoContent},
+
+	"POST /api/v1/webhooks": {Summary: "Register a webhook endpoint", Tag: "Webhooks", Request: models.CreateWebhookInput{}, Response: models.WebhookEndpoint{}, Status: http.StatusCreated},
+	"GET /api/v1/webhooks": {Summary: "List the org's webhook endpoints", Tag: "Webhooks", Response: struct {
+		Webhooks []models.WebhookEndpoint `json:"webhooks"`
+	}{}},
+	"PATCH /api/v1/webhooks/:id":  {Summary: "Change or pause a webhook endpoint", Tag: "Webhooks", Request: models.UpdateWebhookInput{}, Response: models.WebhookEndpoint{}},
+	"DELETE /api/v1/webhooks/:id": {Summary: "Delete a webhook endpoint", Tag: "Webhooks", Status: http.StatusNoContent},
+	"GET /api/v1/webhooks/:id/deliveries": {Summary: "List a webhook endpoint's delivery attempts", Tag: "Webhooks", Query: []string{"limit", "cursor"}, Response: struct {
+		Deliveries []models.WebhookDelivery `json:"deliveries"`
+		PageInfo   api.PageInfo             `json:"pageInfo"`
+		Breaker    models.WebhookBreaker    `json:"breaker"`
+	}{}},
+
+	"GET /api/v1/search": {Summary: "Search tasks, projects and comments", Tag: "Search", Query: []string{"q", "limit"}, Response: struct {
+		Results []models.SearchResult `json:"results"`
+	}{}},
+	"GET /api/v1/suggest": {Summary: "Suggest labels, members or projects for a picker", Tag: "Search", Query: []string{"type", "q", "limit"}, Response: struct {
+		Suggestions []models.Suggestion `json:"suggestions"`
+	}{}},
+	"POST /api/v1/import":                {Summary: "Import a Todoist, Trello or Asana export", Tag: "Import", Response: models.Import{}, Status: http.StatusAccepted},
+	"GET /api/v1/import/:id":             {Summary: "Get an import's progress", Tag: "Import", Response: models.Import{}},
+	"POST /api/v1/tasks/import/validate": {Summary: "Check what importing an export would do, without importing it", Tag: "Import", Response: models.ImportReport{}},
+	"GET /api/v1/export":                 {Summary: "Export every task as CSV or JSON", Tag: "Tasks", Query: []string{"format"}, Response: []handlers.ExportedTask{}},
+	"GET /api/v1/graphql":                {Summary: "Run a GraphQL query", Tag: "GraphQL", Query: []string{"query", "operationName", "variables"}},
+	"POST /api/v1/graphql": {Summary: "Run a GraphQL query", Tag: "GraphQL", Request: struct {
+		Query         string         `json:"query"`
+		OperationName string         `json:"operationName,omitempty"`
+		Variables     map[string]any `json:"variables,omitempty"`
+	}{}},
+	"GET /api/v1/trash": {Summary: "List the trash", Tag: "Trash", Response: struct {
+		Tasks    []models.Task    `json:"tasks"`
+		Projects []models.Project `json:"projects"`
+	}{}},
+	"GET /api/v1/recurrence/preview": {Summary: "Preview a recurrence rule", Tag: "Tasks", Query: []string{"rule", "start", "count"}, Response: struct {
+		Occurrences []time.Time `json:"occurrences"`
+	}{}},
+	"POST /api/v1/sync/push": {Summary: "Push offline changes", Tag: "Sync", Request: models.SyncPushInput{}, Response: struct {
+		Results    []models.SyncResult `json:"results"`
+		Checkpoint int64               `json:"checkpoint"`
+	}{}},
+	"POST /api/v1/batch": {Summary: "Run several reads in one request", Tag: "Batch", Request: models.BatchInput{}, Response: struct {
+		Responses []models.BatchResponse `json:"responses"`
+	}{}},
+	"GET /api/v1/sync": {Summary: "List tasks, projects and labels changed since a cursor", Tag: "Sync", Query: []string{"since", "limit"}, Response: models.Changes{}},
+	"GET /api/v1/sync/pull": {Summary: "Pull changes since a checkpoint", Tag: "Sync", Query: []string{"since", "limit"}, Response: struct {
+		Ops        []models.SyncOp `json:"ops"`
+		Checkpoint int64           `json:"checkpoint"`
+		HasMore    bool            `json:"hasMore"`
+	}{}},
+
+	"POST /api/v1/projects": {Summary: "Create a project", Tag: "Projects", Request: models.CreateProjectInput{}, Response: models.Project{}, Status: http.StatusCreated},
+	"GET /api/v1/projects": {Summary: "List projects", Tag: "Projects", Query: []string{"archived", "limit", "cursor"}, Response: struct {
+		Projects []models.Project `json:"projects"`
+		PageInfo api.PageInfo     `json:"pageInfo"`
+	}{}, Conditional: true},
+	"PATCH /api/v1/projects/:id":          {Summary: "Rename a project", Tag: "Projects", Request: models.RenameProjectInput{}, Response: models.Project{}},
+	"DELETE /api/v1/projects/:id":         {Summary: "Move a project to the trash", Tag: "Projects", Status: http.StatusNoContent},
+	"POST /api/v1/projects/:id/restore":   {Summary: "Restore a project from the trash", Tag: "Projects", Response: models.Project{}},
+	"POST /api/v1/projects/:id/archive":   {Summary: "Archive a project", Tag: "Projects", Response: models.Project{}},
+	"POST /api/v1/projects/:id/unarchive": {Summary: "Unarchive a project", Tag: "Projects", Response: models.Project{}},
+	"POST /api/v1/projects/:id/shares":    {Summary: "Share a project with a member", Tag: "Sharing", Request: models.ShareInput{}, Response: models.Share{}},
+	"GET /api/v1/projects/:id/shares": {Summary: "List who a project is shared with", Tag: "Sharing", Response: struct {
+		Shares []models.Share `json:"shares"`
+	}{}},
+	"DELETE /api/v1/projects/:id/shares/:userId": {Summary: "Stop sharing a project with a member", Tag: "Sharing", Status: http.StatusNoContent},
+	"POST /api/v1/projects/:id/share-links":      {Summary: "Create a public link to a project", Tag: "Sharing", Request: models.CreateShareLinkInput{}, Response: models.ShareLink{}, Status: http.StatusCreated},
+	"GET /api/v1/projects/:id/share-links": {Summary: "List a project's public links", Tag: "Sharing", Response: struct {
+		ShareLinks []models.ShareLink `json:"shareLinks"`
+	}{}},
+	"POST /api/v1/projects/:id/invitations": {Summary: "Invite a guest to a project", Tag: "Invitations", Request: models.CreateInvitationInput{}, Response: models.Invitation{}, Status: http.StatusCreated},
+	"GET /api/v1/projects/:id/invitations": {Summary: "List a project's invitations", Tag: "Invitations", Response: struct {
+		Invitations []models.Invitation `json:"invitations"`
+	}{}},
+	"DELETE /api/v1/projects/:id/invitations/:invitationId": {Summary: "Revoke an invitation", Tag: "Invitations", Status: http.StatusNoContent},
+	"POST /api/v1/invitations/accept":                       {Summary: "Accept an invitation", Tag: "Invitations", Request: models.AcceptInvitationInput{}, Response: models.Invitation{}},
+
+	"GET /api/v1/users/:id":     {Summary: "Get a user", Tag: "Organization", Response: models.User{}},
+	"GET /api/v1/orgs/workload": {Summary: "Sum up each member's open, estimated and overdue tasks", Tag: "Organization", Query: []string{"from", "to"}, Response: models.WorkloadStats{}},
+	"GET /api/v1/orgs/usage":    {Summary: "Get the org's usage against its plan limits", Tag: "Organization", Response: models.OrgUsage{}},
+	"GET /api/v1/orgs/limits":   {Summary: "Get the org's rate limit and usage against its plan limits", Tag: "Organization", Response: models.OrgLimits{}},
+	"GET /api/v1/orgs/members": {Summary: "List the org's members", Tag: "Organization", Query: []string{"limit", "cursor"}, Response: struct {
+		Members  []models.OrgMember `json:"members"`
+		PageInfo api.PageInfo       `json:"pageInfo"`
+	}{}},
+	"GET /api/v1/orgs/activity": {Summary: "List the org's activity", Tag: "Organization", Query: []string{"limit", "cursor"}, Response: struct {
+		Activity []models.Activity `json:"activity"`
+		PageInfo api.PageInfo      `json:"pageInfo"`
+	}{}},
+	"GET /api/v1/orgs/admin/members": {Summary: "List members with their task counts", Tag: "Organization", Query: []string{"limit", "cursor"}, Response: struct {
+		Members  []models.AdminMember `json:"members"`
+		PageInfo api.PageInfo         `json:"pageInfo"`
+	}{}},
+	"POST /api/v1/orgs/admin/members/:userId/transfer": {Summary: "Transfer a user's tasks to another member", Tag: "Organization", Request: models.TransferTasksInput{}, Response: struct {
+		Reassigned []models.ReassignedTask `json:"reassigned"`
+	}{}},
+	"POST /api/v1/orgs/admin/members/:userId/deactivate": {Summary: "Remove a member from the org", Tag: "Organization", Request: models.DeactivateMemberInput{}, Response: struct {
+		Reassigned []models.ReassignedTask `json:"reassigned"`
+	}{}},
+	"POST /api/v1/orgs/admin/tasks/reassign": {Summary: "Reassign tasks to a member", Tag: "Organization", Request: models.ReassignTasksInput{}, Response: struct {
+		Reassigned []models.ReassignedTask `json:"reassigned"`
+	}{}},
+	"GET /api/v1/orgs/settings":            {Summary: "Get org settings", Tag: "Organization", Response: models.OrgSettings{}},
+	"PATCH /api/v1/orgs/settings":          {Summary: "Update org settings", Tag: "Organization", Request: models.UpdateOrgSettingsInput{}, Response: models.OrgSettings{}},
+	"GET /api/v1/orgs/settings/statuses":   {Summary: "Get the org's workflow", Tag: "Organization", Response: models.Workflow{}},
+	"PUT /api/v1/orgs/settings/statuses":   {Summary: "Set the org's statuses", Tag: "Organization", Request: models.SetStatusesInput{}, Response: models.Workflow{}},
+	"GET /api/v1/orgs/settings/priorities": {Summary: "Get the org's workflow", Tag: "Organization", Response: models.Workflow{}},
+	"PUT /api/v1/orgs/settings/priorities": {Summary: "Set the org's priority levels", Tag: "Organization", Request: models.SetPrioritiesInput{}, Response: models.Workflow{}},
+	"GET /api/v1/orgs/settings/slack":      {Summary: "Get the org's Slack integration", Tag: "Organization", Response: models.SlackIntegration{}},
+	"PUT /api/v1/orgs/settings/slack":      {Summary: "Connect Slack or change its channels", Tag: "Organization", Request: models.SetSlackIntegrationInput{}, Response: models.SlackIntegration{}},
+	"DELETE /api/v1/orgs/settings/slack":   {Summary: "Disconnect Slack", Tag: "Organization", Status: http.StatusNoContent},
+
+	"GET /api/v1/notifications": {Summary: "List notifications", Tag: "Notifications", Query: []string{"unread", "limit", "cursor"}, Response: struct {
+		Notifications []models.Notification `json:"notifications"`
+		PageInfo      api.PageInfo          `json:"pageInfo"`
+	}{}},
+	"GET /api/v1/notifications/unread-count": {Summary: "Count unread notifications", Tag: "Notifications", Response: struct {
+		Unread int `json:"unread"`
+	}{}},
+	"POST /api/v1/notifications/read-all": {Summary: "Mark every notification read", Tag: "Notifications", Response: struct {
+		Updated int `json:"updated"`
+	}{}},
+	"PATCH /api/v1/notifications/:id": {Summary: "Mark a notification read or unread", Tag: "Notifications", Request: models.MarkNotificationInput{}, Response: models.Notification{}},
+
+	"GET /api/v1/events": {Summary: "Stream events (server-sent events)", Tag: "Realtime"},
+	"GET /api/v1/ws":     {Summary: "Stream events (WebSocket)", Tag: "Realtime"},
+
+	"POST /api/v1/service/tasks": {Summary: "Create a task as a service", Tag: "Service", Request: models.CreateTaskInput{}, Response: models.Task{}, Status: http.StatusCreated},
+	"GET /api/v1/service/tasks": {Summary: "List tasks as a service", Tag: "Service", Query: []string{"projectId", "status", "limit", "cursor"}, Response: struct {
+		Tasks    []models.Task `json:"tasks"`
+		PageInfo api.PageInfo  `json:"pageInfo"`
+	}{}},
+	"GET /api/v1/service/tasks/:id":   {Summary: "Get a task as a service", Tag: "Service", Response: models.Task{}},
+	"PATCH /api/v1/service/tasks/:id": {Summary: "Update a task as a service", Tag: "Service", Request: models.UpdateTaskInput{}, Response: models.Task{}},
+	"GET /api/v1/service/projects": {Summary: "List projects as a service", Tag: "Service", Response: struct {
+		Projects []models.Project `json:"projects"`
+		PageInfo api.PageInfo     `json:"pageInfo"`
+	}{}},
+}
@@ -0,0 +1,611 @@
+// Package server builds the HTTP API: the router with its middleware and
+// every route, around a store and the services passed in. It doesn't
+// listen or connect to anything itself, so tests can serve it from an
+// httptest.Server over store.NewMemory().
+package server
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"time"
+	"yata/apps/server/internal/account"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/errorreport"
+	"yata/apps/server/internal/events"
+	"yata/apps/server/internal/flags"
+	"yata/apps/server/internal/gcal"
+	"yata/apps/server/internal/graph"
+	"yata/apps/server/internal/handlers"
+	"yata/apps/server/internal/invitations"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/maintenance"
+	"yata/apps/server/internal/members"
+	"yata/apps/server/internal/mentions"
+	"yata/apps/server/internal/metrics"
+	"yata/apps/server/internal/middlewares"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/openapi"
+	"yata/apps/server/internal/origins"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/ratelimit"
+	"yata/apps/server/internal/realtime"
+	"yata/apps/server/internal/servicetokens"
+	"yata/apps/server/internal/sharelinks"
+	"yata/apps/server/internal/slack"
+	"yata/apps/server/internal/storage"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/webhooks"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+)
+
+// Deps are the services the routes use besides the store. Broker, Queue
+// and Flags are required. A nil Limiter limits in memory and a nil
+// Reporter reports nothing; the routes that need Files or ServiceTokens
+// are left out without them.
+type Deps struct {
+	Broker        *events.Broker
+	Queue         jobs.Queue
+	Flags         *flags.Flags
+	Files         storage.Storage
+	Limiter       ratelimit.Limiter
+	Reporter      errorreport.Reporter
+	ServiceTokens *servicetokens.Issuer
+	// SessionKeys verify Clerk session tokens; without them the keys are
+	// only loaded as tokens need them.
+	SessionKeys *middlewares.SessionKeys
+	// Readiness are the checks /readyz runs, by name.
+	Readiness map[string]handlers.ReadinessCheck
+	// Collectors are registered on /metrics next to the request metrics.
+	Collectors []prometheus.Collector
+	// Authenticate, if set, replaces the Clerk and API token check on the
+	// /api/v1 routes, so tests can sign requests in as users of their own:
+	// it should set session claims the way ClerkAuthMiddleware does, or an
+	// auth.User with auth.WithUser.
+	Authenticate gin.HandlerFunc
+}
+
+// New builds the API. Errors are config the routes can't be set up with.
+// Plan limits are enforced by db, so it should be wrapped with
+// quota.WithQuotas as it is for every other way in.
+func New(cfg *config.Config, db store.Store, deps Deps) (http.Handler, error) {
+	var limiter ratelimit.Limiter = ratelimit.NewMemory()
+	if deps.Limiter != nil {
+		limiter = deps.Limiter
+	}
+	var reporter errorreport.Reporter = errorreport.Nop{}
+	if deps.Reporter != nil {
+		reporter = deps.Reporter
+	}
+
+	// Notifications raised by requests are delivered by the job worker.
+	notifier := notify.QueuedNotifier{Queue: deps.Queue}
+	mentionDirectory := mentions.ClerkDirectory{}
+
+	eraser := account.Eraser{Accounts: db.Accounts(), Files: deps.Files}
+	attachmentLimits := handlers.AttachmentLimits{MaxSize: cfg.ATTACHMENT_MAX_SIZE, AllowedTypes: cfg.ATTACHMENT_ALLOWED_TYPES}
+	quotas := quota.New(db.Usage(), quota.LimitsFrom(cfg))
+
+	// Public share links need a stable key to sign their tokens with.
+	var shareLinkURLs handlers.ShareLinkURLs
+	if cfg.SHARE_LINK_SECRET != "" {
+		signer, err := sharelinks.NewSigner(cfg.SHARE_LINK_SECRET)
+		if err != nil {
+			return nil, fmt.Errorf("configuring share links: %w", err)
+		}
+		shareLinkURLs.Signer = signer
+		shareLinkURLs.BaseURL = cfg.SHARE_LINK_BASE_URL
+	}
+
+	rateLimits := map[string]ratelimit.Rule{}
+	for group, raw := range cfg.RateLimits() {
+		rule, err := ratelimit.ParseRule(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RATE_LIMITS: %w", err)
+		}
+		rateLimits[group] = rule
+	}
+
+	// Guest invitations link back to the web app, so they need to know where
+	// it is.
+	var guestInvitations handlers.Invitations
+	if cfg.APP_URL != "" {
+		guestInvitations = handlers.Invitations{Sender: invitations.ClerkSender{}, AppURL: cfg.APP_URL}
+	}
+
+	router := gin.New()
+	router.Use(otelgin.Middleware("yata-api", otelgin.WithFilter(func(r *http.Request) bool {
+		switch r.URL.Path {
+		case "/metrics", "/healthz", "/readyz":
+			return false
+		}
+		return true
+	})))
+	router.Use(middlewares.RequestLogger(), middlewares.Recover(reporter))
+
+	router.Use(middlewares.RequestID(cfg.REQUEST_ID_FORMAT))
+	router.Use(middlewares.Localize(db.UserSettings()))
+	router.Use(middlewares.Metrics())
+
+	recentErrors := middlewares.NewRecentErrors(cfg.RECENT_ERRORS_SIZE)
+	router.Use(middlewares.RecordRecentErrors(recentErrors))
+	router.Use(middlewares.Errors(apierror.RetryPolicy{Base: cfg.RETRY_AFTER_BASE, Jitter: cfg.RETRY_AFTER_JITTER}))
+	// The timeouts are added to a copy, so the caller's config is left as
+	// it was and may have none.
+	routeTimeouts := map[string]time.Duration{}
+	maps.Copy(routeTimeouts, cfg.ROUTE_TIMEOUTS)
+	// Routes are matched under their version, including ones configured
+	// before there was one.
+	middlewares.VersionRouteKeys(routeTimeouts, "v1")
+	// The event stream and socket stay open for as long as the client is listening.
+	routeTimeouts["GET /api/v1/events"] = 0
+	routeTimeouts["GET /api/v1/ws"] = 0
+	// Exports stream every task or time entry; give them longer unless
+	// configured.
+	for _, route := range []string{"GET /api/v1/export", "GET /api/v1/time-entries/export"} {
+		if _, ok := routeTimeouts[route]; !ok {
+			routeTimeouts[route] = 5 * time.Minute
+		}
+	}
+	// CPU profiles and traces run for as long as ?seconds= asks.
+	routeTimeouts["GET /debug/pprof/*name"] = 0
+	router.Use(middlewares.Timeout(cfg.REQUEST_TIMEOUT, routeTimeouts))
+
+	if cfg.DB_SLOW_LOG_ENABLED {
+		router.Use(middlewares.LogSlowQueries(cfg.DB_SLOW_LOG_THRESHOLD))
+	}
+
+	allowedOrigins, err := origins.New(cfg.ALLOWED_ORIGINS, cfg.ALLOWED_ORIGIN_PATTERNS)
+	if err != nil {
+		return nil, fmt.Errorf("configuring allowed origins: %w", err)
+	}
+	corsConfig := cors.Config{
+		AllowMethods:     []string{"GET", "POST", "PATCH", "PUT", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Authorization", "Content-Type", "If-Match", "If-None-Match", middlewares.IdempotencyKeyHeader, middlewares.RequestIDHeader, middlewares.APIVersionHeader, handlers.TimezoneHeader, "traceparent", "tracestate"},
+		ExposeHeaders:    []string{"Content-Length", "ETag", middlewares.IdempotentReplayedHeader, middlewares.RequestIDHeader, "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", middlewares.APIVersionHeader, "Deprecation", "Sunset", "Link"},
+		AllowCredentials: true,
+	}
+	if allowedOrigins.AllowsAny() {
+		corsConfig.AllowAllOrigins = true
+	} else {
+		corsConfig.AllowOriginFunc = allowedOrigins.Allows
+	}
+	router.Use(cors.New(corsConfig))
+	maintenanceSwitch := maintenance.New(deps.Flags, cfg.MAINTENANCE_MODE)
+	router.Use(middlewares.Maintenance(maintenanceSwitch, cfg.MAINTENANCE_RETRY_AFTER, "/healthz", "/readyz", "/version", "/metrics", "/admin/", "/debug/"))
+
+	router.GET("/healthz", handlers.HealthzHandler())
+	router.GET("/readyz", handlers.ReadyzHandler(deps.Readiness))
+	router.GET("/version", handlers.VersionHandler())
+
+	sessionCheck := middlewares.SessionCheck{Keys: deps.SessionKeys, ClockSkew: cfg.CLERK_CLOCK_SKEW, AuthorizedParties: cfg.CLERK_AUTHORIZED_PARTIES}
+	if sessionCheck.Keys == nil {
+		sessionCheck.Keys = middlewares.NewSessionKeys()
+	}
+	sessionAuth, authenticate := middlewares.ClerkAuthMiddleware(sessionCheck), middlewares.Authenticate(sessionCheck, db.APITokens(), db.Users())
+	currentUser := middlewares.CurrentUser(db.Users())
+	if deps.Authenticate != nil {
+		sessionAuth, authenticate = deps.Authenticate, deps.Authenticate
+	}
+	router.GET("/api/v1/events", middlewares.TokenFromQuery(), sessionAuth, currentUser, handlers.EventsHandler(deps.Broker, db.Tasks()))
+	router.GET("/api/v1/ws", handlers.WebSocketHandler(realtime.NewHub(deps.Broker, db.Tasks(), allowedOrigins, sessionCheck)))
+
+	if cfg.CLERK_WEBHOOK_SECRET != "" {
+		verifier, err := webhooks.NewSvixVerifier(cfg.CLERK_WEBHOOK_SECRET)
+		if err != nil {
+			return nil, fmt.Errorf("configuring the Clerk webhook: %w", err)
+		}
+		router.POST("/webhooks/clerk", handlers.ClerkWebhookHandler(db.Users(), eraser, verifier))
+	}
+
+	if shareLinkURLs.Signer != nil {
+		router.GET("/share/:token", middlewares.RateLimit(limiter, "public", rateLimits["public"]), middlewares.Compress(cfg.COMPRESSION_MIN_SIZES["public"]), handlers.PublicShareHandler(handlers.PublicShareStores{Links: db.ShareLinks(), Tasks: db.Tasks(), Projects: db.Projects(), Users: db.Users()}, shareLinkURLs.Signer))
+	}
+	calendarFeedURLs := handlers.CalendarFeedURLs{BaseURL: cfg.SHARE_LINK_BASE_URL, AppURL: cfg.APP_URL}
+	customFieldStores := handlers.CustomFieldStores{Fields: db.CustomFields(), Users: db.Users()}
+	var googleCalendar *gcal.Client
+	if cfg.GOOGLE_CLIENT_ID != "" {
+		googleCalendar = gcal.NewClient(gcal.Config{
+			ClientID:     cfg.GOOGLE_CLIENT_ID,
+			ClientSecret: cfg.GOOGLE_CLIENT_SECRET,
+			RedirectURL:  cfg.GOOGLE_REDIRECT_URL,
+		})
+		router.GET("/integrations/google-calendar/callback", middlewares.RateLimit(limiter, "public", rateLimits["public"]), handlers.GoogleCalendarCallbackHandler(googleCalendar, db.GoogleCalendar(), cfg.APP_URL))
+	}
+	if cfg.SLACK_SIGNING_SECRET != "" {
+		router.POST("/integrations/slack/commands", middlewares.RateLimit(limiter, "public", rateLimits["public"]), handlers.SlackCommandHandler(cfg.SLACK_SIGNING_SECRET, slack.NewClient(), handlers.SlackCommandStores{
+			Integrations: db.Slack(),
+			Users:        db.Users(),
+			Tasks:        db.Tasks(),
+			Workflows:    db.Workflows(),
+			OrgSettings:  db.OrgSettings(),
+		}, cfg.APP_URL))
+	}
+	if cfg.INBOUND_EMAIL_DOMAIN != "" {
+		router.POST("/integrations/inbound-email/mailgun", middlewares.RateLimit(limiter, "public", rateLimits["public"]), handlers.MailgunInboundHandler(cfg.MAILGUN_WEBHOOK_SIGNING_KEY, handlers.InboundEmail{
+			Domain: cfg.INBOUND_EMAIL_DOMAIN,
+			Stores: handlers.InboundEmailStores{
+				Addresses:   db.InboundAddresses(),
+				Users:       db.Users(),
+				Tasks:       db.Tasks(),
+				Projects:    db.Projects(),
+				Workflows:   db.Workflows(),
+				OrgSettings: db.OrgSettings(),
+				Attachments: db.Attachments(),
+			},
+			Files:  deps.Files,
+			Limits: attachmentLimits,
+		}))
+	}
+	if cfg.TELEGRAM_BOT_TOKEN != "" {
+		router.POST("/integrations/telegram/webhook", middlewares.RateLimit(limiter, "public", rateLimits["public"]), handlers.TelegramWebhookHandler(cfg.TELEGRAM_WEBHOOK_SECRET, handlers.TelegramStores{
+			Links:       db.Telegram(),
+			Users:       db.Users(),
+			Tasks:       db.Tasks(),
+			Workflows:   db.Workflows(),
+			OrgSettings: db.OrgSettings(),
+		}, cfg.APP_URL))
+	}
+	router.GET("/feeds/:token/tasks.ics", middlewares.RateLimit(limiter, "public", rateLimits["public"]), middlewares.Compress(cfg.COMPRESSION_MIN_SIZES["public"]), handlers.CalendarFeedHandler(handlers.CalendarFeedStores{
+		Feeds:     db.CalendarFeeds(),
+		Users:     db.Users(),
+		Tasks:     db.Tasks(),
+		Workflows: db.Workflows(),
+	}, calendarFeedURLs))
+
+	apiGroup := router.Group("/api/v1")
+	apiGroup.Use(middlewares.Compress(cfg.COMPRESSION_MIN_SIZES["api"]))
+	apiGroup.Use(authenticate, currentUser)
+	apiGroup.Use(middlewares.OrgRateLimit(limiter, "api", rateLimits["api"], db.OrgSettings()))
+	// Suspended orgs can still see who they are and take their data out.
+	apiGroup.Use(middlewares.RequireActiveOrg(db.Users(), cfg.SUSPENDED_ORGS_BLOCK_READS,
+		"/api/v1/me", "/api/v1/me/export", "/api/v1/me/export/:id", "/api/v1/export", "/api/v1/time-entries/export"))
+	apiGroup.Use(middlewares.Idempotency(db.Idempotency(), cfg.IDEMPOTENCY_TTL))
+	{
+		apiGroup.GET("/me", handlers.GetMeHandler(db.Users()))
+		apiGroup.DELETE("/me", middlewares.RequireSession(), handlers.DeleteAccountHandler(eraser, account.ClerkDeleter{}))
+		if deps.Files != nil {
+			apiGroup.POST("/me/export", middlewares.RequireSession(), handlers.CreateDataExportHandler(db.Accounts(), deps.Queue))
+			apiGroup.GET("/me/export/:id", handlers.GetDataExportHandler(db.Accounts(), deps.Files))
+		}
+		apiGroup.GET("/me/settings", handlers.GetUserSettingsHandler(db.UserSettings()))
+		apiGroup.PATCH("/me/settings", handlers.UpdateUserSettingsHandler(db.UserSettings(), db.Projects()))
+		apiGroup.GET("/me/email-preferences", handlers.GetEmailPreferencesHandler(db.EmailPreferences()))
+		apiGroup.PATCH("/me/email-preferences", handlers.UpdateEmailPreferencesHandler(db.EmailPreferences()))
+		apiGroup.POST("/me/push-subscriptions", handlers.CreatePushSubscriptionHandler(db.PushSubscriptions()))
+		apiGroup.DELETE("/me/push-subscriptions", handlers.DeletePushSubscriptionHandler(db.PushSubscriptions()))
+		apiGroup.GET("/me/timer", handlers.GetRunningTimerHandler(db.TimeEntries()))
+		apiGroup.GET("/me/calendar-feed", handlers.GetCalendarFeedHandler(db.CalendarFeeds()))
+		apiGroup.POST("/me/calendar-feed", middlewares.RequireSession(), handlers.RotateCalendarFeedHandler(db.CalendarFeeds(), calendarFeedURLs))
+		apiGroup.DELETE("/me/calendar-feed", handlers.DeleteCalendarFeedHandler(db.CalendarFeeds()))
+		if googleCalendar != nil {
+			apiGroup.POST("/me/google-calendar/connect", middlewares.RequireSession(), handlers.ConnectGoogleCalendarHandler(googleCalendar))
+			apiGroup.GET("/me/google-calendar", handlers.GetGoogleCalendarHandler(db.GoogleCalendar()))
+			apiGroup.DELETE("/me/google-calendar", handlers.DisconnectGoogleCalendarHandler(googleCalendar, db.GoogleCalendar()))
+		}
+		if cfg.INBOUND_EMAIL_DOMAIN != "" {
+			apiGroup.GET("/me/inbound-addresses", handlers.ListInboundAddressesHandler(db.InboundAddresses(), cfg.INBOUND_EMAIL_DOMAIN))
+			apiGroup.POST("/me/inbound-addresses", handlers.RotateInboundAddressHandler(db.InboundAddresses(), db.Projects(), cfg.INBOUND_EMAIL_DOMAIN))
+			apiGroup.DELETE("/me/inbound-addresses/:id", handlers.DeleteInboundAddressHandler(db.InboundAddresses()))
+		}
+		if cfg.TELEGRAM_BOT_TOKEN != "" {
+			apiGroup.POST("/me/telegram/link", middlewares.RequireSession(), handlers.CreateTelegramLinkHandler(db.Telegram(), cfg.TELEGRAM_BOT_USERNAME))
+			apiGroup.GET("/me/telegram", handlers.GetTelegramLinkHandler(db.Telegram()))
+			apiGroup.DELETE("/me/telegram", handlers.DeleteTelegramLinkHandler(db.Telegram()))
+		}
+		apiGroup.POST("/me/tokens", middlewares.RequireSession(), handlers.CreateAPITokenHandler(db.APITokens()))
+		apiGroup.GET("/me/tokens", middlewares.RequireSession(), handlers.ListAPITokensHandler(db.APITokens()))
+		apiGroup.DELETE("/me/tokens/:id", middlewares.RequireSession(), handlers.DeleteAPITokenHandler(db.APITokens()))
+		apiGroup.GET("/push/vapid-public-key", handlers.GetVAPIDPublicKeyHandler(cfg.VAPID_PUBLIC_KEY))
+
+		apiGroup.POST("/tasks", handlers.CreateTaskHandler(db.Tasks(), db.Projects(), db.Workflows(), db.OrgSettings(), db.Search(), customFieldStores))
+		apiGroup.GET("/tasks", handlers.ListTasksHandler(db.Tasks(), db.Labels(), db.Workflows(), db.CustomFields()))
+		apiGroup.POST("/tasks/quick", handlers.QuickAddTaskHandler(db.Labels(), db.Workflows(), db.UserSettings()))
+		apiGroup.POST("/tasks/bulk", handlers.BulkTasksHandler(db.Tasks(), db.Projects(), db.Labels(), db.Workflows()))
+		apiGroup.GET("/tasks/:id", handlers.GetTaskHandler(db.Tasks(), db.Labels(), db.Workflows()))
+		apiGroup.PATCH("/tasks/:id", handlers.UpdateTaskHandler(db.Tasks(), db.Projects(), db.Workflows(), customFieldStores))
+		apiGroup.DELETE("/tasks/:id", handlers.DeleteTaskHandler(db.Tasks()))
+		apiGroup.POST("/tasks/:id/restore", handlers.RestoreTaskHandler(db.Trash()))
+		apiGroup.POST("/tasks/:id/archive", handlers.ArchiveTaskHandler(db.Tasks(), true))
+		apiGroup.POST("/tasks/:id/unarchive", handlers.ArchiveTaskHandler(db.Tasks(), false))
+		apiGroup.POST("/tasks/:id/snooze", handlers.SnoozeTaskHandler(db.Tasks(), db.UserSettings()))
+		apiGroup.DELETE("/tasks/:id/snooze", handlers.UnsnoozeTaskHandler(db.Tasks()))
+		apiGroup.POST("/tasks/:id/reorder", handlers.ReorderTaskHandler(db.Tasks()))
+		apiGroup.GET("/tasks/:id/activity", handlers.ListTaskActivityHandler(db.Activity()))
+		apiGroup.GET("/tasks/:id/assignees/history", handlers.AssigneeHistoryHandler(db.Tasks(), db.Activity(), db.Users()))
+		apiGroup.POST("/tasks/:id/comments", handlers.CreateCommentHandler(db.Comments(), db.Tasks(), mentionDirectory, notifier))
+		apiGroup.GET("/tasks/:id/comments", handlers.ListCommentsHandler(db.Comments()))
+		apiGroup.GET("/tasks/:id/comments/tree", handlers.CommentTreeHandler(db.Comments()))
+		apiGroup.PATCH("/tasks/:id/comments/:commentId", handlers.UpdateCommentHandler(db.Comments(), db.Tasks(), mentionDirectory, notifier))
+		apiGroup.DELETE("/tasks/:id/comments/:commentId", handlers.DeleteCommentHandler(db.Comments()))
+		apiGroup.GET("/tasks/:id/comments/:commentId/history", handlers.CommentHistoryHandler(db.Comments()))
+		apiGroup.POST("/tasks/:id/comments/:commentId/pin", handlers.PinCommentHandler(db.Comments(), db.Tasks(), true))
+		apiGroup.DELETE("/tasks/:id/comments/:commentId/pin", handlers.PinCommentHandler(db.Comments(), db.Tasks(), false))
+		apiGroup.POST("/tasks/:id/timer/start", handlers.StartTimerHandler(db.TimeEntries()))
+		apiGroup.POST("/tasks/:id/timer/stop", handlers.StopTimerHandler(db.TimeEntries()))
+		apiGroup.POST("/tasks/:id/time-entries", handlers.CreateTimeEntryHandler(db.TimeEntries()))
+		apiGroup.GET("/tasks/:id/time-entries", handlers.ListTimeEntriesHandler(db.TimeEntries()))
+		apiGroup.PATCH("/tasks/:id/time-entries/:entryId", handlers.UpdateTimeEntryHandler(db.TimeEntries()))
+		apiGroup.DELETE("/tasks/:id/time-entries/:entryId", handlers.DeleteTimeEntryHandler(db.TimeEntries()))
+		if deps.Files != nil {
+			apiGroup.POST("/tasks/:id/attachments/uploads", handlers.RequestUploadHandler(db.Attachments(), deps.Files, attachmentLimits))
+			apiGroup.POST("/tasks/:id/attachments/:attachmentId/confirm", handlers.ConfirmUploadHandler(db.Attachments(), deps.Files, attachmentLimits))
+			apiGroup.GET("/tasks/:id/attachments", handlers.ListAttachmentsHandler(db.Attachments()))
+			apiGroup.GET("/tasks/:id/attachments/:attachmentId/download", handlers.DownloadAttachmentHandler(db.Attachments(), deps.Files))
+			apiGroup.DELETE("/tasks/:id/attachments/:attachmentId", handlers.DeleteAttachmentHandler(db.Attachments(), deps.Files))
+		}
+		apiGroup.POST("/tasks/:id/subtasks", handlers.CreateSubtaskHandler(db.Tasks(), db.Projects(), db.Workflows(), db.OrgSettings(), db.Search(), customFieldStores))
+		apiGroup.GET("/tasks/:id/tree", handlers.GetTaskTreeHandler(db.Tasks(), db.Workflows()))
+		apiGroup.POST("/tasks/:id/dependencies", handlers.AddTaskDependencyHandler(db.Tasks()))
+		apiGroup.DELETE("/tasks/:id/dependencies/:blockedById", handlers.RemoveTaskDependencyHandler(db.Tasks()))
+		apiGroup.POST("/tasks/:id/reminders", handlers.CreateReminderHandler(db.Reminders()))
+		apiGroup.GET("/tasks/:id/reminders", handlers.ListRemindersHandler(db.Reminders()))
+		apiGroup.DELETE("/tasks/:id/reminders/:reminderId", handlers.DeleteReminderHandler(db.Reminders()))
+		apiGroup.POST("/tasks/:id/shares", middlewares.RequireOrg(), handlers.ShareTaskHandler(db.Shares(), db.Tasks(), db.Users()))
+		apiGroup.GET("/tasks/:id/shares", middlewares.RequireOrg(), handlers.ListTaskSharesHandler(db.Shares()))
+		apiGroup.DELETE("/tasks/:id/shares/:userId", middlewares.RequireOrg(), handlers.UnshareTaskHandler(db.Shares(), db.Tasks()))
+		if shareLinkURLs.Signer != nil {
+			apiGroup.POST("/tasks/:id/share-links", middlewares.RejectGuests(), handlers.CreateTaskShareLinkHandler(db.ShareLinks(), shareLinkURLs))
+			apiGroup.GET("/tasks/:id/share-links", middlewares.RejectGuests(), handlers.ListTaskShareLinksHandler(db.ShareLinks(), shareLinkURLs))
+			apiGroup.DELETE("/share-links/:id", middlewares.RejectGuests(), handlers.RevokeShareLinkHandler(db.ShareLinks()))
+			apiGroup.GET("/share-links/:id/accesses", middlewares.RejectGuests(), handlers.ListShareLinkAccessesHandler(db.ShareLinks()))
+		}
+		apiGroup.POST("/tasks/:id/labels", middlewares.RequireOrg(), handlers.AttachLabelHandler(db.Labels()))
+		apiGroup.DELETE("/tasks/:id/labels/:labelId", middlewares.RequireOrg(), handlers.DetachLabelHandler(db.Labels()))
+
+		apiGroup.GET("/search", handlers.SearchHandler(db.Search()))
+		apiGroup.GET("/suggest", middlewares.RequireOrg(), middlewares.RejectGuests(), handlers.SuggestHandler(db.Search()))
+		apiGroup.POST("/import", middlewares.RejectGuests(), handlers.CreateImportHandler(db.Imports(), deps.Queue))
+		apiGroup.POST("/tasks/import/validate", middlewares.RejectGuests(), handlers.ValidateImportHandler())
+		apiGroup.GET("/import/:id", handlers.GetImportHandler(db.Imports()))
+		apiGroup.GET("/export", handlers.ExportTasksHandler(handlers.ExportStores{
+			Tasks:    db.Tasks(),
+			Projects: db.Projects(),
+			Labels:   db.Labels(),
+			Comments: db.Comments(),
+		}))
+		apiGroup.POST("/pomodoros", handlers.StartPomodoroHandler(db.Pomodoros()))
+		apiGroup.GET("/pomodoros", handlers.ListPomodorosHandler(db.Pomodoros()))
+		apiGroup.GET("/pomodoros/current", handlers.GetRunningPomodoroHandler(db.Pomodoros()))
+		apiGroup.POST("/pomodoros/:id/complete", handlers.CompletePomodoroHandler(db.Pomodoros()))
+		apiGroup.POST("/pomodoros/:id/interrupt", handlers.InterruptPomodoroHandler(db.Pomodoros()))
+		apiGroup.GET("/stats/pomodoro", handlers.PomodoroStatsHandler(db.Pomodoros(), db.UserSettings()))
+		apiGroup.GET("/stats/completed", handlers.CompletedStatsHandler(db.Stats(), db.UserSettings()))
+		apiGroup.GET("/stats/overdue", handlers.OverdueStatsHandler(db.Stats(), db.UserSettings()))
+		apiGroup.GET("/stats/throughput", middlewares.RequireOrg(), middlewares.RejectGuests(), handlers.ThroughputStatsHandler(db.Stats(), db.UserSettings()))
+		apiGroup.GET("/stats/projects/:id/burndown", middlewares.RequireOrg(), middlewares.RejectGuests(), handlers.BurndownStatsHandler(db.Stats(), db.Projects(), db.UserSettings()))
+		apiGroup.GET("/time-entries/export", handlers.ExportTimeEntriesHandler(handlers.TimeExportStores{
+			Entries:  db.TimeEntries(),
+			Projects: db.Projects(),
+			Users:    db.Users(),
+		}))
+		graphQL := handlers.GraphQLHandler(&graph.Resolver{
+			Tasks:    db.Tasks(),
+			Projects: db.Projects(),
+			Labels:   db.Labels(),
+			Comments: db.Comments(),
+			Users:    db.Users(),
+		})
+		apiGroup.GET("/graphql", graphQL)
+		apiGroup.POST("/graphql", graphQL)
+		apiGroup.GET("/trash", handlers.ListTrashHandler(db.Trash()))
+		apiGroup.GET("/recurrence/preview", handlers.PreviewRecurrenceHandler())
+		apiGroup.POST("/sync/push", handlers.SyncPushHandler(db.Sync(), db.Projects(), db.Workflows()))
+		apiGroup.GET("/sync/pull", handlers.SyncPullHandler(db.Sync()))
+		apiGroup.POST("/batch", handlers.BatchHandler(router, apiGroup.BasePath()))
+		apiGroup.GET("/sync", handlers.SyncChangesHandler(db.Changes(), db.Labels(), cfg.CHANGE_LOG_RETENTION))
+
+		projects := apiGroup.Group("/projects")
+		projects.Use(middlewares.RequireOrg(), middlewares.RejectGuests())
+		{
+			projects.POST("", handlers.CreateProjectHandler(db.Projects()))
+			projects.GET("", handlers.ListProjectsHandler(db.Projects()))
+			projects.PATCH("/:id", handlers.RenameProjectHandler(db.Projects()))
+			projects.DELETE("/:id", middlewares.RequirePermission(middlewares.PermDeleteProject), handlers.DeleteProjectHandler(db.Trash()))
+			projects.POST("/:id/restore", handlers.RestoreProjectHandler(db.Trash()))
+			projects.POST("/:id/archive", handlers.ArchiveProjectHandler(db.Projects(), true))
+			projects.POST("/:id/unarchive", handlers.ArchiveProjectHandler(db.Projects(), false))
+			projects.POST("/:id/shares", middlewares.RequirePermission(middlewares.PermManageProjectShares), handlers.ShareProjectHandler(db.Shares(), db.Users()))
+			projects.GET("/:id/time", handlers.ProjectTimeHandler(db.TimeEntries(), db.Projects()))
+			projects.POST("/:id/boards", handlers.CreateBoardHandler(db.Boards(), db.Workflows()))
+			projects.GET("/:id/boards", handlers.ListProjectBoardsHandler(db.Boards(), db.Projects()))
+			projects.GET("/:id/shares", handlers.ListProjectSharesHandler(db.Shares()))
+			projects.DELETE("/:id/shares/:userId", middlewares.RequirePermission(middlewares.PermManageProjectShares), handlers.UnshareProjectHandler(db.Shares()))
+			if shareLinkURLs.Signer != nil {
+				projects.POST("/:id/share-links", handlers.CreateProjectShareLinkHandler(db.ShareLinks(), shareLinkURLs))
+				projects.GET("/:id/share-links", handlers.ListProjectShareLinksHandler(db.ShareLinks(), shareLinkURLs))
+			}
+			if guestInvitations.Sender != nil {
+				projects.POST("/:id/invitations", middlewares.RequirePermission(middlewares.PermInviteGuests), handlers.CreateInvitationHandler(db.Invitations(), db.Projects(), guestInvitations))
+				projects.GET("/:id/invitations", middlewares.RequirePermission(middlewares.PermInviteGuests), handlers.ListInvitationsHandler(db.Invitations()))
+				projects.DELETE("/:id/invitations/:invitationId", middlewares.RequirePermission(middlewares.PermInviteGuests), handlers.RevokeInvitationHandler(db.Invitations(), guestInvitations))
+			}
+		}
+
+		apiGroup.POST("/views", handlers.CreateViewHandler(db.Views(), db.Labels(), db.Workflows(), db.CustomFields()))
+		apiGroup.GET("/views", handlers.ListViewsHandler(db.Views()))
+		apiGroup.GET("/views/:id", handlers.GetViewHandler(db.Views()))
+		apiGroup.PATCH("/views/:id", handlers.UpdateViewHandler(db.Views(), db.Labels(), db.Workflows(), db.CustomFields()))
+		apiGroup.DELETE("/views/:id", handlers.DeleteViewHandler(db.Views()))
+		apiGroup.GET("/views/:id/tasks", handlers.ViewTasksHandler(db.Views(), db.Tasks(), db.Labels(), db.Workflows(), db.CustomFields()))
+
+		templates := apiGroup.Group("/templates")
+		templates.Use(middlewares.RejectGuests())
+		{
+			templates.POST("", handlers.CreateTemplateHandler(db.Templates(), db.Tasks(), db.Labels()))
+			templates.GET("", handlers.ListTemplatesHandler(db.Templates()))
+			templates.GET("/:id", handlers.GetTemplateHandler(db.Templates()))
+			templates.PATCH("/:id", handlers.UpdateTemplateHandler(db.Templates()))
+			templates.DELETE("/:id", handlers.DeleteTemplateHandler(db.Templates()))
+			templates.POST("/:id/instantiate", handlers.InstantiateTemplateHandler(db.Templates(), db.Tasks(), db.Projects(), db.Labels(), db.Workflows(), db.OrgSettings()))
+		}
+
+		projectTemplates := apiGroup.Group("/project-templates")
+		projectTemplates.Use(middlewares.RequireOrg(), middlewares.RejectGuests())
+		{
+			projectTemplates.POST("", handlers.CreateProjectTemplateHandler(db.ProjectTemplates(), db.Projects(), db.Tasks(), db.Labels()))
+			projectTemplates.GET("", handlers.ListProjectTemplatesHandler(db.ProjectTemplates()))
+			projectTemplates.GET("/:id", handlers.GetProjectTemplateHandler(db.ProjectTemplates()))
+			projectTemplates.PATCH("/:id", handlers.UpdateProjectTemplateHandler(db.ProjectTemplates()))
+			projectTemplates.DELETE("/:id", handlers.DeleteProjectTemplateHandler(db.ProjectTemplates()))
+			projectTemplates.POST("/:id/instantiate", handlers.InstantiateProjectTemplateHandler(handlers.ProjectTemplateStores{
+				Templates:   db.ProjectTemplates(),
+				Projects:    db.Projects(),
+				Labels:      db.Labels(),
+				Users:       db.Users(),
+				Workflows:   db.Workflows(),
+				OrgSettings: db.OrgSettings(),
+				Quotas:      quotas,
+			}))
+		}
+
+		boards := apiGroup.Group("/boards")
+		boards.Use(middlewares.RequireOrg(), middlewares.RejectGuests())
+		{
+			boards.GET("/:id", handlers.GetBoardHandler(db.Boards()))
+			boards.PATCH("/:id", handlers.UpdateBoardHandler(db.Boards(), db.Workflows()))
+			boards.DELETE("/:id", handlers.DeleteBoardHandler(db.Boards()))
+			boards.POST("/:id/move", handlers.MoveCardHandler(db.Boards(), db.Tasks(), db.Workflows()))
+		}
+
+		labels := apiGroup.Group("/labels")
+		labels.Use(middlewares.RequireOrg(), middlewares.RejectGuests())
+		{
+			labels.POST("", handlers.CreateLabelHandler(db.Labels(), db.OrgSettings()))
+			labels.GET("", handlers.ListLabelsHandler(db.Labels()))
+			labels.PATCH("/:id", handlers.UpdateLabelHandler(db.Labels(), db.OrgSettings()))
+			labels.DELETE("/:id", handlers.DeleteLabelHandler(db.Labels()))
+		}
+
+		apiGroup.GET("/users/:id", handlers.GetUserHandler(db.Users()))
+		if guestInvitations.Sender != nil {
+			apiGroup.POST("/invitations/accept", middlewares.RequireOrg(), handlers.AcceptInvitationHandler(db.Invitations(), db.Users()))
+		}
+		apiGroup.GET("/orgs/members", middlewares.RequireOrg(), middlewares.RejectGuests(), handlers.ListOrgMembersHandler(db.Users()))
+		apiGroup.GET("/orgs/workload", middlewares.RequireOrg(), middlewares.RejectGuests(), handlers.WorkloadHandler(db.Stats(), db.UserSettings()))
+		apiGroup.GET("/orgs/usage", middlewares.RequireOrg(), middlewares.RejectGuests(), handlers.GetOrgUsageHandler(quotas))
+		apiGroup.GET("/orgs/limits", middlewares.RequireOrg(), middlewares.RejectGuests(), handlers.GetOrgLimitsHandler(quotas))
+		apiGroup.GET("/orgs/activity", middlewares.RequireOrg(), middlewares.RequirePermission(middlewares.PermReadOrgActivity), handlers.ListOrgActivityHandler(db.Activity()))
+
+		orgAdmin := apiGroup.Group("/orgs/admin")
+		orgAdmin.Use(middlewares.RequireOrg(), middlewares.RequirePermission(middlewares.PermManageMembers))
+		{
+			orgAdmin.GET("/members", handlers.ListAdminMembersHandler(db.Users(), db.OrgAdmin()))
+			orgAdmin.POST("/members/:userId/transfer", handlers.TransferMemberTasksHandler(db.Users(), db.OrgAdmin()))
+			orgAdmin.POST("/members/:userId/deactivate", handlers.DeactivateMemberHandler(db.Users(), db.OrgAdmin(), db.ShareLinks(), members.ClerkRemover{}))
+			orgAdmin.POST("/tasks/reassign", handlers.ReassignTasksHandler(db.Users(), db.OrgAdmin()))
+		}
+
+		customFields := apiGroup.Group("/custom-fields")
+		customFields.Use(middlewares.RequireOrg(), middlewares.RejectGuests())
+		{
+			customFields.GET("", handlers.ListCustomFieldsHandler(db.CustomFields()))
+			customFields.POST("", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.CreateCustomFieldHandler(db.CustomFields()))
+			customFields.PATCH("/:id", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.UpdateCustomFieldHandler(db.CustomFields()))
+			customFields.DELETE("/:id", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.DeleteCustomFieldHandler(db.CustomFields()))
+		}
+
+		orgWebhooks := apiGroup.Group("/webhooks")
+		orgWebhooks.Use(middlewares.RequireOrg(), middlewares.RejectGuests(), middlewares.RequirePermission(middlewares.PermManageOrgSettings))
+		{
+			orgWebhooks.GET("", handlers.ListWebhooksHandler(db.Webhooks()))
+			orgWebhooks.POST("", handlers.CreateWebhookHandler(db.Webhooks()))
+			orgWebhooks.PATCH("/:id", handlers.UpdateWebhookHandler(db.Webhooks()))
+			orgWebhooks.DELETE("/:id", handlers.DeleteWebhookHandler(db.Webhooks()))
+			orgWebhooks.GET("/:id/deliveries", handlers.ListWebhookDeliveriesHandler(db.Webhooks()))
+		}
+
+		settings := apiGroup.Group("/orgs/settings")
+		settings.Use(middlewares.RequireOrg(), middlewares.RejectGuests())
+		{
+			settings.GET("", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.GetOrgSettingsHandler(db.OrgSettings()))
+			settings.PATCH("", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.UpdateOrgSettingsHandler(db.OrgSettings()))
+			settings.GET("/statuses", handlers.GetWorkflowHandler(db.Workflows()))
+			settings.PUT("/statuses", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.SetStatusesHandler(db.Workflows()))
+			settings.GET("/priorities", handlers.GetWorkflowHandler(db.Workflows()))
+			settings.PUT("/priorities", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.SetPrioritiesHandler(db.Workflows()))
+			settings.GET("/slack", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.GetSlackIntegrationHandler(db.Slack()))
+			settings.PUT("/slack", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.SetSlackIntegrationHandler(db.Slack(), slack.NewClient()))
+			settings.DELETE("/slack", middlewares.RequirePermission(middlewares.PermManageOrgSettings), handlers.DeleteSlackIntegrationHandler(db.Slack()))
+		}
+
+		notifications := apiGroup.Group("/notifications")
+		{
+			notifications.GET("", handlers.ListNotificationsHandler(db.Notifications()))
+			notifications.GET("/unread-count", handlers.UnreadNotificationCountHandler(db.Notifications()))
+			notifications.POST("/read-all", handlers.MarkAllNotificationsReadHandler(db.Notifications()))
+			notifications.PATCH("/:id", handlers.MarkNotificationHandler(db.Notifications()))
+		}
+	}
+
+	// Internal services and cron jobs get their own routes, with service
+	// tokens instead of user sessions.
+	if deps.ServiceTokens != nil {
+		service := router.Group("/api/v1/service")
+		service.Use(middlewares.Compress(cfg.COMPRESSION_MIN_SIZES["service"]))
+		service.Use(middlewares.ServiceAuth(deps.ServiceTokens), currentUser)
+		service.Use(middlewares.RateLimit(limiter, "service", rateLimits["service"]))
+		service.Use(middlewares.Idempotency(db.Idempotency(), cfg.IDEMPOTENCY_TTL))
+		{
+			service.POST("/tasks", handlers.CreateTaskHandler(db.Tasks(), db.Projects(), db.Workflows(), db.OrgSettings(), db.Search(), customFieldStores))
+			service.GET("/tasks", handlers.ListTasksHandler(db.Tasks(), db.Labels(), db.Workflows(), db.CustomFields()))
+			service.GET("/tasks/:id", handlers.GetTaskHandler(db.Tasks(), db.Labels(), db.Workflows()))
+			service.PATCH("/tasks/:id", handlers.UpdateTaskHandler(db.Tasks(), db.Projects(), db.Workflows(), customFieldStores))
+			service.GET("/projects", handlers.ListProjectsHandler(db.Projects()))
+		}
+	}
+
+	registry := metrics.NewRegistry()
+	registry.MustRegister(deps.Collectors...)
+	router.GET("/metrics",
+		middlewares.RequireMetricsAccess(cfg.METRICS_ALLOWED_IPS, cfg.METRICS_USERNAME, cfg.METRICS_PASSWORD),
+		gin.WrapH(promhttp.HandlerFor(registry, promhttp.HandlerOpts{})),
+	)
+
+	admin := router.Group("/admin")
+	admin.Use(middlewares.RequireAdminIP(cfg.ADMIN_ALLOWED_IPS))
+	{
+		admin.GET("/recent-errors", handlers.GetRecentErrorsHandler(recentErrors))
+		admin.GET("/flags", handlers.ListFlagsHandler(deps.Flags))
+		admin.GET("/maintenance", handlers.GetMaintenanceHandler(maintenanceSwitch))
+		admin.PUT("/maintenance", handlers.SetMaintenanceHandler(maintenanceSwitch))
+		admin.PUT("/flags/:key", handlers.SetFlagHandler(deps.Flags))
+		admin.DELETE("/flags/:key", handlers.DeleteFlagHandler(deps.Flags))
+		admin.PUT("/orgs/:orgId/rate-limit", handlers.SetOrgRateLimitHandler(db.OrgSettings()))
+		admin.PUT("/orgs/:orgId/status", handlers.SetOrgStatusHandler(db.Users()))
+	}
+
+	if cfg.ENABLE_DEBUG {
+		debug := router.Group("/debug")
+		debug.Use(middlewares.RequireAdminIP(cfg.ADMIN_ALLOWED_IPS))
+		{
+			debug.GET("/pprof/*name", handlers.PprofHandler())
+			debug.POST("/pprof/*name", handlers.PprofHandler())
+			debug.GET("/goroutines", handlers.GoroutineDumpHandler())
+			debug.GET("/heap", handlers.HeapDumpHandler())
+		}
+	}
+
+	// Registered last so the document covers every route above.
+	openAPI, err := handlers.OpenAPIHandler(openapi.Build(openapi.Info{Title: "yata API", Version: "v1"}, router.Routes(), "/api/v1/", apiOps))
+	if err != nil {
+		return nil, fmt.Errorf("building the OpenAPI document: %w", err)
+	}
+	router.GET("/api/openapi.json", openAPI)
+	router.GET("/api/docs", handlers.SwaggerUIHandler())
+
+	// /api/... stays an alias of /api/v1/... until clients have moved over.
+	apiVersions := middlewares.APIVersions{
+		Supported:   []string{"v1"},
+		Current:     "v1",
+		Sunset:      cfg.API_ALIAS_SUNSET,
+		Unversioned: []string{"/api/openapi.json", "/api/docs"},
+	}
+	return apiVersions.Handler(router), nil
+}
@@ -0,0 +1,911 @@
+package server_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/events"
+	"yata/apps/server/internal/flags"
+	"yata/apps/server/internal/importers"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/server"
+	"yata/apps/server/internal/server/servertest"
+	"yata/apps/server/internal/store"
+)
+
+func TestNewLeavesConfigAlone(t *testing.T) {
+	cfg := &config.Config{ROUTE_TIMEOUTS: map[string]time.Duration{"GET /api/tasks": time.Second}}
+	_, err := server.New(cfg, store.NewMemory(), server.Deps{
+		Broker: events.NewBroker(),
+		Flags:  flags.New(flags.NewMemoryStore()),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.ROUTE_TIMEOUTS) != 1 {
+		t.Errorf("ROUTE_TIMEOUTS = %v, want it left as configured", cfg.ROUTE_TIMEOUTS)
+	}
+}
+
+func TestTasks(t *testing.T) {
+	backends := []struct {
+		name  string
+		serve func(*testing.T, *config.Config) *servertest.Server
+	}{
+		{"memory", servertest.Memory},
+		{"postgres", servertest.Postgres},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			// Nothing set, so RATE_LIMITS falls back to the defaults.
+			srv := b.serve(t, nil)
+			alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+			bob := auth.User{ID: "user_bob", OrgID: "org_other", Role: "org:admin"}
+
+			if status := srv.Do(t, auth.User{}, http.MethodGet, "/api/v1/tasks", nil, nil); status != http.StatusForbidden {
+				t.Fatalf("signed out: status = %d, want %d", status, http.StatusForbidden)
+			}
+
+			var created models.Task
+			status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Write the harness"}, &created)
+			if status != http.StatusCreated {
+				t.Fatalf("create: status = %d, want %d", status, http.StatusCreated)
+			}
+			if created.ID == "" || created.Title != "Write the harness" || created.OwnerID != alice.ID {
+				t.Fatalf("created = %+v", created)
+			}
+
+			var got models.Task
+			if status := srv.Do(t, alice, http.MethodGet, "/api/v1/tasks/"+created.ID, nil, &got); status != http.StatusOK || got.ID != created.ID {
+				t.Errorf("get: status = %d, task = %+v", status, got)
+			}
+			var notFound struct {
+				Code      string `json:"code"`
+				Message   string `json:"message"`
+				RequestID string `json:"request_id"`
+			}
+			if status := srv.Do(t, bob, http.MethodGet, "/api/v1/tasks/"+created.ID, nil, &notFound); status != http.StatusNotFound {
+				t.Errorf("get from another org: status = %d, want %d", status, http.StatusNotFound)
+			}
+			if notFound.Code != "NOT_FOUND" || notFound.Message == "" || notFound.RequestID == "" {
+				t.Errorf("get from another org: body = %+v, want the error envelope", notFound)
+			}
+
+			var list struct {
+				Tasks []models.Task `json:"tasks"`
+			}
+			if status := srv.Do(t, alice, http.MethodGet, "/api/v1/tasks", nil, &list); status != http.StatusOK || len(list.Tasks) != 1 {
+				t.Errorf("list: status = %d, %d tasks, want 1", status, len(list.Tasks))
+			}
+			list.Tasks = nil
+			if status := srv.Do(t, bob, http.MethodGet, "/api/v1/tasks", nil, &list); status != http.StatusOK || len(list.Tasks) != 0 {
+				t.Errorf("list from another org: status = %d, %d tasks, want none", status, len(list.Tasks))
+			}
+		})
+	}
+}
+
+func TestSubtaskEstimatesRollUp(t *testing.T) {
+	backends := []struct {
+		name  string
+		serve func(*testing.T, *config.Config) *servertest.Server
+	}{
+		{"memory", servertest.Memory},
+		{"postgres", servertest.Postgres},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			srv := b.serve(t, nil)
+			alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+			if err := srv.Store.Users().UpsertMembership(t.Context(), models.OrgMembership{OrgID: alice.OrgID, UserID: alice.ID, Role: alice.Role, UpdatedAt: time.Now()}); err != nil {
+				t.Fatal(err)
+			}
+			due := time.Now().UTC().Add(24 * time.Hour).Format(time.RFC3339)
+
+			create := func(path string, body map[string]any) models.Task {
+				t.Helper()
+				var task models.Task
+				if status := srv.Do(t, alice, http.MethodPost, path, body, &task); status != http.StatusCreated {
+					t.Fatalf("create %v: status = %d", body["title"], status)
+				}
+				return task
+			}
+			parent := create("/api/v1/tasks", map[string]any{"title": "Launch", "dueDate": due})
+			a := create("/api/v1/tasks/"+parent.ID+"/subtasks", map[string]any{"title": "Write", "estimateMinutes": 30, "dueDate": due})
+			b := create("/api/v1/tasks/"+parent.ID+"/subtasks", map[string]any{"title": "Review"})
+			create("/api/v1/tasks/"+b.ID+"/subtasks", map[string]any{"title": "Proofread", "estimateMinutes": 45})
+
+			detail := func(id string) models.Task {
+				t.Helper()
+				var task models.Task
+				if status := srv.Do(t, alice, http.MethodGet, "/api/v1/tasks/"+id, nil, &task); status != http.StatusOK {
+					t.Fatalf("get: status = %d", status)
+				}
+				return task
+			}
+			total := func(task models.Task, want int) {
+				t.Helper()
+				if task.SubtaskEstimateTotal == nil || *task.SubtaskEstimateTotal != want {
+					t.Errorf("%s: subtaskEstimateTotal = %v, want %d", task.Title, task.SubtaskEstimateTotal, want)
+				}
+			}
+			// Review has no estimate, so Proofread's counts for it.
+			total(detail(parent.ID), 75)
+			if got := detail(a.ID); got.SubtaskEstimateTotal != nil {
+				t.Errorf("no subtasks: subtaskEstimateTotal = %d, want none", *got.SubtaskEstimateTotal)
+			}
+
+			estimate := func(id string, minutes int) {
+				t.Helper()
+				input := models.UpdateTaskInput{EstimateMinutes: models.Nullable[int]{Set: true, Value: minutes}}
+				if _, err := srv.Store.Tasks().Update(t.Context(), alice.Scope(), id, input); err != nil {
+					t.Fatal(err)
+				}
+			}
+			estimate(a.ID, 60)
+			total(detail(parent.ID), 105)
+
+			workload := func(open int, estimate int64) {
+				t.Helper()
+				var stats models.WorkloadStats
+				if status := srv.Do(t, alice, http.MethodGet, "/api/v1/orgs/workload", nil, &stats); status != http.StatusOK || len(stats.Members) != 1 {
+					t.Fatalf("workload: status = %d, members = %+v", status, stats.Members)
+				}
+				if m := stats.Members[0]; m.Open != open || m.EstimateMinutes != estimate || m.Unestimated != 0 {
+					t.Errorf("workload = %+v, want %d open at %d minutes, none unestimated", m, open, estimate)
+				}
+			}
+			// Write is due too, but it's counted in Launch's estimate.
+			workload(2, 105)
+
+			estimate(parent.ID, 20)
+			total(detail(parent.ID), 105)
+			workload(2, 80)
+		})
+	}
+}
+
+func TestAssigneeHistory(t *testing.T) {
+	backends := []struct {
+		name  string
+		serve func(*testing.T, *config.Config) *servertest.Server
+	}{
+		{"memory", servertest.Memory},
+		{"postgres", servertest.Postgres},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			srv := b.serve(t, nil)
+			ctx := t.Context()
+			alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+			for _, u := range []struct{ id, first, last string }{
+				{"user_alice", "Alice", "Admin"},
+				{"user_bob", "Bob", ""},
+				{"user_carol", "", ""},
+			} {
+				user := models.User{ID: u.id, FirstName: &u.first, LastName: &u.last, UpdatedAt: time.Now()}
+				if u.id == "user_carol" {
+					email := "carol@example.com"
+					user.Email = &email
+				}
+				if err := srv.Store.Users().UpsertUser(ctx, user); err != nil {
+					t.Fatal(err)
+				}
+				if err := srv.Store.Users().UpsertMembership(ctx, models.OrgMembership{OrgID: alice.OrgID, UserID: u.id, Role: "org:member", UpdatedAt: time.Now()}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var task models.Task
+			if status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Launch"}, &task); status != http.StatusCreated {
+				t.Fatalf("create: status = %d", status)
+			}
+
+			history := func() []models.AssigneeChange {
+				t.Helper()
+				var body struct{ History []models.AssigneeChange }
+				if status := srv.Do(t, alice, http.MethodGet, "/api/v1/tasks/"+task.ID+"/assignees/history", nil, &body); status != http.StatusOK {
+					t.Fatalf("history: status = %d", status)
+				}
+				return body.History
+			}
+			if got := history(); got == nil || len(got) != 0 {
+				t.Fatalf("never reassigned: history = %v, want an empty list", got)
+			}
+
+			record := func(actor string, changes map[string]models.FieldChange) {
+				t.Helper()
+				err := srv.Store.Activity().Record(ctx, models.CreateActivityInput{
+					OrgID:   &alice.OrgID,
+					ActorID: actor,
+					TaskID:  &task.ID,
+					Action:  models.ActivityTaskUpdated,
+					Changes: changes,
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			record("user_alice", map[string]models.FieldChange{"ownerId": {Old: "user_alice", New: "user_bob"}})
+			record("user_bob", map[string]models.FieldChange{"title": {Old: "Launch", New: "Ship"}})
+			record("user_bob", map[string]models.FieldChange{"ownerId": {Old: "user_bob", New: "user_carol"}})
+			record("user_alice", map[string]models.FieldChange{"ownerId": {Old: "user_carol", New: nil}})
+
+			name := func(p *models.Person) string {
+				if p == nil {
+					return "-"
+				}
+				return p.Name
+			}
+			want := []string{
+				"Alice Admin: Alice Admin -> Bob",
+				"Bob: Bob -> carol@example.com",
+				"Alice Admin: carol@example.com -> -",
+			}
+			got := history()
+			if len(got) != len(want) {
+				t.Fatalf("history = %+v, want %d changes", got, len(want))
+			}
+			for i, change := range got {
+				if line := change.Actor.Name + ": " + name(change.From) + " -> " + name(change.To); line != want[i] {
+					t.Errorf("change %d = %q, want %q", i, line, want[i])
+				}
+				if i > 0 && change.ChangedAt.Before(got[i-1].ChangedAt) {
+					t.Errorf("change %d is older than the one before it", i)
+				}
+			}
+
+			other := auth.User{ID: "user_mallory", OrgID: "org_other", Role: "org:admin"}
+			if status := srv.Do(t, other, http.MethodGet, "/api/v1/tasks/"+task.ID+"/assignees/history", nil, nil); status != http.StatusNotFound {
+				t.Errorf("another org: status = %d, want 404", status)
+			}
+		})
+	}
+}
+
+func TestPinnedComments(t *testing.T) {
+	backends := []struct {
+		name  string
+		serve func(*testing.T, *config.Config) *servertest.Server
+	}{
+		{"memory", servertest.Memory},
+		{"postgres", servertest.Postgres},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			srv := b.serve(t, nil)
+			admin := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+			owner := auth.User{ID: "user_bob", OrgID: "org_acme", Role: "org:member"}
+			commenter := auth.User{ID: "user_carol", OrgID: "org_acme", Role: "org:member"}
+			bystander := auth.User{ID: "user_dave", OrgID: "org_acme", Role: "org:member"}
+			for _, u := range []auth.User{admin, owner, commenter, bystander} {
+				if err := srv.Store.Users().UpsertMembership(t.Context(), models.OrgMembership{OrgID: u.OrgID, UserID: u.ID, Role: u.Role, UpdatedAt: time.Now()}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			var task models.Task
+			if status := srv.Do(t, owner, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Launch"}, &task); status != http.StatusCreated {
+				t.Fatalf("create task: status = %d", status)
+			}
+			comment := func(u auth.User, body string) models.Comment {
+				t.Helper()
+				var c models.Comment
+				if status := srv.Do(t, u, http.MethodPost, "/api/v1/tasks/"+task.ID+"/comments", map[string]any{"body": body}, &c); status != http.StatusCreated {
+					t.Fatalf("comment %q: status = %d", body, status)
+				}
+				return c
+			}
+			first := comment(commenter, "Kickoff on Monday")
+			decision := comment(owner, "Decided: ship in May")
+			comment(commenter, "Sounds good")
+
+			pin := func(u auth.User, method string, c models.Comment) int {
+				t.Helper()
+				return srv.Do(t, u, method, "/api/v1/tasks/"+task.ID+"/comments/"+c.ID+"/pin", nil, nil)
+			}
+			if status := pin(bystander, http.MethodPost, decision); status != http.StatusForbidden {
+				t.Errorf("bystander: status = %d, want 403", status)
+			}
+			for _, u := range []auth.User{owner, commenter, admin} {
+				if status := pin(u, http.MethodPost, decision); status != http.StatusOK {
+					t.Errorf("%s: status = %d, want 200", u.ID, status)
+				}
+			}
+			if status := pin(admin, http.MethodPost, first); status != http.StatusOK {
+				t.Fatalf("pin first: status = %d", status)
+			}
+
+			type listing struct {
+				Pinned   []models.Comment
+				Comments []models.Comment
+			}
+			list := func(u auth.User) listing {
+				t.Helper()
+				var body listing
+				if status := srv.Do(t, u, http.MethodGet, "/api/v1/tasks/"+task.ID+"/comments", nil, &body); status != http.StatusOK {
+					t.Fatalf("list: status = %d", status)
+				}
+				return body
+			}
+			// Pins are shared, so someone who pinned nothing sees them too.
+			got := list(bystander)
+			if len(got.Pinned) != 2 || got.Pinned[0].ID != decision.ID || got.Pinned[1].ID != first.ID {
+				t.Fatalf("pinned = %+v, want the decision, then the kickoff", got.Pinned)
+			}
+			// Pinning again doesn't take the pin over.
+			if by := got.Pinned[0].PinnedBy; by == nil || *by != owner.ID {
+				t.Errorf("pinnedBy = %v, want %s", by, owner.ID)
+			}
+			if len(got.Comments) != 3 || got.Comments[0].ID != first.ID || got.Comments[0].PinnedAt == nil {
+				t.Errorf("comments = %+v, want the whole thread in order, pins marked", got.Comments)
+			}
+
+			if status := pin(commenter, http.MethodDelete, decision); status != http.StatusOK {
+				t.Fatalf("unpin: status = %d", status)
+			}
+			got = list(owner)
+			if len(got.Pinned) != 1 || got.Pinned[0].ID != first.ID {
+				t.Errorf("after unpinning: pinned = %+v, want the kickoff alone", got.Pinned)
+			}
+
+			other := auth.User{ID: "user_mallory", OrgID: "org_other", Role: "org:admin"}
+			if status := pin(other, http.MethodPost, decision); status != http.StatusNotFound {
+				t.Errorf("another org: status = %d, want 404", status)
+			}
+		})
+	}
+}
+
+func TestCommentTree(t *testing.T) {
+	backends := []struct {
+		name  string
+		serve func(*testing.T, *config.Config) *servertest.Server
+	}{
+		{"memory", servertest.Memory},
+		{"postgres", servertest.Postgres},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			srv := b.serve(t, nil)
+			alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:member"}
+			if err := srv.Store.Users().UpsertMembership(t.Context(), models.OrgMembership{OrgID: alice.OrgID, UserID: alice.ID, Role: alice.Role, UpdatedAt: time.Now()}); err != nil {
+				t.Fatal(err)
+			}
+
+			var task models.Task
+			if status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Launch"}, &task); status != http.StatusCreated {
+				t.Fatalf("create task: status = %d", status)
+			}
+			comment := func(body string, parent *models.Comment) models.Comment {
+				t.Helper()
+				input := map[string]any{"body": body}
+				if parent != nil {
+					input["parentId"] = parent.ID
+				}
+				var c models.Comment
+				if status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks/"+task.ID+"/comments", input, &c); status != http.StatusCreated {
+					t.Fatalf("comment %q: status = %d", body, status)
+				}
+				return c
+			}
+			a := comment("A", nil)
+			b := comment("B", nil)
+			a1 := comment("A1", &a)
+			comment("B1", &b)
+			// A reply to a reply answers the top-level comment.
+			if a2 := comment("A2", &a1); a2.ParentID == nil || *a2.ParentID != a.ID {
+				t.Errorf("reply to a reply: parentId = %v, want %s", a2.ParentID, a.ID)
+			}
+			comment("C", nil)
+
+			var elsewhere models.Task
+			srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Other"}, &elsewhere)
+			if status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks/"+elsewhere.ID+"/comments", map[string]any{"body": "x", "parentId": a.ID}, nil); status != http.StatusNotFound {
+				t.Errorf("parent on another task: status = %d, want 404", status)
+			}
+
+			type tree struct {
+				Threads   []models.CommentThread
+				Truncated bool
+			}
+			get := func() tree {
+				t.Helper()
+				var body tree
+				if status := srv.Do(t, alice, http.MethodGet, "/api/v1/tasks/"+task.ID+"/comments/tree", nil, &body); status != http.StatusOK {
+					t.Fatalf("tree: status = %d", status)
+				}
+				return body
+			}
+			shape := func(threads []models.CommentThread) []string {
+				out := []string{}
+				for _, th := range threads {
+					line := th.Body + ":"
+					for _, r := range th.Replies {
+						line += " " + r.Body
+					}
+					out = append(out, line)
+				}
+				return out
+			}
+			got := get()
+			want := []string{"A: A1 A2", "B: B1", "C:"}
+			if !reflect.DeepEqual(shape(got.Threads), want) || got.Truncated {
+				t.Fatalf("tree = %v, truncated %v; want %v, not truncated", shape(got.Threads), got.Truncated, want)
+			}
+
+			// Fill the thread past the cap; the oldest comments are the ones kept.
+			for i := range 500 {
+				if _, err := srv.Store.Comments().Create(t.Context(), alice.Scope(), task.ID, models.CommentInput{Body: fmt.Sprint("more ", i)}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			got = get()
+			if !got.Truncated {
+				t.Error("oversized thread: truncated = false")
+			}
+			total := 0
+			for _, th := range got.Threads {
+				total += 1 + len(th.Replies)
+			}
+			if total != 500 || !reflect.DeepEqual(shape(got.Threads[:3]), want) {
+				t.Errorf("oversized thread: %d comments starting %v, want 500 starting %v", total, shape(got.Threads[:3]), want)
+			}
+		})
+	}
+}
+
+func TestSyncOpIDsAreScopedToTheUser(t *testing.T) {
+	backends := []struct {
+		name  string
+		serve func(*testing.T, *config.Config) *servertest.Server
+	}{
+		{"memory", servertest.Memory},
+		{"postgres", servertest.Postgres},
+	}
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			srv := b.serve(t, nil)
+			alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:member"}
+			mallory := auth.User{ID: "user_mallory", OrgID: "org_other", Role: "org:member"}
+			for _, u := range []auth.User{alice, mallory} {
+				if err := srv.Store.Users().UpsertMembership(t.Context(), models.OrgMembership{OrgID: u.OrgID, UserID: u.ID, Role: u.Role, UpdatedAt: time.Now()}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			const (
+				opID      = "0c6f2a1e-5d4b-4c3a-9e8f-7a6b5c4d3e2f"
+				otherOpID = "1d7a3b2f-6e5c-4d4b-8f9a-8b7c6d5e4f3a"
+				aliceTask = "2e8b4c3a-7f6d-4e5c-9a0b-9c8d7e6f5a4b"
+				ourTask   = "3f9c5d4b-8a7e-4f6d-8b1c-0d9e8f7a6b5c"
+			)
+			op := func(id, taskID, title string) map[string]any {
+				return map[string]any{
+					"id": id, "taskId": taskID, "type": "set", "field": "title", "value": title,
+					"timestamp": map[string]any{"wall": time.Now().UnixMilli(), "node": "n1"},
+				}
+			}
+			type pushed struct {
+				Results []models.SyncResult
+			}
+			push := func(u auth.User, ops ...map[string]any) (int, pushed) {
+				t.Helper()
+				var body pushed
+				status := srv.Do(t, u, http.MethodPost, "/api/v1/sync/push", map[string]any{"ops": ops}, &body)
+				return status, body
+			}
+
+			if status, body := push(alice, op(opID, aliceTask, "Alice's")); status != http.StatusOK || body.Results[0].Status != models.SyncApplied {
+				t.Fatalf("alice: status = %d, results = %+v", status, body.Results)
+			}
+
+			// The same op id from someone else is a new op, not a retry.
+			if status, body := push(mallory, op(opID, ourTask, "Mallory's")); status != http.StatusOK || body.Results[0].Status != models.SyncApplied {
+				t.Fatalf("same op id: status = %d, results = %+v", status, body.Results)
+			}
+			if task, err := srv.Store.Tasks().Get(t.Context(), mallory.Scope(), ourTask); err != nil || task.Title != "Mallory's" {
+				t.Errorf("same op id: task = %+v, %v; want it created", task, err)
+			}
+
+			// A task id that's taken elsewhere fails the push, and nothing in
+			// it is applied.
+			status := srv.Do(t, mallory, http.MethodPost, "/api/v1/sync/push", map[string]any{"ops": []map[string]any{
+				op(otherOpID, ourTask, "Renamed"),
+				op("4a0d6e5c-9b8f-4a7e-9c2d-1e0f9a8b7c6d", aliceTask, "Taken"),
+			}}, nil)
+			if status != http.StatusConflict {
+				t.Errorf("taken task id: status = %d, want 409", status)
+			}
+			if task, _ := srv.Store.Tasks().Get(t.Context(), mallory.Scope(), ourTask); task == nil || task.Title != "Mallory's" {
+				t.Errorf("taken task id: task = %+v, want the rest of the push rolled back", task)
+			}
+			if task, _ := srv.Store.Tasks().Get(t.Context(), alice.Scope(), aliceTask); task == nil || task.Title != "Alice's" {
+				t.Errorf("taken task id: alice's task = %+v, want it untouched", task)
+			}
+		})
+	}
+}
+
+func TestWebhookDeliveriesShowTheBreaker(t *testing.T) {
+	srv := servertest.Memory(t, nil)
+	admin := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+
+	var hook models.WebhookEndpoint
+	status := srv.Do(t, admin, http.MethodPost, "/api/v1/webhooks", map[string]any{"url": "https://example.com/hook", "events": []string{models.EventTaskCreated}}, &hook)
+	if status != http.StatusCreated {
+		t.Fatalf("create webhook: status = %d", status)
+	}
+	policy := models.WebhookBreakerPolicy{Threshold: 1, Cooldown: time.Hour, MaxCooldown: time.Hour}
+	if _, err := srv.Store.Webhooks().RecordBreakerResult(t.Context(), admin.OrgID, hook.URL, false, policy); err != nil {
+		t.Fatal(err)
+	}
+
+	var out struct {
+		Breaker models.WebhookBreaker `json:"breaker"`
+	}
+	if status := srv.Do(t, admin, http.MethodGet, "/api/v1/webhooks/"+hook.ID+"/deliveries", nil, &out); status != http.StatusOK {
+		t.Fatalf("list deliveries: status = %d", status)
+	}
+	if out.Breaker.State != models.BreakerOpen || out.Breaker.Failures != 1 || out.Breaker.OpenUntil == nil {
+		t.Errorf("breaker = %+v, want open after one failure", out.Breaker)
+	}
+}
+
+func TestShareLinksFollowTheCreatorsMembership(t *testing.T) {
+	cfg := &config.Config{SHARE_LINK_SECRET: "0123456789abcdef0123456789abcdef"}
+	srv := servertest.Memory(t, cfg)
+	alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+	bob := auth.User{ID: "user_bob", OrgID: "org_acme", Role: "org:member"}
+	ctx := t.Context()
+	for _, u := range []auth.User{alice, bob} {
+		if err := srv.Store.Users().UpsertMembership(ctx, models.OrgMembership{OrgID: u.OrgID, UserID: u.ID, Role: u.Role, UpdatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var task models.Task
+	if status := srv.Do(t, bob, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Share me"}, &task); status != http.StatusCreated {
+		t.Fatalf("create task: status = %d", status)
+	}
+	var link models.ShareLink
+	if status := srv.Do(t, bob, http.MethodPost, "/api/v1/tasks/"+task.ID+"/share-links", nil, &link); status != http.StatusCreated {
+		t.Fatalf("create link: status = %d", status)
+	}
+	if status := srv.Do(t, auth.User{}, http.MethodGet, link.URL, nil, nil); status != http.StatusOK {
+		t.Fatalf("open as a member's link: status = %d, want %d", status, http.StatusOK)
+	}
+
+	if err := srv.Store.Users().DeleteMembership(ctx, bob.OrgID, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	if status := srv.Do(t, auth.User{}, http.MethodGet, link.URL, nil, nil); status != http.StatusNotFound {
+		t.Errorf("open after the creator left: status = %d, want %d", status, http.StatusNotFound)
+	}
+	if err := srv.Store.ShareLinks().RevokeMember(ctx, bob.OrgID, bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	got, err := srv.Store.ShareLinks().Get(ctx, link.ID)
+	if err != nil || got.RevokedAt == nil {
+		t.Errorf("link after RevokeMember = %+v, %v, want it revoked", got, err)
+	}
+}
+
+func TestErrorsKeepTheEnvelopeWhenGzipped(t *testing.T) {
+	srv := servertest.Memory(t, &config.Config{COMPRESSION_MIN_SIZES: map[string]int{"api": 1}})
+	alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+
+	get := func(path string) (*http.Response, []byte) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept-Encoding", "gzip")
+		req.Header.Set(servertest.UserIDHeader, alice.ID)
+		req.Header.Set(servertest.OrgIDHeader, alice.OrgID)
+		req.Header.Set(servertest.OrgRoleHeader, alice.Role)
+		res, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		var body io.Reader = res.Body
+		if res.Header.Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(res.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			body = gz
+		}
+		b, err := io.ReadAll(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res, b
+	}
+
+	if res, _ := get("/api/v1/tasks"); res.Header.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("list: Content-Encoding = %q, want gzip", res.Header.Get("Content-Encoding"))
+	}
+
+	res, b := get("/api/v1/tasks/00000000-0000-0000-0000-000000000000")
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("get: status = %d, want %d", res.StatusCode, http.StatusNotFound)
+	}
+	var body map[string]any
+	if err := json.Unmarshal(b, &body); err != nil {
+		t.Fatalf("get: body %q isn't JSON: %v", b, err)
+	}
+	if body["code"] != "NOT_FOUND" || body["message"] == "" || body["request_id"] == nil || body["error"] != nil {
+		t.Errorf("get: body = %v, want the error envelope", body)
+	}
+}
+
+func TestOrgRateLimits(t *testing.T) {
+	srv := servertest.Memory(t, &config.Config{
+		RATE_LIMITS:       map[string]string{"api": "2/1m"},
+		ADMIN_ALLOWED_IPS: []string{"127.0.0.1", "::1"},
+	})
+	paid := auth.User{ID: "user_alice", OrgID: "org_paid", Role: "org:admin"}
+	free := auth.User{ID: "user_bob", OrgID: "org_free", Role: "org:admin"}
+
+	// list sends one request and returns its status and rate limit header.
+	list := func(as auth.User) (int, string) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, srv.URL+"/api/v1/tasks", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(servertest.UserIDHeader, as.ID)
+		req.Header.Set(servertest.OrgIDHeader, as.OrgID)
+		req.Header.Set(servertest.OrgRoleHeader, as.Role)
+		res, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		return res.StatusCode, res.Header.Get("X-RateLimit-Limit")
+	}
+
+	if status := srv.Do(t, auth.User{}, http.MethodPut, "/admin/orgs/org_paid/rate-limit", map[string]any{"rateLimit": "nope"}, nil); status != http.StatusBadRequest {
+		t.Fatalf("invalid rule: status = %d, want %d", status, http.StatusBadRequest)
+	}
+	var settings models.OrgSettings
+	if status := srv.Do(t, auth.User{}, http.MethodPut, "/admin/orgs/org_paid/rate-limit", map[string]any{"rateLimit": "5/1m"}, &settings); status != http.StatusOK {
+		t.Fatalf("set: status = %d", status)
+	}
+	if settings.RateLimit == nil || *settings.RateLimit != "5/1m" {
+		t.Fatalf("set: rateLimit = %v, want 5/1m", settings.RateLimit)
+	}
+	// Org admins can see the limit but not change it.
+	if status := srv.Do(t, paid, http.MethodPatch, "/api/v1/orgs/settings", map[string]any{"rateLimit": "1000/1s"}, &settings); status != http.StatusOK || *settings.RateLimit != "5/1m" {
+		t.Fatalf("patch: status = %d, rateLimit = %v, want it left at 5/1m", status, settings.RateLimit)
+	}
+
+	for i := range 4 {
+		if status, limit := list(paid); status != http.StatusOK || limit != "5" {
+			t.Fatalf("paid request %d: status = %d, limit = %q, want 200 under a limit of 5", i+1, status, limit)
+		}
+	}
+	for i := range 2 {
+		if status, limit := list(free); status != http.StatusOK || limit != "2" {
+			t.Fatalf("free request %d: status = %d, limit = %q, want 200 under a limit of 2", i+1, status, limit)
+		}
+	}
+	if status, _ := list(free); status != http.StatusTooManyRequests {
+		t.Errorf("free request 3: status = %d, want %d", status, http.StatusTooManyRequests)
+	}
+
+	// Clearing the limit puts the org back on the default straight away.
+	if status := srv.Do(t, auth.User{}, http.MethodPut, "/admin/orgs/org_paid/rate-limit", map[string]any{"rateLimit": nil}, nil); status != http.StatusOK {
+		t.Fatalf("clear: status = %d", status)
+	}
+	if status, limit := list(paid); status != http.StatusOK || limit != "2" {
+		t.Errorf("after clearing: status = %d, limit = %q, want 200 under a limit of 2", status, limit)
+	}
+}
+
+func TestOrgLimits(t *testing.T) {
+	srv := servertest.Memory(t, &config.Config{
+		RATE_LIMITS:                map[string]string{"api": "10/1m"},
+		ADMIN_ALLOWED_IPS:          []string{"127.0.0.1", "::1"},
+		QUOTA_MAX_TASKS:            3,
+		QUOTA_MAX_ATTACHMENT_BYTES: 100,
+	})
+	alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+
+	if status := srv.Do(t, auth.User{}, http.MethodPut, "/admin/orgs/org_acme/rate-limit", map[string]any{"rateLimit": "20/1m"}, nil); status != http.StatusOK {
+		t.Fatalf("set rate limit: status = %d", status)
+	}
+	var task models.Task
+	for _, title := range []string{"one", "two"} {
+		if status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": title}, &task); status != http.StatusCreated {
+			t.Fatalf("create %s: status = %d", title, status)
+		}
+	}
+	if status := srv.Do(t, alice, http.MethodPost, "/api/v1/webhooks", map[string]any{"url": "https://example.com/hook", "events": []string{models.EventTaskCreated}}, nil); status != http.StatusCreated {
+		t.Fatalf("create webhook: status = %d", status)
+	}
+	if _, err := srv.Store.Attachments().Create(t.Context(), alice.Scope(), task.ID, models.CreateAttachmentInput{Filename: "a.txt", ContentType: "text/plain", Size: 90, Key: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var limits models.OrgLimits
+	if status := srv.Do(t, alice, http.MethodGet, "/api/v1/orgs/limits", nil, &limits); status != http.StatusOK {
+		t.Fatalf("get: status = %d", status)
+	}
+	// Three requests came before this one, under the org's own limit.
+	if rl := limits.RateLimit; rl == nil || rl.Limit != 20 || rl.Remaining != 16 || rl.ResetAt.IsZero() {
+		t.Errorf("rateLimit = %+v, want 16 of 20 left", rl)
+	}
+	// item checks a quota, with a limit of zero for none.
+	item := func(name string, got models.UsageItem, used, limit int64) {
+		t.Helper()
+		var gotLimit int64
+		if got.Limit != nil {
+			gotLimit = *got.Limit
+		}
+		if got.Used != used || gotLimit != limit {
+			t.Errorf("%s = %d used of %d, want %d of %d", name, got.Used, gotLimit, used, limit)
+		}
+	}
+	item("storage", limits.Storage, 90, 100)
+	item("tasks", limits.Caps.Tasks, 2, 3)
+	item("webhookEndpoints", limits.Caps.WebhookEndpoints, 1, 0)
+}
+
+func TestSuspendedOrgsCanOnlyReadAndExport(t *testing.T) {
+	alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+	bob := auth.User{ID: "user_bob", OrgID: "org_other", Role: "org:admin"}
+	suspended := func(t *testing.T, status int, body map[string]any) {
+		t.Helper()
+		if status != http.StatusForbidden || body["code"] != "ORG_SUSPENDED" {
+			t.Errorf("status = %d, body = %v, want 403 ORG_SUSPENDED", status, body)
+		}
+	}
+
+	for _, blockReads := range []bool{false, true} {
+		srv := servertest.Memory(t, &config.Config{
+			ADMIN_ALLOWED_IPS:          []string{"127.0.0.1", "::1"},
+			SUSPENDED_ORGS_BLOCK_READS: blockReads,
+		})
+		if err := srv.Store.Users().UpsertOrganization(t.Context(), models.Organization{ID: alice.OrgID, Name: "Acme", UpdatedAt: time.Now()}); err != nil {
+			t.Fatal(err)
+		}
+		if status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Before"}, nil); status != http.StatusCreated {
+			t.Fatalf("create while active: status = %d", status)
+		}
+		if status := srv.Do(t, auth.User{}, http.MethodPut, "/admin/orgs/org_unknown/status", map[string]any{"status": "suspended"}, nil); status != http.StatusNotFound {
+			t.Errorf("suspend an unknown org: status = %d, want %d", status, http.StatusNotFound)
+		}
+		if status := srv.Do(t, auth.User{}, http.MethodPut, "/admin/orgs/org_acme/status", map[string]any{"status": "suspended"}, nil); status != http.StatusOK {
+			t.Fatalf("suspend: status = %d", status)
+		}
+
+		var body map[string]any
+		status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "After"}, &body)
+		suspended(t, status, body)
+
+		body = nil
+		status = srv.Do(t, alice, http.MethodGet, "/api/v1/tasks", nil, &body)
+		if blockReads {
+			suspended(t, status, body)
+		} else if tasks, _ := body["tasks"].([]any); status != http.StatusOK || len(tasks) != 1 {
+			t.Errorf("list: status = %d, %d tasks, want the one made before", status, len(tasks))
+		}
+
+		var exported []map[string]any
+		if status := srv.Do(t, alice, http.MethodGet, "/api/v1/export?format=json", nil, &exported); status != http.StatusOK || len(exported) != 1 {
+			t.Errorf("export: status = %d, %d tasks, want the one made before", status, len(exported))
+		}
+
+		// Other orgs and personal lists carry on.
+		if status := srv.Do(t, bob, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Elsewhere"}, nil); status != http.StatusCreated {
+			t.Errorf("create in another org: status = %d", status)
+		}
+		if status := srv.Do(t, auth.User{ID: alice.ID}, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Personal"}, nil); status != http.StatusCreated {
+			t.Errorf("create in a personal list: status = %d", status)
+		}
+
+		if status := srv.Do(t, auth.User{}, http.MethodPut, "/admin/orgs/org_acme/status", map[string]any{"status": "active"}, nil); status != http.StatusOK {
+			t.Fatalf("reactivate: status = %d", status)
+		}
+		if status := srv.Do(t, alice, http.MethodPost, "/api/v1/tasks", map[string]any{"title": "Again"}, nil); status != http.StatusCreated {
+			t.Errorf("create after reactivating: status = %d", status)
+		}
+	}
+}
+
+func TestImportValidationMatchesTheImport(t *testing.T) {
+	srv := servertest.Memory(t, nil)
+	alice := auth.User{ID: "user_alice", OrgID: "org_acme", Role: "org:admin"}
+	const export = "Task ID,Name,Notes,Projects,Due Date\n" +
+		"1,Write the plan,,Launch,2026-11-02\n" +
+		"2,,no name,Launch,\n" +
+		"3,\"Review, then ship\",,Launch,\n" +
+		"4,   ,,,\n"
+
+	upload := func(path string, out any) int {
+		t.Helper()
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("source", models.ImportSourceAsana)
+		file, err := form.CreateFormFile("file", "asana.csv")
+		if err != nil {
+			t.Fatal(err)
+		}
+		file.Write([]byte(export))
+		form.Close()
+
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, srv.URL+path, &body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		req.Header.Set(servertest.UserIDHeader, alice.ID)
+		req.Header.Set(servertest.OrgIDHeader, alice.OrgID)
+		req.Header.Set(servertest.OrgRoleHeader, alice.Role)
+		res, err := srv.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode
+	}
+
+	var report models.ImportReport
+	if status := upload("/api/v1/tasks/import/validate", &report); status != http.StatusOK {
+		t.Fatalf("validate: status = %d, want %d", status, http.StatusOK)
+	}
+	want := models.ImportReport{
+		Columns: []string{"Task ID", "Name", "Notes", "Projects", "Due Date"},
+		Valid:   2,
+		Errors:  []models.ImportRowError{{Line: 3, Message: "Title is empty"}, {Line: 5, Message: "Title is empty"}},
+	}
+	if !reflect.DeepEqual(report, want) {
+		t.Errorf("validate: report = %+v, want %+v", report, want)
+	}
+	var list struct {
+		Tasks []models.Task `json:"tasks"`
+	}
+	if srv.Do(t, alice, http.MethodGet, "/api/v1/tasks", nil, &list); len(list.Tasks) != 0 {
+		t.Fatalf("validate created %d tasks", len(list.Tasks))
+	}
+
+	// The same file imported for real skips the same entries.
+	var imp models.Import
+	if status := upload("/api/v1/import", &imp); status != http.StatusAccepted {
+		t.Fatalf("import: status = %d, want %d", status, http.StatusAccepted)
+	}
+	run := importers.JobHandler(importers.Stores{
+		Imports:     srv.Store.Imports(),
+		Tasks:       srv.Store.Tasks(),
+		Projects:    srv.Store.Projects(),
+		Labels:      srv.Store.Labels(),
+		Workflows:   srv.Store.Workflows(),
+		OrgSettings: srv.Store.OrgSettings(),
+	})
+	if err := run(t.Context(), json.RawMessage(`{"importId":"`+imp.ID+`"}`)); err != nil {
+		t.Fatal(err)
+	}
+	if status := srv.Do(t, alice, http.MethodGet, "/api/v1/import/"+imp.ID, nil, &imp); status != http.StatusOK {
+		t.Fatalf("get import: status = %d", status)
+	}
+	if imp.Status != models.ImportStatusDone || imp.Total != 4 || imp.Imported != report.Valid || imp.Skipped != len(report.Errors) {
+		t.Errorf("import = %+v, want %d imported and %d skipped", imp, report.Valid, len(report.Errors))
+	}
+}
@@ -0,0 +1,162 @@
+// Package servertest serves the whole API to tests over HTTP, with Clerk
+// left out: requests name the user they're signed in as, and the session
+// claims Clerk would have verified are made up from that.
+package servertest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"yata/apps/server/internal/apierror"
+	"yata/apps/server/internal/auth"
+	"yata/apps/server/internal/config"
+	"yata/apps/server/internal/database/dbtest"
+	"yata/apps/server/internal/events"
+	"yata/apps/server/internal/flags"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/quota"
+	"yata/apps/server/internal/server"
+	"yata/apps/server/internal/store"
+	"yata/apps/server/internal/validation"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+	"github.com/gin-gonic/gin"
+)
+
+// The headers a request names its user with; see Server.Do.
+const (
+	UserIDHeader  = "X-Test-User-Id"
+	OrgIDHeader   = "X-Test-Org-Id"
+	OrgRoleHeader = "X-Test-Org-Role"
+)
+
+var setupOnce sync.Once
+
+// Server is the API served over a store.
+type Server struct {
+	*httptest.Server
+	// Store is the store the API was built over, for setting up data and
+	// checking what requests left behind.
+	Store  store.Store
+	Config *config.Config
+}
+
+// Memory serves the API over store.NewMemory().
+func Memory(t *testing.T, cfg *config.Config) *Server {
+	t.Helper()
+	return New(t, store.NewMemory(), flags.NewMemoryStore(), discardQueue{}, cfg)
+}
+
+// Postgres serves the API over a migrated Postgres from dbtest, so it's
+// skipped where dbtest is.
+func Postgres(t *testing.T, cfg *config.Config) *Server {
+	t.Helper()
+	pool := dbtest.Pool(t)
+	return New(t, store.NewPostgres(pool, nil), flags.NewPostgresStore(pool), jobs.NewPostgresQueue(pool), cfg)
+}
+
+// New serves the API over db the way serve does, with quotas and events,
+// and closes it when the test ends. A nil cfg is a config with nothing
+// set, like one built by hand.
+func New(t *testing.T, db store.Store, flagStore flags.Store, queue jobs.Queue, cfg *config.Config) *Server {
+	t.Helper()
+	setupOnce.Do(func() {
+		gin.SetMode(gin.TestMode)
+		validation.Setup()
+	})
+
+	if cfg == nil {
+		cfg = &config.Config{}
+	}
+
+	broker := events.NewBroker()
+	quotas := quota.New(db.Usage(), quota.LimitsFrom(cfg))
+	db = store.WithActivity(store.WithEvents(quota.WithQuotas(db, quotas), broker))
+
+	handler, err := server.New(cfg, db, server.Deps{
+		Broker:       broker,
+		Queue:        queue,
+		Flags:        flags.New(flagStore),
+		Authenticate: Authenticate(),
+	})
+	if err != nil {
+		t.Fatalf("Failed to build the API: %v", err)
+	}
+
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return &Server{Server: srv, Store: db, Config: cfg}
+}
+
+// Authenticate signs a request in as the user its test headers name, with
+// session claims like the ones ClerkAuthMiddleware would have verified.
+// A request without them is refused as it would be without a token.
+func Authenticate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := c.GetHeader(UserIDHeader)
+		if userID == "" {
+			apierror.Abort(c, apierror.Forbidden("Forbidden"))
+			return
+		}
+		claims := &clerk.SessionClaims{}
+		claims.Subject = userID
+		claims.ActiveOrganizationID = c.GetHeader(OrgIDHeader)
+		claims.ActiveOrganizationRole = c.GetHeader(OrgRoleHeader)
+		c.Request = c.Request.WithContext(clerk.ContextWithSessionClaims(c.Request.Context(), claims))
+		c.Next()
+	}
+}
+
+// Do sends a request signed in as the user, with body as JSON unless it's
+// nil, and decodes the JSON response into out unless that's nil. A user
+// with no ID sends the request signed out.
+func (s *Server) Do(t *testing.T, as auth.User, method, path string, body, out any) int {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("Failed to encode the %s %s body: %v", method, path, err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(t.Context(), method, s.URL+path, reader)
+	if err != nil {
+		t.Fatalf("Failed to build %s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if as.ID != "" {
+		req.Header.Set(UserIDHeader, as.ID)
+		req.Header.Set(OrgIDHeader, as.OrgID)
+		req.Header.Set(OrgRoleHeader, as.Role)
+	}
+
+	res, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer res.Body.Close()
+
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatalf("Failed to decode the %s %s response (%d): %v", method, path, res.StatusCode, err)
+		}
+	}
+	return res.StatusCode
+}
+
+// discardQueue drops the jobs requests enqueue, since nothing runs them
+// without Postgres.
+type discardQueue struct{}
+
+func (discardQueue) Enqueue(_ context.Context, _ string, _ any, _ ...jobs.EnqueueOption) error {
+	return nil
+}
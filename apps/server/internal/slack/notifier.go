@@ -0,0 +1,101 @@
+package slack
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/store"
+)
+
+// Notifier posts task-assigned and due-soon notifications on org tasks to
+// the channels the org picked. It's the org's channel rather than the
+// user's, so personal preferences don't apply.
+type Notifier struct {
+	Client       *Client
+	Integrations store.SlackStore
+	// Users names the assignee.
+	Users store.UserStore
+	// AppURL is the web app's base URL, used to link to tasks.
+	AppURL string
+}
+
+func (n *Notifier) Notify(ctx context.Context, note notify.Notification) error {
+	if note.OrgID == nil {
+		return nil
+	}
+	var channel func(models.SlackIntegration) *string
+	switch note.Kind {
+	case notify.KindTaskAssigned:
+		channel = func(s models.SlackIntegration) *string { return s.TaskAssignedChannel }
+	case notify.KindReminder:
+		channel = func(s models.SlackIntegration) *string { return s.DueSoonChannel }
+	default:
+		return nil
+	}
+
+	integration, err := n.Integrations.Get(ctx, *note.OrgID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	ch := channel(*integration)
+	if ch == nil || *ch == "" {
+		return nil
+	}
+
+	text := n.message(ctx, note)
+	switch {
+	case integration.BotToken != "":
+		return n.Client.PostMessage(ctx, integration.BotToken, *ch, text)
+	case integration.WebhookURL != "":
+		return n.Client.PostWebhook(ctx, integration.WebhookURL, text)
+	}
+	return nil
+}
+
+func (n *Notifier) message(ctx context.Context, note notify.Notification) string {
+	task := "*" + Escape(note.Title) + "*"
+	if n.AppURL != "" && note.TaskID != "" {
+		task = fmt.Sprintf("*<%s/tasks/%s|%s>*", strings.TrimRight(n.AppURL, "/"), note.TaskID, Escape(note.Title))
+	}
+
+	if note.Kind == notify.KindTaskAssigned {
+		name := "a teammate"
+		// The message still makes sense without a name, so don't fail on it.
+		if u, err := n.Users.GetUser(ctx, note.UserID); err == nil {
+			if s := userName(u); s != "" {
+				name = Escape(s)
+			}
+		}
+		return task + " was assigned to " + name
+	}
+	if note.DueDate == nil {
+		return "Reminder: " + task
+	}
+	// Slack shows the date in each reader's own timezone.
+	return fmt.Sprintf("%s is due <!date^%d^{date_short_pretty} at {time}|%s>",
+		task, note.DueDate.Unix(), note.DueDate.UTC().Format("Jan 2 15:04 UTC"))
+}
+
+func userName(u *models.User) string {
+	parts := []string{}
+	for _, p := range []*string{u.FirstName, u.LastName} {
+		if p != nil && *p != "" {
+			parts = append(parts, *p)
+		}
+	}
+	if len(parts) == 0 && u.Username != nil {
+		return *u.Username
+	}
+	return strings.Join(parts, " ")
+}
+
+// Escape escapes the characters Slack's mrkdwn treats as control sequences.
+func Escape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
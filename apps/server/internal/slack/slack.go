@@ -0,0 +1,197 @@
+// Package slack posts task notifications to the Slack workspaces orgs have
+// connected, and checks the signatures on the slash commands Slack sends.
+package slack
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const apiBase = "https://slack.com/api/"
+
+// WebhookPrefix is where every incoming webhook URL lives; anything else
+// is refused so the server can't be pointed at arbitrary hosts.
+const WebhookPrefix = "https://hooks.slack.com/"
+
+// maxSignatureAge is how old a signed request may be, against replays.
+const maxSignatureAge = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("slack request signature is invalid")
+
+// VerifySignature checks Slack's X-Slack-Signature over the raw body.
+func VerifySignature(secret, timestamp, signature string, body []byte, now time.Time) error {
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(ts, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return ErrInvalidSignature
+	}
+	got, ok := strings.CutPrefix(signature, "v0=")
+	if !ok {
+		return ErrInvalidSignature
+	}
+	sig, err := hex.DecodeString(got)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// APIError is a Web API call that Slack answered with ok: false.
+type APIError struct {
+	Method string
+	Code   string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("slack %s failed: %s", e.Method, e.Code)
+}
+
+// Client calls the Web API and incoming webhooks.
+type Client struct {
+	client *http.Client
+	base   string
+}
+
+func NewClient() *Client {
+	return &Client{client: &http.Client{Timeout: 10 * time.Second}, base: apiBase}
+}
+
+// TeamID returns the workspace botToken was issued in, from auth.test.
+func (c *Client) TeamID(ctx context.Context, botToken string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"auth.test", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+botToken)
+
+	var res struct {
+		TeamID string `json:"team_id"`
+	}
+	if err := c.call(req, &res); err != nil {
+		return "", err
+	}
+	if res.TeamID == "" {
+		return "", errors.New("slack auth.test returned no team_id")
+	}
+	return res.TeamID, nil
+}
+
+// PostMessage posts text to channel as the bot.
+func (c *Client) PostMessage(ctx context.Context, botToken, channel, text string) error {
+	body, err := json.Marshal(map[string]any{"channel": channel, "text": text, "unfurl_links": false})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.base+"chat.postMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+botToken)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	return c.call(req, nil)
+}
+
+// PostWebhook posts text through an incoming webhook.
+func (c *Client) PostWebhook(ctx context.Context, webhookURL, text string) error {
+	if !strings.HasPrefix(webhookURL, WebhookPrefix) {
+		return errors.New("not a slack webhook url")
+	}
+	body, err := json.Marshal(map[string]any{"text": text, "unfurl_links": false})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("slack webhook returned %d: %s", res.StatusCode, detail)
+	}
+	return nil
+}
+
+// UserEmail returns the address on a Slack user's profile. The bot needs
+// the users:read.email scope.
+func (c *Client) UserEmail(ctx context.Context, botToken, userID string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.base+"users.info?"+url.Values{"user": {userID}}.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+botToken)
+
+	var res struct {
+		User struct {
+			Profile struct {
+				Email string `json:"email"`
+			} `json:"profile"`
+		} `json:"user"`
+	}
+	if err := c.call(req, &res); err != nil {
+		return "", err
+	}
+	return res.User.Profile.Email, nil
+}
+
+// call sends a Web API request. Those answer 200 even when they fail, with
+// the reason in "error".
+func (c *Client) call(req *http.Request, out any) error {
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("slack returned %d: %s", res.StatusCode, detail)
+	}
+	data, err := io.ReadAll(io.LimitReader(res.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	var status struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &status); err != nil {
+		return err
+	}
+	if !status.OK {
+		return &APIError{Method: path.Base(req.URL.Path), Code: status.Error}
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,37 @@
+package slack
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTeamID(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/auth.test" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Header.Get("Authorization") {
+		case "Bearer xoxb-good":
+			w.Write([]byte(`{"ok":true,"team_id":"T0123ABCD","user_id":"U1"}`))
+		default:
+			w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+		}
+	}))
+	defer srv.Close()
+	c := &Client{client: srv.Client(), base: srv.URL + "/"}
+
+	team, err := c.TeamID(t.Context(), "xoxb-good")
+	if err != nil || team != "T0123ABCD" {
+		t.Errorf("TeamID = %q, %v; want T0123ABCD", team, err)
+	}
+
+	_, err = c.TeamID(t.Context(), "xoxb-revoked")
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Method != "auth.test" || apiErr.Code != "invalid_auth" {
+		t.Errorf("err = %v, want auth.test's invalid_auth", err)
+	}
+}
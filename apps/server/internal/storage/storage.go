@@ -0,0 +1,66 @@
+// Package storage keeps file contents outside the database. Clients upload
+// and download directly against the backend through presigned URLs; the API
+// only hands those out and checks what arrived, apart from the few files it
+// receives itself.
+package storage
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// ErrObjectNotFound is returned by Stat when nothing was uploaded at the key.
+var ErrObjectNotFound = errors.New("storage: object not found")
+
+type ObjectInfo struct {
+	Size        int64
+	ContentType string
+}
+
+type Storage interface {
+	// PresignPut returns a URL that accepts exactly one upload of size bytes
+	// of contentType at key until it expires.
+	PresignPut(ctx context.Context, key, contentType string, size int64, expires time.Duration) (string, error)
+	// Put uploads size bytes from body, for files the server receives
+	// itself rather than the client.
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	// PresignGet returns a URL that downloads key as filename until it expires.
+	PresignGet(ctx context.Context, key, filename string, expires time.Duration) (string, error)
+	Stat(ctx context.Context, key string) (ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// NewKey returns a fresh object key under prefix that ends in filename, so
+// downloads without a disposition still get a sensible name.
+func NewKey(prefix, filename string) string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return path.Join(prefix, hex.EncodeToString(b), SanitizeFilename(filename))
+}
+
+// SanitizeFilename keeps the base name and replaces anything that's awkward
+// in an object key or a header with an underscore.
+func SanitizeFilename(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	if clean == "" || clean == "." || clean == ".." {
+		return "file"
+	}
+	if len(clean) > 200 {
+		clean = clean[len(clean)-200:]
+	}
+	return clean
+}
@@ -0,0 +1,448 @@
+package store
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"time"
+	"yata/apps/server/internal/models"
+
+	"github.com/clerk/clerk-sdk-go/v2"
+)
+
+// WithActivity records every task, label and project change made through the
+// returned Store in its ActivityStore. Updates read the row first to capture
+// old values, so a concurrent write can make an entry's "old" side stale.
+// Sync pushes aren't recorded; the sync op log already is their history.
+func WithActivity(s Store) Store {
+	return activityStore{Store: s}
+}
+
+type activityStore struct {
+	Store
+}
+
+func (s activityStore) Tasks() TaskStore {
+	return activityTasks{TaskStore: s.Store.Tasks(), labels: s.Store.Labels(), log: activityLog{s.Store.Activity()}}
+}
+
+func (s activityStore) Labels() LabelStore {
+	return activityLabels{LabelStore: s.Store.Labels(), log: activityLog{s.Store.Activity()}}
+}
+
+func (s activityStore) Projects() ProjectStore {
+	return activityProjects{ProjectStore: s.Store.Projects(), log: activityLog{s.Store.Activity()}}
+}
+
+func (s activityStore) Trash() TrashStore {
+	return activityTrash{TrashStore: s.Store.Trash(), log: activityLog{s.Store.Activity()}}
+}
+
+func (s activityStore) Boards() BoardStore {
+	return activityBoards{BoardStore: s.Store.Boards(), log: activityLog{s.Store.Activity()}}
+}
+
+func (s activityStore) OrgAdmin() OrgAdminStore {
+	return activityOrgAdmin{OrgAdminStore: s.Store.OrgAdmin(), log: activityLog{s.Store.Activity()}}
+}
+
+// activityLog records entries without failing the write they describe.
+type activityLog struct {
+	ActivityStore
+}
+
+func (l activityLog) record(ctx context.Context, input models.CreateActivityInput) {
+	if err := l.Record(ctx, input); err != nil {
+		slog.ErrorContext(ctx, "Failed to record activity", "action", input.Action, "error", err)
+	}
+}
+
+// taskEntry describes a change to task in scope.
+func (l activityLog) taskEntry(ctx context.Context, scope models.Scope, task *models.Task, action string, changes map[string]models.FieldChange) {
+	l.record(ctx, models.CreateActivityInput{
+		OrgID:     scope.OrgIDPtr(),
+		ActorID:   scope.UserID,
+		TaskID:    &task.ID,
+		ProjectID: task.ProjectID,
+		Action:    action,
+		Changes:   changes,
+	})
+}
+
+// projectEntry describes a change to project by the signed-in user.
+func (l activityLog) projectEntry(ctx context.Context, project *models.Project, action string, changes map[string]models.FieldChange) {
+	l.record(ctx, models.CreateActivityInput{
+		OrgID:     &project.OrgID,
+		ActorID:   actorID(ctx),
+		ProjectID: &project.ID,
+		Action:    action,
+		Changes:   changes,
+	})
+}
+
+// actorID is the signed-in user, for stores whose methods only take an org.
+func actorID(ctx context.Context) string {
+	claims, ok := clerk.SessionClaimsFromContext(ctx)
+	if !ok {
+		return ""
+	}
+	return claims.Subject
+}
+
+// taskChanges diffs the user-editable fields of two versions of a task. With
+// no before, every field that's set counts as new.
+func taskChanges(before, after *models.Task) map[string]models.FieldChange {
+	var old models.Task
+	if before != nil {
+		old = *before
+	}
+
+	changes := map[string]models.FieldChange{}
+	diff := func(field string, o, n any) {
+		if reflect.DeepEqual(o, n) {
+			return
+		}
+		if before == nil {
+			o = nil
+		}
+		changes[field] = models.FieldChange{Old: o, New: n}
+	}
+	diff("title", old.Title, after.Title)
+	diff("description", old.Description, after.Description)
+	diff("status", old.Status, after.Status)
+	diff("priority", old.Priority, after.Priority)
+	diff("dueDate", timeValue(old.DueDate), timeValue(after.DueDate))
+	diff("dueTimezone", ptrValue(old.DueTimezone), ptrValue(after.DueTimezone))
+	diff("recurrence", ptrValue(old.Recurrence), ptrValue(after.Recurrence))
+	diff("estimateMinutes", ptrValue(old.EstimateMinutes), ptrValue(after.EstimateMinutes))
+	diff("projectId", ptrValue(old.ProjectID), ptrValue(after.ProjectID))
+	diff("parentId", ptrValue(old.ParentID), ptrValue(after.ParentID))
+	for key, value := range after.CustomFields {
+		diff("customFields."+key, old.CustomFields[key], value)
+	}
+	for key, value := range old.CustomFields {
+		if _, ok := after.CustomFields[key]; !ok {
+			diff("customFields."+key, value, nil)
+		}
+	}
+	return changes
+}
+
+func ptrValue[T any](p *T) any {
+	if p == nil {
+		return nil
+	}
+	return *p
+}
+
+func timeValue(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+type activityTasks struct {
+	TaskStore
+	labels LabelStore
+	log    activityLog
+}
+
+func (t activityTasks) Create(ctx context.Context, scope models.Scope, input models.CreateTaskInput) (*models.Task, error) {
+	task, err := t.TaskStore.Create(ctx, scope, input)
+	if err == nil {
+		t.log.taskEntry(ctx, scope, task, models.ActivityTaskCreated, taskChanges(nil, task))
+	}
+	return task, err
+}
+
+func (t activityTasks) CreateBatch(ctx context.Context, scope models.Scope, batch []models.NewTask) ([]models.Task, error) {
+	tasks, err := t.TaskStore.CreateBatch(ctx, scope, batch)
+	for i := range tasks {
+		t.log.taskEntry(ctx, scope, &tasks[i], models.ActivityTaskCreated, taskChanges(nil, &tasks[i]))
+	}
+	return tasks, err
+}
+
+func (t activityTasks) Update(ctx context.Context, scope models.Scope, id string, input models.UpdateTaskInput) (*models.Task, error) {
+	before, err := t.TaskStore.Get(ctx, scope, id)
+	if err != nil {
+		return nil, err
+	}
+	task, err := t.TaskStore.Update(ctx, scope, id, input)
+	if err == nil {
+		if changes := taskChanges(before, task); len(changes) > 0 {
+			t.log.taskEntry(ctx, scope, task, models.ActivityTaskUpdated, changes)
+		}
+	}
+	return task, err
+}
+
+func (t activityTasks) Delete(ctx context.Context, scope models.Scope, id string, ifVersion *int) error {
+	task, err := t.TaskStore.Get(ctx, scope, id)
+	if err != nil {
+		return err
+	}
+	err = t.TaskStore.Delete(ctx, scope, id, ifVersion)
+	if err == nil {
+		t.log.taskEntry(ctx, scope, task, models.ActivityTaskDeleted, nil)
+	}
+	return err
+}
+
+func (t activityTasks) SetArchived(ctx context.Context, scope models.Scope, id string, archived bool) (*models.Task, error) {
+	task, err := t.TaskStore.SetArchived(ctx, scope, id, archived)
+	if err == nil {
+		action := models.ActivityTaskArchived
+		if !archived {
+			action = models.ActivityTaskUnarchived
+		}
+		t.log.taskEntry(ctx, scope, task, action, nil)
+	}
+	return task, err
+}
+
+func (t activityTasks) Snooze(ctx context.Context, scope models.Scope, id string, until *time.Time) (*models.Task, error) {
+	task, err := t.TaskStore.Snooze(ctx, scope, id, until)
+	if err == nil {
+		changes := map[string]models.FieldChange{"snoozedUntil": {New: until}}
+		t.log.taskEntry(ctx, scope, task, models.ActivityTaskSnoozed, changes)
+	}
+	return task, err
+}
+
+func (t activityTasks) AddDependency(ctx context.Context, scope models.Scope, id, blockedByID string) error {
+	err := t.TaskStore.AddDependency(ctx, scope, id, blockedByID)
+	if err == nil {
+		changes := map[string]models.FieldChange{"blockedBy": {New: blockedByID}}
+		t.log.taskEntry(ctx, scope, &models.Task{ID: id}, models.ActivityTaskDependencyAdded, changes)
+	}
+	return err
+}
+
+func (t activityTasks) RemoveDependency(ctx context.Context, scope models.Scope, id, blockedByID string) error {
+	err := t.TaskStore.RemoveDependency(ctx, scope, id, blockedByID)
+	if err == nil {
+		changes := map[string]models.FieldChange{"blockedBy": {Old: blockedByID}}
+		t.log.taskEntry(ctx, scope, &models.Task{ID: id}, models.ActivityTaskDependencyRemoved, changes)
+	}
+	return err
+}
+
+// Bulk reads the tasks and, for relabels, their labels up front so each
+// result can be recorded with what it replaced.
+func (t activityTasks) Bulk(ctx context.Context, scope models.Scope, ops []models.BulkTaskOp, completedStatus string, doneStatuses []string) ([]models.BulkTaskResult, error) {
+	before := map[string]*models.Task{}
+	relabeled := []string{}
+	for _, op := range ops {
+		if task, err := t.TaskStore.Get(ctx, scope, op.TaskID); err == nil {
+			before[op.TaskID] = task
+		}
+		if op.Action == models.BulkRelabel {
+			relabeled = append(relabeled, op.TaskID)
+		}
+	}
+	oldLabels := map[string][]models.Label{}
+	if len(relabeled) > 0 && scope.IsOrg() {
+		labels, err := t.labels.ForTasks(ctx, scope.OrgID, relabeled)
+		if err != nil {
+			return nil, err
+		}
+		oldLabels = labels
+	}
+
+	results, err := t.TaskStore.Bulk(ctx, scope, ops, completedStatus, doneStatuses)
+	if err != nil {
+		return results, err
+	}
+	for i, r := range results {
+		task := before[r.TaskID]
+		if !r.OK || task == nil {
+			continue
+		}
+
+		switch op := ops[i]; op.Action {
+		case models.BulkComplete:
+			if r.Completed != nil {
+				t.log.taskEntry(ctx, scope, r.Completed, models.ActivityTaskUpdated, taskChanges(task, r.Completed))
+			}
+		case models.BulkMove:
+			moved := *task
+			moved.ProjectID = op.ProjectID
+			if changes := taskChanges(task, &moved); len(changes) > 0 {
+				t.log.taskEntry(ctx, scope, &moved, models.ActivityTaskUpdated, changes)
+			}
+		case models.BulkRelabel:
+			old := []string{}
+			for _, l := range oldLabels[r.TaskID] {
+				old = append(old, l.ID)
+			}
+			changes := map[string]models.FieldChange{"labelIds": {Old: old, New: op.LabelIDs}}
+			t.log.taskEntry(ctx, scope, task, models.ActivityTaskUpdated, changes)
+		case models.BulkDelete:
+			t.log.taskEntry(ctx, scope, task, models.ActivityTaskDeleted, nil)
+		}
+	}
+	return results, nil
+}
+
+type activityLabels struct {
+	LabelStore
+	log activityLog
+}
+
+func (l activityLabels) Attach(ctx context.Context, orgID, taskID, labelID string) error {
+	err := l.LabelStore.Attach(ctx, orgID, taskID, labelID)
+	if err == nil {
+		l.log.record(ctx, models.CreateActivityInput{
+			OrgID:   &orgID,
+			ActorID: actorID(ctx),
+			TaskID:  &taskID,
+			Action:  models.ActivityTaskLabeled,
+			Changes: map[string]models.FieldChange{"labelId": {New: labelID}},
+		})
+	}
+	return err
+}
+
+func (l activityLabels) Detach(ctx context.Context, orgID, taskID, labelID string) error {
+	err := l.LabelStore.Detach(ctx, orgID, taskID, labelID)
+	if err == nil {
+		l.log.record(ctx, models.CreateActivityInput{
+			OrgID:   &orgID,
+			ActorID: actorID(ctx),
+			TaskID:  &taskID,
+			Action:  models.ActivityTaskUnlabeled,
+			Changes: map[string]models.FieldChange{"labelId": {Old: labelID}},
+		})
+	}
+	return err
+}
+
+type activityProjects struct {
+	ProjectStore
+	log activityLog
+}
+
+func (p activityProjects) Create(ctx context.Context, orgID, userID string, input models.CreateProjectInput) (*models.Project, error) {
+	project, err := p.ProjectStore.Create(ctx, orgID, userID, input)
+	if err == nil {
+		p.log.projectEntry(ctx, project, models.ActivityProjectCreated, map[string]models.FieldChange{"name": {New: project.Name}})
+	}
+	return project, err
+}
+
+func (p activityProjects) CreateSeeded(ctx context.Context, scope models.Scope, input models.NewProject) (*models.SeededProject, error) {
+	seeded, err := p.ProjectStore.CreateSeeded(ctx, scope, input)
+	if err == nil {
+		p.log.projectEntry(ctx, &seeded.Project, models.ActivityProjectCreated, map[string]models.FieldChange{"name": {New: seeded.Project.Name}})
+		for i := range seeded.Tasks {
+			p.log.taskEntry(ctx, scope, &seeded.Tasks[i], models.ActivityTaskCreated, taskChanges(nil, &seeded.Tasks[i]))
+		}
+	}
+	return seeded, err
+}
+
+func (p activityProjects) Rename(ctx context.Context, orgID, id, name string) (*models.Project, error) {
+	before, err := p.ProjectStore.Get(ctx, orgID, id)
+	if err != nil {
+		return nil, err
+	}
+	project, err := p.ProjectStore.Rename(ctx, orgID, id, name)
+	if err == nil && before.Name != project.Name {
+		p.log.projectEntry(ctx, project, models.ActivityProjectRenamed, map[string]models.FieldChange{"name": {Old: before.Name, New: project.Name}})
+	}
+	return project, err
+}
+
+func (p activityProjects) SetArchived(ctx context.Context, orgID, id string, archived bool) (*models.Project, error) {
+	project, err := p.ProjectStore.SetArchived(ctx, orgID, id, archived)
+	if err == nil {
+		action := models.ActivityProjectArchived
+		if !archived {
+			action = models.ActivityProjectUnarchived
+		}
+		p.log.projectEntry(ctx, project, action, nil)
+	}
+	return project, err
+}
+
+type activityTrash struct {
+	TrashStore
+	log activityLog
+}
+
+func (t activityTrash) RestoreTask(ctx context.Context, scope models.Scope, id string) (*models.Task, error) {
+	task, err := t.TrashStore.RestoreTask(ctx, scope, id)
+	if err == nil {
+		t.log.taskEntry(ctx, scope, task, models.ActivityTaskRestored, nil)
+	}
+	return task, err
+}
+
+func (t activityTrash) DeleteProject(ctx context.Context, orgID, id string) error {
+	err := t.TrashStore.DeleteProject(ctx, orgID, id)
+	if err == nil {
+		t.log.projectEntry(ctx, &models.Project{ID: id, OrgID: orgID}, models.ActivityProjectDeleted, nil)
+	}
+	return err
+}
+
+func (t activityTrash) RestoreProject(ctx context.Context, orgID, id string) (*models.Project, error) {
+	project, err := t.TrashStore.RestoreProject(ctx, orgID, id)
+	if err == nil {
+		t.log.projectEntry(ctx, project, models.ActivityProjectRestored, nil)
+	}
+	return project, err
+}
+
+type activityOrgAdmin struct {
+	OrgAdminStore
+	log activityLog
+}
+
+func (a activityOrgAdmin) TransferTasks(ctx context.Context, orgID, fromUserID, toUserID string) ([]models.ReassignedTask, error) {
+	moved, err := a.OrgAdminStore.TransferTasks(ctx, orgID, fromUserID, toUserID)
+	if err == nil {
+		a.ownerEntries(ctx, orgID, toUserID, moved)
+	}
+	return moved, err
+}
+
+func (a activityOrgAdmin) ReassignTasks(ctx context.Context, orgID string, ids []string, toUserID string) ([]models.ReassignedTask, error) {
+	moved, err := a.OrgAdminStore.ReassignTasks(ctx, orgID, ids, toUserID)
+	if err == nil {
+		a.ownerEntries(ctx, orgID, toUserID, moved)
+	}
+	return moved, err
+}
+
+func (a activityOrgAdmin) ownerEntries(ctx context.Context, orgID, toUserID string, moved []models.ReassignedTask) {
+	for _, t := range moved {
+		a.log.record(ctx, models.CreateActivityInput{
+			OrgID:     &orgID,
+			ActorID:   actorID(ctx),
+			TaskID:    &t.ID,
+			ProjectID: t.ProjectID,
+			Action:    models.ActivityTaskUpdated,
+			Changes:   map[string]models.FieldChange{"ownerId": {Old: t.PreviousOwnerID, New: toUserID}},
+		})
+	}
+}
+
+type activityBoards struct {
+	BoardStore
+	log activityLog
+}
+
+// Move records the status change, if any; where a task sits on a board isn't
+// part of its history.
+func (b activityBoards) Move(ctx context.Context, scope models.Scope, id string, input models.MoveCardInput) (*models.BoardMove, error) {
+	move, err := b.BoardStore.Move(ctx, scope, id, input)
+	if err == nil && move.Task.Status != move.PreviousStatus {
+		b.log.taskEntry(ctx, scope, &move.Task, models.ActivityTaskUpdated, map[string]models.FieldChange{
+			"status": {Old: move.PreviousStatus, New: move.Task.Status},
+		})
+	}
+	return move, err
+}
@@ -0,0 +1,293 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+	"yata/apps/server/internal/cache"
+	"yata/apps/server/internal/models"
+)
+
+// WithCache serves user, org member, org status, project and org settings
+// reads from c for up to ttl.
+// Writes made through the returned Store invalidate what they change; the
+// Clerk webhooks are what change users and members, so they must go through
+// it too.
+func WithCache(s Store, c cache.Cache, ttl time.Duration) Store {
+	return cacheStore{Store: s, cache: c, ttl: ttl}
+}
+
+type cacheStore struct {
+	Store
+	cache cache.Cache
+	ttl   time.Duration
+}
+
+// Namespaces that are invalidated together.
+const usersNamespace = "users"
+
+func orgNamespace(orgID string) string         { return "org:" + orgID }
+func membersNamespace(orgID string) string     { return "members:" + orgID }
+func projectsNamespace(orgID string) string    { return "projects:" + orgID }
+func orgSettingsNamespace(orgID string) string { return "org_settings:" + orgID }
+
+// cached returns the value cached under parts, or loads and caches it. The
+// cache failing only costs the load.
+func cached[T any](ctx context.Context, s cacheStore, namespaces []string, parts []string, load func() (T, error)) (T, error) {
+	key, err := cache.Key(ctx, s.cache, namespaces, parts...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to read cache", "error", err)
+		return load()
+	}
+
+	var value T
+	err = cache.GetJSON(ctx, s.cache, key, &value)
+	if err == nil {
+		return value, nil
+	}
+	if !errors.Is(err, cache.ErrMiss) {
+		slog.ErrorContext(ctx, "Failed to read cache", "error", err)
+	}
+
+	value, err = load()
+	if err != nil {
+		return value, err
+	}
+	if err := cache.SetJSON(ctx, s.cache, key, value, s.ttl); err != nil {
+		slog.ErrorContext(ctx, "Failed to write cache", "error", err)
+	}
+	return value, nil
+}
+
+func (s cacheStore) invalidate(ctx context.Context, namespaces ...string) {
+	for _, ns := range namespaces {
+		if err := cache.Invalidate(ctx, s.cache, ns); err != nil {
+			slog.ErrorContext(ctx, "Failed to invalidate cache", "namespace", ns, "error", err)
+		}
+	}
+}
+
+func pageParts(page models.Page) []string {
+	return append([]string{cache.Int(page.Limit)}, page.After...)
+}
+
+func (s cacheStore) Users() UserStore {
+	return cacheUsers{UserStore: s.Store.Users(), s: s}
+}
+
+func (s cacheStore) Projects() ProjectStore {
+	return cacheProjects{ProjectStore: s.Store.Projects(), s: s}
+}
+
+func (s cacheStore) Trash() TrashStore {
+	return cacheTrash{TrashStore: s.Store.Trash(), s: s}
+}
+
+func (s cacheStore) OrgSettings() OrgSettingsStore {
+	return cacheOrgSettings{OrgSettingsStore: s.Store.OrgSettings(), s: s}
+}
+
+func (s cacheStore) Accounts() AccountStore {
+	return cacheAccounts{AccountStore: s.Store.Accounts(), s: s}
+}
+
+type cacheUsers struct {
+	UserStore
+	s cacheStore
+}
+
+func (u cacheUsers) UpsertUser(ctx context.Context, user models.User) error {
+	err := u.UserStore.UpsertUser(ctx, user)
+	if err == nil {
+		u.s.invalidate(ctx, usersNamespace)
+	}
+	return err
+}
+
+func (u cacheUsers) DeleteUser(ctx context.Context, id string) error {
+	err := u.UserStore.DeleteUser(ctx, id)
+	if err == nil {
+		u.s.invalidate(ctx, usersNamespace)
+	}
+	return err
+}
+
+func (u cacheUsers) DeleteOrganization(ctx context.Context, id string) error {
+	err := u.UserStore.DeleteOrganization(ctx, id)
+	if err == nil {
+		u.s.invalidate(ctx, orgNamespace(id), membersNamespace(id), projectsNamespace(id), orgSettingsNamespace(id))
+	}
+	return err
+}
+
+func (u cacheUsers) UpsertOrganization(ctx context.Context, org models.Organization) error {
+	err := u.UserStore.UpsertOrganization(ctx, org)
+	if err == nil {
+		u.s.invalidate(ctx, orgNamespace(org.ID))
+	}
+	return err
+}
+
+func (u cacheUsers) SetOrganizationStatus(ctx context.Context, id, status string) error {
+	err := u.UserStore.SetOrganizationStatus(ctx, id, status)
+	if err == nil {
+		u.s.invalidate(ctx, orgNamespace(id))
+	}
+	return err
+}
+
+func (u cacheUsers) GetOrganizationStatus(ctx context.Context, id string) (string, error) {
+	return cached(ctx, u.s, []string{orgNamespace(id)}, []string{"status"}, func() (string, error) {
+		return u.UserStore.GetOrganizationStatus(ctx, id)
+	})
+}
+
+func (u cacheUsers) UpsertMembership(ctx context.Context, membership models.OrgMembership) error {
+	err := u.UserStore.UpsertMembership(ctx, membership)
+	if err == nil {
+		u.s.invalidate(ctx, membersNamespace(membership.OrgID))
+	}
+	return err
+}
+
+func (u cacheUsers) DeleteMembership(ctx context.Context, orgID, userID string) error {
+	err := u.UserStore.DeleteMembership(ctx, orgID, userID)
+	if err == nil {
+		u.s.invalidate(ctx, membersNamespace(orgID))
+	}
+	return err
+}
+
+func (u cacheUsers) GetUser(ctx context.Context, id string) (*models.User, error) {
+	return cached(ctx, u.s, []string{usersNamespace}, []string{"user", id}, func() (*models.User, error) {
+		return u.UserStore.GetUser(ctx, id)
+	})
+}
+
+func (u cacheUsers) GetMember(ctx context.Context, orgID, userID string) (*models.OrgMember, error) {
+	namespaces := []string{usersNamespace, membersNamespace(orgID)}
+	return cached(ctx, u.s, namespaces, []string{"member", userID}, func() (*models.OrgMember, error) {
+		return u.UserStore.GetMember(ctx, orgID, userID)
+	})
+}
+
+func (u cacheUsers) ListMembers(ctx context.Context, orgID string, page models.Page) ([]models.OrgMember, error) {
+	namespaces := []string{usersNamespace, membersNamespace(orgID)}
+	return cached(ctx, u.s, namespaces, append([]string{"list"}, pageParts(page)...), func() ([]models.OrgMember, error) {
+		return u.UserStore.ListMembers(ctx, orgID, page)
+	})
+}
+
+type cacheProjects struct {
+	ProjectStore
+	s cacheStore
+}
+
+func (p cacheProjects) Create(ctx context.Context, orgID, userID string, input models.CreateProjectInput) (*models.Project, error) {
+	project, err := p.ProjectStore.Create(ctx, orgID, userID, input)
+	if err == nil {
+		p.s.invalidate(ctx, projectsNamespace(orgID))
+	}
+	return project, err
+}
+
+func (p cacheProjects) CreateSeeded(ctx context.Context, scope models.Scope, input models.NewProject) (*models.SeededProject, error) {
+	seeded, err := p.ProjectStore.CreateSeeded(ctx, scope, input)
+	if err == nil {
+		p.s.invalidate(ctx, projectsNamespace(scope.OrgID))
+	}
+	return seeded, err
+}
+
+func (p cacheProjects) Rename(ctx context.Context, orgID, id, name string) (*models.Project, error) {
+	project, err := p.ProjectStore.Rename(ctx, orgID, id, name)
+	if err == nil {
+		p.s.invalidate(ctx, projectsNamespace(orgID))
+	}
+	return project, err
+}
+
+func (p cacheProjects) SetArchived(ctx context.Context, orgID, id string, archived bool) (*models.Project, error) {
+	project, err := p.ProjectStore.SetArchived(ctx, orgID, id, archived)
+	if err == nil {
+		p.s.invalidate(ctx, projectsNamespace(orgID))
+	}
+	return project, err
+}
+
+func (p cacheProjects) Get(ctx context.Context, orgID, id string) (*models.Project, error) {
+	return cached(ctx, p.s, []string{projectsNamespace(orgID)}, []string{"project", id}, func() (*models.Project, error) {
+		return p.ProjectStore.Get(ctx, orgID, id)
+	})
+}
+
+func (p cacheProjects) List(ctx context.Context, orgID string, includeArchived bool, page models.Page) ([]models.Project, error) {
+	parts := append([]string{"list", strconv.FormatBool(includeArchived)}, pageParts(page)...)
+	return cached(ctx, p.s, []string{projectsNamespace(orgID)}, parts, func() ([]models.Project, error) {
+		return p.ProjectStore.List(ctx, orgID, includeArchived, page)
+	})
+}
+
+// cacheOrgSettings is read on every API request, for the org's rate limit.
+type cacheOrgSettings struct {
+	OrgSettingsStore
+	s cacheStore
+}
+
+func (o cacheOrgSettings) Get(ctx context.Context, orgID string) (models.OrgSettings, error) {
+	return cached(ctx, o.s, []string{orgSettingsNamespace(orgID)}, []string{"settings"}, func() (models.OrgSettings, error) {
+		return o.OrgSettingsStore.Get(ctx, orgID)
+	})
+}
+
+func (o cacheOrgSettings) Update(ctx context.Context, orgID string, input models.UpdateOrgSettingsInput) (models.OrgSettings, error) {
+	s, err := o.OrgSettingsStore.Update(ctx, orgID, input)
+	if err == nil {
+		o.s.invalidate(ctx, orgSettingsNamespace(orgID))
+	}
+	return s, err
+}
+
+// cacheAccounts invalidates the erased user, and the members and projects
+// of their orgs, which still had them as creator.
+type cacheAccounts struct {
+	AccountStore
+	s cacheStore
+}
+
+func (a cacheAccounts) Erase(ctx context.Context, userID string) (models.ErasedAccount, error) {
+	erased, err := a.AccountStore.Erase(ctx, userID)
+	if err == nil {
+		namespaces := []string{usersNamespace}
+		for _, orgID := range erased.OrgIDs {
+			namespaces = append(namespaces, membersNamespace(orgID), projectsNamespace(orgID))
+		}
+		a.s.invalidate(ctx, namespaces...)
+	}
+	return erased, err
+}
+
+// cacheTrash invalidates projects moved in and out of the trash. Purging
+// only removes projects that were already out of the cached reads.
+type cacheTrash struct {
+	TrashStore
+	s cacheStore
+}
+
+func (t cacheTrash) DeleteProject(ctx context.Context, orgID, id string) error {
+	err := t.TrashStore.DeleteProject(ctx, orgID, id)
+	if err == nil {
+		t.s.invalidate(ctx, projectsNamespace(orgID))
+	}
+	return err
+}
+
+func (t cacheTrash) RestoreProject(ctx context.Context, orgID, id string) (*models.Project, error) {
+	project, err := t.TrashStore.RestoreProject(ctx, orgID, id)
+	if err == nil {
+		t.s.invalidate(ctx, projectsNamespace(orgID))
+	}
+	return project, err
+}
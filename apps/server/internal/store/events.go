@@ -0,0 +1,227 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/events"
+	"yata/apps/server/internal/models"
+)
+
+// WithEvents publishes a task event to broker after every successful task
+// write made through the returned Store.
+func WithEvents(s Store, broker *events.Broker) Store {
+	return eventStore{Store: s, broker: broker}
+}
+
+type eventStore struct {
+	Store
+	broker *events.Broker
+}
+
+func (s eventStore) Tasks() TaskStore {
+	return eventTasks{TaskStore: s.Store.Tasks(), broker: s.broker}
+}
+
+func (s eventStore) Projects() ProjectStore {
+	return eventProjects{ProjectStore: s.Store.Projects(), broker: s.broker}
+}
+
+func (s eventStore) Sync() SyncStore {
+	return eventSync{SyncStore: s.Store.Sync(), broker: s.broker}
+}
+
+func (s eventStore) Trash() TrashStore {
+	return eventTrash{TrashStore: s.Store.Trash(), broker: s.broker}
+}
+
+func (s eventStore) Boards() BoardStore {
+	return eventBoards{BoardStore: s.Store.Boards(), broker: s.broker}
+}
+
+func (s eventStore) OrgAdmin() OrgAdminStore {
+	return eventOrgAdmin{OrgAdminStore: s.Store.OrgAdmin(), broker: s.broker}
+}
+
+// eventProjects publishes the tasks a project is created with.
+type eventProjects struct {
+	ProjectStore
+	broker *events.Broker
+}
+
+func (p eventProjects) CreateSeeded(ctx context.Context, scope models.Scope, input models.NewProject) (*models.SeededProject, error) {
+	seeded, err := p.ProjectStore.CreateSeeded(ctx, scope, input)
+	if err != nil {
+		return nil, err
+	}
+	for i := range seeded.Tasks {
+		p.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskCreated, TaskID: seeded.Tasks[i].ID, Task: &seeded.Tasks[i]})
+	}
+	return seeded, nil
+}
+
+type eventTasks struct {
+	TaskStore
+	broker *events.Broker
+}
+
+func (t eventTasks) Create(ctx context.Context, scope models.Scope, input models.CreateTaskInput) (*models.Task, error) {
+	task, err := t.TaskStore.Create(ctx, scope, input)
+	if err == nil {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskCreated, TaskID: task.ID, Task: task})
+	}
+	return task, err
+}
+
+func (t eventTasks) CreateBatch(ctx context.Context, scope models.Scope, batch []models.NewTask) ([]models.Task, error) {
+	tasks, err := t.TaskStore.CreateBatch(ctx, scope, batch)
+	for i := range tasks {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskCreated, TaskID: tasks[i].ID, Task: &tasks[i]})
+	}
+	return tasks, err
+}
+
+func (t eventTasks) Update(ctx context.Context, scope models.Scope, id string, input models.UpdateTaskInput) (*models.Task, error) {
+	task, err := t.TaskStore.Update(ctx, scope, id, input)
+	if err == nil {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskUpdated, TaskID: task.ID, Task: task})
+	}
+	return task, err
+}
+
+func (t eventTasks) Delete(ctx context.Context, scope models.Scope, id string, ifVersion *int) error {
+	err := t.TaskStore.Delete(ctx, scope, id, ifVersion)
+	if err == nil {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskDeleted, TaskID: id})
+	}
+	return err
+}
+
+func (t eventTasks) SetArchived(ctx context.Context, scope models.Scope, id string, archived bool) (*models.Task, error) {
+	task, err := t.TaskStore.SetArchived(ctx, scope, id, archived)
+	if err == nil {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskUpdated, TaskID: task.ID, Task: task})
+	}
+	return task, err
+}
+
+func (t eventTasks) Snooze(ctx context.Context, scope models.Scope, id string, until *time.Time) (*models.Task, error) {
+	task, err := t.TaskStore.Snooze(ctx, scope, id, until)
+	if err == nil {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskUpdated, TaskID: task.ID, Task: task})
+	}
+	return task, err
+}
+
+func (t eventTasks) Reorder(ctx context.Context, scope models.Scope, id string, input models.ReorderTaskInput) (*models.Task, error) {
+	task, err := t.TaskStore.Reorder(ctx, scope, id, input)
+	if err == nil {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskUpdated, TaskID: task.ID, Task: task})
+	}
+	return task, err
+}
+
+func (t eventTasks) Bulk(ctx context.Context, scope models.Scope, ops []models.BulkTaskOp, completedStatus string, doneStatuses []string) ([]models.BulkTaskResult, error) {
+	results, err := t.TaskStore.Bulk(ctx, scope, ops, completedStatus, doneStatuses)
+	if err != nil {
+		return results, err
+	}
+	for _, r := range results {
+		if !r.OK {
+			continue
+		}
+		e := events.Event{Type: events.TaskUpdated, TaskID: r.TaskID, Task: r.Completed}
+		if r.Action == models.BulkDelete {
+			e.Type = events.TaskDeleted
+		}
+		t.broker.Publish(events.Topic(scope), e)
+	}
+	return results, nil
+}
+
+type eventSync struct {
+	SyncStore
+	broker *events.Broker
+}
+
+// Push publishes an event without the task body for every applied op;
+// listeners refetch the task if they need it.
+func (s eventSync) Push(ctx context.Context, scope models.Scope, defaultStatus string, ops []models.SyncOp) ([]models.SyncResult, int64, error) {
+	results, checkpoint, err := s.SyncStore.Push(ctx, scope, defaultStatus, ops)
+	if err != nil {
+		return results, checkpoint, err
+	}
+	for i, r := range results {
+		if r.Status != models.SyncApplied {
+			continue
+		}
+		e := events.Event{Type: events.TaskUpdated, TaskID: ops[i].TaskID}
+		if ops[i].Type == models.SyncOpDelete {
+			e.Type = events.TaskDeleted
+		}
+		s.broker.Publish(events.Topic(scope), e)
+	}
+	return results, checkpoint, nil
+}
+
+type eventTrash struct {
+	TrashStore
+	broker *events.Broker
+}
+
+// RestoreTask publishes the restored task as created, since listeners saw
+// it deleted.
+func (t eventTrash) RestoreTask(ctx context.Context, scope models.Scope, id string) (*models.Task, error) {
+	task, err := t.TrashStore.RestoreTask(ctx, scope, id)
+	if err == nil {
+		t.broker.Publish(events.Topic(scope), events.Event{Type: events.TaskCreated, TaskID: task.ID, Task: task})
+	}
+	return task, err
+}
+
+// eventOrgAdmin publishes reassigned tasks without their bodies; listeners
+// refetch them if they need to.
+type eventOrgAdmin struct {
+	OrgAdminStore
+	broker *events.Broker
+}
+
+func (a eventOrgAdmin) TransferTasks(ctx context.Context, orgID, fromUserID, toUserID string) ([]models.ReassignedTask, error) {
+	moved, err := a.OrgAdminStore.TransferTasks(ctx, orgID, fromUserID, toUserID)
+	if err == nil {
+		a.publish(orgID, moved)
+	}
+	return moved, err
+}
+
+func (a eventOrgAdmin) ReassignTasks(ctx context.Context, orgID string, ids []string, toUserID string) ([]models.ReassignedTask, error) {
+	moved, err := a.OrgAdminStore.ReassignTasks(ctx, orgID, ids, toUserID)
+	if err == nil {
+		a.publish(orgID, moved)
+	}
+	return moved, err
+}
+
+func (a eventOrgAdmin) publish(orgID string, moved []models.ReassignedTask) {
+	topic := events.Topic(models.Scope{OrgID: orgID})
+	for _, t := range moved {
+		a.broker.Publish(topic, events.Event{Type: events.TaskUpdated, TaskID: t.ID})
+	}
+}
+
+type eventBoards struct {
+	BoardStore
+	broker *events.Broker
+}
+
+func (b eventBoards) Move(ctx context.Context, scope models.Scope, id string, input models.MoveCardInput) (*models.BoardMove, error) {
+	move, err := b.BoardStore.Move(ctx, scope, id, input)
+	if err != nil {
+		return move, err
+	}
+	topic := events.Topic(scope)
+	if move.Task.Status != move.PreviousStatus {
+		b.broker.Publish(topic, events.Event{Type: events.TaskUpdated, TaskID: move.Task.ID, Task: &move.Task})
+	}
+	b.broker.Publish(topic, events.Event{Type: events.BoardCardMoved, TaskID: move.Task.ID, BoardID: id, Move: move})
+	return move, nil
+}
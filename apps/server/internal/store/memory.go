@@ -0,0 +1,812 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+	"yata/apps/server/internal/crdt"
+	"yata/apps/server/internal/models"
+)
+
+// memoryStore is an in-process Store for tests. It mirrors the scoping rules
+// of the Postgres repositories.
+type memoryStore struct {
+	mu       sync.RWMutex
+	tasks    map[string]models.Task
+	projects map[string]models.Project
+	// blockers maps a task id to the set of task ids blocking it.
+	blockers map[string]map[string]bool
+	labels   map[string]models.Label
+	// taskLabels maps a task id to the set of label ids attached to it.
+	taskLabels map[string]map[string]bool
+	workflows  map[string]models.Workflow
+	reminders  map[string]models.Reminder
+	emailPrefs map[string]models.EmailPreferences
+	settings   map[string]models.UserSettings
+	orgConfig  map[string]models.OrgSettings
+	// pushSubs is keyed by endpoint, which is unique across users.
+	pushSubs      map[string]models.PushSubscription
+	apiTokens     map[string]memoryAPIToken
+	imports       map[string]memoryImport
+	exports       map[string]models.DataExport
+	calendarFeeds map[string]memoryCalendarFeed
+	// inboundAddresses is keyed by id.
+	inboundAddresses map[string]models.InboundAddress
+	// gcalConnections is keyed by id; gcalEvents by "connectionID/taskID".
+	gcalConnections map[string]models.GoogleCalendarConnection
+	gcalNextSync    map[string]time.Time
+	gcalEvents      map[string]models.GoogleCalendarEvent
+	// slack is keyed by org id.
+	slack map[string]models.SlackIntegration
+	// webhookDeliveries is keyed by endpoint id, oldest first, and
+	// webhookBreakers by org id and URL.
+	webhooks          map[string]models.WebhookEndpoint
+	webhookDeliveries map[string][]models.WebhookDelivery
+	webhookBreakers   map[[2]string]models.WebhookBreaker
+	// telegram is keyed by user id, telegramCodes by hex code hash.
+	telegram      map[string]models.TelegramLink
+	telegramCodes map[string]memoryTelegramCode
+	notifications map[string]models.Notification
+	// clocks holds the latest sync timestamp per "taskID/field".
+	clocks  map[string]crdt.Timestamp
+	syncLog []memorySyncEntry
+	// changeLog is what the change_log triggers would have written.
+	changeLog []memoryChange
+	changeSeq int64
+	// idempotency is keyed by "userID/orgID/key".
+	idempotency map[string]memoryIdempotentRequest
+	comments    map[string]models.Comment
+	// revisions maps a comment id to its earlier bodies, oldest first.
+	revisions        map[string][]models.CommentRevision
+	attachments      map[string]models.Attachment
+	timeEntries      map[string]models.TimeEntry
+	pomodoros        map[string]models.Pomodoro
+	boards           map[string]models.Board
+	views            map[string]models.View
+	templates        map[string]models.TaskTemplate
+	projectTemplates map[string]models.ProjectTemplate
+	customFields     map[string]models.CustomField
+	digestsSent      map[string]time.Time
+	boardCards       map[string]memoryBoardCard
+	users            map[string]models.User
+	orgs             map[string]models.Organization
+	// memberships is keyed by "orgID/userID".
+	memberships map[string]models.OrgMembership
+	activity    []models.Activity
+	// taskShares and projectShares are keyed by "taskID/userID" and
+	// "projectID/userID".
+	taskShares    map[string]models.Share
+	projectShares map[string]models.Share
+	shareLinks    map[string]models.ShareLink
+	linkAccesses  []models.ShareLinkAccess
+	invitations   map[string]memoryInvitation
+}
+
+func NewMemory() Store {
+	return &memoryStore{
+		tasks:    map[string]models.Task{},
+		projects: map[string]models.Project{},
+		blockers: map[string]map[string]bool{},
+		labels:   map[string]models.Label{},
+
+		taskLabels:    map[string]map[string]bool{},
+		workflows:     map[string]models.Workflow{},
+		reminders:     map[string]models.Reminder{},
+		emailPrefs:    map[string]models.EmailPreferences{},
+		settings:      map[string]models.UserSettings{},
+		orgConfig:     map[string]models.OrgSettings{},
+		pushSubs:      map[string]models.PushSubscription{},
+		apiTokens:     map[string]memoryAPIToken{},
+		imports:       map[string]memoryImport{},
+		exports:       map[string]models.DataExport{},
+		calendarFeeds: map[string]memoryCalendarFeed{},
+
+		inboundAddresses: map[string]models.InboundAddress{},
+
+		gcalConnections:   map[string]models.GoogleCalendarConnection{},
+		gcalNextSync:      map[string]time.Time{},
+		gcalEvents:        map[string]models.GoogleCalendarEvent{},
+		slack:             map[string]models.SlackIntegration{},
+		webhooks:          map[string]models.WebhookEndpoint{},
+		webhookDeliveries: map[string][]models.WebhookDelivery{},
+		webhookBreakers:   map[[2]string]models.WebhookBreaker{},
+		telegram:          map[string]models.TelegramLink{},
+		telegramCodes:     map[string]memoryTelegramCode{},
+
+		notifications:    map[string]models.Notification{},
+		clocks:           map[string]crdt.Timestamp{},
+		idempotency:      map[string]memoryIdempotentRequest{},
+		comments:         map[string]models.Comment{},
+		revisions:        map[string][]models.CommentRevision{},
+		attachments:      map[string]models.Attachment{},
+		timeEntries:      map[string]models.TimeEntry{},
+		pomodoros:        map[string]models.Pomodoro{},
+		boards:           map[string]models.Board{},
+		views:            map[string]models.View{},
+		templates:        map[string]models.TaskTemplate{},
+		projectTemplates: map[string]models.ProjectTemplate{},
+		customFields:     map[string]models.CustomField{},
+		digestsSent:      map[string]time.Time{},
+		boardCards:       map[string]memoryBoardCard{},
+		users:            map[string]models.User{},
+		orgs:             map[string]models.Organization{},
+		memberships:      map[string]models.OrgMembership{},
+		taskShares:       map[string]models.Share{},
+		projectShares:    map[string]models.Share{},
+		shareLinks:       map[string]models.ShareLink{},
+		invitations:      map[string]memoryInvitation{},
+	}
+}
+
+func (s *memoryStore) Tasks() TaskStore                         { return memoryTasks{s} }
+func (s *memoryStore) Projects() ProjectStore                   { return memoryProjects{s} }
+func (s *memoryStore) Labels() LabelStore                       { return memoryLabels{s} }
+func (s *memoryStore) Workflows() WorkflowStore                 { return memoryWorkflows{s} }
+func (s *memoryStore) Reminders() ReminderStore                 { return memoryReminders{s} }
+func (s *memoryStore) EmailPreferences() EmailPreferenceStore   { return memoryEmailPreferences{s} }
+func (s *memoryStore) UserSettings() UserSettingsStore          { return memoryUserSettings{s} }
+func (s *memoryStore) OrgSettings() OrgSettingsStore            { return memoryOrgSettings{s} }
+func (s *memoryStore) PushSubscriptions() PushSubscriptionStore { return memoryPushSubscriptions{s} }
+func (s *memoryStore) APITokens() APITokenStore                 { return memoryAPITokens{s} }
+func (s *memoryStore) Imports() ImportStore                     { return memoryImports{s} }
+func (s *memoryStore) Accounts() AccountStore                   { return memoryAccounts{s} }
+func (s *memoryStore) CalendarFeeds() CalendarFeedStore         { return memoryCalendarFeeds{s} }
+func (s *memoryStore) InboundAddresses() InboundAddressStore    { return memoryInboundAddresses{s} }
+func (s *memoryStore) GoogleCalendar() GoogleCalendarStore      { return memoryGoogleCalendar{s} }
+func (s *memoryStore) Slack() SlackStore                        { return memorySlack{s} }
+func (s *memoryStore) Webhooks() WebhookStore                   { return memoryWebhooks{s} }
+func (s *memoryStore) Telegram() TelegramStore                  { return memoryTelegram{s} }
+func (s *memoryStore) Notifications() NotificationStore         { return memoryNotifications{s} }
+func (s *memoryStore) Sync() SyncStore                          { return memorySync{s} }
+func (s *memoryStore) Changes() ChangeStore                     { return memoryChanges{s} }
+func (s *memoryStore) Usage() UsageStore                        { return memoryUsage{s} }
+func (s *memoryStore) Idempotency() IdempotencyStore            { return memoryIdempotency{s} }
+func (s *memoryStore) Trash() TrashStore                        { return memoryTrash{s} }
+func (s *memoryStore) Comments() CommentStore                   { return memoryComments{s} }
+func (s *memoryStore) Attachments() AttachmentStore             { return memoryAttachments{s} }
+func (s *memoryStore) TimeEntries() TimeEntryStore              { return memoryTimeEntries{s} }
+func (s *memoryStore) Pomodoros() PomodoroStore                 { return memoryPomodoros{s} }
+func (s *memoryStore) Stats() StatsStore                        { return memoryStats{s} }
+func (s *memoryStore) OrgAdmin() OrgAdminStore                  { return memoryOrgAdmin{s} }
+func (s *memoryStore) Boards() BoardStore                       { return memoryBoards{s} }
+func (s *memoryStore) Views() ViewStore                         { return memoryViews{s} }
+func (s *memoryStore) Templates() TemplateStore                 { return memoryTemplates{s} }
+func (s *memoryStore) ProjectTemplates() ProjectTemplateStore   { return memoryProjectTemplates{s} }
+func (s *memoryStore) CustomFields() CustomFieldStore           { return memoryCustomFields{s} }
+func (s *memoryStore) Digests() DigestStore                     { return memoryDigests{s} }
+func (s *memoryStore) Shares() ShareStore                       { return memoryShares{s} }
+func (s *memoryStore) ShareLinks() ShareLinkStore               { return memoryShareLinks{s} }
+func (s *memoryStore) Invitations() InvitationStore             { return memoryInvitations{s} }
+func (s *memoryStore) Users() UserStore                         { return memoryUsers{s} }
+func (s *memoryStore) Activity() ActivityStore                  { return memoryActivity{s} }
+func (s *memoryStore) Search() SearchStore                      { return memorySearch{s} }
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// limit mirrors the repositories returning one row past the page size.
+func limit[T any](items []T, pageLimit int) []T {
+	if pageLimit > 0 && len(items) > pageLimit+1 {
+		return items[:pageLimit+1]
+	}
+	return items
+}
+
+func inScope(scope models.Scope, ownerID string, orgID *string) bool {
+	if scope.IsOrg() {
+		return orgID != nil && *orgID == scope.OrgID
+	}
+	return orgID == nil && ownerID == scope.UserID
+}
+
+// canAccess is inScope for tasks, with visibility applied: a private org
+// task, or any org task for a guest, is left out unless the caller owns it
+// or holds a share of at least role on it or its project; callers hold the
+// lock.
+func (s *memoryStore) canAccess(scope models.Scope, t models.Task, role string) bool {
+	if !inScope(scope, t.OwnerID, t.OrgID) {
+		return false
+	}
+	if !scope.IsOrg() || (t.Visibility == models.TaskVisibilityOrg && !scope.Guest) || t.OwnerID == scope.UserID {
+		return true
+	}
+	grants := func(share models.Share, ok bool) bool {
+		return ok && (role == models.ShareRoleViewer || share.Role == models.ShareRoleEditor)
+	}
+	if share, ok := s.taskShares[t.ID+"/"+scope.UserID]; grants(share, ok) {
+		return true
+	}
+	if t.ProjectID != nil {
+		share, ok := s.projectShares[*t.ProjectID+"/"+scope.UserID]
+		return grants(share, ok)
+	}
+	return false
+}
+
+// liveTask looks up a task that the scope can see and that's not in the
+// trash; callers hold the lock.
+func (s *memoryStore) liveTask(scope models.Scope, id string) (models.Task, bool) {
+	t, ok := s.tasks[id]
+	return t, ok && t.DeletedAt == nil && s.canAccess(scope, t, models.ShareRoleViewer)
+}
+
+// editableTask is liveTask for tasks the scope can change.
+func (s *memoryStore) editableTask(scope models.Scope, id string) (models.Task, bool) {
+	t, ok := s.tasks[id]
+	return t, ok && t.DeletedAt == nil && s.canAccess(scope, t, models.ShareRoleEditor)
+}
+
+// writeMiss is the error for a write to a task editableTask didn't find,
+// matching the repository's; callers hold the lock.
+func (s *memoryStore) writeMiss(scope models.Scope, id string) error {
+	if _, ok := s.liveTask(scope, id); ok {
+		return ErrReadOnly
+	}
+	return ErrNotFound
+}
+
+// setCompletedAt keeps t.CompletedAt in step with a status it's just been
+// given, as the repository's trigger does; callers hold the lock.
+func (s *memoryStore) setCompletedAt(t *models.Task, now time.Time) {
+	orgID := ""
+	if t.OrgID != nil {
+		orgID = *t.OrgID
+	}
+	if !(memoryWorkflows{s}).get(orgID).IsDone(t.Status) {
+		t.CompletedAt = nil
+	} else if t.CompletedAt == nil {
+		t.CompletedAt = &now
+	}
+}
+
+type memoryTasks struct{ s *memoryStore }
+
+func (m memoryTasks) Create(_ context.Context, scope models.Scope, input models.CreateTaskInput) (*models.Task, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t := m.s.insertTask(scope, scope.UserID, input, m.s.nextPosition(scope), time.Now().UTC())
+	return &t, nil
+}
+
+// insertTask stores a new task owned by ownerID; callers hold the lock.
+func (s *memoryStore) insertTask(scope models.Scope, ownerID string, input models.CreateTaskInput, position string, now time.Time) models.Task {
+	status := input.Status
+	if status == "" {
+		status = models.TaskStatusTodo
+	}
+	visibility := input.Visibility
+	if visibility == "" {
+		visibility = models.TaskVisibilityOrg
+	}
+	fields := maps.Clone(input.CustomFields)
+	if fields == nil {
+		fields = map[string]any{}
+	}
+
+	t := models.Task{
+		ID:              newID(),
+		OwnerID:         ownerID,
+		OrgID:           scope.OrgIDPtr(),
+		ProjectID:       input.ProjectID,
+		ParentID:        input.ParentID,
+		Title:           input.Title,
+		Description:     input.Description,
+		Status:          status,
+		Priority:        input.Priority,
+		DueDate:         input.DueDate,
+		DueTimezone:     input.DueTimezone,
+		Recurrence:      input.Recurrence,
+		EstimateMinutes: input.EstimateMinutes,
+		Visibility:      visibility,
+		Version:         1,
+		Position:        position,
+		CustomFields:    fields,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	s.setCompletedAt(&t, now)
+	s.tasks[t.ID] = t
+	s.logTask(t)
+	return t
+}
+
+func (m memoryTasks) Visible(_ context.Context, scope models.Scope, ids []string) ([]string, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	var visible []string
+	for _, id := range ids {
+		if t, ok := m.s.tasks[id]; ok && m.s.canAccess(scope, t, models.ShareRoleViewer) {
+			visible = append(visible, id)
+		}
+	}
+	return visible, nil
+}
+
+func (m memoryTasks) Get(_ context.Context, scope models.Scope, id string) (*models.Task, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	t, ok := m.s.liveTask(scope, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+func (m memoryTasks) List(_ context.Context, scope models.Scope, filter models.TaskFilter, page models.Page) ([]models.Task, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, t := range m.s.tasks {
+		if t.DeletedAt == nil && m.s.canAccess(scope, t, models.ShareRoleViewer) && matchesTaskFilter(&t, filter, m.s.taskLabels[t.ID]) {
+			tasks = append(tasks, t)
+		}
+	}
+
+	sort.Slice(tasks, func(i, j int) bool {
+		return compareTasks(&tasks[i], &tasks[j], filter.Sort) < 0
+	})
+
+	if len(page.After) == len(filter.Sort)+1 {
+		idx := sort.Search(len(tasks), func(i int) bool {
+			return compareTaskToCursor(&tasks[i], filter.Sort, page.After) > 0
+		})
+		tasks = tasks[idx:]
+	}
+
+	return limit(tasks, page.Limit), nil
+}
+
+func (m memoryTasks) Update(_ context.Context, scope models.Scope, id string, input models.UpdateTaskInput) (*models.Task, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, ok := m.s.editableTask(scope, id)
+	if !ok {
+		return nil, m.s.writeMiss(scope, id)
+	}
+	if input.IfVersion != nil && t.Version != *input.IfVersion {
+		return nil, ErrVersionMismatch
+	}
+	before := t
+
+	if input.Title != nil {
+		t.Title = *input.Title
+	}
+	if input.Description != nil {
+		t.Description = *input.Description
+	}
+	if input.Status != nil && *input.Status != t.Status {
+		t.Status = *input.Status
+		m.s.setCompletedAt(&t, time.Now().UTC())
+	}
+	if input.Priority != nil {
+		t.Priority = *input.Priority
+	}
+	if input.DueDate.Set {
+		t.DueDate = input.DueDate.Ptr()
+	}
+	if input.DueTimezone.Set {
+		t.DueTimezone = input.DueTimezone.Ptr()
+	}
+	if input.Recurrence.Set {
+		t.Recurrence = input.Recurrence.Ptr()
+	}
+	if input.EstimateMinutes.Set {
+		t.EstimateMinutes = input.EstimateMinutes.Ptr()
+	}
+	if input.ProjectID.Set {
+		t.ProjectID = input.ProjectID.Ptr()
+	}
+	if input.Visibility != nil {
+		t.Visibility = *input.Visibility
+	}
+	if len(input.CustomFields) > 0 {
+		t.CustomFields = maps.Clone(t.CustomFields)
+		for key, value := range input.CustomFields {
+			if value == nil {
+				delete(t.CustomFields, key)
+			} else {
+				t.CustomFields[key] = value
+			}
+		}
+	}
+	// An empty update is a read, like in the repository.
+	if !input.Empty() {
+		t.Version = before.Version + 1
+		t.UpdatedAt = time.Now().UTC()
+	}
+
+	m.s.tasks[id] = t
+	m.s.logTask(t)
+	return &t, nil
+}
+
+func (m memoryTasks) Delete(_ context.Context, scope models.Scope, id string, ifVersion *int) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, ok := m.s.editableTask(scope, id)
+	if !ok {
+		return m.s.writeMiss(scope, id)
+	}
+	if ifVersion != nil && t.Version != *ifVersion {
+		return ErrVersionMismatch
+	}
+
+	m.trash(id)
+	return nil
+}
+
+// trash moves the task and its live subtasks to the trash, all stamped with
+// the same time so RestoreTask brings them back together; callers hold the
+// lock.
+func (m memoryTasks) trash(id string) {
+	now := time.Now().UTC()
+	for _, d := range m.descendants(id) {
+		d.DeletedAt = &now
+		d.UpdatedAt = now
+		m.s.tasks[d.ID] = d
+		m.s.logTask(d)
+	}
+	t := m.s.tasks[id]
+	t.DeletedAt = &now
+	t.Version++
+	t.UpdatedAt = now
+	m.s.tasks[id] = t
+	m.s.logTask(t)
+}
+
+// deleteTask drops the task and any dependency edges touching it; callers
+// hold the lock.
+func (m memoryTasks) deleteTask(id string) {
+	m.s.logTask(m.s.tasks[id])
+	delete(m.s.tasks, id)
+	delete(m.s.blockers, id)
+	delete(m.s.taskLabels, id)
+	for rid, r := range m.s.reminders {
+		if r.TaskID == id {
+			delete(m.s.reminders, rid)
+		}
+	}
+	for nid, n := range m.s.notifications {
+		if n.TaskID != nil && *n.TaskID == id {
+			delete(m.s.notifications, nid)
+		}
+	}
+	for cid, c := range m.s.comments {
+		if c.TaskID == id {
+			delete(m.s.comments, cid)
+			delete(m.s.revisions, cid)
+		}
+	}
+	for aid, a := range m.s.attachments {
+		if a.TaskID == id {
+			delete(m.s.attachments, aid)
+		}
+	}
+	for eid, e := range m.s.timeEntries {
+		if e.TaskID == id {
+			delete(m.s.timeEntries, eid)
+		}
+	}
+	for key, card := range m.s.boardCards {
+		if card.taskID == id {
+			delete(m.s.boardCards, key)
+		}
+	}
+	for pid, p := range m.s.pomodoros {
+		if p.TaskID != nil && *p.TaskID == id {
+			p.TaskID = nil
+			m.s.pomodoros[pid] = p
+		}
+	}
+	for key, share := range m.s.taskShares {
+		if *share.TaskID == id {
+			delete(m.s.taskShares, key)
+		}
+	}
+	for lid, l := range m.s.shareLinks {
+		if l.TaskID != nil && *l.TaskID == id {
+			m.s.deleteShareLink(lid)
+		}
+	}
+	for _, set := range m.s.blockers {
+		delete(set, id)
+	}
+}
+
+// descendants walks the live tree breadth first; callers hold the lock.
+func (m memoryTasks) descendants(id string) []models.Task {
+	out := []models.Task{}
+	queue := []string{id}
+	for len(queue) > 0 {
+		parent := queue[0]
+		queue = queue[1:]
+
+		children := []models.Task{}
+		for _, t := range m.s.tasks {
+			if t.ParentID != nil && *t.ParentID == parent && t.DeletedAt == nil {
+				children = append(children, t)
+			}
+		}
+		sort.Slice(children, func(i, j int) bool {
+			if !children[i].CreatedAt.Equal(children[j].CreatedAt) {
+				return children[i].CreatedAt.Before(children[j].CreatedAt)
+			}
+			return children[i].Position < children[j].Position
+		})
+
+		for _, c := range children {
+			out = append(out, c)
+			queue = append(queue, c.ID)
+		}
+	}
+	return out
+}
+
+func (m memoryTasks) Subtree(_ context.Context, scope models.Scope, id string) ([]models.Task, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	_, ok := m.s.liveTask(scope, id)
+	if !ok {
+		return []models.Task{}, nil
+	}
+	return m.descendants(id), nil
+}
+
+func (m memoryTasks) Progress(ctx context.Context, scope models.Scope, id string, doneStatuses []string) (models.TaskProgress, error) {
+	subtree, err := m.Subtree(ctx, scope, id)
+	if err != nil {
+		return models.TaskProgress{}, err
+	}
+
+	done := 0
+	for _, t := range subtree {
+		if slices.Contains(doneStatuses, t.Status) {
+			done++
+		}
+	}
+	return models.NewTaskProgress(len(subtree), done), nil
+}
+
+func (m memoryTasks) SubtaskEstimate(_ context.Context, scope models.Scope, id string) (*int, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if _, ok := m.s.liveTask(scope, id); !ok {
+		return nil, nil
+	}
+	return models.RollUpEstimate(m.s.subtasksByParent(scope), id), nil
+}
+
+// subtasksByParent indexes the live tasks the scope can see by their
+// parent's id; callers hold the lock.
+func (s *memoryStore) subtasksByParent(scope models.Scope) map[string][]models.Task {
+	children := map[string][]models.Task{}
+	for _, t := range s.tasks {
+		if t.ParentID != nil && t.DeletedAt == nil && s.canAccess(scope, t, models.ShareRoleViewer) {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+	return children
+}
+
+func (m memoryTasks) Dependencies(_ context.Context, scope models.Scope, id string) (models.TaskDependencies, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	deps := models.TaskDependencies{BlockedBy: []string{}, Blocks: []string{}}
+	_, ok := m.s.liveTask(scope, id)
+	if !ok {
+		return deps, nil
+	}
+
+	// Edges to trashed tasks are kept for a restore but not reported.
+	for blocker := range m.s.blockers[id] {
+		if m.s.tasks[blocker].DeletedAt == nil {
+			deps.BlockedBy = append(deps.BlockedBy, blocker)
+		}
+	}
+	for blocked, set := range m.s.blockers {
+		if set[id] && m.s.tasks[blocked].DeletedAt == nil {
+			deps.Blocks = append(deps.Blocks, blocked)
+		}
+	}
+	sort.Strings(deps.BlockedBy)
+	sort.Strings(deps.Blocks)
+	return deps, nil
+}
+
+func (m memoryTasks) AddDependency(_ context.Context, scope models.Scope, id, blockedByID string) error {
+	if id == blockedByID {
+		return ErrDependencyCycle
+	}
+
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, tid := range []string{id, blockedByID} {
+		_, ok := m.s.editableTask(scope, tid)
+		if !ok {
+			return ErrNotFound
+		}
+	}
+
+	// Walk everything id blocks; if blockedByID is among them the new edge
+	// would close a loop.
+	seen := map[string]bool{}
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for blocked, set := range m.s.blockers {
+			if set[cur] && !seen[blocked] {
+				if blocked == blockedByID {
+					return ErrDependencyCycle
+				}
+				seen[blocked] = true
+				queue = append(queue, blocked)
+			}
+		}
+	}
+
+	if m.s.blockers[id] == nil {
+		m.s.blockers[id] = map[string]bool{}
+	}
+	m.s.blockers[id][blockedByID] = true
+	return nil
+}
+
+func (m memoryTasks) RemoveDependency(_ context.Context, scope models.Scope, id, blockedByID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	_, ok := m.s.editableTask(scope, id)
+	if !ok || !m.s.blockers[id][blockedByID] {
+		return ErrNotFound
+	}
+	delete(m.s.blockers[id], blockedByID)
+	return nil
+}
+
+type memoryProjects struct{ s *memoryStore }
+
+func (m memoryProjects) Create(_ context.Context, orgID, userID string, input models.CreateProjectInput) (*models.Project, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	p := models.Project{
+		ID:        newID(),
+		OrgID:     orgID,
+		Name:      input.Name,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.projects[p.ID] = p
+	m.s.logProject(p)
+	return &p, nil
+}
+
+func (m memoryProjects) CreateSeeded(ctx context.Context, scope models.Scope, input models.NewProject) (*models.SeededProject, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	p := models.Project{
+		ID:        newID(),
+		OrgID:     scope.OrgID,
+		Name:      input.Input.Name,
+		CreatedBy: scope.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.projects[p.ID] = p
+	m.s.logProject(p)
+	seeded := &models.SeededProject{Project: p}
+	if input.Board != nil {
+		b := withColumns(models.Board{
+			ID:        newID(),
+			OrgID:     scope.OrgID,
+			ProjectID: p.ID,
+			Name:      input.Board.Name,
+			CreatedBy: scope.UserID,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}, input.Board.Columns)
+		m.s.boards[b.ID] = b
+		seeded.Board = copyBoard(b)
+	}
+	for i := range input.Tasks {
+		input.Tasks[i].Input.ProjectID = &p.ID
+	}
+	seeded.Tasks = m.s.insertTaskBatch(scope, input.Tasks)
+	return seeded, nil
+}
+
+func (m memoryProjects) Get(_ context.Context, orgID, id string) (*models.Project, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	p, ok := m.s.projects[id]
+	if !ok || p.OrgID != orgID || p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (m memoryProjects) List(_ context.Context, orgID string, includeArchived bool, page models.Page) ([]models.Project, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, p := range m.s.projects {
+		if p.OrgID != orgID || p.DeletedAt != nil || (!includeArchived && p.ArchivedAt != nil) {
+			continue
+		}
+		projects = append(projects, p)
+	}
+
+	sort.Slice(projects, func(i, j int) bool {
+		if projects[i].Name != projects[j].Name {
+			return projects[i].Name < projects[j].Name
+		}
+		return projects[i].ID < projects[j].ID
+	})
+
+	if len(page.After) == 2 {
+		idx := sort.Search(len(projects), func(i int) bool {
+			p := projects[i]
+			return p.Name > page.After[0] || (p.Name == page.After[0] && p.ID > page.After[1])
+		})
+		projects = projects[idx:]
+	}
+
+	return limit(projects, page.Limit), nil
+}
+
+func (m memoryProjects) Rename(_ context.Context, orgID, id, name string) (*models.Project, error) {
+	return m.update(orgID, id, func(p *models.Project) { p.Name = name })
+}
+
+func (m memoryProjects) SetArchived(_ context.Context, orgID, id string, archived bool) (*models.Project, error) {
+	return m.update(orgID, id, func(p *models.Project) {
+		if !archived {
+			p.ArchivedAt = nil
+		} else if p.ArchivedAt == nil {
+			now := time.Now().UTC()
+			p.ArchivedAt = &now
+		}
+	})
+}
+
+func (m memoryProjects) update(orgID, id string, fn func(p *models.Project)) (*models.Project, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	p, ok := m.s.projects[id]
+	if !ok || p.OrgID != orgID || p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	fn(&p)
+	p.UpdatedAt = time.Now().UTC()
+	m.s.projects[id] = p
+	m.s.logProject(p)
+	return &p, nil
+}
@@ -0,0 +1,342 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryAccounts struct{ s *memoryStore }
+
+func (m memoryAccounts) CreateExport(_ context.Context, userID string) (*models.DataExport, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, e := range m.s.exports {
+		if e.UserID == userID && (e.Status == models.DataExportPending || e.Status == models.DataExportRunning) {
+			return &e, nil
+		}
+	}
+	e := models.DataExport{ID: newID(), UserID: userID, Status: models.DataExportPending, CreatedAt: time.Now().UTC()}
+	m.s.exports[e.ID] = e
+	return &e, nil
+}
+
+func (m memoryAccounts) GetExport(_ context.Context, userID, id string) (*models.DataExport, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	e, ok := m.s.exports[id]
+	if !ok || e.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return &e, nil
+}
+
+func (m memoryAccounts) StartExport(_ context.Context, id string) (*models.DataExport, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	e, ok := m.s.exports[id]
+	if !ok || e.Status != models.DataExportPending {
+		return nil, ErrNotFound
+	}
+	e.Status = models.DataExportRunning
+	m.s.exports[id] = e
+	return &e, nil
+}
+
+func (m memoryAccounts) FinishExport(_ context.Context, id, key string, size int64, expiresAt time.Time) error {
+	return m.finish(id, func(e *models.DataExport) {
+		e.Status = models.DataExportDone
+		e.Key = &key
+		e.Size = &size
+		e.ExpiresAt = &expiresAt
+	})
+}
+
+func (m memoryAccounts) FailExport(_ context.Context, id, message string) error {
+	return m.finish(id, func(e *models.DataExport) {
+		e.Status = models.DataExportFailed
+		e.Error = &message
+	})
+}
+
+func (m memoryAccounts) finish(id string, apply func(*models.DataExport)) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	e, ok := m.s.exports[id]
+	if !ok {
+		return ErrNotFound
+	}
+	apply(&e)
+	now := time.Now().UTC()
+	e.FinishedAt = &now
+	m.s.exports[id] = e
+	return nil
+}
+
+// collectSorted returns the values matching keep, oldest first like the
+// repository's.
+func collectSorted[T any](values map[string]T, keep func(T) bool, at func(T) time.Time, id func(T) string) []T {
+	list := []T{}
+	for _, v := range values {
+		if keep(v) {
+			list = append(list, v)
+		}
+	}
+	slices.SortFunc(list, func(a, b T) int {
+		if c := at(a).Compare(at(b)); c != 0 {
+			return c
+		}
+		return strings.Compare(id(a), id(b))
+	})
+	return list
+}
+
+func (m memoryAccounts) Collect(_ context.Context, userID string) (*models.AccountData, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	data := &models.AccountData{
+		Memberships:      []models.OrgMembership{},
+		Settings:         models.DefaultUserSettings(userID),
+		EmailPreferences: models.DefaultEmailPreferences(userID),
+		Activity:         []models.Activity{},
+	}
+	if u, ok := m.s.users[userID]; ok {
+		data.User = &u
+	}
+	for _, membership := range m.s.memberships {
+		if membership.UserID == userID {
+			data.Memberships = append(data.Memberships, membership)
+		}
+	}
+	slices.SortFunc(data.Memberships, func(a, b models.OrgMembership) int { return strings.Compare(a.OrgID, b.OrgID) })
+	if s, ok := m.s.settings[userID]; ok {
+		data.Settings = s
+	}
+	if p, ok := m.s.emailPrefs[userID]; ok {
+		data.EmailPreferences = p
+	}
+
+	data.Tasks = collectSorted(m.s.tasks,
+		func(t models.Task) bool { return t.OwnerID == userID },
+		func(t models.Task) time.Time { return t.CreatedAt },
+		func(t models.Task) string { return t.ID })
+	data.Comments = collectSorted(m.s.comments,
+		func(c models.Comment) bool { return c.AuthorID == userID },
+		func(c models.Comment) time.Time { return c.CreatedAt },
+		func(c models.Comment) string { return c.ID })
+	data.TimeEntries = collectSorted(m.s.timeEntries,
+		func(e models.TimeEntry) bool { return e.UserID == userID },
+		func(e models.TimeEntry) time.Time { return e.StartedAt },
+		func(e models.TimeEntry) string { return e.ID })
+	now := time.Now()
+	for i := range data.TimeEntries {
+		data.TimeEntries[i].SetSeconds(now)
+	}
+	data.Pomodoros = collectSorted(m.s.pomodoros,
+		func(p models.Pomodoro) bool { return p.UserID == userID },
+		func(p models.Pomodoro) time.Time { return p.StartedAt },
+		func(p models.Pomodoro) string { return p.ID })
+	data.Notifications = collectSorted(m.s.notifications,
+		func(n models.Notification) bool { return n.UserID == userID },
+		func(n models.Notification) time.Time { return n.CreatedAt },
+		func(n models.Notification) string { return n.ID })
+	// activity is recorded in order already.
+	for _, a := range m.s.activity {
+		if a.ActorID == userID {
+			data.Activity = append(data.Activity, a)
+		}
+	}
+	return data, nil
+}
+
+func (m memoryAccounts) Erase(_ context.Context, userID string) (models.ErasedAccount, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	s := m.s
+	deletedID := models.DeletedUserID
+	swap := func(id *string) {
+		if *id == userID {
+			*id = deletedID
+		}
+	}
+
+	// Tasks only they could see, and their subtasks, as the cascade would.
+	doomed := map[string]bool{}
+	for id, t := range s.tasks {
+		if t.OwnerID == userID && (t.OrgID == nil || t.Visibility == models.TaskVisibilityPrivate) {
+			doomed[id] = true
+		}
+	}
+	for grew := true; grew; {
+		grew = false
+		for id, t := range s.tasks {
+			if !doomed[id] && t.ParentID != nil && doomed[*t.ParentID] {
+				doomed[id] = true
+				grew = true
+			}
+		}
+	}
+	tasks := memoryTasks{s}
+	for id := range doomed {
+		tasks.deleteTask(id)
+	}
+	s.syncLog = slices.DeleteFunc(s.syncLog, func(e memorySyncEntry) bool { return e.ownerID == userID && e.orgID == nil })
+
+	// What only concerns them.
+	deleteWhere(s.reminders, func(r models.Reminder) bool { return r.UserID == userID })
+	deleteWhere(s.notifications, func(n models.Notification) bool { return n.UserID == userID })
+	deleteWhere(s.pomodoros, func(p models.Pomodoro) bool { return p.UserID == userID })
+	deleteWhere(s.taskShares, func(sh models.Share) bool { return sh.UserID == userID })
+	deleteWhere(s.projectShares, func(sh models.Share) bool { return sh.UserID == userID })
+	deleteWhere(s.views, func(v models.View) bool { return v.OwnerID == userID })
+	deleteWhere(s.templates, func(t models.TaskTemplate) bool { return t.OwnerID == userID })
+	deleteWhere(s.pushSubs, func(p models.PushSubscription) bool { return p.UserID == userID })
+	deleteWhere(s.apiTokens, func(t memoryAPIToken) bool { return t.UserID == userID })
+	deleteWhere(s.calendarFeeds, func(f memoryCalendarFeed) bool { return f.UserID == userID })
+	deleteWhere(s.telegramCodes, func(c memoryTelegramCode) bool { return c.scope.UserID == userID })
+	deleteWhere(s.inboundAddresses, func(a models.InboundAddress) bool { return a.UserID == userID })
+	deleteWhere(s.imports, func(i memoryImport) bool { return i.UserID == userID })
+	for id, c := range s.gcalConnections {
+		if c.UserID == userID {
+			delete(s.gcalConnections, id)
+			delete(s.gcalNextSync, id)
+			for key := range s.gcalEvents {
+				if strings.HasPrefix(key, id+"/") {
+					delete(s.gcalEvents, key)
+				}
+			}
+		}
+	}
+	for key := range s.idempotency {
+		if strings.HasPrefix(key, userID+"/") {
+			delete(s.idempotency, key)
+		}
+	}
+	delete(s.settings, userID)
+	delete(s.emailPrefs, userID)
+	delete(s.telegram, userID)
+	delete(s.digestsSent, userID)
+
+	// What the orgs they were in keep.
+	now := time.Now().UTC()
+	for id, t := range s.tasks {
+		swap(&t.OwnerID)
+		s.tasks[id] = t
+		s.logTask(t)
+	}
+	for i := range s.syncLog {
+		swap(&s.syncLog[i].ownerID)
+	}
+	for id, c := range s.comments {
+		swap(&c.AuthorID)
+		s.comments[id] = c
+	}
+	for i := range s.activity {
+		swap(&s.activity[i].ActorID)
+	}
+	for id, n := range s.notifications {
+		if n.ActorID != nil && *n.ActorID == userID {
+			n.ActorID = nil
+			s.notifications[id] = n
+		}
+	}
+	for id, e := range s.timeEntries {
+		if e.UserID == userID {
+			e.UserID = deletedID
+			if e.EndedAt == nil {
+				e.EndedAt = &now
+			}
+			e.UpdatedAt = now
+			s.timeEntries[id] = e
+		}
+	}
+	for id, a := range s.attachments {
+		swap(&a.UploaderID)
+		s.attachments[id] = a
+	}
+	for id, p := range s.projects {
+		swap(&p.CreatedBy)
+		s.projects[id] = p
+		s.logProject(p)
+	}
+	for id, l := range s.labels {
+		swap(&l.CreatedBy)
+		s.labels[id] = l
+		s.logLabel(l)
+	}
+	for id, b := range s.boards {
+		swap(&b.CreatedBy)
+		s.boards[id] = b
+	}
+	for id, t := range s.projectTemplates {
+		swap(&t.CreatedBy)
+		s.projectTemplates[id] = t
+	}
+	for id, f := range s.customFields {
+		swap(&f.CreatedBy)
+		s.customFields[id] = f
+	}
+	for id, w := range s.webhooks {
+		swap(&w.CreatedBy)
+		s.webhooks[id] = w
+	}
+	for id, l := range s.shareLinks {
+		swap(&l.CreatedBy)
+		s.shareLinks[id] = l
+	}
+	for key, sh := range s.taskShares {
+		swap(&sh.CreatedBy)
+		s.taskShares[key] = sh
+	}
+	for key, sh := range s.projectShares {
+		swap(&sh.CreatedBy)
+		s.projectShares[key] = sh
+	}
+	for id, inv := range s.invitations {
+		swap(&inv.InvitedBy)
+		if inv.AcceptedBy != nil && *inv.AcceptedBy == userID {
+			inv.AcceptedBy = &deletedID
+		}
+		s.invitations[id] = inv
+	}
+
+	var erased models.ErasedAccount
+	for key, membership := range s.memberships {
+		if membership.UserID == userID {
+			erased.OrgIDs = append(erased.OrgIDs, membership.OrgID)
+			delete(s.memberships, key)
+		}
+	}
+	for id, e := range s.exports {
+		if e.UserID == userID {
+			if e.Key != nil {
+				erased.ExportKeys = append(erased.ExportKeys, *e.Key)
+			}
+			delete(s.exports, id)
+		}
+	}
+
+	user := models.User{ID: userID, UpdatedAt: now, DeletedAt: &now}
+	if u, ok := s.users[userID]; ok && u.DeletedAt != nil {
+		user.DeletedAt = u.DeletedAt
+	}
+	s.users[userID] = user
+	return erased, nil
+}
+
+// deleteWhere drops the entries of m matching drop; callers hold the lock.
+func deleteWhere[T any](m map[string]T, drop func(T) bool) {
+	for key, v := range m {
+		if drop(v) {
+			delete(m, key)
+		}
+	}
+}
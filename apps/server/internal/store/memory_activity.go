@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryActivity struct{ s *memoryStore }
+
+func (m memoryActivity) Record(_ context.Context, input models.CreateActivityInput) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	changes := input.Changes
+	if changes == nil {
+		changes = map[string]models.FieldChange{}
+	}
+	m.s.activity = append(m.s.activity, models.Activity{
+		ID:        newID(),
+		OrgID:     input.OrgID,
+		ActorID:   input.ActorID,
+		TaskID:    input.TaskID,
+		ProjectID: input.ProjectID,
+		Action:    input.Action,
+		Changes:   changes,
+		CreatedAt: time.Now().UTC(),
+	})
+	return nil
+}
+
+func (m memoryActivity) ListForTask(_ context.Context, scope models.Scope, taskID string, page models.Page) ([]models.Activity, error) {
+	return m.list(page, func(a models.Activity) bool {
+		if a.TaskID == nil || *a.TaskID != taskID {
+			return false
+		}
+		return inScope(scope, a.ActorID, a.OrgID)
+	})
+}
+
+func (m memoryActivity) ListForOrg(_ context.Context, orgID string, page models.Page) ([]models.Activity, error) {
+	return m.list(page, func(a models.Activity) bool { return a.OrgID != nil && *a.OrgID == orgID })
+}
+
+func (m memoryActivity) ListOwnerChanges(_ context.Context, scope models.Scope, taskID string) ([]models.Activity, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.Activity{}
+	for _, a := range m.s.activity {
+		if _, ok := a.Changes["ownerId"]; !ok || a.TaskID == nil || *a.TaskID != taskID {
+			continue
+		}
+		if inScope(scope, a.ActorID, a.OrgID) {
+			list = append(list, a)
+		}
+	}
+	// Entries are appended as they're recorded, so ties keep that order.
+	sort.SliceStable(list, func(i, j int) bool { return list[i].CreatedAt.Before(list[j].CreatedAt) })
+	return list, nil
+}
+
+func (m memoryActivity) list(page models.Page, match func(models.Activity) bool) ([]models.Activity, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.Activity{}
+	for _, a := range m.s.activity {
+		if match(a) {
+			list = append(list, a)
+		}
+	}
+
+	// older reports whether a sorts after (t, id) in newest-first order.
+	older := func(a models.Activity, t time.Time, id string) bool {
+		if !a.CreatedAt.Equal(t) {
+			return a.CreatedAt.Before(t)
+		}
+		return a.ID < id
+	}
+	sort.Slice(list, func(i, j int) bool { return older(list[j], list[i].CreatedAt, list[i].ID) })
+
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		idx := sort.Search(len(list), func(i int) bool { return older(list[i], t, page.After[1]) })
+		list = list[idx:]
+	}
+
+	return limit(list, page.Limit), nil
+}
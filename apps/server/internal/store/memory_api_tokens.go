@@ -0,0 +1,92 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+// memoryAPIToken keeps the token hash the model leaves out.
+type memoryAPIToken struct {
+	models.APIToken
+	tokenHash []byte
+}
+
+type memoryAPITokens struct{ s *memoryStore }
+
+func (m memoryAPITokens) Create(_ context.Context, scope models.Scope, input models.CreateAPITokenInput) (*models.APIToken, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, t := range m.s.apiTokens {
+		if bytes.Equal(t.tokenHash, input.TokenHash) {
+			return nil, ErrConflict
+		}
+	}
+	t := memoryAPIToken{
+		APIToken: models.APIToken{
+			ID:        newID(),
+			UserID:    scope.UserID,
+			OrgID:     scope.OrgIDPtr(),
+			Name:      input.Name,
+			Prefix:    input.Prefix,
+			Scopes:    append([]string(nil), input.Scopes...),
+			ExpiresAt: input.ExpiresAt,
+			CreatedAt: time.Now().UTC(),
+		},
+		tokenHash: input.TokenHash,
+	}
+	m.s.apiTokens[t.ID] = t
+	return &t.APIToken, nil
+}
+
+func (m memoryAPITokens) ListForUser(_ context.Context, userID string) ([]models.APIToken, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	tokens := []models.APIToken{}
+	for _, t := range m.s.apiTokens {
+		if t.UserID == userID {
+			tokens = append(tokens, t.APIToken)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		if !tokens[i].CreatedAt.Equal(tokens[j].CreatedAt) {
+			return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+		}
+		return tokens[i].ID > tokens[j].ID
+	})
+	return tokens, nil
+}
+
+func (m memoryAPITokens) Delete(_ context.Context, userID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if t, ok := m.s.apiTokens[id]; !ok || t.UserID != userID {
+		return ErrNotFound
+	}
+	delete(m.s.apiTokens, id)
+	return nil
+}
+
+func (m memoryAPITokens) Authenticate(_ context.Context, tokenHash []byte) (*models.APIToken, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for id, t := range m.s.apiTokens {
+		if !bytes.Equal(t.tokenHash, tokenHash) {
+			continue
+		}
+		if t.ExpiresAt != nil && !now.Before(*t.ExpiresAt) {
+			return nil, ErrNotFound
+		}
+		t.LastUsedAt = &now
+		m.s.apiTokens[id] = t
+		return &t.APIToken, nil
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+func (m memoryTasks) SetArchived(_ context.Context, scope models.Scope, id string, archived bool) (*models.Task, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, ok := m.s.editableTask(scope, id)
+	if !ok {
+		return nil, m.s.writeMiss(scope, id)
+	}
+
+	now := time.Now().UTC()
+	if !archived {
+		t.ArchivedAt = nil
+	} else if t.ArchivedAt == nil {
+		t.ArchivedAt = &now
+	}
+	t.Version++
+	t.UpdatedAt = now
+	m.s.tasks[id] = t
+	m.s.logTask(t)
+	return &t, nil
+}
+
+func (m memoryTasks) Snooze(_ context.Context, scope models.Scope, id string, until *time.Time) (*models.Task, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, ok := m.s.editableTask(scope, id)
+	if !ok {
+		return nil, m.s.writeMiss(scope, id)
+	}
+
+	t.SnoozedUntil = until
+	t.Version++
+	t.UpdatedAt = time.Now().UTC()
+	m.s.tasks[id] = t
+	m.s.logTask(t)
+
+	if until != nil {
+		for rid, r := range m.s.reminders {
+			if r.TaskID == id && r.UserID == scope.UserID && r.SentAt != nil {
+				r.SentAt = nil
+				m.s.reminders[rid] = r
+			}
+		}
+	}
+	return &t, nil
+}
+
+func (m memoryTasks) ArchiveCompleted(_ context.Context, before time.Time, limit int) (int, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	archived := 0
+	for id, t := range m.s.tasks {
+		if archived == limit {
+			break
+		}
+		if t.ArchivedAt != nil || t.DeletedAt != nil || !t.UpdatedAt.Before(before) {
+			continue
+		}
+
+		w := models.DefaultWorkflow()
+		if t.OrgID != nil {
+			w = memoryWorkflows{m.s}.get(*t.OrgID)
+		}
+		if !w.IsDone(t.Status) {
+			continue
+		}
+
+		t.ArchivedAt = &now
+		t.Version++
+		m.s.tasks[id] = t
+		m.s.logTask(t)
+		archived++
+	}
+	return archived, nil
+}
@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryAttachments struct{ s *memoryStore }
+
+// attachment looks up an attachment on a live task in scope; callers hold
+// the lock.
+func (m memoryAttachments) attachment(scope models.Scope, taskID, id string) (models.Attachment, bool) {
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return models.Attachment{}, false
+	}
+	a, ok := m.s.attachments[id]
+	return a, ok && a.TaskID == taskID
+}
+
+func (m memoryAttachments) Create(_ context.Context, scope models.Scope, taskID string, input models.CreateAttachmentInput) (*models.Attachment, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.editableTask(scope, taskID); !ok {
+		return nil, ErrNotFound
+	}
+
+	a := models.Attachment{
+		ID:          newID(),
+		TaskID:      taskID,
+		UploaderID:  scope.UserID,
+		Filename:    input.Filename,
+		ContentType: input.ContentType,
+		Size:        input.Size,
+		Key:         input.Key,
+		CreatedAt:   time.Now().UTC(),
+	}
+	m.s.attachments[a.ID] = a
+	return &a, nil
+}
+
+func (m memoryAttachments) Get(_ context.Context, scope models.Scope, taskID, id string) (*models.Attachment, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	a, ok := m.attachment(scope, taskID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &a, nil
+}
+
+func (m memoryAttachments) List(_ context.Context, scope models.Scope, taskID string) ([]models.Attachment, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.Attachment{}
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return list, nil
+	}
+	for _, a := range m.s.attachments {
+		if a.TaskID == taskID && a.ConfirmedAt != nil {
+			list = append(list, a)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (m memoryAttachments) Confirm(_ context.Context, scope models.Scope, taskID, id string) (*models.Attachment, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	a, ok := m.attachment(scope, taskID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if a.ConfirmedAt == nil {
+		now := time.Now().UTC()
+		a.ConfirmedAt = &now
+		m.s.attachments[id] = a
+	}
+	return &a, nil
+}
+
+func (m memoryAttachments) Delete(_ context.Context, scope models.Scope, taskID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.attachment(scope, taskID, id); !ok {
+		return ErrNotFound
+	}
+	delete(m.s.attachments, id)
+	return nil
+}
+
+func (m memoryAttachments) DeletePending(_ context.Context, cutoff time.Time) ([]string, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	keys := []string{}
+	for id, a := range m.s.attachments {
+		if a.ConfirmedAt == nil && a.CreatedAt.Before(cutoff) {
+			keys = append(keys, a.Key)
+			delete(m.s.attachments, id)
+		}
+	}
+	return keys, nil
+}
@@ -0,0 +1,239 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryBoards struct{ s *memoryStore }
+
+// board looks up a board in the scope's org; callers hold the lock.
+func (m memoryBoards) board(scope models.Scope, id string) (models.Board, bool) {
+	b, ok := m.s.boards[id]
+	return b, ok && b.OrgID == scope.OrgID
+}
+
+// withColumns returns a copy of b with columns in order, keeping the ids of
+// the columns whose status it already had.
+func withColumns(b models.Board, columns []models.BoardColumnInput) models.Board {
+	ids := map[string]string{}
+	for _, col := range b.Columns {
+		ids[col.Status] = col.ID
+	}
+	b.Columns = make([]models.BoardColumn, len(columns))
+	for i, col := range columns {
+		id, ok := ids[col.Status]
+		if !ok {
+			id = newID()
+		}
+		b.Columns[i] = models.BoardColumn{ID: id, Name: col.Name, Status: col.Status, Position: i}
+	}
+	return b
+}
+
+// copyBoard keeps callers from sharing the stored board's columns.
+func copyBoard(b models.Board) *models.Board {
+	b.Columns = append([]models.BoardColumn{}, b.Columns...)
+	return &b
+}
+
+func (m memoryBoards) Create(_ context.Context, scope models.Scope, projectID string, input models.CreateBoardInput) (*models.Board, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	p, ok := m.s.projects[projectID]
+	if !ok || p.OrgID != scope.OrgID || p.DeletedAt != nil {
+		return nil, ErrNotFound
+	}
+	now := time.Now().UTC()
+	b := withColumns(models.Board{
+		ID:        newID(),
+		OrgID:     scope.OrgID,
+		ProjectID: projectID,
+		Name:      input.Name,
+		CreatedBy: scope.UserID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, input.Columns)
+	m.s.boards[b.ID] = b
+	return copyBoard(b), nil
+}
+
+func (m memoryBoards) Get(_ context.Context, scope models.Scope, id string) (*models.Board, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	b, ok := m.board(scope, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return copyBoard(b), nil
+}
+
+func (m memoryBoards) ListForProject(_ context.Context, scope models.Scope, projectID string) ([]models.Board, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	boards := []models.Board{}
+	for _, b := range m.s.boards {
+		if b.OrgID == scope.OrgID && b.ProjectID == projectID {
+			b.Columns = nil
+			boards = append(boards, b)
+		}
+	}
+	sort.Slice(boards, func(i, j int) bool {
+		if boards[i].Name != boards[j].Name {
+			return boards[i].Name < boards[j].Name
+		}
+		return boards[i].ID < boards[j].ID
+	})
+	return boards, nil
+}
+
+func (m memoryBoards) Update(_ context.Context, scope models.Scope, id string, input models.UpdateBoardInput) (*models.Board, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	b, ok := m.board(scope, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if input.Name != nil {
+		b.Name = *input.Name
+	}
+	if input.Columns != nil {
+		b = withColumns(b, input.Columns)
+	}
+	b.UpdatedAt = time.Now().UTC()
+	m.s.boards[id] = b
+	return copyBoard(b), nil
+}
+
+func (m memoryBoards) Delete(_ context.Context, scope models.Scope, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.board(scope, id); !ok {
+		return ErrNotFound
+	}
+	m.s.deleteBoard(id)
+	return nil
+}
+
+// deleteBoard drops a board and its cards; callers hold the lock.
+func (s *memoryStore) deleteBoard(id string) {
+	delete(s.boards, id)
+	for key, card := range s.boardCards {
+		if card.boardID == id {
+			delete(s.boardCards, key)
+		}
+	}
+}
+
+type memoryBoardCard struct {
+	boardID, taskID string
+	position        int
+}
+
+// column returns the live, unarchived tasks of the project in status, in
+// board order; callers hold the lock.
+func (m memoryBoards) column(b models.Board, status string) []models.Task {
+	tasks := []models.Task{}
+	for _, t := range m.s.tasks {
+		if t.ProjectID != nil && *t.ProjectID == b.ProjectID && t.Status == status && t.DeletedAt == nil && t.ArchivedAt == nil {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		ci, iok := m.s.boardCards[b.ID+"/"+tasks[i].ID]
+		cj, jok := m.s.boardCards[b.ID+"/"+tasks[j].ID]
+		switch {
+		case iok != jok:
+			return iok
+		case iok && ci.position != cj.position:
+			return ci.position < cj.position
+		case !tasks[i].CreatedAt.Equal(tasks[j].CreatedAt):
+			return tasks[i].CreatedAt.Before(tasks[j].CreatedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks
+}
+
+func (m memoryBoards) Cards(_ context.Context, scope models.Scope, id string) (*models.Board, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	b, ok := m.board(scope, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	board := copyBoard(b)
+	for i, col := range board.Columns {
+		board.Columns[i].Tasks = []models.Task{}
+		for _, t := range m.column(b, col.Status) {
+			if m.s.canAccess(scope, t, models.ShareRoleViewer) {
+				board.Columns[i].Tasks = append(board.Columns[i].Tasks, t)
+			}
+		}
+	}
+	return board, nil
+}
+
+func (m memoryBoards) Move(_ context.Context, scope models.Scope, id string, input models.MoveCardInput) (*models.BoardMove, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	b, ok := m.board(scope, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	var status string
+	for _, col := range b.Columns {
+		if col.ID == input.ColumnID {
+			status = col.Status
+		}
+	}
+	t, ok := m.s.liveTask(scope, input.TaskID)
+	if status == "" || !ok || t.ProjectID == nil || *t.ProjectID != b.ProjectID {
+		return nil, ErrNotFound
+	}
+	if _, ok := m.s.editableTask(scope, input.TaskID); !ok {
+		return nil, ErrReadOnly
+	}
+
+	move := &models.BoardMove{ColumnID: input.ColumnID, PreviousStatus: t.Status}
+	if t.Status != status {
+		now := time.Now().UTC()
+		t.Status, t.Version, t.UpdatedAt = status, t.Version+1, now
+		m.s.setCompletedAt(&t, now)
+		m.s.tasks[t.ID] = t
+		m.s.logTask(t)
+	}
+	move.Task = t
+
+	order, seen, placed := []string{}, 0, false
+	for _, other := range m.column(b, status) {
+		if other.ID == t.ID {
+			continue
+		}
+		visible := m.s.canAccess(scope, other, models.ShareRoleViewer)
+		if visible && seen == input.Index && !placed {
+			order, placed = append(order, t.ID), true
+		}
+		if visible {
+			seen++
+		}
+		order = append(order, other.ID)
+	}
+	if !placed {
+		order = append(order, t.ID)
+	}
+	move.Index = min(input.Index, seen)
+	for i, taskID := range order {
+		m.s.boardCards[id+"/"+taskID] = memoryBoardCard{boardID: id, taskID: taskID, position: i}
+	}
+	return move, nil
+}
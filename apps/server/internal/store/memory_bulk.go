@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+func (m memoryTasks) Bulk(_ context.Context, scope models.Scope, ops []models.BulkTaskOp, completedStatus string, doneStatuses []string) ([]models.BulkTaskResult, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	results := make([]models.BulkTaskResult, 0, len(ops))
+	for _, op := range ops {
+		result := models.BulkTaskResult{TaskID: op.TaskID, Action: op.Action}
+
+		t, ok := m.s.editableTask(scope, op.TaskID)
+		if !ok {
+			result.Error = "Task not found"
+			results = append(results, result)
+			continue
+		}
+		result.OK = true
+
+		now := time.Now().UTC()
+		switch op.Action {
+		case models.BulkComplete:
+			if slices.Contains(doneStatuses, t.Status) {
+				break
+			}
+			t.Status = completedStatus
+			m.s.setCompletedAt(&t, now)
+			t.Version++
+			t.UpdatedAt = now
+			m.s.tasks[t.ID] = t
+			m.s.logTask(t)
+			completed := t
+			result.Completed = &completed
+		case models.BulkMove:
+			t.ProjectID = op.ProjectID
+			t.Version++
+			t.UpdatedAt = now
+			m.s.tasks[t.ID] = t
+			m.s.logTask(t)
+		case models.BulkRelabel:
+			set := map[string]bool{}
+			for _, id := range op.LabelIDs {
+				if l, ok := m.s.labels[id]; ok && l.OrgID == scope.OrgID {
+					set[id] = true
+				}
+			}
+			m.s.taskLabels[t.ID] = set
+			t.Version++
+			t.UpdatedAt = now
+			m.s.tasks[t.ID] = t
+			m.s.logTask(t)
+		case models.BulkDelete:
+			m.trash(t.ID)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
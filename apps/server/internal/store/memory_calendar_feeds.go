@@ -0,0 +1,76 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+// memoryCalendarFeed keeps the token hash the model leaves out.
+type memoryCalendarFeed struct {
+	models.CalendarFeed
+	tokenHash []byte
+}
+
+// memoryCalendarFeeds is keyed by "userID/orgID".
+type memoryCalendarFeeds struct{ s *memoryStore }
+
+func calendarFeedKey(scope models.Scope) string {
+	return scope.UserID + "/" + scope.OrgID
+}
+
+func (m memoryCalendarFeeds) Get(_ context.Context, scope models.Scope) (*models.CalendarFeed, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	f, ok := m.s.calendarFeeds[calendarFeedKey(scope)]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &f.CalendarFeed, nil
+}
+
+func (m memoryCalendarFeeds) Rotate(_ context.Context, scope models.Scope, tokenHash []byte) (*models.CalendarFeed, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	f := memoryCalendarFeed{
+		CalendarFeed: models.CalendarFeed{
+			ID:        newID(),
+			UserID:    scope.UserID,
+			OrgID:     scope.OrgIDPtr(),
+			CreatedAt: time.Now().UTC(),
+		},
+		tokenHash: tokenHash,
+	}
+	m.s.calendarFeeds[calendarFeedKey(scope)] = f
+	return &f.CalendarFeed, nil
+}
+
+func (m memoryCalendarFeeds) Delete(_ context.Context, scope models.Scope) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key := calendarFeedKey(scope)
+	if _, ok := m.s.calendarFeeds[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.s.calendarFeeds, key)
+	return nil
+}
+
+func (m memoryCalendarFeeds) Lookup(_ context.Context, tokenHash []byte) (*models.CalendarFeed, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for key, f := range m.s.calendarFeeds {
+		if bytes.Equal(f.tokenHash, tokenHash) {
+			now := time.Now().UTC()
+			f.LastUsedAt = &now
+			m.s.calendarFeeds[key] = f
+			return &f.CalendarFeed, nil
+		}
+	}
+	return nil, ErrNotFound
+}
@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryChange struct {
+	seq       int64
+	entity    string
+	entityID  string
+	orgID     *string
+	ownerID   string
+	changedAt time.Time
+}
+
+// logChange stands in for the change_log triggers; callers hold the lock.
+func (s *memoryStore) logChange(entity, id string, orgID *string, ownerID string) {
+	s.changeSeq++
+	s.changeLog = append(s.changeLog, memoryChange{seq: s.changeSeq, entity: entity, entityID: id, orgID: orgID, ownerID: ownerID, changedAt: time.Now()})
+}
+
+func (s *memoryStore) logTask(t models.Task) {
+	s.logChange(models.ChangeTask, t.ID, t.OrgID, t.OwnerID)
+}
+
+func (s *memoryStore) logProject(p models.Project) {
+	orgID := p.OrgID
+	s.logChange(models.ChangeProject, p.ID, &orgID, "")
+}
+
+func (s *memoryStore) logLabel(l models.Label) {
+	orgID := l.OrgID
+	s.logChange(models.ChangeLabel, l.ID, &orgID, "")
+}
+
+// memoryChanges has no concurrent writers to wait for, so its cursors are
+// sequence numbers alone.
+type memoryChanges struct{ s *memoryStore }
+
+func (m memoryChanges) Current(context.Context, models.Scope) (models.ChangeCursor, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	return models.ChangeCursor{Seq: m.s.changeSeq}, nil
+}
+
+func (m memoryChanges) Since(_ context.Context, scope models.Scope, after models.ChangeCursor, limit int) (*models.Changes, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	changes := &models.Changes{
+		Tasks:    []models.Task{},
+		Projects: []models.Project{},
+		Labels:   []models.Label{},
+		Deleted:  models.Deleted{Tasks: []string{}, Projects: []string{}, Labels: []string{}},
+		Next:     models.ChangeCursor{Seq: m.s.changeSeq},
+	}
+	seen := map[string]bool{}
+	read, last := 0, after.Seq
+	for _, c := range m.s.changeLog {
+		if c.seq <= after.Seq || !inScope(scope, c.ownerID, c.orgID) || (scope.Guest && c.entity != models.ChangeTask) {
+			continue
+		}
+		if read == limit {
+			changes.HasMore = true
+			changes.Next = models.ChangeCursor{Seq: last}
+			break
+		}
+		read, last = read+1, c.seq
+		key := c.entity + "/" + c.entityID
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		m.add(scope, c, changes)
+	}
+	return changes, nil
+}
+
+// add mirrors ChangeRepository's loads; callers hold the lock.
+func (m memoryChanges) add(scope models.Scope, c memoryChange, changes *models.Changes) {
+	switch c.entity {
+	case models.ChangeTask:
+		t, ok := m.s.tasks[c.entityID]
+		switch {
+		case !ok:
+			changes.Deleted.Tasks = append(changes.Deleted.Tasks, c.entityID)
+		case !m.s.canAccess(scope, t, models.ShareRoleViewer):
+		case t.DeletedAt != nil:
+			changes.Deleted.Tasks = append(changes.Deleted.Tasks, t.ID)
+		default:
+			changes.Tasks = append(changes.Tasks, t)
+		}
+	case models.ChangeProject:
+		p, ok := m.s.projects[c.entityID]
+		if !ok || p.DeletedAt != nil {
+			changes.Deleted.Projects = append(changes.Deleted.Projects, c.entityID)
+			return
+		}
+		changes.Projects = append(changes.Projects, p)
+	case models.ChangeLabel:
+		l, ok := m.s.labels[c.entityID]
+		if !ok {
+			changes.Deleted.Labels = append(changes.Deleted.Labels, c.entityID)
+			return
+		}
+		changes.Labels = append(changes.Labels, l)
+	}
+}
+
+func (m memoryChanges) Prune(_ context.Context, cutoff time.Time) (int, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	kept := m.s.changeLog[:0]
+	for _, c := range m.s.changeLog {
+		if !c.changedAt.Before(cutoff) {
+			kept = append(kept, c)
+		}
+	}
+	pruned := len(m.s.changeLog) - len(kept)
+	m.s.changeLog = kept
+	return pruned, nil
+}
@@ -0,0 +1,220 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryComments struct{ s *memoryStore }
+
+// comment looks up a comment on a live task in scope; callers hold the lock.
+func (m memoryComments) comment(scope models.Scope, taskID, id string) (models.Comment, bool) {
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return models.Comment{}, false
+	}
+	c, ok := m.s.comments[id]
+	return c, ok && c.TaskID == taskID
+}
+
+func (m memoryComments) Create(_ context.Context, scope models.Scope, taskID string, input models.CommentInput) (*models.Comment, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return nil, ErrNotFound
+	}
+	if input.ParentID != nil {
+		if p, ok := m.s.comments[*input.ParentID]; !ok || p.TaskID != taskID {
+			return nil, ErrNotFound
+		}
+	}
+
+	now := time.Now().UTC()
+	c := models.Comment{
+		ID:        newID(),
+		TaskID:    taskID,
+		AuthorID:  scope.UserID,
+		ParentID:  input.ParentID,
+		Body:      input.Body,
+		Mentions:  append([]string{}, input.Mentions...),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.comments[c.ID] = c
+	return &c, nil
+}
+
+func (m memoryComments) Get(_ context.Context, scope models.Scope, taskID, id string) (*models.Comment, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	c, ok := m.comment(scope, taskID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+func (m memoryComments) List(_ context.Context, scope models.Scope, taskID string, page models.Page) ([]models.Comment, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.Comment{}
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return list, nil
+	}
+	for _, c := range m.s.comments {
+		if c.TaskID == taskID {
+			list = append(list, c)
+		}
+	}
+
+	// newer reports whether c sorts after (t, id) in oldest-first order.
+	newer := func(c models.Comment, t time.Time, id string) bool {
+		if !c.CreatedAt.Equal(t) {
+			return c.CreatedAt.After(t)
+		}
+		return c.ID > id
+	}
+	sort.Slice(list, func(i, j int) bool { return newer(list[j], list[i].CreatedAt, list[i].ID) })
+
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		idx := sort.Search(len(list), func(i int) bool { return newer(list[i], t, page.After[1]) })
+		list = list[idx:]
+	}
+
+	return limit(list, page.Limit), nil
+}
+
+func (m memoryComments) Update(_ context.Context, scope models.Scope, taskID, id string, input models.CommentInput) (*models.Comment, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	c, ok := m.comment(scope, taskID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if c.Body == input.Body {
+		return &c, nil
+	}
+
+	m.s.revisions[id] = append(m.s.revisions[id], models.CommentRevision{
+		ID:        newID(),
+		CommentID: id,
+		Body:      c.Body,
+		CreatedAt: c.UpdatedAt,
+	})
+	c.Body = input.Body
+	c.Mentions = append([]string{}, input.Mentions...)
+	c.UpdatedAt = time.Now().UTC()
+	c.Edited = true
+	m.s.comments[id] = c
+	return &c, nil
+}
+
+func (m memoryComments) Delete(_ context.Context, scope models.Scope, taskID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.comment(scope, taskID, id); !ok {
+		return ErrNotFound
+	}
+	delete(m.s.comments, id)
+	delete(m.s.revisions, id)
+	for replyID, reply := range m.s.comments {
+		if reply.ParentID != nil && *reply.ParentID == id {
+			reply.ParentID = nil
+			m.s.comments[replyID] = reply
+		}
+	}
+	return nil
+}
+
+func (m memoryComments) History(_ context.Context, scope models.Scope, taskID, id string) ([]models.CommentRevision, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if _, ok := m.comment(scope, taskID, id); !ok {
+		return nil, ErrNotFound
+	}
+	return append([]models.CommentRevision{}, m.s.revisions[id]...), nil
+}
+
+func (m memoryComments) SetPinned(_ context.Context, scope models.Scope, taskID, id string, pinned bool) (*models.Comment, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	c, ok := m.comment(scope, taskID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	switch {
+	case !pinned:
+		c.PinnedAt, c.PinnedBy = nil, nil
+	case c.PinnedAt == nil:
+		now, by := time.Now().UTC(), scope.UserID
+		c.PinnedAt, c.PinnedBy = &now, &by
+	}
+	m.s.comments[id] = c
+	return &c, nil
+}
+
+func (m memoryComments) Pinned(_ context.Context, scope models.Scope, taskID string) ([]models.Comment, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.Comment{}
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return list, nil
+	}
+	for _, c := range m.s.comments {
+		if c.TaskID == taskID && c.PinnedAt != nil {
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].PinnedAt.Equal(*list[j].PinnedAt) {
+			return list[i].PinnedAt.Before(*list[j].PinnedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (m memoryComments) Commented(_ context.Context, scope models.Scope, taskID, userID string) (bool, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return false, nil
+	}
+	for _, c := range m.s.comments {
+		if c.TaskID == taskID && c.AuthorID == userID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m memoryComments) Counts(_ context.Context, taskIDs []string) (map[string]int, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	wanted := map[string]bool{}
+	for _, id := range taskIDs {
+		wanted[id] = true
+	}
+	counts := map[string]int{}
+	for _, c := range m.s.comments {
+		if wanted[c.TaskID] {
+			counts[c.TaskID]++
+		}
+	}
+	return counts, nil
+}
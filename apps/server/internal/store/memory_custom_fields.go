@@ -0,0 +1,118 @@
+package store
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryCustomFields struct{ s *memoryStore }
+
+func (m memoryCustomFields) Create(_ context.Context, orgID, userID string, input models.CreateCustomFieldInput) (*models.CustomField, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for _, f := range m.s.customFields {
+		if f.OrgID == orgID && f.Key == input.Key {
+			return nil, ErrConflict
+		}
+	}
+
+	now := time.Now()
+	f := models.CustomField{
+		ID:        newID(),
+		OrgID:     orgID,
+		Key:       input.Key,
+		Name:      input.Name,
+		Type:      input.Type,
+		Options:   slices.Clone(input.Options),
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if f.Options == nil {
+		f.Options = []string{}
+	}
+	m.s.customFields[f.ID] = f
+	return &f, nil
+}
+
+func (m memoryCustomFields) List(_ context.Context, orgID string) ([]models.CustomField, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	fields := []models.CustomField{}
+	for _, f := range m.s.customFields {
+		if f.OrgID == orgID {
+			fields = append(fields, f)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		if !fields[i].CreatedAt.Equal(fields[j].CreatedAt) {
+			return fields[i].CreatedAt.Before(fields[j].CreatedAt)
+		}
+		return fields[i].ID < fields[j].ID
+	})
+	return fields, nil
+}
+
+func (m memoryCustomFields) Update(_ context.Context, orgID, id string, input models.UpdateCustomFieldInput) (*models.CustomField, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	f, ok := m.s.customFields[id]
+	if !ok || f.OrgID != orgID {
+		return nil, ErrNotFound
+	}
+	if input.Name == nil && input.Options == nil {
+		return &f, nil
+	}
+
+	if input.Name != nil {
+		f.Name = *input.Name
+	}
+	if input.Options != nil {
+		f.Options = slices.Clone(*input.Options)
+		m.clearTasks(orgID, f.Key, func(value any) bool {
+			s, _ := value.(string)
+			return !slices.Contains(f.Options, s)
+		})
+	}
+	f.UpdatedAt = time.Now()
+	m.s.customFields[id] = f
+	return &f, nil
+}
+
+func (m memoryCustomFields) Delete(_ context.Context, orgID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	f, ok := m.s.customFields[id]
+	if !ok || f.OrgID != orgID {
+		return ErrNotFound
+	}
+	delete(m.s.customFields, id)
+	m.clearTasks(orgID, f.Key, func(any) bool { return true })
+	return nil
+}
+
+// clearTasks removes the field from the org's tasks whose value for it
+// matches drop; callers hold the lock.
+func (m memoryCustomFields) clearTasks(orgID, key string, drop func(value any) bool) {
+	now := time.Now().UTC()
+	for id, t := range m.s.tasks {
+		value, ok := t.CustomFields[key]
+		if !ok || t.OrgID == nil || *t.OrgID != orgID || !drop(value) {
+			continue
+		}
+		t.CustomFields = maps.Clone(t.CustomFields)
+		delete(t.CustomFields, key)
+		t.Version++
+		t.UpdatedAt = now
+		m.s.tasks[id] = t
+		m.s.logTask(t)
+	}
+}
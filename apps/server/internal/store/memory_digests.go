@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryDigests struct{ s *memoryStore }
+
+func (m memoryDigests) Subscribers(_ context.Context) ([]models.DigestSubscriber, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	subscribers := []models.DigestSubscriber{}
+	for userID, s := range m.s.settings {
+		if s.DigestFrequency == "off" || !s.NotificationChannels.Email {
+			continue
+		}
+		sub := models.DigestSubscriber{Settings: s}
+		if sent, ok := m.s.digestsSent[userID]; ok {
+			sub.LastSentAt = &sent
+		}
+		subscribers = append(subscribers, sub)
+	}
+	sort.Slice(subscribers, func(i, j int) bool { return subscribers[i].Settings.UserID < subscribers[j].Settings.UserID })
+	return subscribers, nil
+}
+
+func (m memoryDigests) Claim(_ context.Context, userID string, slot, sentAt time.Time) (bool, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.settings[userID]; !ok {
+		return false, nil
+	}
+	if sent, ok := m.s.digestsSent[userID]; ok && !sent.Before(slot) {
+		return false, nil
+	}
+	m.s.digestsSent[userID] = sentAt
+	return true, nil
+}
+
+func (m memoryDigests) Digest(_ context.Context, userID string, q models.DigestQuery) (models.Digest, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	assigned := map[string]bool{}
+	for _, a := range m.s.activity {
+		if a.TaskID == nil || a.ActorID == userID || a.CreatedAt.Before(q.AssignedSince) {
+			continue
+		}
+		if owner, _ := a.Changes["ownerId"].New.(string); a.Action == models.ActivityTaskCreated || owner == userID {
+			assigned[*a.TaskID] = true
+		}
+	}
+
+	var d models.Digest
+	now := time.Now()
+	for _, t := range m.s.tasks {
+		if t.OwnerID != userID || t.DeletedAt != nil || t.ArchivedAt != nil {
+			continue
+		}
+		w := models.DefaultWorkflow()
+		if t.OrgID != nil {
+			w = memoryWorkflows{m.s}.get(*t.OrgID)
+		}
+		if w.IsDone(t.Status) {
+			continue
+		}
+
+		snoozed := t.SnoozedUntil != nil && t.SnoozedUntil.After(now)
+		switch {
+		case t.DueDate != nil && t.DueDate.Before(q.DueTo):
+			if snoozed {
+				continue
+			}
+			if t.DueDate.Before(q.DueFrom) {
+				d.Overdue = append(d.Overdue, t)
+			} else {
+				d.Due = append(d.Due, t)
+			}
+		case assigned[t.ID]:
+			d.Assigned = append(d.Assigned, t)
+		}
+	}
+
+	byDue := func(tasks []models.Task) func(i, j int) bool {
+		return func(i, j int) bool {
+			if !tasks[i].DueDate.Equal(*tasks[j].DueDate) {
+				return tasks[i].DueDate.Before(*tasks[j].DueDate)
+			}
+			return tasks[i].ID < tasks[j].ID
+		}
+	}
+	sort.Slice(d.Overdue, byDue(d.Overdue))
+	sort.Slice(d.Due, byDue(d.Due))
+	sort.Slice(d.Assigned, func(i, j int) bool {
+		if !d.Assigned[i].CreatedAt.Equal(d.Assigned[j].CreatedAt) {
+			return d.Assigned[i].CreatedAt.After(d.Assigned[j].CreatedAt)
+		}
+		return d.Assigned[i].ID < d.Assigned[j].ID
+	})
+
+	d.Overdue = limitTasks(d.Overdue, q.Limit)
+	d.Due = limitTasks(d.Due, q.Limit)
+	d.Assigned = limitTasks(d.Assigned, q.Limit)
+	return d, nil
+}
+
+func limitTasks(tasks []models.Task, limit int) []models.Task {
+	if tasks == nil {
+		return []models.Task{}
+	}
+	if len(tasks) > limit {
+		return tasks[:limit]
+	}
+	return tasks
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryGoogleCalendar struct{ s *memoryStore }
+
+// connection finds scope's connection; the caller holds the lock.
+func (m memoryGoogleCalendar) connection(scope models.Scope) (models.GoogleCalendarConnection, bool) {
+	for _, c := range m.s.gcalConnections {
+		if c.UserID == scope.UserID && c.Scope().OrgID == scope.OrgID {
+			return c, true
+		}
+	}
+	return models.GoogleCalendarConnection{}, false
+}
+
+// remove drops a connection and its events; the caller holds the lock.
+func (m memoryGoogleCalendar) remove(id string) {
+	delete(m.s.gcalConnections, id)
+	delete(m.s.gcalNextSync, id)
+	for key, e := range m.s.gcalEvents {
+		if e.ConnectionID == id {
+			delete(m.s.gcalEvents, key)
+		}
+	}
+}
+
+func (m memoryGoogleCalendar) Get(_ context.Context, scope models.Scope) (*models.GoogleCalendarConnection, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	c, ok := m.connection(scope)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &c, nil
+}
+
+func (m memoryGoogleCalendar) Connect(_ context.Context, scope models.Scope, email string, token models.GoogleCalendarToken) (*models.GoogleCalendarConnection, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if old, ok := m.connection(scope); ok {
+		m.remove(old.ID)
+	}
+	now := time.Now().UTC()
+	c := models.GoogleCalendarConnection{
+		ID:         newID(),
+		UserID:     scope.UserID,
+		OrgID:      scope.OrgIDPtr(),
+		Email:      email,
+		CalendarID: "primary",
+		CreatedAt:  now,
+		Token:      token,
+	}
+	m.s.gcalConnections[c.ID] = c
+	m.s.gcalNextSync[c.ID] = now
+	return &c, nil
+}
+
+func (m memoryGoogleCalendar) Delete(_ context.Context, scope models.Scope) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	c, ok := m.connection(scope)
+	if !ok {
+		return ErrNotFound
+	}
+	m.remove(c.ID)
+	return nil
+}
+
+func (m memoryGoogleCalendar) ClaimDue(_ context.Context, interval time.Duration, limit int) ([]models.GoogleCalendarConnection, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	due := []models.GoogleCalendarConnection{}
+	for id, next := range m.s.gcalNextSync {
+		if !next.After(now) {
+			due = append(due, m.s.gcalConnections[id])
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return m.s.gcalNextSync[due[i].ID].Before(m.s.gcalNextSync[due[j].ID])
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	for _, c := range due {
+		m.s.gcalNextSync[c.ID] = now.Add(interval)
+	}
+	return due, nil
+}
+
+func (m memoryGoogleCalendar) SaveToken(_ context.Context, id string, token models.GoogleCalendarToken) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	c, ok := m.s.gcalConnections[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = c.Token.RefreshToken
+	}
+	c.Token = token
+	m.s.gcalConnections[id] = c
+	return nil
+}
+
+func (m memoryGoogleCalendar) Synced(_ context.Context, id string, at time.Time, syncErr *string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	c, ok := m.s.gcalConnections[id]
+	if !ok {
+		return ErrNotFound
+	}
+	if syncErr == nil {
+		c.LastSyncedAt = &at
+	}
+	c.LastError = syncErr
+	m.s.gcalConnections[id] = c
+	return nil
+}
+
+func (m memoryGoogleCalendar) Events(_ context.Context, connectionID string) ([]models.GoogleCalendarEvent, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	events := []models.GoogleCalendarEvent{}
+	for _, e := range m.s.gcalEvents {
+		if e.ConnectionID == connectionID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+func (m memoryGoogleCalendar) SaveEvent(_ context.Context, e models.GoogleCalendarEvent) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.gcalConnections[e.ConnectionID]; !ok {
+		return ErrNotFound
+	}
+	m.s.gcalEvents[e.ConnectionID+"/"+e.TaskID] = e
+	return nil
+}
+
+func (m memoryGoogleCalendar) DeleteEvent(_ context.Context, connectionID, taskID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	delete(m.s.gcalEvents, connectionID+"/"+taskID)
+	return nil
+}
@@ -0,0 +1,56 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryIdempotentRequest struct {
+	models.IdempotentRequest
+	createdAt time.Time
+}
+
+type memoryIdempotency struct{ s *memoryStore }
+
+func idempotencyKey(scope models.Scope, key string) string {
+	return scope.UserID + "/" + scope.OrgID + "/" + key
+}
+
+func (m memoryIdempotency) Reserve(_ context.Context, scope models.Scope, key, fingerprint string, ttl time.Duration) (models.IdempotentRequest, bool, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now()
+	if req, ok := m.s.idempotency[idempotencyKey(scope, key)]; ok && now.Sub(req.createdAt) < ttl {
+		return req.IdempotentRequest, false, nil
+	}
+	m.s.idempotency[idempotencyKey(scope, key)] = memoryIdempotentRequest{
+		IdempotentRequest: models.IdempotentRequest{Fingerprint: fingerprint},
+		createdAt:         now,
+	}
+	return models.IdempotentRequest{}, true, nil
+}
+
+func (m memoryIdempotency) Complete(_ context.Context, scope models.Scope, key string, statusCode int, contentType string, body []byte) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	req, ok := m.s.idempotency[idempotencyKey(scope, key)]
+	if !ok {
+		return nil
+	}
+	req.StatusCode = statusCode
+	req.ContentType = contentType
+	req.Body = body
+	m.s.idempotency[idempotencyKey(scope, key)] = req
+	return nil
+}
+
+func (m memoryIdempotency) Release(_ context.Context, scope models.Scope, key string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	delete(m.s.idempotency, idempotencyKey(scope, key))
+	return nil
+}
@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+// memoryImport keeps the uploaded file the model leaves out.
+type memoryImport struct {
+	models.Import
+	data []byte
+}
+
+type memoryImports struct{ s *memoryStore }
+
+func (m memoryImports) Create(_ context.Context, scope models.Scope, input models.CreateImportInput) (*models.Import, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	i := memoryImport{
+		Import: models.Import{
+			ID:        newID(),
+			UserID:    scope.UserID,
+			OrgID:     scope.OrgIDPtr(),
+			Source:    input.Source,
+			FileName:  input.FileName,
+			Timezone:  input.Timezone,
+			Status:    models.ImportStatusPending,
+			Total:     input.Total,
+			CreatedAt: now,
+			UpdatedAt: now,
+		},
+		data: input.Data,
+	}
+	m.s.imports[i.ID] = i
+	return &i.Import, nil
+}
+
+func (m memoryImports) Get(_ context.Context, userID, id string) (*models.Import, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	i, ok := m.s.imports[id]
+	if !ok || i.UserID != userID {
+		return nil, ErrNotFound
+	}
+	return &i.Import, nil
+}
+
+func (m memoryImports) Start(_ context.Context, id string) (*models.Import, []byte, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	i, ok := m.s.imports[id]
+	if !ok || i.Status != models.ImportStatusPending {
+		return nil, nil, ErrNotFound
+	}
+	i.Status = models.ImportStatusRunning
+	i.UpdatedAt = time.Now().UTC()
+	m.s.imports[id] = i
+	return &i.Import, i.data, nil
+}
+
+func (m memoryImports) Progress(_ context.Context, id string, progress models.ImportProgress) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	i, ok := m.s.imports[id]
+	if !ok {
+		return ErrNotFound
+	}
+	now := time.Now().UTC()
+	i.Status = progress.Status
+	i.Imported = progress.Imported
+	i.Skipped = progress.Skipped
+	i.Error = progress.Error
+	i.UpdatedAt = now
+	if i.Finished() {
+		i.FinishedAt = &now
+		i.data = nil
+	}
+	m.s.imports[id] = i
+	return nil
+}
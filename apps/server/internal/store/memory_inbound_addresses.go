@@ -0,0 +1,86 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryInboundAddresses struct{ s *memoryStore }
+
+func inboundAddressIn(a models.InboundAddress, scope models.Scope) bool {
+	return a.UserID == scope.UserID && a.Scope().OrgID == scope.OrgID
+}
+
+func (m memoryInboundAddresses) List(_ context.Context, scope models.Scope) ([]models.InboundAddress, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	addresses := []models.InboundAddress{}
+	for _, a := range m.s.inboundAddresses {
+		if inboundAddressIn(a, scope) {
+			addresses = append(addresses, a)
+		}
+	}
+	sort.Slice(addresses, func(i, j int) bool {
+		if (addresses[i].ProjectID == nil) != (addresses[j].ProjectID == nil) {
+			return addresses[i].ProjectID == nil
+		}
+		return addresses[i].CreatedAt.Before(addresses[j].CreatedAt)
+	})
+	return addresses, nil
+}
+
+func (m memoryInboundAddresses) Rotate(_ context.Context, scope models.Scope, projectID *string, token string) (*models.InboundAddress, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for id, a := range m.s.inboundAddresses {
+		if inboundAddressIn(a, scope) && sameProject(a.ProjectID, projectID) {
+			a.Token = token
+			a.CreatedAt = now
+			m.s.inboundAddresses[id] = a
+			return &a, nil
+		}
+	}
+	a := models.InboundAddress{
+		ID:        newID(),
+		UserID:    scope.UserID,
+		OrgID:     scope.OrgIDPtr(),
+		ProjectID: projectID,
+		Token:     token,
+		CreatedAt: now,
+	}
+	m.s.inboundAddresses[a.ID] = a
+	return &a, nil
+}
+
+func (m memoryInboundAddresses) Delete(_ context.Context, scope models.Scope, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	a, ok := m.s.inboundAddresses[id]
+	if !ok || !inboundAddressIn(a, scope) {
+		return ErrNotFound
+	}
+	delete(m.s.inboundAddresses, id)
+	return nil
+}
+
+func (m memoryInboundAddresses) Lookup(_ context.Context, token string) (*models.InboundAddress, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	for _, a := range m.s.inboundAddresses {
+		if a.Token == token {
+			return &a, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func sameProject(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
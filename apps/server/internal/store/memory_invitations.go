@@ -0,0 +1,128 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+// memoryInvitation keeps the token hash the model leaves out.
+type memoryInvitation struct {
+	models.Invitation
+	tokenHash []byte
+}
+
+type memoryInvitations struct{ s *memoryStore }
+
+// pending looks up an invitation of the org that can still be accepted;
+// callers hold the lock.
+func (m memoryInvitations) pending(orgID, id string) (memoryInvitation, bool) {
+	i, ok := m.s.invitations[id]
+	return i, ok && i.OrgID == orgID && i.Pending(time.Now())
+}
+
+func (m memoryInvitations) Create(_ context.Context, orgID, invitedBy string, input models.CreateInvitationInput) (*models.Invitation, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	scope := models.Scope{OrgID: orgID, UserID: invitedBy}
+	if !(memoryShares{m.s}).liveProject(scope, input.ProjectID) {
+		return nil, ErrNotFound
+	}
+	for _, i := range m.s.invitations {
+		if bytes.Equal(i.tokenHash, input.TokenHash) {
+			return nil, ErrConflict
+		}
+	}
+
+	i := memoryInvitation{
+		Invitation: models.Invitation{
+			ID:                newID(),
+			OrgID:             orgID,
+			ProjectID:         input.ProjectID,
+			Email:             input.Email,
+			Role:              input.Role,
+			ClerkInvitationID: input.ClerkInvitationID,
+			InvitedBy:         invitedBy,
+			ExpiresAt:         input.ExpiresAt,
+			CreatedAt:         time.Now().UTC(),
+		},
+		tokenHash: input.TokenHash,
+	}
+	m.s.invitations[i.ID] = i
+	return &i.Invitation, nil
+}
+
+func (m memoryInvitations) GetByToken(_ context.Context, orgID string, tokenHash []byte) (*models.Invitation, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	for _, i := range m.s.invitations {
+		if i.OrgID == orgID && bytes.Equal(i.tokenHash, tokenHash) {
+			return &i.Invitation, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryInvitations) ListForProject(_ context.Context, orgID, projectID string) ([]models.Invitation, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	invitations := []models.Invitation{}
+	for _, i := range m.s.invitations {
+		if i.OrgID == orgID && i.ProjectID == projectID {
+			invitations = append(invitations, i.Invitation)
+		}
+	}
+	sort.Slice(invitations, func(i, j int) bool {
+		if !invitations[i].CreatedAt.Equal(invitations[j].CreatedAt) {
+			return invitations[i].CreatedAt.After(invitations[j].CreatedAt)
+		}
+		return invitations[i].ID > invitations[j].ID
+	})
+	return invitations, nil
+}
+
+func (m memoryInvitations) Revoke(_ context.Context, orgID, projectID, id string) (*models.Invitation, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	i, ok := m.pending(orgID, id)
+	if !ok || i.ProjectID != projectID {
+		return nil, ErrNotFound
+	}
+	now := time.Now().UTC()
+	i.RevokedAt = &now
+	m.s.invitations[id] = i
+	return &i.Invitation, nil
+}
+
+func (m memoryInvitations) Accept(_ context.Context, scope models.Scope, id string) (*models.Invitation, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	i, ok := m.pending(scope.OrgID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	now := time.Now().UTC()
+	i.AcceptedBy, i.AcceptedAt = &scope.UserID, &now
+	m.s.invitations[id] = i
+
+	key := i.ProjectID + "/" + scope.UserID
+	role := i.Role
+	if existing, ok := m.s.projectShares[key]; ok && existing.Role == models.ShareRoleEditor {
+		role = existing.Role
+	}
+	upsertShare(m.s.projectShares, key, models.Share{
+		ProjectID: &i.ProjectID,
+		UserID:    scope.UserID,
+		Role:      role,
+		CreatedBy: i.InvitedBy,
+		CreatedAt: now,
+	})
+	return &i.Invitation, nil
+}
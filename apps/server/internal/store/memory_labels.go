@@ -0,0 +1,169 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryLabels struct{ s *memoryStore }
+
+// nameTaken mirrors the case-insensitive unique index on labels; callers hold
+// the lock.
+func (m memoryLabels) nameTaken(orgID, name, exceptID string) bool {
+	for _, l := range m.s.labels {
+		if l.OrgID == orgID && l.ID != exceptID && strings.EqualFold(l.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m memoryLabels) Create(_ context.Context, orgID, userID string, input models.CreateLabelInput) (*models.Label, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if m.nameTaken(orgID, input.Name, "") {
+		return nil, ErrConflict
+	}
+
+	now := time.Now()
+	l := models.Label{
+		ID:        newID(),
+		OrgID:     orgID,
+		Name:      input.Name,
+		Color:     input.Color,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.labels[l.ID] = l
+	m.s.logLabel(l)
+	return &l, nil
+}
+
+func (m memoryLabels) List(_ context.Context, orgID string) ([]models.Label, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	labels := []models.Label{}
+	for _, l := range m.s.labels {
+		if l.OrgID == orgID {
+			labels = append(labels, l)
+		}
+	}
+	sortLabels(labels)
+	return labels, nil
+}
+
+func sortLabels(labels []models.Label) {
+	sort.Slice(labels, func(i, j int) bool {
+		a, b := strings.ToLower(labels[i].Name), strings.ToLower(labels[j].Name)
+		if a != b {
+			return a < b
+		}
+		return labels[i].ID < labels[j].ID
+	})
+}
+
+func (m memoryLabels) Update(_ context.Context, orgID, id string, input models.UpdateLabelInput) (*models.Label, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	l, ok := m.s.labels[id]
+	if !ok || l.OrgID != orgID {
+		return nil, ErrNotFound
+	}
+	if input.Name == nil && input.Color == nil {
+		return &l, nil
+	}
+
+	if input.Name != nil {
+		if m.nameTaken(orgID, *input.Name, id) {
+			return nil, ErrConflict
+		}
+		l.Name = *input.Name
+	}
+	if input.Color != nil {
+		l.Color = *input.Color
+	}
+	l.UpdatedAt = time.Now()
+	m.s.labels[id] = l
+	m.s.logLabel(l)
+	return &l, nil
+}
+
+func (m memoryLabels) Delete(_ context.Context, orgID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	l, ok := m.s.labels[id]
+	if !ok || l.OrgID != orgID {
+		return ErrNotFound
+	}
+	delete(m.s.labels, id)
+	m.s.logLabel(l)
+	for taskID, set := range m.s.taskLabels {
+		if set[id] {
+			delete(set, id)
+			m.s.logTask(m.s.tasks[taskID])
+		}
+	}
+	return nil
+}
+
+func (m memoryLabels) Attach(_ context.Context, orgID, taskID, labelID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, ok := m.s.tasks[taskID]
+	if !ok || t.DeletedAt != nil || t.OrgID == nil || *t.OrgID != orgID {
+		return ErrNotFound
+	}
+	l, ok := m.s.labels[labelID]
+	if !ok || l.OrgID != orgID {
+		return ErrNotFound
+	}
+
+	if m.s.taskLabels[taskID] == nil {
+		m.s.taskLabels[taskID] = map[string]bool{}
+	}
+	m.s.taskLabels[taskID][labelID] = true
+	m.s.logTask(t)
+	return nil
+}
+
+func (m memoryLabels) Detach(_ context.Context, orgID, taskID, labelID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	l, ok := m.s.labels[labelID]
+	if !ok || l.OrgID != orgID || !m.s.taskLabels[taskID][labelID] {
+		return ErrNotFound
+	}
+	delete(m.s.taskLabels[taskID], labelID)
+	m.s.logTask(m.s.tasks[taskID])
+	return nil
+}
+
+func (m memoryLabels) ForTasks(_ context.Context, orgID string, taskIDs []string) (map[string][]models.Label, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	byTask := map[string][]models.Label{}
+	for _, taskID := range taskIDs {
+		labels := []models.Label{}
+		for labelID := range m.s.taskLabels[taskID] {
+			if l, ok := m.s.labels[labelID]; ok && l.OrgID == orgID {
+				labels = append(labels, l)
+			}
+		}
+		if len(labels) > 0 {
+			sortLabels(labels)
+			byTask[taskID] = labels
+		}
+	}
+	return byTask, nil
+}
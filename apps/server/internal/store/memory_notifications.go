@@ -0,0 +1,108 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryNotifications struct{ s *memoryStore }
+
+func (m memoryNotifications) Create(_ context.Context, input models.CreateNotificationInput) (*models.Notification, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	n := models.Notification{
+		ID:        newID(),
+		UserID:    input.UserID,
+		OrgID:     input.OrgID,
+		Kind:      input.Kind,
+		TaskID:    input.TaskID,
+		ActorID:   input.ActorID,
+		Title:     input.Title,
+		Body:      input.Body,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.s.notifications[n.ID] = n
+	return &n, nil
+}
+
+func (m memoryNotifications) List(_ context.Context, userID string, unreadOnly bool, page models.Page) ([]models.Notification, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.Notification{}
+	for _, n := range m.s.notifications {
+		if n.UserID == userID && (!unreadOnly || n.ReadAt == nil) {
+			list = append(list, n)
+		}
+	}
+
+	// older reports whether n sorts after (t, id) in newest-first order.
+	older := func(n models.Notification, t time.Time, id string) bool {
+		if !n.CreatedAt.Equal(t) {
+			return n.CreatedAt.Before(t)
+		}
+		return n.ID < id
+	}
+	sort.Slice(list, func(i, j int) bool { return older(list[j], list[i].CreatedAt, list[i].ID) })
+
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		idx := sort.Search(len(list), func(i int) bool { return older(list[i], t, page.After[1]) })
+		list = list[idx:]
+	}
+
+	return limit(list, page.Limit), nil
+}
+
+func (m memoryNotifications) SetRead(_ context.Context, userID, id string, read bool) (*models.Notification, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	n, ok := m.s.notifications[id]
+	if !ok || n.UserID != userID {
+		return nil, ErrNotFound
+	}
+	if !read {
+		n.ReadAt = nil
+	} else if n.ReadAt == nil {
+		now := time.Now().UTC()
+		n.ReadAt = &now
+	}
+	m.s.notifications[id] = n
+	return &n, nil
+}
+
+func (m memoryNotifications) MarkAllRead(_ context.Context, userID string) (int, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	count := 0
+	for id, n := range m.s.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			n.ReadAt = &now
+			m.s.notifications[id] = n
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m memoryNotifications) UnreadCount(_ context.Context, userID string) (int, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	count := 0
+	for _, n := range m.s.notifications {
+		if n.UserID == userID && n.ReadAt == nil {
+			count++
+		}
+	}
+	return count, nil
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryOrgAdmin struct{ s *memoryStore }
+
+func (m memoryOrgAdmin) TaskCounts(_ context.Context, orgID string, userIDs []string) (map[string]models.MemberTaskCounts, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	counts := map[string]models.MemberTaskCounts{}
+	now := time.Now()
+	for _, t := range m.s.tasks {
+		if t.OrgID == nil || *t.OrgID != orgID || t.DeletedAt != nil || !slices.Contains(userIDs, t.OwnerID) {
+			continue
+		}
+		c := counts[t.OwnerID]
+		switch {
+		case t.CompletedAt != nil:
+			c.Completed++
+		case t.ArchivedAt == nil:
+			c.Open++
+			if t.DueDate != nil && t.DueDate.Before(now) {
+				c.Overdue++
+			}
+		}
+		counts[t.OwnerID] = c
+	}
+	return counts, nil
+}
+
+// reassign moves the org's tasks that match to toUserID, who must be a
+// member; callers hold the lock.
+func (m memoryOrgAdmin) reassign(orgID, toUserID string, match func(models.Task) bool) ([]models.ReassignedTask, error) {
+	if _, ok := m.s.memberships[orgID+"/"+toUserID]; !ok {
+		return nil, ErrNotFound
+	}
+	moved := []models.ReassignedTask{}
+	now := time.Now().UTC()
+	for id, t := range m.s.tasks {
+		if t.OrgID == nil || *t.OrgID != orgID || t.OwnerID == toUserID || !match(t) {
+			continue
+		}
+		moved = append(moved, models.ReassignedTask{ID: id, ProjectID: t.ProjectID, PreviousOwnerID: t.OwnerID})
+		t.OwnerID = toUserID
+		t.Version++
+		t.UpdatedAt = now
+		m.s.tasks[id] = t
+		m.s.logTask(t)
+	}
+	sort.Slice(moved, func(i, j int) bool { return moved[i].ID < moved[j].ID })
+	return moved, nil
+}
+
+func (m memoryOrgAdmin) TransferTasks(_ context.Context, orgID, fromUserID, toUserID string) ([]models.ReassignedTask, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	return m.reassign(orgID, toUserID, func(t models.Task) bool {
+		return t.DeletedAt == nil && t.OwnerID == fromUserID
+	})
+}
+
+func (m memoryOrgAdmin) ReassignTasks(_ context.Context, orgID string, ids []string, toUserID string) ([]models.ReassignedTask, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	return m.reassign(orgID, toUserID, func(t models.Task) bool {
+		return t.DeletedAt == nil && slices.Contains(ids, t.ID)
+	})
+}
@@ -0,0 +1,152 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryPomodoros struct{ s *memoryStore }
+
+// pomodoroIn reports whether the session is the scope user's own and in
+// the scope.
+func pomodoroIn(scope models.Scope, p models.Pomodoro) bool {
+	if p.UserID != scope.UserID {
+		return false
+	}
+	if p.OrgID == nil {
+		return !scope.IsOrg()
+	}
+	return *p.OrgID == scope.OrgID
+}
+
+func (m memoryPomodoros) Start(_ context.Context, scope models.Scope, taskID *string, plannedSeconds int) (*models.Pomodoro, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if taskID != nil {
+		if _, ok := m.s.liveTask(scope, *taskID); !ok {
+			return nil, ErrNotFound
+		}
+		id := *taskID
+		taskID = &id
+	}
+	for _, p := range m.s.pomodoros {
+		if p.UserID == scope.UserID && p.Status == models.PomodoroRunning {
+			return nil, ErrConflict
+		}
+	}
+
+	p := models.Pomodoro{
+		ID:             newID(),
+		UserID:         scope.UserID,
+		OrgID:          scope.OrgIDPtr(),
+		TaskID:         taskID,
+		Status:         models.PomodoroRunning,
+		PlannedSeconds: plannedSeconds,
+		StartedAt:      time.Now().UTC(),
+	}
+	m.s.pomodoros[p.ID] = p
+	return &p, nil
+}
+
+func (m memoryPomodoros) Get(_ context.Context, scope models.Scope, id string) (*models.Pomodoro, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	p, ok := m.s.pomodoros[id]
+	if !ok || !pomodoroIn(scope, p) {
+		return nil, ErrNotFound
+	}
+	return &p, nil
+}
+
+func (m memoryPomodoros) Running(_ context.Context, userID string) (*models.Pomodoro, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	for _, p := range m.s.pomodoros {
+		if p.UserID == userID && p.Status == models.PomodoroRunning {
+			return &p, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryPomodoros) Finish(_ context.Context, scope models.Scope, id, status string) (*models.Pomodoro, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	p, ok := m.s.pomodoros[id]
+	if !ok || !pomodoroIn(scope, p) || p.Status != models.PomodoroRunning {
+		return nil, ErrNotFound
+	}
+	now := time.Now().UTC()
+	p.Status, p.EndedAt = status, &now
+	m.s.pomodoros[id] = p
+	return &p, nil
+}
+
+func (m memoryPomodoros) List(_ context.Context, scope models.Scope, page models.Page) ([]models.Pomodoro, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.Pomodoro{}
+	for _, p := range m.s.pomodoros {
+		if pomodoroIn(scope, p) {
+			list = append(list, p)
+		}
+	}
+
+	// older reports whether p sorts after (t, id) in newest-first order.
+	older := func(p models.Pomodoro, t time.Time, id string) bool {
+		if !p.StartedAt.Equal(t) {
+			return p.StartedAt.Before(t)
+		}
+		return p.ID < id
+	}
+	sort.Slice(list, func(i, j int) bool { return older(list[j], list[i].StartedAt, list[i].ID) })
+
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		idx := sort.Search(len(list), func(i int) bool { return older(list[i], t, page.After[1]) })
+		list = list[idx:]
+	}
+
+	return limit(list, page.Limit), nil
+}
+
+func (m memoryPomodoros) Days(_ context.Context, scope models.Scope, loc *time.Location) ([]models.PomodoroDay, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	byDate := map[string]*models.PomodoroDay{}
+	for _, p := range m.s.pomodoros {
+		if !pomodoroIn(scope, p) || p.Status == models.PomodoroRunning {
+			continue
+		}
+		date := p.StartedAt.In(loc).Format(time.DateOnly)
+		d, ok := byDate[date]
+		if !ok {
+			d = &models.PomodoroDay{Date: date}
+			byDate[date] = d
+		}
+		if p.Status == models.PomodoroCompleted {
+			d.Completed++
+		} else {
+			d.Interrupted++
+		}
+		d.FocusSeconds += int64(p.EndedAt.Sub(p.StartedAt) / time.Second)
+	}
+
+	days := make([]models.PomodoroDay, 0, len(byDate))
+	for _, d := range byDate {
+		days = append(days, *d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Date < days[j].Date })
+	return days, nil
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryEmailPreferences struct{ s *memoryStore }
+
+func (m memoryEmailPreferences) Get(_ context.Context, userID string) (models.EmailPreferences, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if p, ok := m.s.emailPrefs[userID]; ok {
+		return p, nil
+	}
+	return models.DefaultEmailPreferences(userID), nil
+}
+
+func (m memoryEmailPreferences) Update(_ context.Context, userID string, input models.UpdateEmailPreferencesInput) (models.EmailPreferences, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	p, ok := m.s.emailPrefs[userID]
+	if !ok {
+		p = models.DefaultEmailPreferences(userID)
+	}
+	if input.TaskAssigned != nil {
+		p.TaskAssigned = *input.TaskAssigned
+	}
+	if input.DueSoon != nil {
+		p.DueSoon = *input.DueSoon
+	}
+	if input.CommentMention != nil {
+		p.CommentMention = *input.CommentMention
+	}
+	p.UpdatedAt = time.Now().UTC()
+
+	m.s.emailPrefs[userID] = p
+	return p, nil
+}
+
+type memoryUserSettings struct{ s *memoryStore }
+
+func (m memoryUserSettings) Get(_ context.Context, userID string) (models.UserSettings, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if s, ok := m.s.settings[userID]; ok {
+		return s, nil
+	}
+	return models.DefaultUserSettings(userID), nil
+}
+
+func (m memoryUserSettings) Update(_ context.Context, userID string, input models.UpdateUserSettingsInput) (models.UserSettings, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	s, ok := m.s.settings[userID]
+	if !ok {
+		s = models.DefaultUserSettings(userID)
+	}
+	s = input.Apply(s)
+	s.UpdatedAt = time.Now().UTC()
+
+	m.s.settings[userID] = s
+	return s, nil
+}
+
+type memoryOrgSettings struct{ s *memoryStore }
+
+func (m memoryOrgSettings) Get(_ context.Context, orgID string) (models.OrgSettings, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if s, ok := m.s.orgConfig[orgID]; ok {
+		return s, nil
+	}
+	return models.DefaultOrgSettings(orgID), nil
+}
+
+func (m memoryOrgSettings) Update(_ context.Context, orgID string, input models.UpdateOrgSettingsInput) (models.OrgSettings, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	s, ok := m.s.orgConfig[orgID]
+	if !ok {
+		s = models.DefaultOrgSettings(orgID)
+	}
+	s = input.Apply(s)
+	s.UpdatedAt = time.Now().UTC()
+
+	m.s.orgConfig[orgID] = s
+	return s, nil
+}
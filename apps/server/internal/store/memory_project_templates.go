@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryProjectTemplates struct{ s *memoryStore }
+
+func (m memoryProjectTemplates) Create(_ context.Context, scope models.Scope, name string, tasks []models.ProjectTemplateTask) (*models.ProjectTemplate, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	t := models.ProjectTemplate{
+		ID:        newID(),
+		OrgID:     scope.OrgID,
+		Name:      name,
+		CreatedBy: scope.UserID,
+		Tasks:     tasks,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.projectTemplates[t.ID] = t
+	return &t, nil
+}
+
+func (m memoryProjectTemplates) Get(_ context.Context, scope models.Scope, id string) (*models.ProjectTemplate, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	t, ok := m.s.projectTemplates[id]
+	if !ok || t.OrgID != scope.OrgID {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+func (m memoryProjectTemplates) List(_ context.Context, scope models.Scope) ([]models.ProjectTemplate, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	templates := []models.ProjectTemplate{}
+	for _, t := range m.s.projectTemplates {
+		if t.OrgID == scope.OrgID {
+			templates = append(templates, t)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].ID < templates[j].ID
+	})
+	return templates, nil
+}
+
+// own looks up a template the scope may change; callers hold the lock.
+func (m memoryProjectTemplates) own(scope models.Scope, id string) (models.ProjectTemplate, error) {
+	t, ok := m.s.projectTemplates[id]
+	switch {
+	case !ok || t.OrgID != scope.OrgID:
+		return t, ErrNotFound
+	case t.CreatedBy != scope.UserID:
+		return t, ErrReadOnly
+	}
+	return t, nil
+}
+
+func (m memoryProjectTemplates) Update(_ context.Context, scope models.Scope, id string, input models.UpdateProjectTemplateInput) (*models.ProjectTemplate, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, err := m.own(scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		t.Name = *input.Name
+	}
+	if input.Tasks != nil {
+		t.Tasks = input.Tasks
+	}
+	t.UpdatedAt = time.Now().UTC()
+	m.s.projectTemplates[id] = t
+	return &t, nil
+}
+
+func (m memoryProjectTemplates) Delete(_ context.Context, scope models.Scope, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, err := m.own(scope, id); err != nil {
+		return err
+	}
+	delete(m.s.projectTemplates, id)
+	return nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryPushSubscriptions struct{ s *memoryStore }
+
+func (m memoryPushSubscriptions) Create(_ context.Context, userID string, input models.CreatePushSubscriptionInput) (*models.PushSubscription, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	sub, ok := m.s.pushSubs[input.Endpoint]
+	if !ok {
+		sub = models.PushSubscription{ID: newID(), Endpoint: input.Endpoint, CreatedAt: time.Now().UTC()}
+	}
+	sub.UserID = userID
+	sub.P256dh = input.Keys.P256dh
+	sub.Auth = input.Keys.Auth
+
+	m.s.pushSubs[input.Endpoint] = sub
+	return &sub, nil
+}
+
+func (m memoryPushSubscriptions) Delete(_ context.Context, userID, endpoint string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	sub, ok := m.s.pushSubs[endpoint]
+	if !ok || sub.UserID != userID {
+		return ErrNotFound
+	}
+	delete(m.s.pushSubs, endpoint)
+	return nil
+}
+
+func (m memoryPushSubscriptions) ListForUser(_ context.Context, userID string) ([]models.PushSubscription, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	subs := []models.PushSubscription{}
+	for _, sub := range m.s.pushSubs {
+		if sub.UserID == userID {
+			subs = append(subs, sub)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].CreatedAt.Before(subs[j].CreatedAt) })
+	return subs, nil
+}
@@ -0,0 +1,170 @@
+package store
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+func matchesTaskFilter(t *models.Task, f models.TaskFilter, labels map[string]bool) bool {
+	if f.ProjectID != "" && (t.ProjectID == nil || *t.ProjectID != f.ProjectID) {
+		return false
+	}
+	if f.LabelID != "" && !labels[f.LabelID] {
+		return false
+	}
+	if f.OwnerID != "" && t.OwnerID != f.OwnerID {
+		return false
+	}
+	if f.Visibility != "" && t.Visibility != f.Visibility {
+		return false
+	}
+	if f.ParentID != "" && (t.ParentID == nil || *t.ParentID != f.ParentID) {
+		return false
+	}
+	if f.TopLevel && t.ParentID != nil {
+		return false
+	}
+	if !f.IncludeArchived && t.ArchivedAt != nil {
+		return false
+	}
+	if len(f.Statuses) > 0 {
+		found := false
+		for _, s := range f.Statuses {
+			if s == t.Status {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if f.OpenOnly && slices.Contains(f.DoneStatuses, t.Status) {
+		return false
+	}
+	if f.Overdue && (t.DueDate == nil || !t.DueDate.Before(time.Now()) || slices.Contains(f.DoneStatuses, t.Status)) {
+		return false
+	}
+	if f.DueBefore != nil && (t.DueDate == nil || !t.DueDate.Before(*f.DueBefore)) {
+		return false
+	}
+	if f.DueAfter != nil && (t.DueDate == nil || t.DueDate.Before(*f.DueAfter)) {
+		return false
+	}
+	if f.HideSnoozed && t.SnoozedUntil != nil && t.SnoozedUntil.After(time.Now()) {
+		return false
+	}
+	if f.Priority != nil && t.Priority != *f.Priority {
+		return false
+	}
+	for _, ff := range f.Fields {
+		if !matchesFieldFilter(t.CustomFields[ff.Key], ff) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesFieldFilter mirrors the repository's custom field conditions.
+func matchesFieldFilter(value any, f models.FieldFilter) bool {
+	if value == nil {
+		return false
+	}
+	var c int
+	switch v := value.(type) {
+	case float64:
+		want, ok := f.Value.(float64)
+		if !ok {
+			return false
+		}
+		c = cmp.Compare(v, want)
+	case string:
+		want, ok := f.Value.(string)
+		if !ok {
+			return false
+		}
+		if f.Type == models.CustomFieldText {
+			return strings.Contains(strings.ToLower(v), strings.ToLower(want))
+		}
+		c = strings.Compare(v, want)
+	default:
+		return false
+	}
+
+	switch f.Op {
+	case "=":
+		return c == 0
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	}
+	return false
+}
+
+// compareSortValues compares two cursor-style values of a sort field.
+func compareSortValues(field, a, b string) int {
+	switch field {
+	case "created_at", "updated_at", "due_date":
+		return compareTimes(a, b)
+	case "priority":
+		x, _ := strconv.Atoi(a)
+		y, _ := strconv.Atoi(b)
+		return x - y
+	}
+	return strings.Compare(a, b)
+}
+
+func compareTimes(a, b string) int {
+	rank := func(v string) (int, time.Time) {
+		switch v {
+		case "-infinity":
+			return -1, time.Time{}
+		case "infinity":
+			return 1, time.Time{}
+		}
+		t, _ := time.Parse(time.RFC3339Nano, v)
+		return 0, t
+	}
+
+	ra, ta := rank(a)
+	rb, tb := rank(b)
+	if ra != rb {
+		return ra - rb
+	}
+	return ta.Compare(tb)
+}
+
+func compareTaskToCursor(t *models.Task, sorts []models.SortField, after []string) int {
+	for i, s := range sorts {
+		c := compareSortValues(s.Field, models.TaskSortValue(t, s), after[i])
+		if s.Desc {
+			c = -c
+		}
+		if c != 0 {
+			return c
+		}
+	}
+
+	c := strings.Compare(t.ID, after[len(sorts)])
+	if len(sorts) > 0 && sorts[len(sorts)-1].Desc {
+		c = -c
+	}
+	return c
+}
+
+func compareTasks(a, b *models.Task, sorts []models.SortField) int {
+	values := make([]string, 0, len(sorts)+1)
+	for _, s := range sorts {
+		values = append(values, models.TaskSortValue(b, s))
+	}
+	return compareTaskToCursor(a, sorts, append(values, b.ID))
+}
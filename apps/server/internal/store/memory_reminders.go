@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryReminders struct{ s *memoryStore }
+
+// withFireAt fills in FireAt from the task's current due date and snooze;
+// callers hold the lock.
+func (m memoryReminders) withFireAt(r models.Reminder) models.Reminder {
+	t, ok := m.s.tasks[r.TaskID]
+	r.FireAt = r.RemindAt
+	if r.MinutesBefore != nil {
+		r.FireAt = nil
+		if ok && t.DueDate != nil {
+			at := t.DueDate.Add(-time.Duration(*r.MinutesBefore) * time.Minute)
+			r.FireAt = &at
+		}
+	}
+	if r.FireAt != nil && ok && t.SnoozedUntil != nil && t.SnoozedUntil.After(*r.FireAt) {
+		at := *t.SnoozedUntil
+		r.FireAt = &at
+	}
+	return r
+}
+
+func (m memoryReminders) Create(_ context.Context, scope models.Scope, taskID string, input models.CreateReminderInput) (*models.Reminder, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	_, ok := m.s.liveTask(scope, taskID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	r := models.Reminder{
+		ID:            newID(),
+		TaskID:        taskID,
+		UserID:        scope.UserID,
+		RemindAt:      input.RemindAt,
+		MinutesBefore: input.MinutesBefore,
+		CreatedAt:     time.Now().UTC(),
+	}
+	m.s.reminders[r.ID] = r
+
+	r = m.withFireAt(r)
+	return &r, nil
+}
+
+func (m memoryReminders) List(_ context.Context, scope models.Scope, taskID string) ([]models.Reminder, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	reminders := []models.Reminder{}
+	_, ok := m.s.liveTask(scope, taskID)
+	if !ok {
+		return reminders, nil
+	}
+
+	for _, r := range m.s.reminders {
+		if r.TaskID == taskID && r.UserID == scope.UserID {
+			reminders = append(reminders, m.withFireAt(r))
+		}
+	}
+	sort.Slice(reminders, func(i, j int) bool {
+		a, b := reminders[i].FireAt, reminders[j].FireAt
+		if (a == nil) != (b == nil) {
+			return b == nil
+		}
+		if a != nil && !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return reminders[i].CreatedAt.Before(reminders[j].CreatedAt)
+	})
+	return reminders, nil
+}
+
+func (m memoryReminders) Delete(_ context.Context, scope models.Scope, taskID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	r, ok := m.s.reminders[id]
+	if !ok || r.TaskID != taskID || r.UserID != scope.UserID {
+		return ErrNotFound
+	}
+	delete(m.s.reminders, id)
+	return nil
+}
+
+func (m memoryReminders) ClaimDue(_ context.Context, limit int) ([]models.DueReminder, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	due := []models.DueReminder{}
+	for id, r := range m.s.reminders {
+		r = m.withFireAt(r)
+		if r.SentAt != nil || r.FireAt == nil || r.FireAt.After(now) || m.s.tasks[r.TaskID].DeletedAt != nil {
+			continue
+		}
+
+		r.SentAt = &now
+		m.s.reminders[id] = r
+		due = append(due, models.DueReminder{Reminder: r, Task: m.s.tasks[r.TaskID]})
+		if len(due) == limit {
+			break
+		}
+	}
+	return due, nil
+}
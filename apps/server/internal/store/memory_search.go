@@ -0,0 +1,214 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"yata/apps/server/internal/models"
+)
+
+// memorySearch approximates full-text search with case-insensitive term
+// matching: every term must appear, title hits rank above description hits.
+type memorySearch struct{ s *memoryStore }
+
+func (m memorySearch) SearchTasks(_ context.Context, scope models.Scope, query string, limit int) ([]models.SearchResult, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	terms := strings.Fields(strings.ToLower(query))
+	results := []models.SearchResult{}
+
+	for _, t := range m.s.tasks {
+		if t.DeletedAt != nil || !m.s.canAccess(scope, t, models.ShareRoleViewer) || len(terms) == 0 {
+			continue
+		}
+
+		title, desc := strings.ToLower(t.Title), strings.ToLower(t.Description)
+		var rank float32
+		matched := true
+		for _, term := range terms {
+			switch {
+			case strings.Contains(title, term):
+				rank += 1
+			case strings.Contains(desc, term):
+				rank += 0.4
+			default:
+				matched = false
+			}
+		}
+		if !matched {
+			continue
+		}
+
+		results = append(results, models.SearchResult{Task: t, Rank: rank, Title: t.Title, Snippet: t.Description})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+func (m memorySearch) Suggest(_ context.Context, scope models.Scope, kind, query string, limit int) ([]models.Suggestion, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	query = strings.ToLower(query)
+	type candidate struct {
+		models.Suggestion
+		whole bool
+	}
+	candidates := []candidate{}
+	add := func(s models.Suggestion, text string) {
+		if used := s.LastUsedAt; used != nil && used.IsZero() {
+			s.LastUsedAt = nil
+		}
+		text = strings.ToLower(text)
+		if strings.HasPrefix(text, query) || strings.Contains(text, " "+query) {
+			candidates = append(candidates, candidate{Suggestion: s, whole: strings.HasPrefix(strings.ToLower(s.Name), query)})
+		}
+	}
+
+	switch kind {
+	case models.SuggestLabel:
+		for _, l := range m.s.labels {
+			if l.OrgID != scope.OrgID {
+				continue
+			}
+			var used time.Time
+			for taskID, labels := range m.s.taskLabels {
+				if labels[l.ID] && m.s.tasks[taskID].UpdatedAt.After(used) {
+					used = m.s.tasks[taskID].UpdatedAt
+				}
+			}
+			add(models.Suggestion{ID: l.ID, Name: l.Name, Color: l.Color, LastUsedAt: &used}, l.Name)
+		}
+	case models.SuggestProject:
+		for _, p := range m.s.projects {
+			if p.OrgID != scope.OrgID || p.DeletedAt != nil || p.ArchivedAt != nil {
+				continue
+			}
+			var used time.Time
+			for _, t := range m.s.tasks {
+				if t.ProjectID != nil && *t.ProjectID == p.ID && t.DeletedAt == nil && t.UpdatedAt.After(used) {
+					used = t.UpdatedAt
+				}
+			}
+			add(models.Suggestion{ID: p.ID, Name: p.Name, LastUsedAt: &used}, p.Name)
+		}
+	case models.SuggestMember:
+		for _, ms := range m.s.memberships {
+			u, ok := m.s.users[ms.UserID]
+			if ms.OrgID != scope.OrgID || !ok || u.DeletedAt != nil {
+				continue
+			}
+			var used time.Time
+			for _, a := range m.s.activity {
+				if a.OrgID != nil && *a.OrgID == scope.OrgID && a.ActorID == u.ID && a.CreatedAt.After(used) {
+					used = a.CreatedAt
+				}
+			}
+			deref := func(s *string) string {
+				if s == nil {
+					return ""
+				}
+				return *s
+			}
+			name := strings.TrimSpace(deref(u.FirstName) + " " + deref(u.LastName))
+			for _, fallback := range []string{deref(u.Username), deref(u.Email), u.ID} {
+				if name == "" {
+					name = fallback
+				}
+			}
+			text := deref(u.FirstName) + " " + deref(u.LastName) + " " + deref(u.Username) + " " + deref(u.Email)
+			add(models.Suggestion{ID: u.ID, Name: name, Email: u.Email, ImageURL: u.ImageURL, LastUsedAt: &used}, text)
+		}
+	default:
+		return nil, fmt.Errorf("unknown suggestion type %q", kind)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.whole != b.whole {
+			return a.whole
+		}
+		if (a.LastUsedAt == nil) != (b.LastUsedAt == nil) {
+			return a.LastUsedAt != nil
+		}
+		if a.LastUsedAt != nil && !a.LastUsedAt.Equal(*b.LastUsedAt) {
+			return a.LastUsedAt.After(*b.LastUsedAt)
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+	suggestions := make([]models.Suggestion, 0, min(len(candidates), limit))
+	for _, c := range candidates[:min(len(candidates), limit)] {
+		suggestions = append(suggestions, c.Suggestion)
+	}
+	return suggestions, nil
+}
+
+func (m memorySearch) SimilarTasks(_ context.Context, scope models.Scope, projectID *string, title string, doneStatuses []string, limit int) ([]models.SimilarTask, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	want := trigrams(title)
+	similar := []models.SimilarTask{}
+	for _, t := range m.s.tasks {
+		if t.DeletedAt != nil || t.ArchivedAt != nil || slices.Contains(doneStatuses, t.Status) || !m.s.canAccess(scope, t, models.ShareRoleViewer) {
+			continue
+		}
+		if (projectID == nil) != (t.ProjectID == nil) || (projectID != nil && *projectID != *t.ProjectID) {
+			continue
+		}
+		if sim := trigramSimilarity(want, trigrams(t.Title)); sim >= models.DuplicateSimilarity {
+			similar = append(similar, models.SimilarTask{Task: t, Similarity: sim})
+		}
+	}
+
+	sort.Slice(similar, func(i, j int) bool {
+		if similar[i].Similarity != similar[j].Similarity {
+			return similar[i].Similarity > similar[j].Similarity
+		}
+		return similar[i].Task.UpdatedAt.After(similar[j].Task.UpdatedAt)
+	})
+	if len(similar) > limit {
+		similar = similar[:limit]
+	}
+	return similar, nil
+}
+
+// trigrams is the set of trigrams pg_trgm would take from s: those of each
+// lower-cased alphanumeric word, padded with two spaces before and one after.
+func trigrams(s string) map[string]bool {
+	set := map[string]bool{}
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		padded := []rune("  " + word + " ")
+		for i := 0; i+3 <= len(padded); i++ {
+			set[string(padded[i:i+3])] = true
+		}
+	}
+	return set
+}
+
+// trigramSimilarity is pg_trgm's similarity: shared trigrams over all of them.
+func trigramSimilarity(a, b map[string]bool) float32 {
+	shared := 0
+	for t := range a {
+		if b[t] {
+			shared++
+		}
+	}
+	total := len(a) + len(b) - shared
+	if total == 0 {
+		return 0
+	}
+	return float32(shared) / float32(total)
+}
@@ -0,0 +1,174 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryShareLinks struct{ s *memoryStore }
+
+// deleteShareLink drops a link along with its access log, like the cascade;
+// callers hold the lock.
+func (s *memoryStore) deleteShareLink(id string) {
+	delete(s.shareLinks, id)
+	s.linkAccesses = slices.DeleteFunc(s.linkAccesses, func(a models.ShareLinkAccess) bool { return a.LinkID == id })
+}
+
+// ownLink looks up a link the caller made in scope; callers hold the lock.
+func (m memoryShareLinks) ownLink(scope models.Scope, id string) (models.ShareLink, bool) {
+	l, ok := m.s.shareLinks[id]
+	return l, ok && l.CreatedBy == scope.UserID && l.Scope().OrgID == scope.OrgID
+}
+
+func (m memoryShareLinks) Create(_ context.Context, scope models.Scope, input models.CreateShareLinkInput) (*models.ShareLink, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	switch {
+	case input.TaskID != nil:
+		if _, ok := m.s.editableTask(scope, *input.TaskID); !ok {
+			return nil, m.s.writeMiss(scope, *input.TaskID)
+		}
+	case input.ProjectID != nil:
+		if !(memoryShares{m.s}).liveProject(scope, *input.ProjectID) {
+			return nil, ErrNotFound
+		}
+	default:
+		return nil, ErrNotFound
+	}
+
+	l := models.ShareLink{
+		ID:        newID(),
+		OrgID:     scope.OrgIDPtr(),
+		TaskID:    input.TaskID,
+		ProjectID: input.ProjectID,
+		CreatedBy: scope.UserID,
+		ExpiresAt: *input.ExpiresAt,
+		CreatedAt: time.Now().UTC(),
+	}
+	m.s.shareLinks[l.ID] = l
+	return &l, nil
+}
+
+func (m memoryShareLinks) Get(_ context.Context, id string) (*models.ShareLink, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	l, ok := m.s.shareLinks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &l, nil
+}
+
+func (m memoryShareLinks) list(scope models.Scope, match func(models.ShareLink) bool) []models.ShareLink {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	links := []models.ShareLink{}
+	for id, l := range m.s.shareLinks {
+		if _, own := m.ownLink(scope, id); own && match(l) {
+			links = append(links, l)
+		}
+	}
+	sort.Slice(links, func(i, j int) bool {
+		if !links[i].CreatedAt.Equal(links[j].CreatedAt) {
+			return links[i].CreatedAt.After(links[j].CreatedAt)
+		}
+		return links[i].ID > links[j].ID
+	})
+	return links
+}
+
+func (m memoryShareLinks) ListForTask(_ context.Context, scope models.Scope, taskID string) ([]models.ShareLink, error) {
+	return m.list(scope, func(l models.ShareLink) bool { return l.TaskID != nil && *l.TaskID == taskID }), nil
+}
+
+func (m memoryShareLinks) ListForProject(_ context.Context, scope models.Scope, projectID string) ([]models.ShareLink, error) {
+	return m.list(scope, func(l models.ShareLink) bool { return l.ProjectID != nil && *l.ProjectID == projectID }), nil
+}
+
+func (m memoryShareLinks) Revoke(_ context.Context, scope models.Scope, id string) (*models.ShareLink, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	l, ok := m.ownLink(scope, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if l.RevokedAt == nil {
+		now := time.Now().UTC()
+		l.RevokedAt = &now
+		m.s.shareLinks[id] = l
+	}
+	return &l, nil
+}
+
+func (m memoryShareLinks) RevokeMember(_ context.Context, orgID, userID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	for id, l := range m.s.shareLinks {
+		if l.CreatedBy == userID && l.Scope().OrgID == orgID && l.RevokedAt == nil {
+			l.RevokedAt = &now
+			m.s.shareLinks[id] = l
+		}
+	}
+	return nil
+}
+
+func (m memoryShareLinks) RecordAccess(_ context.Context, linkID, ip, userAgent string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.shareLinks[linkID]; !ok {
+		return ErrNotFound
+	}
+	m.s.linkAccesses = append(m.s.linkAccesses, models.ShareLinkAccess{
+		ID:         newID(),
+		LinkID:     linkID,
+		IP:         ip,
+		UserAgent:  userAgent,
+		AccessedAt: time.Now().UTC(),
+	})
+	return nil
+}
+
+func (m memoryShareLinks) ListAccesses(_ context.Context, scope models.Scope, id string, page models.Page) ([]models.ShareLinkAccess, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	accesses := []models.ShareLinkAccess{}
+	if _, ok := m.ownLink(scope, id); !ok {
+		return accesses, nil
+	}
+
+	var after *time.Time
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		after = &t
+	}
+	for _, a := range m.s.linkAccesses {
+		if a.LinkID != id {
+			continue
+		}
+		if after != nil && (a.AccessedAt.After(*after) || a.AccessedAt.Equal(*after) && a.ID >= page.After[1]) {
+			continue
+		}
+		accesses = append(accesses, a)
+	}
+	sort.Slice(accesses, func(i, j int) bool {
+		if !accesses[i].AccessedAt.Equal(accesses[j].AccessedAt) {
+			return accesses[i].AccessedAt.After(accesses[j].AccessedAt)
+		}
+		return accesses[i].ID > accesses[j].ID
+	})
+	return limit(accesses, page.Limit), nil
+}
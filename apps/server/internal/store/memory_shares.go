@@ -0,0 +1,130 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryShares struct{ s *memoryStore }
+
+// liveProject looks up a live org project; callers hold the lock.
+func (m memoryShares) liveProject(scope models.Scope, id string) bool {
+	p, ok := m.s.projects[id]
+	return ok && scope.IsOrg() && p.OrgID == scope.OrgID && p.DeletedAt == nil
+}
+
+// sharesOf lists the shares keyed under id, oldest first; callers hold the
+// lock.
+func sharesOf(shares map[string]models.Share, id string) []models.Share {
+	list := []models.Share{}
+	for key, share := range shares {
+		if strings.HasPrefix(key, id+"/") {
+			list = append(list, share)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].UserID < list[j].UserID
+	})
+	return list
+}
+
+// upsertShare keeps CreatedBy and CreatedAt of an existing share, like the
+// repository's ON CONFLICT; callers hold the lock.
+func upsertShare(shares map[string]models.Share, key string, share models.Share) models.Share {
+	if existing, ok := shares[key]; ok {
+		existing.Role = share.Role
+		share = existing
+	}
+	shares[key] = share
+	return share
+}
+
+func (m memoryShares) ShareTask(_ context.Context, scope models.Scope, taskID string, input models.ShareInput) (*models.Share, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.liveTask(scope, taskID); !ok || !scope.IsOrg() {
+		return nil, ErrNotFound
+	}
+	share := upsertShare(m.s.taskShares, taskID+"/"+input.UserID, models.Share{
+		TaskID:    &taskID,
+		UserID:    input.UserID,
+		Role:      input.Role,
+		CreatedBy: scope.UserID,
+		CreatedAt: time.Now().UTC(),
+	})
+	return &share, nil
+}
+
+func (m memoryShares) ListTaskShares(_ context.Context, scope models.Scope, taskID string) ([]models.Share, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return []models.Share{}, nil
+	}
+	return sharesOf(m.s.taskShares, taskID), nil
+}
+
+func (m memoryShares) UnshareTask(_ context.Context, scope models.Scope, taskID, userID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key := taskID + "/" + userID
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return ErrNotFound
+	}
+	if _, ok := m.s.taskShares[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.s.taskShares, key)
+	return nil
+}
+
+func (m memoryShares) ShareProject(_ context.Context, scope models.Scope, projectID string, input models.ShareInput) (*models.Share, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if !m.liveProject(scope, projectID) {
+		return nil, ErrNotFound
+	}
+	share := upsertShare(m.s.projectShares, projectID+"/"+input.UserID, models.Share{
+		ProjectID: &projectID,
+		UserID:    input.UserID,
+		Role:      input.Role,
+		CreatedBy: scope.UserID,
+		CreatedAt: time.Now().UTC(),
+	})
+	return &share, nil
+}
+
+func (m memoryShares) ListProjectShares(_ context.Context, scope models.Scope, projectID string) ([]models.Share, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if !m.liveProject(scope, projectID) {
+		return []models.Share{}, nil
+	}
+	return sharesOf(m.s.projectShares, projectID), nil
+}
+
+func (m memoryShares) UnshareProject(_ context.Context, scope models.Scope, projectID, userID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key := projectID + "/" + userID
+	if !m.liveProject(scope, projectID) {
+		return ErrNotFound
+	}
+	if _, ok := m.s.projectShares[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.s.projectShares, key)
+	return nil
+}
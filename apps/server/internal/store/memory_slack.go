@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memorySlack struct{ s *memoryStore }
+
+func (m memorySlack) Get(_ context.Context, orgID string) (*models.SlackIntegration, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	s, ok := m.s.slack[orgID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &s, nil
+}
+
+func (m memorySlack) Set(_ context.Context, orgID string, input models.SetSlackIntegrationInput) (*models.SlackIntegration, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if input.TeamID != nil {
+		for other, s := range m.s.slack {
+			if other != orgID && s.TeamID != nil && *s.TeamID == *input.TeamID {
+				return nil, ErrConflict
+			}
+		}
+	}
+	s := m.s.slack[orgID]
+	s.OrgID = orgID
+	s.TeamID = input.TeamID
+	s.TaskAssignedChannel = input.TaskAssignedChannel
+	s.DueSoonChannel = input.DueSoonChannel
+	if input.BotToken.Set {
+		s.BotToken = input.BotToken.Value
+	}
+	if input.WebhookURL.Set {
+		s.WebhookURL = input.WebhookURL.Value
+	}
+	s.HasBotToken = s.BotToken != ""
+	s.HasWebhook = s.WebhookURL != ""
+	s.UpdatedAt = time.Now().UTC()
+	m.s.slack[orgID] = s
+	return &s, nil
+}
+
+func (m memorySlack) Delete(_ context.Context, orgID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.slack[orgID]; !ok {
+		return ErrNotFound
+	}
+	delete(m.s.slack, orgID)
+	return nil
+}
+
+func (m memorySlack) GetByTeam(_ context.Context, teamID string) (*models.SlackIntegration, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	for _, s := range m.s.slack {
+		if s.TeamID != nil && *s.TeamID == teamID {
+			return &s, nil
+		}
+	}
+	return nil, ErrNotFound
+}
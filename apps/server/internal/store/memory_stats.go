@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryStats struct{ s *memoryStore }
+
+// visible returns the live tasks the scope can see; callers hold the lock.
+func (m memoryStats) visible(scope models.Scope) []models.Task {
+	var tasks []models.Task
+	for _, t := range m.s.tasks {
+		if t.DeletedAt == nil && m.s.canAccess(scope, t, models.ShareRoleViewer) {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks
+}
+
+// openAt reports whether t existed by at without having been completed yet.
+func openAt(t models.Task, at time.Time) bool {
+	return !t.CreatedAt.After(at) && (t.CompletedAt == nil || t.CompletedAt.After(at))
+}
+
+func (m memoryStats) Completed(_ context.Context, scope models.Scope, bounds []time.Time) ([]int, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	counts := make([]int, max(len(bounds)-1, 0))
+	for _, t := range m.visible(scope) {
+		if t.CompletedAt == nil {
+			continue
+		}
+		for i := range counts {
+			if !t.CompletedAt.Before(bounds[i]) && t.CompletedAt.Before(bounds[i+1]) {
+				counts[i]++
+				break
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (m memoryStats) Overdue(_ context.Context, scope models.Scope, points []time.Time) ([]int, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	counts := make([]int, len(points))
+	for _, t := range m.visible(scope) {
+		if t.DueDate == nil {
+			continue
+		}
+		for i, at := range points {
+			if t.DueDate.Before(at) && openAt(t, at) {
+				counts[i]++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func (m memoryStats) Throughput(_ context.Context, scope models.Scope, from, to time.Time) ([]models.MemberThroughput, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	byOwner := map[string]*models.MemberThroughput{}
+	cycles := map[string]time.Duration{}
+	for _, t := range m.visible(scope) {
+		if t.CompletedAt == nil || t.CompletedAt.Before(from) || !t.CompletedAt.Before(to) {
+			continue
+		}
+		member, ok := byOwner[t.OwnerID]
+		if !ok {
+			member = &models.MemberThroughput{UserID: t.OwnerID}
+			byOwner[t.OwnerID] = member
+		}
+		member.Completed++
+		cycles[t.OwnerID] += t.CompletedAt.Sub(t.CreatedAt)
+	}
+
+	members := make([]models.MemberThroughput, 0, len(byOwner))
+	for id, member := range byOwner {
+		member.AvgCycleSeconds = int64(cycles[id] / time.Duration(member.Completed) / time.Second)
+		members = append(members, *member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].Completed != members[j].Completed {
+			return members[i].Completed > members[j].Completed
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members, nil
+}
+
+func (m memoryStats) Burndown(_ context.Context, scope models.Scope, projectID string, points []time.Time) ([]models.BurndownPoint, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	burndown := make([]models.BurndownPoint, len(points))
+	for _, t := range m.visible(scope) {
+		if t.ProjectID == nil || *t.ProjectID != projectID {
+			continue
+		}
+		for i, at := range points {
+			if t.CreatedAt.After(at) {
+				continue
+			}
+			burndown[i].Total++
+			if openAt(t, at) {
+				burndown[i].Remaining++
+			}
+		}
+	}
+	return burndown, nil
+}
+
+func (m memoryStats) Workload(_ context.Context, scope models.Scope, from, to time.Time) ([]models.MemberWorkload, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	byOwner := map[string]*models.MemberWorkload{}
+	for _, ms := range m.s.memberships {
+		if ms.OrgID == scope.OrgID {
+			byOwner[ms.UserID] = &models.MemberWorkload{UserID: ms.UserID}
+		}
+	}
+	now := time.Now()
+	counted := func(t models.Task) bool {
+		return t.CompletedAt == nil && t.ArchivedAt == nil && t.DueDate != nil && !t.DueDate.Before(from) && t.DueDate.Before(to)
+	}
+
+	// Tasks counted without an estimate of their own take their subtasks'
+	// total, and those subtasks aren't counted again.
+	children := m.s.subtasksByParent(scope)
+	rolled := map[string]bool{}
+	var rollInto func(id string, depth int)
+	rollInto = func(id string, depth int) {
+		for _, c := range children[id] {
+			rolled[c.ID] = true
+			if c.EstimateMinutes == nil && depth < 50 {
+				rollInto(c.ID, depth+1)
+			}
+		}
+	}
+	for _, t := range m.visible(scope) {
+		if counted(t) && t.EstimateMinutes == nil {
+			rollInto(t.ID, 1)
+		}
+	}
+
+	for _, t := range m.visible(scope) {
+		member, ok := byOwner[t.OwnerID]
+		if !ok || t.CompletedAt != nil || t.ArchivedAt != nil || t.DueDate == nil {
+			continue
+		}
+		if t.DueDate.Before(now) {
+			member.Overdue++
+		}
+		if !counted(t) {
+			continue
+		}
+		member.Open++
+		if rolled[t.ID] {
+			continue
+		}
+		estimate := t.EstimateMinutes
+		if estimate == nil {
+			estimate = models.RollUpEstimate(children, t.ID)
+		}
+		if estimate == nil {
+			member.Unestimated++
+		} else {
+			member.EstimateMinutes += int64(*estimate)
+		}
+	}
+
+	members := make([]models.MemberWorkload, 0, len(byOwner))
+	for _, member := range byOwner {
+		members = append(members, *member)
+	}
+	sort.Slice(members, func(i, j int) bool {
+		if members[i].EstimateMinutes != members[j].EstimateMinutes {
+			return members[i].EstimateMinutes > members[j].EstimateMinutes
+		}
+		if members[i].Open != members[j].Open {
+			return members[i].Open > members[j].Open
+		}
+		return members[i].UserID < members[j].UserID
+	})
+	return members, nil
+}
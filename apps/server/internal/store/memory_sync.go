@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+const syncDeletedField = "_deleted"
+
+var errSyncRejected = errors.New("task not found")
+
+type memorySyncEntry struct {
+	op      models.SyncOp
+	ownerID string
+	orgID   *string
+}
+
+type memorySync struct{ s *memoryStore }
+
+func (m memorySync) Push(_ context.Context, scope models.Scope, defaultStatus string, ops []models.SyncOp) ([]models.SyncResult, int64, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	// A conflict fails the whole push, as it rolls the Postgres one back.
+	for _, op := range ops {
+		if t, ok := m.s.tasks[op.TaskID]; ok && !inScope(scope, t.OwnerID, t.OrgID) {
+			return nil, 0, &SyncConflictError{OpID: op.ID, TaskID: op.TaskID}
+		}
+	}
+
+	results := make([]models.SyncResult, 0, len(ops))
+	for _, op := range ops {
+		status, err := m.apply(scope, defaultStatus, op)
+		if err != nil {
+			results = append(results, models.SyncResult{ID: op.ID, Status: models.SyncRejected, Error: err.Error()})
+			continue
+		}
+		results = append(results, models.SyncResult{ID: op.ID, Status: status})
+	}
+
+	var checkpoint int64
+	for _, e := range m.s.syncLog {
+		if inScope(scope, e.ownerID, e.orgID) {
+			checkpoint = e.op.Seq
+		}
+	}
+	return results, checkpoint, nil
+}
+
+// apply mirrors SyncRepository.applyOp, once Push has checked for
+// conflicts; callers hold the lock.
+func (m memorySync) apply(scope models.Scope, defaultStatus string, op models.SyncOp) (string, error) {
+	for _, e := range m.s.syncLog {
+		if e.ownerID == scope.UserID && e.op.ID == op.ID {
+			return models.SyncApplied, nil
+		}
+	}
+	if _, deleted := m.s.clocks[op.TaskID+"/"+syncDeletedField]; deleted {
+		return models.SyncSuperseded, nil
+	}
+
+	t, exists := m.s.tasks[op.TaskID]
+	if exists && !m.s.canAccess(scope, t, models.ShareRoleEditor) {
+		return "", errSyncRejected
+	}
+	if !exists && op.Type == models.SyncOpSet {
+		now := time.Now().UTC()
+		t = models.Task{
+			ID:         op.TaskID,
+			OwnerID:    scope.UserID,
+			OrgID:      scope.OrgIDPtr(),
+			Status:     defaultStatus,
+			Visibility: models.TaskVisibilityOrg,
+			Position:   m.s.nextPosition(scope),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+
+	field := op.Field
+	if op.Type == models.SyncOpDelete {
+		field = syncDeletedField
+	}
+	key := op.TaskID + "/" + field
+	if clock, ok := m.s.clocks[key]; ok && !op.Timestamp.After(clock) {
+		return models.SyncSuperseded, nil
+	}
+
+	if op.Type == models.SyncOpDelete {
+		if exists && t.DeletedAt == nil {
+			memoryTasks{m.s}.trash(op.TaskID)
+		}
+	} else {
+		status := t.Status
+		if err := setSyncField(&t, op.Field, op.Value); err != nil {
+			return "", err
+		}
+		now := time.Now().UTC()
+		if !exists || t.Status != status {
+			m.s.setCompletedAt(&t, now)
+		}
+		t.Version++
+		t.UpdatedAt = now
+		m.s.tasks[t.ID] = t
+		m.s.logTask(t)
+	}
+	m.s.clocks[key] = op.Timestamp
+
+	logged := op
+	logged.Seq = int64(len(m.s.syncLog) + 1)
+	if op.Type == models.SyncOpDelete {
+		logged.Value = nil
+	}
+	m.s.syncLog = append(m.s.syncLog, memorySyncEntry{op: logged, ownerID: scope.UserID, orgID: scope.OrgIDPtr()})
+	return models.SyncApplied, nil
+}
+
+func setSyncField(t *models.Task, field string, value json.RawMessage) error {
+	switch field {
+	case "title":
+		return json.Unmarshal(value, &t.Title)
+	case "description":
+		return json.Unmarshal(value, &t.Description)
+	case "status":
+		return json.Unmarshal(value, &t.Status)
+	case "priority":
+		return json.Unmarshal(value, &t.Priority)
+	case "dueDate":
+		t.DueDate = nil
+		return json.Unmarshal(value, &t.DueDate)
+	case "dueTimezone":
+		t.DueTimezone = nil
+		return json.Unmarshal(value, &t.DueTimezone)
+	case "projectId":
+		t.ProjectID = nil
+		return json.Unmarshal(value, &t.ProjectID)
+	}
+	return nil
+}
+
+func (m memorySync) Pull(_ context.Context, scope models.Scope, since int64, pageLimit int) ([]models.SyncOp, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	ops := []models.SyncOp{}
+	for _, e := range m.s.syncLog {
+		if e.op.Seq <= since || !inScope(scope, e.ownerID, e.orgID) {
+			continue
+		}
+		if t, ok := m.s.tasks[e.op.TaskID]; ok && !m.s.canAccess(scope, t, models.ShareRoleViewer) {
+			continue
+		}
+		ops = append(ops, e.op)
+	}
+	return limit(ops, pageLimit), nil
+}
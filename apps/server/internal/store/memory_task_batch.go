@@ -0,0 +1,58 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/ordering"
+)
+
+func (m memoryTasks) CreateBatch(_ context.Context, scope models.Scope, batch []models.NewTask) ([]models.Task, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	return m.s.insertTaskBatch(scope, batch), nil
+}
+
+// insertTaskBatch stores the batch; callers hold the lock.
+func (s *memoryStore) insertTaskBatch(scope models.Scope, batch []models.NewTask) []models.Task {
+	now := time.Now().UTC()
+	position := s.nextPosition(scope)
+	created := make([]models.Task, 0, len(batch))
+	for i, n := range batch {
+		input := n.Input
+		if n.Parent >= 0 {
+			input.ParentID = &created[n.Parent].ID
+		}
+		if i > 0 {
+			position, _ = ordering.Between(position, "")
+		}
+		owner := n.OwnerID
+		if owner == "" {
+			owner = scope.UserID
+		}
+		t := s.insertTask(scope, owner, input, position, now)
+		if scope.IsOrg() {
+			for _, labelID := range n.LabelIDs {
+				if l, ok := s.labels[labelID]; ok && l.OrgID == scope.OrgID {
+					if s.taskLabels[t.ID] == nil {
+						s.taskLabels[t.ID] = map[string]bool{}
+					}
+					s.taskLabels[t.ID][labelID] = true
+				}
+			}
+		}
+		created = append(created, t)
+	}
+
+	for i, n := range batch {
+		for _, j := range n.BlockedBy {
+			blocked := created[i].ID
+			if s.blockers[blocked] == nil {
+				s.blockers[blocked] = map[string]bool{}
+			}
+			s.blockers[blocked][created[j].ID] = true
+		}
+	}
+	return created
+}
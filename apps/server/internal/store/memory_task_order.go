@@ -0,0 +1,72 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/ordering"
+)
+
+// nextPosition is a position after every task in scope's list; callers hold
+// the lock.
+func (s *memoryStore) nextPosition(scope models.Scope) string {
+	last := ""
+	for _, t := range s.tasks {
+		if inScope(scope, t.OwnerID, t.OrgID) && t.Position > last {
+			last = t.Position
+		}
+	}
+	position, _ := ordering.Between(last, "")
+	return position
+}
+
+func (m memoryTasks) Reorder(_ context.Context, scope models.Scope, id string, input models.ReorderTaskInput) (*models.Task, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, ok := m.s.editableTask(scope, id)
+	if !ok {
+		return nil, m.s.writeMiss(scope, id)
+	}
+	neighbour := func(neighbourID *string) (string, bool) {
+		if neighbourID == nil {
+			return "", true
+		}
+		n, ok := m.s.liveTask(scope, *neighbourID)
+		return n.Position, ok
+	}
+	after, ok := neighbour(input.After)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	before, ok := neighbour(input.Before)
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	for _, other := range m.s.tasks {
+		if other.ID == id || !inScope(scope, other.OwnerID, other.OrgID) {
+			continue
+		}
+		switch {
+		case input.Before == nil && other.Position > after && (before == "" || other.Position < before):
+			before = other.Position
+		case input.After == nil && other.Position < before && other.Position > after:
+			after = other.Position
+		}
+	}
+	if after != "" && before != "" && after >= before {
+		return nil, ErrConflict
+	}
+	position, err := ordering.Between(after, before)
+	if err != nil {
+		return nil, err
+	}
+
+	t.Position = position
+	t.Version++
+	t.UpdatedAt = time.Now().UTC()
+	m.s.tasks[id] = t
+	m.s.logTask(t)
+	return &t, nil
+}
@@ -0,0 +1,102 @@
+package store
+
+import (
+	"context"
+	"encoding/hex"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryTelegramCode struct {
+	scope     models.Scope
+	expiresAt time.Time
+}
+
+type memoryTelegram struct{ s *memoryStore }
+
+func (m memoryTelegram) Get(_ context.Context, userID string) (*models.TelegramLink, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	l, ok := m.s.telegram[userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &l, nil
+}
+
+func (m memoryTelegram) CreateLinkCode(_ context.Context, scope models.Scope, codeHash []byte, expiresAt time.Time) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for key, code := range m.s.telegramCodes {
+		if code.scope.UserID == scope.UserID {
+			delete(m.s.telegramCodes, key)
+		}
+	}
+	m.s.telegramCodes[hex.EncodeToString(codeHash)] = memoryTelegramCode{scope: scope, expiresAt: expiresAt}
+	return nil
+}
+
+func (m memoryTelegram) Link(_ context.Context, codeHash []byte, chatID int64, username *string) (*models.TelegramLink, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key := hex.EncodeToString(codeHash)
+	code, ok := m.s.telegramCodes[key]
+	if !ok || !time.Now().Before(code.expiresAt) {
+		return nil, ErrNotFound
+	}
+	delete(m.s.telegramCodes, key)
+
+	for userID, l := range m.s.telegram {
+		if l.ChatID == chatID && userID != code.scope.UserID {
+			delete(m.s.telegram, userID)
+		}
+	}
+	l := models.TelegramLink{
+		UserID:    code.scope.UserID,
+		OrgID:     code.scope.OrgIDPtr(),
+		Username:  username,
+		CreatedAt: time.Now().UTC(),
+		ChatID:    chatID,
+	}
+	m.s.telegram[l.UserID] = l
+	return &l, nil
+}
+
+func (m memoryTelegram) Delete(_ context.Context, userID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.telegram[userID]; !ok {
+		return ErrNotFound
+	}
+	delete(m.s.telegram, userID)
+	return nil
+}
+
+func (m memoryTelegram) GetByChat(_ context.Context, chatID int64) (*models.TelegramLink, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	for _, l := range m.s.telegram {
+		if l.ChatID == chatID {
+			return &l, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryTelegram) DeleteByChat(_ context.Context, chatID int64) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	for userID, l := range m.s.telegram {
+		if l.ChatID == chatID {
+			delete(m.s.telegram, userID)
+			return nil
+		}
+	}
+	return ErrNotFound
+}
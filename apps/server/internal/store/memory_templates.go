@@ -0,0 +1,100 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryTemplates struct{ s *memoryStore }
+
+func (m memoryTemplates) Create(_ context.Context, scope models.Scope, name string, task models.TemplateTask) (*models.TaskTemplate, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	t := models.TaskTemplate{
+		ID:        newID(),
+		OwnerID:   scope.UserID,
+		OrgID:     scope.OrgIDPtr(),
+		Name:      name,
+		Task:      task,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.templates[t.ID] = t
+	return &t, nil
+}
+
+func (m memoryTemplates) Get(_ context.Context, scope models.Scope, id string) (*models.TaskTemplate, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	t, ok := m.s.templates[id]
+	if !ok || !inScope(scope, t.OwnerID, t.OrgID) {
+		return nil, ErrNotFound
+	}
+	return &t, nil
+}
+
+func (m memoryTemplates) List(_ context.Context, scope models.Scope) ([]models.TaskTemplate, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	templates := []models.TaskTemplate{}
+	for _, t := range m.s.templates {
+		if inScope(scope, t.OwnerID, t.OrgID) {
+			templates = append(templates, t)
+		}
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		if templates[i].Name != templates[j].Name {
+			return templates[i].Name < templates[j].Name
+		}
+		return templates[i].ID < templates[j].ID
+	})
+	return templates, nil
+}
+
+// own looks up a template the scope may change; callers hold the lock.
+func (m memoryTemplates) own(scope models.Scope, id string) (models.TaskTemplate, error) {
+	t, ok := m.s.templates[id]
+	switch {
+	case !ok || !inScope(scope, t.OwnerID, t.OrgID):
+		return t, ErrNotFound
+	case t.OwnerID != scope.UserID:
+		return t, ErrReadOnly
+	}
+	return t, nil
+}
+
+func (m memoryTemplates) Update(_ context.Context, scope models.Scope, id string, input models.UpdateTemplateInput) (*models.TaskTemplate, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, err := m.own(scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		t.Name = *input.Name
+	}
+	if input.Task != nil {
+		t.Task = *input.Task
+	}
+	t.UpdatedAt = time.Now().UTC()
+	m.s.templates[id] = t
+	return &t, nil
+}
+
+func (m memoryTemplates) Delete(_ context.Context, scope models.Scope, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, err := m.own(scope, id); err != nil {
+		return err
+	}
+	delete(m.s.templates, id)
+	return nil
+}
@@ -0,0 +1,289 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryTimeEntries struct{ s *memoryStore }
+
+// entry looks up an entry on a live task in scope; callers hold the lock.
+func (m memoryTimeEntries) entry(scope models.Scope, taskID, id string) (models.TimeEntry, bool) {
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return models.TimeEntry{}, false
+	}
+	e, ok := m.s.timeEntries[id]
+	return e, ok && e.TaskID == taskID
+}
+
+// entryAt returns a copy of e with its Seconds as of now.
+func entryAt(e models.TimeEntry, now time.Time) *models.TimeEntry {
+	e.SetSeconds(now)
+	return &e
+}
+
+func (m memoryTimeEntries) Start(_ context.Context, scope models.Scope, taskID string, input models.StartTimerInput) (*models.TimeEntry, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return nil, ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	for id, e := range m.s.timeEntries {
+		if e.UserID == scope.UserID && e.EndedAt == nil {
+			e.EndedAt, e.UpdatedAt = &now, now
+			m.s.timeEntries[id] = e
+		}
+	}
+	e := models.TimeEntry{
+		ID:        newID(),
+		TaskID:    taskID,
+		UserID:    scope.UserID,
+		StartedAt: now,
+		Note:      input.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.timeEntries[e.ID] = e
+	return entryAt(e, now), nil
+}
+
+func (m memoryTimeEntries) Stop(_ context.Context, scope models.Scope, taskID string) (*models.TimeEntry, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return nil, ErrNotFound
+	}
+	now := time.Now().UTC()
+	for id, e := range m.s.timeEntries {
+		if e.TaskID == taskID && e.UserID == scope.UserID && e.EndedAt == nil {
+			e.EndedAt, e.UpdatedAt = &now, now
+			m.s.timeEntries[id] = e
+			return entryAt(e, now), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryTimeEntries) Running(_ context.Context, userID string) (*models.TimeEntry, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	for _, e := range m.s.timeEntries {
+		if e.UserID != userID || e.EndedAt != nil {
+			continue
+		}
+		if t, ok := m.s.tasks[e.TaskID]; ok && t.DeletedAt == nil {
+			return entryAt(e, time.Now()), nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+func (m memoryTimeEntries) Create(_ context.Context, scope models.Scope, taskID string, input models.CreateTimeEntryInput) (*models.TimeEntry, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return nil, ErrNotFound
+	}
+
+	now := time.Now().UTC()
+	ended := input.EndedAt
+	e := models.TimeEntry{
+		ID:        newID(),
+		TaskID:    taskID,
+		UserID:    scope.UserID,
+		StartedAt: input.StartedAt,
+		EndedAt:   &ended,
+		Note:      input.Note,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.timeEntries[e.ID] = e
+	return entryAt(e, now), nil
+}
+
+func (m memoryTimeEntries) Get(_ context.Context, scope models.Scope, taskID, id string) (*models.TimeEntry, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	e, ok := m.entry(scope, taskID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return entryAt(e, time.Now()), nil
+}
+
+func (m memoryTimeEntries) Update(_ context.Context, scope models.Scope, taskID, id string, input models.UpdateTimeEntryInput) (*models.TimeEntry, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	e, ok := m.entry(scope, taskID, id)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	if input.StartedAt != nil {
+		e.StartedAt = *input.StartedAt
+	}
+	if input.EndedAt != nil {
+		ended := *input.EndedAt
+		e.EndedAt = &ended
+	}
+	if input.Note != nil {
+		e.Note = *input.Note
+	}
+	now := time.Now().UTC()
+	e.UpdatedAt = now
+	m.s.timeEntries[id] = e
+	return entryAt(e, now), nil
+}
+
+func (m memoryTimeEntries) Delete(_ context.Context, scope models.Scope, taskID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.entry(scope, taskID, id); !ok {
+		return ErrNotFound
+	}
+	delete(m.s.timeEntries, id)
+	return nil
+}
+
+// earlierEntry reports whether a sorts before (t, id) in oldest-first order.
+func earlierEntry(a models.TimeEntry, t time.Time, id string) bool {
+	if !a.StartedAt.Equal(t) {
+		return a.StartedAt.Before(t)
+	}
+	return a.ID < id
+}
+
+func (m memoryTimeEntries) List(_ context.Context, scope models.Scope, taskID string, page models.Page) ([]models.TimeEntry, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.TimeEntry{}
+	if _, ok := m.s.liveTask(scope, taskID); !ok {
+		return list, nil
+	}
+	now := time.Now()
+	for _, e := range m.s.timeEntries {
+		if e.TaskID == taskID {
+			list = append(list, *entryAt(e, now))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return earlierEntry(list[j], list[i].StartedAt, list[i].ID) })
+
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		idx := sort.Search(len(list), func(i int) bool { return earlierEntry(list[i], t, page.After[1]) })
+		list = list[idx:]
+	}
+
+	return limit(list, page.Limit), nil
+}
+
+// totals sums the entries on the tasks by user, and by task if byTask.
+func (m memoryTimeEntries) totals(tasks map[string]models.Task, byTask bool) *models.TimeTotals {
+	users, perTask := map[string]int64{}, map[string]int64{}
+	totals := &models.TimeTotals{ByUser: []models.TimeTotal{}}
+	now := time.Now()
+	for _, e := range m.s.timeEntries {
+		if _, ok := tasks[e.TaskID]; !ok {
+			continue
+		}
+		seconds := entryAt(e, now).Seconds
+		users[e.UserID] += seconds
+		perTask[e.TaskID] += seconds
+		totals.Seconds += seconds
+	}
+
+	sorted := func(sums map[string]int64) []models.TimeTotal {
+		list := make([]models.TimeTotal, 0, len(sums))
+		for id, seconds := range sums {
+			list = append(list, models.TimeTotal{ID: id, Seconds: seconds})
+		}
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Seconds != list[j].Seconds {
+				return list[i].Seconds > list[j].Seconds
+			}
+			return list[i].ID < list[j].ID
+		})
+		return list
+	}
+	totals.ByUser = sorted(users)
+	if byTask {
+		totals.ByTask = sorted(perTask)
+		for i := range totals.ByTask {
+			totals.ByTask[i].Title = tasks[totals.ByTask[i].ID].Title
+		}
+	}
+	return totals
+}
+
+func (m memoryTimeEntries) TaskTotals(_ context.Context, scope models.Scope, taskID string) (*models.TimeTotals, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	t, ok := m.s.liveTask(scope, taskID)
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return m.totals(map[string]models.Task{t.ID: t}, false), nil
+}
+
+func (m memoryTimeEntries) ProjectTotals(_ context.Context, scope models.Scope, projectID string) (*models.TimeTotals, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	tasks := map[string]models.Task{}
+	for _, t := range m.s.tasks {
+		if t.ProjectID != nil && *t.ProjectID == projectID && t.DeletedAt == nil && m.s.canAccess(scope, t, models.ShareRoleViewer) {
+			tasks[t.ID] = t
+		}
+	}
+	return m.totals(tasks, true), nil
+}
+
+func (m memoryTimeEntries) Export(_ context.Context, scope models.Scope, filter models.TimeEntryFilter, page models.Page) ([]models.ExportedTimeEntry, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.ExportedTimeEntry{}
+	now := time.Now()
+	for _, e := range m.s.timeEntries {
+		t, ok := m.s.tasks[e.TaskID]
+		switch {
+		case !ok || !m.s.canAccess(scope, t, models.ShareRoleViewer):
+			continue
+		case e.EndedAt == nil || e.StartedAt.Before(filter.From) || !e.StartedAt.Before(filter.To):
+			continue
+		case filter.ProjectID != nil && (t.ProjectID == nil || *t.ProjectID != *filter.ProjectID):
+			continue
+		case filter.UserID != nil && e.UserID != *filter.UserID:
+			continue
+		}
+		list = append(list, models.ExportedTimeEntry{TimeEntry: *entryAt(e, now), TaskTitle: t.Title, ProjectID: t.ProjectID})
+	}
+	sort.Slice(list, func(i, j int) bool { return earlierEntry(list[i].TimeEntry, list[j].StartedAt, list[j].ID) })
+
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		after := models.TimeEntry{StartedAt: t, ID: page.After[1]}
+		idx := sort.Search(len(list), func(i int) bool { return earlierEntry(after, list[i].StartedAt, list[i].ID) })
+		list = list[idx:]
+	}
+
+	return limit(list, page.Limit), nil
+}
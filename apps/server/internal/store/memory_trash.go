@@ -0,0 +1,222 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryTrash struct{ s *memoryStore }
+
+// trashedWith reports whether the task went to the trash together with its
+// parent or project; callers hold the lock.
+func (m memoryTrash) trashedWith(t models.Task) bool {
+	if t.ParentID != nil {
+		if p, ok := m.s.tasks[*t.ParentID]; ok && p.DeletedAt != nil && p.DeletedAt.Equal(*t.DeletedAt) {
+			return true
+		}
+	}
+	if t.ProjectID != nil {
+		if p, ok := m.s.projects[*t.ProjectID]; ok && p.DeletedAt != nil && p.DeletedAt.Equal(*t.DeletedAt) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m memoryTrash) ListTasks(_ context.Context, scope models.Scope) ([]models.Task, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	tasks := []models.Task{}
+	for _, t := range m.s.tasks {
+		if t.DeletedAt != nil && m.s.canAccess(scope, t, models.ShareRoleViewer) && !m.trashedWith(t) {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks, nil
+}
+
+func (m memoryTrash) ListProjects(_ context.Context, orgID string) ([]models.Project, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	projects := []models.Project{}
+	for _, p := range m.s.projects {
+		if p.OrgID == orgID && p.DeletedAt != nil {
+			projects = append(projects, p)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool {
+		if !projects[i].DeletedAt.Equal(*projects[j].DeletedAt) {
+			return projects[i].DeletedAt.After(*projects[j].DeletedAt)
+		}
+		return projects[i].ID < projects[j].ID
+	})
+	return projects, nil
+}
+
+// restore clears DeletedAt on the task and every descendant trashed at the
+// same time; callers hold the lock.
+func (m memoryTrash) restore(id string, deletedAt time.Time) {
+	now := time.Now().UTC()
+	queue := []string{id}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		t := m.s.tasks[cur]
+		t.DeletedAt = nil
+		t.Version++
+		t.UpdatedAt = now
+		m.s.tasks[cur] = t
+		m.s.logTask(t)
+
+		for _, c := range m.s.tasks {
+			if c.ParentID != nil && *c.ParentID == cur && c.DeletedAt != nil && c.DeletedAt.Equal(deletedAt) {
+				queue = append(queue, c.ID)
+			}
+		}
+	}
+}
+
+func (m memoryTrash) RestoreTask(_ context.Context, scope models.Scope, id string) (*models.Task, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	t, ok := m.s.tasks[id]
+	if !ok || t.DeletedAt == nil || !m.s.canAccess(scope, t, models.ShareRoleEditor) {
+		return nil, ErrNotFound
+	}
+	if t.ParentID != nil && m.s.tasks[*t.ParentID].DeletedAt != nil {
+		return nil, ErrConflict
+	}
+	if t.ProjectID != nil && m.s.projects[*t.ProjectID].DeletedAt != nil {
+		return nil, ErrConflict
+	}
+
+	m.restore(id, *t.DeletedAt)
+	t = m.s.tasks[id]
+	return &t, nil
+}
+
+func (m memoryTrash) DeleteProject(_ context.Context, orgID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	p, ok := m.s.projects[id]
+	if !ok || p.OrgID != orgID || p.DeletedAt != nil {
+		return ErrNotFound
+	}
+	now := time.Now().UTC()
+	p.DeletedAt = &now
+	p.UpdatedAt = now
+	m.s.projects[id] = p
+	m.s.logProject(p)
+
+	tasks := memoryTasks{m.s}
+	for _, t := range m.s.tasks {
+		if t.ProjectID == nil || *t.ProjectID != id || t.DeletedAt != nil {
+			continue
+		}
+		for _, d := range tasks.descendants(t.ID) {
+			d.DeletedAt = &now
+			d.UpdatedAt = now
+			m.s.tasks[d.ID] = d
+			m.s.logTask(d)
+		}
+		t.DeletedAt = &now
+		t.Version++
+		t.UpdatedAt = now
+		m.s.tasks[t.ID] = t
+		m.s.logTask(t)
+	}
+	return nil
+}
+
+func (m memoryTrash) RestoreProject(_ context.Context, orgID, id string) (*models.Project, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	p, ok := m.s.projects[id]
+	if !ok || p.OrgID != orgID || p.DeletedAt == nil {
+		return nil, ErrNotFound
+	}
+	for _, t := range m.s.tasks {
+		if t.ProjectID != nil && *t.ProjectID == id && t.DeletedAt != nil && t.DeletedAt.Equal(*p.DeletedAt) {
+			m.restore(t.ID, *p.DeletedAt)
+		}
+	}
+
+	p.DeletedAt = nil
+	p.UpdatedAt = time.Now().UTC()
+	m.s.projects[id] = p
+	m.s.logProject(p)
+	return &p, nil
+}
+
+func (m memoryTrash) Purge(_ context.Context, cutoff time.Time) (int, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	// cutoffFor honours an org's own retention, like the repository does.
+	now := time.Now()
+	cutoffFor := func(orgID *string) time.Time {
+		if orgID != nil {
+			if s, ok := m.s.orgConfig[*orgID]; ok && s.TrashRetentionDays != nil {
+				return now.AddDate(0, 0, -*s.TrashRetentionDays)
+			}
+		}
+		return cutoff
+	}
+
+	purged := 0
+	tasks := memoryTasks{m.s}
+	for id, t := range m.s.tasks {
+		if t.DeletedAt != nil && t.DeletedAt.Before(cutoffFor(t.OrgID)) {
+			tasks.deleteTask(id)
+			purged++
+		}
+	}
+	for id, p := range m.s.projects {
+		if p.DeletedAt != nil && p.DeletedAt.Before(cutoffFor(&p.OrgID)) {
+			delete(m.s.projects, id)
+			m.s.logProject(p)
+			for key, share := range m.s.projectShares {
+				if *share.ProjectID == id {
+					delete(m.s.projectShares, key)
+				}
+			}
+			for lid, l := range m.s.shareLinks {
+				if l.ProjectID != nil && *l.ProjectID == id {
+					m.s.deleteShareLink(lid)
+				}
+			}
+			for bid, b := range m.s.boards {
+				if b.ProjectID == id {
+					m.s.deleteBoard(bid)
+				}
+			}
+			purged++
+		}
+	}
+	// Like the foreign key, tasks outliving their project lose the link.
+	for id, t := range m.s.tasks {
+		if t.ProjectID == nil {
+			continue
+		}
+		if _, ok := m.s.projects[*t.ProjectID]; !ok {
+			t.ProjectID = nil
+			m.s.tasks[id] = t
+			m.s.logTask(t)
+		}
+	}
+	return purged, nil
+}
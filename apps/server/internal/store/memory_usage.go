@@ -0,0 +1,32 @@
+package store
+
+import (
+	"context"
+	"yata/apps/server/internal/models"
+)
+
+// memoryUsage counts on demand instead of keeping counters.
+type memoryUsage struct{ s *memoryStore }
+
+func (m memoryUsage) Get(_ context.Context, orgID string) (*models.Usage, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	var u models.Usage
+	for _, t := range m.s.tasks {
+		if t.OrgID != nil && *t.OrgID == orgID && t.DeletedAt == nil {
+			u.Tasks++
+		}
+	}
+	for _, a := range m.s.attachments {
+		if t, ok := m.s.tasks[a.TaskID]; ok && t.OrgID != nil && *t.OrgID == orgID {
+			u.AttachmentBytes += a.Size
+		}
+	}
+	for _, w := range m.s.webhooks {
+		if w.OrgID == orgID {
+			u.WebhookEndpoints++
+		}
+	}
+	return &u, nil
+}
@@ -0,0 +1,200 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryUsers struct{ s *memoryStore }
+
+func (m memoryUsers) UpsertUser(_ context.Context, user models.User) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if existing, ok := m.s.users[user.ID]; ok && (existing.DeletedAt != nil || existing.UpdatedAt.After(user.UpdatedAt)) {
+		return nil
+	}
+	user.DeletedAt = nil
+	m.s.users[user.ID] = user
+	return nil
+}
+
+func (m memoryUsers) DeleteUser(_ context.Context, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	user, ok := m.s.users[id]
+	if !ok {
+		user = models.User{ID: id, UpdatedAt: now}
+	}
+	if user.DeletedAt == nil {
+		user.DeletedAt = &now
+	}
+	m.s.users[id] = user
+	for key, ms := range m.s.memberships {
+		if ms.UserID == id {
+			delete(m.s.memberships, key)
+		}
+	}
+	return nil
+}
+
+func (m memoryUsers) UpsertOrganization(_ context.Context, org models.Organization) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	existing, ok := m.s.orgs[org.ID]
+	if ok && (existing.DeletedAt != nil || existing.UpdatedAt.After(org.UpdatedAt)) {
+		return nil
+	}
+	if org.Status == "" {
+		org.Status = existing.Status
+	}
+	if org.Status == "" {
+		org.Status = models.OrgStatusActive
+	}
+	org.DeletedAt = nil
+	m.s.orgs[org.ID] = org
+	return nil
+}
+
+func (m memoryUsers) SetOrganizationStatus(_ context.Context, id, status string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	org, ok := m.s.orgs[id]
+	if !ok || org.DeletedAt != nil {
+		return ErrNotFound
+	}
+	org.Status = status
+	m.s.orgs[id] = org
+	return nil
+}
+
+func (m memoryUsers) GetOrganizationStatus(_ context.Context, id string) (string, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	if org, ok := m.s.orgs[id]; ok && org.Status != "" {
+		return org.Status, nil
+	}
+	return models.OrgStatusActive, nil
+}
+
+func (m memoryUsers) DeleteOrganization(_ context.Context, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	org, ok := m.s.orgs[id]
+	if !ok {
+		org = models.Organization{ID: id, UpdatedAt: now}
+	}
+	if org.DeletedAt == nil {
+		org.DeletedAt = &now
+	}
+	m.s.orgs[id] = org
+	for key, ms := range m.s.memberships {
+		if ms.OrgID == id {
+			delete(m.s.memberships, key)
+		}
+	}
+	return nil
+}
+
+func (m memoryUsers) UpsertMembership(_ context.Context, membership models.OrgMembership) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if org, ok := m.s.orgs[membership.OrgID]; ok && org.DeletedAt != nil {
+		return nil
+	}
+	if user, ok := m.s.users[membership.UserID]; ok && user.DeletedAt != nil {
+		return nil
+	}
+	key := membership.OrgID + "/" + membership.UserID
+	if existing, ok := m.s.memberships[key]; ok && existing.UpdatedAt.After(membership.UpdatedAt) {
+		return nil
+	}
+	m.s.memberships[key] = membership
+	return nil
+}
+
+func (m memoryUsers) DeleteMembership(_ context.Context, orgID, userID string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	delete(m.s.memberships, orgID+"/"+userID)
+	return nil
+}
+
+func (m memoryUsers) GetUser(_ context.Context, id string) (*models.User, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	user, ok := m.s.users[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &user, nil
+}
+
+// member joins a membership with its user, if we have one; callers hold the
+// lock.
+func (m memoryUsers) member(ms models.OrgMembership) models.OrgMember {
+	user, ok := m.s.users[ms.UserID]
+	if !ok {
+		user = models.User{ID: ms.UserID, UpdatedAt: ms.UpdatedAt}
+	}
+	return models.OrgMember{User: user, Role: ms.Role}
+}
+
+func (m memoryUsers) GetMember(_ context.Context, orgID, userID string) (*models.OrgMember, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	ms, ok := m.s.memberships[orgID+"/"+userID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	member := m.member(ms)
+	return &member, nil
+}
+
+func (m memoryUsers) FindMemberByEmail(_ context.Context, orgID, email string) (*models.OrgMember, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	var found *models.OrgMember
+	for _, ms := range m.s.memberships {
+		user, ok := m.s.users[ms.UserID]
+		if ms.OrgID != orgID || !ok || user.DeletedAt != nil || user.Email == nil || !strings.EqualFold(*user.Email, email) {
+			continue
+		}
+		if member := m.member(ms); found == nil || member.ID < found.ID {
+			found = &member
+		}
+	}
+	if found == nil {
+		return nil, ErrNotFound
+	}
+	return found, nil
+}
+
+func (m memoryUsers) ListMembers(_ context.Context, orgID string, page models.Page) ([]models.OrgMember, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.OrgMember{}
+	for _, ms := range m.s.memberships {
+		if ms.OrgID == orgID && (len(page.After) != 1 || ms.UserID > page.After[0]) {
+			list = append(list, m.member(ms))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return limit(list, page.Limit), nil
+}
@@ -0,0 +1,114 @@
+package store
+
+import (
+	"context"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryViews struct{ s *memoryStore }
+
+// visible reports whether the scope owns v or, in an org, v is shared with
+// it.
+func (m memoryViews) visible(scope models.Scope, v models.View) bool {
+	return inScope(scope, v.OwnerID, v.OrgID) && (!scope.IsOrg() || v.OwnerID == scope.UserID || v.Shared)
+}
+
+func (m memoryViews) Create(_ context.Context, scope models.Scope, input models.CreateViewInput) (*models.View, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now().UTC()
+	v := models.View{
+		ID:        newID(),
+		OwnerID:   scope.UserID,
+		OrgID:     scope.OrgIDPtr(),
+		Name:      input.Name,
+		Query:     input.Query,
+		Sort:      input.Sort,
+		Shared:    input.Shared,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	m.s.views[v.ID] = v
+	return &v, nil
+}
+
+func (m memoryViews) Get(_ context.Context, scope models.Scope, id string) (*models.View, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	v, ok := m.s.views[id]
+	if !ok || !m.visible(scope, v) {
+		return nil, ErrNotFound
+	}
+	return &v, nil
+}
+
+func (m memoryViews) List(_ context.Context, scope models.Scope) ([]models.View, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	views := []models.View{}
+	for _, v := range m.s.views {
+		if m.visible(scope, v) {
+			views = append(views, v)
+		}
+	}
+	sort.Slice(views, func(i, j int) bool {
+		if views[i].Name != views[j].Name {
+			return views[i].Name < views[j].Name
+		}
+		return views[i].ID < views[j].ID
+	})
+	return views, nil
+}
+
+// own looks up a view the scope may change; callers hold the lock.
+func (m memoryViews) own(scope models.Scope, id string) (models.View, error) {
+	v, ok := m.s.views[id]
+	switch {
+	case !ok || !m.visible(scope, v):
+		return v, ErrNotFound
+	case v.OwnerID != scope.UserID:
+		return v, ErrReadOnly
+	}
+	return v, nil
+}
+
+func (m memoryViews) Update(_ context.Context, scope models.Scope, id string, input models.UpdateViewInput) (*models.View, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	v, err := m.own(scope, id)
+	if err != nil {
+		return nil, err
+	}
+	if input.Name != nil {
+		v.Name = *input.Name
+	}
+	if input.Query != nil {
+		v.Query = *input.Query
+	}
+	if input.Sort != nil {
+		v.Sort = *input.Sort
+	}
+	if input.Shared != nil {
+		v.Shared = *input.Shared
+	}
+	v.UpdatedAt = time.Now().UTC()
+	m.s.views[id] = v
+	return &v, nil
+}
+
+func (m memoryViews) Delete(_ context.Context, scope models.Scope, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, err := m.own(scope, id); err != nil {
+		return err
+	}
+	delete(m.s.views, id)
+	return nil
+}
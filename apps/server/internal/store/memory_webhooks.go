@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"time"
+	"yata/apps/server/internal/models"
+)
+
+type memoryWebhooks struct{ s *memoryStore }
+
+// public hides the endpoint's secret.
+func (m memoryWebhooks) public(e models.WebhookEndpoint) *models.WebhookEndpoint {
+	e.Events = slices.Clone(e.Events)
+	e.Secret = ""
+	return &e
+}
+
+func (m memoryWebhooks) Create(_ context.Context, orgID, userID string, input models.CreateWebhookInput) (*models.WebhookEndpoint, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	now := time.Now()
+	e := models.WebhookEndpoint{
+		ID:        newID(),
+		OrgID:     orgID,
+		URL:       input.URL,
+		Events:    slices.Clone(input.Events),
+		Active:    true,
+		CreatedBy: userID,
+		CreatedAt: now,
+		UpdatedAt: now,
+		Secret:    input.Secret,
+	}
+	m.s.webhooks[e.ID] = e
+	return &e, nil
+}
+
+func (m memoryWebhooks) List(_ context.Context, orgID string) ([]models.WebhookEndpoint, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.WebhookEndpoint{}
+	for _, e := range m.s.webhooks {
+		if e.OrgID == orgID {
+			list = append(list, *m.public(e))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if !list[i].CreatedAt.Equal(list[j].CreatedAt) {
+			return list[i].CreatedAt.Before(list[j].CreatedAt)
+		}
+		return list[i].ID < list[j].ID
+	})
+	return list, nil
+}
+
+func (m memoryWebhooks) Get(_ context.Context, orgID, id string) (*models.WebhookEndpoint, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	e, ok := m.s.webhooks[id]
+	if !ok || e.OrgID != orgID {
+		return nil, ErrNotFound
+	}
+	return m.public(e), nil
+}
+
+func (m memoryWebhooks) Update(_ context.Context, orgID, id string, input models.UpdateWebhookInput) (*models.WebhookEndpoint, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	e, ok := m.s.webhooks[id]
+	if !ok || e.OrgID != orgID {
+		return nil, ErrNotFound
+	}
+	if input.URL == nil && input.Events == nil && input.Active == nil {
+		return m.public(e), nil
+	}
+
+	if input.URL != nil {
+		e.URL = *input.URL
+	}
+	if input.Events != nil {
+		e.Events = slices.Clone(*input.Events)
+	}
+	if input.Active != nil {
+		e.Active = *input.Active
+	}
+	e.UpdatedAt = time.Now()
+	m.s.webhooks[id] = e
+	return m.public(e), nil
+}
+
+func (m memoryWebhooks) Delete(_ context.Context, orgID, id string) error {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	e, ok := m.s.webhooks[id]
+	if !ok || e.OrgID != orgID {
+		return ErrNotFound
+	}
+	delete(m.s.webhooks, id)
+	delete(m.s.webhookDeliveries, id)
+	return nil
+}
+
+func (m memoryWebhooks) Subscribed(_ context.Context, orgID, event string) ([]models.WebhookEndpoint, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	list := []models.WebhookEndpoint{}
+	for _, e := range m.s.webhooks {
+		if e.OrgID == orgID && e.Subscribed(event) {
+			list = append(list, *m.public(e))
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].ID < list[j].ID })
+	return list, nil
+}
+
+func (m memoryWebhooks) Target(_ context.Context, id string) (*models.WebhookEndpoint, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	e, ok := m.s.webhooks[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	e.Events = slices.Clone(e.Events)
+	return &e, nil
+}
+
+func (m memoryWebhooks) RecordDelivery(_ context.Context, d models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	if _, ok := m.s.webhooks[d.EndpointID]; !ok {
+		return nil, ErrNotFound
+	}
+	d.ID = newID()
+	d.CreatedAt = time.Now()
+	d.Attempt = 1
+	for _, earlier := range m.s.webhookDeliveries[d.EndpointID] {
+		if earlier.EventID == d.EventID {
+			d.Attempt++
+		}
+	}
+	m.s.webhookDeliveries[d.EndpointID] = append(m.s.webhookDeliveries[d.EndpointID], d)
+	return &d, nil
+}
+
+func (m memoryWebhooks) Deliveries(_ context.Context, orgID, endpointID string, page models.Page) ([]models.WebhookDelivery, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	e, ok := m.s.webhooks[endpointID]
+	if !ok || e.OrgID != orgID {
+		return nil, ErrNotFound
+	}
+
+	list := slices.Clone(m.s.webhookDeliveries[endpointID])
+	// older reports whether d sorts after (t, id) in newest-first order.
+	older := func(d models.WebhookDelivery, t time.Time, id string) bool {
+		if !d.CreatedAt.Equal(t) {
+			return d.CreatedAt.Before(t)
+		}
+		return d.ID < id
+	}
+	sort.Slice(list, func(i, j int) bool { return older(list[j], list[i].CreatedAt, list[i].ID) })
+
+	if len(page.After) == 2 {
+		t, err := time.Parse(time.RFC3339Nano, page.After[0])
+		if err != nil {
+			return nil, err
+		}
+		idx := sort.Search(len(list), func(i int) bool { return older(list[i], t, page.After[1]) })
+		list = list[idx:]
+	}
+
+	if list == nil {
+		list = []models.WebhookDelivery{}
+	}
+	return limit(list, page.Limit), nil
+}
+
+func (m memoryWebhooks) Breaker(_ context.Context, orgID, url string) (*models.WebhookBreaker, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+
+	b, ok := m.s.webhookBreakers[[2]string{orgID, url}]
+	if !ok {
+		b = models.WebhookBreaker{URL: url}
+	}
+	b.SetState(time.Now())
+	return &b, nil
+}
+
+func (m memoryWebhooks) ClaimProbe(_ context.Context, orgID, url string, lease time.Duration) (bool, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key := [2]string{orgID, url}
+	b, ok := m.s.webhookBreakers[key]
+	now := time.Now()
+	if !ok || b.OpenUntil == nil || b.OpenUntil.After(now) {
+		return false, nil
+	}
+	until := now.Add(lease)
+	b.OpenUntil = &until
+	b.Probing = true
+	m.s.webhookBreakers[key] = b
+	return true, nil
+}
+
+func (m memoryWebhooks) RecordBreakerResult(_ context.Context, orgID, url string, succeeded bool, policy models.WebhookBreakerPolicy) (*models.WebhookBreaker, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	key := [2]string{orgID, url}
+	if succeeded {
+		delete(m.s.webhookBreakers, key)
+		return &models.WebhookBreaker{URL: url, State: models.BreakerClosed}, nil
+	}
+	b, ok := m.s.webhookBreakers[key]
+	if !ok {
+		b = models.WebhookBreaker{URL: url}
+	}
+	b.Fail(time.Now(), policy)
+	m.s.webhookBreakers[key] = b
+	return &b, nil
+}
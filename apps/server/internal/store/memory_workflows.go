@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"yata/apps/server/internal/models"
+)
+
+type memoryWorkflows struct{ s *memoryStore }
+
+func (m memoryWorkflows) Get(_ context.Context, orgID string) (models.Workflow, error) {
+	m.s.mu.RLock()
+	defer m.s.mu.RUnlock()
+	return m.get(orgID), nil
+}
+
+// get returns the configured workflow or the defaults; callers hold the lock.
+func (m memoryWorkflows) get(orgID string) models.Workflow {
+	if w, ok := m.s.workflows[orgID]; ok {
+		return w
+	}
+	return models.DefaultWorkflow()
+}
+
+func (m memoryWorkflows) SetStatuses(_ context.Context, orgID string, statuses []models.StatusDefinition) (models.Workflow, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	w := m.get(orgID)
+	w.Statuses = statuses
+	for _, t := range m.s.tasks {
+		if t.OrgID != nil && *t.OrgID == orgID && !w.IsValidStatus(t.Status) {
+			return models.Workflow{}, ErrConflict
+		}
+	}
+	m.s.workflows[orgID] = w
+	return w, nil
+}
+
+func (m memoryWorkflows) SetPriorities(_ context.Context, orgID string, priorities []models.PriorityLevel) (models.Workflow, error) {
+	m.s.mu.Lock()
+	defer m.s.mu.Unlock()
+
+	w := m.get(orgID)
+	w.Priorities = priorities
+	for _, t := range m.s.tasks {
+		if t.OrgID != nil && *t.OrgID == orgID && !w.IsValidPriority(t.Priority) {
+			return models.Workflow{}, ErrConflict
+		}
+	}
+	m.s.workflows[orgID] = w
+	return w, nil
+}
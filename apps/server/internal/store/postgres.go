@@ -0,0 +1,154 @@
+package store
+
+import (
+	"yata/apps/server/internal/crypto"
+	"yata/apps/server/internal/repository"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postgresStore struct {
+	tasks         *repository.TaskRepository
+	projects      *repository.ProjectRepository
+	labels        *repository.LabelRepository
+	workflows     *repository.WorkflowRepository
+	reminders     *repository.ReminderRepository
+	emailPrefs    *repository.EmailPreferenceRepository
+	pushSubs      *repository.PushSubscriptionRepository
+	apiTokens     *repository.APITokenRepository
+	imports       *repository.ImportRepository
+	accounts      *repository.AccountRepository
+	calendarFeeds *repository.CalendarFeedRepository
+	inbound       *repository.InboundAddressRepository
+	gcal          *repository.GoogleCalendarRepository
+	slack         *repository.SlackRepository
+	webhooks      *repository.WebhookRepository
+	telegram      *repository.TelegramRepository
+
+	notifications    *repository.NotificationRepository
+	sync             *repository.SyncRepository
+	changes          *repository.ChangeRepository
+	usage            *repository.UsageRepository
+	idempotency      *repository.IdempotencyRepository
+	trash            *repository.TrashRepository
+	comments         *repository.CommentRepository
+	attachments      *repository.AttachmentRepository
+	timeEntries      *repository.TimeEntryRepository
+	pomodoros        *repository.PomodoroRepository
+	stats            *repository.StatsRepository
+	orgAdmin         *repository.OrgAdminRepository
+	boards           *repository.BoardRepository
+	views            *repository.ViewRepository
+	templates        *repository.TemplateRepository
+	projectTemplates *repository.ProjectTemplateRepository
+	customFields     *repository.CustomFieldRepository
+	digests          *repository.DigestRepository
+	shares           *repository.ShareRepository
+	shareLinks       *repository.ShareLinkRepository
+	invitations      *repository.InvitationRepository
+	users            *repository.UserRepository
+	userSettings     *repository.UserSettingsRepository
+	orgSettings      *repository.OrgSettingsRepository
+	activity         *repository.ActivityRepository
+	search           *repository.SearchRepository
+}
+
+// NewPostgres encrypts the fields that are kept encrypted with fields,
+// which can be nil to store them in the clear.
+func NewPostgres(pool *pgxpool.Pool, fields *crypto.Fields) Store {
+	return NewPostgresWithReads(pool, pool, fields)
+}
+
+// NewPostgresWithReads sends search, stats, org admin counts and activity
+// lists to reads, since they can be a little stale; everything else, and
+// anything that reads its own writes, stays on pool.
+func NewPostgresWithReads(pool *pgxpool.Pool, reads repository.Reader, fields *crypto.Fields) Store {
+	return &postgresStore{
+		tasks:         repository.NewTaskRepository(pool),
+		projects:      repository.NewProjectRepository(pool),
+		labels:        repository.NewLabelRepository(pool),
+		workflows:     repository.NewWorkflowRepository(pool),
+		reminders:     repository.NewReminderRepository(pool),
+		emailPrefs:    repository.NewEmailPreferenceRepository(pool),
+		pushSubs:      repository.NewPushSubscriptionRepository(pool),
+		apiTokens:     repository.NewAPITokenRepository(pool),
+		imports:       repository.NewImportRepository(pool),
+		accounts:      repository.NewAccountRepository(pool),
+		calendarFeeds: repository.NewCalendarFeedRepository(pool),
+		inbound:       repository.NewInboundAddressRepository(pool),
+		gcal:          repository.NewGoogleCalendarRepository(pool),
+		slack:         repository.NewSlackRepository(pool),
+		webhooks:      repository.NewWebhookRepository(pool),
+		telegram:      repository.NewTelegramRepository(pool),
+
+		notifications:    repository.NewNotificationRepository(pool),
+		sync:             repository.NewSyncRepository(pool),
+		changes:          repository.NewChangeRepository(pool),
+		usage:            repository.NewUsageRepository(pool),
+		idempotency:      repository.NewIdempotencyRepository(pool),
+		trash:            repository.NewTrashRepository(pool),
+		comments:         repository.NewCommentRepository(pool),
+		attachments:      repository.NewAttachmentRepository(pool, fields),
+		timeEntries:      repository.NewTimeEntryRepository(pool),
+		pomodoros:        repository.NewPomodoroRepository(pool),
+		stats:            repository.NewStatsRepository(reads),
+		orgAdmin:         repository.NewOrgAdminRepository(pool, reads),
+		boards:           repository.NewBoardRepository(pool),
+		views:            repository.NewViewRepository(pool),
+		templates:        repository.NewTemplateRepository(pool),
+		projectTemplates: repository.NewProjectTemplateRepository(pool),
+		customFields:     repository.NewCustomFieldRepository(pool),
+		digests:          repository.NewDigestRepository(pool),
+		shares:           repository.NewShareRepository(pool),
+		shareLinks:       repository.NewShareLinkRepository(pool),
+		invitations:      repository.NewInvitationRepository(pool),
+		users:            repository.NewUserRepository(pool),
+		userSettings:     repository.NewUserSettingsRepository(pool),
+		orgSettings:      repository.NewOrgSettingsRepository(pool),
+		activity:         repository.NewActivityRepository(pool, reads),
+		search:           repository.NewSearchRepository(reads),
+	}
+}
+
+func (s *postgresStore) Tasks() TaskStore                         { return s.tasks }
+func (s *postgresStore) Projects() ProjectStore                   { return s.projects }
+func (s *postgresStore) Labels() LabelStore                       { return s.labels }
+func (s *postgresStore) Workflows() WorkflowStore                 { return s.workflows }
+func (s *postgresStore) Reminders() ReminderStore                 { return s.reminders }
+func (s *postgresStore) EmailPreferences() EmailPreferenceStore   { return s.emailPrefs }
+func (s *postgresStore) UserSettings() UserSettingsStore          { return s.userSettings }
+func (s *postgresStore) OrgSettings() OrgSettingsStore            { return s.orgSettings }
+func (s *postgresStore) PushSubscriptions() PushSubscriptionStore { return s.pushSubs }
+func (s *postgresStore) APITokens() APITokenStore                 { return s.apiTokens }
+func (s *postgresStore) Imports() ImportStore                     { return s.imports }
+func (s *postgresStore) Accounts() AccountStore                   { return s.accounts }
+func (s *postgresStore) CalendarFeeds() CalendarFeedStore         { return s.calendarFeeds }
+func (s *postgresStore) InboundAddresses() InboundAddressStore    { return s.inbound }
+func (s *postgresStore) GoogleCalendar() GoogleCalendarStore      { return s.gcal }
+func (s *postgresStore) Slack() SlackStore                        { return s.slack }
+func (s *postgresStore) Webhooks() WebhookStore                   { return s.webhooks }
+func (s *postgresStore) Telegram() TelegramStore                  { return s.telegram }
+func (s *postgresStore) Notifications() NotificationStore         { return s.notifications }
+func (s *postgresStore) Sync() SyncStore                          { return s.sync }
+func (s *postgresStore) Changes() ChangeStore                     { return s.changes }
+func (s *postgresStore) Usage() UsageStore                        { return s.usage }
+func (s *postgresStore) Idempotency() IdempotencyStore            { return s.idempotency }
+func (s *postgresStore) Trash() TrashStore                        { return s.trash }
+func (s *postgresStore) Comments() CommentStore                   { return s.comments }
+func (s *postgresStore) Attachments() AttachmentStore             { return s.attachments }
+func (s *postgresStore) TimeEntries() TimeEntryStore              { return s.timeEntries }
+func (s *postgresStore) Pomodoros() PomodoroStore                 { return s.pomodoros }
+func (s *postgresStore) Stats() StatsStore                        { return s.stats }
+func (s *postgresStore) OrgAdmin() OrgAdminStore                  { return s.orgAdmin }
+func (s *postgresStore) Boards() BoardStore                       { return s.boards }
+func (s *postgresStore) Views() ViewStore                         { return s.views }
+func (s *postgresStore) Templates() TemplateStore                 { return s.templates }
+func (s *postgresStore) ProjectTemplates() ProjectTemplateStore   { return s.projectTemplates }
+func (s *postgresStore) CustomFields() CustomFieldStore           { return s.customFields }
+func (s *postgresStore) Digests() DigestStore                     { return s.digests }
+func (s *postgresStore) Shares() ShareStore                       { return s.shares }
+func (s *postgresStore) ShareLinks() ShareLinkStore               { return s.shareLinks }
+func (s *postgresStore) Invitations() InvitationStore             { return s.invitations }
+func (s *postgresStore) Users() UserStore                         { return s.users }
+func (s *postgresStore) Activity() ActivityStore                  { return s.activity }
+func (s *postgresStore) Search() SearchStore                      { return s.search }
@@ -0,0 +1,682 @@
+package store
+
+import (
+	"context"
+	"time"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/repository"
+)
+
+// ErrNotFound is returned by every implementation when the row doesn't exist
+// or isn't visible in the caller's scope.
+var ErrNotFound = repository.ErrNotFound
+
+// ErrDependencyCycle is returned when adding a dependency would make a task
+// (transitively) block itself.
+var ErrDependencyCycle = repository.ErrDependencyCycle
+
+// ErrConflict is returned when a write would violate a uniqueness rule.
+var ErrConflict = repository.ErrConflict
+
+// ErrVersionMismatch is returned when a conditional write's expected version
+// is no longer the current one.
+var ErrVersionMismatch = repository.ErrVersionMismatch
+
+// ErrReadOnly is returned when writing to a task the caller can see but was
+// only given viewer access to.
+var ErrReadOnly = repository.ErrReadOnly
+
+// SyncConflictError is a sync op on a task id another scope's task has. It
+// wraps ErrConflict.
+type SyncConflictError = repository.SyncConflictError
+
+type TaskStore interface {
+	Create(ctx context.Context, scope models.Scope, input models.CreateTaskInput) (*models.Task, error)
+	// CreateBatch creates the tasks together, or none of them, returning
+	// them in batch order.
+	CreateBatch(ctx context.Context, scope models.Scope, batch []models.NewTask) ([]models.Task, error)
+	Get(ctx context.Context, scope models.Scope, id string) (*models.Task, error)
+	// Visible returns those of ids that are tasks the scope can see, in the
+	// trash or not, in no particular order.
+	Visible(ctx context.Context, scope models.Scope, ids []string) ([]string, error)
+	List(ctx context.Context, scope models.Scope, filter models.TaskFilter, page models.Page) ([]models.Task, error)
+	Update(ctx context.Context, scope models.Scope, id string, input models.UpdateTaskInput) (*models.Task, error)
+	// Delete only deletes when ifVersion is nil or the current version.
+	Delete(ctx context.Context, scope models.Scope, id string, ifVersion *int) error
+	Subtree(ctx context.Context, scope models.Scope, id string) ([]models.Task, error)
+	Progress(ctx context.Context, scope models.Scope, id string, doneStatuses []string) (models.TaskProgress, error)
+	// SubtaskEstimate is models.RollUpEstimate over the task's subtasks.
+	SubtaskEstimate(ctx context.Context, scope models.Scope, id string) (*int, error)
+	Dependencies(ctx context.Context, scope models.Scope, id string) (models.TaskDependencies, error)
+	AddDependency(ctx context.Context, scope models.Scope, id, blockedByID string) error
+	RemoveDependency(ctx context.Context, scope models.Scope, id, blockedByID string) error
+	// Bulk applies ops together, reporting each one's outcome. Complete moves
+	// tasks not already in one of doneStatuses to completedStatus.
+	Bulk(ctx context.Context, scope models.Scope, ops []models.BulkTaskOp, completedStatus string, doneStatuses []string) ([]models.BulkTaskResult, error)
+	SetArchived(ctx context.Context, scope models.Scope, id string, archived bool) (*models.Task, error)
+	// Snooze sets or, with a nil until, clears the task's snooze. Reminders
+	// of the caller's that already went off fire again when it ends.
+	Snooze(ctx context.Context, scope models.Scope, id string, until *time.Time) (*models.Task, error)
+	// Reorder moves the task in the scope's manual order. ErrNotFound is for
+	// the task or a neighbour, and ErrConflict for neighbours that are no
+	// longer in the order given.
+	Reorder(ctx context.Context, scope models.Scope, id string, input models.ReorderTaskInput) (*models.Task, error)
+	// ArchiveCompleted archives up to limit done tasks, across every scope,
+	// that were last changed before the given time.
+	ArchiveCompleted(ctx context.Context, before time.Time, limit int) (int, error)
+}
+
+type ProjectStore interface {
+	Create(ctx context.Context, orgID, userID string, input models.CreateProjectInput) (*models.Project, error)
+	// CreateSeeded creates a project in the scope's org together with its
+	// board and the batch of tasks in it (see TaskStore.CreateBatch), or
+	// none of them.
+	CreateSeeded(ctx context.Context, scope models.Scope, input models.NewProject) (*models.SeededProject, error)
+	Get(ctx context.Context, orgID, id string) (*models.Project, error)
+	List(ctx context.Context, orgID string, includeArchived bool, page models.Page) ([]models.Project, error)
+	Rename(ctx context.Context, orgID, id, name string) (*models.Project, error)
+	SetArchived(ctx context.Context, orgID, id string, archived bool) (*models.Project, error)
+}
+
+type LabelStore interface {
+	Create(ctx context.Context, orgID, userID string, input models.CreateLabelInput) (*models.Label, error)
+	List(ctx context.Context, orgID string) ([]models.Label, error)
+	Update(ctx context.Context, orgID, id string, input models.UpdateLabelInput) (*models.Label, error)
+	Delete(ctx context.Context, orgID, id string) error
+	Attach(ctx context.Context, orgID, taskID, labelID string) error
+	Detach(ctx context.Context, orgID, taskID, labelID string) error
+	// ForTasks returns the labels of each task, keyed by task id.
+	ForTasks(ctx context.Context, orgID string, taskIDs []string) (map[string][]models.Label, error)
+}
+
+// CustomFieldStore holds each org's custom field definitions. Keys are
+// unique within the org; Create returns ErrConflict for a key in use.
+type CustomFieldStore interface {
+	Create(ctx context.Context, orgID, userID string, input models.CreateCustomFieldInput) (*models.CustomField, error)
+	List(ctx context.Context, orgID string) ([]models.CustomField, error)
+	// Update drops values no longer among a select field's options from
+	// the org's tasks.
+	Update(ctx context.Context, orgID, id string, input models.UpdateCustomFieldInput) (*models.CustomField, error)
+	// Delete also clears the field from every task in the org.
+	Delete(ctx context.Context, orgID, id string) error
+}
+
+// WorkflowStore holds each org's configured statuses and priority levels.
+type WorkflowStore interface {
+	Get(ctx context.Context, orgID string) (models.Workflow, error)
+	SetStatuses(ctx context.Context, orgID string, statuses []models.StatusDefinition) (models.Workflow, error)
+	SetPriorities(ctx context.Context, orgID string, priorities []models.PriorityLevel) (models.Workflow, error)
+}
+
+type ReminderStore interface {
+	Create(ctx context.Context, scope models.Scope, taskID string, input models.CreateReminderInput) (*models.Reminder, error)
+	List(ctx context.Context, scope models.Scope, taskID string) ([]models.Reminder, error)
+	Delete(ctx context.Context, scope models.Scope, taskID, id string) error
+	// ClaimDue marks due reminders as sent and returns them for delivery.
+	ClaimDue(ctx context.Context, limit int) ([]models.DueReminder, error)
+}
+
+type EmailPreferenceStore interface {
+	Get(ctx context.Context, userID string) (models.EmailPreferences, error)
+	Update(ctx context.Context, userID string, input models.UpdateEmailPreferencesInput) (models.EmailPreferences, error)
+}
+
+// UserSettingsStore returns models.DefaultUserSettings for users who never
+// changed anything.
+type UserSettingsStore interface {
+	Get(ctx context.Context, userID string) (models.UserSettings, error)
+	Update(ctx context.Context, userID string, input models.UpdateUserSettingsInput) (models.UserSettings, error)
+}
+
+// DigestStore picks who gets digest emails and works out what they list.
+type DigestStore interface {
+	// Subscribers returns the users with a daily or weekly digest and email
+	// turned on.
+	Subscribers(ctx context.Context) ([]models.DigestSubscriber, error)
+	// Claim marks the user's digest for slot as sent at sentAt, reporting
+	// false if one already was at or after slot. Several instances can poll
+	// at once without sending the same digest twice.
+	Claim(ctx context.Context, userID string, slot, sentAt time.Time) (bool, error)
+	Digest(ctx context.Context, userID string, q models.DigestQuery) (models.Digest, error)
+}
+
+// OrgSettingsStore returns models.DefaultOrgSettings for orgs that never
+// changed anything.
+type OrgSettingsStore interface {
+	Get(ctx context.Context, orgID string) (models.OrgSettings, error)
+	Update(ctx context.Context, orgID string, input models.UpdateOrgSettingsInput) (models.OrgSettings, error)
+}
+
+type PushSubscriptionStore interface {
+	Create(ctx context.Context, userID string, input models.CreatePushSubscriptionInput) (*models.PushSubscription, error)
+	Delete(ctx context.Context, userID, endpoint string) error
+	ListForUser(ctx context.Context, userID string) ([]models.PushSubscription, error)
+}
+
+// APITokenStore keeps personal access tokens; every method but
+// Authenticate is scoped to one user.
+type APITokenStore interface {
+	Create(ctx context.Context, scope models.Scope, input models.CreateAPITokenInput) (*models.APIToken, error)
+	// ListForUser returns the user's tokens, newest first.
+	ListForUser(ctx context.Context, userID string) ([]models.APIToken, error)
+	Delete(ctx context.Context, userID, id string) error
+	// Authenticate returns ErrNotFound unless an unexpired token has
+	// tokenHash, and marks the token as used.
+	Authenticate(ctx context.Context, tokenHash []byte) (*models.APIToken, error)
+}
+
+// CalendarFeedStore keeps each user's calendar feed, one per scope.
+type CalendarFeedStore interface {
+	Get(ctx context.Context, scope models.Scope) (*models.CalendarFeed, error)
+	// Rotate creates the scope's feed, or replaces it so the old URL stops
+	// working.
+	Rotate(ctx context.Context, scope models.Scope, tokenHash []byte) (*models.CalendarFeed, error)
+	Delete(ctx context.Context, scope models.Scope) error
+	// Lookup returns ErrNotFound unless a feed has tokenHash, and marks the
+	// feed as used.
+	Lookup(ctx context.Context, tokenHash []byte) (*models.CalendarFeed, error)
+}
+
+// InboundAddressStore keeps the addresses that turn email into tasks.
+type InboundAddressStore interface {
+	// List returns the user's addresses in scope, the scope's own first.
+	List(ctx context.Context, scope models.Scope) ([]models.InboundAddress, error)
+	// Rotate creates the address for the scope or one of its projects, or
+	// gives it a new token so the old address stops working.
+	Rotate(ctx context.Context, scope models.Scope, projectID *string, token string) (*models.InboundAddress, error)
+	Delete(ctx context.Context, scope models.Scope, id string) error
+	Lookup(ctx context.Context, token string) (*models.InboundAddress, error)
+}
+
+// GoogleCalendarStore keeps each user's Google Calendar connection, one per
+// scope, and the events the sync made for their tasks.
+type GoogleCalendarStore interface {
+	Get(ctx context.Context, scope models.Scope) (*models.GoogleCalendarConnection, error)
+	// Connect creates the scope's connection, or replaces it and forgets the
+	// events synced through the old one. It's due for sync straight away.
+	Connect(ctx context.Context, scope models.Scope, email string, token models.GoogleCalendarToken) (*models.GoogleCalendarConnection, error)
+	Delete(ctx context.Context, scope models.Scope) error
+	// ClaimDue returns up to limit connections due for sync, tokens
+	// included, and puts them off by interval so no other worker picks them.
+	ClaimDue(ctx context.Context, interval time.Duration, limit int) ([]models.GoogleCalendarConnection, error)
+	SaveToken(ctx context.Context, id string, token models.GoogleCalendarToken) error
+	// Synced records how a sync went: with syncErr nil it succeeded at at.
+	Synced(ctx context.Context, id string, at time.Time, syncErr *string) error
+	Events(ctx context.Context, connectionID string) ([]models.GoogleCalendarEvent, error)
+	SaveEvent(ctx context.Context, event models.GoogleCalendarEvent) error
+	DeleteEvent(ctx context.Context, connectionID, taskID string) error
+}
+
+// SlackStore keeps each org's Slack integration.
+type SlackStore interface {
+	Get(ctx context.Context, orgID string) (*models.SlackIntegration, error)
+	// Set returns ErrConflict if another org has the workspace already.
+	Set(ctx context.Context, orgID string, input models.SetSlackIntegrationInput) (*models.SlackIntegration, error)
+	Delete(ctx context.Context, orgID string) error
+	GetByTeam(ctx context.Context, teamID string) (*models.SlackIntegration, error)
+}
+
+// WebhookStore keeps the endpoints orgs send their events to, and the log
+// of attempts at delivering them.
+type WebhookStore interface {
+	Create(ctx context.Context, orgID, userID string, input models.CreateWebhookInput) (*models.WebhookEndpoint, error)
+	List(ctx context.Context, orgID string) ([]models.WebhookEndpoint, error)
+	Get(ctx context.Context, orgID, id string) (*models.WebhookEndpoint, error)
+	Update(ctx context.Context, orgID, id string, input models.UpdateWebhookInput) (*models.WebhookEndpoint, error)
+	// Delete also drops the endpoint's delivery log.
+	Delete(ctx context.Context, orgID, id string) error
+	// Subscribed lists the org's active endpoints that want event.
+	Subscribed(ctx context.Context, orgID, event string) ([]models.WebhookEndpoint, error)
+	// Target returns an endpoint with its secret, for delivering to it.
+	Target(ctx context.Context, id string) (*models.WebhookEndpoint, error)
+	// RecordDelivery logs an attempt, numbering it after the earlier
+	// attempts at the same event.
+	RecordDelivery(ctx context.Context, d models.WebhookDelivery) (*models.WebhookDelivery, error)
+	// Deliveries returns up to page.Limit+1 of the endpoint's attempts,
+	// newest first.
+	Deliveries(ctx context.Context, orgID, endpointID string, page models.Page) ([]models.WebhookDelivery, error)
+	// Breaker returns the breaker on the org's deliveries to url; a URL
+	// nothing has failed to reach has a closed one.
+	Breaker(ctx context.Context, orgID, url string) (*models.WebhookBreaker, error)
+	// ClaimProbe lets one delivery through a half-open breaker: the first
+	// caller gets true and the breaker stays held for lease, so a probe
+	// that never reports back is retried after it.
+	ClaimProbe(ctx context.Context, orgID, url string, lease time.Duration) (bool, error)
+	// RecordBreakerResult closes the breaker after a delivery succeeds and
+	// counts the failure with WebhookBreaker.Fail otherwise.
+	RecordBreakerResult(ctx context.Context, orgID, url string, succeeded bool, policy models.WebhookBreakerPolicy) (*models.WebhookBreaker, error)
+}
+
+// TelegramStore keeps the chat each user linked with the bot, and the
+// codes that link them.
+type TelegramStore interface {
+	Get(ctx context.Context, userID string) (*models.TelegramLink, error)
+	// CreateLinkCode replaces the user's pending codes with codeHash, which
+	// links from scope until expiresAt.
+	CreateLinkCode(ctx context.Context, scope models.Scope, codeHash []byte, expiresAt time.Time) error
+	// Link uses up a code and links its user to chatID, taking the chat
+	// from anyone it was linked to. It returns ErrNotFound for a code that
+	// was never made, was used or expired.
+	Link(ctx context.Context, codeHash []byte, chatID int64, username *string) (*models.TelegramLink, error)
+	Delete(ctx context.Context, userID string) error
+	GetByChat(ctx context.Context, chatID int64) (*models.TelegramLink, error)
+	DeleteByChat(ctx context.Context, chatID int64) error
+}
+
+// ImportStore tracks uploaded exports through the job that imports them.
+type ImportStore interface {
+	Create(ctx context.Context, scope models.Scope, input models.CreateImportInput) (*models.Import, error)
+	// Get only returns the user's own imports.
+	Get(ctx context.Context, userID, id string) (*models.Import, error)
+	// Start moves a pending import to running and returns it with the
+	// uploaded file. It returns ErrNotFound for one that's already started,
+	// so a retried job doesn't import the file twice.
+	Start(ctx context.Context, id string) (*models.Import, []byte, error)
+	Progress(ctx context.Context, id string, progress models.ImportProgress) error
+}
+
+// AccountStore gathers and erases everything kept about a user, and tracks
+// the exports of it they ask for.
+type AccountStore interface {
+	// CreateExport returns the user's export that's still being built, if
+	// there is one, rather than starting another.
+	CreateExport(ctx context.Context, userID string) (*models.DataExport, error)
+	// GetExport only returns the user's own exports.
+	GetExport(ctx context.Context, userID, id string) (*models.DataExport, error)
+	// StartExport moves a pending export to running. It returns ErrNotFound
+	// for one that's already started.
+	StartExport(ctx context.Context, id string) (*models.DataExport, error)
+	FinishExport(ctx context.Context, id, key string, size int64, expiresAt time.Time) error
+	FailExport(ctx context.Context, id, message string) error
+	Collect(ctx context.Context, userID string) (*models.AccountData, error)
+	// Erase deletes what only concerns the user, and what only they could
+	// see, and puts models.DeletedUserID in their place on what's left in
+	// orgs. The user is tombstoned with their profile cleared. Erasing an
+	// erased user does nothing more.
+	Erase(ctx context.Context, userID string) (models.ErasedAccount, error)
+}
+
+// NotificationStore is the in-app inbox; every method is scoped to one user.
+type NotificationStore interface {
+	Create(ctx context.Context, input models.CreateNotificationInput) (*models.Notification, error)
+	List(ctx context.Context, userID string, unreadOnly bool, page models.Page) ([]models.Notification, error)
+	SetRead(ctx context.Context, userID, id string, read bool) (*models.Notification, error)
+	MarkAllRead(ctx context.Context, userID string) (int, error)
+	UnreadCount(ctx context.Context, userID string) (int, error)
+}
+
+// SyncStore merges offline edits. Each task field is a last-writer-wins
+// register ordered by the op timestamp, and deletes win over everything, so
+// the result doesn't depend on the order ops arrive in.
+type SyncStore interface {
+	// Push applies ops in order and returns the scope's checkpoint after
+	// them. Tasks created by an op start with defaultStatus. An op on
+	// another scope's task id fails the push with a *SyncConflictError,
+	// and none of the ops are applied.
+	Push(ctx context.Context, scope models.Scope, defaultStatus string, ops []models.SyncOp) ([]models.SyncResult, int64, error)
+	// Pull returns up to limit+1 applied ops logged after the since checkpoint.
+	Pull(ctx context.Context, scope models.Scope, since int64, limit int) ([]models.SyncOp, error)
+}
+
+// UsageStore reads what orgs hold against their quotas.
+type UsageStore interface {
+	Get(ctx context.Context, orgID string) (*models.Usage, error)
+}
+
+// ChangeStore reads the change log that every write to tasks, projects and
+// labels leaves, for clients that sync incrementally instead of loading
+// everything again.
+type ChangeStore interface {
+	// Current is the cursor a sync starting now begins from.
+	Current(ctx context.Context, scope models.Scope) (models.ChangeCursor, error)
+	// Since returns what changed in scope after the cursor, reading at most
+	// limit log entries.
+	Since(ctx context.Context, scope models.Scope, after models.ChangeCursor, limit int) (*models.Changes, error)
+	// Prune drops entries logged before cutoff.
+	Prune(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// IdempotencyStore remembers responses to requests sent with an
+// Idempotency-Key, per user and org.
+type IdempotencyStore interface {
+	// Reserve claims key and reports true, or returns what's stored for it.
+	Reserve(ctx context.Context, scope models.Scope, key, fingerprint string, ttl time.Duration) (models.IdempotentRequest, bool, error)
+	Complete(ctx context.Context, scope models.Scope, key string, statusCode int, contentType string, body []byte) error
+	Release(ctx context.Context, scope models.Scope, key string) error
+}
+
+// TrashStore holds soft-deleted tasks and projects until they're restored or
+// purged.
+type TrashStore interface {
+	ListTasks(ctx context.Context, scope models.Scope) ([]models.Task, error)
+	ListProjects(ctx context.Context, orgID string) ([]models.Project, error)
+	// RestoreTask returns ErrConflict while the task's parent or project is
+	// still in the trash.
+	RestoreTask(ctx context.Context, scope models.Scope, id string) (*models.Task, error)
+	DeleteProject(ctx context.Context, orgID, id string) error
+	RestoreProject(ctx context.Context, orgID, id string) (*models.Project, error)
+	// Purge permanently deletes what was trashed before cutoff, or before an
+	// org's own trash retention where it set one.
+	Purge(ctx context.Context, cutoff time.Time) (int, error)
+}
+
+// CommentStore holds comments on tasks; every method requires the task to
+// be live and in scope.
+type CommentStore interface {
+	// Create is ErrNotFound for a parent that isn't on the task; callers
+	// pass a top-level one.
+	Create(ctx context.Context, scope models.Scope, taskID string, input models.CommentInput) (*models.Comment, error)
+	Get(ctx context.Context, scope models.Scope, taskID, id string) (*models.Comment, error)
+	// List returns up to page.Limit+1 comments, oldest first.
+	List(ctx context.Context, scope models.Scope, taskID string, page models.Page) ([]models.Comment, error)
+	// Update keeps the replaced body in the comment's history.
+	Update(ctx context.Context, scope models.Scope, taskID, id string, input models.CommentInput) (*models.Comment, error)
+	Delete(ctx context.Context, scope models.Scope, taskID, id string) error
+	History(ctx context.Context, scope models.Scope, taskID, id string) ([]models.CommentRevision, error)
+	// SetPinned pins the comment to its task, or unpins it; pinning a
+	// pinned comment keeps who pinned it and when.
+	SetPinned(ctx context.Context, scope models.Scope, taskID, id string, pinned bool) (*models.Comment, error)
+	// Pinned returns the task's pinned comments, in the order they were
+	// pinned.
+	Pinned(ctx context.Context, scope models.Scope, taskID string) ([]models.Comment, error)
+	// Commented reports whether userID has commented on the task.
+	Commented(ctx context.Context, scope models.Scope, taskID, userID string) (bool, error)
+	// Counts returns how many comments each task has, keyed by task id;
+	// tasks without comments are left out. Callers pass tasks they've
+	// already loaded in scope.
+	Counts(ctx context.Context, taskIDs []string) (map[string]int, error)
+}
+
+// AttachmentStore holds the metadata of files attached to tasks; every
+// method but DeletePending requires the task to be live and in scope.
+type AttachmentStore interface {
+	// Create records a pending attachment that List leaves out until it's
+	// confirmed.
+	Create(ctx context.Context, scope models.Scope, taskID string, input models.CreateAttachmentInput) (*models.Attachment, error)
+	Get(ctx context.Context, scope models.Scope, taskID, id string) (*models.Attachment, error)
+	// List returns the task's confirmed attachments, oldest first.
+	List(ctx context.Context, scope models.Scope, taskID string) ([]models.Attachment, error)
+	Confirm(ctx context.Context, scope models.Scope, taskID, id string) (*models.Attachment, error)
+	Delete(ctx context.Context, scope models.Scope, taskID, id string) error
+	// DeletePending deletes attachments in every scope that were created
+	// before cutoff and never confirmed, and returns their object keys.
+	DeletePending(ctx context.Context, cutoff time.Time) ([]string, error)
+}
+
+// TimeEntryStore holds the time users log on tasks. Entries are read by
+// whoever can see the task; callers check the entry is the user's own
+// before changing it.
+type TimeEntryStore interface {
+	// Start stops the user's running timer, on whichever task it is, and
+	// starts one on the task. ErrConflict means another start raced it.
+	Start(ctx context.Context, scope models.Scope, taskID string, input models.StartTimerInput) (*models.TimeEntry, error)
+	// Stop stops the user's running timer on the task.
+	Stop(ctx context.Context, scope models.Scope, taskID string) (*models.TimeEntry, error)
+	// Running returns the user's running timer in any scope.
+	Running(ctx context.Context, userID string) (*models.TimeEntry, error)
+	Create(ctx context.Context, scope models.Scope, taskID string, input models.CreateTimeEntryInput) (*models.TimeEntry, error)
+	Get(ctx context.Context, scope models.Scope, taskID, id string) (*models.TimeEntry, error)
+	Update(ctx context.Context, scope models.Scope, taskID, id string, input models.UpdateTimeEntryInput) (*models.TimeEntry, error)
+	Delete(ctx context.Context, scope models.Scope, taskID, id string) error
+	// List returns up to page.Limit+1 of the task's entries, newest first.
+	List(ctx context.Context, scope models.Scope, taskID string, page models.Page) ([]models.TimeEntry, error)
+	TaskTotals(ctx context.Context, scope models.Scope, taskID string) (*models.TimeTotals, error)
+	// ProjectTotals sums the project's live tasks that the scope can see.
+	ProjectTotals(ctx context.Context, scope models.Scope, projectID string) (*models.TimeTotals, error)
+	// Export returns up to page.Limit+1 finished entries on tasks the
+	// scope can see, trashed ones included, oldest first.
+	Export(ctx context.Context, scope models.Scope, filter models.TimeEntryFilter, page models.Page) ([]models.ExportedTimeEntry, error)
+}
+
+// PomodoroStore keeps users' focus sessions. A session is its user's own,
+// in the scope it was started in.
+type PomodoroStore interface {
+	// Start begins a session, on a task that has to be live and in scope
+	// when one is given. It returns ErrConflict while the user has a
+	// session running in any scope.
+	Start(ctx context.Context, scope models.Scope, taskID *string, plannedSeconds int) (*models.Pomodoro, error)
+	Get(ctx context.Context, scope models.Scope, id string) (*models.Pomodoro, error)
+	// Running returns the user's running session in any scope.
+	Running(ctx context.Context, userID string) (*models.Pomodoro, error)
+	// Finish ends the session as status; it has to be running.
+	Finish(ctx context.Context, scope models.Scope, id, status string) (*models.Pomodoro, error)
+	// List returns up to page.Limit+1 sessions, newest first.
+	List(ctx context.Context, scope models.Scope, page models.Page) ([]models.Pomodoro, error)
+	// Days totals the finished sessions by the day they started on in loc,
+	// oldest first, leaving out days without any.
+	Days(ctx context.Context, scope models.Scope, loc *time.Location) ([]models.PomodoroDay, error)
+}
+
+// ViewStore keeps saved views. Get and List return the scope's own views
+// and, in an org, the ones shared with it; writes to a shared view the scope
+// doesn't own are ErrReadOnly.
+type ViewStore interface {
+	Create(ctx context.Context, scope models.Scope, input models.CreateViewInput) (*models.View, error)
+	Get(ctx context.Context, scope models.Scope, id string) (*models.View, error)
+	// List orders views by name.
+	List(ctx context.Context, scope models.Scope) ([]models.View, error)
+	Update(ctx context.Context, scope models.Scope, id string, input models.UpdateViewInput) (*models.View, error)
+	Delete(ctx context.Context, scope models.Scope, id string) error
+}
+
+// TemplateStore keeps task templates. Get and List return the scope's
+// templates, which in an org are everyone's; writes to one the scope's user
+// doesn't own are ErrReadOnly.
+type TemplateStore interface {
+	Create(ctx context.Context, scope models.Scope, name string, task models.TemplateTask) (*models.TaskTemplate, error)
+	Get(ctx context.Context, scope models.Scope, id string) (*models.TaskTemplate, error)
+	// List orders templates by name.
+	List(ctx context.Context, scope models.Scope) ([]models.TaskTemplate, error)
+	Update(ctx context.Context, scope models.Scope, id string, input models.UpdateTemplateInput) (*models.TaskTemplate, error)
+	Delete(ctx context.Context, scope models.Scope, id string) error
+}
+
+// ProjectTemplateStore keeps an org's project templates. Everyone in the
+// org can see them; writes to one the scope's user didn't create are
+// ErrReadOnly.
+type ProjectTemplateStore interface {
+	Create(ctx context.Context, scope models.Scope, name string, tasks []models.ProjectTemplateTask) (*models.ProjectTemplate, error)
+	Get(ctx context.Context, scope models.Scope, id string) (*models.ProjectTemplate, error)
+	// List orders templates by name.
+	List(ctx context.Context, scope models.Scope) ([]models.ProjectTemplate, error)
+	Update(ctx context.Context, scope models.Scope, id string, input models.UpdateProjectTemplateInput) (*models.ProjectTemplate, error)
+	Delete(ctx context.Context, scope models.Scope, id string) error
+}
+
+// BoardStore keeps the kanban boards on projects in an org.
+type BoardStore interface {
+	Create(ctx context.Context, scope models.Scope, projectID string, input models.CreateBoardInput) (*models.Board, error)
+	// Get returns the board with its columns but not their tasks.
+	Get(ctx context.Context, scope models.Scope, id string) (*models.Board, error)
+	ListForProject(ctx context.Context, scope models.Scope, projectID string) ([]models.Board, error)
+	Update(ctx context.Context, scope models.Scope, id string, input models.UpdateBoardInput) (*models.Board, error)
+	Delete(ctx context.Context, scope models.Scope, id string) error
+	// Cards returns the board with the live, unarchived tasks the scope can
+	// see in each column, in board order.
+	Cards(ctx context.Context, scope models.Scope, id string) (*models.Board, error)
+	// Move gives the task the column's status and puts it at the index in
+	// one go. The task has to be in the board's project; ErrReadOnly is for
+	// one the scope may only view.
+	Move(ctx context.Context, scope models.Scope, id string, input models.MoveCardInput) (*models.BoardMove, error)
+}
+
+// OrgAdminStore manages members' tasks on behalf of org admins. It acts on
+// the org's tasks whoever they're visible to, and owners it's given are
+// taken to be members already.
+type OrgAdminStore interface {
+	// TaskCounts counts the tasks each of userIDs owns.
+	TaskCounts(ctx context.Context, orgID string, userIDs []string) (map[string]models.MemberTaskCounts, error)
+	// TransferTasks hands every live task fromUserID owns to toUserID;
+	// tasks in the trash stay with fromUserID. It returns ErrNotFound if
+	// toUserID isn't a member of the org.
+	TransferTasks(ctx context.Context, orgID, fromUserID, toUserID string) ([]models.ReassignedTask, error)
+	// ReassignTasks hands the live tasks among ids to toUserID; ids it
+	// doesn't find, or that toUserID owns already, are skipped. Like
+	// TransferTasks, it returns ErrNotFound for a toUserID not in the org.
+	ReassignTasks(ctx context.Context, orgID string, ids []string, toUserID string) ([]models.ReassignedTask, error)
+}
+
+// StatsStore aggregates the live tasks the scope can see, for reporting.
+// A task is open until its CompletedAt, so one that's reopened counts as
+// never having been done.
+type StatsStore interface {
+	// Completed counts the tasks completed in each period, which runs from
+	// one of bounds to the next.
+	Completed(ctx context.Context, scope models.Scope, bounds []time.Time) ([]int, error)
+	// Overdue counts at each of points the tasks open and past due then.
+	Overdue(ctx context.Context, scope models.Scope, points []time.Time) ([]int, error)
+	// Throughput counts each owner's tasks completed between from and to.
+	Throughput(ctx context.Context, scope models.Scope, from, to time.Time) ([]models.MemberThroughput, error)
+	// Burndown counts at each of points the project's tasks created by
+	// then and those still open; it leaves the dates to the caller.
+	Burndown(ctx context.Context, scope models.Scope, projectID string, points []time.Time) ([]models.BurndownPoint, error)
+	// Workload sums up the unarchived open tasks each member of the scope's
+	// org owns that are due between from and to. Members with none are
+	// listed too, the most estimated work first.
+	Workload(ctx context.Context, scope models.Scope, from, to time.Time) ([]models.MemberWorkload, error)
+}
+
+// ShareStore grants org members access to private tasks, one at a time or
+// for a whole project. The task or project has to be live and visible in
+// scope; sharing with someone who already has a share changes their role.
+type ShareStore interface {
+	ShareTask(ctx context.Context, scope models.Scope, taskID string, input models.ShareInput) (*models.Share, error)
+	ListTaskShares(ctx context.Context, scope models.Scope, taskID string) ([]models.Share, error)
+	UnshareTask(ctx context.Context, scope models.Scope, taskID, userID string) error
+	ShareProject(ctx context.Context, scope models.Scope, projectID string, input models.ShareInput) (*models.Share, error)
+	ListProjectShares(ctx context.Context, scope models.Scope, projectID string) ([]models.Share, error)
+	UnshareProject(ctx context.Context, scope models.Scope, projectID, userID string) error
+}
+
+// ShareLinkStore keeps the public links to tasks and projects and a log of
+// each time one is opened. Apart from Get and RecordAccess, which serve the
+// public route, methods only reach links the caller made in scope.
+type ShareLinkStore interface {
+	// Create needs the task to be live and editable in scope, or the project
+	// live in the org. A task the scope may only view is ErrReadOnly.
+	Create(ctx context.Context, scope models.Scope, input models.CreateShareLinkInput) (*models.ShareLink, error)
+	Get(ctx context.Context, id string) (*models.ShareLink, error)
+	ListForTask(ctx context.Context, scope models.Scope, taskID string) ([]models.ShareLink, error)
+	ListForProject(ctx context.Context, scope models.Scope, projectID string) ([]models.ShareLink, error)
+	// Revoke is a no-op on a link that's already revoked.
+	Revoke(ctx context.Context, scope models.Scope, id string) (*models.ShareLink, error)
+	// RevokeMember revokes every link the user made in the org, for when
+	// they leave it.
+	RevokeMember(ctx context.Context, orgID, userID string) error
+	RecordAccess(ctx context.Context, linkID, ip, userAgent string) error
+	// ListAccesses returns up to page.Limit+1 opens of the link, newest first.
+	ListAccesses(ctx context.Context, scope models.Scope, id string, page models.Page) ([]models.ShareLinkAccess, error)
+}
+
+// InvitationStore keeps guest invitations to an org's projects.
+type InvitationStore interface {
+	// Create needs the project to be live in the org.
+	Create(ctx context.Context, orgID, invitedBy string, input models.CreateInvitationInput) (*models.Invitation, error)
+	// GetByToken finds an invitation in the org by its token's hash, whatever
+	// its state.
+	GetByToken(ctx context.Context, orgID string, tokenHash []byte) (*models.Invitation, error)
+	// ListForProject returns the project's invitations, newest first.
+	ListForProject(ctx context.Context, orgID, projectID string) ([]models.Invitation, error)
+	// Revoke and Accept return ErrNotFound unless the invitation is pending.
+	Revoke(ctx context.Context, orgID, projectID, id string) (*models.Invitation, error)
+	// Accept shares the project with the user at the invitation's role.
+	Accept(ctx context.Context, scope models.Scope, id string) (*models.Invitation, error)
+}
+
+// UserStore is the local mirror of Clerk's users, organizations and
+// memberships. Writes carrying an older UpdatedAt than what's stored are
+// ignored, as are writes to users and orgs that have been deleted.
+type UserStore interface {
+	UpsertUser(ctx context.Context, user models.User) error
+	DeleteUser(ctx context.Context, id string) error
+	UpsertOrganization(ctx context.Context, org models.Organization) error
+	DeleteOrganization(ctx context.Context, id string) error
+	// SetOrganizationStatus returns ErrNotFound for orgs that aren't
+	// mirrored yet or are deleted. Clerk's updates don't order against it.
+	SetOrganizationStatus(ctx context.Context, id, status string) error
+	// GetOrganizationStatus is models.OrgStatusActive for orgs that aren't
+	// mirrored yet.
+	GetOrganizationStatus(ctx context.Context, id string) (string, error)
+	UpsertMembership(ctx context.Context, membership models.OrgMembership) error
+	DeleteMembership(ctx context.Context, orgID, userID string) error
+
+	// GetUser includes deleted users, so old records can still name them.
+	GetUser(ctx context.Context, id string) (*models.User, error)
+	GetMember(ctx context.Context, orgID, userID string) (*models.OrgMember, error)
+	// FindMemberByEmail finds the org member with the address, for
+	// integrations that only know who someone is by email.
+	FindMemberByEmail(ctx context.Context, orgID, email string) (*models.OrgMember, error)
+	// ListMembers returns up to page.Limit+1 of the org's members by user id.
+	ListMembers(ctx context.Context, orgID string, page models.Page) ([]models.OrgMember, error)
+}
+
+// ActivityStore is the audit trail of task and project changes.
+type ActivityStore interface {
+	Record(ctx context.Context, input models.CreateActivityInput) error
+	// ListForTask returns up to page.Limit+1 entries for the task, newest first.
+	ListForTask(ctx context.Context, scope models.Scope, taskID string, page models.Page) ([]models.Activity, error)
+	// ListForOrg returns up to page.Limit+1 entries across the org, newest first.
+	ListForOrg(ctx context.Context, orgID string, page models.Page) ([]models.Activity, error)
+	// ListOwnerChanges returns the task's entries that changed its owner,
+	// oldest first.
+	ListOwnerChanges(ctx context.Context, scope models.Scope, taskID string) ([]models.Activity, error)
+}
+
+type SearchStore interface {
+	SearchTasks(ctx context.Context, scope models.Scope, query string, limit int) ([]models.SearchResult, error)
+	// Suggest returns up to limit of the org's labels, members or projects,
+	// by kind, with a word starting with query: whole-name prefix matches
+	// first, then the most recently used.
+	Suggest(ctx context.Context, scope models.Scope, kind, query string, limit int) ([]models.Suggestion, error)
+	// SimilarTasks returns up to limit open tasks in the project, or with no
+	// project when projectID is nil, whose titles are at least
+	// models.DuplicateSimilarity alike to title, most alike first.
+	SimilarTasks(ctx context.Context, scope models.Scope, projectID *string, title string, doneStatuses []string, limit int) ([]models.SimilarTask, error)
+}
+
+// Store is what handlers depend on instead of the pgx pool, so they can run
+// against the in-memory implementation in tests.
+type Store interface {
+	Tasks() TaskStore
+	Projects() ProjectStore
+	Labels() LabelStore
+	Workflows() WorkflowStore
+	Reminders() ReminderStore
+	EmailPreferences() EmailPreferenceStore
+	UserSettings() UserSettingsStore
+	OrgSettings() OrgSettingsStore
+	PushSubscriptions() PushSubscriptionStore
+	APITokens() APITokenStore
+	Imports() ImportStore
+	Accounts() AccountStore
+	CalendarFeeds() CalendarFeedStore
+	InboundAddresses() InboundAddressStore
+	GoogleCalendar() GoogleCalendarStore
+	Slack() SlackStore
+	Webhooks() WebhookStore
+	Telegram() TelegramStore
+	Notifications() NotificationStore
+	Sync() SyncStore
+	Changes() ChangeStore
+	Usage() UsageStore
+	Idempotency() IdempotencyStore
+	Trash() TrashStore
+	Comments() CommentStore
+	Attachments() AttachmentStore
+	TimeEntries() TimeEntryStore
+	Pomodoros() PomodoroStore
+	Stats() StatsStore
+	OrgAdmin() OrgAdminStore
+	Boards() BoardStore
+	Views() ViewStore
+	Templates() TemplateStore
+	ProjectTemplates() ProjectTemplateStore
+	CustomFields() CustomFieldStore
+	Digests() DigestStore
+	Shares() ShareStore
+	ShareLinks() ShareLinkStore
+	Invitations() InvitationStore
+	Users() UserStore
+	Activity() ActivityStore
+	Search() SearchStore
+}
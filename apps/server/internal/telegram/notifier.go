@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"yata/apps/server/internal/notify"
+	"yata/apps/server/internal/store"
+)
+
+// maxBody is how much of the task description a reminder quotes.
+const maxBody = 280
+
+// Notifier sends due-date reminders to the user's linked chat.
+type Notifier struct {
+	Client *Client
+	Links  store.TelegramStore
+	// Settings can turn Telegram off without unlinking, and has the
+	// timezone due dates are shown in.
+	Settings store.UserSettingsStore
+	// AppURL is the web app's base URL, used to link to tasks.
+	AppURL string
+}
+
+func (n *Notifier) Notify(ctx context.Context, note notify.Notification) error {
+	if note.Kind != notify.KindReminder {
+		return nil
+	}
+
+	link, err := n.Links.Get(ctx, note.UserID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	settings, err := n.Settings.Get(ctx, note.UserID)
+	if err != nil {
+		return err
+	}
+	if !settings.NotificationChannels.Telegram {
+		return nil
+	}
+
+	task := "<b>" + html.EscapeString(note.Title) + "</b>"
+	if n.AppURL != "" && note.TaskID != "" {
+		task = fmt.Sprintf(`<a href="%s/tasks/%s">%s</a>`, html.EscapeString(strings.TrimRight(n.AppURL, "/")), note.TaskID, task)
+	}
+	text := "Reminder: " + task
+	if note.DueDate != nil {
+		text = task + " is due " + note.DueDate.In(settings.Location()).Format("Mon Jan 2 15:04 MST")
+	}
+	if body := strings.TrimSpace(note.Body); body != "" {
+		if r := []rune(body); len(r) > maxBody {
+			body = string(r[:maxBody]) + "…"
+		}
+		text += "\n\n" + html.EscapeString(body)
+	}
+
+	err = n.Client.SendMessage(ctx, link.ChatID, text)
+	if errors.Is(err, ErrBlocked) {
+		// Retrying can't help; the user has to link again.
+		if err := n.Links.Delete(ctx, note.UserID); err != nil && !errors.Is(err, store.ErrNotFound) {
+			slog.Error("Failed to unlink blocked Telegram chat", "error", err)
+		}
+		return nil
+	}
+	return err
+}
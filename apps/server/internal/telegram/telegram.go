@@ -0,0 +1,96 @@
+// Package telegram talks to the Telegram Bot API: it sends reminders to the
+// chats users linked, and decodes the updates Telegram posts to the webhook.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const apiBase = "https://api.telegram.org/bot"
+
+// ErrBlocked is returned when the user blocked the bot or deleted the chat,
+// so nothing can be sent there any more.
+var ErrBlocked = errors.New("telegram chat is no longer reachable")
+
+// Update is the part of a webhook update the bot acts on.
+type Update struct {
+	UpdateID int64    `json:"update_id"`
+	Message  *Message `json:"message"`
+}
+
+type Message struct {
+	MessageID int64  `json:"message_id"`
+	From      *User  `json:"from"`
+	Chat      Chat   `json:"chat"`
+	Text      string `json:"text"`
+}
+
+type Chat struct {
+	ID int64 `json:"id"`
+	// Type is "private" for a one-to-one chat with the bot.
+	Type string `json:"type"`
+}
+
+type User struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+}
+
+// VerifySecret checks the X-Telegram-Bot-Api-Secret-Token header against
+// the secret_token the webhook was registered with.
+func VerifySecret(secret, header string) bool {
+	return secret != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(header)) == 1
+}
+
+// Client calls the Bot API as one bot.
+type Client struct {
+	token  string
+	client *http.Client
+}
+
+func NewClient(token string) *Client {
+	return &Client{token: token, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// SendMessage sends text, formatted as Telegram's HTML subset, to chatID.
+func (c *Client) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]any{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"parse_mode":               "HTML",
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiBase+c.token+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	switch {
+	case res.StatusCode == http.StatusForbidden:
+		return ErrBlocked
+	case res.StatusCode >= 300:
+		// The body names the method but never the token, which is only in
+		// the URL.
+		detail, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return fmt.Errorf("telegram returned %d: %s", res.StatusCode, detail)
+	}
+	return nil
+}
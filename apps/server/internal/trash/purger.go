@@ -0,0 +1,51 @@
+// Package trash permanently removes tasks and projects that have sat in the
+// trash past the retention period.
+package trash
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"yata/apps/server/internal/store"
+)
+
+// Purger deletes everything trashed more than Retention ago, checking every
+// Interval.
+type Purger struct {
+	Trash     store.TrashStore
+	Retention time.Duration
+	Interval  time.Duration
+}
+
+func NewPurger(trash store.TrashStore, retention, interval time.Duration) *Purger {
+	return &Purger{Trash: trash, Retention: retention, Interval: interval}
+}
+
+// Start runs the purger until ctx is done.
+func (p *Purger) Start(ctx context.Context) {
+	go func() {
+		ticker := time.NewTicker(p.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.Run(ctx)
+			}
+		}
+	}()
+}
+
+// Run purges once.
+func (p *Purger) Run(ctx context.Context) {
+	n, err := p.Trash.Purge(ctx, time.Now().Add(-p.Retention))
+	if err != nil {
+		slog.Error("Failed to purge trash", "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("Purged the trash", "items", n)
+	}
+}
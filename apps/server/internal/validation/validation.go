@@ -0,0 +1,131 @@
+// Package validation turns why a request body didn't bind into errors per
+// field, named as the client sent them, for the details of a 400.
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is one field that didn't pass, by its JSON path, like
+// "columns[0].name".
+type FieldError struct {
+	Field string `json:"field"`
+	Error string `json:"error"`
+}
+
+// Setup makes gin's validator name fields by their json tags, so errors
+// use the names clients know. Call it before serving.
+func Setup() {
+	v, ok := binding.Validator.Engine().(*validator.Validate)
+	if !ok {
+		return
+	}
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return f.Name
+		}
+		return name
+	})
+}
+
+// Fields lists the fields err, from ShouldBindJSON, is about. It's empty
+// when the body wasn't JSON at all.
+func Fields(err error) []FieldError {
+	var invalid validator.ValidationErrors
+	if errors.As(err, &invalid) {
+		fields := make([]FieldError, len(invalid))
+		for i, fe := range invalid {
+			fields[i] = FieldError{Field: path(fe), Error: message(fe)}
+		}
+		return fields
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return []FieldError{{Field: typeErr.Field, Error: "must be " + kindName(typeErr.Type)}}
+	}
+	return []FieldError{}
+}
+
+// path drops the struct type the namespace starts with.
+func path(fe validator.FieldError) string {
+	_, rest, ok := strings.Cut(fe.Namespace(), ".")
+	if !ok {
+		return fe.Field()
+	}
+	return rest
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func message(fe validator.FieldError) string {
+	param := fe.Param()
+	kind := fe.Kind()
+	isTime := fe.Type() == timeType || fe.Type() == reflect.PointerTo(timeType)
+
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "min", "max", "len":
+		bound := map[string]string{"min": "at least", "max": "at most", "len": "exactly"}[fe.Tag()]
+		switch kind {
+		case reflect.String:
+			return fmt.Sprintf("must be %s %s characters long", bound, param)
+		case reflect.Slice, reflect.Array, reflect.Map:
+			return fmt.Sprintf("must have %s %s items", bound, param)
+		}
+		return fmt.Sprintf("must be %s %s", bound, param)
+	case "gt", "gte", "lt", "lte":
+		if isTime && param == "" {
+			// Without a parameter they compare with now.
+			if fe.Tag() == "gt" || fe.Tag() == "gte" {
+				return "must be in the future"
+			}
+			return "must be in the past"
+		}
+		op := map[string]string{"gt": "greater than", "gte": "at least", "lt": "less than", "lte": "at most"}[fe.Tag()]
+		return fmt.Sprintf("must be %s %s", op, param)
+	case "oneof":
+		return "must be one of: " + strings.Join(strings.Fields(param), ", ")
+	case "datetime":
+		return "must be a date and time formatted as " + param
+	case "url", "http_url":
+		return "must be a URL"
+	case "email":
+		return "must be an email address"
+	case "uuid", "uuid4":
+		return "must be a UUID"
+	}
+	return "is invalid"
+}
+
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "a string"
+	case reflect.Bool:
+		return "true or false"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "a whole number"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "a list"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+	return "a " + t.String()
+}
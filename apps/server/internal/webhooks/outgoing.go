@@ -0,0 +1,338 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+	"yata/apps/server/internal/jobs"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// JobKind is the job that delivers one event to one endpoint.
+const JobKind = "webhook"
+
+// maxAttempts is how often a delivery is tried. With the queue's backoff
+// the last attempt comes three and a half hours after the first.
+const maxAttempts = 12
+
+// Headers on every delivery. The signature is "v1=" and the hex
+// HMAC-SHA256, keyed with the endpoint's secret, of the timestamp, a dot and
+// the body.
+const (
+	HeaderID        = "X-Yata-Webhook-Id"
+	HeaderEvent     = "X-Yata-Webhook-Event"
+	HeaderTimestamp = "X-Yata-Webhook-Timestamp"
+	HeaderSignature = "X-Yata-Webhook-Signature"
+)
+
+var errBlockedAddress = errors.New("webhook url resolves to a non-public address")
+
+// NewSecret returns a fresh signing secret for an endpoint.
+func NewSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return "whsec_" + base64.RawURLEncoding.EncodeToString(b)
+}
+
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// delivery is the job payload. The event is built when it's relayed, so
+// every attempt sends the same body.
+type delivery struct {
+	EndpointID string              `json:"endpointId"`
+	Event      models.WebhookEvent `json:"event"`
+}
+
+// Dispatcher is the outbox publisher that queues a delivery of each event to
+// every endpoint subscribed to it. The jobs are queued in the relay's
+// transaction, so each endpoint gets each event once.
+type Dispatcher struct {
+	Endpoints store.WebhookStore
+}
+
+func (d Dispatcher) Publish(ctx context.Context, tx pgx.Tx, e models.OutboxEvent) error {
+	if e.OrgID == nil || !slices.Contains(models.WebhookEvents, e.Event) {
+		return nil
+	}
+	endpoints, err := d.Endpoints.Subscribed(ctx, *e.OrgID, e.Event)
+	if err != nil || len(endpoints) == 0 {
+		return err
+	}
+
+	queue := jobs.TxQueue(tx)
+	event := models.WebhookEvent{ID: e.ID, Type: e.Event, OrgID: *e.OrgID, CreatedAt: e.CreatedAt.UTC(), Data: e.Payload}
+	for _, endpoint := range endpoints {
+		if err := queue.Enqueue(ctx, JobKind, delivery{EndpointID: endpoint.ID, Event: event}, jobs.MaxAttempts(maxAttempts)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// probeLease is how long a half-open breaker waits on its probe before
+// letting another delivery try; longer than a delivery can take.
+const probeLease = 30 * time.Second
+
+// busyDelay is how long a delivery waits when its org already has
+// OrgConcurrency of them under way.
+const busyDelay = 5 * time.Second
+
+// Deliverer POSTs queued events to their endpoints and logs each attempt.
+// A failed attempt fails the job, so the queue retries it with backoff.
+//
+// Deliveries that can't go out yet are postponed without using up an
+// attempt: those of an org with OrgConcurrency under way in this process
+// already, and those to a URL whose breaker is open. Breaker opens an
+// org's breaker on a URL once deliveries to it keep failing; a zero
+// Threshold leaves breakers off.
+type Deliverer struct {
+	Endpoints      store.WebhookStore
+	Client         *http.Client
+	OrgConcurrency int
+	Breaker        models.WebhookBreakerPolicy
+
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewDeliverer's client won't connect to any of blockedPrefixes, whatever
+// the endpoint's host resolves to, and doesn't follow redirects.
+func NewDeliverer(endpoints store.WebhookStore) *Deliverer {
+	dialer := &net.Dialer{Timeout: 5 * time.Second, Control: publicOnly}
+	return &Deliverer{
+		Endpoints: endpoints,
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: 5 * time.Second},
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// blockedPrefixes are the IANA special-purpose ranges that aren't the public
+// internet, or can be made to reach back into it, like NAT64 and 6to4.
+var blockedPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),       // "this network"
+	netip.MustParsePrefix("10.0.0.0/8"),      // private
+	netip.MustParsePrefix("100.64.0.0/10"),   // carrier-grade NAT
+	netip.MustParsePrefix("127.0.0.0/8"),     // loopback
+	netip.MustParsePrefix("169.254.0.0/16"),  // link-local, cloud metadata
+	netip.MustParsePrefix("172.16.0.0/12"),   // private
+	netip.MustParsePrefix("192.0.0.0/24"),    // IETF protocol assignments
+	netip.MustParsePrefix("192.0.2.0/24"),    // documentation
+	netip.MustParsePrefix("192.88.99.0/24"),  // 6to4 relay anycast
+	netip.MustParsePrefix("192.168.0.0/16"),  // private
+	netip.MustParsePrefix("198.18.0.0/15"),   // benchmarking
+	netip.MustParsePrefix("198.51.100.0/24"), // documentation
+	netip.MustParsePrefix("203.0.113.0/24"),  // documentation
+	netip.MustParsePrefix("224.0.0.0/4"),     // multicast
+	netip.MustParsePrefix("240.0.0.0/4"),     // reserved, broadcast
+
+	netip.MustParsePrefix("::/128"),         // unspecified
+	netip.MustParsePrefix("::1/128"),        // loopback
+	netip.MustParsePrefix("64:ff9b::/96"),   // NAT64
+	netip.MustParsePrefix("64:ff9b:1::/48"), // local NAT64
+	netip.MustParsePrefix("100::/64"),       // discard
+	netip.MustParsePrefix("2001::/23"),      // IETF protocol assignments, Teredo
+	netip.MustParsePrefix("2001:db8::/32"),  // documentation
+	netip.MustParsePrefix("2002::/16"),      // 6to4
+	netip.MustParsePrefix("3fff::/20"),      // documentation
+	netip.MustParsePrefix("fc00::/7"),       // unique local
+	netip.MustParsePrefix("fe80::/10"),      // link-local
+	netip.MustParsePrefix("fec0::/10"),      // site-local
+	netip.MustParsePrefix("ff00::/8"),       // multicast
+}
+
+func publicOnly(_, address string, _ syscall.RawConn) error {
+	addr, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if blocked(addr.Addr()) {
+		return errBlockedAddress
+	}
+	return nil
+}
+
+// blocked reports whether ip is in blockedPrefixes. IPv4-mapped addresses
+// are checked as the IPv4 address they carry.
+func blocked(ip netip.Addr) bool {
+	ip = ip.Unmap().WithZone("")
+	for _, prefix := range blockedPrefixes {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Handle is the jobs.Handler for JobKind.
+func (d *Deliverer) Handle(ctx context.Context, payload json.RawMessage) error {
+	var job delivery
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	endpoint, err := d.Endpoints.Target(ctx, job.EndpointID)
+	if errors.Is(err, store.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if !endpoint.Active {
+		return nil
+	}
+
+	if !d.acquire(endpoint.OrgID) {
+		return jobs.Postpone(busyDelay)
+	}
+	defer d.release(endpoint.OrgID)
+	if wait, err := d.breakerWait(ctx, endpoint); err != nil || wait > 0 {
+		if err != nil {
+			return err
+		}
+		return jobs.Postpone(wait)
+	}
+
+	body, err := json.Marshal(job.Event)
+	if err != nil {
+		return err
+	}
+	start := time.Now()
+	status, sendErr := d.send(ctx, endpoint, job.Event, body)
+
+	record := models.WebhookDelivery{
+		EndpointID: endpoint.ID,
+		EventID:    job.Event.ID,
+		Event:      job.Event.Type,
+		Payload:    body,
+		DurationMs: int(time.Since(start).Milliseconds()),
+	}
+	if status != 0 {
+		record.StatusCode = &status
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		record.Error = &msg
+	}
+	if _, err := d.Endpoints.RecordDelivery(ctx, record); err != nil && !errors.Is(err, store.ErrNotFound) {
+		slog.ErrorContext(ctx, "Failed to record webhook delivery", "endpoint_id", endpoint.ID, "error", err)
+	}
+	if d.Breaker.Threshold > 0 {
+		breaker, err := d.Endpoints.RecordBreakerResult(ctx, endpoint.OrgID, endpoint.URL, sendErr == nil, d.Breaker)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to record webhook breaker result", "endpoint_id", endpoint.ID, "error", err)
+		} else if breaker.State == models.BreakerOpen {
+			slog.WarnContext(ctx, "Webhook breaker open", "endpoint_id", endpoint.ID, "failures", breaker.Failures, "open_until", breaker.OpenUntil)
+		}
+	}
+	return sendErr
+}
+
+// acquire takes one of the org's delivery slots, if it has one free.
+func (d *Deliverer) acquire(orgID string) bool {
+	if d.OrgConcurrency <= 0 {
+		return true
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.inFlight[orgID] >= d.OrgConcurrency {
+		return false
+	}
+	if d.inFlight == nil {
+		d.inFlight = map[string]int{}
+	}
+	d.inFlight[orgID]++
+	return true
+}
+
+func (d *Deliverer) release(orgID string) {
+	if d.OrgConcurrency <= 0 {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.inFlight[orgID]--; d.inFlight[orgID] <= 0 {
+		delete(d.inFlight, orgID)
+	}
+}
+
+// breakerWait is how long a delivery to the endpoint has to wait for its
+// breaker: none while it's closed, until the cooldown is over while it's
+// open, and, while it's half open, none for the one delivery that gets to
+// probe the URL and probeLease for the rest.
+func (d *Deliverer) breakerWait(ctx context.Context, endpoint *models.WebhookEndpoint) (time.Duration, error) {
+	if d.Breaker.Threshold <= 0 {
+		return 0, nil
+	}
+	breaker, err := d.Endpoints.Breaker(ctx, endpoint.OrgID, endpoint.URL)
+	if err != nil {
+		return 0, err
+	}
+	switch breaker.State {
+	case models.BreakerOpen:
+		return time.Until(*breaker.OpenUntil), nil
+	case models.BreakerHalfOpen:
+		probe, err := d.Endpoints.ClaimProbe(ctx, endpoint.OrgID, endpoint.URL, probeLease)
+		if err != nil || probe {
+			return 0, err
+		}
+		return probeLease, nil
+	}
+	return 0, nil
+}
+
+func (d *Deliverer) send(ctx context.Context, endpoint *models.WebhookEndpoint, event models.WebhookEvent, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "yata-webhooks/1")
+	req.Header.Set(HeaderID, event.ID)
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(endpoint.Secret, timestamp, body))
+
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
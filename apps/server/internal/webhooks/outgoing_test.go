@@ -0,0 +1,288 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+	"yata/apps/server/internal/models"
+	"yata/apps/server/internal/store"
+)
+
+// receiver counts the deliveries each path gets and answers them with the
+// status set for it, 200 by default.
+type receiver struct {
+	mu     sync.Mutex
+	status map[string]int
+	hits   map[string]int
+	block  chan struct{}
+}
+
+func newReceiver(t *testing.T) (*receiver, *httptest.Server) {
+	r := &receiver{status: map[string]int{}, hits: map[string]int{}}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		r.hits[req.URL.Path]++
+		status, block := r.status[req.URL.Path], r.block
+		r.mu.Unlock()
+		if block != nil && req.URL.Path == "/slow" {
+			<-block
+		}
+		if status == 0 {
+			status = http.StatusOK
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+	return r, srv
+}
+
+func (r *receiver) set(path string, status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status[path] = status
+}
+
+func (r *receiver) count(path string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.hits[path]
+}
+
+func newEndpoint(t *testing.T, db store.Store, orgID, url string) *models.WebhookEndpoint {
+	t.Helper()
+	e, err := db.Webhooks().Create(context.Background(), orgID, "user_1", models.CreateWebhookInput{URL: url, Events: []string{models.EventTaskCreated}, Secret: NewSecret()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+var eventSeq atomic.Int64
+
+func deliver(t *testing.T, d *Deliverer, e *models.WebhookEndpoint) error {
+	payload, _ := json.Marshal(delivery{EndpointID: e.ID, Event: models.WebhookEvent{
+		ID:        fmt.Sprintf("evt-%d", eventSeq.Add(1)),
+		Type:      models.EventTaskCreated,
+		OrgID:     e.OrgID,
+		CreatedAt: time.Now(),
+		Data:      json.RawMessage(`{}`),
+	}})
+	return d.Handle(t.Context(), payload)
+}
+
+func breakerState(t *testing.T, db store.Store, e *models.WebhookEndpoint) *models.WebhookBreaker {
+	t.Helper()
+	b, err := db.Webhooks().Breaker(context.Background(), e.OrgID, e.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestPublicOnly(t *testing.T) {
+	tests := []struct {
+		address string
+		blocked bool
+	}{
+		{"93.184.215.14:443", false},
+		{"[2606:2800:21f:cb07:6820:80da:af6b:8b2c]:443", false},
+		{"127.0.0.1:80", true},
+		{"10.1.2.3:443", true},
+		{"172.16.0.1:443", true},
+		{"192.168.1.1:443", true},
+		{"169.254.169.254:80", true},
+		{"100.64.0.1:443", true},
+		{"100.127.255.255:443", true},
+		{"100.128.0.1:443", false},
+		{"0.1.2.3:443", true},
+		{"0.0.0.0:443", true},
+		{"192.0.0.8:443", true},
+		{"198.18.0.1:443", true},
+		{"203.0.113.7:443", true},
+		{"224.0.0.1:443", true},
+		{"255.255.255.255:443", true},
+		{"[::1]:443", true},
+		{"[::]:443", true},
+		{"[::ffff:127.0.0.1]:443", true},
+		{"[::ffff:169.254.169.254]:80", true},
+		{"[::ffff:93.184.215.14]:443", false},
+		{"[64:ff9b::a9fe:a9fe]:80", true},
+		{"[2001:0:4136:e378:8000:63bf:3fff:fdd2]:443", true},
+		{"[2001:db8::1]:443", true},
+		{"[2002:7f00:1::]:443", true},
+		{"[fd00::1]:443", true},
+		{"[fe80::1%eth0]:443", true},
+		{"[ff02::1]:443", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			err := publicOnly("tcp", tt.address, nil)
+			if tt.blocked && !errors.Is(err, errBlockedAddress) {
+				t.Errorf("err = %v, want it blocked", err)
+			}
+			if !tt.blocked && err != nil {
+				t.Errorf("err = %v, want it allowed", err)
+			}
+		})
+	}
+}
+
+func TestBreakerOpensOnlyForTheFailingEndpoint(t *testing.T) {
+	recv, srv := newReceiver(t)
+	recv.set("/failing", http.StatusInternalServerError)
+
+	db := store.NewMemory()
+	failing := newEndpoint(t, db, "org_1", srv.URL+"/failing")
+	healthy := newEndpoint(t, db, "org_1", srv.URL+"/healthy")
+	d := &Deliverer{
+		Endpoints: db.Webhooks(),
+		Client:    srv.Client(),
+		Breaker:   models.WebhookBreakerPolicy{Threshold: 3, Cooldown: time.Hour, MaxCooldown: 4 * time.Hour},
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := deliver(t, d, failing); err == nil {
+			t.Fatalf("delivery %d to the failing endpoint succeeded", i+1)
+		}
+		if err := deliver(t, d, healthy); err != nil {
+			t.Fatalf("delivery %d to the healthy endpoint: %v", i+1, err)
+		}
+	}
+
+	if b := breakerState(t, db, failing); b.State != models.BreakerOpen || b.Failures != 3 || b.Trips != 1 {
+		t.Errorf("failing breaker = %+v, want open after 3 failures", b)
+	}
+	if b := breakerState(t, db, healthy); b.State != models.BreakerClosed {
+		t.Errorf("healthy breaker = %+v, want closed", b)
+	}
+
+	// While it's open, deliveries to the failing URL are held back and the
+	// healthy one is still delivered to.
+	if err := deliver(t, d, failing); err == nil {
+		t.Error("delivery through an open breaker wasn't postponed")
+	}
+	if err := deliver(t, d, healthy); err != nil {
+		t.Errorf("healthy delivery while the other breaker is open: %v", err)
+	}
+	if got := recv.count("/failing"); got != 3 {
+		t.Errorf("failing endpoint got %d deliveries, want 3", got)
+	}
+	if got := recv.count("/healthy"); got != 4 {
+		t.Errorf("healthy endpoint got %d deliveries, want 4", got)
+	}
+
+	// The same URL in another org has a breaker of its own.
+	other := newEndpoint(t, db, "org_2", srv.URL+"/failing")
+	if b := breakerState(t, db, other); b.State != models.BreakerClosed {
+		t.Errorf("other org's breaker = %+v, want closed", b)
+	}
+}
+
+func TestBreakerProbes(t *testing.T) {
+	recv, srv := newReceiver(t)
+	recv.set("/flaky", http.StatusBadGateway)
+
+	db := store.NewMemory()
+	flaky := newEndpoint(t, db, "org_1", srv.URL+"/flaky")
+	cooldown := 20 * time.Millisecond
+	d := &Deliverer{
+		Endpoints: db.Webhooks(),
+		Client:    srv.Client(),
+		Breaker:   models.WebhookBreakerPolicy{Threshold: 2, Cooldown: cooldown, MaxCooldown: time.Second},
+	}
+
+	deliver(t, d, flaky)
+	deliver(t, d, flaky)
+	first := breakerState(t, db, flaky)
+	if first.Trips != 1 || first.OpenUntil == nil {
+		t.Fatalf("breaker = %+v, want opened once", first)
+	}
+
+	// A failed probe opens it again for twice as long.
+	time.Sleep(cooldown)
+	if b := breakerState(t, db, flaky); b.State != models.BreakerHalfOpen {
+		t.Fatalf("breaker after the cooldown = %+v, want half open", b)
+	}
+	if err := deliver(t, d, flaky); err == nil {
+		t.Fatal("failed probe succeeded")
+	}
+	second := breakerState(t, db, flaky)
+	if second.Trips != 2 || recv.count("/flaky") != 3 {
+		t.Fatalf("breaker after a failed probe = %+v with %d deliveries, want opened twice after 3", second, recv.count("/flaky"))
+	}
+
+	// A successful probe closes it.
+	time.Sleep(2 * cooldown)
+	recv.set("/flaky", http.StatusOK)
+	if err := deliver(t, d, flaky); err != nil {
+		t.Fatalf("probe: %v", err)
+	}
+	if b := breakerState(t, db, flaky); b.State != models.BreakerClosed || b.Failures != 0 || b.Trips != 0 {
+		t.Errorf("breaker after a successful probe = %+v, want closed and reset", b)
+	}
+}
+
+func TestBreakerLetsOneProbeThrough(t *testing.T) {
+	db := store.NewMemory()
+	ctx := context.Background()
+	policy := models.WebhookBreakerPolicy{Threshold: 1, Cooldown: time.Millisecond, MaxCooldown: time.Second}
+	if _, err := db.Webhooks().RecordBreakerResult(ctx, "org_1", "https://example.com/hook", false, policy); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	claims := 0
+	for range 3 {
+		ok, err := db.Webhooks().ClaimProbe(ctx, "org_1", "https://example.com/hook", time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			claims++
+		}
+	}
+	if claims != 1 {
+		t.Errorf("%d probes claimed, want 1", claims)
+	}
+}
+
+func TestOrgConcurrency(t *testing.T) {
+	recv, srv := newReceiver(t)
+	recv.block = make(chan struct{})
+
+	db := store.NewMemory()
+	slow := newEndpoint(t, db, "org_1", srv.URL+"/slow")
+	sameOrg := newEndpoint(t, db, "org_1", srv.URL+"/same-org")
+	otherOrg := newEndpoint(t, db, "org_2", srv.URL+"/other-org")
+	d := &Deliverer{Endpoints: db.Webhooks(), Client: srv.Client(), OrgConcurrency: 1}
+
+	done := make(chan error)
+	go func() { done <- deliver(t, d, slow) }()
+	for recv.count("/slow") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := deliver(t, d, sameOrg); err == nil {
+		t.Error("second delivery of a busy org wasn't postponed")
+	}
+	if err := deliver(t, d, otherOrg); err != nil {
+		t.Errorf("delivery of another org: %v", err)
+	}
+	close(recv.block)
+	if err := <-done; err != nil {
+		t.Fatalf("slow delivery: %v", err)
+	}
+	if err := deliver(t, d, sameOrg); err != nil {
+		t.Errorf("delivery once the org's slot is free: %v", err)
+	}
+	if got := recv.count("/same-org"); got != 1 {
+		t.Errorf("same-org endpoint got %d deliveries, want 1", got)
+	}
+}